@@ -0,0 +1,239 @@
+package cache
+
+import (
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// archivesDirName is the directory of the cache holding the monthly archives
+	archivesDirName = "archives"
+	// archiveMonthFormat is the layout of archive months, in UTC like the hourly bucket keys
+	archiveMonthFormat = "2006-01"
+)
+
+// ArchivedFile identifies a usage file whose summary was rolled into an archive
+type ArchivedFile struct {
+	PathHash  string    `json:"path_hash"` // MD5 of the absolute path, so that archives do not retain session IDs
+	ModTime   time.Time `json:"mod_time"`
+	FileSize  int64     `json:"file_size"`
+	LastEntry time.Time `json:"last_entry"` // Entries after this were appended once the file was archived
+}
+
+// MonthArchive is the usage of one data path during one calendar month, aggregated per project directory
+type MonthArchive struct {
+	SchemaVersion int                     `json:"schema_version"`
+	DataPath      string                  `json:"data_path"`
+	Month         string                  `json:"month"` // "2006-01" in UTC
+	Files         []ArchivedFile          `json:"files"`
+	Projects      map[string]*FileSummary `json:"projects"` // Hourly buckets and session hints of each project directory
+	UpdatedAt     time.Time               `json:"updated_at"`
+}
+
+// ArchiveStore reads and writes the monthly archives kept next to the summary cache
+type ArchiveStore struct {
+	dir string
+	enc *Encryptor
+}
+
+// NewArchiveStore creates a store for the archives in the cache directory persistPath, encrypted with enc if set
+func NewArchiveStore(persistPath string, enc *Encryptor) *ArchiveStore {
+	return &ArchiveStore{dir: filepath.Join(persistPath, archivesDirName), enc: enc}
+}
+
+// Archives returns the archive store of the cache, sharing its directory and encryption
+func (c *FileBasedSummaryCache) Archives() *ArchiveStore {
+	return NewArchiveStore(filepath.Dir(c.baseDir), c.enc)
+}
+
+// ArchiveCutoff returns the start of the UTC month months before the one containing now; files last
+// modified before it are archived
+func ArchiveCutoff(months int, now time.Time) time.Time {
+	now = now.UTC()
+	return time.Date(now.Year(), now.Month()-time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// PathHash returns the identifier of a usage file in archives
+func PathHash(absPath string) string {
+	return fmt.Sprintf("%x", md5.Sum([]byte(absPath)))
+}
+
+// NewMonthArchive creates an empty archive of dataPath for the month containing t
+func NewMonthArchive(dataPath string, t time.Time) *MonthArchive {
+	return &MonthArchive{
+		SchemaVersion: CurrentSchemaVersion,
+		DataPath:      dataPath,
+		Month:         t.UTC().Format(archiveMonthFormat),
+		Projects:      make(map[string]*FileSummary),
+	}
+}
+
+// Add merges the hourly buckets of summary falling into the archive month into the project directory of
+// the file, and records file as archived
+func (a *MonthArchive) Add(file ArchivedFile, summary *FileSummary) {
+	if !a.HasFile(file.PathHash) {
+		a.Files = append(a.Files, file)
+	}
+	if summary == nil {
+		return
+	}
+
+	projectDir := filepath.Dir(summary.Path)
+	project, ok := a.Projects[projectDir]
+	if !ok {
+		project = &FileSummary{
+			SchemaVersion: CurrentSchemaVersion,
+			Path:          filepath.Join(projectDir, a.Month+".jsonl"),
+			ModelStats:    make(map[string]ModelStat),
+			HourlyBuckets: make(map[string]*TemporalBucket),
+			DailyBuckets:  make(map[string]*TemporalBucket),
+		}
+		a.Projects[projectDir] = project
+	}
+
+	for key, bucket := range summary.HourlyBuckets {
+		if !strings.HasPrefix(key, a.Month) {
+			continue
+		}
+		merged, ok := project.HourlyBuckets[key]
+		if !ok {
+			merged = &TemporalBucket{Period: key, ModelStats: make(map[string]*ModelStat)}
+			project.HourlyBuckets[key] = merged
+		}
+		merged.EntryCount += bucket.EntryCount
+		merged.TotalCost += bucket.TotalCost
+		merged.TotalTokens += bucket.TotalTokens
+		project.EntryCount += bucket.EntryCount
+		project.TotalCost += bucket.TotalCost
+		project.TotalTokens += bucket.TotalTokens
+		for model, stat := range bucket.ModelStats {
+			mergeModelStat(merged.ModelStats, model, stat)
+		}
+	}
+
+	start, _ := time.Parse(archiveMonthFormat, a.Month)
+	end := start.AddDate(0, 1, 0)
+	for _, hint := range summary.SessionHints {
+		if _, _, ok := hint.Overlap(start, end); ok {
+			project.SessionHints = append(project.SessionHints, hint)
+		}
+	}
+}
+
+// HasFile reports whether the file with the given path hash is archived
+func (a *MonthArchive) HasFile(pathHash string) bool {
+	for _, file := range a.Files {
+		if file.PathHash == pathHash {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeModelStat adds stat to the statistics of model, keeping the largest message of either
+func mergeModelStat(stats map[string]*ModelStat, model string, stat *ModelStat) {
+	merged, ok := stats[model]
+	if !ok {
+		merged = &ModelStat{Model: stat.Model}
+		stats[model] = merged
+	}
+	merged.EntryCount += stat.EntryCount
+	merged.TotalCost += stat.TotalCost
+	merged.InputTokens += stat.InputTokens
+	merged.OutputTokens += stat.OutputTokens
+	merged.CacheCreationTokens += stat.CacheCreationTokens
+	merged.CacheCreation1hTokens += stat.CacheCreation1hTokens
+	merged.CacheReadTokens += stat.CacheReadTokens
+	if stat.LargestInputTokens+stat.LargestCacheCreationTokens > merged.LargestInputTokens+merged.LargestCacheCreationTokens {
+		merged.LargestInputTokens = stat.LargestInputTokens
+		merged.LargestCacheCreationTokens = stat.LargestCacheCreationTokens
+	}
+}
+
+// dataPathDir returns the directory holding the archives of dataPath
+func (s *ArchiveStore) dataPathDir(dataPath string) string {
+	return filepath.Join(s.dir, PathHash(dataPath)[:12])
+}
+
+// Load returns the archives of dataPath ordered by month
+func (s *ArchiveStore) Load(dataPath string) ([]*MonthArchive, error) {
+	dir := s.dataPathDir(dataPath)
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read archive directory: %w", err)
+	}
+
+	var archives []*MonthArchive
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive %s: %w", file.Name(), err)
+		}
+		if IsSealed(data) {
+			if s.enc == nil {
+				return nil, fmt.Errorf("archive %s is encrypted but cache encryption is off", file.Name())
+			}
+			if data, err = s.enc.Open(data); err != nil {
+				return nil, fmt.Errorf("failed to decrypt archive %s: %w", file.Name(), err)
+			}
+		}
+		var archive MonthArchive
+		if err := json.Unmarshal(data, &archive); err != nil {
+			return nil, fmt.Errorf("failed to parse archive %s: %w", file.Name(), err)
+		}
+		if archive.SchemaVersion > CurrentSchemaVersion {
+			return nil, fmt.Errorf("archive %s: %w", file.Name(), &ErrUnsupportedSchema{Version: archive.SchemaVersion})
+		}
+		if archive.DataPath != dataPath {
+			continue
+		}
+		archives = append(archives, &archive)
+	}
+
+	sort.Slice(archives, func(i, j int) bool {
+		return archives[i].Month < archives[j].Month
+	})
+	return archives, nil
+}
+
+// Save atomically writes an archive, replacing the previous archive of the same month
+func (s *ArchiveStore) Save(archive *MonthArchive) error {
+	archive.SchemaVersion = CurrentSchemaVersion
+	archive.UpdatedAt = time.Now()
+	data, err := json.Marshal(archive)
+	if err != nil {
+		return fmt.Errorf("failed to marshal archive: %w", err)
+	}
+	if s.enc != nil {
+		if data, err = s.enc.Seal(data); err != nil {
+			return fmt.Errorf("failed to encrypt archive: %w", err)
+		}
+	}
+
+	dir := s.dataPathDir(archive.DataPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	path := filepath.Join(dir, archive.Month+".json")
+	tmpFile := path + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := os.Rename(tmpFile, path); err != nil {
+		os.Remove(tmpFile)
+		return fmt.Errorf("failed to rename archive: %w", err)
+	}
+	return nil
+}
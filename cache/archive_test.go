@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchiveCutoff(t *testing.T) {
+	now := time.Date(2025, 3, 15, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), ArchiveCutoff(2, now))
+	assert.Equal(t, time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC), ArchiveCutoff(3, now))
+}
+
+func TestMonthArchive_Add(t *testing.T) {
+	bucket := func(key string, input, largest int) *TemporalBucket {
+		return &TemporalBucket{Period: key, EntryCount: 2, TotalCost: 1, TotalTokens: input, ModelStats: map[string]*ModelStat{
+			"claude-sonnet-4": {Model: "claude-sonnet-4", EntryCount: 2, TotalCost: 1, InputTokens: input, LargestInputTokens: largest},
+		}}
+	}
+	first := &FileSummary{Path: "/data/-Users-dev-webapp/one.jsonl", HourlyBuckets: map[string]*TemporalBucket{
+		"2025-01-31 23": bucket("2025-01-31 23", 100, 80),
+		"2025-02-01 00": bucket("2025-02-01 00", 50, 40),
+	}, SessionHints: []SessionHint{{StartTime: time.Date(2025, 1, 31, 22, 0, 0, 0, time.UTC), EndTime: time.Date(2025, 2, 1, 0, 30, 0, 0, time.UTC)}}}
+	second := &FileSummary{Path: "/data/-Users-dev-webapp/two.jsonl", HourlyBuckets: map[string]*TemporalBucket{
+		"2025-01-31 23": bucket("2025-01-31 23", 300, 200),
+	}}
+
+	archive := NewMonthArchive("/data", time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, "2025-01", archive.Month)
+	archive.Add(ArchivedFile{PathHash: "one"}, first)
+	archive.Add(ArchivedFile{PathHash: "two"}, second)
+	archive.Add(ArchivedFile{PathHash: "two"}, nil)
+
+	require.Len(t, archive.Files, 2)
+	assert.True(t, archive.HasFile("one"))
+	require.Len(t, archive.Projects, 1)
+	project := archive.Projects["/data/-Users-dev-webapp"]
+	assert.Equal(t, filepath.Join("/data/-Users-dev-webapp", "2025-01.jsonl"), project.Path)
+	require.Len(t, project.HourlyBuckets, 1, "buckets of other months are left out")
+	stat := project.HourlyBuckets["2025-01-31 23"].ModelStats["claude-sonnet-4"]
+	assert.Equal(t, 4, stat.EntryCount)
+	assert.Equal(t, 400, stat.InputTokens)
+	assert.Equal(t, 200, stat.LargestInputTokens)
+	assert.Equal(t, 4, project.EntryCount)
+	assert.Len(t, project.SessionHints, 1)
+}
+
+func TestArchiveStore_SaveAndLoad(t *testing.T) {
+	dir := t.TempDir()
+	enc, err := NewEncryptor(make([]byte, 32))
+	require.NoError(t, err)
+	store := NewArchiveStore(dir, enc)
+
+	archives, err := store.Load("/data")
+	require.NoError(t, err)
+	assert.Empty(t, archives)
+
+	for _, month := range []time.Month{time.February, time.January} {
+		archive := NewMonthArchive("/data", time.Date(2025, month, 1, 0, 0, 0, 0, time.UTC))
+		archive.Add(ArchivedFile{PathHash: PathHash("/data/-Users-dev-webapp/one.jsonl")}, nil)
+		require.NoError(t, store.Save(archive))
+	}
+	require.NoError(t, store.Save(NewMonthArchive("/other", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))))
+
+	archives, err = store.Load("/data")
+	require.NoError(t, err)
+	require.Len(t, archives, 2)
+	assert.Equal(t, "2025-01", archives[0].Month)
+	assert.Equal(t, "2025-02", archives[1].Month)
+	assert.Len(t, archives[0].Files, 1)
+
+	// Archives are encrypted along with the cache and only keep hashed paths
+	matches, err := filepath.Glob(filepath.Join(dir, archivesDirName, "*", "*.json"))
+	require.NoError(t, err)
+	require.Len(t, matches, 3)
+	data, err := os.ReadFile(matches[0])
+	require.NoError(t, err)
+	assert.True(t, IsSealed(data))
+	assert.False(t, strings.Contains(string(data), "webapp"))
+
+	_, err = NewArchiveStore(dir, nil).Load("/data")
+	assert.Error(t, err)
+}
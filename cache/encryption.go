@@ -0,0 +1,330 @@
+package cache
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Sources of the key used to encrypt cached summaries and the command history at rest
+const (
+	EncryptionOff        = "off"
+	EncryptionKeychain   = "keychain"   // Random key stored in the macOS keychain or the Secret Service (secret-tool)
+	EncryptionPassphrase = "passphrase" // Key derived from the passphrase in PassphraseEnv
+)
+
+// PassphraseEnv is the environment variable holding the passphrase when encryption uses a passphrase
+const PassphraseEnv = "CLAUDECAT_CACHE_PASSPHRASE"
+
+const (
+	// encryptionKeyFile records the key source, the passphrase salt and a value to verify the key against
+	encryptionKeyFile = "encryption.json"
+	// sealedMagic prefixes encrypted data; it is followed by the GCM nonce and the ciphertext
+	sealedMagic = "CCE1"
+	// passphraseIterations is the PBKDF2-SHA256 work factor for passphrase-derived keys
+	passphraseIterations = 600000
+	// keyCheckPlaintext is sealed into the key file to detect a wrong passphrase or keychain key
+	keyCheckPlaintext = "claudecat"
+
+	keychainService = "claudecat"
+	keychainAccount = "cache-key"
+)
+
+// Encryptor encrypts data at rest with AES-256-GCM
+type Encryptor struct {
+	aead cipher.AEAD
+}
+
+// encryptionKeyInfo is the content of the key file; it never contains the key itself
+type encryptionKeyInfo struct {
+	Source string `json:"source"`
+	Salt   []byte `json:"salt,omitempty"` // PBKDF2 salt of passphrase-derived keys
+	Check  []byte `json:"check"`          // keyCheckPlaintext sealed with the key
+}
+
+// encryptors memoizes LoadEncryptor, since deriving or fetching a key is slow
+var (
+	encryptorsMu sync.Mutex
+	encryptors   = make(map[string]*Encryptor)
+)
+
+// NewEncryptor creates an encryptor from a 32-byte key
+func NewEncryptor(key []byte) (*Encryptor, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("encryption key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Encryptor{aead: aead}, nil
+}
+
+// IsSealed reports whether data was produced by Encryptor.Seal
+func IsSealed(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(sealedMagic))
+}
+
+// Seal encrypts plaintext with a random nonce
+func (e *Encryptor) Seal(plaintext []byte) ([]byte, error) {
+	sealed := make([]byte, len(sealedMagic)+e.aead.NonceSize(), len(sealedMagic)+e.aead.NonceSize()+len(plaintext)+e.aead.Overhead())
+	copy(sealed, sealedMagic)
+	nonce := sealed[len(sealedMagic):]
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return e.aead.Seal(sealed, nonce, plaintext, nil), nil
+}
+
+// Open decrypts data produced by Seal, failing if it was sealed with another key or tampered with
+func (e *Encryptor) Open(data []byte) ([]byte, error) {
+	if !IsSealed(data) || len(data) < len(sealedMagic)+e.aead.NonceSize() {
+		return nil, fmt.Errorf("data is not encrypted")
+	}
+	data = data[len(sealedMagic):]
+	nonce, ciphertext := data[:e.aead.NonceSize()], data[e.aead.NonceSize():]
+	plaintext, err := e.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: wrong key or corrupted data")
+	}
+	return plaintext, nil
+}
+
+// SealFile encrypts the content of a file stored next to the cache; a nil encryptor leaves it as it is
+func (e *Encryptor) SealFile(data []byte) ([]byte, error) {
+	if e == nil {
+		return data, nil
+	}
+	return e.Seal(data)
+}
+
+// OpenFile returns the content of a file written with SealFile. Unencrypted content is returned as it
+// is, so files written before encryption was enabled stay readable.
+func (e *Encryptor) OpenFile(data []byte) ([]byte, error) {
+	if !IsSealed(data) {
+		return data, nil
+	}
+	if e == nil {
+		return nil, fmt.Errorf("file is encrypted but cache encryption is off")
+	}
+	return e.Open(data)
+}
+
+// LoadEncryptor returns the encryptor for the given key source, or nil when encryption is off.
+// The key is verified against the key file in dir, which is created on first use, so that a wrong
+// passphrase is reported instead of silently producing unreadable data.
+func LoadEncryptor(source, dir string) (*Encryptor, error) {
+	if source == "" || source == EncryptionOff {
+		return nil, nil
+	}
+
+	passphrase := os.Getenv(PassphraseEnv)
+	memoKey := source + "\x00" + dir + "\x00" + passphrase
+	encryptorsMu.Lock()
+	defer encryptorsMu.Unlock()
+	if enc, ok := encryptors[memoKey]; ok {
+		return enc, nil
+	}
+
+	keyPath := filepath.Join(dir, encryptionKeyFile)
+	var info encryptionKeyInfo
+	exists := false
+	if data, err := os.ReadFile(keyPath); err == nil {
+		if err := json.Unmarshal(data, &info); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", keyPath, err)
+		}
+		exists = info.Source == source
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", keyPath, err)
+	}
+	if !exists && info.Source != "" {
+		return nil, fmt.Errorf("the cache in %s is encrypted with a %s key; delete %s to start over with an empty cache",
+			dir, info.Source, keyPath)
+	}
+
+	var key []byte
+	switch source {
+	case EncryptionPassphrase:
+		if passphrase == "" {
+			return nil, fmt.Errorf("cache encryption uses a passphrase but %s is not set", PassphraseEnv)
+		}
+		if !exists {
+			info.Salt = make([]byte, 16)
+			if _, err := rand.Read(info.Salt); err != nil {
+				return nil, fmt.Errorf("failed to generate salt: %w", err)
+			}
+		}
+		derived, err := pbkdf2.Key(sha256.New, passphrase, info.Salt, passphraseIterations, 32)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive key: %w", err)
+		}
+		key = derived
+	case EncryptionKeychain:
+		stored, err := keychainKey(!exists)
+		if err != nil {
+			return nil, err
+		}
+		key = stored
+	default:
+		return nil, fmt.Errorf("unknown encryption key source: %s", source)
+	}
+
+	enc, err := NewEncryptor(key)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		if check, err := enc.Open(info.Check); err != nil || string(check) != keyCheckPlaintext {
+			return nil, fmt.Errorf("the %s does not match the key the cache in %s was encrypted with", keySourceName(source), dir)
+		}
+	} else {
+		info.Source = source
+		if info.Check, err = enc.Seal([]byte(keyCheckPlaintext)); err != nil {
+			return nil, err
+		}
+		data, err := json.MarshalIndent(info, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal key file: %w", err)
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create cache directory: %w", err)
+		}
+		if err := os.WriteFile(keyPath, data, 0600); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", keyPath, err)
+		}
+	}
+
+	encryptors[memoKey] = enc
+	return enc, nil
+}
+
+// keySourceName describes a key source in error messages
+func keySourceName(source string) string {
+	if source == EncryptionPassphrase {
+		return "passphrase in " + PassphraseEnv
+	}
+	return "keychain key"
+}
+
+// keychainKey reads the cache key from the OS keychain, generating and storing a random one if create is set
+func keychainKey(create bool) ([]byte, error) {
+	if runtime.GOOS != "darwin" && runtime.GOOS != "linux" && runtime.GOOS != "freebsd" {
+		return nil, fmt.Errorf("keychain encryption is not supported on %s; use cache.encryption: passphrase", runtime.GOOS)
+	}
+
+	if stored, err := readKeychain(); err == nil && stored != "" {
+		key, err := hex.DecodeString(stored)
+		if err != nil {
+			return nil, fmt.Errorf("malformed cache key in the keychain: %w", err)
+		}
+		return key, nil
+	} else if !create {
+		return nil, fmt.Errorf("cache key not found in the keychain: %v", err)
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	if err := writeKeychain(hex.EncodeToString(key)); err != nil {
+		return nil, fmt.Errorf("failed to store cache key in the keychain: %w", err)
+	}
+	return key, nil
+}
+
+// readKeychain returns the hex-encoded key stored in the keychain
+func readKeychain() (string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "darwin" {
+		cmd = exec.Command("security", "find-generic-password", "-s", keychainService, "-a", keychainAccount, "-w")
+	} else {
+		cmd = exec.Command("secret-tool", "lookup", "service", keychainService, "account", keychainAccount)
+	}
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	stored := strings.TrimSpace(string(output))
+	if stored == "" {
+		return "", errors.New("no key stored")
+	}
+	return stored, nil
+}
+
+// writeKeychain stores the hex-encoded key, passing it on stdin so it never appears in a process list
+func writeKeychain(key string) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "darwin" {
+		cmd = exec.Command("security", "-i")
+		cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", keychainService, keychainAccount, key))
+	} else {
+		cmd = exec.Command("secret-tool", "store", "--label=claudecat cache key", "service", keychainService, "account", keychainAccount)
+		cmd.Stdin = strings.NewReader(key)
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// decodeSummaryFile decrypts a summary file if it is encrypted and decodes it, see decodeFileSummary
+func (c *FileBasedSummaryCache) decodeSummaryFile(data []byte) (*FileSummary, bool, error) {
+	if IsSealed(data) {
+		if c.enc == nil {
+			return nil, false, fmt.Errorf("summary is encrypted but cache encryption is off")
+		}
+		plaintext, err := c.enc.Open(data)
+		if err != nil {
+			return nil, false, err
+		}
+		data = plaintext
+	}
+	return decodeFileSummary(data)
+}
+
+// encodeSummaryFile marshals a summary, encrypting it when the cache has an encryptor
+func (c *FileBasedSummaryCache) encodeSummaryFile(summary *FileSummary) ([]byte, error) {
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal summary: %w", err)
+	}
+	if c.enc == nil {
+		return data, nil
+	}
+	return c.enc.Seal(data)
+}
+
+// sealFileInPlace atomically replaces an unencrypted file with its encrypted content, keeping its
+// modification time so that the quota and trash expiry are unaffected
+func (c *FileBasedSummaryCache) sealFileInPlace(path string, data []byte, info os.FileInfo) error {
+	sealed, err := c.enc.Seal(data)
+	if err != nil {
+		return err
+	}
+	tmpFile := path + ".tmp"
+	if err := os.WriteFile(tmpFile, sealed, 0644); err != nil {
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	if err := os.Rename(tmpFile, path); err != nil {
+		os.Remove(tmpFile)
+		return fmt.Errorf("failed to rename cache file: %w", err)
+	}
+	return os.Chtimes(path, info.ModTime(), info.ModTime())
+}
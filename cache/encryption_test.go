@@ -0,0 +1,176 @@
+package cache
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptor_SealAndOpen(t *testing.T) {
+	enc, err := NewEncryptor(bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+
+	sealed, err := enc.Seal([]byte("secret summary"))
+	require.NoError(t, err)
+	assert.True(t, IsSealed(sealed))
+	assert.NotContains(t, string(sealed), "secret")
+
+	plaintext, err := enc.Open(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "secret summary", string(plaintext))
+
+	other, err := NewEncryptor(bytes.Repeat([]byte{2}, 32))
+	require.NoError(t, err)
+	_, err = other.Open(sealed)
+	assert.Error(t, err)
+
+	_, err = NewEncryptor([]byte("short"))
+	assert.Error(t, err)
+}
+
+func TestLoadEncryptor_Passphrase(t *testing.T) {
+	dir := t.TempDir()
+
+	enc, err := LoadEncryptor(EncryptionOff, dir)
+	require.NoError(t, err)
+	assert.Nil(t, enc)
+
+	_, err = LoadEncryptor(EncryptionPassphrase, dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), PassphraseEnv)
+
+	t.Setenv(PassphraseEnv, "correct horse")
+	enc, err = LoadEncryptor(EncryptionPassphrase, dir)
+	require.NoError(t, err)
+	require.NotNil(t, enc)
+	info, err := os.Stat(filepath.Join(dir, encryptionKeyFile))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// The same passphrase yields the same key; another one is rejected
+	sealed, err := enc.Seal([]byte("data"))
+	require.NoError(t, err)
+	encryptors = make(map[string]*Encryptor)
+	again, err := LoadEncryptor(EncryptionPassphrase, dir)
+	require.NoError(t, err)
+	_, err = again.Open(sealed)
+	require.NoError(t, err)
+
+	t.Setenv(PassphraseEnv, "wrong")
+	_, err = LoadEncryptor(EncryptionPassphrase, dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not match")
+
+	_, err = LoadEncryptor(EncryptionKeychain, dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "encrypted with a passphrase key")
+}
+
+func TestFileBasedSummaryCache_Encrypted(t *testing.T) {
+	c, dir := newTestSummaryCache(t)
+	require.NoError(t, c.SetFileSummary(&FileSummary{AbsolutePath: "/data/plain.jsonl", EntryCount: 3}))
+
+	// Existing plaintext summaries are encrypted when the cache is reopened with a key
+	t.Setenv(PassphraseEnv, "correct horse")
+	encrypted, err := OpenFileBasedSummaryCache(dir, EncryptionPassphrase)
+	require.NoError(t, err)
+	assert.True(t, encrypted.HasFileSummary("/data/plain.jsonl"))
+	require.NoError(t, encrypted.SetFileSummary(&FileSummary{AbsolutePath: "/data/new.jsonl", EntryCount: 5}))
+
+	files := 0
+	require.NoError(t, filepath.Walk(encrypted.baseDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !strings.HasSuffix(path, ".json") {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.True(t, IsSealed(data), path)
+		files++
+		return nil
+	}))
+	assert.Equal(t, 2, files)
+
+	reloaded, err := OpenFileBasedSummaryCache(dir, EncryptionPassphrase)
+	require.NoError(t, err)
+	summary, err := reloaded.GetFileSummary("/data/new.jsonl")
+	require.NoError(t, err)
+	assert.Equal(t, 5, summary.EntryCount)
+
+	// Without the key the encrypted summaries are unreadable and rebuilt rather than misparsed
+	unencrypted, err := NewFileBasedSummaryCache(dir)
+	require.NoError(t, err)
+	assert.False(t, unencrypted.HasFileSummary("/data/new.jsonl"))
+}
+
+func TestFileBasedSummaryCache_EncryptedEvictionLedger(t *testing.T) {
+	c, dir := newTestSummaryCache(t)
+	for _, path := range []string{"/data/secret-project/one.jsonl", "/data/secret-project/two.jsonl"} {
+		require.NoError(t, c.SetFileSummary(&FileSummary{AbsolutePath: path, EntryCount: 1}))
+	}
+	c.SetMaxDiskSize(c.DiskUsage() / 2)
+	require.NoError(t, c.saveEvictionLedger())
+
+	// A plaintext ledger is keyed by the summary file names and read back once encryption is enabled
+	ledger := filepath.Join(dir, evictionLedgerFile)
+	data, err := os.ReadFile(ledger)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), summaryKey("/data/secret-project/one.jsonl"))
+
+	t.Setenv(PassphraseEnv, "correct horse")
+	encrypted, err := OpenFileBasedSummaryCache(dir, EncryptionPassphrase)
+	require.NoError(t, err)
+	assert.Equal(t, 1, encrypted.evictions[summaryKey("/data/secret-project/one.jsonl")].Count)
+
+	require.NoError(t, encrypted.saveEvictionLedger())
+	data, err = os.ReadFile(ledger)
+	require.NoError(t, err)
+	assert.True(t, IsSealed(data))
+	assert.NotContains(t, string(data), "secret-project")
+
+	reloaded, err := OpenFileBasedSummaryCache(dir, EncryptionPassphrase)
+	require.NoError(t, err)
+	assert.Equal(t, encrypted.evictions, reloaded.evictions)
+
+	// Without the key the ledger is ignored rather than misread
+	plain, err := NewFileBasedSummaryCache(dir)
+	require.NoError(t, err)
+	assert.Empty(t, plain.evictions)
+}
+
+func TestEncryptor_SealFileAndOpenFile(t *testing.T) {
+	enc, err := NewEncryptor(bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+	var none *Encryptor
+
+	tests := []struct {
+		name    string
+		sealer  *Encryptor
+		opener  *Encryptor
+		wantErr bool
+	}{
+		{"encrypted", enc, enc, false},
+		{"plaintext read with a key", none, enc, false},
+		{"plaintext", none, none, false},
+		{"encrypted read without a key", enc, none, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sealed, err := tt.sealer.SealFile([]byte(`{"path":"/data/a.jsonl"}`))
+			require.NoError(t, err)
+			assert.Equal(t, tt.sealer != nil, IsSealed(sealed))
+
+			data, err := tt.opener.OpenFile(sealed)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, `{"path":"/data/a.jsonl"}`, string(data))
+		})
+	}
+}
@@ -2,24 +2,41 @@ package cache
 
 import (
 	"crypto/md5"
-	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/penwyp/claudecat/logging"
+	"github.com/penwyp/claudecat/models"
 )
 
+// DefaultTrashTTL is how long invalidated summaries are kept in the trash before being purged
+const DefaultTrashTTL = 7 * 24 * time.Hour
+
 // FileBasedSummaryCache provides a file-based cache for file summaries with memory preloading
 type FileBasedSummaryCache struct {
 	baseDir  string
+	trashDir string                  // Invalidated summaries are moved here instead of being deleted
+	trashTTL time.Duration           // Trashed summaries older than this are purged
 	memCache map[string]*FileSummary // Memory cache for fast access
+	redacted map[string]bool         // Cache files of redacted summaries, which are loaded on first lookup
 	mu       sync.RWMutex
 	stats    FileBasedCacheStats
+	enc      *Encryptor // Encrypts summary files at rest when set
+	readOnly bool       // Nothing is written, not even migrations; see OpenFileBasedSummaryCacheReadOnly
+
+	// Disk quota; the oldest summaries are evicted once the summary files exceed maxDiskSize
+	maxDiskSize  int64
+	disk         map[string]diskEntry // Summary files on disk by cache file path
+	diskSize     int64
+	evictions    map[string]evictionRecord // Evictions by summary key, persisted in ledgerPath
+	ledgerPath   string
+	thrashWarned bool
 }
 
 // FileBasedCacheStats tracks cache statistics
@@ -30,20 +47,70 @@ type FileBasedCacheStats struct {
 	Deletes    int64
 	Errors     int64
 	MemoryHits int64 // Hits from memory cache
+	Migrations int64 // Summaries upgraded from an older schema version
+
+	Evictions               int64 // Summaries deleted to stay within the disk quota
+	EvictedBytes            int64
+	ColdParsesAfterEviction int64 // Summaries rebuilt after the disk quota evicted them
 }
 
 // NewFileBasedSummaryCache creates a new file-based summary cache
 func NewFileBasedSummaryCache(persistPath string) (*FileBasedSummaryCache, error) {
+	return NewFileBasedSummaryCacheWithEncryptor(persistPath, nil)
+}
+
+// OpenFileBasedSummaryCache creates a cache encrypted with the key from the given source, see LoadEncryptor
+func OpenFileBasedSummaryCache(persistPath, encryption string) (*FileBasedSummaryCache, error) {
+	enc, err := LoadEncryptor(encryption, persistPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load cache encryption key: %w", err)
+	}
+	return NewFileBasedSummaryCacheWithEncryptor(persistPath, enc)
+}
+
+// NewFileBasedSummaryCacheWithEncryptor creates a cache whose summary files are encrypted with enc.
+// Unencrypted summaries left from before encryption was enabled are encrypted in place on load;
+// summaries that cannot be decrypted are skipped and rebuilt from their usage files.
+func NewFileBasedSummaryCacheWithEncryptor(persistPath string, enc *Encryptor) (*FileBasedSummaryCache, error) {
+	return newFileBasedSummaryCache(persistPath, enc, false)
+}
+
+// OpenFileBasedSummaryCacheReadOnly opens a cache for inspection without creating, migrating or writing
+// anything. A cache without a key file cannot hold encrypted summaries, so no key is created for it.
+func OpenFileBasedSummaryCacheReadOnly(persistPath, encryption string) (*FileBasedSummaryCache, error) {
+	var enc *Encryptor
+	if _, err := os.Stat(filepath.Join(persistPath, encryptionKeyFile)); err == nil {
+		if enc, err = LoadEncryptor(encryption, persistPath); err != nil {
+			return nil, fmt.Errorf("failed to load cache encryption key: %w", err)
+		}
+	}
+	return newFileBasedSummaryCache(persistPath, enc, true)
+}
+
+// newFileBasedSummaryCache creates a cache in persistPath and preloads its summaries
+func newFileBasedSummaryCache(persistPath string, enc *Encryptor, readOnly bool) (*FileBasedSummaryCache, error) {
 	// Create base directory if it doesn't exist
 	summariesDir := filepath.Join(persistPath, "summaries")
-	if err := os.MkdirAll(summariesDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	if !readOnly {
+		if err := os.MkdirAll(summariesDir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create cache directory: %w", err)
+		}
 	}
 
 	cache := &FileBasedSummaryCache{
 		baseDir:  summariesDir,
+		trashDir: filepath.Join(persistPath, "trash"),
+		trashTTL: DefaultTrashTTL,
 		memCache: make(map[string]*FileSummary),
+		redacted: make(map[string]bool),
+		enc:      enc,
+		readOnly: readOnly,
+
+		disk:       make(map[string]diskEntry),
+		evictions:  make(map[string]evictionRecord),
+		ledgerPath: filepath.Join(persistPath, evictionLedgerFile),
 	}
+	cache.loadEvictionLedger()
 
 	// Preload existing summaries into memory
 	if err := cache.preloadSummaries(); err != nil {
@@ -76,14 +143,40 @@ func (c *FileBasedSummaryCache) preloadSummaries() error {
 			return nil // Skip this file
 		}
 
-		var summary FileSummary
-		if err := json.Unmarshal(data, &summary); err != nil {
-			logging.LogDebugf("Failed to unmarshal cache file %s: %v", path, err)
-			return nil // Skip this file
+		summary, migrated, err := c.decodeSummaryFile(data)
+		if err != nil {
+			logging.LogDebugf("Skipping cache file %s: %v", path, err)
+			return nil // Skip this file; it will be rebuilt from the source file
+		}
+		if c.enc != nil && !IsSealed(data) && !migrated && !c.readOnly {
+			if err := c.sealFileInPlace(path, data, info); err != nil {
+				logging.LogDebugf("Failed to encrypt cache file %s: %v", path, err)
+			} else if info, err = os.Stat(path); err != nil {
+				return nil
+			}
+		}
+
+		// Persist the upgraded summary so the migration only runs once
+		if migrated && !c.readOnly {
+			if err := c.writeSummaryFile(summary); err != nil {
+				logging.LogDebugf("Failed to rewrite migrated cache file %s: %v", path, err)
+			} else {
+				c.stats.Migrations++
+			}
+		}
+
+		// Redacted summaries no longer know their path; they are loaded on first lookup instead
+		if summary.Redacted {
+			c.trackDiskFile(path, "", info.Size(), info.ModTime())
+			c.redacted[path] = true
+			return nil
+		}
+		if !migrated || c.readOnly {
+			c.trackDiskFile(path, summary.AbsolutePath, info.Size(), info.ModTime())
 		}
 
 		// Add to memory cache
-		c.memCache[summary.AbsolutePath] = &summary
+		c.memCache[summary.AbsolutePath] = summary
 		count++
 
 		if count%100 == 0 {
@@ -101,15 +194,19 @@ func (c *FileBasedSummaryCache) preloadSummaries() error {
 	return nil
 }
 
-// getCacheFilePath returns the cache file path for a given absolute path
-func (c *FileBasedSummaryCache) getCacheFilePath(absolutePath string) string {
-	// Calculate MD5 hash of the absolute path
+// summaryKey returns the MD5 hash of an absolute path that names its summary file
+func summaryKey(absolutePath string) string {
 	h := md5.New()
 	if _, err := io.WriteString(h, absolutePath); err != nil {
 		// This should never fail for hash operations, but handle gracefully
 		logging.LogWarnf("Failed to write to hash: %v", err)
 	}
-	hash := fmt.Sprintf("%x", h.Sum(nil))
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// getCacheFilePath returns the cache file path for a given absolute path
+func (c *FileBasedSummaryCache) getCacheFilePath(absolutePath string) string {
+	hash := summaryKey(absolutePath)
 
 	// Use first 2 characters as subdirectory for better file system performance
 	subDir := hash[:2]
@@ -153,17 +250,29 @@ func (c *FileBasedSummaryCache) GetFileSummary(absolutePath string) (*FileSummar
 		return nil, fmt.Errorf("failed to read cache file: %w", err)
 	}
 
-	var summary FileSummary
-	if err := json.Unmarshal(data, &summary); err != nil {
+	summary, migrated, err := c.decodeSummaryFile(data)
+	if err != nil {
 		c.stats.Errors++
-		return nil, fmt.Errorf("failed to unmarshal summary: %w", err)
+		return nil, err
+	}
+	if summary.Redacted {
+		// The caller knows the real path; keep it in memory only
+		summary.AbsolutePath = absolutePath
+		migrated = false
+	}
+	if migrated {
+		if err := c.writeSummaryFile(summary); err != nil {
+			logging.LogDebugf("Failed to rewrite migrated cache file %s: %v", cacheFile, err)
+		} else {
+			c.stats.Migrations++
+		}
 	}
 
 	// Add to memory cache
-	c.memCache[absolutePath] = &summary
+	c.memCache[absolutePath] = summary
 	c.stats.Hits++
 
-	return &summary, nil
+	return summary, nil
 }
 
 // SetFileSummary stores a file summary in cache
@@ -172,39 +281,53 @@ func (c *FileBasedSummaryCache) SetFileSummary(summary *FileSummary) error {
 	defer c.mu.Unlock()
 
 	// Update memory cache
+	summary.SchemaVersion = CurrentSchemaVersion
+	summary.Redacted = false
 	c.memCache[summary.AbsolutePath] = summary
+	delete(c.redacted, c.getCacheFilePath(summary.AbsolutePath))
 
 	// Write to disk
+	if err := c.writeSummaryFile(summary); err != nil {
+		c.stats.Errors++
+		return err
+	}
+
+	c.stats.Writes++
+	c.noteRebuilt(summary.AbsolutePath)
+	c.enforceDiskQuota(c.getCacheFilePath(summary.AbsolutePath))
+	return nil
+}
+
+// writeSummaryFile atomically writes summary to its cache file; callers must hold the lock or own the cache
+func (c *FileBasedSummaryCache) writeSummaryFile(summary *FileSummary) error {
+	if c.readOnly {
+		return fmt.Errorf("cache is open read-only")
+	}
 	cacheFile := c.getCacheFilePath(summary.AbsolutePath)
 	cacheDir := filepath.Dir(cacheFile)
 
 	// Create subdirectory if needed
 	if err := os.MkdirAll(cacheDir, 0755); err != nil {
-		c.stats.Errors++
 		return fmt.Errorf("failed to create cache subdirectory: %w", err)
 	}
 
-	data, err := json.MarshalIndent(summary, "", "  ")
+	data, err := c.encodeSummaryFile(summary)
 	if err != nil {
-		c.stats.Errors++
-		return fmt.Errorf("failed to marshal summary: %w", err)
+		return err
 	}
 
 	// Write to temporary file first
 	tmpFile := cacheFile + ".tmp"
 	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
-		c.stats.Errors++
 		return fmt.Errorf("failed to write cache file: %w", err)
 	}
 
 	// Atomic rename
 	if err := os.Rename(tmpFile, cacheFile); err != nil {
-		c.stats.Errors++
 		os.Remove(tmpFile) // Clean up
 		return fmt.Errorf("failed to rename cache file: %w", err)
 	}
-
-	c.stats.Writes++
+	c.trackDiskFile(cacheFile, summary.AbsolutePath, int64(len(data)), time.Now())
 	return nil
 }
 
@@ -213,11 +336,15 @@ func (c *FileBasedSummaryCache) HasFileSummary(absolutePath string) bool {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	_, exists := c.memCache[absolutePath]
-	return exists
+	if _, exists := c.memCache[absolutePath]; exists {
+		return true
+	}
+
+	// Redacted summaries are only on disk until first read
+	return c.redacted[c.getCacheFilePath(absolutePath)]
 }
 
-// InvalidateFileSummary removes a file summary from cache
+// InvalidateFileSummary removes a file summary from cache, keeping a restorable copy in the trash
 func (c *FileBasedSummaryCache) InvalidateFileSummary(absolutePath string) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -225,9 +352,11 @@ func (c *FileBasedSummaryCache) InvalidateFileSummary(absolutePath string) error
 	// Remove from memory cache
 	delete(c.memCache, absolutePath)
 
-	// Remove from disk
+	// Move to trash
 	cacheFile := c.getCacheFilePath(absolutePath)
-	if err := os.Remove(cacheFile); err != nil {
+	delete(c.redacted, cacheFile)
+	c.untrackDiskFile(cacheFile)
+	if err := c.moveToTrash(cacheFile); err != nil {
 		if !os.IsNotExist(err) {
 			c.stats.Errors++
 			return fmt.Errorf("failed to delete cache file: %w", err)
@@ -259,13 +388,29 @@ func (c *FileBasedSummaryCache) BatchSet(summaries []*FileSummary) error {
 	return nil
 }
 
-// Clear removes all summaries from cache
+// Clear removes all summaries from cache, keeping restorable copies in the trash
 func (c *FileBasedSummaryCache) Clear() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	// Clear memory cache
 	c.memCache = make(map[string]*FileSummary)
+	c.redacted = make(map[string]bool)
+	c.disk = make(map[string]diskEntry)
+	c.diskSize = 0
+
+	// Move every summary to the trash before removing the directory
+	if err := filepath.Walk(c.baseDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !strings.HasSuffix(path, ".json") {
+			return nil
+		}
+		if err := c.moveToTrash(path); err != nil {
+			logging.LogWarnf("Failed to move %s to trash: %v", path, err)
+		}
+		return nil
+	}); err != nil {
+		logging.LogWarnf("Failed to walk cache directory %s: %v", c.baseDir, err)
+	}
 
 	// Remove entire summaries directory
 	if err := os.RemoveAll(c.baseDir); err != nil {
@@ -277,10 +422,168 @@ func (c *FileBasedSummaryCache) Clear() error {
 		return fmt.Errorf("failed to recreate cache directory: %w", err)
 	}
 
+	if _, err := c.purgeTrash(); err != nil {
+		logging.LogWarnf("Failed to purge cache trash: %v", err)
+	}
+
 	logging.LogInfof("Cache cleared")
 	return nil
 }
 
+// SetRedactAfterDays redacts IDs from summaries of files not modified in the last days; zero disables
+func (c *FileBasedSummaryCache) SetRedactAfterDays(days int) {
+	if _, err := c.RedactExpiredIDs(models.RedactionCutoff(days, time.Now())); err != nil {
+		logging.LogWarnf("Failed to redact cached summaries: %v", err)
+	}
+}
+
+// SetTrashTTL sets how long invalidated summaries stay restorable; zero keeps the default
+func (c *FileBasedSummaryCache) SetTrashTTL(ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if ttl > 0 {
+		c.trashTTL = ttl
+	}
+	if _, err := c.purgeTrash(); err != nil {
+		logging.LogWarnf("Failed to purge cache trash: %v", err)
+	}
+}
+
+// moveToTrash moves a summary file into the trash, stamping it with the time of deletion.
+// Callers must hold the write lock.
+func (c *FileBasedSummaryCache) moveToTrash(cacheFile string) error {
+	if err := os.MkdirAll(c.trashDir, 0755); err != nil {
+		return fmt.Errorf("failed to create trash directory: %w", err)
+	}
+
+	trashFile := filepath.Join(c.trashDir, filepath.Base(cacheFile))
+	if err := os.Rename(cacheFile, trashFile); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if err := os.Chtimes(trashFile, now, now); err != nil {
+		logging.LogDebugf("Failed to stamp trashed summary %s: %v", trashFile, err)
+	}
+	return nil
+}
+
+// PurgeTrash permanently removes trashed summaries older than the trash TTL
+func (c *FileBasedSummaryCache) PurgeTrash() (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.purgeTrash()
+}
+
+// purgeTrash removes expired trash entries; callers must hold the write lock
+func (c *FileBasedSummaryCache) purgeTrash() (int, error) {
+	files, err := os.ReadDir(c.trashDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read trash directory: %w", err)
+	}
+
+	cutoff := time.Now().Add(-c.trashTTL)
+	purged := 0
+	for _, file := range files {
+		info, err := file.Info()
+		if err != nil || file.IsDir() || !info.ModTime().Before(cutoff) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(c.trashDir, file.Name())); err != nil {
+			logging.LogWarnf("Failed to purge trashed summary %s: %v", file.Name(), err)
+			continue
+		}
+		purged++
+	}
+
+	if purged > 0 {
+		logging.LogInfof("Purged %d expired summaries from cache trash", purged)
+	}
+	return purged, nil
+}
+
+// RestoreFileSummaries moves trashed summaries for path back into the cache.
+// path may be a single usage file or a directory, in which case all summaries beneath it are restored.
+func (c *FileBasedSummaryCache) RestoreFileSummaries(path string) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, err := c.purgeTrash(); err != nil {
+		return 0, err
+	}
+
+	files, err := os.ReadDir(c.trashDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read trash directory: %w", err)
+	}
+
+	prefix := strings.TrimSuffix(path, string(filepath.Separator)) + string(filepath.Separator)
+	restored := 0
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+
+		trashFile := filepath.Join(c.trashDir, file.Name())
+		data, err := os.ReadFile(trashFile)
+		if err != nil {
+			logging.LogDebugf("Failed to read trashed summary %s: %v", trashFile, err)
+			continue
+		}
+
+		summary, _, err := c.decodeSummaryFile(data)
+		if err != nil {
+			logging.LogDebugf("Skipping trashed summary %s: %v", trashFile, err)
+			continue
+		}
+		if summary.AbsolutePath != path && !strings.HasPrefix(summary.AbsolutePath, prefix) {
+			continue
+		}
+
+		// Redacted summaries are restored by their cache file name and loaded on first lookup
+		if summary.Redacted {
+			cacheFile := filepath.Join(c.baseDir, file.Name()[:2], file.Name())
+			if err := os.MkdirAll(filepath.Dir(cacheFile), 0755); err != nil {
+				return restored, fmt.Errorf("failed to create cache subdirectory: %w", err)
+			}
+			if err := os.Rename(trashFile, cacheFile); err != nil {
+				return restored, fmt.Errorf("failed to restore %s: %w", file.Name(), err)
+			}
+			c.trackDiskFile(cacheFile, "", int64(len(data)), time.Now())
+			c.redacted[cacheFile] = true
+			restored++
+			continue
+		}
+
+		// A newer summary has been built since; the trashed copy is obsolete
+		if _, exists := c.memCache[summary.AbsolutePath]; exists {
+			continue
+		}
+
+		cacheFile := c.getCacheFilePath(summary.AbsolutePath)
+		if err := os.MkdirAll(filepath.Dir(cacheFile), 0755); err != nil {
+			return restored, fmt.Errorf("failed to create cache subdirectory: %w", err)
+		}
+		if err := os.Rename(trashFile, cacheFile); err != nil {
+			return restored, fmt.Errorf("failed to restore %s: %w", summary.AbsolutePath, err)
+		}
+		c.trackDiskFile(cacheFile, summary.AbsolutePath, int64(len(data)), time.Now())
+
+		c.memCache[summary.AbsolutePath] = summary
+		restored++
+	}
+
+	return restored, nil
+}
+
 // GetStats returns cache statistics
 func (c *FileBasedSummaryCache) GetStats() map[string]interface{} {
 	c.mu.RLock()
@@ -319,24 +622,45 @@ func (c *FileBasedSummaryCache) GetStats() map[string]interface{} {
 	}
 
 	return map[string]interface{}{
-		"cached_files":     len(c.memCache),
-		"disk_files":       fileCount,
-		"total_entries":    totalEntries,
-		"total_cost":       totalCost,
-		"total_tokens":     totalTokens,
-		"cache_size_bytes": totalSize,
-		"cache_size_mb":    float64(totalSize) / 1024 / 1024,
-		"hits":             c.stats.Hits,
-		"memory_hits":      c.stats.MemoryHits,
-		"misses":           c.stats.Misses,
-		"writes":           c.stats.Writes,
-		"deletes":          c.stats.Deletes,
-		"errors":           c.stats.Errors,
-		"hit_rate":         hitRate,
-		"persist_path":     c.baseDir,
+		"cached_files":               len(c.memCache),
+		"disk_files":                 fileCount,
+		"total_entries":              totalEntries,
+		"total_cost":                 totalCost,
+		"total_tokens":               totalTokens,
+		"cache_size_bytes":           totalSize,
+		"cache_size_mb":              float64(totalSize) / 1024 / 1024,
+		"hits":                       c.stats.Hits,
+		"memory_hits":                c.stats.MemoryHits,
+		"misses":                     c.stats.Misses,
+		"writes":                     c.stats.Writes,
+		"deletes":                    c.stats.Deletes,
+		"errors":                     c.stats.Errors,
+		"migrations":                 c.stats.Migrations,
+		"evictions":                  c.stats.Evictions,
+		"evicted_bytes":              c.stats.EvictedBytes,
+		"cold_parses_after_eviction": c.stats.ColdParsesAfterEviction,
+		"max_disk_size":              c.maxDiskSize,
+		"schema_version":             CurrentSchemaVersion,
+		"hit_rate":                   hitRate,
+		"persist_path":               c.baseDir,
 	}
 }
 
+// Summaries returns the summaries in memory ordered by path; redacted summaries are not included
+func (c *FileBasedSummaryCache) Summaries() []*FileSummary {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	summaries := make([]*FileSummary, 0, len(c.memCache))
+	for _, summary := range c.memCache {
+		summaries = append(summaries, summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].AbsolutePath < summaries[j].AbsolutePath
+	})
+	return summaries
+}
+
 // Close is a no-op for file-based cache but satisfies the interface
 func (c *FileBasedSummaryCache) Close() error {
 	// Nothing to close for file-based cache
@@ -0,0 +1,144 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSummaryCache(t *testing.T) (*FileBasedSummaryCache, string) {
+	logging.InitLogger("error", filepath.Join(t.TempDir(), "test.log"), false)
+	dir := t.TempDir()
+	c, err := NewFileBasedSummaryCache(dir)
+	require.NoError(t, err)
+	return c, dir
+}
+
+func TestFileBasedSummaryCache_ClearAndRestore(t *testing.T) {
+	c, dir := newTestSummaryCache(t)
+
+	for _, p := range []string{"/data/a/one.jsonl", "/data/a/two.jsonl", "/data/b/three.jsonl"} {
+		require.NoError(t, c.SetFileSummary(&FileSummary{AbsolutePath: p, EntryCount: 1}))
+	}
+	require.NoError(t, c.Clear())
+	assert.False(t, c.HasFileSummary("/data/a/one.jsonl"))
+
+	restored, err := c.RestoreFileSummaries("/data/a")
+	require.NoError(t, err)
+	assert.Equal(t, 2, restored)
+	assert.True(t, c.HasFileSummary("/data/a/one.jsonl"))
+	assert.True(t, c.HasFileSummary("/data/a/two.jsonl"))
+	assert.False(t, c.HasFileSummary("/data/b/three.jsonl"))
+
+	// Restored summaries survive a reload from disk
+	reloaded, err := NewFileBasedSummaryCache(dir)
+	require.NoError(t, err)
+	assert.True(t, reloaded.HasFileSummary("/data/a/two.jsonl"))
+	assert.False(t, reloaded.HasFileSummary("/data/b/three.jsonl"))
+}
+
+func TestFileBasedSummaryCache_InvalidateAndRestoreFile(t *testing.T) {
+	c, _ := newTestSummaryCache(t)
+
+	require.NoError(t, c.SetFileSummary(&FileSummary{AbsolutePath: "/data/file.jsonl"}))
+	require.NoError(t, c.InvalidateFileSummary("/data/file.jsonl"))
+	assert.False(t, c.HasFileSummary("/data/file.jsonl"))
+
+	restored, err := c.RestoreFileSummaries("/data/file.jsonl")
+	require.NoError(t, err)
+	assert.Equal(t, 1, restored)
+	assert.True(t, c.HasFileSummary("/data/file.jsonl"))
+}
+
+func TestFileBasedSummaryCache_PurgeTrash(t *testing.T) {
+	c, dir := newTestSummaryCache(t)
+
+	require.NoError(t, c.SetFileSummary(&FileSummary{AbsolutePath: "/data/old.jsonl"}))
+	require.NoError(t, c.InvalidateFileSummary("/data/old.jsonl"))
+
+	// Age the trashed file beyond the TTL
+	entries, err := os.ReadDir(filepath.Join(dir, "trash"))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	old := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "trash", entries[0].Name()), old, old))
+
+	c.SetTrashTTL(time.Hour)
+	restored, err := c.RestoreFileSummaries("/data/old.jsonl")
+	require.NoError(t, err)
+	assert.Equal(t, 0, restored)
+
+	purged, err := c.PurgeTrash()
+	require.NoError(t, err)
+	assert.Equal(t, 0, purged, "expired summaries were already purged")
+}
+
+func TestFileBasedSummaryCache_DiskQuota(t *testing.T) {
+	c, dir := newTestSummaryCache(t)
+
+	paths := []string{"/data/one.jsonl", "/data/two.jsonl", "/data/three.jsonl", "/data/four.jsonl"}
+	for _, p := range paths {
+		require.NoError(t, c.SetFileSummary(&FileSummary{AbsolutePath: p, EntryCount: 1}))
+	}
+	perFile := c.DiskUsage() / int64(len(paths))
+	require.Positive(t, perFile)
+
+	// Shrinking the quota evicts the oldest summaries down to the low watermark
+	c.SetMaxDiskSize(3 * perFile)
+	assert.LessOrEqual(t, c.DiskUsage(), int64(float64(3*perFile)*quotaLowWatermark))
+	assert.False(t, c.HasFileSummary("/data/one.jsonl"))
+	assert.False(t, c.HasFileSummary("/data/two.jsonl"))
+	assert.True(t, c.HasFileSummary("/data/four.jsonl"))
+
+	stats := c.GetStats()
+	assert.Equal(t, int64(2), stats["evictions"])
+	assert.Equal(t, 2*perFile, stats["evicted_bytes"])
+
+	// The summary being written is never evicted, and rebuilt summaries count as cold parses
+	require.NoError(t, c.SetFileSummary(&FileSummary{AbsolutePath: "/data/one.jsonl", EntryCount: 1}))
+	assert.True(t, c.HasFileSummary("/data/one.jsonl"))
+	assert.Equal(t, int64(1), c.GetStats()["cold_parses_after_eviction"])
+
+	// Eviction counts survive a reload so thrashing is detected across runs
+	reloaded, err := NewFileBasedSummaryCache(dir)
+	require.NoError(t, err)
+	assert.Equal(t, 1, reloaded.evictions[summaryKey("/data/two.jsonl")].Count)
+	assert.Equal(t, c.DiskUsage(), reloaded.DiskUsage())
+
+	// Zero disables the quota
+	reloaded.SetMaxDiskSize(0)
+	for _, p := range paths {
+		require.NoError(t, reloaded.SetFileSummary(&FileSummary{AbsolutePath: p, EntryCount: 1}))
+	}
+	assert.Equal(t, int64(0), reloaded.GetStats()["evictions"])
+}
+
+func TestOpenFileBasedSummaryCacheReadOnly(t *testing.T) {
+	logging.InitLogger("error", filepath.Join(t.TempDir(), "test.log"), false)
+	t.Setenv(PassphraseEnv, "correct horse")
+	dir := filepath.Join(t.TempDir(), "cache")
+
+	// A missing cache is not created, and neither is a key for it
+	empty, err := OpenFileBasedSummaryCacheReadOnly(dir, EncryptionPassphrase)
+	require.NoError(t, err)
+	assert.Zero(t, empty.DiskUsage())
+	require.Error(t, empty.SetFileSummary(&FileSummary{AbsolutePath: "/data/one.jsonl", EntryCount: 1}))
+	_, err = os.Stat(dir)
+	assert.True(t, os.IsNotExist(err))
+
+	writer, err := OpenFileBasedSummaryCache(dir, EncryptionPassphrase)
+	require.NoError(t, err)
+	require.NoError(t, writer.SetFileSummary(&FileSummary{AbsolutePath: "/data/one.jsonl", EntryCount: 1}))
+
+	reader, err := OpenFileBasedSummaryCacheReadOnly(dir, EncryptionPassphrase)
+	require.NoError(t, err)
+	assert.True(t, reader.HasFileSummary("/data/one.jsonl"))
+	assert.Equal(t, writer.DiskUsage(), reader.DiskUsage())
+	require.Len(t, reader.Summaries(), 1)
+	assert.Equal(t, "/data/one.jsonl", reader.Summaries()[0].AbsolutePath)
+}
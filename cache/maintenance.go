@@ -0,0 +1,347 @@
+package cache
+
+import (
+	"crypto/md5"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/penwyp/claudecat/logging"
+)
+
+// Verification problems
+const (
+	ProblemUnreadable = "unreadable" // The summary file cannot be read, decrypted or decoded
+	ProblemChecksum   = "checksum"   // The checksum does not match the path, modification time and size recorded
+	ProblemMisplaced  = "misplaced"  // The summary is stored under a file name not derived from its path
+)
+
+// SummaryChecksum returns the checksum of a summary built from the usage file at absolutePath
+func SummaryChecksum(absolutePath string, modTime time.Time, size int64) string {
+	return fmt.Sprintf("%x", md5.Sum([]byte(fmt.Sprintf("%s_%d_%d", absolutePath, modTime.Unix(), size))))
+}
+
+// Coverage counts the usage files whose summaries the next load would use as they are
+type Coverage struct {
+	Files   int `json:"files"`
+	Fresh   int `json:"fresh"`   // Summary matches the file's modification time and size
+	Stale   int `json:"stale"`   // Summary exists but the file changed since
+	Missing int `json:"missing"` // No summary
+}
+
+// HitRate returns the share of files served from the cache, 0 when there are no files
+func (c Coverage) HitRate() float64 {
+	if c.Files == 0 {
+		return 0
+	}
+	return float64(c.Fresh) / float64(c.Files)
+}
+
+// defaultSummarySize is the assumed size of one summary file when the cache holds none to measure
+const defaultSummarySize = 4 << 10
+
+// PlannedFile is a usage file a cache warm would parse
+type PlannedFile struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	Reason string `json:"reason"` // "missing" or "stale"
+}
+
+// WarmPlan lists what warming the cache for a set of usage files would parse and write
+type WarmPlan struct {
+	Files        []PlannedFile `json:"files"`         // Files without a fresh summary
+	Cached       int           `json:"cached"`        // Files whose summaries would be used as they are
+	ParseBytes   int64         `json:"parse_bytes"`   // Bytes of the files to parse
+	SummaryBytes int64         `json:"summary_bytes"` // Estimated bytes of the summaries written
+	CacheBytes   int64         `json:"cache_bytes"`   // Estimated size of the cache afterwards, stale summaries replaced
+}
+
+// GCResult reports what a garbage collection removed
+type GCResult struct {
+	Pruned    int `json:"pruned"`     // Summaries of usage files that no longer exist, moved to the trash
+	TempFiles int `json:"temp_files"` // Leftovers of interrupted writes
+	Purged    int `json:"purged"`     // Trashed summaries past the trash TTL
+	Evictions int `json:"evictions"`  // Eviction ledger records of usage files that no longer exist
+}
+
+// VerifyIssue is a summary file that failed verification
+type VerifyIssue struct {
+	File    string `json:"file"`
+	Path    string `json:"path,omitempty"` // Usage file of the summary, when it could be decoded
+	Problem string `json:"problem"`
+	Detail  string `json:"detail,omitempty"`
+}
+
+// VerifyReport is the result of verifying every summary file
+type VerifyReport struct {
+	Checked  int           `json:"checked"`
+	Redacted int           `json:"redacted"` // Redacted summaries no longer know their path, so only decoding is verified
+	Issues   []VerifyIssue `json:"issues"`
+	Repaired int           `json:"repaired"` // Failed summaries moved to the trash
+}
+
+// Coverage looks up the summaries of usage files without counting the lookups as hits or misses
+func (c *FileBasedSummaryCache) Coverage(files []string) Coverage {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var coverage Coverage
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		coverage.Files++
+		absPath, err := filepath.Abs(file)
+		if err != nil {
+			absPath = file
+		}
+		summary, ok := c.memCache[absPath]
+		switch {
+		case ok && !summary.IsExpired(info.ModTime(), info.Size()):
+			coverage.Fresh++
+		case ok || c.redacted[c.getCacheFilePath(absPath)]:
+			// Redacted summaries are not decoded here; a changed file is the common reason to look
+			coverage.Stale++
+		default:
+			coverage.Missing++
+		}
+	}
+	return coverage
+}
+
+// PlanWarm works out which usage files a cache warm would parse, without parsing or writing anything.
+// Summary sizes are estimated from the average summary already on disk.
+func (c *FileBasedSummaryCache) PlanWarm(files []string) WarmPlan {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	summarySize := int64(defaultSummarySize)
+	if len(c.disk) > 0 {
+		summarySize = c.diskSize / int64(len(c.disk))
+	}
+	plan := WarmPlan{Files: []PlannedFile{}, CacheBytes: c.diskSize}
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		absPath, err := filepath.Abs(file)
+		if err != nil {
+			absPath = file
+		}
+		cacheFile := c.getCacheFilePath(absPath)
+		summary, ok := c.memCache[absPath]
+		reason := "missing"
+		switch {
+		case ok && !summary.IsExpired(info.ModTime(), info.Size()):
+			plan.Cached++
+			continue
+		case ok || c.redacted[cacheFile]:
+			reason = "stale"
+			if entry, tracked := c.disk[cacheFile]; tracked {
+				plan.CacheBytes -= entry.size
+			}
+		}
+		plan.Files = append(plan.Files, PlannedFile{Path: absPath, Size: info.Size(), Reason: reason})
+		plan.ParseBytes += info.Size()
+		plan.SummaryBytes += summarySize
+	}
+	plan.CacheBytes += plan.SummaryBytes
+	return plan
+}
+
+// TrashUsage returns the number and bytes of summaries in the trash
+func (c *FileBasedSummaryCache) TrashUsage() (int, int64) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	files, err := os.ReadDir(c.trashDir)
+	if err != nil {
+		return 0, 0
+	}
+	count, size := 0, int64(0)
+	for _, file := range files {
+		info, err := file.Info()
+		if err != nil || file.IsDir() {
+			continue
+		}
+		count++
+		size += info.Size()
+	}
+	return count, size
+}
+
+// GC moves summaries of deleted usage files to the trash, removes leftovers of interrupted writes
+// and purges expired trash. Summaries of files on a path that is temporarily unavailable are pruned
+// as well; they stay restorable for the trash TTL.
+func (c *FileBasedSummaryCache) GC() (GCResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var result GCResult
+	for absPath := range c.memCache {
+		if _, err := os.Stat(absPath); !os.IsNotExist(err) {
+			continue
+		}
+		delete(c.memCache, absPath)
+		if err := c.trashSummary(c.getCacheFilePath(absPath)); err != nil {
+			return result, fmt.Errorf("failed to prune summary of %s: %w", absPath, err)
+		}
+		result.Pruned++
+	}
+
+	if err := filepath.Walk(c.baseDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !strings.HasSuffix(path, ".tmp") {
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			logging.LogWarnf("Failed to remove temporary cache file %s: %v", path, err)
+			return nil
+		}
+		result.TempFiles++
+		return nil
+	}); err != nil {
+		return result, fmt.Errorf("failed to walk cache directory: %w", err)
+	}
+
+	purged, err := c.purgeTrash()
+	if err != nil {
+		return result, err
+	}
+	result.Purged = purged
+
+	if result.Evictions = c.pruneEvictionLedger(); result.Evictions > 0 {
+		if err := c.saveEvictionLedger(); err != nil {
+			return result, err
+		}
+	}
+
+	c.stats.Deletes += int64(result.Pruned)
+	logging.LogInfof("Cache GC pruned %d summaries and %d eviction records, removed %d temporary files and purged %d trashed summaries",
+		result.Pruned, result.Evictions, result.TempFiles, result.Purged)
+	return result, nil
+}
+
+// ClearMatching moves the summaries of usage files beneath path and last modified before cutoff to the
+// trash; an empty path or zero cutoff does not filter. Redacted summaries no longer know their path, so
+// they only match when path is empty.
+func (c *FileBasedSummaryCache) ClearMatching(path string, cutoff time.Time) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	prefix := strings.TrimSuffix(path, string(filepath.Separator)) + string(filepath.Separator)
+	matches := func(summary *FileSummary) bool {
+		if path != "" && summary.AbsolutePath != path && !strings.HasPrefix(summary.AbsolutePath, prefix) {
+			return false
+		}
+		return cutoff.IsZero() || summary.ModTime.Before(cutoff)
+	}
+
+	var cacheFiles []string
+	for absPath, summary := range c.memCache {
+		if matches(summary) {
+			delete(c.memCache, absPath)
+			cacheFiles = append(cacheFiles, c.getCacheFilePath(absPath))
+		}
+	}
+	if path == "" {
+		for cacheFile := range c.redacted {
+			data, err := os.ReadFile(cacheFile)
+			if err != nil {
+				continue
+			}
+			if summary, _, err := c.decodeSummaryFile(data); err == nil && matches(summary) {
+				cacheFiles = append(cacheFiles, cacheFile)
+			}
+		}
+	}
+
+	cleared := 0
+	for _, cacheFile := range cacheFiles {
+		if err := c.trashSummary(cacheFile); err != nil {
+			return cleared, fmt.Errorf("failed to clear %s: %w", cacheFile, err)
+		}
+		cleared++
+	}
+	c.stats.Deletes += int64(cleared)
+	if cleared > 0 {
+		logging.LogInfof("Cleared %d cached summaries", cleared)
+	}
+	return cleared, nil
+}
+
+// Verify decodes every summary file and checks its checksum and file name; with repair, failed
+// summaries are moved to the trash so that their usage files are parsed again
+func (c *FileBasedSummaryCache) Verify(repair bool) (VerifyReport, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	report := VerifyReport{Issues: []VerifyIssue{}}
+	err := filepath.Walk(c.baseDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !strings.HasSuffix(path, ".json") {
+			return nil
+		}
+		report.Checked++
+		if issue := c.verifySummaryFile(path, &report); issue != nil {
+			report.Issues = append(report.Issues, *issue)
+		}
+		return nil
+	})
+	if err != nil {
+		return report, fmt.Errorf("failed to walk cache directory: %w", err)
+	}
+
+	if repair {
+		for _, issue := range report.Issues {
+			if err := c.trashSummary(issue.File); err != nil {
+				return report, fmt.Errorf("failed to repair %s: %w", issue.File, err)
+			}
+			report.Repaired++
+		}
+	}
+	return report, nil
+}
+
+// verifySummaryFile returns the problem of one summary file, or nil when it is sound
+func (c *FileBasedSummaryCache) verifySummaryFile(path string, report *VerifyReport) *VerifyIssue {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return &VerifyIssue{File: path, Problem: ProblemUnreadable, Detail: err.Error()}
+	}
+	summary, _, err := c.decodeSummaryFile(data)
+	if err != nil {
+		return &VerifyIssue{File: path, Problem: ProblemUnreadable, Detail: err.Error()}
+	}
+	if summary.Redacted {
+		report.Redacted++
+		return nil
+	}
+	if expected := c.getCacheFilePath(summary.AbsolutePath); expected != path {
+		return &VerifyIssue{File: path, Path: summary.AbsolutePath, Problem: ProblemMisplaced,
+			Detail: fmt.Sprintf("expected at %s", expected)}
+	}
+	if summary.Checksum != "" && summary.Checksum != SummaryChecksum(summary.AbsolutePath, summary.ModTime, summary.FileSize) {
+		return &VerifyIssue{File: path, Path: summary.AbsolutePath, Problem: ProblemChecksum,
+			Detail: fmt.Sprintf("recorded %s", summary.Checksum)}
+	}
+	return nil
+}
+
+// trashSummary moves a summary file to the trash and forgets it along with the memory cache entry
+// it was loaded into; callers must hold the write lock
+func (c *FileBasedSummaryCache) trashSummary(cacheFile string) error {
+	// A misplaced copy must not drop the summary loaded from the right file
+	if entry, ok := c.disk[cacheFile]; ok && entry.absolutePath != "" && c.getCacheFilePath(entry.absolutePath) == cacheFile {
+		delete(c.memCache, entry.absolutePath)
+	}
+	delete(c.redacted, cacheFile)
+	c.untrackDiskFile(cacheFile)
+	if err := c.moveToTrash(cacheFile); err != nil && !os.IsNotExist(err) {
+		c.stats.Errors++
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// summaryOf builds the summary of an existing usage file as the loader would
+func summaryOf(t *testing.T, path string) *FileSummary {
+	t.Helper()
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	return &FileSummary{
+		AbsolutePath: path,
+		ModTime:      info.ModTime(),
+		FileSize:     info.Size(),
+		EntryCount:   1,
+		Checksum:     SummaryChecksum(path, info.ModTime(), info.Size()),
+	}
+}
+
+func writeUsageFile(t *testing.T, dir, name string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte("{}\n"), 0644))
+	return path
+}
+
+func TestFileBasedSummaryCache_Coverage(t *testing.T) {
+	c, _ := newTestSummaryCache(t)
+	data := t.TempDir()
+	fresh := writeUsageFile(t, data, "fresh.jsonl")
+	stale := writeUsageFile(t, data, "stale.jsonl")
+	missing := writeUsageFile(t, data, "missing.jsonl")
+
+	require.NoError(t, c.SetFileSummary(summaryOf(t, fresh)))
+	require.NoError(t, c.SetFileSummary(summaryOf(t, stale)))
+	require.NoError(t, os.WriteFile(stale, []byte("{}\n{}\n"), 0644))
+
+	coverage := c.Coverage([]string{fresh, stale, missing, filepath.Join(data, "deleted.jsonl")})
+	assert.Equal(t, Coverage{Files: 3, Fresh: 1, Stale: 1, Missing: 1}, coverage)
+	assert.InDelta(t, 1.0/3, coverage.HitRate(), 1e-9)
+	assert.Equal(t, int64(0), c.GetStats()["hits"], "coverage lookups are not counted")
+	assert.Equal(t, 0.0, Coverage{}.HitRate())
+}
+
+func TestFileBasedSummaryCache_PlanWarm(t *testing.T) {
+	c, _ := newTestSummaryCache(t)
+	data := t.TempDir()
+	fresh := writeUsageFile(t, data, "fresh.jsonl")
+	stale := writeUsageFile(t, data, "stale.jsonl")
+	missing := writeUsageFile(t, data, "missing.jsonl")
+
+	require.NoError(t, c.SetFileSummary(summaryOf(t, fresh)))
+	require.NoError(t, c.SetFileSummary(summaryOf(t, stale)))
+	require.NoError(t, os.WriteFile(stale, []byte("{}\n{}\n"), 0644))
+	usage := c.DiskUsage()
+	staleSize := c.disk[c.getCacheFilePath(stale)].size
+	average := usage / 2
+
+	plan := c.PlanWarm([]string{fresh, stale, missing, filepath.Join(data, "deleted.jsonl")})
+	assert.Equal(t, 1, plan.Cached)
+	require.Len(t, plan.Files, 2)
+	assert.Equal(t, PlannedFile{Path: stale, Size: 6, Reason: "stale"}, plan.Files[0])
+	assert.Equal(t, PlannedFile{Path: missing, Size: 3, Reason: "missing"}, plan.Files[1])
+	assert.Equal(t, int64(9), plan.ParseBytes)
+	assert.Equal(t, 2*average, plan.SummaryBytes, "two summaries of the average size are written")
+	assert.Equal(t, usage-staleSize+2*average, plan.CacheBytes, "the stale summary is replaced")
+	assert.Equal(t, usage, c.DiskUsage(), "planning writes nothing")
+
+	empty, _ := newTestSummaryCache(t)
+	assert.Equal(t, int64(2*defaultSummarySize), empty.PlanWarm([]string{fresh, missing}).SummaryBytes)
+}
+
+func TestFileBasedSummaryCache_GC(t *testing.T) {
+	c, dir := newTestSummaryCache(t)
+	data := t.TempDir()
+	kept := writeUsageFile(t, data, "kept.jsonl")
+	deleted := writeUsageFile(t, data, "deleted.jsonl")
+	require.NoError(t, c.SetFileSummary(summaryOf(t, kept)))
+	require.NoError(t, c.SetFileSummary(summaryOf(t, deleted)))
+	require.NoError(t, os.Remove(deleted))
+	tmp := c.getCacheFilePath("/data/interrupted.jsonl") + ".tmp"
+	require.NoError(t, os.MkdirAll(filepath.Dir(tmp), 0755))
+	require.NoError(t, os.WriteFile(tmp, []byte("{"), 0644))
+
+	result, err := c.GC()
+	require.NoError(t, err)
+	assert.Equal(t, GCResult{Pruned: 1, TempFiles: 1}, result)
+	assert.True(t, c.HasFileSummary(kept))
+	assert.False(t, c.HasFileSummary(deleted))
+	assert.NoFileExists(t, tmp)
+
+	// Pruned summaries stay restorable
+	trashed, _ := c.TrashUsage()
+	assert.Equal(t, 1, trashed)
+	restored, err := c.RestoreFileSummaries(deleted)
+	require.NoError(t, err)
+	assert.Equal(t, 1, restored)
+
+	reloaded, err := NewFileBasedSummaryCache(dir)
+	require.NoError(t, err)
+	assert.True(t, reloaded.HasFileSummary(deleted))
+}
+
+func TestFileBasedSummaryCache_GCPrunesEvictionLedger(t *testing.T) {
+	c, dir := newTestSummaryCache(t)
+	data := t.TempDir()
+	kept := writeUsageFile(t, data, "kept.jsonl")
+	c.evictions[summaryKey(kept)] = evictionRecord{Path: kept, Count: 2}
+	c.evictions[summaryKey("/data/deleted.jsonl")] = evictionRecord{Path: "/data/deleted.jsonl", Count: 1}
+
+	result, err := c.GC()
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Evictions)
+
+	reloaded, err := NewFileBasedSummaryCache(dir)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]evictionRecord{summaryKey(kept): {Path: kept, Count: 2}}, reloaded.evictions)
+}
+
+func TestFileBasedSummaryCache_ClearMatching(t *testing.T) {
+	c, _ := newTestSummaryCache(t)
+	now := time.Now()
+	summaries := map[string]time.Time{
+		"/data/a/old.jsonl":  now.AddDate(0, 0, -100),
+		"/data/a/new.jsonl":  now,
+		"/data/ab/old.jsonl": now.AddDate(0, 0, -100),
+		"/data/b/old.jsonl":  now.AddDate(0, 0, -100),
+	}
+	for path, modTime := range summaries {
+		require.NoError(t, c.SetFileSummary(&FileSummary{AbsolutePath: path, ModTime: modTime}))
+	}
+
+	cleared, err := c.ClearMatching("/data/a", time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, cleared)
+	assert.True(t, c.HasFileSummary("/data/ab/old.jsonl"), "paths match by directory, not by prefix")
+
+	cleared, err = c.ClearMatching("", now.AddDate(0, 0, -30))
+	require.NoError(t, err)
+	assert.Equal(t, 2, cleared)
+	assert.False(t, c.HasFileSummary("/data/b/old.jsonl"))
+	assert.Equal(t, 0, c.GetStats()["cached_files"])
+
+	restored, err := c.RestoreFileSummaries("/data")
+	require.NoError(t, err)
+	assert.Equal(t, 4, restored)
+}
+
+func TestFileBasedSummaryCache_Verify(t *testing.T) {
+	c, dir := newTestSummaryCache(t)
+	data := t.TempDir()
+	sound := writeUsageFile(t, data, "sound.jsonl")
+	tampered := writeUsageFile(t, data, "tampered.jsonl")
+	require.NoError(t, c.SetFileSummary(summaryOf(t, sound)))
+	summary := summaryOf(t, tampered)
+	summary.FileSize++
+	require.NoError(t, c.SetFileSummary(summary))
+
+	corrupt := filepath.Join(c.baseDir, "00", "corrupt.json")
+	require.NoError(t, os.MkdirAll(filepath.Dir(corrupt), 0755))
+	require.NoError(t, os.WriteFile(corrupt, []byte("{not json"), 0644))
+	misplaced := filepath.Join(c.baseDir, "00", "misplaced.json")
+	copied, err := os.ReadFile(c.getCacheFilePath(sound))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(misplaced, copied, 0644))
+
+	report, err := c.Verify(false)
+	require.NoError(t, err)
+	assert.Equal(t, 4, report.Checked)
+	problems := make(map[string]string)
+	for _, issue := range report.Issues {
+		problems[filepath.Base(issue.File)] = issue.Problem
+	}
+	assert.Equal(t, map[string]string{
+		"corrupt.json":   ProblemUnreadable,
+		"misplaced.json": ProblemMisplaced,
+		filepath.Base(c.getCacheFilePath(tampered)): ProblemChecksum,
+	}, problems)
+	assert.Equal(t, 0, report.Repaired)
+
+	report, err = c.Verify(true)
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Repaired)
+	assert.False(t, c.HasFileSummary(tampered))
+	assert.True(t, c.HasFileSummary(sound))
+
+	reloaded, err := NewFileBasedSummaryCache(dir)
+	require.NoError(t, err)
+	report, err = reloaded.Verify(false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Checked)
+	assert.Empty(t, report.Issues)
+}
@@ -0,0 +1,199 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/penwyp/claudecat/logging"
+)
+
+const (
+	// evictionLedgerFile records how often each summary was evicted, so cold parses caused by the quota are detected across runs
+	evictionLedgerFile = "evictions.json"
+	// quotaLowWatermark is the share of the quota eviction frees down to, so a full cache does not evict on every write
+	quotaLowWatermark = 0.9
+	// thrashEvictions is the number of evictions of a path after which rebuilding it again is reported as thrashing
+	thrashEvictions = 2
+)
+
+// evictionRecord counts the evictions of the summary of one usage file. The ledger is keyed by the
+// summary's file name, and sealed like the summaries when the cache is encrypted.
+type evictionRecord struct {
+	Path  string `json:"path"` // Usage file, so that cache gc can forget deleted ones
+	Count int    `json:"count"`
+}
+
+// diskEntry is the on-disk footprint of a summary file
+type diskEntry struct {
+	size         int64
+	writtenAt    time.Time
+	absolutePath string // Empty for redacted summaries, which are not in the memory cache
+}
+
+// SetMaxDiskSize sets the disk quota for summary files and evicts the oldest summaries beyond it; zero disables
+func (c *FileBasedSummaryCache) SetMaxDiskSize(maxBytes int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.maxDiskSize = max(maxBytes, 0)
+	c.enforceDiskQuota("")
+}
+
+// DiskUsage returns the bytes used by summary files
+func (c *FileBasedSummaryCache) DiskUsage() int64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.diskSize
+}
+
+// trackDiskFile records the size of a summary file; callers must hold the lock or own the cache
+func (c *FileBasedSummaryCache) trackDiskFile(cacheFile, absolutePath string, size int64, writtenAt time.Time) {
+	c.untrackDiskFile(cacheFile)
+	c.disk[cacheFile] = diskEntry{size: size, writtenAt: writtenAt, absolutePath: absolutePath}
+	c.diskSize += size
+}
+
+// untrackDiskFile forgets a summary file that was removed; callers must hold the lock or own the cache
+func (c *FileBasedSummaryCache) untrackDiskFile(cacheFile string) {
+	if entry, ok := c.disk[cacheFile]; ok {
+		c.diskSize -= entry.size
+		delete(c.disk, cacheFile)
+	}
+}
+
+// enforceDiskQuota deletes the least recently written summaries until the cache is below the low watermark
+// of the quota, never evicting keep. Callers must hold the write lock.
+func (c *FileBasedSummaryCache) enforceDiskQuota(keep string) {
+	if c.maxDiskSize <= 0 || c.diskSize <= c.maxDiskSize {
+		return
+	}
+
+	files := make([]string, 0, len(c.disk))
+	for cacheFile := range c.disk {
+		if cacheFile != keep {
+			files = append(files, cacheFile)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return c.disk[files[i]].writtenAt.Before(c.disk[files[j]].writtenAt)
+	})
+
+	target := int64(float64(c.maxDiskSize) * quotaLowWatermark)
+	evicted, freed := 0, int64(0)
+	for _, cacheFile := range files {
+		if c.diskSize <= target {
+			break
+		}
+		entry := c.disk[cacheFile]
+		if err := os.Remove(cacheFile); err != nil && !os.IsNotExist(err) {
+			logging.LogDebugf("Failed to evict cache file %s: %v", cacheFile, err)
+			c.stats.Errors++
+			continue
+		}
+		c.untrackDiskFile(cacheFile)
+		delete(c.redacted, cacheFile)
+		if entry.absolutePath != "" {
+			delete(c.memCache, entry.absolutePath)
+			key := summaryKey(entry.absolutePath)
+			c.evictions[key] = evictionRecord{Path: entry.absolutePath, Count: c.evictions[key].Count + 1}
+		}
+		evicted++
+		freed += entry.size
+	}
+	if evicted == 0 {
+		return
+	}
+
+	c.stats.Evictions += int64(evicted)
+	c.stats.EvictedBytes += freed
+	logging.LogInfof("Evicted %d summaries (%.1f MB) to stay within the %.1f MB cache quota",
+		evicted, float64(freed)/1024/1024, float64(c.maxDiskSize)/1024/1024)
+	if err := c.saveEvictionLedger(); err != nil {
+		logging.LogDebugf("Failed to save eviction ledger: %v", err)
+	}
+}
+
+// noteRebuilt counts a summary written again after the quota evicted it, warning once when the cache thrashes.
+// Callers must hold the write lock.
+func (c *FileBasedSummaryCache) noteRebuilt(absolutePath string) {
+	times := c.evictions[summaryKey(absolutePath)].Count
+	if times == 0 {
+		return
+	}
+	c.stats.ColdParsesAfterEviction++
+	if times >= thrashEvictions && !c.thrashWarned {
+		c.thrashWarned = true
+		logging.LogWarnf("Cache quota of %.1f MB is too small: %s was parsed again after being evicted %d times; "+
+			"raise cache.max_disk_size to avoid repeated cold parses", float64(c.maxDiskSize)/1024/1024, absolutePath, times)
+	}
+}
+
+// loadEvictionLedger reads the eviction counts persisted by earlier runs
+func (c *FileBasedSummaryCache) loadEvictionLedger() {
+	data, err := os.ReadFile(c.ledgerPath)
+	if err != nil {
+		return
+	}
+	if data, err = c.enc.OpenFile(data); err == nil {
+		err = json.Unmarshal(data, &c.evictions)
+	}
+	if err != nil {
+		logging.LogDebugf("Ignoring eviction ledger %s: %v", c.ledgerPath, err)
+		c.evictions = make(map[string]evictionRecord)
+	}
+}
+
+// pruneEvictionLedger forgets the evictions of usage files that no longer exist and reports how many
+// were forgotten; callers must hold the write lock
+func (c *FileBasedSummaryCache) pruneEvictionLedger() int {
+	pruned := 0
+	for key, record := range c.evictions {
+		if _, err := os.Stat(record.Path); os.IsNotExist(err) {
+			delete(c.evictions, key)
+			pruned++
+		}
+	}
+	return pruned
+}
+
+// redactEvictionLedger forgets the evictions of usage files last modified before cutoff, so that their
+// paths, which hold session IDs, are not kept past the retention period. Callers must hold the write lock.
+func (c *FileBasedSummaryCache) redactEvictionLedger(cutoff time.Time) int {
+	forgotten := 0
+	for key, record := range c.evictions {
+		if info, err := os.Stat(record.Path); err != nil || info.ModTime().Before(cutoff) {
+			delete(c.evictions, key)
+			forgotten++
+		}
+	}
+	if forgotten > 0 {
+		if err := c.saveEvictionLedger(); err != nil {
+			logging.LogDebugf("Failed to save eviction ledger: %v", err)
+		}
+	}
+	return forgotten
+}
+
+// saveEvictionLedger atomically persists the eviction counts; callers must hold the write lock
+func (c *FileBasedSummaryCache) saveEvictionLedger() error {
+	data, err := json.Marshal(c.evictions)
+	if err != nil {
+		return fmt.Errorf("failed to marshal eviction ledger: %w", err)
+	}
+	if data, err = c.enc.SealFile(data); err != nil {
+		return fmt.Errorf("failed to encrypt eviction ledger: %w", err)
+	}
+	tmpFile := c.ledgerPath + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write eviction ledger: %w", err)
+	}
+	if err := os.Rename(tmpFile, c.ledgerPath); err != nil {
+		os.Remove(tmpFile)
+		return fmt.Errorf("failed to replace eviction ledger: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/penwyp/claudecat/logging"
+	"github.com/penwyp/claudecat/models"
+)
+
+// RedactExpiredIDs rewrites summaries (including trashed ones) for files last modified before cutoff
+// so that session IDs no longer appear in their paths. Numeric aggregates are kept unchanged.
+// Redacted summaries are still found by lookups on the original path, which determines the cache file.
+// The eviction ledger forgets those files.
+func (c *FileBasedSummaryCache) RedactExpiredIDs(cutoff time.Time) (int, error) {
+	if cutoff.IsZero() {
+		return 0, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	redacted := 0
+	for _, dir := range []string{c.baseDir, c.trashDir} {
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() || !strings.HasSuffix(path, ".json") {
+				return nil
+			}
+
+			changed, err := c.redactSummaryFile(path, info, cutoff)
+			if err != nil {
+				logging.LogDebugf("Failed to redact cache file %s: %v", path, err)
+				return nil
+			}
+			if changed {
+				redacted++
+				if dir == c.baseDir {
+					c.redacted[path] = true
+				}
+			}
+			return nil
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return redacted, fmt.Errorf("failed to walk cache directory: %w", err)
+		}
+	}
+
+	if forgotten := c.redactEvictionLedger(cutoff); forgotten > 0 {
+		logging.LogInfof("Forgot the evictions of %d usage files older than %s", forgotten, cutoff.Format("2006-01-02"))
+	}
+	if redacted > 0 {
+		logging.LogInfof("Redacted IDs from %d cached summaries older than %s", redacted, cutoff.Format("2006-01-02"))
+	}
+	return redacted, nil
+}
+
+// redactSummaryFile redacts a single summary file in place, preserving its modification time
+func (c *FileBasedSummaryCache) redactSummaryFile(path string, info os.FileInfo, cutoff time.Time) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+
+	summary, _, err := c.decodeSummaryFile(data)
+	if err != nil {
+		return false, err
+	}
+	if summary.Redacted || !summary.ModTime.Before(cutoff) {
+		return false, nil
+	}
+
+	summary.Path = models.RedactIDs(summary.Path)
+	summary.AbsolutePath = models.RedactIDs(summary.AbsolutePath)
+	summary.Redacted = true
+
+	data, err = c.encodeSummaryFile(summary)
+	if err != nil {
+		return false, err
+	}
+
+	tmpFile := path + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+		return false, fmt.Errorf("failed to write cache file: %w", err)
+	}
+	if err := os.Rename(tmpFile, path); err != nil {
+		os.Remove(tmpFile)
+		return false, fmt.Errorf("failed to rename cache file: %w", err)
+	}
+
+	// Trash expiry is based on modification time, so keep it
+	if err := os.Chtimes(path, info.ModTime(), info.ModTime()); err != nil {
+		logging.LogDebugf("Failed to preserve modification time of %s: %v", path, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileBasedSummaryCache_RedactExpiredIDs(t *testing.T) {
+	c, dir := newTestSummaryCache(t)
+
+	oldPath := "/data/app/0b6c3c1e-9a43-4a8e-b5d2-6f1f0e2d3c4b.jsonl"
+	newPath := "/data/app/7f1e2d3c-4b5a-4c6d-8e9f-0a1b2c3d4e5f.jsonl"
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, c.SetFileSummary(&FileSummary{AbsolutePath: oldPath, Path: oldPath, ModTime: now.AddDate(0, 0, -60), EntryCount: 5, TotalCost: 1.5}))
+	require.NoError(t, c.SetFileSummary(&FileSummary{AbsolutePath: newPath, Path: newPath, ModTime: now.AddDate(0, 0, -1), EntryCount: 2}))
+
+	redacted, err := c.RedactExpiredIDs(now.AddDate(0, 0, -30))
+	require.NoError(t, err)
+	assert.Equal(t, 1, redacted)
+
+	// The persisted summary keeps its aggregates but no longer contains the session ID
+	data, err := os.ReadFile(c.getCacheFilePath(oldPath))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "0b6c3c1e")
+	var onDisk FileSummary
+	require.NoError(t, json.Unmarshal(data, &onDisk))
+	assert.True(t, onDisk.Redacted)
+	assert.Equal(t, 5, onDisk.EntryCount)
+	assert.Equal(t, 1.5, onDisk.TotalCost)
+
+	// Running again is a no-op
+	redacted, err = c.RedactExpiredIDs(now.AddDate(0, 0, -30))
+	require.NoError(t, err)
+	assert.Zero(t, redacted)
+
+	// A reloaded cache still finds the redacted summary by its real path
+	reloaded, err := NewFileBasedSummaryCache(dir)
+	require.NoError(t, err)
+	assert.True(t, reloaded.HasFileSummary(oldPath))
+	summary, err := reloaded.GetFileSummary(oldPath)
+	require.NoError(t, err)
+	assert.Equal(t, oldPath, summary.AbsolutePath)
+	assert.Equal(t, 5, summary.EntryCount)
+}
+
+func TestFileBasedSummaryCache_RedactExpiredIDsForgetsEvictions(t *testing.T) {
+	c, dir := newTestSummaryCache(t)
+	data := t.TempDir()
+	recent := writeUsageFile(t, data, "recent.jsonl")
+	old := writeUsageFile(t, data, "0b6c3c1e-9a43-4a8e-b5d2-6f1f0e2d3c4b.jsonl")
+	modTime := time.Now().AddDate(0, 0, -60)
+	require.NoError(t, os.Chtimes(old, modTime, modTime))
+	c.evictions[summaryKey(recent)] = evictionRecord{Path: recent, Count: 1}
+	c.evictions[summaryKey(old)] = evictionRecord{Path: old, Count: 3}
+
+	_, err := c.RedactExpiredIDs(time.Now().AddDate(0, 0, -30))
+	require.NoError(t, err)
+
+	ledger, err := os.ReadFile(c.ledgerPath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(ledger), "0b6c3c1e")
+	reloaded, err := NewFileBasedSummaryCache(dir)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]evictionRecord{summaryKey(recent): {Path: recent, Count: 1}}, reloaded.evictions)
+}
@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Summary schema compatibility policy:
+//   - Summaries written by this version carry CurrentSchemaVersion.
+//   - Older summaries with a chain of registered migrations are migrated in memory on read and rewritten lazily.
+//   - Summaries no migration reaches, or written by a newer claudecat, are ignored and the affected file is
+//     re-parsed; the rest of the cache stays usable.
+//
+// Bump CurrentSchemaVersion whenever FileSummary changes in a way old readers would misinterpret,
+// and register a migration from the previous version in summaryMigrations.
+const (
+	// CurrentSchemaVersion is the schema version written by this build. Version 3 splits cache
+	// writes into the 5-minute and 1-hour tiers.
+	CurrentSchemaVersion = 3
+
+	// MinSupportedSchemaVersion is the oldest schema summaryMigrations can upgrade. Summaries before
+	// version 3 lack the 1-hour cache write share, which only re-parsing recovers, so upgrading
+	// from them invalidates every summary.
+	MinSupportedSchemaVersion = 3
+
+	// legacySchemaVersion is assumed for summaries written before versioning was introduced
+	legacySchemaVersion = 1
+)
+
+// summaryMigration upgrades a summary from one schema version to the next
+type summaryMigration func(summary *FileSummary) error
+
+// summaryMigrations maps a schema version to the migration that upgrades it to version+1
+var summaryMigrations = map[int]summaryMigration{}
+
+// ErrUnsupportedSchema is returned when a cached summary cannot be migrated to the current schema
+type ErrUnsupportedSchema struct {
+	Version int
+}
+
+func (e *ErrUnsupportedSchema) Error() string {
+	return fmt.Sprintf("unsupported summary schema version %d (supported: %d-%d)",
+		e.Version, MinSupportedSchemaVersion, CurrentSchemaVersion)
+}
+
+// decodeFileSummary parses a cached summary and migrates it to the current schema.
+// The returned flag reports whether a migration was applied and the summary should be rewritten.
+func decodeFileSummary(data []byte) (*FileSummary, bool, error) {
+	var summary FileSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return nil, false, fmt.Errorf("failed to unmarshal summary: %w", err)
+	}
+
+	migrated, err := migrateFileSummary(&summary)
+	if err != nil {
+		return nil, false, err
+	}
+	return &summary, migrated, nil
+}
+
+// migrateFileSummary applies migrations in order until the summary reaches the current schema
+func migrateFileSummary(summary *FileSummary) (bool, error) {
+	if summary.SchemaVersion == 0 {
+		summary.SchemaVersion = legacySchemaVersion
+	}
+	if summary.SchemaVersion > CurrentSchemaVersion {
+		return false, &ErrUnsupportedSchema{Version: summary.SchemaVersion}
+	}
+
+	migrated := false
+	for summary.SchemaVersion < CurrentSchemaVersion {
+		migrate, ok := summaryMigrations[summary.SchemaVersion]
+		if !ok {
+			return false, &ErrUnsupportedSchema{Version: summary.SchemaVersion}
+		}
+		if err := migrate(summary); err != nil {
+			return false, fmt.Errorf("failed to migrate summary from schema %d: %w", summary.SchemaVersion, err)
+		}
+		summary.SchemaVersion++
+		migrated = true
+	}
+	return migrated, nil
+}
@@ -0,0 +1,122 @@
+package cache
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeFileSummary_RejectsSchemaWithoutCacheTiers(t *testing.T) {
+	for version, data := range map[int]string{
+		legacySchemaVersion: `{"absolute_path": "/data/legacy.jsonl", "entry_count": 2}`,
+		2:                   `{"schema_version": 2, "absolute_path": "/data/v2.jsonl", "entry_count": 2}`,
+	} {
+		_, _, err := decodeFileSummary([]byte(data))
+		var schemaErr *ErrUnsupportedSchema
+		require.ErrorAs(t, err, &schemaErr)
+		assert.Equal(t, version, schemaErr.Version)
+	}
+
+	summary, migrated, err := decodeFileSummary([]byte(`{"schema_version": 3, "absolute_path": "/data/v3.jsonl", "entry_count": 2}`))
+	require.NoError(t, err)
+	assert.False(t, migrated)
+	assert.Equal(t, 2, summary.EntryCount)
+}
+
+func TestDecodeFileSummary_RejectsNewerSchema(t *testing.T) {
+	data, err := json.Marshal(FileSummary{SchemaVersion: CurrentSchemaVersion + 1, AbsolutePath: "/data/new.jsonl"})
+	require.NoError(t, err)
+
+	_, _, err = decodeFileSummary(data)
+	var schemaErr *ErrUnsupportedSchema
+	require.ErrorAs(t, err, &schemaErr)
+	assert.Equal(t, CurrentSchemaVersion+1, schemaErr.Version)
+}
+
+func TestFileBasedSummaryCache_IgnoresUnsupportedOnPreload(t *testing.T) {
+	c, dir := newTestSummaryCache(t)
+	require.NoError(t, c.SetFileSummary(&FileSummary{AbsolutePath: "/data/current.jsonl", EntryCount: 1}))
+
+	// Simulate caches written by an older build and by a newer one
+	legacyPath := c.getCacheFilePath("/data/legacy.jsonl")
+	require.NoError(t, os.MkdirAll(filepath.Dir(legacyPath), 0755))
+	require.NoError(t, os.WriteFile(legacyPath, []byte(`{"absolute_path": "/data/legacy.jsonl", "entry_count": 3}`), 0644))
+
+	futurePath := c.getCacheFilePath("/data/future.jsonl")
+	require.NoError(t, os.MkdirAll(filepath.Dir(futurePath), 0755))
+	require.NoError(t, os.WriteFile(futurePath, []byte(`{"schema_version": 99, "absolute_path": "/data/future.jsonl"}`), 0644))
+
+	reloaded, err := NewFileBasedSummaryCache(dir)
+	require.NoError(t, err)
+	assert.True(t, reloaded.HasFileSummary("/data/current.jsonl"))
+	assert.False(t, reloaded.HasFileSummary("/data/legacy.jsonl"), "legacy summaries are re-parsed")
+	assert.False(t, reloaded.HasFileSummary("/data/future.jsonl"))
+	assert.Equal(t, int64(0), reloaded.GetStats()["migrations"])
+}
+
+// registerStubMigration makes schema 2 migratable for the duration of a test, standing in for a
+// real migration while none is registered
+func registerStubMigration(t *testing.T) {
+	summaryMigrations[2] = func(summary *FileSummary) error {
+		summary.EntryCount *= 10
+		return nil
+	}
+	t.Cleanup(func() { delete(summaryMigrations, 2) })
+}
+
+func TestDecodeFileSummary_AppliesMigrations(t *testing.T) {
+	registerStubMigration(t)
+
+	summary, migrated, err := decodeFileSummary([]byte(`{"schema_version": 2, "absolute_path": "/data/v2.jsonl", "entry_count": 2}`))
+	require.NoError(t, err)
+	assert.True(t, migrated)
+	assert.Equal(t, CurrentSchemaVersion, summary.SchemaVersion)
+	assert.Equal(t, 20, summary.EntryCount)
+
+	// Schemas without a chain of migrations to the current one stay unsupported
+	_, _, err = decodeFileSummary([]byte(`{"absolute_path": "/data/legacy.jsonl", "entry_count": 2}`))
+	var schemaErr *ErrUnsupportedSchema
+	require.ErrorAs(t, err, &schemaErr)
+	assert.Equal(t, legacySchemaVersion, schemaErr.Version)
+}
+
+func TestFileBasedSummaryCache_RewritesMigrated(t *testing.T) {
+	registerStubMigration(t)
+	c, dir := newTestSummaryCache(t)
+
+	readOnDisk := func(path string) FileSummary {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		var onDisk FileSummary
+		require.NoError(t, json.Unmarshal(data, &onDisk))
+		return onDisk
+	}
+	writeV2 := func(absolutePath string) string {
+		path := c.getCacheFilePath(absolutePath)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(`{"schema_version": 2, "absolute_path": "`+absolutePath+`", "entry_count": 3}`), 0644))
+		return path
+	}
+
+	// Migrated on preload
+	preloadPath := writeV2("/data/preload.jsonl")
+	reloaded, err := NewFileBasedSummaryCache(dir)
+	require.NoError(t, err)
+	assert.True(t, reloaded.HasFileSummary("/data/preload.jsonl"))
+	assert.Equal(t, int64(1), reloaded.GetStats()["migrations"])
+	onDisk := readOnDisk(preloadPath)
+	assert.Equal(t, CurrentSchemaVersion, onDisk.SchemaVersion, "rewritten with the current version")
+	assert.Equal(t, 30, onDisk.EntryCount)
+
+	// Migrated on first lookup
+	lookupPath := writeV2("/data/lookup.jsonl")
+	summary, err := reloaded.GetFileSummary("/data/lookup.jsonl")
+	require.NoError(t, err)
+	assert.Equal(t, 30, summary.EntryCount)
+	assert.Equal(t, int64(2), reloaded.GetStats()["migrations"])
+	assert.Equal(t, CurrentSchemaVersion, readOnDisk(lookupPath).SchemaVersion)
+}
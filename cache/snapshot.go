@@ -0,0 +1,195 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// SnapshotVersion is the version of the snapshot format written by NewSnapshot
+const SnapshotVersion = 1
+
+// Snapshot is the aggregate state of a cache: the usage files it summarizes and their usage per
+// UTC day and model, saved to verify later that a backfill or rebuild did not lose data
+type Snapshot struct {
+	Version int                                 `json:"version"`
+	TakenAt time.Time                           `json:"taken_at"`
+	Files   map[string]SnapshotFile             `json:"files"` // By absolute path
+	Days    map[string]map[string]SnapshotUsage `json:"days"`  // By "2006-01-02" in UTC, then model
+}
+
+// SnapshotFile is a summarized usage file in a snapshot
+type SnapshotFile struct {
+	ModTime time.Time `json:"mod_time"`
+	Size    int64     `json:"size"`
+	Entries int       `json:"entries"`
+	CostUSD float64   `json:"cost_usd"`
+}
+
+// SnapshotUsage is the usage of a model during a day
+type SnapshotUsage struct {
+	Entries int     `json:"entries"`
+	Tokens  int     `json:"tokens"`
+	CostUSD float64 `json:"cost_usd"`
+}
+
+// NewSnapshot aggregates summaries into a snapshot taken at takenAt
+func NewSnapshot(summaries []*FileSummary, takenAt time.Time) *Snapshot {
+	snapshot := &Snapshot{
+		Version: SnapshotVersion,
+		TakenAt: takenAt,
+		Files:   make(map[string]SnapshotFile, len(summaries)),
+		Days:    make(map[string]map[string]SnapshotUsage),
+	}
+	for _, summary := range summaries {
+		snapshot.Files[summary.AbsolutePath] = SnapshotFile{
+			ModTime: summary.ModTime,
+			Size:    summary.FileSize,
+			Entries: summary.EntryCount,
+			CostUSD: summary.TotalCost,
+		}
+		for day, bucket := range summary.DailyBuckets {
+			models, ok := snapshot.Days[day]
+			if !ok {
+				models = make(map[string]SnapshotUsage)
+				snapshot.Days[day] = models
+			}
+			for model, stat := range bucket.ModelStats {
+				usage := models[model]
+				usage.Entries += stat.EntryCount
+				usage.Tokens += stat.InputTokens + stat.OutputTokens + stat.CacheCreationTokens + stat.CacheReadTokens
+				usage.CostUSD += stat.TotalCost
+				models[model] = usage
+			}
+		}
+	}
+	return snapshot
+}
+
+// ParseSnapshot reads a snapshot, checking its version
+func ParseSnapshot(data []byte) (*Snapshot, error) {
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot: %w", err)
+	}
+	if snapshot.Version < 1 || snapshot.Version > SnapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version: %d", snapshot.Version)
+	}
+	return &snapshot, nil
+}
+
+// Totals returns the entries and cost of every file in the snapshot
+func (s *Snapshot) Totals() (int, float64) {
+	entries, cost := 0, 0.0
+	for _, file := range s.Files {
+		entries += file.Entries
+		cost += file.CostUSD
+	}
+	return entries, cost
+}
+
+// UsageDelta is the change in usage of a model during a day between two snapshots
+type UsageDelta struct {
+	Day     string        `json:"day"`
+	Model   string        `json:"model"`
+	Before  SnapshotUsage `json:"before"`
+	After   SnapshotUsage `json:"after"`
+	Entries int           `json:"entries"` // Entries added, negative when entries were lost
+	Tokens  int           `json:"tokens"`
+	CostUSD float64       `json:"cost_usd"`
+}
+
+// SnapshotDiff reports what changed between two snapshots
+type SnapshotDiff struct {
+	FilesAdded    []string     `json:"files_added"`   // Summarized only after
+	FilesRemoved  []string     `json:"files_removed"` // Summarized only before
+	FilesChanged  []string     `json:"files_changed"` // Summarized from a different version of the file, or to different totals
+	FilesSame     int          `json:"files_same"`
+	EntriesBefore int          `json:"entries_before"`
+	EntriesAfter  int          `json:"entries_after"`
+	CostBefore    float64      `json:"cost_before"`
+	CostAfter     float64      `json:"cost_after"`
+	Usage         []UsageDelta `json:"usage"` // Days and models whose usage changed, by day then model
+}
+
+// Lost reports whether the later snapshot is missing usage of the earlier one: a file no longer
+// summarized, or fewer entries of a model during a day
+func (d SnapshotDiff) Lost() bool {
+	if len(d.FilesRemoved) > 0 {
+		return true
+	}
+	for _, delta := range d.Usage {
+		if delta.Entries < 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// DiffSnapshots compares the snapshot before with the one after
+func DiffSnapshots(before, after *Snapshot) SnapshotDiff {
+	diff := SnapshotDiff{FilesAdded: []string{}, FilesRemoved: []string{}, FilesChanged: []string{}, Usage: []UsageDelta{}}
+	diff.EntriesBefore, diff.CostBefore = before.Totals()
+	diff.EntriesAfter, diff.CostAfter = after.Totals()
+
+	for path, file := range after.Files {
+		previous, ok := before.Files[path]
+		switch {
+		case !ok:
+			diff.FilesAdded = append(diff.FilesAdded, path)
+		case !previous.ModTime.Equal(file.ModTime) || previous.Size != file.Size ||
+			previous.Entries != file.Entries || !costEqual(previous.CostUSD, file.CostUSD):
+			diff.FilesChanged = append(diff.FilesChanged, path)
+		default:
+			diff.FilesSame++
+		}
+	}
+	for path := range before.Files {
+		if _, ok := after.Files[path]; !ok {
+			diff.FilesRemoved = append(diff.FilesRemoved, path)
+		}
+	}
+	sort.Strings(diff.FilesAdded)
+	sort.Strings(diff.FilesRemoved)
+	sort.Strings(diff.FilesChanged)
+
+	seen := make(map[[2]string]bool)
+	for _, days := range []map[string]map[string]SnapshotUsage{before.Days, after.Days} {
+		for day, models := range days {
+			for model := range models {
+				key := [2]string{day, model}
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				was, is := before.Days[day][model], after.Days[day][model]
+				delta := UsageDelta{
+					Day:     day,
+					Model:   model,
+					Before:  was,
+					After:   is,
+					Entries: is.Entries - was.Entries,
+					Tokens:  is.Tokens - was.Tokens,
+					CostUSD: is.CostUSD - was.CostUSD,
+				}
+				if delta.Entries != 0 || delta.Tokens != 0 || !costEqual(was.CostUSD, is.CostUSD) {
+					diff.Usage = append(diff.Usage, delta)
+				}
+			}
+		}
+	}
+	sort.Slice(diff.Usage, func(i, j int) bool {
+		if diff.Usage[i].Day != diff.Usage[j].Day {
+			return diff.Usage[i].Day < diff.Usage[j].Day
+		}
+		return diff.Usage[i].Model < diff.Usage[j].Model
+	})
+	return diff
+}
+
+// costEqual compares costs summed in different orders
+func costEqual(a, b float64) bool {
+	const epsilon = 1e-9
+	return a-b < epsilon && b-a < epsilon
+}
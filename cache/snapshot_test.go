@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// snapshotSummary builds a summary with the usage of one model during one day
+func snapshotSummary(path, day, model string, entries int, cost float64) *FileSummary {
+	return &FileSummary{
+		AbsolutePath: path,
+		ModTime:      time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+		FileSize:     int64(100 * entries),
+		EntryCount:   entries,
+		TotalCost:    cost,
+		DailyBuckets: map[string]*TemporalBucket{
+			day: {Period: day, EntryCount: entries, TotalCost: cost, ModelStats: map[string]*ModelStat{
+				model: {Model: model, EntryCount: entries, TotalCost: cost, InputTokens: 10 * entries, OutputTokens: entries},
+			}},
+		},
+	}
+}
+
+func TestSnapshotDiff(t *testing.T) {
+	takenAt := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+	before := NewSnapshot([]*FileSummary{
+		snapshotSummary("/data/a.jsonl", "2025-06-01", "sonnet", 4, 0.4),
+		snapshotSummary("/data/b.jsonl", "2025-06-01", "opus", 2, 1.0),
+		snapshotSummary("/data/c.jsonl", "2025-05-31", "sonnet", 1, 0.1),
+	}, takenAt)
+	assert.Equal(t, SnapshotUsage{Entries: 4, Tokens: 44, CostUSD: 0.4}, before.Days["2025-06-01"]["sonnet"])
+
+	data, err := json.Marshal(before)
+	require.NoError(t, err)
+	parsed, err := ParseSnapshot(data)
+	require.NoError(t, err)
+	assert.Equal(t, before.Files, parsed.Files)
+
+	after := NewSnapshot([]*FileSummary{
+		snapshotSummary("/data/a.jsonl", "2025-06-01", "sonnet", 4, 0.4),
+		snapshotSummary("/data/b.jsonl", "2025-06-01", "opus", 3, 1.5),
+		snapshotSummary("/data/d.jsonl", "2025-06-02", "sonnet", 1, 0.1),
+	}, takenAt)
+	diff := DiffSnapshots(parsed, after)
+	assert.Equal(t, []string{"/data/d.jsonl"}, diff.FilesAdded)
+	assert.Equal(t, []string{"/data/c.jsonl"}, diff.FilesRemoved)
+	assert.Equal(t, []string{"/data/b.jsonl"}, diff.FilesChanged)
+	assert.Equal(t, 1, diff.FilesSame)
+	assert.Equal(t, 7, diff.EntriesBefore)
+	assert.Equal(t, 8, diff.EntriesAfter)
+	assert.InDelta(t, 2.0, diff.CostAfter, 1e-9)
+
+	require.Len(t, diff.Usage, 3)
+	assert.Equal(t, "2025-05-31", diff.Usage[0].Day)
+	assert.Equal(t, -1, diff.Usage[0].Entries)
+	assert.Equal(t, "opus", diff.Usage[1].Model)
+	assert.Equal(t, 1, diff.Usage[1].Entries)
+	assert.InDelta(t, 0.5, diff.Usage[1].CostUSD, 1e-9)
+	assert.Equal(t, "2025-06-02", diff.Usage[2].Day)
+	assert.True(t, diff.Lost())
+
+	same := DiffSnapshots(after, after)
+	assert.Empty(t, same.Usage)
+	assert.Equal(t, 3, same.FilesSame)
+	assert.False(t, same.Lost())
+
+	_, err = ParseSnapshot([]byte(`{"version": 2}`))
+	assert.Error(t, err)
+}
@@ -6,6 +6,7 @@ import (
 
 // FileSummary represents a cached summary of a parsed usage file
 type FileSummary struct {
+	SchemaVersion          int                        `json:"schema_version"` // See CurrentSchemaVersion; 0 means a legacy unversioned summary
 	Path                   string                     `json:"path"`
 	AbsolutePath           string                     `json:"absolute_path"`
 	ModTime                time.Time                  `json:"mod_time"`
@@ -19,6 +20,27 @@ type FileSummary struct {
 	ProcessedAt            time.Time                  `json:"processed_at"`
 	Checksum               string                     `json:"checksum"`
 	HasNoAssistantMessages bool                       `json:"has_no_assistant_messages"` // True if file has no assistant messages
+	SessionHints           []SessionHint              `json:"session_hints,omitempty"`   // Session boundaries observed when the summary was built
+	Redacted               bool                       `json:"redacted,omitempty"`        // IDs were removed from the persisted paths by the retention policy
+}
+
+// SessionHint records a session boundary so sessions spanning bucket boundaries can be stitched back together
+type SessionHint struct {
+	StartTime  time.Time `json:"start_time"` // First entry timestamp rounded down to the hour
+	EndTime    time.Time `json:"end_time"`   // Timestamp of the last entry in the session
+	EntryCount int       `json:"entry_count"`
+}
+
+// Overlap returns the part of [from, to) covered by the hinted session
+func (h SessionHint) Overlap(from, to time.Time) (time.Time, time.Time, bool) {
+	start, end := from, to
+	if h.StartTime.After(start) {
+		start = h.StartTime
+	}
+	if h.EndTime.Before(end) {
+		end = h.EndTime
+	}
+	return start, end, h.StartTime.Before(to) && !h.EndTime.Before(from)
 }
 
 // TemporalBucket represents aggregated usage data for a specific time period
@@ -38,7 +60,13 @@ type ModelStat struct {
 	InputTokens         int     `json:"input_tokens"`
 	OutputTokens        int     `json:"output_tokens"`
 	CacheCreationTokens int     `json:"cache_creation_tokens"`
-	CacheReadTokens     int     `json:"cache_read_tokens"`
+	// CacheCreation1hTokens is the share of CacheCreationTokens written to the 1-hour cache tier
+	CacheCreation1hTokens int `json:"cache_creation_1h_tokens,omitempty"`
+	CacheReadTokens       int `json:"cache_read_tokens"`
+	// LargestInputTokens and LargestCacheCreationTokens are those of the message adding the most input,
+	// so that synthetic entries keep it for message-size guardrails; unset in older summaries
+	LargestInputTokens         int `json:"largest_input_tokens,omitempty"`
+	LargestCacheCreationTokens int `json:"largest_cache_creation_tokens,omitempty"`
 }
 
 // IsExpired checks if the summary is expired based on file modification time or size
@@ -0,0 +1,187 @@
+package calculations
+
+import (
+	"time"
+
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/models"
+)
+
+// AlertRuleResult is the outcome of an alert rule at a point in time
+type AlertRuleResult struct {
+	Rule      config.AlertRule `json:"rule"`
+	Value     float64          `json:"value"`
+	Threshold float64          `json:"threshold"` // The rule's threshold, or the percentile it resolved to
+	Firing    bool             `json:"firing"`
+	SessionID string           `json:"session_id,omitempty"` // The active session, for session metrics
+	// NoData is set when there is nothing to compare: no active session for a session metric, no
+	// limit for a percentage of it, or no history for a percentile
+	NoData bool `json:"no_data,omitempty"`
+}
+
+// EvaluateAlertRules evaluates every rule against blocks at now; days, weeks and months start in loc,
+// session percentages are of limits and percentiles cover the history in blocks
+func EvaluateAlertRules(rules []config.AlertRule, blocks []models.SessionBlock, limits models.PlanLimits, now time.Time, loc *time.Location) []AlertRuleResult {
+	if loc == nil {
+		loc = time.Local
+	}
+	var active *models.SessionBlock
+	for i := range blocks {
+		if blocks[i].IsActive && !blocks[i].IsGap {
+			active = &blocks[i]
+			break
+		}
+	}
+
+	results := make([]AlertRuleResult, 0, len(rules))
+	for _, rule := range rules {
+		result := AlertRuleResult{Rule: rule, Threshold: rule.Threshold}
+		switch rule.Metric {
+		case config.RuleDailyCost, config.RuleDailyTokens:
+			today := sumEntries(blocks, StartOfDay(now, loc), now)
+			result.Value = today.Cost
+			if rule.Metric == config.RuleDailyTokens {
+				result.Value = float64(today.Tokens)
+			}
+		case config.RuleWeeklyCost, config.RuleMonthlyCost:
+			period := ReportPeriodWeek
+			if rule.Metric == config.RuleMonthlyCost {
+				period = ReportPeriodMonth
+			}
+			start, _, _ := ReportPeriodBounds(period, now, loc)
+			result.Value = sumEntries(blocks, start, now).Cost
+		case config.RuleSessionCost, config.RuleSessionTokens, config.RuleSessionMessages:
+			if active == nil {
+				result.NoData = true
+				break
+			}
+			result.SessionID = active.ID
+			result.Value = sessionMetric(*active, rule.Metric)
+		case config.RuleSessionCostPct, config.RuleSessionTokensPct:
+			limit := limits.CostLimit
+			if rule.Metric == config.RuleSessionTokensPct {
+				limit = float64(limits.TokenLimit)
+			}
+			if active == nil || limit <= 0 {
+				result.NoData = true
+				break
+			}
+			result.SessionID = active.ID
+			used := active.CostUSD
+			if rule.Metric == config.RuleSessionTokensPct {
+				used = float64(active.TokenCounts.TotalTokens())
+			}
+			result.Value = used / limit * 100
+		case config.RuleBurnRate, config.RuleCostRate:
+			window := sumEntries(blocks, now.Add(-rule.Window), now)
+			if rule.Metric == config.RuleBurnRate {
+				result.Value = float64(window.Tokens) / rule.Window.Minutes()
+			} else {
+				result.Value = window.Cost / rule.Window.Hours()
+			}
+		}
+
+		if rule.Percentile != "" && !result.NoData {
+			history := ruleHistory(rule.Metric, blocks, now, loc)
+			if len(history) == 0 {
+				result.NoData = true
+			} else {
+				result.Threshold = namedPercentile(NewPercentiles(history), rule.Percentile)
+			}
+		}
+		result.Firing = !result.NoData && rule.Compare(result.Value, result.Threshold)
+		results = append(results, result)
+	}
+	return results
+}
+
+// sessionMetric returns the value of a session metric for block
+func sessionMetric(block models.SessionBlock, metric string) float64 {
+	switch metric {
+	case config.RuleSessionCost:
+		return block.CostUSD
+	case config.RuleSessionMessages:
+		return float64(block.SentMessagesCount)
+	default:
+		return float64(block.TokenCounts.TotalTokens())
+	}
+}
+
+// ruleHistory returns the past values a percentile rule compares with: those of the completed
+// sessions for session metrics and of the days before today with usage for daily metrics
+func ruleHistory(metric string, blocks []models.SessionBlock, now time.Time, loc *time.Location) []float64 {
+	var history []float64
+	switch metric {
+	case config.RuleSessionCost, config.RuleSessionTokens, config.RuleSessionMessages:
+		for _, sample := range completedSessions(blocks) {
+			switch metric {
+			case config.RuleSessionCost:
+				history = append(history, sample.cost)
+			case config.RuleSessionMessages:
+				history = append(history, sample.messages)
+			default:
+				history = append(history, sample.tokens)
+			}
+		}
+	case config.RuleDailyCost, config.RuleDailyTokens:
+		today := StartOfDay(now, loc)
+		days := make(map[time.Time]*DayTotals)
+		for _, block := range blocks {
+			if block.IsGap {
+				continue
+			}
+			for _, entry := range block.Entries {
+				if !entry.Timestamp.Before(today) {
+					continue
+				}
+				day := StartOfDay(entry.Timestamp, loc)
+				if days[day] == nil {
+					days[day] = &DayTotals{Date: day}
+				}
+				days[day].Tokens += entry.CalculateTotalTokens()
+				days[day].Cost += entry.CostUSD
+			}
+		}
+		for _, totals := range days {
+			if metric == config.RuleDailyCost {
+				history = append(history, totals.Cost)
+			} else {
+				history = append(history, float64(totals.Tokens))
+			}
+		}
+	}
+	return history
+}
+
+// sumEntries totals the entries of blocks recorded in [from, to]
+func sumEntries(blocks []models.SessionBlock, from, to time.Time) DayTotals {
+	totals := DayTotals{Date: from}
+	for _, block := range blocks {
+		if block.IsGap {
+			continue
+		}
+		for _, entry := range block.Entries {
+			if entry.Timestamp.Before(from) || entry.Timestamp.After(to) {
+				continue
+			}
+			totals.Entries++
+			totals.Tokens += entry.CalculateTotalTokens()
+			totals.Cost += entry.CostUSD
+		}
+	}
+	return totals
+}
+
+// namedPercentile returns the percentile of p named p50, p75, p90 or p99
+func namedPercentile(p Percentiles, name string) float64 {
+	switch name {
+	case "p50":
+		return p.P50
+	case "p75":
+		return p.P75
+	case "p99":
+		return p.P99
+	default:
+		return p.P90
+	}
+}
@@ -0,0 +1,110 @@
+package calculations
+
+import (
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ruleBlock builds a session block with an entry of tokens and cost at each time
+func ruleBlock(id string, active bool, tokens int, cost float64, at ...time.Time) models.SessionBlock {
+	block := models.SessionBlock{ID: id, StartTime: at[0], EndTime: at[0].Add(models.SessionDuration), IsActive: active}
+	for _, t := range at {
+		block.Entries = append(block.Entries, models.UsageEntry{Timestamp: t, InputTokens: tokens, CostUSD: cost})
+		block.TokenCounts.InputTokens += tokens
+		block.CostUSD += cost
+		block.SentMessagesCount++
+	}
+	return block
+}
+
+func mustParseRules(t *testing.T, when ...string) []config.AlertRule {
+	t.Helper()
+	var configs []config.AlertRuleConfig
+	for _, w := range when {
+		configs = append(configs, config.AlertRuleConfig{When: w})
+	}
+	rules, err := config.ParseAlertRules(configs)
+	require.NoError(t, err)
+	return rules
+}
+
+func TestEvaluateAlertRules(t *testing.T) {
+	// Wednesday June 4, 2025
+	now := time.Date(2025, 6, 4, 15, 0, 0, 0, time.UTC)
+	at := func(day, hour, minute int) time.Time { return time.Date(2025, 6, day, hour, minute, 0, 0, time.UTC) }
+	blocks := []models.SessionBlock{
+		ruleBlock("mon", false, 1000, 1, at(2, 9, 0), at(2, 9, 30)),
+		ruleBlock("tue", false, 1000, 2, at(3, 9, 0)),
+		ruleBlock("now", true, 10_000, 5, at(4, 14, 52), at(4, 14, 56), at(4, 14, 58)),
+		ruleBlock("later", false, 1000, 50, at(4, 16, 0)), // After now
+	}
+	rules := mustParseRules(t,
+		"daily_cost > 10",
+		"weekly_cost >= 19",
+		"session_messages > p90",
+		"burn_rate > 5k for 10m",
+		"cost_rate > 100",
+		"daily_tokens > p50",
+		"monthly_cost > 70",
+		"session_tokens_pct >= 75",
+		"session_cost_pct < 50",
+	)
+
+	results := EvaluateAlertRules(rules, blocks, models.PlanLimits{TokenLimit: 40_000, CostLimit: 10}, now, time.UTC)
+	require.Len(t, results, len(rules))
+
+	assert.InDelta(t, 15, results[0].Value, 0.001, "entries after now are left out")
+	assert.True(t, results[0].Firing)
+
+	assert.InDelta(t, 19, results[1].Value, 0.001, "the week starts on Monday")
+	assert.True(t, results[1].Firing)
+
+	assert.Equal(t, 3.0, results[2].Value)
+	assert.Equal(t, 2.0, results[2].Threshold, "the P90 of the completed sessions")
+	assert.Equal(t, "now", results[2].SessionID)
+	assert.True(t, results[2].Firing)
+
+	assert.Equal(t, 3000.0, results[3].Value, "30,000 tokens over 10 minutes")
+	assert.False(t, results[3].Firing)
+
+	assert.InDelta(t, 120, results[4].Value, 0.001, "$10 in the default 5 minutes")
+	assert.True(t, results[4].Firing)
+
+	assert.Equal(t, 30_000.0, results[5].Value)
+	assert.Equal(t, 2000.0, results[5].Threshold, "the median of the past days")
+	assert.True(t, results[5].Firing)
+
+	assert.InDelta(t, 19, results[6].Value, 0.001, "June 2 to 4")
+	assert.False(t, results[6].Firing)
+
+	assert.InDelta(t, 75, results[7].Value, 0.001, "30,000 of 40,000 tokens")
+	assert.Equal(t, "now", results[7].SessionID)
+	assert.True(t, results[7].Firing)
+
+	assert.InDelta(t, 150, results[8].Value, 0.001, "$15 of $10")
+	assert.False(t, results[8].Firing)
+}
+
+func TestEvaluateAlertRules_NoData(t *testing.T) {
+	now := time.Date(2025, 6, 4, 15, 0, 0, 0, time.UTC)
+	rules := mustParseRules(t, "session_cost > 1", "daily_cost > p90", "daily_cost < 1", "session_tokens_pct > 50")
+	blocks := []models.SessionBlock{ruleBlock("done", false, 100, 0.5, now.Add(-time.Hour))}
+
+	results := EvaluateAlertRules(rules, blocks, models.PlanLimits{}, now, time.UTC)
+	assert.True(t, results[0].NoData, "no active session")
+	assert.False(t, results[0].Firing)
+	assert.True(t, results[1].NoData, "no past days")
+	assert.False(t, results[1].Firing)
+	assert.False(t, results[2].NoData)
+	assert.True(t, results[2].Firing)
+	assert.True(t, results[3].NoData)
+
+	blocks[0].IsActive = true
+	results = EvaluateAlertRules(rules, blocks, models.PlanLimits{}, now, time.UTC)
+	assert.True(t, results[3].NoData, "no token limit")
+}
@@ -0,0 +1,111 @@
+package calculations
+
+import (
+	"sort"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+)
+
+// Anomaly severities, by the cost of the day relative to its baseline
+const (
+	AnomalySeverityLow    = "low"
+	AnomalySeverityMedium = "medium" // At least twice the baseline
+	AnomalySeverityHigh   = "high"   // At least four times the baseline
+)
+
+// UsageAnomaly is a day whose cost was well above the days before it, with what drove it
+type UsageAnomaly struct {
+	Date       time.Time    `json:"date"`
+	Expected   float64      `json:"expected"` // Mean daily cost over the active baseline days
+	Actual     float64      `json:"actual"`
+	Ratio      float64      `json:"ratio"` // Actual relative to expected
+	Severity   string       `json:"severity"`
+	TopModel   *DigestShare `json:"top_model,omitempty"`
+	TopProject *DigestShare `json:"top_project,omitempty"`
+}
+
+// AnomalySeverity returns the severity of a day costing ratio times its baseline
+func AnomalySeverity(ratio float64) string {
+	switch {
+	case ratio >= 4:
+		return AnomalySeverityHigh
+	case ratio >= 2:
+		return AnomalySeverityMedium
+	default:
+		return AnomalySeverityLow
+	}
+}
+
+// DetectAnomalies runs the digest's anomaly detection over the daily cost of every day from start to
+// end in loc, each against the DigestBaselineDays before it, and ranks the anomalous days by their
+// ratio to the baseline, highest first
+func DetectAnomalies(blocks []models.SessionBlock, start, end time.Time, loc *time.Location) []UsageAnomaly {
+	if loc == nil {
+		loc = time.Local
+	}
+	dayOf := func(t time.Time) time.Time {
+		local := t.In(loc)
+		return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	}
+	first := dayOf(start)
+
+	daily := make(map[time.Time]float64)
+	modelCosts := make(map[time.Time]map[string]float64)
+	projectCosts := make(map[time.Time]map[string]float64)
+	for _, block := range blocks {
+		if block.IsGap {
+			continue
+		}
+		for _, entry := range block.Entries {
+			if entry.Timestamp.After(end) {
+				continue
+			}
+			day := dayOf(entry.Timestamp)
+			daily[day] += entry.CostUSD
+			if day.Before(first) {
+				continue
+			}
+			if modelCosts[day] == nil {
+				modelCosts[day] = make(map[string]float64)
+				projectCosts[day] = make(map[string]float64)
+			}
+			modelCosts[day][entry.Model] += entry.CostUSD
+			if entry.Project != "" {
+				projectCosts[day][entry.Project] += entry.CostUSD
+			}
+		}
+	}
+
+	anomalies := []UsageAnomaly{}
+	for day, cost := range daily {
+		if day.Before(first) {
+			continue
+		}
+		baselineStart := day.AddDate(0, 0, -DigestBaselineDays)
+		baseline := make(map[time.Time]float64)
+		for other, otherCost := range daily {
+			if !other.Before(baselineStart) && other.Before(day) {
+				baseline[other] = otherCost
+			}
+		}
+		for _, flagged := range detectDailyAnomalies(map[time.Time]float64{day: cost}, baseline) {
+			anomalies = append(anomalies, UsageAnomaly{
+				Date:       flagged.Date,
+				Expected:   flagged.Baseline,
+				Actual:     flagged.Cost,
+				Ratio:      flagged.Ratio,
+				Severity:   AnomalySeverity(flagged.Ratio),
+				TopModel:   topShare(modelCosts[day], cost),
+				TopProject: topShare(projectCosts[day], cost),
+			})
+		}
+	}
+	sort.Slice(anomalies, func(i, j int) bool {
+		if anomalies[i].Ratio != anomalies[j].Ratio {
+			return anomalies[i].Ratio > anomalies[j].Ratio
+		}
+		return anomalies[i].Date.Before(anomalies[j].Date)
+	})
+	return anomalies
+}
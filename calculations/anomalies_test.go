@@ -0,0 +1,51 @@
+package calculations
+
+import (
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectAnomalies(t *testing.T) {
+	now := time.Date(2025, 3, 31, 18, 0, 0, 0, time.UTC)
+	day := func(offset int) time.Time { return time.Date(2025, 3, 31+offset, 10, 0, 0, 0, time.UTC) }
+
+	var blocks []models.SessionBlock
+	// Baseline alternating between $2 and $3 a day
+	for i := -40; i <= -11; i++ {
+		blocks = append(blocks, digestBlock(day(i), "api", float64(2+(-i)%2)))
+	}
+	opus := digestBlock(day(-2), "webapp", 10)
+	opus.Entries[0].Model = models.ModelOpus
+	blocks = append(blocks,
+		digestBlock(day(-5), "api", 6),
+		digestBlock(day(-2), "api", 2),
+		opus,
+		digestBlock(day(-1), "api", 3),
+		models.SessionBlock{StartTime: day(0), CostUSD: 50, IsGap: true},
+	)
+
+	anomalies := DetectAnomalies(blocks, now.AddDate(0, 0, -6), now, time.UTC)
+	require.Len(t, anomalies, 2)
+
+	assert.Equal(t, time.Date(2025, 3, 29, 0, 0, 0, 0, time.UTC), anomalies[0].Date, "ranked by ratio")
+	assert.InDelta(t, 12.0, anomalies[0].Actual, 0.001)
+	assert.Equal(t, AnomalySeverityHigh, anomalies[0].Severity)
+	require.NotNil(t, anomalies[0].TopModel)
+	assert.Equal(t, models.ModelOpus, anomalies[0].TopModel.Name)
+	require.NotNil(t, anomalies[0].TopProject)
+	assert.Equal(t, "webapp", anomalies[0].TopProject.Name)
+	assert.InDelta(t, 10.0/12, anomalies[0].TopProject.Share, 0.001)
+
+	assert.Equal(t, time.Date(2025, 3, 26, 0, 0, 0, 0, time.UTC), anomalies[1].Date)
+	assert.InDelta(t, 2.52, anomalies[1].Expected, 0.01)
+	assert.Equal(t, AnomalySeverityMedium, anomalies[1].Severity)
+	assert.Equal(t, "api", anomalies[1].TopProject.Name)
+
+	assert.Empty(t, DetectAnomalies(blocks, now, now, time.UTC), "nothing unusual today")
+	assert.Empty(t, DetectAnomalies(nil, now.AddDate(0, 0, -90), now, time.UTC))
+	assert.Equal(t, AnomalySeverityLow, AnomalySeverity(1.5))
+}
@@ -0,0 +1,150 @@
+package calculations
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+)
+
+// benchmarksJSON is the benchmark distribution shipped with the binary; comparisons never use the network
+//
+//go:embed benchmarks.json
+var benchmarksJSON []byte
+
+// BenchmarkPoint is the monthly cost at one percentile of a plan's users
+type BenchmarkPoint struct {
+	Percentile  float64 `json:"percentile"`
+	MonthlyCost float64 `json:"monthly_cost"`
+}
+
+// BenchmarkPlan is the distribution of monthly API-equivalent cost among users of one plan
+type BenchmarkPlan struct {
+	Plan   string           `json:"plan"`
+	Users  int              `json:"users"`
+	Points []BenchmarkPoint `json:"points"` // Ordered by percentile
+}
+
+// Benchmarks holds the anonymized usage distributions of typical users per plan
+type Benchmarks struct {
+	Version     string          `json:"version"`
+	Description string          `json:"description"`
+	Plans       []BenchmarkPlan `json:"plans"`
+}
+
+// BenchmarkComparison places the monthly cost of a report in the distribution of a plan
+type BenchmarkComparison struct {
+	Plan        string  `json:"plan"`
+	PlanName    string  `json:"plan_name"`
+	Version     string  `json:"version"`
+	MonthlyCost float64 `json:"monthly_cost"`
+	Projected   bool    `json:"projected"` // MonthlyCost extrapolates a month in progress
+	Percentile  int     `json:"percentile"`
+	Median      float64 `json:"median"`
+}
+
+// LoadBenchmarks parses the benchmark distributions shipped with the binary
+func LoadBenchmarks() (*Benchmarks, error) {
+	var benchmarks Benchmarks
+	if err := json.Unmarshal(benchmarksJSON, &benchmarks); err != nil {
+		return nil, fmt.Errorf("failed to parse benchmarks: %w", err)
+	}
+	for i := range benchmarks.Plans {
+		points := benchmarks.Plans[i].Points
+		sort.Slice(points, func(a, b int) bool {
+			return points[a].Percentile < points[b].Percentile
+		})
+	}
+	return &benchmarks, nil
+}
+
+// Plan returns the distribution of plan, if the benchmarks include it
+func (b *Benchmarks) Plan(plan string) (BenchmarkPlan, bool) {
+	for _, p := range b.Plans {
+		if strings.EqualFold(p.Plan, plan) {
+			return p, true
+		}
+	}
+	return BenchmarkPlan{}, false
+}
+
+// PlanNames returns the plans the benchmarks cover
+func (b *Benchmarks) PlanNames() []string {
+	names := make([]string, len(b.Plans))
+	for i, p := range b.Plans {
+		names[i] = p.Plan
+	}
+	return names
+}
+
+// Percentile returns the percentile of users of the plan whose monthly cost is at most monthlyCost,
+// interpolating linearly between the points of the distribution and from zero below the first one.
+// Any usage places at least in the 1st percentile.
+func (p BenchmarkPlan) Percentile(monthlyCost float64) int {
+	if len(p.Points) == 0 || monthlyCost <= 0 {
+		return 0
+	}
+	previous := BenchmarkPoint{}
+	for _, point := range p.Points {
+		if monthlyCost <= point.MonthlyCost {
+			span := point.MonthlyCost - previous.MonthlyCost
+			if span <= 0 {
+				return int(point.Percentile)
+			}
+			fraction := (monthlyCost - previous.MonthlyCost) / span
+			return max(int(math.Floor(previous.Percentile+fraction*(point.Percentile-previous.Percentile))), 1)
+		}
+		previous = point
+	}
+	return int(previous.Percentile)
+}
+
+// Median returns the monthly cost of the median user of the plan
+func (p BenchmarkPlan) Median() float64 {
+	previous := BenchmarkPoint{}
+	for _, point := range p.Points {
+		if point.Percentile >= 50 {
+			if point.Percentile == previous.Percentile {
+				return point.MonthlyCost
+			}
+			fraction := (50 - previous.Percentile) / (point.Percentile - previous.Percentile)
+			return previous.MonthlyCost + fraction*(point.MonthlyCost-previous.MonthlyCost)
+		}
+		previous = point
+	}
+	return previous.MonthlyCost
+}
+
+// CompareBenchmark places the cost of a monthly report among typical users of plan. The cost of a
+// month still in progress is extrapolated to the whole month from the time elapsed by now.
+func (r Report) CompareBenchmark(benchmarks *Benchmarks, plan string, now time.Time) (BenchmarkComparison, error) {
+	if r.Period != ReportPeriodMonth {
+		return BenchmarkComparison{}, fmt.Errorf("benchmarks compare monthly usage; use a month period")
+	}
+	distribution, ok := benchmarks.Plan(plan)
+	if !ok {
+		return BenchmarkComparison{}, fmt.Errorf("no benchmark for plan %q (available: %s)", plan, strings.Join(benchmarks.PlanNames(), ", "))
+	}
+
+	comparison := BenchmarkComparison{
+		Plan:        distribution.Plan,
+		PlanName:    models.GetPlan(distribution.Plan).Name,
+		Version:     benchmarks.Version,
+		MonthlyCost: r.TotalCost,
+		Median:      distribution.Median(),
+	}
+	if !r.Complete {
+		elapsed := now.Sub(r.Start)
+		if elapsed > 0 {
+			comparison.MonthlyCost = r.TotalCost * float64(r.End.Sub(r.Start)) / float64(elapsed)
+			comparison.Projected = true
+		}
+	}
+	comparison.Percentile = distribution.Percentile(comparison.MonthlyCost)
+	return comparison, nil
+}
@@ -0,0 +1,70 @@
+package calculations
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadBenchmarks(t *testing.T) {
+	benchmarks, err := LoadBenchmarks()
+	require.NoError(t, err)
+	assert.NotEmpty(t, benchmarks.Version)
+	for _, name := range []string{"pro", "max5", "max20"} {
+		plan, ok := benchmarks.Plan(name)
+		require.True(t, ok, name)
+		require.NotEmpty(t, plan.Points)
+		for i := 1; i < len(plan.Points); i++ {
+			assert.Greater(t, plan.Points[i].MonthlyCost, plan.Points[i-1].MonthlyCost, "%s costs increase with the percentile", name)
+		}
+	}
+	_, ok := benchmarks.Plan("custom")
+	assert.False(t, ok)
+}
+
+func TestBenchmarkPlan_Percentile(t *testing.T) {
+	plan := BenchmarkPlan{Plan: "max20", Points: []BenchmarkPoint{
+		{Percentile: 10, MonthlyCost: 100},
+		{Percentile: 50, MonthlyCost: 500},
+		{Percentile: 90, MonthlyCost: 900},
+	}}
+	assert.Equal(t, 0, plan.Percentile(0))
+	assert.Equal(t, 1, plan.Percentile(1))
+	assert.Equal(t, 5, plan.Percentile(50))
+	assert.Equal(t, 10, plan.Percentile(100))
+	assert.Equal(t, 30, plan.Percentile(300))
+	assert.Equal(t, 90, plan.Percentile(5000), "capped at the last point")
+	assert.InDelta(t, 500, plan.Median(), 0.001)
+}
+
+func TestReport_CompareBenchmark(t *testing.T) {
+	benchmarks := &Benchmarks{Version: "test", Plans: []BenchmarkPlan{{Plan: "max20", Points: []BenchmarkPoint{
+		{Percentile: 50, MonthlyCost: 1000},
+		{Percentile: 99, MonthlyCost: 2000},
+	}}}}
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	report := Report{Period: ReportPeriodMonth, Start: start, End: start.AddDate(0, 1, 0), Complete: true, TotalCost: 500}
+
+	comparison, err := report.CompareBenchmark(benchmarks, "max20", start.AddDate(0, 2, 0))
+	require.NoError(t, err)
+	assert.Equal(t, 25, comparison.Percentile)
+	assert.False(t, comparison.Projected)
+	assert.Equal(t, "Claude Max 20", comparison.PlanName)
+	assert.InDelta(t, 1000, comparison.Median, 0.001)
+
+	// Ten days into a thirty-day month, the cost so far is tripled
+	report.Complete = false
+	comparison, err = report.CompareBenchmark(benchmarks, "max20", start.AddDate(0, 0, 10))
+	require.NoError(t, err)
+	assert.True(t, comparison.Projected)
+	assert.InDelta(t, 1500, comparison.MonthlyCost, 0.001)
+	assert.Equal(t, 74, comparison.Percentile)
+
+	_, err = report.CompareBenchmark(benchmarks, "pro", start)
+	assert.ErrorContains(t, err, "available: max20")
+	report.Period = ReportPeriodWeek
+	_, err = report.CompareBenchmark(benchmarks, "max20", start)
+	assert.Error(t, err)
+}
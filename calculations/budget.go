@@ -0,0 +1,102 @@
+package calculations
+
+import (
+	"time"
+
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/models"
+)
+
+// Budget statuses, in increasing severity
+const (
+	BudgetOK       = "ok"
+	BudgetWarning  = "warning"
+	BudgetExceeded = "exceeded"
+)
+
+// BudgetPeriodDay is the period of a daily budget; weekly and monthly budgets use the report periods
+const BudgetPeriodDay = "day"
+
+// BudgetCheck compares the spend of the current period against its budget
+type BudgetCheck struct {
+	Period    string    `json:"period"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"` // Exclusive
+	Budget    float64   `json:"budget"`
+	Spent     float64   `json:"spent"`
+	Remaining float64   `json:"remaining"` // Negative once the budget is exceeded
+	Used      float64   `json:"used"`      // Fraction of the budget spent
+	Status    string    `json:"status"`
+}
+
+// BudgetStatus is the result of checking every configured budget
+type BudgetStatus struct {
+	CheckedAt time.Time     `json:"checked_at"`
+	Status    string        `json:"status"` // Most severe status of the checks
+	Checks    []BudgetCheck `json:"checks"`
+}
+
+// BudgetPeriods returns the bounds of the current day, week and month in loc for the budgets that are set
+func BudgetPeriods(budgets config.BudgetConfig, now time.Time, loc *time.Location) []BudgetCheck {
+	var checks []BudgetCheck
+	if budgets.Daily > 0 {
+		start := StartOfDay(now, loc)
+		checks = append(checks, BudgetCheck{Period: BudgetPeriodDay, Start: start, End: start.AddDate(0, 0, 1), Budget: budgets.Daily})
+	}
+	for _, budget := range []struct {
+		period string
+		amount float64
+	}{{ReportPeriodWeek, budgets.Weekly}, {ReportPeriodMonth, budgets.Monthly}} {
+		if budget.amount <= 0 {
+			continue
+		}
+		start, end, _ := ReportPeriodBounds(budget.period, now, loc)
+		checks = append(checks, BudgetCheck{Period: budget.period, Start: start, End: end, Budget: budget.amount})
+	}
+	return checks
+}
+
+// CheckBudgets sums the spend of the current day, week and month up to now and compares it against the
+// budgets that are set. A period whose spend reaches the warn threshold of its budget is a warning.
+func CheckBudgets(budgets config.BudgetConfig, blocks []models.SessionBlock, now time.Time, loc *time.Location) BudgetStatus {
+	if loc == nil {
+		loc = time.Local
+	}
+	status := BudgetStatus{CheckedAt: now, Status: BudgetOK, Checks: BudgetPeriods(budgets, now, loc)}
+
+	for _, block := range blocks {
+		if block.IsGap {
+			continue
+		}
+		for _, entry := range block.Entries {
+			if entry.Timestamp.After(now) {
+				continue
+			}
+			for i := range status.Checks {
+				check := &status.Checks[i]
+				if !entry.Timestamp.Before(check.Start) && entry.Timestamp.Before(check.End) {
+					check.Spent += entry.CostUSD
+				}
+			}
+		}
+	}
+
+	severity := map[string]int{BudgetOK: 0, BudgetWarning: 1, BudgetExceeded: 2}
+	for i := range status.Checks {
+		check := &status.Checks[i]
+		check.Remaining = check.Budget - check.Spent
+		check.Used = check.Spent / check.Budget
+		switch {
+		case check.Spent > check.Budget:
+			check.Status = BudgetExceeded
+		case budgets.WarnThreshold > 0 && check.Used >= budgets.WarnThreshold:
+			check.Status = BudgetWarning
+		default:
+			check.Status = BudgetOK
+		}
+		if severity[check.Status] > severity[status.Status] {
+			status.Status = check.Status
+		}
+	}
+	return status
+}
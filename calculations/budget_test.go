@@ -0,0 +1,47 @@
+package calculations
+
+import (
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckBudgets(t *testing.T) {
+	// Wednesday June 4, 2025
+	now := time.Date(2025, 6, 4, 15, 0, 0, 0, time.UTC)
+	day := func(d, hour int) time.Time { return time.Date(2025, 6, d, hour, 0, 0, 0, time.UTC) }
+	blocks := []models.SessionBlock{
+		digestBlock(day(1, 10), "api", 10), // Previous week, current month
+		digestBlock(day(3, 10), "api", 4),
+		digestBlock(day(4, 9), "api", 3),
+		digestBlock(day(4, 16), "api", 50), // After now
+		{StartTime: day(4, 10), CostUSD: 99, IsGap: true},
+	}
+
+	status := CheckBudgets(config.BudgetConfig{Daily: 5, Weekly: 6, Monthly: 100, WarnThreshold: 0.5}, blocks, now, time.UTC)
+	assert.Equal(t, BudgetExceeded, status.Status)
+	require.Len(t, status.Checks, 3)
+
+	daily, weekly, monthly := status.Checks[0], status.Checks[1], status.Checks[2]
+	assert.Equal(t, BudgetPeriodDay, daily.Period)
+	assert.InDelta(t, 3.0, daily.Spent, 0.001)
+	assert.Equal(t, BudgetWarning, daily.Status)
+
+	assert.Equal(t, day(2, 0), weekly.Start)
+	assert.InDelta(t, 7.0, weekly.Spent, 0.001)
+	assert.InDelta(t, -1.0, weekly.Remaining, 0.001)
+	assert.Equal(t, BudgetExceeded, weekly.Status)
+
+	assert.InDelta(t, 17.0, monthly.Spent, 0.001)
+	assert.InDelta(t, 0.17, monthly.Used, 0.001)
+	assert.Equal(t, BudgetOK, monthly.Status)
+
+	// Without a warn threshold, only exceeded budgets are reported
+	status = CheckBudgets(config.BudgetConfig{Daily: 5}, blocks, now, time.UTC)
+	assert.Equal(t, BudgetOK, status.Status)
+	assert.Len(t, status.Checks, 1)
+}
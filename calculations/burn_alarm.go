@@ -0,0 +1,50 @@
+package calculations
+
+import "time"
+
+// DefaultBurnAlarmDuration is how long the burn rate must stay above the threshold before the alarm sounds
+const DefaultBurnAlarmDuration = time.Minute
+
+// BurnRateAlarm tracks how long the burn rate has exceeded a threshold and fires once per excursion
+type BurnRateAlarm struct {
+	threshold float64
+	duration  time.Duration
+	since     time.Time
+	fired     bool
+}
+
+// NewBurnRateAlarm creates an alarm for a threshold in tokens/min sustained for duration; 0 uses DefaultBurnAlarmDuration
+func NewBurnRateAlarm(threshold float64, duration time.Duration) *BurnRateAlarm {
+	if duration <= 0 {
+		duration = DefaultBurnAlarmDuration
+	}
+	return &BurnRateAlarm{threshold: threshold, duration: duration}
+}
+
+// Observe records the burn rate at now and reports whether the alarm should sound. It fires once when the
+// rate has stayed above the threshold for the configured duration and re-arms when the rate drops back.
+func (a *BurnRateAlarm) Observe(tokensPerMinute float64, now time.Time) bool {
+	if a.threshold <= 0 || tokensPerMinute <= a.threshold {
+		a.since = time.Time{}
+		a.fired = false
+		return false
+	}
+	if a.since.IsZero() {
+		a.since = now
+	}
+	if a.fired || now.Sub(a.since) < a.duration {
+		return false
+	}
+	a.fired = true
+	return true
+}
+
+// Threshold returns the burn rate in tokens/min above which the alarm arms
+func (a *BurnRateAlarm) Threshold() float64 {
+	return a.threshold
+}
+
+// Duration returns how long the burn rate must stay above the threshold
+func (a *BurnRateAlarm) Duration() time.Duration {
+	return a.duration
+}
@@ -0,0 +1,30 @@
+package calculations
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBurnRateAlarm(t *testing.T) {
+	start := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	at := func(seconds int) time.Time { return start.Add(time.Duration(seconds) * time.Second) }
+	alarm := NewBurnRateAlarm(500, 30*time.Second)
+
+	assert.False(t, alarm.Observe(400, at(0)), "below threshold")
+	assert.False(t, alarm.Observe(600, at(10)), "above threshold but not for long enough")
+	assert.False(t, alarm.Observe(600, at(30)))
+	assert.True(t, alarm.Observe(700, at(40)), "sustained for the duration")
+	assert.False(t, alarm.Observe(700, at(90)), "fires once per excursion")
+
+	// Dropping below the threshold re-arms the alarm and restarts the clock
+	assert.False(t, alarm.Observe(100, at(100)))
+	assert.False(t, alarm.Observe(800, at(110)))
+	assert.True(t, alarm.Observe(800, at(140)))
+
+	disabled := NewBurnRateAlarm(0, 0)
+	assert.Equal(t, DefaultBurnAlarmDuration, disabled.Duration())
+	assert.False(t, disabled.Observe(1e6, at(0)))
+	assert.False(t, disabled.Observe(1e6, at(3600)))
+}
@@ -3,15 +3,23 @@ package calculations
 import (
 	"time"
 
+	"github.com/penwyp/claudecat/clock"
 	"github.com/penwyp/claudecat/models"
 )
 
 // BurnRateCalculator calculates burn rates and usage projections for session blocks
-type BurnRateCalculator struct{}
+type BurnRateCalculator struct {
+	clock clock.Clock
+}
 
 // NewBurnRateCalculator creates a new burn rate calculator
 func NewBurnRateCalculator() *BurnRateCalculator {
-	return &BurnRateCalculator{}
+	return &BurnRateCalculator{clock: clock.Real}
+}
+
+// SetClock sets the clock of the projections and history taken at the current time
+func (brc *BurnRateCalculator) SetClock(c clock.Clock) {
+	brc.clock = clock.OrReal(c)
 }
 
 // CalculateBurnRate calculates current consumption rate for active blocks
@@ -41,12 +49,16 @@ func (brc *BurnRateCalculator) CalculateBurnRate(block models.SessionBlock) *mod
 
 // ProjectBlockUsage projects total usage if current rate continues
 func (brc *BurnRateCalculator) ProjectBlockUsage(block models.SessionBlock) *models.UsageProjection {
+	return brc.ProjectBlockUsageAt(block, clock.OrReal(brc.clock).Now().UTC())
+}
+
+// ProjectBlockUsageAt projects total usage at the end of the block if the rate observed at now continues
+func (brc *BurnRateCalculator) ProjectBlockUsageAt(block models.SessionBlock, now time.Time) *models.UsageProjection {
 	burnRate := brc.CalculateBurnRate(block)
 	if burnRate == nil {
 		return nil
 	}
 
-	now := time.Now().UTC()
 	remainingDuration := block.EndTime.Sub(now)
 	if remainingDuration <= 0 {
 		return nil
@@ -189,7 +201,7 @@ func (brc *BurnRateCalculator) CalculateGlobalBurnRate(blocks []models.SessionBl
 // GetBurnRateHistory returns historical burn rate data for analysis
 func (brc *BurnRateCalculator) GetBurnRateHistory(blocks []models.SessionBlock, duration time.Duration) []models.BurnRate {
 	var history []models.BurnRate
-	now := time.Now().UTC()
+	now := clock.OrReal(brc.clock).Now().UTC()
 
 	// Sample burn rates at regular intervals
 	sampleInterval := duration / 20 // 20 data points
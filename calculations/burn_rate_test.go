@@ -0,0 +1,37 @@
+package calculations
+
+import (
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBurnRateCalculator_SetClock(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	blocks := smoothingTestBlocks(now)
+	fake := clock.NewFake(now)
+	calc := NewBurnRateCalculator()
+	calc.SetClock(fake)
+
+	projection := calc.ProjectBlockUsage(blocks[0])
+	require.NotNil(t, projection)
+	assert.Equal(t, calc.ProjectBlockUsageAt(blocks[0], now), projection)
+	assert.InDelta(t, 210, projection.RemainingMinutes, 1e-9)
+
+	// The block started 90 minutes before now and ends five hours after its start
+	fake.Advance(3*time.Hour + 29*time.Minute)
+	projection = calc.ProjectBlockUsage(blocks[0])
+	require.NotNil(t, projection)
+	assert.InDelta(t, 1, projection.RemainingMinutes, 1e-9)
+	fake.Advance(time.Minute)
+	assert.Nil(t, calc.ProjectBlockUsage(blocks[0]))
+
+	fake.Set(now)
+	history := calc.GetBurnRateHistory(blocks, 2*time.Hour)
+	require.Len(t, history, 20)
+	assert.InDelta(t, calc.CalculateHourlyBurnRate(blocks, now.Add(-2*time.Hour)), history[0].TokensPerMinute, 1e-9)
+	assert.InDelta(t, calc.CalculateHourlyBurnRate(blocks, now.Add(-6*time.Minute)), history[19].TokensPerMinute, 1e-9)
+}
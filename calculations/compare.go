@@ -0,0 +1,170 @@
+package calculations
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+)
+
+// CompareRange is one of the two time ranges of a comparison; both bounds are inclusive
+type CompareRange struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// Contains reports whether t falls within the range
+func (r CompareRange) Contains(t time.Time) bool {
+	return !t.Before(r.From) && !t.After(r.To)
+}
+
+// PeriodUsage is the total usage within one compared range
+type PeriodUsage struct {
+	CompareRange
+	Messages            int     `json:"messages"`
+	Sessions            int     `json:"sessions"`
+	InputTokens         int     `json:"input_tokens"`
+	OutputTokens        int     `json:"output_tokens"`
+	CacheCreationTokens int     `json:"cache_creation_tokens"`
+	CacheReadTokens     int     `json:"cache_read_tokens"`
+	TotalTokens         int     `json:"total_tokens"`
+	CostUSD             float64 `json:"cost_usd"`
+}
+
+// MetricDelta is the change of one metric from the base range to the current one
+type MetricDelta struct {
+	Metric    string   `json:"metric"`
+	Base      float64  `json:"base"`
+	Current   float64  `json:"current"`
+	Change    float64  `json:"change"`
+	ChangePct *float64 `json:"change_pct"` // Relative change in percent; nil when the base is zero
+}
+
+// ModelShift is the change of one model's usage and share of the cost between the compared ranges
+type ModelShift struct {
+	Model         string  `json:"model"`
+	BaseCost      float64 `json:"base_cost"`
+	CurrentCost   float64 `json:"current_cost"`
+	CostChange    float64 `json:"cost_change"`
+	BaseTokens    int     `json:"base_tokens"`
+	CurrentTokens int     `json:"current_tokens"`
+	TokenChange   int     `json:"token_change"`
+	BaseShare     float64 `json:"base_share"` // Fraction of the range's total cost
+	CurrentShare  float64 `json:"current_share"`
+	ShareChange   float64 `json:"share_change"` // Difference of the shares, 0.05 being 5 percentage points
+}
+
+// PeriodComparison compares the usage of a current range against a base range
+type PeriodComparison struct {
+	Base    PeriodUsage   `json:"base"`
+	Current PeriodUsage   `json:"current"`
+	Deltas  []MetricDelta `json:"deltas"`
+	Models  []ModelShift  `json:"models"` // Largest cost change first
+}
+
+// PreviousRange returns the range of the same length ending right before r
+func PreviousRange(r CompareRange) CompareRange {
+	length := r.To.Sub(r.From)
+	return CompareRange{From: r.From.Add(-length - time.Nanosecond), To: r.From.Add(-time.Nanosecond)}
+}
+
+// ComparePeriods totals results within base and current and computes the deltas of each metric and model
+func ComparePeriods(results []models.AnalysisResult, base, current CompareRange) PeriodComparison {
+	comparison := PeriodComparison{Deltas: []MetricDelta{}, Models: []ModelShift{}}
+	var baseModels, currentModels []GroupResult
+	comparison.Base, baseModels = totalPeriod(results, base)
+	comparison.Current, currentModels = totalPeriod(results, current)
+
+	b, c := comparison.Base, comparison.Current
+	for _, metric := range []struct {
+		name          string
+		base, current float64
+	}{
+		{"cost", b.CostUSD, c.CostUSD},
+		{"total_tokens", float64(b.TotalTokens), float64(c.TotalTokens)},
+		{"input_tokens", float64(b.InputTokens), float64(c.InputTokens)},
+		{"output_tokens", float64(b.OutputTokens), float64(c.OutputTokens)},
+		{"cache_creation_tokens", float64(b.CacheCreationTokens), float64(c.CacheCreationTokens)},
+		{"cache_read_tokens", float64(b.CacheReadTokens), float64(c.CacheReadTokens)},
+		{"messages", float64(b.Messages), float64(c.Messages)},
+		{"sessions", float64(b.Sessions), float64(c.Sessions)},
+	} {
+		comparison.Deltas = append(comparison.Deltas, newMetricDelta(metric.name, metric.base, metric.current))
+	}
+
+	shifts := make(map[string]*ModelShift)
+	shift := func(model string) *ModelShift {
+		s, ok := shifts[model]
+		if !ok {
+			s = &ModelShift{Model: model}
+			shifts[model] = s
+		}
+		return s
+	}
+	for _, group := range baseModels {
+		s := shift(group.Result.GroupKey)
+		s.BaseCost = group.Result.CostUSD
+		s.BaseTokens = group.Result.TotalTokens
+		if b.CostUSD > 0 {
+			s.BaseShare = s.BaseCost / b.CostUSD
+		}
+	}
+	for _, group := range currentModels {
+		s := shift(group.Result.GroupKey)
+		s.CurrentCost = group.Result.CostUSD
+		s.CurrentTokens = group.Result.TotalTokens
+		if c.CostUSD > 0 {
+			s.CurrentShare = s.CurrentCost / c.CostUSD
+		}
+	}
+	for _, s := range shifts {
+		s.CostChange = s.CurrentCost - s.BaseCost
+		s.TokenChange = s.CurrentTokens - s.BaseTokens
+		s.ShareChange = s.CurrentShare - s.BaseShare
+		comparison.Models = append(comparison.Models, *s)
+	}
+	sort.Slice(comparison.Models, func(i, j int) bool {
+		ci, cj := math.Abs(comparison.Models[i].CostChange), math.Abs(comparison.Models[j].CostChange)
+		if ci != cj {
+			return ci > cj
+		}
+		return comparison.Models[i].Model < comparison.Models[j].Model
+	})
+	return comparison
+}
+
+// totalPeriod sums the results within r and aggregates them per model
+func totalPeriod(results []models.AnalysisResult, r CompareRange) (PeriodUsage, []GroupResult) {
+	usage := PeriodUsage{CompareRange: r}
+	sessions := make(map[string]bool)
+	byModel := make(map[string][]models.AnalysisResult)
+	for _, result := range results {
+		if !r.Contains(result.Timestamp) {
+			continue
+		}
+		usage.Messages += result.Count
+		usage.InputTokens += result.InputTokens
+		usage.OutputTokens += result.OutputTokens
+		usage.CacheCreationTokens += result.CacheCreationTokens
+		usage.CacheReadTokens += result.CacheReadTokens
+		usage.TotalTokens += result.TotalTokens
+		usage.CostUSD += result.CostUSD
+		if result.SessionID != "" {
+			sessions[result.SessionID] = true
+		}
+		byModel[result.Model] = append(byModel[result.Model], result)
+	}
+	usage.Sessions = len(sessions)
+	return usage, NewGroupAggregator(0).Aggregate(byModel)
+}
+
+// newMetricDelta computes the absolute and relative change of a metric
+func newMetricDelta(metric string, base, current float64) MetricDelta {
+	delta := MetricDelta{Metric: metric, Base: base, Current: current, Change: current - base}
+	if base != 0 {
+		pct := delta.Change / base * 100
+		delta.ChangePct = &pct
+	}
+	return delta
+}
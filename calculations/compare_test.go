@@ -0,0 +1,74 @@
+package calculations
+
+import (
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviousRange(t *testing.T) {
+	current := CompareRange{
+		From: time.Date(2025, 6, 9, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2025, 6, 16, 0, 0, 0, 0, time.UTC).Add(-time.Nanosecond),
+	}
+	base := PreviousRange(current)
+	assert.Equal(t, time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC), base.From)
+	assert.Equal(t, current.From.Add(-time.Nanosecond), base.To)
+}
+
+func TestComparePeriods(t *testing.T) {
+	day := func(d, hour int) time.Time { return time.Date(2025, 6, d, hour, 0, 0, 0, time.UTC) }
+	results := []models.AnalysisResult{
+		{Timestamp: day(2, 10), Model: "claude-sonnet-4", SessionID: "a", InputTokens: 100, OutputTokens: 100, TotalTokens: 200, CostUSD: 2, Count: 1},
+		{Timestamp: day(3, 10), Model: "claude-sonnet-4", SessionID: "b", InputTokens: 100, OutputTokens: 100, TotalTokens: 200, CostUSD: 2, Count: 1},
+		{Timestamp: day(9, 10), Model: "claude-sonnet-4", SessionID: "c", InputTokens: 100, OutputTokens: 100, TotalTokens: 200, CostUSD: 1, Count: 1},
+		{Timestamp: day(10, 10), Model: "claude-opus-4", SessionID: "c", InputTokens: 300, OutputTokens: 300, CacheReadTokens: 400, TotalTokens: 1000, CostUSD: 9, Count: 2},
+		{Timestamp: day(20, 10), Model: "claude-opus-4", SessionID: "d", TotalTokens: 5000, CostUSD: 50, Count: 1},
+	}
+	current := CompareRange{From: day(9, 0), To: day(16, 0).Add(-time.Nanosecond)}
+	comparison := ComparePeriods(results, PreviousRange(current), current)
+
+	assert.Equal(t, 2, comparison.Base.Messages)
+	assert.Equal(t, 2, comparison.Base.Sessions)
+	assert.InDelta(t, 4, comparison.Base.CostUSD, 0.001)
+	assert.Equal(t, 3, comparison.Current.Messages)
+	assert.Equal(t, 1, comparison.Current.Sessions)
+	assert.Equal(t, 1200, comparison.Current.TotalTokens)
+	assert.InDelta(t, 10, comparison.Current.CostUSD, 0.001)
+
+	deltas := make(map[string]MetricDelta)
+	for _, delta := range comparison.Deltas {
+		deltas[delta.Metric] = delta
+	}
+	require.Contains(t, deltas, "cost")
+	assert.InDelta(t, 6, deltas["cost"].Change, 0.001)
+	require.NotNil(t, deltas["cost"].ChangePct)
+	assert.InDelta(t, 150, *deltas["cost"].ChangePct, 0.001)
+	assert.InDelta(t, -1, deltas["sessions"].Change, 0.001)
+	assert.Nil(t, deltas["cache_read_tokens"].ChangePct, "no relative change from a zero base")
+
+	require.Len(t, comparison.Models, 2)
+	opus, sonnet := comparison.Models[0], comparison.Models[1]
+	assert.Equal(t, "claude-opus-4", opus.Model, "largest cost change first")
+	assert.InDelta(t, 9, opus.CostChange, 0.001)
+	assert.Equal(t, 1000, opus.TokenChange)
+	assert.InDelta(t, 0.9, opus.ShareChange, 0.001)
+	assert.Equal(t, "claude-sonnet-4", sonnet.Model)
+	assert.InDelta(t, -3, sonnet.CostChange, 0.001)
+	assert.InDelta(t, 1, sonnet.BaseShare, 0.001)
+	assert.InDelta(t, 0.1, sonnet.CurrentShare, 0.001)
+}
+
+func TestComparePeriods_Empty(t *testing.T) {
+	current := CompareRange{From: time.Date(2025, 6, 9, 0, 0, 0, 0, time.UTC), To: time.Date(2025, 6, 15, 23, 0, 0, 0, time.UTC)}
+	comparison := ComparePeriods(nil, PreviousRange(current), current)
+	assert.Len(t, comparison.Deltas, 8)
+	assert.Empty(t, comparison.Models)
+	for _, delta := range comparison.Deltas {
+		assert.Zero(t, delta.Change)
+		assert.Nil(t, delta.ChangePct)
+	}
+}
@@ -101,7 +101,8 @@ func (c *CostCalculator) Calculate(entry models.UsageEntry) (CostResult, error)
 	// Calculate costs (pricing is per million tokens)
 	result.InputCost = c.calculateTokenCost(entry.InputTokens, pricing.Input)
 	result.OutputCost = c.calculateTokenCost(entry.OutputTokens, pricing.Output)
-	result.CacheCreationCost = c.calculateTokenCost(entry.CacheCreationTokens, pricing.CacheCreation)
+	result.CacheCreationCost = c.calculateTokenCost(entry.CacheCreation5mTokens(), pricing.CacheCreation) +
+		c.calculateTokenCost(entry.CacheCreation1hTokens, pricing.CacheCreation1hPrice())
 	result.CacheReadCost = c.calculateTokenCost(entry.CacheReadTokens, pricing.CacheRead)
 
 	result.TotalCost = result.InputCost + result.OutputCost +
@@ -0,0 +1,107 @@
+package calculations
+
+import (
+	"math"
+	"sort"
+
+	"github.com/penwyp/claudecat/models"
+)
+
+// DefaultCostAuditTolerance is the default relative tolerance (1%) between logged and calculated cost
+const DefaultCostAuditTolerance = 0.01
+
+// costAuditEpsilon ignores differences below a hundredth of a cent
+const costAuditEpsilon = 0.0001
+
+// CostAuditor compares costs recorded in the logs with costs calculated from token counts
+type CostAuditor struct {
+	tolerance float64
+	models    map[string]*ModelCostAudit
+}
+
+// ModelCostAudit contains cost discrepancy statistics for a single model
+type ModelCostAudit struct {
+	Model              string  `json:"model"`
+	Entries            int     `json:"entries"`
+	EntriesWithCost    int     `json:"entries_with_cost"`
+	Discrepancies      int     `json:"discrepancies"`
+	CachedCost         float64 `json:"cached_cost"`
+	CalculatedCost     float64 `json:"calculated_cost"`
+	DiscrepancyCost    float64 `json:"discrepancy_cost"`
+	MaxAbsoluteDiff    float64 `json:"max_absolute_diff"`
+	MaxRelativeDiffPct float64 `json:"max_relative_diff_pct"`
+}
+
+// NewCostAuditor creates a new cost auditor; tolerance is a relative fraction (0.01 = 1%)
+func NewCostAuditor(tolerance float64) *CostAuditor {
+	if tolerance < 0 {
+		tolerance = DefaultCostAuditTolerance
+	}
+	return &CostAuditor{
+		tolerance: tolerance,
+		models:    make(map[string]*ModelCostAudit),
+	}
+}
+
+// Record compares an entry's logged cost with its calculated CostUSD
+func (ca *CostAuditor) Record(entry models.UsageEntry) {
+	stat, exists := ca.models[entry.Model]
+	if !exists {
+		stat = &ModelCostAudit{Model: entry.Model}
+		ca.models[entry.Model] = stat
+	}
+	stat.Entries++
+
+	if entry.CachedCostUSD <= 0 {
+		return
+	}
+
+	stat.EntriesWithCost++
+	stat.CachedCost += entry.CachedCostUSD
+	stat.CalculatedCost += entry.CostUSD
+
+	diff := math.Abs(entry.CachedCostUSD - entry.CostUSD)
+	relative := diff / math.Max(entry.CachedCostUSD, entry.CostUSD)
+
+	if diff > stat.MaxAbsoluteDiff {
+		stat.MaxAbsoluteDiff = diff
+	}
+	if relative*100 > stat.MaxRelativeDiffPct {
+		stat.MaxRelativeDiffPct = relative * 100
+	}
+
+	if diff > costAuditEpsilon && relative > ca.tolerance {
+		stat.Discrepancies++
+		stat.DiscrepancyCost += entry.CachedCostUSD - entry.CostUSD
+	}
+}
+
+// Report returns per-model audit results sorted by model name
+func (ca *CostAuditor) Report() []ModelCostAudit {
+	report := make([]ModelCostAudit, 0, len(ca.models))
+	for _, stat := range ca.models {
+		report = append(report, *stat)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		return report[i].Model < report[j].Model
+	})
+	return report
+}
+
+// HasDiscrepancies reports whether any entry exceeded the tolerance
+func (ca *CostAuditor) HasDiscrepancies() bool {
+	for _, stat := range ca.models {
+		if stat.Discrepancies > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// DriftPercent returns the aggregate drift of logged cost relative to calculated cost
+func (m ModelCostAudit) DriftPercent() float64 {
+	if m.CalculatedCost == 0 {
+		return 0
+	}
+	return (m.CachedCost - m.CalculatedCost) / m.CalculatedCost * 100
+}
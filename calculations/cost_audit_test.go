@@ -0,0 +1,47 @@
+package calculations
+
+import (
+	"testing"
+
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCostAuditor_Record(t *testing.T) {
+	auditor := NewCostAuditor(0.01)
+
+	// Within tolerance
+	auditor.Record(models.UsageEntry{Model: "claude-3-5-sonnet", CostUSD: 1.000, CachedCostUSD: 1.005})
+	// Outside tolerance
+	auditor.Record(models.UsageEntry{Model: "claude-3-5-sonnet", CostUSD: 1.000, CachedCostUSD: 1.200})
+	// No logged cost
+	auditor.Record(models.UsageEntry{Model: "claude-3-5-sonnet", CostUSD: 0.5})
+	// Tiny absolute difference above relative tolerance is ignored
+	auditor.Record(models.UsageEntry{Model: "claude-3-haiku", CostUSD: 0.00001, CachedCostUSD: 0.00002})
+
+	report := auditor.Report()
+	require.Len(t, report, 2)
+	assert.Equal(t, "claude-3-5-sonnet", report[0].Model)
+
+	sonnet := report[0]
+	assert.Equal(t, 3, sonnet.Entries)
+	assert.Equal(t, 2, sonnet.EntriesWithCost)
+	assert.Equal(t, 1, sonnet.Discrepancies)
+	assert.InDelta(t, 2.205, sonnet.CachedCost, 1e-9)
+	assert.InDelta(t, 2.0, sonnet.CalculatedCost, 1e-9)
+	assert.InDelta(t, 0.2, sonnet.DiscrepancyCost, 1e-9)
+	assert.InDelta(t, 0.2, sonnet.MaxAbsoluteDiff, 1e-9)
+	assert.InDelta(t, 10.25, sonnet.DriftPercent(), 1e-9)
+
+	assert.Equal(t, 0, report[1].Discrepancies)
+	assert.True(t, auditor.HasDiscrepancies())
+}
+
+func TestCostAuditor_NoDiscrepancies(t *testing.T) {
+	auditor := NewCostAuditor(DefaultCostAuditTolerance)
+	auditor.Record(models.UsageEntry{Model: "claude-3-opus", CostUSD: 2.0, CachedCostUSD: 2.0})
+
+	assert.False(t, auditor.HasDiscrepancies())
+	assert.Equal(t, 0.0, auditor.Report()[0].DriftPercent())
+}
@@ -0,0 +1,124 @@
+package calculations
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DefaultCostBucketBounds are the upper bounds in USD of the per-message cost buckets;
+// a final bucket collects everything at or above the last bound
+var DefaultCostBucketBounds = []float64{0.01, 0.05, 0.10, 0.50, 1, 5}
+
+// CostBucket counts the messages whose cost falls in [Min, Max); Max is 0 for the last bucket
+type CostBucket struct {
+	Label    string  `json:"label"`
+	Min      float64 `json:"min"`
+	Max      float64 `json:"max,omitempty"`
+	Messages int     `json:"messages"`
+	Cost     float64 `json:"cost"`
+}
+
+// CostDistribution is the per-message cost histogram of one model, or of all models
+type CostDistribution struct {
+	Model    string       `json:"model"`
+	Messages int          `json:"messages"`
+	Cost     float64      `json:"cost"`
+	Buckets  []CostBucket `json:"buckets"`
+}
+
+// MessageShare returns the fraction of messages in bucket i
+func (d CostDistribution) MessageShare(i int) float64 {
+	if d.Messages == 0 {
+		return 0
+	}
+	return float64(d.Buckets[i].Messages) / float64(d.Messages)
+}
+
+// CostShare returns the fraction of cost contributed by bucket i
+func (d CostDistribution) CostShare(i int) float64 {
+	if d.Cost <= 0 {
+		return 0
+	}
+	return d.Buckets[i].Cost / d.Cost
+}
+
+// CostHistogram buckets messages by cost, overall and per model
+type CostHistogram struct {
+	bounds  []float64
+	total   *CostDistribution
+	byModel map[string]*CostDistribution
+}
+
+// NewCostHistogram creates a histogram with the given ascending bucket bounds, or the default bounds if nil
+func NewCostHistogram(bounds []float64) *CostHistogram {
+	if len(bounds) == 0 {
+		bounds = DefaultCostBucketBounds
+	}
+	h := &CostHistogram{
+		bounds:  bounds,
+		byModel: make(map[string]*CostDistribution),
+	}
+	h.total = h.newDistribution("all")
+	return h
+}
+
+// Add records one message
+func (h *CostHistogram) Add(model string, cost float64) {
+	dist, ok := h.byModel[model]
+	if !ok {
+		dist = h.newDistribution(model)
+		h.byModel[model] = dist
+	}
+	i := h.bucketIndex(cost)
+	for _, d := range []*CostDistribution{h.total, dist} {
+		d.Messages++
+		d.Cost += cost
+		d.Buckets[i].Messages++
+		d.Buckets[i].Cost += cost
+	}
+}
+
+// Total returns the distribution over all models
+func (h *CostHistogram) Total() CostDistribution {
+	return *h.total
+}
+
+// ByModel returns the per-model distributions, most expensive model first
+func (h *CostHistogram) ByModel() []CostDistribution {
+	result := make([]CostDistribution, 0, len(h.byModel))
+	for _, dist := range h.byModel {
+		result = append(result, *dist)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Cost != result[j].Cost {
+			return result[i].Cost > result[j].Cost
+		}
+		return result[i].Model < result[j].Model
+	})
+	return result
+}
+
+// bucketIndex returns the bucket a cost falls into
+func (h *CostHistogram) bucketIndex(cost float64) int {
+	return sort.Search(len(h.bounds), func(i int) bool { return cost < h.bounds[i] })
+}
+
+// newDistribution creates an empty distribution with labeled buckets
+func (h *CostHistogram) newDistribution(model string) *CostDistribution {
+	buckets := make([]CostBucket, len(h.bounds)+1)
+	for i := range buckets {
+		switch {
+		case i == 0:
+			buckets[i] = CostBucket{Label: fmt.Sprintf("<$%.2f", h.bounds[0]), Max: h.bounds[0]}
+		case i == len(h.bounds):
+			buckets[i] = CostBucket{Label: fmt.Sprintf("$%.2f+", h.bounds[i-1]), Min: h.bounds[i-1]}
+		default:
+			buckets[i] = CostBucket{
+				Label: fmt.Sprintf("$%.2f-%.2f", h.bounds[i-1], h.bounds[i]),
+				Min:   h.bounds[i-1],
+				Max:   h.bounds[i],
+			}
+		}
+	}
+	return &CostDistribution{Model: model, Buckets: buckets}
+}
@@ -0,0 +1,48 @@
+package calculations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCostHistogram_Buckets(t *testing.T) {
+	h := NewCostHistogram(nil)
+	for i := 0; i < 8; i++ {
+		h.Add("sonnet", 0.005)
+	}
+	h.Add("sonnet", 0.01) // Bounds are exclusive upper limits
+	h.Add("opus", 0.75)
+	h.Add("opus", 12)
+
+	total := h.Total()
+	assert.Equal(t, 11, total.Messages)
+	require.Len(t, total.Buckets, len(DefaultCostBucketBounds)+1)
+	assert.Equal(t, "<$0.01", total.Buckets[0].Label)
+	assert.Equal(t, 8, total.Buckets[0].Messages)
+	assert.Equal(t, 1, total.Buckets[1].Messages)
+	assert.Equal(t, 1, total.Buckets[4].Messages)
+	assert.Equal(t, "$5.00+", total.Buckets[6].Label)
+	assert.Equal(t, 1, total.Buckets[6].Messages)
+
+	// Most messages are cheap but the most expensive bucket dominates cost
+	assert.InDelta(t, 8.0/11, total.MessageShare(0), 0.0001)
+	assert.Greater(t, total.CostShare(6), 0.9)
+
+	byModel := h.ByModel()
+	require.Len(t, byModel, 2)
+	assert.Equal(t, "opus", byModel[0].Model)
+	assert.Equal(t, 2, byModel[0].Messages)
+	assert.Equal(t, 9, byModel[1].Messages)
+}
+
+func TestCostHistogram_CustomBoundsAndEmpty(t *testing.T) {
+	h := NewCostHistogram([]float64{1})
+	total := h.Total()
+	require.Len(t, total.Buckets, 2)
+	assert.Equal(t, "$1.00+", total.Buckets[1].Label)
+	assert.Zero(t, total.MessageShare(0))
+	assert.Zero(t, total.CostShare(1))
+	assert.Empty(t, h.ByModel())
+}
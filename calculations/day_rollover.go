@@ -0,0 +1,77 @@
+package calculations
+
+import (
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+)
+
+// DayClock tracks the current local day of a long-running monitor and reports when midnight passes
+type DayClock struct {
+	loc *time.Location
+	day time.Time
+}
+
+// DayTotals is the usage recorded on one local day
+type DayTotals struct {
+	Date    time.Time `json:"date"`
+	Entries int       `json:"entries"`
+	Tokens  int       `json:"tokens"`
+	Cost    float64   `json:"cost"`
+}
+
+// NewDayClock creates a clock starting on the day containing now in loc
+func NewDayClock(loc *time.Location, now time.Time) *DayClock {
+	if loc == nil {
+		loc = time.Local
+	}
+	return &DayClock{loc: loc, day: StartOfDay(now, loc)}
+}
+
+// StartOfDay returns midnight of the day containing t in loc
+func StartOfDay(t time.Time, loc *time.Location) time.Time {
+	local := t.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+}
+
+// Day returns midnight of the current day
+func (c *DayClock) Day() time.Time {
+	return c.day
+}
+
+// NextRollover returns the next local midnight, accounting for days shortened or lengthened by DST
+func (c *DayClock) NextRollover() time.Time {
+	return c.day.AddDate(0, 0, 1)
+}
+
+// Advance moves the clock to the day containing now and reports the previous day when it changed.
+// A clock that missed several midnights, e.g. across a suspend, rolls over once to the current day.
+func (c *DayClock) Advance(now time.Time) (time.Time, bool) {
+	day := StartOfDay(now, c.loc)
+	if !day.After(c.day) {
+		return time.Time{}, false
+	}
+	previous := c.day
+	c.day = day
+	return previous, true
+}
+
+// SumDay totals the entries of blocks recorded on the local day starting at day
+func SumDay(blocks []models.SessionBlock, day time.Time) DayTotals {
+	totals := DayTotals{Date: day}
+	end := day.AddDate(0, 0, 1)
+	for _, block := range blocks {
+		if block.IsGap || !block.StartTime.Before(end) {
+			continue
+		}
+		for _, entry := range block.Entries {
+			if entry.Timestamp.Before(day) || !entry.Timestamp.Before(end) {
+				continue
+			}
+			totals.Entries++
+			totals.Tokens += entry.CalculateTotalTokens()
+			totals.Cost += entry.CostUSD
+		}
+	}
+	return totals
+}
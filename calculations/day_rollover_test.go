@@ -0,0 +1,79 @@
+package calculations
+
+import (
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDayClock_Advance(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*3600)
+	start := time.Date(2025, 6, 1, 23, 30, 0, 0, loc)
+	clock := NewDayClock(loc, start)
+	assert.Equal(t, time.Date(2025, 6, 1, 0, 0, 0, 0, loc), clock.Day())
+	assert.Equal(t, time.Date(2025, 6, 2, 0, 0, 0, 0, loc), clock.NextRollover())
+
+	_, rolled := clock.Advance(start.Add(10 * time.Minute))
+	assert.False(t, rolled)
+
+	previous, rolled := clock.Advance(start.Add(45 * time.Minute))
+	require.True(t, rolled)
+	assert.Equal(t, time.Date(2025, 6, 1, 0, 0, 0, 0, loc), previous)
+	assert.Equal(t, time.Date(2025, 6, 2, 0, 0, 0, 0, loc), clock.Day())
+
+	// Midnight is local: 23:00 UTC is already the next day in loc
+	_, rolled = clock.Advance(time.Date(2025, 6, 2, 21, 59, 0, 0, time.UTC))
+	assert.False(t, rolled)
+	_, rolled = clock.Advance(time.Date(2025, 6, 2, 22, 0, 0, 0, time.UTC))
+	assert.True(t, rolled)
+
+	// Several missed midnights roll over once, and time going backwards never rolls back
+	previous, rolled = clock.Advance(time.Date(2025, 6, 6, 12, 0, 0, 0, loc))
+	require.True(t, rolled)
+	assert.Equal(t, 3, previous.Day())
+	assert.Equal(t, 6, clock.Day().Day())
+	_, rolled = clock.Advance(time.Date(2025, 6, 5, 12, 0, 0, 0, loc))
+	assert.False(t, rolled)
+	assert.Equal(t, 6, clock.Day().Day())
+}
+
+func TestDayClock_DST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("timezone data unavailable")
+	}
+	clock := NewDayClock(loc, time.Date(2025, 3, 9, 12, 0, 0, 0, loc))
+	next := clock.NextRollover()
+	assert.Equal(t, time.Date(2025, 3, 10, 0, 0, 0, 0, loc), next)
+	assert.Equal(t, 23*time.Hour, next.Sub(clock.Day()))
+}
+
+func TestSumDay(t *testing.T) {
+	day := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+	blocks := []models.SessionBlock{
+		{
+			StartTime: day.Add(-2 * time.Hour),
+			Entries: []models.UsageEntry{
+				{Timestamp: day.Add(-time.Hour), InputTokens: 100, CostUSD: 1},
+				{Timestamp: day.Add(time.Hour), InputTokens: 200, OutputTokens: 50, CostUSD: 2},
+			},
+		},
+		{IsGap: true, StartTime: day.Add(2 * time.Hour)},
+		{
+			StartTime: day.Add(20 * time.Hour),
+			Entries: []models.UsageEntry{
+				{Timestamp: day.Add(23 * time.Hour), InputTokens: 10, CostUSD: 0.5},
+				{Timestamp: day.Add(25 * time.Hour), InputTokens: 999, CostUSD: 9},
+			},
+		},
+	}
+
+	totals := SumDay(blocks, day)
+	assert.Equal(t, day, totals.Date)
+	assert.Equal(t, 2, totals.Entries)
+	assert.Equal(t, 260, totals.Tokens)
+	assert.InDelta(t, 2.5, totals.Cost, 0.0001)
+}
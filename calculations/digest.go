@@ -0,0 +1,319 @@
+package calculations
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+)
+
+// Digest periods
+const (
+	DigestPeriodDay   = "day"
+	DigestPeriodWeek  = "week"
+	DigestPeriodMonth = "month"
+)
+
+// DigestBaselineDays is the number of days before a digest period used as the anomaly baseline
+const DigestBaselineDays = 28
+
+// DefaultNotableSessions is the number of most expensive sessions listed in a digest
+const DefaultNotableSessions = 3
+
+// digestAnomalySigma is how many standard deviations above the baseline a day must be to be flagged
+const digestAnomalySigma = 2.0
+
+// digestMinBaselineDays is the number of active baseline days required before flagging anomalies
+const digestMinBaselineDays = 3
+
+// DigestShare is the largest contributor to a digest period's cost
+type DigestShare struct {
+	Name  string  `json:"name"`
+	Cost  float64 `json:"cost"`
+	Share float64 `json:"share"` // Fraction of the period's total cost
+}
+
+// DigestSession summarizes one notable session
+type DigestSession struct {
+	StartTime time.Time `json:"start_time"`
+	Cost      float64   `json:"cost"`
+	Tokens    int       `json:"tokens"`
+	Projects  []string  `json:"projects"`
+	Models    []string  `json:"models"`
+	LimitHit  bool      `json:"limit_hit"`
+	Tags      []string  `json:"tags,omitempty"` // Labels of the session, from claudecat tag
+}
+
+// DigestAnomaly is a day whose cost was well above the trailing baseline
+type DigestAnomaly struct {
+	Date     time.Time `json:"date"`
+	Cost     float64   `json:"cost"`
+	Baseline float64   `json:"baseline"` // Mean daily cost over the baseline days
+	Ratio    float64   `json:"ratio"`    // Cost relative to the baseline
+}
+
+// DigestBudget relates the period's usage to the subscription plan
+type DigestBudget struct {
+	Plan             string  `json:"plan"`
+	MonthlyPrice     float64 `json:"monthly_price"`
+	ProratedPrice    float64 `json:"prorated_price"` // Share of the monthly price covering the period
+	SessionLimit     float64 `json:"session_cost_limit"`
+	PeakSessionCost  float64 `json:"peak_session_cost"`
+	SessionsOverWarn int     `json:"sessions_over_warn"`
+	LimitHits        int     `json:"limit_hits"`
+	WarnThreshold    float64 `json:"warn_threshold"`
+}
+
+// Digest is a concise summary of one period of usage
+type Digest struct {
+	Period          string          `json:"period"`
+	Start           time.Time       `json:"start"`
+	End             time.Time       `json:"end"`
+	TotalCost       float64         `json:"total_cost"`
+	TotalTokens     int             `json:"total_tokens"`
+	Sessions        int             `json:"sessions"`
+	ActiveDays      int             `json:"active_days"`
+	PreviousCost    float64         `json:"previous_cost"`
+	CostChange      float64         `json:"cost_change"` // Percent vs the previous period; 0 when there is none
+	HasPrevious     bool            `json:"has_previous"`
+	BusiestDay      *time.Time      `json:"busiest_day,omitempty"`
+	BusiestDayCost  float64         `json:"busiest_day_cost"`
+	TopModel        *DigestShare    `json:"top_model,omitempty"`
+	TopProject      *DigestShare    `json:"top_project,omitempty"`
+	NotableSessions []DigestSession `json:"notable_sessions"`
+	Anomalies       []DigestAnomaly `json:"anomalies"`
+	Budget          DigestBudget    `json:"budget"`
+}
+
+// DigestBuilder builds usage digests from session blocks
+type DigestBuilder struct {
+	timezone        *time.Location
+	limits          map[string]models.PlanLimits
+	notableSessions int
+}
+
+// NewDigestBuilder creates a digest builder; limits override the built-in per-plan limits and may be nil
+func NewDigestBuilder(timezone *time.Location, limits map[string]models.PlanLimits) *DigestBuilder {
+	if timezone == nil {
+		timezone = time.Local
+	}
+	return &DigestBuilder{
+		timezone:        timezone,
+		limits:          limits,
+		notableSessions: DefaultNotableSessions,
+	}
+}
+
+// DigestPeriodDays returns the number of days covered by a digest period
+func DigestPeriodDays(period string) (int, error) {
+	switch strings.ToLower(period) {
+	case DigestPeriodDay:
+		return 1, nil
+	case DigestPeriodWeek:
+		return 7, nil
+	case DigestPeriodMonth:
+		return 30, nil
+	default:
+		return 0, fmt.Errorf("invalid digest period: %s (valid: day, week, month)", period)
+	}
+}
+
+// Build summarizes the sessions of the period ending at now. The period covers whole calendar days
+// including today; the previous period of the same length and the baseline days before it are used for
+// comparison and anomaly detection. warnThreshold is the fraction of the session cost limit counted as a near miss.
+func (b *DigestBuilder) Build(period, plan string, warnThreshold float64, blocks []models.SessionBlock, now time.Time) (Digest, error) {
+	days, err := DigestPeriodDays(period)
+	if err != nil {
+		return Digest{}, err
+	}
+
+	local := now.In(b.timezone)
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, b.timezone)
+	start := today.AddDate(0, 0, -(days - 1))
+	previousStart := start.AddDate(0, 0, -days)
+	baselineStart := start.AddDate(0, 0, -DigestBaselineDays)
+
+	digest := Digest{
+		Period:          strings.ToLower(period),
+		Start:           start,
+		End:             now,
+		NotableSessions: []DigestSession{},
+		Anomalies:       []DigestAnomaly{},
+	}
+
+	limits := b.planLimits(plan)
+	digest.Budget = DigestBudget{
+		Plan:          plan,
+		MonthlyPrice:  models.GetPlanMonthlyPrice(plan),
+		SessionLimit:  limits.CostLimit,
+		WarnThreshold: warnThreshold,
+	}
+	digest.Budget.ProratedPrice = digest.Budget.MonthlyPrice * float64(days) / 30
+
+	daily := make(map[time.Time]float64)
+	baselineDaily := make(map[time.Time]float64)
+	modelCosts := make(map[string]float64)
+	projectCosts := make(map[string]float64)
+	var sessions []DigestSession
+
+	for _, block := range blocks {
+		if block.IsGap {
+			continue
+		}
+		inPeriod := !block.StartTime.Before(start) && !block.StartTime.After(now)
+
+		for _, entry := range block.Entries {
+			if entry.Timestamp.After(now) {
+				continue
+			}
+			day := b.dayOf(entry.Timestamp)
+			switch {
+			case !entry.Timestamp.Before(start):
+				daily[day] += entry.CostUSD
+				digest.TotalCost += entry.CostUSD
+				digest.TotalTokens += entry.TotalTokens
+				modelCosts[entry.Model] += entry.CostUSD
+				if entry.Project != "" {
+					projectCosts[entry.Project] += entry.CostUSD
+				}
+			case !entry.Timestamp.Before(previousStart):
+				digest.PreviousCost += entry.CostUSD
+				digest.HasPrevious = true
+				baselineDaily[day] += entry.CostUSD
+			case !entry.Timestamp.Before(baselineStart):
+				baselineDaily[day] += entry.CostUSD
+			}
+		}
+
+		if !inPeriod {
+			continue
+		}
+		sessions = append(sessions, summarizeSession(block))
+		digest.Budget.PeakSessionCost = math.Max(digest.Budget.PeakSessionCost, block.CostUSD)
+		if len(block.LimitMessages) > 0 {
+			digest.Budget.LimitHits++
+		}
+		if limits.CostLimit > 0 && warnThreshold > 0 && block.CostUSD >= limits.CostLimit*warnThreshold {
+			digest.Budget.SessionsOverWarn++
+		}
+	}
+
+	digest.Sessions = len(sessions)
+	digest.ActiveDays = len(daily)
+	if digest.HasPrevious && digest.PreviousCost > 0 {
+		digest.CostChange = (digest.TotalCost - digest.PreviousCost) / digest.PreviousCost * 100
+	}
+
+	for day, cost := range daily {
+		if digest.BusiestDay == nil || cost > digest.BusiestDayCost || (cost == digest.BusiestDayCost && day.Before(*digest.BusiestDay)) {
+			d := day
+			digest.BusiestDay = &d
+			digest.BusiestDayCost = cost
+		}
+	}
+
+	digest.TopModel = topShare(modelCosts, digest.TotalCost)
+	digest.TopProject = topShare(projectCosts, digest.TotalCost)
+
+	sort.SliceStable(sessions, func(i, j int) bool {
+		if sessions[i].Cost != sessions[j].Cost {
+			return sessions[i].Cost > sessions[j].Cost
+		}
+		return sessions[i].StartTime.Before(sessions[j].StartTime)
+	})
+	if len(sessions) > b.notableSessions {
+		sessions = sessions[:b.notableSessions]
+	}
+	digest.NotableSessions = append(digest.NotableSessions, sessions...)
+
+	digest.Anomalies = append(digest.Anomalies, detectDailyAnomalies(daily, baselineDaily)...)
+	return digest, nil
+}
+
+// summarizeSession extracts the digest fields of a session block
+func summarizeSession(block models.SessionBlock) DigestSession {
+	session := DigestSession{
+		StartTime: block.StartTime,
+		Cost:      block.CostUSD,
+		Tokens:    block.TokenCounts.TotalTokens(),
+		Models:    append([]string(nil), block.Models...),
+		Projects:  []string{},
+		LimitHit:  len(block.LimitMessages) > 0,
+	}
+	seen := make(map[string]bool)
+	for _, entry := range block.Entries {
+		if entry.Project != "" && !seen[entry.Project] {
+			seen[entry.Project] = true
+			session.Projects = append(session.Projects, entry.Project)
+		}
+	}
+	sort.Strings(session.Projects)
+	return session
+}
+
+// planLimits returns the session limits for a plan, preferring overrides
+func (b *DigestBuilder) planLimits(plan string) models.PlanLimits {
+	if limits, ok := b.limits[plan]; ok {
+		return limits
+	}
+	return models.GetPlanLimits(plan)
+}
+
+// dayOf returns local midnight of the day containing t
+func (b *DigestBuilder) dayOf(t time.Time) time.Time {
+	local := t.In(b.timezone)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, b.timezone)
+}
+
+// topShare returns the largest cost in costs with its share of total, or nil if there is none
+func topShare(costs map[string]float64, total float64) *DigestShare {
+	var top *DigestShare
+	for name, cost := range costs {
+		if top == nil || cost > top.Cost || (cost == top.Cost && name < top.Name) {
+			top = &DigestShare{Name: name, Cost: cost}
+		}
+	}
+	if top != nil && total > 0 {
+		top.Share = top.Cost / total
+	}
+	return top
+}
+
+// detectDailyAnomalies flags days whose cost exceeds the baseline mean by more than
+// digestAnomalySigma standard deviations, computed over the active baseline days
+func detectDailyAnomalies(daily, baseline map[time.Time]float64) []DigestAnomaly {
+	if len(baseline) < digestMinBaselineDays {
+		return nil
+	}
+
+	mean := 0.0
+	for _, cost := range baseline {
+		mean += cost
+	}
+	mean /= float64(len(baseline))
+
+	variance := 0.0
+	for _, cost := range baseline {
+		variance += (cost - mean) * (cost - mean)
+	}
+	stddev := math.Sqrt(variance / float64(len(baseline)))
+	threshold := mean + digestAnomalySigma*stddev
+
+	var anomalies []DigestAnomaly
+	for day, cost := range daily {
+		if mean <= 0 || cost <= threshold {
+			continue
+		}
+		anomalies = append(anomalies, DigestAnomaly{
+			Date:     day,
+			Cost:     cost,
+			Baseline: mean,
+			Ratio:    cost / mean,
+		})
+	}
+	sort.Slice(anomalies, func(i, j int) bool { return anomalies[i].Date.Before(anomalies[j].Date) })
+	return anomalies
+}
@@ -0,0 +1,96 @@
+package calculations
+
+import (
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func digestBlock(start time.Time, project string, cost float64) models.SessionBlock {
+	entry := models.UsageEntry{
+		Timestamp:   start.Add(time.Minute),
+		Model:       models.ModelSonnet,
+		TotalTokens: int(cost * 1000),
+		CostUSD:     cost,
+		Project:     project,
+	}
+	return models.SessionBlock{
+		StartTime:   start,
+		EndTime:     start.Add(models.SessionDuration),
+		Entries:     []models.UsageEntry{entry},
+		TokenCounts: models.TokenCounts{InputTokens: entry.TotalTokens},
+		Models:      []string{entry.Model},
+		CostUSD:     cost,
+	}
+}
+
+func TestDigestBuilder_Week(t *testing.T) {
+	now := time.Date(2025, 3, 31, 18, 0, 0, 0, time.UTC)
+	day := func(offset int) time.Time { return time.Date(2025, 3, 31+offset, 10, 0, 0, 0, time.UTC) }
+
+	var blocks []models.SessionBlock
+	// Steady baseline of $2/day over the four weeks before the previous week
+	for i := -34; i <= -14; i++ {
+		blocks = append(blocks, digestBlock(day(i), "api", 2))
+	}
+	// Previous week
+	blocks = append(blocks, digestBlock(day(-10), "api", 5))
+	// Current week: one spike day and a session that hit a limit
+	spike := digestBlock(day(-2), "webapp", 16)
+	spike.LimitMessages = []models.LimitMessage{{Type: "general_limit"}}
+	blocks = append(blocks,
+		digestBlock(day(-6), "api", 1),
+		digestBlock(day(-4), "api", 2),
+		spike,
+		digestBlock(day(0), "webapp", 1),
+		models.SessionBlock{StartTime: day(-1), CostUSD: 50, IsGap: true},
+	)
+
+	digest, err := NewDigestBuilder(time.UTC, nil).Build(DigestPeriodWeek, models.PlanPro, 0.8, blocks, now)
+	require.NoError(t, err)
+
+	assert.Equal(t, time.Date(2025, 3, 25, 0, 0, 0, 0, time.UTC), digest.Start)
+	assert.InDelta(t, 20.0, digest.TotalCost, 0.001)
+	assert.Equal(t, 4, digest.Sessions)
+	assert.Equal(t, 4, digest.ActiveDays)
+	assert.True(t, digest.HasPrevious)
+	assert.InDelta(t, 300.0, digest.CostChange, 0.001)
+
+	require.NotNil(t, digest.BusiestDay)
+	assert.Equal(t, time.Date(2025, 3, 29, 0, 0, 0, 0, time.UTC), *digest.BusiestDay)
+	require.NotNil(t, digest.TopProject)
+	assert.Equal(t, "webapp", digest.TopProject.Name)
+	assert.InDelta(t, 0.85, digest.TopProject.Share, 0.001)
+
+	require.Len(t, digest.NotableSessions, DefaultNotableSessions)
+	assert.InDelta(t, 16.0, digest.NotableSessions[0].Cost, 0.001)
+	assert.True(t, digest.NotableSessions[0].LimitHit)
+	assert.Equal(t, []string{"webapp"}, digest.NotableSessions[0].Projects)
+
+	require.Len(t, digest.Anomalies, 1)
+	assert.Equal(t, time.Date(2025, 3, 29, 0, 0, 0, 0, time.UTC), digest.Anomalies[0].Date)
+
+	assert.Equal(t, 1, digest.Budget.LimitHits)
+	assert.Equal(t, 1, digest.Budget.SessionsOverWarn)
+	assert.InDelta(t, 16.0, digest.Budget.PeakSessionCost, 0.001)
+	assert.InDelta(t, 20.0*7/30, digest.Budget.ProratedPrice, 0.001)
+}
+
+func TestDigestBuilder_EmptyAndInvalidPeriod(t *testing.T) {
+	now := time.Date(2025, 3, 31, 18, 0, 0, 0, time.UTC)
+	builder := NewDigestBuilder(time.UTC, nil)
+
+	digest, err := builder.Build(DigestPeriodDay, models.PlanMax5, 0.8, nil, now)
+	require.NoError(t, err)
+	assert.Zero(t, digest.Sessions)
+	assert.False(t, digest.HasPrevious)
+	assert.Nil(t, digest.BusiestDay)
+	assert.Empty(t, digest.NotableSessions)
+	assert.Empty(t, digest.Anomalies)
+
+	_, err = builder.Build("year", models.PlanPro, 0.8, nil, now)
+	assert.Error(t, err)
+}
@@ -0,0 +1,86 @@
+package calculations_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/penwyp/claudecat/calculations"
+	"github.com/penwyp/claudecat/models"
+)
+
+// exampleSession returns an active five-hour session that used 90,000 tokens and $1.50 in its first 90 minutes
+func exampleSession() models.SessionBlock {
+	start := time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC)
+	lastEntry := start.Add(90 * time.Minute)
+	return models.SessionBlock{
+		StartTime:     start,
+		EndTime:       start.Add(5 * time.Hour),
+		ActualEndTime: &lastEntry,
+		IsActive:      true,
+		TokenCounts:   models.TokenCounts{InputTokens: 20000, OutputTokens: 10000, CacheReadTokens: 60000},
+		CostUSD:       1.5,
+	}
+}
+
+func ExampleNewPercentiles() {
+	p := calculations.NewPercentiles([]float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
+	fmt.Printf("P50=%.0f P75=%.0f P90=%.0f P99=%.0f\n", p.P50, p.P75, p.P90, p.P99)
+	// Output:
+	// P50=6 P75=8 P90=10 P99=10
+}
+
+func ExampleBurnRateCalculator_CalculateBurnRate() {
+	rate := calculations.NewBurnRateCalculator().CalculateBurnRate(exampleSession())
+	fmt.Printf("%.0f tokens/min, $%.2f/hour\n", rate.TokensPerMinute, rate.CostPerHour)
+	// Output:
+	// 1000 tokens/min, $1.00/hour
+}
+
+func ExampleBurnRateCalculator_ProjectBlockUsageAt() {
+	session := exampleSession()
+	now := session.StartTime.Add(2 * time.Hour)
+	projection := calculations.NewBurnRateCalculator().ProjectBlockUsageAt(session, now)
+	fmt.Printf("%d tokens, $%.2f, %.0f minutes left\n",
+		projection.ProjectedTotalTokens, projection.ProjectedTotalCost, projection.RemainingMinutes)
+	// Output:
+	// 270000 tokens, $4.50, 180 minutes left
+}
+
+// Without limit messages the tail of the session history is used; the token limit never drops below the default
+func ExampleCustomLimitEstimator_EstimateLimits() {
+	start := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+	var blocks []models.SessionBlock
+	for i, tokens := range []int{40000, 55000, 61000, 48000, 70000} {
+		begin := start.Add(time.Duration(i) * 5 * time.Hour)
+		blocks = append(blocks, models.SessionBlock{
+			StartTime:         begin,
+			EndTime:           begin.Add(5 * time.Hour),
+			TokenCounts:       models.TokenCounts{InputTokens: tokens},
+			CostUSD:           float64(tokens) / 100000,
+			SentMessagesCount: tokens / 5000,
+		})
+	}
+	limits := calculations.NewCustomLimitEstimator().EstimateLimits(blocks)
+	fmt.Printf("tokens=%d cost=$%.2f messages=%d\n", limits.TokenLimit, limits.CostLimit, limits.MessageLimit)
+	// Output:
+	// tokens=1000000 cost=$0.70 messages=14
+}
+
+func ExampleGroupAggregator_Aggregate() {
+	groups := map[string][]models.AnalysisResult{
+		"claude-sonnet-4-20250514": {
+			{Model: "claude-sonnet-4-20250514", TotalTokens: 12000, CostUSD: 0.05, Count: 1},
+			{Model: "claude-sonnet-4-20250514", TotalTokens: 8000, CostUSD: 0.03, Count: 1},
+		},
+		"claude-opus-4-20250514": {
+			{Model: "claude-opus-4-20250514", TotalTokens: 5000, CostUSD: 0.20, Count: 1},
+		},
+	}
+	for _, group := range calculations.NewGroupAggregator(0).Aggregate(groups) {
+		fmt.Printf("%s: %d entries, %d tokens, $%.2f\n",
+			group.Result.GroupKey, group.Result.Count, group.Result.TotalTokens, group.Result.CostUSD)
+	}
+	// Output:
+	// claude-opus-4-20250514: 1 entries, 5000 tokens, $0.20
+	// claude-sonnet-4-20250514: 2 entries, 20000 tokens, $0.08
+}
@@ -0,0 +1,228 @@
+package calculations
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+)
+
+// Forecast models
+const (
+	ForecastLinear = "linear" // Least-squares trend over the daily costs
+	ForecastEWMA   = "ewma"   // Exponentially weighted moving average of the daily costs
+)
+
+// ForecastModels lists the models a forecast can use
+var ForecastModels = []string{ForecastLinear, ForecastEWMA}
+
+// Forecast defaults
+const (
+	DefaultForecastHistoryDays = 28
+	DefaultForecastConfidence  = 0.8
+)
+
+// forecastEWMASpan is the number of days the EWMA weights like a simple average over the same span
+const forecastEWMASpan = 7
+
+// forecastStableSlope is the daily change relative to the average daily cost below which the trend is stable
+const forecastStableSlope = 0.02
+
+// Trend directions
+const (
+	TrendUp     = "up"
+	TrendDown   = "down"
+	TrendStable = "stable"
+)
+
+// ForecastDay is the actual or projected cost of one local day of the forecast period
+type ForecastDay struct {
+	Date      time.Time `json:"date"`
+	Cost      float64   `json:"cost"`
+	Low       float64   `json:"low"`
+	High      float64   `json:"high"`
+	Projected bool      `json:"projected"` // Days from today on; today includes its actual cost so far
+}
+
+// Forecast projects the cost of the current week or month from the daily costs before today
+type Forecast struct {
+	Period      string        `json:"period"`
+	Model       string        `json:"model"`
+	Start       time.Time     `json:"start"`
+	End         time.Time     `json:"end"` // Exclusive
+	HistoryDays int           `json:"history_days"`
+	Confidence  float64       `json:"confidence"`
+	Spent       float64       `json:"spent"`     // Actual cost of the period so far
+	Projected   float64       `json:"projected"` // Expected cost of the whole period
+	Low         float64       `json:"low"`       // Bounds of the confidence band of the projected cost
+	High        float64       `json:"high"`
+	DailyCost   float64       `json:"daily_cost"` // Expected cost of the next full day
+	Trend       string        `json:"trend"`
+	TrendPerDay float64       `json:"trend_per_day"` // Change of the daily cost per day, from the linear fit
+	Days        []ForecastDay `json:"days"`
+}
+
+// DailyCosts sums the results of each local day from the day containing from up to, but excluding, the
+// day containing to; days without usage are included with zero totals
+func DailyCosts(results []models.AnalysisResult, from, to time.Time, loc *time.Location) []DayTotals {
+	start, end := StartOfDay(from, loc), StartOfDay(to, loc)
+	var days []DayTotals
+	index := make(map[string]int)
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		index[day.Format("2006-01-02")] = len(days)
+		days = append(days, DayTotals{Date: day})
+	}
+	for _, result := range results {
+		i, ok := index[result.Timestamp.In(loc).Format("2006-01-02")]
+		if !ok {
+			continue
+		}
+		days[i].Entries += max(result.Count, 1)
+		days[i].Tokens += result.TotalTokens
+		days[i].Cost += result.CostUSD
+	}
+	return days
+}
+
+// ForecastPeriod projects the cost of the week or month containing now. The model is fitted to the
+// daily costs of the historyDays complete days before today; the band covers the given confidence
+// assuming independent, normally distributed daily errors.
+func ForecastPeriod(results []models.AnalysisResult, period, model string, historyDays int, confidence float64, now time.Time, loc *time.Location) (Forecast, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+	model = strings.ToLower(model)
+	if model != ForecastLinear && model != ForecastEWMA {
+		return Forecast{}, fmt.Errorf("invalid forecast model: %s (valid: %s)", model, strings.Join(ForecastModels, ", "))
+	}
+	if historyDays < 2 {
+		return Forecast{}, fmt.Errorf("invalid history: %d days (must be at least 2)", historyDays)
+	}
+	if confidence <= 0 || confidence >= 1 {
+		return Forecast{}, fmt.Errorf("invalid confidence: %g (must be between 0 and 1)", confidence)
+	}
+	start, end, err := ReportPeriodBounds(period, now, loc)
+	if err != nil {
+		return Forecast{}, err
+	}
+
+	today := StartOfDay(now, loc)
+	history := DailyCosts(results, today.AddDate(0, 0, -historyDays), today, loc)
+	costs := make([]float64, len(history))
+	for i, day := range history {
+		costs[i] = day.Cost
+	}
+	fit := fitForecast(costs, model)
+
+	forecast := Forecast{
+		Period:      strings.ToLower(period),
+		Model:       model,
+		Start:       start,
+		End:         end,
+		HistoryDays: historyDays,
+		Confidence:  confidence,
+		Trend:       fit.trend,
+		TrendPerDay: fit.slope,
+		DailyCost:   math.Max(fit.predict(len(costs)+1), 0),
+		Days:        []ForecastDay{},
+	}
+
+	z := math.Sqrt2 * math.Erfinv(confidence)
+	var variance float64
+	actual := DailyCosts(results, start, now.AddDate(0, 0, 1), loc)
+	ahead := 0
+	for i, day := 0, start; day.Before(end); i, day = i+1, day.AddDate(0, 0, 1) {
+		spent := 0.0
+		if i < len(actual) {
+			spent = actual[i].Cost
+		}
+		if day.Before(today) {
+			forecast.Spent += spent
+			forecast.Projected += spent
+			forecast.Days = append(forecast.Days, ForecastDay{Date: day, Cost: spent, Low: spent, High: spent})
+			continue
+		}
+
+		// Today only the rest of the day is projected on top of its actual cost
+		remaining := 1.0
+		if day.Equal(today) {
+			forecast.Spent += spent
+			next := day.AddDate(0, 0, 1)
+			remaining = math.Max(float64(next.Sub(now))/float64(next.Sub(day)), 0)
+		}
+		predicted := math.Max(fit.predict(len(costs)+ahead), 0) * remaining
+		spread := z * fit.sigma * remaining
+		variance += (fit.sigma * remaining) * (fit.sigma * remaining)
+		forecast.Days = append(forecast.Days, ForecastDay{
+			Date:      day,
+			Cost:      spent + predicted,
+			Low:       spent + math.Max(predicted-spread, 0),
+			High:      spent + predicted + spread,
+			Projected: true,
+		})
+		forecast.Projected += spent + predicted
+		ahead++
+	}
+	spread := z * math.Sqrt(variance)
+	forecast.Low = math.Max(forecast.Projected-spread, forecast.Spent)
+	forecast.High = forecast.Projected + spread
+	return forecast, nil
+}
+
+// forecastFit is a model fitted to daily costs, indexed from 0 for the first day of history
+type forecastFit struct {
+	predict func(day int) float64
+	sigma   float64 // Standard deviation of the daily errors
+	slope   float64
+	trend   string
+}
+
+// fitForecast fits the model to the daily costs and detects their trend from the least-squares slope
+func fitForecast(costs []float64, model string) forecastFit {
+	n := float64(len(costs))
+	var sumX, sumY, sumXY, sumXX float64
+	for i, y := range costs {
+		x := float64(i)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	mean := sumY / n
+	slope := 0.0
+	if denominator := n*sumXX - sumX*sumX; denominator != 0 {
+		slope = (n*sumXY - sumX*sumY) / denominator
+	}
+	intercept := mean - slope*sumX/n
+
+	fit := forecastFit{slope: slope, trend: TrendStable}
+	if mean > 0 && math.Abs(slope)/mean >= forecastStableSlope {
+		fit.trend = TrendUp
+		if slope < 0 {
+			fit.trend = TrendDown
+		}
+	}
+
+	var sse float64
+	switch model {
+	case ForecastEWMA:
+		alpha := 2.0 / float64(forecastEWMASpan+1)
+		level := costs[0]
+		for _, y := range costs[1:] {
+			sse += (y - level) * (y - level)
+			level = alpha*y + (1-alpha)*level
+		}
+		fit.predict = func(int) float64 { return level }
+		fit.sigma = math.Sqrt(sse / math.Max(n-1, 1))
+	default:
+		for i, y := range costs {
+			e := y - (intercept + slope*float64(i))
+			sse += e * e
+		}
+		fit.predict = func(day int) float64 { return intercept + slope*float64(day) }
+		fit.sigma = math.Sqrt(sse / math.Max(n-2, 1))
+	}
+	return fit
+}
@@ -0,0 +1,97 @@
+package calculations
+
+import (
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dailyResults returns one result at noon of each of the days before now, costing cost(i) on the i-th day
+func dailyResults(now time.Time, days int, cost func(i int) float64) []models.AnalysisResult {
+	start := StartOfDay(now, time.UTC).AddDate(0, 0, -days)
+	var results []models.AnalysisResult
+	for i := 0; i < days; i++ {
+		results = append(results, models.AnalysisResult{
+			Timestamp: start.AddDate(0, 0, i).Add(12 * time.Hour),
+			CostUSD:   cost(i),
+			Count:     1,
+		})
+	}
+	return results
+}
+
+func TestDailyCosts(t *testing.T) {
+	now := time.Date(2025, 6, 10, 9, 0, 0, 0, time.UTC)
+	results := dailyResults(now, 3, func(i int) float64 { return float64(i + 1) })
+	results = append(results, models.AnalysisResult{Timestamp: now, CostUSD: 100, Count: 1})
+
+	days := DailyCosts(results, now.AddDate(0, 0, -5), now, time.UTC)
+	require.Len(t, days, 5, "up to but excluding today")
+	assert.Zero(t, days[0].Cost)
+	assert.Zero(t, days[1].Cost)
+	assert.InDelta(t, 1, days[2].Cost, 0.001)
+	assert.InDelta(t, 3, days[4].Cost, 0.001)
+	assert.Equal(t, 1, days[4].Entries)
+}
+
+func TestForecastPeriod_Linear(t *testing.T) {
+	// Noon on Friday 13 June: the week has Monday to Thursday behind it and half of today left
+	now := time.Date(2025, 6, 13, 12, 0, 0, 0, time.UTC)
+	results := dailyResults(now, 28, func(i int) float64 { return float64(i + 1) })
+	results = append(results, models.AnalysisResult{Timestamp: now.Add(-time.Hour), CostUSD: 10, Count: 1})
+
+	forecast, err := ForecastPeriod(results, ReportPeriodWeek, ForecastLinear, 28, 0.8, now, time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 6, 9, 0, 0, 0, 0, time.UTC), forecast.Start)
+	require.Len(t, forecast.Days, 7)
+	assert.False(t, forecast.Days[3].Projected)
+	assert.True(t, forecast.Days[4].Projected)
+
+	// Monday to Thursday cost 25..28, plus 10 so far today
+	assert.InDelta(t, 25+26+27+28+10, forecast.Spent, 0.001)
+	assert.Equal(t, TrendUp, forecast.Trend)
+	assert.InDelta(t, 1, forecast.TrendPerDay, 0.001)
+	assert.InDelta(t, 30, forecast.DailyCost, 0.001, "tomorrow continues the trend")
+	// Half of today's 29 on top of the 10 spent, then 30 and 31 for the weekend
+	assert.InDelta(t, 10+14.5, forecast.Days[4].Cost, 0.001)
+	assert.InDelta(t, forecast.Spent+14.5+30+31, forecast.Projected, 0.001)
+	// A perfect fit leaves no band
+	assert.InDelta(t, forecast.Projected, forecast.Low, 0.001)
+	assert.InDelta(t, forecast.Projected, forecast.High, 0.001)
+}
+
+func TestForecastPeriod_EWMABand(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	results := dailyResults(now, 28, func(i int) float64 { return float64(4 + 2*(i%2)) })
+
+	forecast, err := ForecastPeriod(results, ReportPeriodMonth, ForecastEWMA, 28, 0.9, now, time.UTC)
+	require.NoError(t, err)
+	require.Len(t, forecast.Days, 30)
+	assert.Zero(t, forecast.Spent)
+	assert.Equal(t, TrendStable, forecast.Trend)
+	assert.InDelta(t, 5, forecast.DailyCost, 0.5, "the average level of alternating 4 and 6")
+	assert.InDelta(t, forecast.DailyCost*30, forecast.Projected, 0.001)
+	assert.Less(t, forecast.Low, forecast.Projected)
+	assert.Greater(t, forecast.High, forecast.Projected)
+	assert.InDelta(t, forecast.Projected-forecast.Low, forecast.High-forecast.Projected, 0.001)
+}
+
+func TestForecastPeriod_Invalid(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	_, err := ForecastPeriod(nil, ReportPeriodMonth, "arima", 28, 0.8, now, time.UTC)
+	assert.Error(t, err)
+	_, err = ForecastPeriod(nil, ReportPeriodMonth, ForecastLinear, 1, 0.8, now, time.UTC)
+	assert.Error(t, err)
+	_, err = ForecastPeriod(nil, ReportPeriodMonth, ForecastLinear, 28, 1, now, time.UTC)
+	assert.Error(t, err)
+	_, err = ForecastPeriod(nil, "year", ForecastLinear, 28, 0.8, now, time.UTC)
+	assert.Error(t, err)
+
+	forecast, err := ForecastPeriod(nil, ReportPeriodMonth, ForecastLinear, 28, 0.8, now, time.UTC)
+	require.NoError(t, err)
+	assert.Zero(t, forecast.Projected)
+	assert.Equal(t, TrendStable, forecast.Trend)
+}
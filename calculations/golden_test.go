@@ -0,0 +1,131 @@
+package calculations
+
+import (
+	"encoding/json"
+	"flag"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+// goldenNow is the evaluation time of the golden fixtures, shortly after the last entry of the active session
+var goldenNow = time.Date(2025, 6, 6, 10, 0, 0, 0, time.UTC)
+
+// goldenOutputs are the published results of the calculations for testdata/golden/blocks.json
+type goldenOutputs struct {
+	BurnRate           *models.BurnRate             `json:"burn_rate"`
+	Projection         *models.UsageProjection      `json:"projection"`
+	HourlyBurnRate     float64                      `json:"hourly_burn_rate"`
+	SmoothedRates      map[SmoothingMode]UsageRates `json:"smoothed_rates"`
+	SessionPercentiles SessionPercentiles           `json:"session_percentiles"`
+	EstimatedLimits    models.PlanLimits            `json:"estimated_limits"`
+	ModelGroups        []goldenGroup                `json:"model_groups"`
+}
+
+// goldenGroup is the aggregate of one model in the golden outputs
+type goldenGroup struct {
+	Model       string  `json:"model"`
+	Entries     int     `json:"entries"`
+	TotalTokens int     `json:"total_tokens"`
+	CostUSD     float64 `json:"cost_usd"`
+}
+
+func computeGoldenOutputs(blocks []models.SessionBlock) goldenOutputs {
+	brc := NewBurnRateCalculator()
+	active := blocks[len(blocks)-1]
+
+	outputs := goldenOutputs{
+		BurnRate:           brc.CalculateBurnRate(active),
+		Projection:         brc.ProjectBlockUsageAt(active, goldenNow),
+		HourlyBurnRate:     brc.CalculateHourlyBurnRate(blocks, goldenNow),
+		SmoothedRates:      make(map[SmoothingMode]UsageRates),
+		SessionPercentiles: NewStatsAggregator(time.UTC).SessionPercentiles(blocks),
+		EstimatedLimits:    NewCustomLimitEstimator().EstimateLimits(blocks),
+	}
+	for _, mode := range []SmoothingMode{SmoothingInstant, SmoothingEMA, SmoothingHourly} {
+		outputs.SmoothedRates[mode] = brc.CalculateSmoothedRates(blocks, goldenNow, mode)
+	}
+
+	groups := make(map[string][]models.AnalysisResult)
+	for _, block := range blocks {
+		for _, entry := range block.Entries {
+			groups[entry.Model] = append(groups[entry.Model], models.AnalysisResult{
+				Model:       entry.Model,
+				TotalTokens: entry.TotalTokens,
+				CostUSD:     entry.CostUSD,
+				Count:       1,
+			})
+		}
+	}
+	for _, group := range NewGroupAggregator(1).Aggregate(groups) {
+		outputs.ModelGroups = append(outputs.ModelGroups, goldenGroup{
+			Model:       group.Result.GroupKey,
+			Entries:     group.Result.Count,
+			TotalTokens: group.Result.TotalTokens,
+			CostUSD:     group.Result.CostUSD,
+		})
+	}
+	return outputs
+}
+
+func TestGoldenFixtures(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "golden", "blocks.json"))
+	require.NoError(t, err)
+	var blocks []models.SessionBlock
+	require.NoError(t, json.Unmarshal(data, &blocks))
+
+	actual, err := json.MarshalIndent(computeGoldenOutputs(blocks), "", "  ")
+	require.NoError(t, err)
+	goldenFile := filepath.Join("testdata", "golden", "expected.json")
+	if *updateGolden {
+		require.NoError(t, os.WriteFile(goldenFile, append(actual, '\n'), 0644))
+	}
+
+	expected, err := os.ReadFile(goldenFile)
+	require.NoError(t, err, "run go test ./calculations -run TestGoldenFixtures -update to create it")
+	assertJSONNear(t, expected, actual)
+}
+
+// assertJSONNear compares two JSON documents, allowing a relative difference of 1e-9 between numbers
+// so that the fixtures hold across platforms with different floating point contraction
+func assertJSONNear(t *testing.T, expected, actual []byte) {
+	t.Helper()
+	var want, got any
+	require.NoError(t, json.Unmarshal(expected, &want))
+	require.NoError(t, json.Unmarshal(actual, &got))
+
+	var compare func(path string, want, got any)
+	compare = func(path string, want, got any) {
+		switch w := want.(type) {
+		case map[string]any:
+			g, ok := got.(map[string]any)
+			require.True(t, ok, "%s: expected an object", path)
+			require.Len(t, g, len(w), "%s: field count", path)
+			for key, value := range w {
+				compare(path+"."+key, value, g[key])
+			}
+		case []any:
+			g, ok := got.([]any)
+			require.True(t, ok, "%s: expected an array", path)
+			require.Len(t, g, len(w), "%s: length", path)
+			for i := range w {
+				compare(path+"["+strconv.Itoa(i)+"]", w[i], g[i])
+			}
+		case float64:
+			g, ok := got.(float64)
+			require.True(t, ok, "%s: expected a number", path)
+			require.LessOrEqual(t, math.Abs(w-g), 1e-9*math.Max(1, math.Abs(w)), "%s: expected %v, got %v", path, w, g)
+		default:
+			require.Equal(t, want, got, path)
+		}
+	}
+	compare("$", want, got)
+}
@@ -0,0 +1,156 @@
+package calculations
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/penwyp/claudecat/models"
+)
+
+// minParallelGroupEntries is the dataset size below which aggregation stays serial
+const minParallelGroupEntries = 10000
+
+// GroupAggregator aggregates grouped analysis results using a bounded worker pool
+type GroupAggregator struct {
+	workers int
+}
+
+// GroupResult is the aggregate of one group along with the distinct models it contains
+type GroupResult struct {
+	Result models.AnalysisResult
+	Models []string
+}
+
+// GroupByKeys are the fields results can be grouped by with GroupKey
+var GroupByKeys = []string{"model", "project", "tool", "session", "tag", "hour", "day", "week", "month"}
+
+// GroupKey returns the group of result when grouping by groupBy; unknown fields group everything as "all"
+func GroupKey(result models.AnalysisResult, groupBy string) string {
+	switch groupBy {
+	case "model":
+		return result.Model
+	case "project":
+		if result.Project == "" {
+			return "unknown"
+		}
+		return result.Project
+	case "tool":
+		if result.ToolServer == "" {
+			return "none"
+		}
+		return result.ToolServer
+	case "day":
+		return result.Timestamp.Format("2006-01-02")
+	case "hour":
+		return result.Timestamp.Format("2006-01-02 15:00")
+	case "week":
+		year, week := result.Timestamp.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case "month":
+		return result.Timestamp.Format("2006-01")
+	case "session":
+		return result.SessionID
+	case "tag":
+		if len(result.Tags) == 0 {
+			return "untagged"
+		}
+		return strings.Join(result.Tags, ",")
+	default:
+		return "all"
+	}
+}
+
+// IsTimeGrouping reports whether groupBy groups results by a time period
+func IsTimeGrouping(groupBy string) bool {
+	return groupBy == "hour" || groupBy == "day" || groupBy == "week" || groupBy == "month"
+}
+
+// NewGroupAggregator creates an aggregator with the given number of workers; 0 uses all CPUs
+func NewGroupAggregator(workers int) *GroupAggregator {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	return &GroupAggregator{workers: workers}
+}
+
+// Aggregate sums each group's results and returns the aggregates sorted by group key
+func (ga *GroupAggregator) Aggregate(groups map[string][]models.AnalysisResult) []GroupResult {
+	keys := make([]string, 0, len(groups))
+	total := 0
+	for key, results := range groups {
+		if len(results) == 0 {
+			continue
+		}
+		keys = append(keys, key)
+		total += len(results)
+	}
+	sort.Strings(keys)
+
+	aggregated := make([]GroupResult, len(keys))
+	workers := ga.workers
+	if workers > len(keys) {
+		workers = len(keys)
+	}
+
+	if workers <= 1 || total < minParallelGroupEntries {
+		for i, key := range keys {
+			aggregated[i] = aggregateGroup(key, groups[key])
+		}
+		return aggregated
+	}
+
+	// Each worker writes to distinct indices, so no locking is needed for the merge
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				aggregated[i] = aggregateGroup(keys[i], groups[keys[i]])
+			}
+		}()
+	}
+	for i := range keys {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return aggregated
+}
+
+// aggregateGroup sums token and cost totals for a single group
+func aggregateGroup(key string, results []models.AnalysisResult) GroupResult {
+	agg := models.AnalysisResult{
+		GroupKey:  key,
+		Timestamp: results[0].Timestamp,
+		SessionID: results[0].SessionID,
+		Project:   results[0].Project,
+		Tags:      results[0].Tags,
+		Count:     len(results),
+
+		SessionConfidence: results[0].SessionConfidence,
+	}
+
+	modelSet := make(map[string]bool)
+	var groupModels []string
+	for _, result := range results {
+		agg.InputTokens += result.InputTokens
+		agg.OutputTokens += result.OutputTokens
+		agg.CacheCreationTokens += result.CacheCreationTokens
+		agg.CacheCreation1hTokens += result.CacheCreation1hTokens
+		agg.CacheReadTokens += result.CacheReadTokens
+		agg.TotalTokens += result.TotalTokens
+		agg.CostUSD += result.CostUSD
+		if result.Model != "" && !modelSet[result.Model] {
+			modelSet[result.Model] = true
+			groupModels = append(groupModels, result.Model)
+		}
+	}
+
+	return GroupResult{Result: agg, Models: groupModels}
+}
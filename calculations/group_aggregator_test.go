@@ -0,0 +1,95 @@
+package calculations
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// yearOfGroups builds a year of hourly results grouped by day
+func yearOfGroups(perHour int) map[string][]models.AnalysisResult {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	modelNames := []string{"claude-sonnet-4", "claude-opus-4", "claude-3-5-haiku"}
+	groups := make(map[string][]models.AnalysisResult)
+	for h := 0; h < 365*24; h++ {
+		ts := start.Add(time.Duration(h) * time.Hour)
+		key := ts.Format("2006-01-02")
+		for i := 0; i < perHour; i++ {
+			groups[key] = append(groups[key], models.AnalysisResult{
+				Timestamp:    ts,
+				Model:        modelNames[(h+i)%len(modelNames)],
+				InputTokens:  100 + i,
+				OutputTokens: 50,
+				TotalTokens:  150 + i,
+				CostUSD:      0.001 * float64(i+1),
+			})
+		}
+	}
+	return groups
+}
+
+func TestGroupAggregator_ParallelMatchesSerial(t *testing.T) {
+	groups := yearOfGroups(4)
+
+	serial := NewGroupAggregator(1).Aggregate(groups)
+	parallel := NewGroupAggregator(8).Aggregate(groups)
+
+	require.Len(t, serial, 365)
+	assert.Equal(t, serial, parallel)
+
+	first := serial[0]
+	assert.Equal(t, "2024-01-01", first.Result.GroupKey)
+	assert.Equal(t, 96, first.Result.Count)
+	assert.Equal(t, 24*(100+101+102+103), first.Result.InputTokens)
+	assert.ElementsMatch(t, []string{"claude-sonnet-4", "claude-opus-4", "claude-3-5-haiku"}, first.Models)
+}
+
+func TestGroupAggregator_SkipsEmptyGroups(t *testing.T) {
+	groups := map[string][]models.AnalysisResult{
+		"a": {{TotalTokens: 10}},
+		"b": nil,
+	}
+	result := NewGroupAggregator(0).Aggregate(groups)
+	require.Len(t, result, 1)
+	assert.Equal(t, "a", result[0].Result.GroupKey)
+	assert.Empty(t, result[0].Models)
+}
+
+func BenchmarkGroupAggregator_Year(b *testing.B) {
+	groups := yearOfGroups(20)
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			aggregator := NewGroupAggregator(workers)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				aggregator.Aggregate(groups)
+			}
+		})
+	}
+}
+
+func TestGroupKey(t *testing.T) {
+	result := models.AnalysisResult{
+		Timestamp: time.Date(2025, 6, 10, 14, 30, 0, 0, time.UTC),
+		Model:     "claude-sonnet-4",
+		SessionID: "session-1",
+	}
+	assert.Equal(t, "claude-sonnet-4", GroupKey(result, "model"))
+	assert.Equal(t, "unknown", GroupKey(result, "project"))
+	assert.Equal(t, "none", GroupKey(result, "tool"))
+	assert.Equal(t, "session-1", GroupKey(result, "session"))
+	assert.Equal(t, "untagged", GroupKey(result, "tag"))
+	result.Tags = []string{"bug-12", "feature-x"}
+	assert.Equal(t, "bug-12,feature-x", GroupKey(result, "tag"))
+	assert.Equal(t, "2025-06-10 14:00", GroupKey(result, "hour"))
+	assert.Equal(t, "2025-06-10", GroupKey(result, "day"))
+	assert.Equal(t, "2025-W24", GroupKey(result, "week"))
+	assert.Equal(t, "2025-06", GroupKey(result, "month"))
+	assert.Equal(t, "all", GroupKey(result, ""))
+	assert.True(t, IsTimeGrouping("week"))
+	assert.False(t, IsTimeGrouping("model"))
+}
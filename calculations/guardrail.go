@@ -0,0 +1,100 @@
+package calculations
+
+import (
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/models"
+)
+
+// GuardrailHit is a single message whose input exceeded the guardrail of its model
+type GuardrailHit struct {
+	Timestamp   time.Time `json:"timestamp"`
+	Model       string    `json:"model"`
+	InputTokens int       `json:"input_tokens"` // Uncached input plus cache writes of the message
+	Threshold   int       `json:"threshold"`
+	CostUSD     float64   `json:"cost_usd"`
+	SessionID   string    `json:"session_id,omitempty"` // Claude Code conversation, unknown for entries read from cached summaries
+	Project     string    `json:"project"`
+	SourceFile  string    `json:"source_file,omitempty"` // Set when provenance is recorded
+	SourceLine  int       `json:"source_line,omitempty"`
+}
+
+// MessageGuardrail flags messages that add more input tokens than the threshold of their model,
+// typically an accidental paste of a large file or log into the conversation
+type MessageGuardrail struct {
+	threshold int
+	models    []config.ModelGuardrailConfig
+}
+
+// NewMessageGuardrail creates a guardrail from the configured default threshold and per-model overrides
+func NewMessageGuardrail(cfg config.GuardrailsConfig) *MessageGuardrail {
+	return &MessageGuardrail{threshold: cfg.MessageInputTokens, models: cfg.Models}
+}
+
+// Enabled reports whether any model has a threshold
+func (g *MessageGuardrail) Enabled() bool {
+	if g.threshold > 0 {
+		return true
+	}
+	for _, model := range g.models {
+		if model.MessageInputTokens > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Threshold returns the input token threshold of model, 0 if it is not guarded
+func (g *MessageGuardrail) Threshold(model string) int {
+	model = strings.ToLower(model)
+	for _, override := range g.models {
+		if matched, _ := path.Match(strings.ToLower(override.Model), model); matched {
+			return override.MessageInputTokens
+		}
+	}
+	return g.threshold
+}
+
+// Check returns the hit caused by entry, if its input exceeds the threshold of its model
+func (g *MessageGuardrail) Check(entry models.UsageEntry) (GuardrailHit, bool) {
+	threshold := g.Threshold(entry.Model)
+	input := MessageInputTokens(entry)
+	if threshold <= 0 || input <= threshold {
+		return GuardrailHit{}, false
+	}
+	return GuardrailHit{
+		Timestamp:   entry.Timestamp,
+		Model:       entry.Model,
+		InputTokens: input,
+		Threshold:   threshold,
+		CostUSD:     entry.CostUSD,
+		SessionID:   entry.SessionID,
+		Project:     entry.Project,
+		SourceFile:  entry.SourceFile,
+		SourceLine:  entry.SourceLine,
+	}, true
+}
+
+// Scan returns the hits among entries, largest first
+func (g *MessageGuardrail) Scan(entries []models.UsageEntry) []GuardrailHit {
+	var hits []GuardrailHit
+	for _, entry := range entries {
+		if hit, ok := g.Check(entry); ok {
+			hits = append(hits, hit)
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].InputTokens > hits[j].InputTokens
+	})
+	return hits
+}
+
+// MessageInputTokens returns the input a message added to its conversation: uncached input plus
+// cache writes. Cache reads are excluded since they repeat context sent earlier.
+func MessageInputTokens(entry models.UsageEntry) int {
+	return entry.InputTokens + entry.CacheCreationTokens
+}
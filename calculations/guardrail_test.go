@@ -0,0 +1,42 @@
+package calculations
+
+import (
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageGuardrail(t *testing.T) {
+	guardrail := NewMessageGuardrail(config.GuardrailsConfig{
+		MessageInputTokens: 150000,
+		Models: []config.ModelGuardrailConfig{
+			{Model: "claude-opus-*", MessageInputTokens: 50000},
+			{Model: "*HAIKU*", MessageInputTokens: 0},
+		},
+	})
+	assert.True(t, guardrail.Enabled())
+	assert.Equal(t, 50000, guardrail.Threshold("claude-opus-4-20250514"))
+	assert.Equal(t, 0, guardrail.Threshold("claude-3-5-haiku-20241022"))
+	assert.Equal(t, 150000, guardrail.Threshold("claude-sonnet-4-20250514"))
+
+	now := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	entries := []models.UsageEntry{
+		// Cache reads repeat earlier context and do not count
+		{Timestamp: now, Model: "claude-sonnet-4-20250514", InputTokens: 10, CacheReadTokens: 500000},
+		{Timestamp: now, Model: "claude-sonnet-4-20250514", InputTokens: 500, CacheCreationTokens: 160000, Project: "webapp"},
+		{Timestamp: now, Model: "claude-opus-4-20250514", InputTokens: 60000, SessionID: "abc"},
+		{Timestamp: now, Model: "claude-3-5-haiku-20241022", InputTokens: 900000},
+	}
+	hits := guardrail.Scan(entries)
+	require.Len(t, hits, 2)
+	assert.Equal(t, 160500, hits[0].InputTokens)
+	assert.Equal(t, "webapp", hits[0].Project)
+	assert.Equal(t, 50000, hits[1].Threshold)
+	assert.Equal(t, "abc", hits[1].SessionID)
+
+	assert.False(t, NewMessageGuardrail(config.GuardrailsConfig{}).Enabled())
+}
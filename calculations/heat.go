@@ -0,0 +1,40 @@
+package calculations
+
+// HeatLevel ranks a value by its percentile among the other values of a column, for conditional
+// formatting of long tables
+type HeatLevel int
+
+const (
+	HeatNone   HeatLevel = iota // Not ranked: fewer than two different values
+	HeatLow                     // Below the 50th percentile
+	HeatMedium                  // From the 50th to below the 90th percentile
+	HeatHigh                    // At or above the 90th percentile
+)
+
+// HeatLevels ranks each of values by its percentile among values
+func HeatLevels(values []float64) []HeatLevel {
+	levels := make([]HeatLevel, len(values))
+	if len(values) < 2 {
+		return levels
+	}
+	lowest, highest := values[0], values[0]
+	for _, v := range values {
+		lowest, highest = min(lowest, v), max(highest, v)
+	}
+	if lowest == highest {
+		return levels
+	}
+
+	p := NewPercentiles(values)
+	for i, v := range values {
+		switch {
+		case v >= p.P90:
+			levels[i] = HeatHigh
+		case v >= p.P50:
+			levels[i] = HeatMedium
+		default:
+			levels[i] = HeatLow
+		}
+	}
+	return levels
+}
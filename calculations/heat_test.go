@@ -0,0 +1,21 @@
+package calculations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeatLevels(t *testing.T) {
+	values := []float64{10, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	levels := HeatLevels(values)
+	assert.Equal(t, HeatHigh, levels[0])
+	assert.Equal(t, HeatLow, levels[1])
+	assert.Equal(t, HeatLow, levels[5], "5 is below the median of 6")
+	assert.Equal(t, HeatMedium, levels[6])
+	assert.Equal(t, HeatMedium, levels[9])
+
+	assert.Equal(t, []HeatLevel{HeatNone, HeatNone}, HeatLevels([]float64{3, 3}))
+	assert.Equal(t, []HeatLevel{HeatNone}, HeatLevels([]float64{3}))
+	assert.Equal(t, []HeatLevel{HeatLow, HeatHigh}, HeatLevels([]float64{1, 2}))
+}
@@ -0,0 +1,180 @@
+package calculations
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/penwyp/claudecat/humanize"
+	"github.com/penwyp/claudecat/models"
+)
+
+// Milestone metrics
+const (
+	MilestoneTokens = "tokens" // Lifetime tokens
+	MilestoneCost   = "cost"   // Lifetime cost in USD
+	MilestoneStreak = "streak" // Longest run of consecutive days with usage
+)
+
+// milestoneThresholds are the badges awarded per metric, in increasing order
+var milestoneThresholds = map[string][]float64{
+	MilestoneTokens: {1e6, 1e7, 1e8, 1e9, 1e10},
+	MilestoneCost:   {10, 100, 1000, 10000},
+	MilestoneStreak: {7, 30, 100, 365},
+}
+
+// Badge is a lifetime usage threshold and whether it was reached
+type Badge struct {
+	Name      string    `json:"name"`
+	Metric    string    `json:"metric"`
+	Threshold float64   `json:"threshold"`
+	Reached   bool      `json:"reached"`
+	ReachedOn time.Time `json:"reached_on,omitempty"` // Day the threshold was crossed
+}
+
+// Streak is a run of consecutive days with usage
+type Streak struct {
+	Days  int       `json:"days"`
+	Start time.Time `json:"start,omitempty"`
+	End   time.Time `json:"end,omitempty"`
+}
+
+// Milestones are the lifetime totals, streaks and badges of a user's usage
+type Milestones struct {
+	FirstUse      time.Time `json:"first_use,omitempty"`
+	LastUse       time.Time `json:"last_use,omitempty"`
+	ActiveDays    int       `json:"active_days"`
+	TotalEntries  int       `json:"total_entries"`
+	TotalTokens   int       `json:"total_tokens"`
+	TotalCost     float64   `json:"total_cost"`
+	LongestStreak Streak    `json:"longest_streak"`
+	CurrentStreak Streak    `json:"current_streak"` // Ending today, or yesterday when today has no usage yet
+	BusiestDay    DayTotals `json:"busiest_day"`    // The day with the highest cost
+	Badges        []Badge   `json:"badges"`
+}
+
+// NextBadge returns the unreached badge closest to being reached relative to its threshold, if any
+func (m Milestones) NextBadge() (Badge, float64, bool) {
+	var next Badge
+	best := -1.0
+	for _, badge := range m.Badges {
+		if badge.Reached {
+			continue
+		}
+		progress := m.value(badge.Metric) / badge.Threshold
+		if progress > best {
+			next, best = badge, progress
+		}
+	}
+	return next, best, best >= 0
+}
+
+// value returns the current value of a milestone metric
+func (m Milestones) value(metric string) float64 {
+	switch metric {
+	case MilestoneTokens:
+		return float64(m.TotalTokens)
+	case MilestoneCost:
+		return m.TotalCost
+	default:
+		return float64(m.LongestStreak.Days)
+	}
+}
+
+// ComputeMilestones derives the milestones of results, counting days in loc up to now
+func ComputeMilestones(results []models.AnalysisResult, now time.Time, loc *time.Location) Milestones {
+	if loc == nil {
+		loc = time.Local
+	}
+	byDay := make(map[time.Time]*DayTotals)
+	var milestones Milestones
+	for _, result := range results {
+		if milestones.FirstUse.IsZero() || result.Timestamp.Before(milestones.FirstUse) {
+			milestones.FirstUse = result.Timestamp
+		}
+		if result.Timestamp.After(milestones.LastUse) {
+			milestones.LastUse = result.Timestamp
+		}
+		day := StartOfDay(result.Timestamp, loc)
+		totals, ok := byDay[day]
+		if !ok {
+			totals = &DayTotals{Date: day}
+			byDay[day] = totals
+		}
+		totals.Entries += max(result.Count, 1)
+		totals.Tokens += result.TotalTokens
+		totals.Cost += result.CostUSD
+	}
+
+	days := make([]DayTotals, 0, len(byDay))
+	for _, totals := range byDay {
+		days = append(days, *totals)
+	}
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Date.Before(days[j].Date)
+	})
+	milestones.ActiveDays = len(days)
+
+	reachedOn := make(map[string]map[float64]time.Time)
+	for metric := range milestoneThresholds {
+		reachedOn[metric] = make(map[float64]time.Time)
+	}
+	var run Streak
+	for i, day := range days {
+		milestones.TotalEntries += day.Entries
+		milestones.TotalTokens += day.Tokens
+		milestones.TotalCost += day.Cost
+		if day.Cost > milestones.BusiestDay.Cost || milestones.BusiestDay.Date.IsZero() {
+			milestones.BusiestDay = day
+		}
+
+		if i > 0 && day.Date.Equal(days[i-1].Date.AddDate(0, 0, 1)) {
+			run.Days++
+		} else {
+			run = Streak{Days: 1, Start: day.Date}
+		}
+		run.End = day.Date
+		if run.Days > milestones.LongestStreak.Days {
+			milestones.LongestStreak = run
+		}
+
+		values := map[string]float64{
+			MilestoneTokens: float64(milestones.TotalTokens),
+			MilestoneCost:   milestones.TotalCost,
+			MilestoneStreak: float64(milestones.LongestStreak.Days),
+		}
+		for metric, thresholds := range milestoneThresholds {
+			for _, threshold := range thresholds {
+				if _, ok := reachedOn[metric][threshold]; !ok && values[metric] >= threshold {
+					reachedOn[metric][threshold] = day.Date
+				}
+			}
+		}
+	}
+
+	today := StartOfDay(now, loc)
+	if run.Days > 0 && !run.End.Before(today.AddDate(0, 0, -1)) {
+		milestones.CurrentStreak = run
+	}
+
+	for _, metric := range []string{MilestoneTokens, MilestoneCost, MilestoneStreak} {
+		for _, threshold := range milestoneThresholds[metric] {
+			badge := Badge{Name: badgeName(metric, threshold), Metric: metric, Threshold: threshold}
+			badge.ReachedOn, badge.Reached = reachedOn[metric][threshold]
+			milestones.Badges = append(milestones.Badges, badge)
+		}
+	}
+	return milestones
+}
+
+// badgeName names the badge of a metric threshold, such as "1B tokens", "$1K spent" or "30-day streak"
+func badgeName(metric string, threshold float64) string {
+	switch metric {
+	case MilestoneTokens:
+		return humanize.Compact(int(threshold)) + " tokens"
+	case MilestoneCost:
+		return "$" + humanize.Compact(int(threshold)) + " spent"
+	default:
+		return fmt.Sprintf("%.0f-day streak", threshold)
+	}
+}
@@ -0,0 +1,57 @@
+package calculations
+
+import (
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeMilestones(t *testing.T) {
+	now := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+	day := func(d int) time.Time { return time.Date(2025, 6, d, 12, 0, 0, 0, time.UTC) }
+	var results []models.AnalysisResult
+	// An 8-day streak from June 1st, then a 2-day one ending yesterday
+	for d := 1; d <= 8; d++ {
+		results = append(results, models.AnalysisResult{Timestamp: day(d), TotalTokens: 200_000, CostUSD: 1, Count: 2})
+	}
+	results = append(results,
+		models.AnalysisResult{Timestamp: day(18), TotalTokens: 300_000, CostUSD: 5},
+		models.AnalysisResult{Timestamp: day(19), TotalTokens: 100_000, CostUSD: 0.5},
+	)
+
+	milestones := ComputeMilestones(results, now, time.UTC)
+	assert.Equal(t, day(1), milestones.FirstUse)
+	assert.Equal(t, day(19), milestones.LastUse)
+	assert.Equal(t, 10, milestones.ActiveDays)
+	assert.Equal(t, 18, milestones.TotalEntries)
+	assert.Equal(t, 2_000_000, milestones.TotalTokens)
+	assert.InDelta(t, 13.5, milestones.TotalCost, 1e-9)
+	assert.Equal(t, Streak{Days: 8, Start: StartOfDay(day(1), time.UTC), End: StartOfDay(day(8), time.UTC)}, milestones.LongestStreak)
+	assert.Equal(t, 2, milestones.CurrentStreak.Days)
+	assert.Equal(t, StartOfDay(day(18), time.UTC), milestones.BusiestDay.Date)
+
+	badges := make(map[string]Badge)
+	for _, badge := range milestones.Badges {
+		badges[badge.Name] = badge
+	}
+	require.Contains(t, badges, "1B tokens")
+	assert.False(t, badges["1B tokens"].Reached)
+	assert.True(t, badges["1M tokens"].Reached)
+	assert.Equal(t, StartOfDay(day(5), time.UTC), badges["1M tokens"].ReachedOn)
+	assert.Equal(t, StartOfDay(day(18), time.UTC), badges["$10 spent"].ReachedOn)
+	assert.Equal(t, StartOfDay(day(7), time.UTC), badges["7-day streak"].ReachedOn)
+	assert.False(t, badges["30-day streak"].Reached)
+
+	next, progress, ok := milestones.NextBadge()
+	require.True(t, ok)
+	assert.Equal(t, "30-day streak", next.Name)
+	assert.InDelta(t, 8.0/30, progress, 1e-9)
+
+	// A streak that ended before yesterday is not current
+	later := ComputeMilestones(results, now.AddDate(0, 0, 2), time.UTC)
+	assert.Zero(t, later.CurrentStreak.Days)
+	assert.Zero(t, ComputeMilestones(nil, now, time.UTC).ActiveDays)
+}
@@ -0,0 +1,229 @@
+package calculations
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+)
+
+// Percentiles holds the 50th, 75th, 90th and 99th percentiles of a distribution
+type Percentiles struct {
+	P50 float64 `json:"p50"`
+	P75 float64 `json:"p75"`
+	P90 float64 `json:"p90"`
+	P99 float64 `json:"p99"`
+}
+
+// NewPercentiles computes the percentiles of values; values is not modified
+func NewPercentiles(values []float64) Percentiles {
+	if len(values) == 0 {
+		return Percentiles{}
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	return Percentiles{
+		P50: percentileOf(sorted, 0.50),
+		P75: percentileOf(sorted, 0.75),
+		P90: percentileOf(sorted, 0.90),
+		P99: percentileOf(sorted, 0.99),
+	}
+}
+
+// percentileOf returns the value at fraction q of sorted values, using the index floor(n*q)
+func percentileOf(sorted []float64, q float64) float64 {
+	index := int(float64(len(sorted)) * q)
+	if index >= len(sorted) {
+		index = len(sorted) - 1
+	}
+	return sorted[index]
+}
+
+// SessionPercentiles is the distribution of per-session usage over completed sessions
+type SessionPercentiles struct {
+	Sessions      int         `json:"sessions"`
+	LimitSessions int         `json:"limit_sessions"` // Sessions with a limit message in the logs
+	Tokens        Percentiles `json:"tokens"`
+	Cost          Percentiles `json:"cost"`
+	Messages      Percentiles `json:"messages"`
+}
+
+// sessionSample is the usage of one completed session
+type sessionSample struct {
+	tokens   float64
+	cost     float64
+	messages float64
+	limited  bool
+}
+
+// completedSessions returns the usage of blocks that are neither gaps nor still active
+func completedSessions(blocks []models.SessionBlock) []sessionSample {
+	var samples []sessionSample
+	for _, block := range blocks {
+		if block.IsGap || block.IsActive {
+			continue
+		}
+		tokens := block.TotalTokens
+		if tokens == 0 {
+			tokens = block.TokenCounts.TotalTokens()
+		}
+		if tokens == 0 && block.CostUSD == 0 {
+			continue
+		}
+		samples = append(samples, sessionSample{
+			tokens:   float64(tokens),
+			cost:     block.CostUSD,
+			messages: float64(block.SentMessagesCount),
+			limited:  len(block.LimitMessages) > 0,
+		})
+	}
+	return samples
+}
+
+// sessionPercentiles computes the percentiles of the given session samples
+func sessionPercentiles(samples []sessionSample) SessionPercentiles {
+	stats := SessionPercentiles{Sessions: len(samples)}
+	tokens := make([]float64, 0, len(samples))
+	costs := make([]float64, 0, len(samples))
+	messages := make([]float64, 0, len(samples))
+	for _, sample := range samples {
+		tokens = append(tokens, sample.tokens)
+		costs = append(costs, sample.cost)
+		messages = append(messages, sample.messages)
+		if sample.limited {
+			stats.LimitSessions++
+		}
+	}
+	stats.Tokens = NewPercentiles(tokens)
+	stats.Cost = NewPercentiles(costs)
+	stats.Messages = NewPercentiles(messages)
+	return stats
+}
+
+// SessionPercentiles computes per-session token, cost and message percentiles over completed sessions
+func (sa *StatsAggregator) SessionPercentiles(blocks []models.SessionBlock) SessionPercentiles {
+	return sessionPercentiles(completedSessions(blocks))
+}
+
+// CustomLimitConfig configures how custom plan limits are estimated from session history
+type CustomLimitConfig struct {
+	CommonLimits    []int         // Token limits of the known plans
+	LimitThreshold  float64       // Fraction of a known limit at which a session counts as having hit it
+	MinSessions     int           // Below this many sessions the P99 is used instead of the P90
+	DefaultTokens   int           // Token limit floor, also used without history
+	DefaultCost     float64       // Cost limit used without history
+	DefaultMessages int           // Message limit used without history
+	CacheTTL        time.Duration // How long an estimate is reused
+}
+
+// DefaultCustomLimitConfig returns the default custom limit configuration
+func DefaultCustomLimitConfig() CustomLimitConfig {
+	return CustomLimitConfig{
+		CommonLimits:    []int{1000000, 2000000, 8000000}, // Pro: 1M, Max5: 2M, Max20: 8M
+		LimitThreshold:  0.95,
+		MinSessions:     10,
+		DefaultTokens:   1000000,
+		DefaultCost:     100.0,
+		DefaultMessages: 150,
+		CacheTTL:        time.Hour,
+	}
+}
+
+// CustomLimitEstimator estimates custom plan limits from session percentiles
+type CustomLimitEstimator struct {
+	config CustomLimitConfig
+
+	mu        sync.Mutex
+	cached    models.PlanLimits
+	expiresAt time.Time
+}
+
+// NewCustomLimitEstimator creates an estimator with the default configuration
+func NewCustomLimitEstimator() *CustomLimitEstimator {
+	return NewCustomLimitEstimatorWithConfig(DefaultCustomLimitConfig())
+}
+
+// NewCustomLimitEstimatorWithConfig creates an estimator with a custom configuration
+func NewCustomLimitEstimatorWithConfig(config CustomLimitConfig) *CustomLimitEstimator {
+	return &CustomLimitEstimator{config: config}
+}
+
+// Estimate returns the limits estimated from blocks, reusing the previous estimate until it expires
+func (e *CustomLimitEstimator) Estimate(blocks []models.SessionBlock, now time.Time) models.PlanLimits {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if now.Before(e.expiresAt) {
+		return e.cached
+	}
+	e.cached = e.EstimateLimits(blocks)
+	e.expiresAt = now.Add(e.config.CacheTTL)
+	return e.cached
+}
+
+// EstimateLimits estimates limits without caching. Sessions that ended with a limit message show where
+// the limit actually is, so their median is used; otherwise sessions close to a known plan limit and
+// finally all sessions are used, taking the P99 instead of the P90 when there are few sessions.
+func (e *CustomLimitEstimator) EstimateLimits(blocks []models.SessionBlock) models.PlanLimits {
+	limits := models.PlanLimits{
+		TokenLimit:   e.config.DefaultTokens,
+		CostLimit:    e.config.DefaultCost,
+		MessageLimit: e.config.DefaultMessages,
+	}
+	samples := completedSessions(blocks)
+	if len(samples) == 0 {
+		return limits
+	}
+
+	var limited, nearLimit []sessionSample
+	for _, sample := range samples {
+		if sample.limited {
+			limited = append(limited, sample)
+		}
+		if e.nearKnownLimit(sample.tokens) {
+			nearLimit = append(nearLimit, sample)
+		}
+	}
+
+	var pick func(Percentiles) float64
+	switch {
+	case len(limited) > 0:
+		samples = limited
+		pick = func(p Percentiles) float64 { return p.P50 }
+	case len(nearLimit) > 0:
+		samples = nearLimit
+		pick = e.tail(len(nearLimit))
+	default:
+		pick = e.tail(len(samples))
+	}
+	stats := sessionPercentiles(samples)
+
+	if tokens := int(pick(stats.Tokens)); tokens > limits.TokenLimit {
+		limits.TokenLimit = tokens
+	}
+	if cost := pick(stats.Cost); cost > 0 {
+		limits.CostLimit = cost
+	}
+	if messages := int(pick(stats.Messages)); messages > 0 {
+		limits.MessageLimit = messages
+	}
+	return limits
+}
+
+// tail returns the percentile used for a sample of n sessions
+func (e *CustomLimitEstimator) tail(n int) func(Percentiles) float64 {
+	if n < e.config.MinSessions {
+		return func(p Percentiles) float64 { return p.P99 }
+	}
+	return func(p Percentiles) float64 { return p.P90 }
+}
+
+// nearKnownLimit reports whether a session's tokens came within the threshold of a known plan limit
+func (e *CustomLimitEstimator) nearKnownLimit(tokens float64) bool {
+	for _, limit := range e.config.CommonLimits {
+		if tokens >= float64(limit)*e.config.LimitThreshold {
+			return true
+		}
+	}
+	return false
+}
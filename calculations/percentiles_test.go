@@ -0,0 +1,80 @@
+package calculations
+
+import (
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPercentiles(t *testing.T) {
+	values := make([]float64, 0, 100)
+	for i := 100; i >= 1; i-- {
+		values = append(values, float64(i))
+	}
+
+	p := NewPercentiles(values)
+	assert.Equal(t, Percentiles{P50: 51, P75: 76, P90: 91, P99: 100}, p)
+	assert.Equal(t, 100.0, values[0], "input is not reordered")
+	assert.Equal(t, Percentiles{}, NewPercentiles(nil))
+}
+
+func TestStatsAggregator_SessionPercentiles(t *testing.T) {
+	blocks := []models.SessionBlock{
+		{TotalTokens: 1000, CostUSD: 1, SentMessagesCount: 10},
+		{TotalTokens: 3000, CostUSD: 3, SentMessagesCount: 30, LimitMessages: []models.LimitMessage{{Type: "general_limit"}}},
+		{TokenCounts: models.TokenCounts{InputTokens: 2000}, CostUSD: 2, SentMessagesCount: 20},
+		{IsGap: true},
+		{IsActive: true, TotalTokens: 99999, CostUSD: 99},
+	}
+
+	stats := NewStatsAggregator(time.UTC).SessionPercentiles(blocks)
+	assert.Equal(t, 3, stats.Sessions)
+	assert.Equal(t, 1, stats.LimitSessions)
+	assert.Equal(t, 2000.0, stats.Tokens.P50)
+	assert.Equal(t, 3000.0, stats.Tokens.P99)
+	assert.Equal(t, 2.0, stats.Cost.P50)
+	assert.Equal(t, 30.0, stats.Messages.P90)
+}
+
+func TestCustomLimitEstimator(t *testing.T) {
+	estimator := NewCustomLimitEstimator()
+	config := DefaultCustomLimitConfig()
+
+	// Without history the defaults apply
+	limits := estimator.EstimateLimits(nil)
+	assert.Equal(t, models.PlanLimits{TokenLimit: config.DefaultTokens, CostLimit: config.DefaultCost, MessageLimit: config.DefaultMessages}, limits)
+
+	// With few sessions the P99 is used so the estimate is not below most sessions
+	var blocks []models.SessionBlock
+	for i := 1; i <= 5; i++ {
+		blocks = append(blocks, models.SessionBlock{TotalTokens: i * 1000, CostUSD: float64(i), SentMessagesCount: i * 10})
+	}
+	limits = estimator.EstimateLimits(blocks)
+	assert.Equal(t, config.DefaultTokens, limits.TokenLimit, "token limit never drops below the floor")
+	assert.Equal(t, 5.0, limits.CostLimit)
+	assert.Equal(t, 50, limits.MessageLimit)
+
+	// Sessions close to a known plan limit take precedence over the rest
+	blocks = append(blocks, models.SessionBlock{TotalTokens: 1_960_000, CostUSD: 40, SentMessagesCount: 200})
+	limits = estimator.EstimateLimits(blocks)
+	assert.Equal(t, 1_960_000, limits.TokenLimit)
+	assert.Equal(t, 40.0, limits.CostLimit)
+
+	// Sessions that ended with a limit message show the actual limit
+	limited := []models.LimitMessage{{Type: "general_limit"}}
+	blocks = append(blocks,
+		models.SessionBlock{TotalTokens: 1_200_000, CostUSD: 20, SentMessagesCount: 120, LimitMessages: limited},
+		models.SessionBlock{TotalTokens: 1_400_000, CostUSD: 24, SentMessagesCount: 140, LimitMessages: limited},
+		models.SessionBlock{TotalTokens: 1_300_000, CostUSD: 22, SentMessagesCount: 130, LimitMessages: limited},
+	)
+	limits = estimator.EstimateLimits(blocks)
+	assert.Equal(t, models.PlanLimits{TokenLimit: 1_300_000, CostLimit: 22, MessageLimit: 130}, limits)
+
+	// Estimates are reused until the cache expires
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	first := estimator.Estimate(blocks, now)
+	assert.Equal(t, first, estimator.Estimate(nil, now.Add(time.Minute)))
+	assert.Equal(t, config.DefaultCost, estimator.Estimate(nil, now.Add(config.CacheTTL)).CostLimit)
+}
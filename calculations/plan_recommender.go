@@ -0,0 +1,150 @@
+package calculations
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+)
+
+// Recommendation actions
+const (
+	PlanActionKeep      = "keep"
+	PlanActionUpgrade   = "upgrade"
+	PlanActionDowngrade = "downgrade"
+	PlanActionSwitch    = "switch" // Current plan has no known price
+)
+
+// DefaultRecommendationWindow is the period of usage a plan recommendation is based on
+const DefaultRecommendationWindow = 30 * 24 * time.Hour
+
+// DefaultAcceptableCollisions is the number of limit hits per window a plan may cause and still fit
+const DefaultAcceptableCollisions = 2
+
+// recommendablePlans lists the plans considered, cheapest first
+var recommendablePlans = []string{models.PlanPro, models.PlanMax5, models.PlanMax20}
+
+// PlanFit describes how a single plan would have handled the analyzed usage
+type PlanFit struct {
+	Plan         string  `json:"plan"`
+	MonthlyPrice float64 `json:"monthly_price"`
+	SessionLimit float64 `json:"session_cost_limit"`
+	Collisions   int     `json:"collisions"`
+	Fits         bool    `json:"fits"`
+}
+
+// PlanRecommendation is the result of comparing actual usage against each plan
+type PlanRecommendation struct {
+	WindowStart      time.Time `json:"window_start"`
+	WindowEnd        time.Time `json:"window_end"`
+	Sessions         int       `json:"sessions"`
+	APICost          float64   `json:"api_cost"`
+	CurrentPlan      string    `json:"current_plan"`
+	RecommendedPlan  string    `json:"recommended_plan"`
+	Action           string    `json:"action"`
+	ProjectedSavings float64   `json:"projected_savings"` // Monthly; negative when upgrading costs more
+	Reason           string    `json:"reason"`
+	Plans            []PlanFit `json:"plans"`
+}
+
+// PlanRecommender recommends the cheapest plan whose session limits the usage would rarely hit
+type PlanRecommender struct {
+	limits               map[string]models.PlanLimits
+	window               time.Duration
+	acceptableCollisions int
+}
+
+// NewPlanRecommender creates a recommender; limits override the built-in per-plan limits and may be nil
+func NewPlanRecommender(limits map[string]models.PlanLimits) *PlanRecommender {
+	return &PlanRecommender{
+		limits:               limits,
+		window:               DefaultRecommendationWindow,
+		acceptableCollisions: DefaultAcceptableCollisions,
+	}
+}
+
+// Recommend analyzes the sessions that started in the window ending at now.
+// A collision is a session whose cost exceeds the plan's session limit; for the current plan,
+// sessions with a recorded limit message also count.
+func (r *PlanRecommender) Recommend(currentPlan string, blocks []models.SessionBlock, now time.Time) PlanRecommendation {
+	rec := PlanRecommendation{
+		WindowStart: now.Add(-r.window),
+		WindowEnd:   now,
+		CurrentPlan: currentPlan,
+	}
+
+	var sessions []models.SessionBlock
+	for _, block := range blocks {
+		if block.IsGap || block.StartTime.Before(rec.WindowStart) || block.StartTime.After(now) {
+			continue
+		}
+		sessions = append(sessions, block)
+		rec.APICost += block.CostUSD
+	}
+	rec.Sessions = len(sessions)
+
+	for _, plan := range recommendablePlans {
+		limit := r.planLimits(plan).CostLimit
+		fit := PlanFit{
+			Plan:         plan,
+			MonthlyPrice: models.GetPlanMonthlyPrice(plan),
+			SessionLimit: limit,
+		}
+		for _, session := range sessions {
+			if session.CostUSD > limit || (plan == currentPlan && len(session.LimitMessages) > 0) {
+				fit.Collisions++
+			}
+		}
+		fit.Fits = fit.Collisions <= r.acceptableCollisions
+		rec.Plans = append(rec.Plans, fit)
+
+		if fit.Fits && rec.RecommendedPlan == "" {
+			rec.RecommendedPlan = plan
+		}
+	}
+	if rec.RecommendedPlan == "" {
+		rec.RecommendedPlan = recommendablePlans[len(recommendablePlans)-1]
+	}
+
+	currentPrice := models.GetPlanMonthlyPrice(currentPlan)
+	recommendedPrice := models.GetPlanMonthlyPrice(rec.RecommendedPlan)
+	rec.ProjectedSavings = currentPrice - recommendedPrice
+
+	switch {
+	case rec.RecommendedPlan == currentPlan:
+		rec.Action = PlanActionKeep
+		rec.Reason = fmt.Sprintf("%s fits your usage", currentPlan)
+	case currentPrice == 0:
+		rec.Action = PlanActionSwitch
+		rec.ProjectedSavings = 0
+		rec.Reason = fmt.Sprintf("%s is the cheapest plan that fits your usage", rec.RecommendedPlan)
+	case recommendedPrice < currentPrice:
+		rec.Action = PlanActionDowngrade
+		rec.Reason = fmt.Sprintf("%s would have hit its session limit %d time(s) in %d sessions",
+			rec.RecommendedPlan, r.fitFor(rec, rec.RecommendedPlan).Collisions, rec.Sessions)
+	default:
+		rec.Action = PlanActionUpgrade
+		rec.Reason = fmt.Sprintf("%s hit its session limit %d time(s) in %d sessions",
+			currentPlan, r.fitFor(rec, currentPlan).Collisions, rec.Sessions)
+	}
+
+	return rec
+}
+
+// planLimits returns the session limits for a plan, preferring overrides
+func (r *PlanRecommender) planLimits(plan string) models.PlanLimits {
+	if limits, ok := r.limits[plan]; ok {
+		return limits
+	}
+	return models.GetPlanLimits(plan)
+}
+
+// fitFor returns the evaluated fit for a plan
+func (r *PlanRecommender) fitFor(rec PlanRecommendation, plan string) PlanFit {
+	for _, fit := range rec.Plans {
+		if fit.Plan == plan {
+			return fit
+		}
+	}
+	return PlanFit{Plan: plan}
+}
@@ -0,0 +1,73 @@
+package calculations
+
+import (
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sessionsWithCosts(now time.Time, costs ...float64) []models.SessionBlock {
+	blocks := make([]models.SessionBlock, len(costs))
+	for i, cost := range costs {
+		start := now.Add(-time.Duration(i+1) * 24 * time.Hour)
+		blocks[i] = models.SessionBlock{StartTime: start, EndTime: start.Add(5 * time.Hour), CostUSD: cost}
+	}
+	return blocks
+}
+
+func TestPlanRecommender_Downgrade(t *testing.T) {
+	now := time.Date(2025, 3, 31, 12, 0, 0, 0, time.UTC)
+	blocks := sessionsWithCosts(now, 5, 10, 12, 20)
+
+	rec := NewPlanRecommender(nil).Recommend(models.PlanMax20, blocks, now)
+
+	assert.Equal(t, 4, rec.Sessions)
+	assert.InDelta(t, 47.0, rec.APICost, 0.001)
+	assert.Equal(t, models.PlanPro, rec.RecommendedPlan)
+	assert.Equal(t, PlanActionDowngrade, rec.Action)
+	assert.InDelta(t, 180.0, rec.ProjectedSavings, 0.001)
+
+	require.Len(t, rec.Plans, 3)
+	assert.Equal(t, 1, rec.Plans[0].Collisions)
+	assert.True(t, rec.Plans[0].Fits)
+}
+
+func TestPlanRecommender_UpgradeOnCollisions(t *testing.T) {
+	now := time.Date(2025, 3, 31, 12, 0, 0, 0, time.UTC)
+	blocks := sessionsWithCosts(now, 30, 30, 30, 30)
+	// A recorded limit message counts against the current plan even below its cost limit
+	blocks = append(blocks, models.SessionBlock{
+		StartTime:     now.Add(-time.Hour),
+		CostUSD:       1,
+		LimitMessages: []models.LimitMessage{{Type: "general_limit"}},
+	})
+
+	rec := NewPlanRecommender(nil).Recommend(models.PlanPro, blocks, now)
+
+	assert.Equal(t, 5, rec.Plans[0].Collisions)
+	assert.Equal(t, models.PlanMax5, rec.RecommendedPlan)
+	assert.Equal(t, PlanActionUpgrade, rec.Action)
+	assert.InDelta(t, -80.0, rec.ProjectedSavings, 0.001)
+}
+
+func TestPlanRecommender_KeepAndWindow(t *testing.T) {
+	now := time.Date(2025, 3, 31, 12, 0, 0, 0, time.UTC)
+	blocks := sessionsWithCosts(now, 40, 40, 40)
+	// Sessions outside the window and gaps are ignored
+	blocks = append(blocks,
+		models.SessionBlock{StartTime: now.AddDate(0, -2, 0), CostUSD: 500},
+		models.SessionBlock{StartTime: now.Add(-2 * time.Hour), CostUSD: 500, IsGap: true},
+	)
+
+	limits := map[string]models.PlanLimits{models.PlanMax5: {CostLimit: 50}}
+	rec := NewPlanRecommender(limits).Recommend(models.PlanMax5, blocks, now)
+
+	assert.Equal(t, 3, rec.Sessions)
+	assert.Equal(t, models.PlanMax5, rec.RecommendedPlan)
+	assert.Equal(t, PlanActionKeep, rec.Action)
+	assert.Zero(t, rec.ProjectedSavings)
+	assert.Equal(t, 50.0, rec.Plans[1].SessionLimit)
+}
@@ -0,0 +1,121 @@
+package calculations
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+)
+
+// ProjectSortKeys are the accepted sort orders of a project breakdown; all but name sort descending
+var ProjectSortKeys = []string{"cost", "tokens", "sessions", "entries", "last", "name"}
+
+// unknownProject is the name under which entries without a project are reported
+const unknownProject = "(unknown)"
+
+// ProjectUsage is the total usage of one project
+type ProjectUsage struct {
+	Project             string    `json:"project"`
+	Entries             int       `json:"entries"`
+	Sessions            int       `json:"sessions"` // 5-hour sessions with activity in the project
+	InputTokens         int       `json:"input_tokens"`
+	OutputTokens        int       `json:"output_tokens"`
+	CacheCreationTokens int       `json:"cache_creation_tokens"`
+	CacheReadTokens     int       `json:"cache_read_tokens"`
+	TotalTokens         int       `json:"total_tokens"`
+	CostUSD             float64   `json:"cost_usd"`
+	CostShare           float64   `json:"cost_share"` // Fraction of the cost of all projects
+	Models              []string  `json:"models"`
+	FirstUsage          time.Time `json:"first_usage"`
+	LastUsage           time.Time `json:"last_usage"`
+}
+
+// BuildProjectBreakdown totals results per project, sorted by cost
+func BuildProjectBreakdown(results []models.AnalysisResult) []ProjectUsage {
+	byProject := make(map[string]*ProjectUsage)
+	sessions := make(map[string]map[string]bool)
+	modelSets := make(map[string]map[string]bool)
+	totalCost := 0.0
+	for _, result := range results {
+		name := result.Project
+		if name == "" {
+			name = unknownProject
+		}
+		project, ok := byProject[name]
+		if !ok {
+			project = &ProjectUsage{Project: name, FirstUsage: result.Timestamp, LastUsage: result.Timestamp}
+			byProject[name] = project
+			sessions[name] = make(map[string]bool)
+			modelSets[name] = make(map[string]bool)
+		}
+		project.Entries += result.Count
+		project.InputTokens += result.InputTokens
+		project.OutputTokens += result.OutputTokens
+		project.CacheCreationTokens += result.CacheCreationTokens
+		project.CacheReadTokens += result.CacheReadTokens
+		project.TotalTokens += result.TotalTokens
+		project.CostUSD += result.CostUSD
+		totalCost += result.CostUSD
+		if result.Timestamp.Before(project.FirstUsage) {
+			project.FirstUsage = result.Timestamp
+		}
+		if result.Timestamp.After(project.LastUsage) {
+			project.LastUsage = result.Timestamp
+		}
+		if result.SessionID != "" {
+			sessions[name][result.SessionID] = true
+		}
+		if result.Model != "" && !modelSets[name][result.Model] {
+			modelSets[name][result.Model] = true
+			project.Models = append(project.Models, result.Model)
+		}
+	}
+
+	breakdown := make([]ProjectUsage, 0, len(byProject))
+	for name, project := range byProject {
+		project.Sessions = len(sessions[name])
+		if totalCost > 0 {
+			project.CostShare = project.CostUSD / totalCost
+		}
+		if project.Models == nil {
+			project.Models = []string{}
+		}
+		sort.Strings(project.Models)
+		breakdown = append(breakdown, *project)
+	}
+	_ = SortProjects(breakdown, "cost")
+	return breakdown
+}
+
+// SortProjects orders a breakdown by one of ProjectSortKeys, breaking ties by name
+func SortProjects(projects []ProjectUsage, key string) error {
+	var less func(a, b ProjectUsage) bool
+	switch strings.ToLower(key) {
+	case "cost":
+		less = func(a, b ProjectUsage) bool { return a.CostUSD > b.CostUSD }
+	case "tokens":
+		less = func(a, b ProjectUsage) bool { return a.TotalTokens > b.TotalTokens }
+	case "sessions":
+		less = func(a, b ProjectUsage) bool { return a.Sessions > b.Sessions }
+	case "entries":
+		less = func(a, b ProjectUsage) bool { return a.Entries > b.Entries }
+	case "last":
+		less = func(a, b ProjectUsage) bool { return a.LastUsage.After(b.LastUsage) }
+	case "name":
+		less = func(a, b ProjectUsage) bool { return false }
+	default:
+		return fmt.Errorf("invalid sort key: %s (valid: %s)", key, strings.Join(ProjectSortKeys, ", "))
+	}
+	sort.SliceStable(projects, func(i, j int) bool {
+		if less(projects[i], projects[j]) {
+			return true
+		}
+		if less(projects[j], projects[i]) {
+			return false
+		}
+		return strings.ToLower(projects[i].Project) < strings.ToLower(projects[j].Project)
+	})
+	return nil
+}
@@ -0,0 +1,45 @@
+package calculations
+
+import (
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildProjectBreakdown(t *testing.T) {
+	at := func(day, hour int) time.Time { return time.Date(2025, 3, day, hour, 0, 0, 0, time.UTC) }
+	results := []models.AnalysisResult{
+		{Timestamp: at(3, 9), Project: "webapp", Model: "claude-sonnet-4", SessionID: "s1", Count: 1, InputTokens: 80, OutputTokens: 20, TotalTokens: 100, CostUSD: 1},
+		{Timestamp: at(3, 10), Project: "webapp", Model: "claude-opus-4", SessionID: "s1", Count: 1, TotalTokens: 200, CostUSD: 4},
+		{Timestamp: at(5, 10), Project: "webapp", Model: "claude-sonnet-4", SessionID: "s2", Count: 1, TotalTokens: 100, CostUSD: 1},
+		{Timestamp: at(4, 12), Project: "cli", Model: "claude-sonnet-4", SessionID: "s3", Count: 1, TotalTokens: 900, CostUSD: 3},
+		{Timestamp: at(4, 13), Model: "claude-sonnet-4", SessionID: "s3", Count: 1, TotalTokens: 10, CostUSD: 1},
+	}
+
+	breakdown := BuildProjectBreakdown(results)
+	require.Len(t, breakdown, 3)
+	webapp := breakdown[0]
+	assert.Equal(t, "webapp", webapp.Project)
+	assert.Equal(t, 3, webapp.Entries)
+	assert.Equal(t, 2, webapp.Sessions)
+	assert.Equal(t, 80, webapp.InputTokens)
+	assert.Equal(t, 400, webapp.TotalTokens)
+	assert.InDelta(t, 6, webapp.CostUSD, 1e-9)
+	assert.InDelta(t, 0.6, webapp.CostShare, 1e-9)
+	assert.Equal(t, []string{"claude-opus-4", "claude-sonnet-4"}, webapp.Models)
+	assert.Equal(t, at(3, 9), webapp.FirstUsage)
+	assert.Equal(t, at(5, 10), webapp.LastUsage)
+	assert.Equal(t, "cli", breakdown[1].Project)
+	assert.Equal(t, unknownProject, breakdown[2].Project)
+
+	require.NoError(t, SortProjects(breakdown, "tokens"))
+	assert.Equal(t, "cli", breakdown[0].Project)
+	require.NoError(t, SortProjects(breakdown, "name"))
+	assert.Equal(t, []string{unknownProject, "cli", "webapp"}, []string{breakdown[0].Project, breakdown[1].Project, breakdown[2].Project})
+	require.NoError(t, SortProjects(breakdown, "LAST"))
+	assert.Equal(t, "webapp", breakdown[0].Project)
+	assert.Error(t, SortProjects(breakdown, "model"))
+}
@@ -0,0 +1,96 @@
+package calculations
+
+import (
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+)
+
+// QuickStatsVersion is the version of the QuickStats JSON, raised only on incompatible changes so
+// that scripts and prompt widgets can rely on its fields
+const QuickStatsVersion = 1
+
+// QuickStats is a snapshot of the current day, week and month and of the active session
+type QuickStats struct {
+	Version       int                  `json:"version"`
+	GeneratedAt   time.Time            `json:"generated_at"`
+	Timezone      string               `json:"timezone"`
+	Today         PeriodTotals         `json:"today"`
+	Week          PeriodTotals         `json:"week"`
+	Month         PeriodTotals         `json:"month"`
+	ActiveSession *ActiveSessionTotals `json:"active_session"` // Null without an active session
+}
+
+// PeriodTotals is the usage of a period up to the time of the snapshot
+type PeriodTotals struct {
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"` // Exclusive
+	Entries int       `json:"entries"`
+	Tokens  int       `json:"tokens"`
+	Cost    float64   `json:"cost"`
+}
+
+// ActiveSessionTotals is the usage of the session block running at the time of the snapshot
+type ActiveSessionTotals struct {
+	ID         string                  `json:"id"`
+	StartTime  time.Time               `json:"start_time"`
+	EndTime    time.Time               `json:"end_time"`
+	Entries    int                     `json:"entries"`
+	Tokens     int                     `json:"tokens"`
+	Cost       float64                 `json:"cost"`
+	Models     []string                `json:"models"`
+	TokenLimit int                     `json:"token_limit"` // Of the configured plan
+	CostLimit  float64                 `json:"cost_limit"`
+	BurnRate   *models.BurnRate        `json:"burn_rate"`  // Null in the first minute of the session
+	Projection *models.UsageProjection `json:"projection"` // At the end of the session, null with the burn rate
+}
+
+// ComputeQuickStats totals the entries of blocks recorded in the day, Monday-based week and calendar
+// month containing now in loc, up to now, and the session block running at now against limits
+func ComputeQuickStats(blocks []models.SessionBlock, limits models.PlanLimits, now time.Time, loc *time.Location) QuickStats {
+	if loc == nil {
+		loc = time.Local
+	}
+	stats := QuickStats{Version: QuickStatsVersion, GeneratedAt: now, Timezone: loc.String()}
+	today := StartOfDay(now, loc)
+	weekStart, weekEnd, _ := ReportPeriodBounds(ReportPeriodWeek, now, loc)
+	monthStart, monthEnd, _ := ReportPeriodBounds(ReportPeriodMonth, now, loc)
+	for _, period := range []struct {
+		totals     *PeriodTotals
+		start, end time.Time
+	}{
+		{&stats.Today, today, today.AddDate(0, 0, 1)},
+		{&stats.Week, weekStart, weekEnd},
+		{&stats.Month, monthStart, monthEnd},
+	} {
+		sum := sumEntries(blocks, period.start, now)
+		*period.totals = PeriodTotals{Start: period.start, End: period.end, Entries: sum.Entries, Tokens: sum.Tokens, Cost: sum.Cost}
+	}
+
+	for _, block := range blocks {
+		if block.IsGap || block.StartTime.After(now) || !block.EndTime.After(now) {
+			continue
+		}
+		block.IsActive = true
+		calculator := NewBurnRateCalculator()
+		sum := sumEntries([]models.SessionBlock{block}, block.StartTime, now)
+		stats.ActiveSession = &ActiveSessionTotals{
+			ID:         block.ID,
+			StartTime:  block.StartTime,
+			EndTime:    block.EndTime,
+			Entries:    sum.Entries,
+			Tokens:     sum.Tokens,
+			Cost:       sum.Cost,
+			Models:     block.Models,
+			TokenLimit: limits.TokenLimit,
+			CostLimit:  limits.CostLimit,
+			BurnRate:   calculator.CalculateBurnRate(block),
+			Projection: calculator.ProjectBlockUsageAt(block, now),
+		}
+		if stats.ActiveSession.Models == nil {
+			stats.ActiveSession.Models = []string{}
+		}
+		break
+	}
+	return stats
+}
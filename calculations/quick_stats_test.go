@@ -0,0 +1,63 @@
+package calculations
+
+import (
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// quickStatsBlock is a session block starting at start with one entry of cost dollars an hour in
+func quickStatsBlock(start time.Time, cost float64) models.SessionBlock {
+	entry := models.UsageEntry{Timestamp: start.Add(time.Hour), Model: models.ModelSonnet, InputTokens: int(cost * 1000), CostUSD: cost}
+	end := entry.Timestamp
+	return models.SessionBlock{
+		ID:            start.Format(time.RFC3339),
+		StartTime:     start,
+		EndTime:       start.Add(models.SessionDuration),
+		ActualEndTime: &end,
+		Entries:       []models.UsageEntry{entry},
+		TokenCounts:   models.TokenCounts{InputTokens: entry.InputTokens},
+		Models:        []string{entry.Model},
+		CostUSD:       cost,
+	}
+}
+
+func TestComputeQuickStats(t *testing.T) {
+	// Tuesday July 1, 2025: the week started in the previous month
+	now := time.Date(2025, 7, 1, 15, 0, 0, 0, time.UTC)
+	blocks := []models.SessionBlock{
+		quickStatsBlock(time.Date(2025, 6, 29, 10, 0, 0, 0, time.UTC), 10), // Previous week
+		quickStatsBlock(time.Date(2025, 6, 30, 10, 0, 0, 0, time.UTC), 4),
+		quickStatsBlock(time.Date(2025, 7, 1, 8, 0, 0, 0, time.UTC), 3),
+		{StartTime: time.Date(2025, 7, 1, 13, 0, 0, 0, time.UTC), EndTime: now, CostUSD: 99, IsGap: true},
+		quickStatsBlock(time.Date(2025, 7, 1, 13, 0, 0, 0, time.UTC), 2), // Active
+	}
+	limits := models.PlanLimits{TokenLimit: 19000, CostLimit: 18}
+
+	stats := ComputeQuickStats(blocks, limits, now, time.UTC)
+	assert.Equal(t, QuickStatsVersion, stats.Version)
+	assert.Equal(t, "UTC", stats.Timezone)
+	assert.Equal(t, PeriodTotals{Start: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2025, 7, 2, 0, 0, 0, 0, time.UTC), Entries: 2, Tokens: 5000, Cost: 5}, stats.Today)
+	assert.Equal(t, time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC), stats.Week.Start)
+	assert.InDelta(t, 9.0, stats.Week.Cost, 0.001)
+	assert.Equal(t, time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC), stats.Month.End)
+	assert.InDelta(t, 5.0, stats.Month.Cost, 0.001)
+
+	session := stats.ActiveSession
+	require.NotNil(t, session)
+	assert.Equal(t, time.Date(2025, 7, 1, 13, 0, 0, 0, time.UTC), session.StartTime)
+	assert.Equal(t, 2000, session.Tokens)
+	assert.Equal(t, []string{models.ModelSonnet}, session.Models)
+	assert.Equal(t, 19000, session.TokenLimit)
+	require.NotNil(t, session.BurnRate)
+	assert.InDelta(t, 2.0, session.BurnRate.CostPerHour, 0.001)
+	require.NotNil(t, session.Projection)
+	assert.InDelta(t, 180.0, session.Projection.RemainingMinutes, 0.001)
+
+	stats = ComputeQuickStats(blocks, limits, now.Add(5*time.Hour), time.UTC)
+	assert.Nil(t, stats.ActiveSession, "the session has ended")
+	assert.InDelta(t, 5.0, stats.Today.Cost, 0.001)
+}
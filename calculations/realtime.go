@@ -1,7 +1,10 @@
 package calculations
 
 import (
+	"math"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/penwyp/claudecat/config"
@@ -93,7 +96,15 @@ type ModelMetrics struct {
 	LastUsed   time.Time `json:"last_used"`
 }
 
+// metricsShards 写入分片数; 并发写入方分散到不同分片, 互不阻塞
+const metricsShards = 8
+
 // MetricsCalculator 指标计算引擎
+//
+// 写入方 (UpdateWithNewEntry) 只锁住轮询选中的分片并追加条目, 再尝试 (不等待) 获取主锁
+// 把各分片的待处理条目合并进 entries; 获取失败时由下一个持有主锁的调用方合并.
+// 每次计算得到的 RealtimeMetrics 为不可变快照, 通过原子指针发布,
+// 读取方可用 Snapshot 无锁读取, 不会读到写入一半的指标
 type MetricsCalculator struct {
 	mu             sync.RWMutex
 	entries        []models.UsageEntry
@@ -101,10 +112,310 @@ type MetricsCalculator struct {
 	windowSize     time.Duration // 默认5小时
 	updateInterval time.Duration // 默认10秒
 
+	// 增量累计值, 与 entries 一起由 mu 保护
+	totals metricsAccumulator
+
+	// 写入分片, 待合并条目总数记录在 pending
+	shards    [metricsShards]metricsShard
+	nextShard atomic.Uint64
+	pending   atomic.Int64
+
 	// 缓存
 	lastCalculated time.Time
 	cachedMetrics  *RealtimeMetrics
+	snapshot       atomic.Pointer[RealtimeMetrics] // 最近一次计算结果, 无锁读取
 
 	// 配置
 	config *config.Config
 }
+
+// metricsShard 尚未合并进 entries 的新条目
+type metricsShard struct {
+	mu      sync.Mutex
+	entries []models.UsageEntry
+}
+
+// metricsAccumulator 窗口内条目的累计值
+type metricsAccumulator struct {
+	tokens int
+	cost   float64
+	oldest time.Time // 窗口内最早条目的时间, 用于判断是否需要清理
+	models map[string]*ModelMetrics
+}
+
+// add 累加一个条目
+func (a *metricsAccumulator) add(entry models.UsageEntry) {
+	a.tokens += entry.TotalTokens
+	a.cost += entry.CostUSD
+	if a.oldest.IsZero() || entry.Timestamp.Before(a.oldest) {
+		a.oldest = entry.Timestamp
+	}
+
+	if a.models == nil {
+		a.models = make(map[string]*ModelMetrics)
+	}
+	model, ok := a.models[entry.Model]
+	if !ok {
+		model = &ModelMetrics{}
+		a.models[entry.Model] = model
+	}
+	model.TokenCount += entry.TotalTokens
+	model.Cost += entry.CostUSD
+	if entry.Timestamp.After(model.LastUsed) {
+		model.LastUsed = entry.Timestamp
+	}
+}
+
+// NewMetricsCalculator 创建指标计算引擎
+func NewMetricsCalculator(sessionStart time.Time, cfg *config.Config) *MetricsCalculator {
+	return &MetricsCalculator{
+		entries:        make([]models.UsageEntry, 0),
+		sessionStart:   sessionStart,
+		windowSize:     models.SessionDuration,
+		updateInterval: 10 * time.Second,
+		config:         cfg,
+	}
+}
+
+// UpdateWithNewEntry 添加新条目, 可与 Calculate 和 Snapshot 并发调用; 不会等待计算完成
+func (mc *MetricsCalculator) UpdateWithNewEntry(entry models.UsageEntry) {
+	shard := &mc.shards[mc.nextShard.Add(1)%metricsShards]
+	shard.mu.Lock()
+	shard.entries = append(shard.entries, entry)
+	mc.pending.Add(1)
+	shard.mu.Unlock()
+
+	if mc.mu.TryLock() {
+		mc.mergePending(time.Now())
+		mc.mu.Unlock()
+	}
+}
+
+// Calculate 计算实时指标; 结果在 updateInterval 内被缓存, 调用方不得修改返回值
+func (mc *MetricsCalculator) Calculate() *RealtimeMetrics {
+	mc.mu.RLock()
+	if mc.pending.Load() == 0 {
+		if metrics := mc.freshCachedMetrics(time.Now()); metrics != nil {
+			mc.mu.RUnlock()
+			return metrics
+		}
+	}
+	mc.mu.RUnlock()
+
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	now := time.Now()
+	mc.mergePending(now)
+	// 等待写锁期间可能已有其他调用方完成计算
+	if metrics := mc.freshCachedMetrics(now); metrics != nil {
+		return metrics
+	}
+
+	mc.trimExpired(now)
+	metrics := mc.calculate(now)
+	mc.cachedMetrics = metrics
+	mc.lastCalculated = now
+	mc.snapshot.Store(metrics)
+	return metrics
+}
+
+// Snapshot 无锁返回最近一次 Calculate 的结果, 尚未计算时返回 nil
+func (mc *MetricsCalculator) Snapshot() *RealtimeMetrics {
+	return mc.snapshot.Load()
+}
+
+// GetBurnRate 返回最近 window 内每分钟的 token 消耗
+func (mc *MetricsCalculator) GetBurnRate(window time.Duration) float64 {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	now := time.Now()
+	mc.mergePending(now)
+	return mc.burnRate(window, now)
+}
+
+// GetEntryCount 返回窗口内的条目数
+func (mc *MetricsCalculator) GetEntryCount() int {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.mergePending(time.Now())
+	return len(mc.entries)
+}
+
+// Reset 清空数据并开始新的会话
+func (mc *MetricsCalculator) Reset(sessionStart time.Time) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	for i := range mc.shards {
+		shard := &mc.shards[i]
+		shard.mu.Lock()
+		mc.pending.Add(-int64(len(shard.entries)))
+		shard.entries = nil
+		shard.mu.Unlock()
+	}
+	mc.sessionStart = sessionStart
+	mc.entries = make([]models.UsageEntry, 0)
+	mc.totals = metricsAccumulator{}
+	mc.cachedMetrics = nil
+	mc.lastCalculated = time.Time{}
+	mc.snapshot.Store(nil)
+}
+
+// mergePending 把各分片的待处理条目并入 entries 并使缓存失效, 调用方需持有写锁
+func (mc *MetricsCalculator) mergePending(now time.Time) {
+	if mc.pending.Load() == 0 {
+		return
+	}
+	for i := range mc.shards {
+		shard := &mc.shards[i]
+		shard.mu.Lock()
+		for _, entry := range shard.entries {
+			mc.entries = append(mc.entries, entry)
+			mc.totals.add(entry)
+		}
+		mc.pending.Add(-int64(len(shard.entries)))
+		shard.entries = shard.entries[:0]
+		shard.mu.Unlock()
+	}
+	mc.trimExpired(now)
+	mc.cachedMetrics = nil
+}
+
+// freshCachedMetrics 返回未过期的缓存, 调用方需持有锁
+func (mc *MetricsCalculator) freshCachedMetrics(now time.Time) *RealtimeMetrics {
+	if mc.cachedMetrics != nil && now.Sub(mc.lastCalculated) < mc.updateInterval {
+		return mc.cachedMetrics
+	}
+	return nil
+}
+
+// trimExpired 清理超出窗口的条目并重建累计值, 调用方需持有写锁
+func (mc *MetricsCalculator) trimExpired(now time.Time) {
+	cutoff := now.Add(-mc.windowSize)
+	if mc.totals.oldest.IsZero() || !mc.totals.oldest.Before(cutoff) {
+		return
+	}
+
+	// 重建而不是递减, 避免浮点误差累积
+	kept := make([]models.UsageEntry, 0, len(mc.entries))
+	mc.totals = metricsAccumulator{}
+	for _, entry := range mc.entries {
+		if entry.Timestamp.Before(cutoff) {
+			continue
+		}
+		kept = append(kept, entry)
+		mc.totals.add(entry)
+	}
+	mc.entries = kept
+}
+
+// effectiveStart 返回会话开始时间和最早条目中较早的一个
+func (mc *MetricsCalculator) effectiveStart() time.Time {
+	if !mc.totals.oldest.IsZero() && mc.totals.oldest.Before(mc.sessionStart) {
+		return mc.totals.oldest
+	}
+	return mc.sessionStart
+}
+
+// burnRate 计算最近 window (不超过已用时长) 内每分钟的 token 消耗, 调用方需持有锁
+func (mc *MetricsCalculator) burnRate(window time.Duration, now time.Time) float64 {
+	span := window
+	if elapsed := now.Sub(mc.effectiveStart()); elapsed < span {
+		span = elapsed
+	}
+	if span <= 0 {
+		return 0
+	}
+
+	cutoff := now.Add(-span)
+	tokens := 0
+	for _, entry := range mc.entries {
+		if !entry.Timestamp.Before(cutoff) && !entry.Timestamp.After(now) {
+			tokens += entry.TotalTokens
+		}
+	}
+	return float64(tokens) / span.Minutes()
+}
+
+// calculate 基于当前累计值生成新的指标快照, 调用方需持有写锁
+func (mc *MetricsCalculator) calculate(now time.Time) *RealtimeMetrics {
+	metrics := &RealtimeMetrics{
+		SessionStart:      mc.sessionStart,
+		SessionEnd:        mc.sessionStart.Add(mc.windowSize),
+		CurrentTokens:     mc.totals.tokens,
+		CurrentCost:       mc.totals.cost,
+		ModelDistribution: make(map[string]ModelMetrics, len(mc.totals.models)),
+	}
+
+	elapsed := now.Sub(mc.sessionStart)
+	metrics.SessionProgress = math.Max(0, math.Min(100, float64(elapsed)/float64(mc.windowSize)*100))
+	if remaining := metrics.SessionEnd.Sub(now); remaining > 0 {
+		metrics.TimeRemaining = remaining
+	}
+
+	for model, stats := range mc.totals.models {
+		modelMetrics := *stats
+		if mc.totals.tokens > 0 {
+			modelMetrics.Percentage = float64(stats.TokenCount) / float64(mc.totals.tokens) * 100
+		}
+		metrics.ModelDistribution[model] = modelMetrics
+	}
+
+	if len(mc.entries) == 0 {
+		return metrics
+	}
+
+	// 速率计算
+	activeTime := now.Sub(mc.effectiveStart())
+	if minutes := activeTime.Minutes(); minutes > 0 {
+		metrics.TokensPerMinute = float64(metrics.CurrentTokens) / minutes
+		metrics.CostPerMinute = metrics.CurrentCost / minutes
+		metrics.TokensPerHour = metrics.TokensPerMinute * 60
+		metrics.CostPerHour = metrics.CostPerMinute * 60
+		metrics.PerformanceMetrics.RequestsPerMinute = float64(len(mc.entries)) / minutes
+	}
+	metrics.BurnRate = mc.burnRate(time.Hour, now)
+
+	// 预测值
+	remainingMinutes := metrics.TimeRemaining.Minutes()
+	metrics.ProjectedTokens = metrics.CurrentTokens + int(metrics.TokensPerMinute*remainingMinutes)
+	metrics.ProjectedCost = metrics.CurrentCost + metrics.CostPerMinute*remainingMinutes
+	if limit := mc.getPlanLimit(); limit > 0 && metrics.CostPerMinute > 0 {
+		remainingBudget := math.Max(0, limit-metrics.CurrentCost)
+		metrics.PredictedEndTime = now.Add(time.Duration(remainingBudget / metrics.CostPerMinute * float64(time.Minute)))
+	}
+	// 数据点和时长越多越可信, 10 个条目和 1 小时各自达到满分
+	dataConfidence := math.Min(100, float64(len(mc.entries))/10*100)
+	timeConfidence := math.Min(100, activeTime.Minutes()/60*100)
+	metrics.ConfidenceLevel = dataConfidence*0.6 + timeConfidence*0.4
+
+	// 性能和效率指标
+	requests := len(mc.entries)
+	metrics.PerformanceMetrics.RequestCount = requests
+	metrics.PerformanceMetrics.TokensPerRequest = float64(metrics.CurrentTokens) / float64(requests)
+	metrics.EfficiencyMetrics.CostPerRequest = metrics.CurrentCost / float64(requests)
+	if metrics.CurrentTokens > 0 {
+		metrics.EfficiencyMetrics.CostPerToken = metrics.CurrentCost / float64(metrics.CurrentTokens)
+	}
+	if metrics.CurrentCost > 0 {
+		metrics.EfficiencyMetrics.TokensPerDollar = float64(metrics.CurrentTokens) / metrics.CurrentCost
+	}
+
+	return metrics
+}
+
+// getPlanLimit 返回当前订阅计划的会话费用上限, 未知计划返回 0
+func (mc *MetricsCalculator) getPlanLimit() float64 {
+	if mc.config == nil {
+		return 0
+	}
+	switch plan := strings.ToLower(mc.config.Subscription.Plan); plan {
+	case models.PlanPro, models.PlanMax5, models.PlanMax20:
+		return models.GetPlanLimits(plan).CostLimit
+	case "custom":
+		return mc.config.Subscription.CustomCostLimit
+	default:
+		return 0
+	}
+}
@@ -1,7 +1,9 @@
 package calculations
 
 import (
+	"fmt"
 	"math/rand"
+	"sync"
 	"testing"
 	"time"
 
@@ -439,6 +441,106 @@ func TestMetricsCalculator_ConcurrentAccess(t *testing.T) {
 	assert.InDelta(t, 1.0, metrics.CurrentCost, 0.01) // 100 entries * 0.01 cost (允许浮点误差)
 }
 
+func TestMetricsCalculator_ConcurrentSnapshots(t *testing.T) {
+	calc := NewMetricsCalculator(time.Now(), testConfig)
+	// 关闭缓存, 让每次 Calculate 都重新计算
+	calc.updateInterval = 0
+
+	const writers, perWriter = 8, 200
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				calc.UpdateWithNewEntry(models.UsageEntry{
+					Timestamp:   time.Now(),
+					Model:       fmt.Sprintf("model-%d", w%3),
+					TotalTokens: 100,
+					CostUSD:     0.01,
+				})
+			}
+		}(w)
+	}
+
+	// 每个快照内的 tokens, 费用和模型分布必须互相一致
+	stop := make(chan struct{})
+	var readers sync.WaitGroup
+	for r := 0; r < 4; r++ {
+		readers.Add(1)
+		go func(r int) {
+			defer readers.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				metrics := calc.Calculate()
+				if r%2 == 1 {
+					if snapshot := calc.Snapshot(); snapshot != nil {
+						metrics = snapshot
+					}
+				}
+				modelTokens := 0
+				for _, model := range metrics.ModelDistribution {
+					modelTokens += model.TokenCount
+				}
+				assert.Equal(t, metrics.CurrentTokens, modelTokens)
+				assert.InDelta(t, float64(metrics.CurrentTokens)/10000, metrics.CurrentCost, 1e-9)
+			}
+		}(r)
+	}
+
+	wg.Wait()
+	close(stop)
+	readers.Wait()
+
+	metrics := calc.Calculate()
+	assert.Equal(t, writers*perWriter, calc.GetEntryCount())
+	assert.Equal(t, writers*perWriter*100, metrics.CurrentTokens)
+	assert.Same(t, metrics, calc.Snapshot())
+}
+
+func TestMetricsCalculator_UpdateDoesNotWaitForCalculation(t *testing.T) {
+	calc := NewMetricsCalculator(time.Now(), testConfig)
+	entry := models.UsageEntry{Timestamp: time.Now(), Model: "claude-3-opus", TotalTokens: 100}
+
+	// 模拟进行中的计算: 写入方只锁分片, 条目留待下次计算合并
+	calc.mu.RLock()
+	done := make(chan struct{})
+	go func() {
+		calc.UpdateWithNewEntry(entry)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("UpdateWithNewEntry waited for the calculation")
+	}
+	assert.Equal(t, int64(1), calc.pending.Load())
+	calc.mu.RUnlock()
+
+	assert.Equal(t, 100, calc.Calculate().CurrentTokens)
+	assert.Zero(t, calc.pending.Load())
+}
+
+func TestMetricsCalculator_SnapshotReset(t *testing.T) {
+	calc := NewMetricsCalculator(time.Now(), testConfig)
+	assert.Nil(t, calc.Snapshot())
+
+	calc.UpdateWithNewEntry(models.UsageEntry{Timestamp: time.Now(), Model: "claude-3-opus", TotalTokens: 100})
+	metrics := calc.Calculate()
+	assert.Same(t, metrics, calc.Snapshot())
+
+	// 快照不随后续写入改变
+	calc.UpdateWithNewEntry(models.UsageEntry{Timestamp: time.Now(), Model: "claude-3-opus", TotalTokens: 100})
+	assert.Equal(t, 100, calc.Snapshot().CurrentTokens)
+
+	calc.Reset(time.Now())
+	assert.Nil(t, calc.Snapshot())
+}
+
 // 辅助函数
 
 // generateTestEntries 生成测试条目
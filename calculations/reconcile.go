@@ -0,0 +1,120 @@
+package calculations
+
+import (
+	"sort"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+)
+
+// Sources of usage numbers compared by Reconcile
+const (
+	SourceConsole = "console" // Reported by the Anthropic console usage export
+	SourceLocal   = "local"   // Computed by claudecat from local Claude Code logs
+)
+
+// SourceTotals is the usage attributed to one source
+type SourceTotals struct {
+	Source string  `json:"source"`
+	Tokens int     `json:"tokens"`
+	Cost   float64 `json:"cost"`
+}
+
+// ReconcileRow compares console-reported and locally-computed usage for one UTC day, and model when grouped by model
+type ReconcileRow struct {
+	Date      time.Time    `json:"date"`
+	Model     string       `json:"model,omitempty"`
+	Console   SourceTotals `json:"console"`
+	Local     SourceTotals `json:"local"`
+	CostDelta float64      `json:"cost_delta"` // Console minus local cost
+}
+
+// Reconciliation is the day-by-day comparison of console and local usage
+type Reconciliation struct {
+	Rows      []ReconcileRow `json:"rows"`
+	Console   SourceTotals   `json:"console"`
+	Local     SourceTotals   `json:"local"`
+	CostDelta float64        `json:"cost_delta"`
+}
+
+// Coverage returns the share of the console cost also found in local logs, or 0 without console cost
+func (r ReconcileRow) Coverage() float64 {
+	if r.Console.Cost <= 0 {
+		return 0
+	}
+	return r.Local.Cost / r.Console.Cost
+}
+
+// Reconcile aggregates console and local results by UTC day, the granularity of the console export,
+// and optionally by model. Local results outside the days covered by the console export are ignored.
+func Reconcile(console, local []models.AnalysisResult, byModel bool) Reconciliation {
+	type key struct {
+		day   time.Time
+		model string
+	}
+	rows := make(map[key]*ReconcileRow)
+	add := func(result models.AnalysisResult, totals func(*ReconcileRow) *SourceTotals) {
+		t := result.Timestamp.UTC()
+		k := key{day: time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)}
+		if byModel {
+			k.model = models.NormalizeModelName(result.Model)
+		}
+		row, ok := rows[k]
+		if !ok {
+			row = &ReconcileRow{
+				Date:    k.day,
+				Model:   k.model,
+				Console: SourceTotals{Source: SourceConsole},
+				Local:   SourceTotals{Source: SourceLocal},
+			}
+			rows[k] = row
+		}
+		target := totals(row)
+		target.Tokens += result.TotalTokens
+		target.Cost += result.CostUSD
+	}
+
+	var first, last time.Time
+	for _, result := range console {
+		add(result, func(row *ReconcileRow) *SourceTotals { return &row.Console })
+		if first.IsZero() || result.Timestamp.Before(first) {
+			first = result.Timestamp
+		}
+		if result.Timestamp.After(last) {
+			last = result.Timestamp
+		}
+	}
+	if len(console) > 0 {
+		first = first.UTC().Truncate(24 * time.Hour)
+		last = last.UTC().Truncate(24*time.Hour).AddDate(0, 0, 1)
+		for _, result := range local {
+			if result.Timestamp.Before(first) || !result.Timestamp.Before(last) {
+				continue
+			}
+			add(result, func(row *ReconcileRow) *SourceTotals { return &row.Local })
+		}
+	}
+
+	reconciliation := Reconciliation{
+		Rows:    make([]ReconcileRow, 0, len(rows)),
+		Console: SourceTotals{Source: SourceConsole},
+		Local:   SourceTotals{Source: SourceLocal},
+	}
+	for _, row := range rows {
+		row.CostDelta = row.Console.Cost - row.Local.Cost
+		reconciliation.Rows = append(reconciliation.Rows, *row)
+		reconciliation.Console.Tokens += row.Console.Tokens
+		reconciliation.Console.Cost += row.Console.Cost
+		reconciliation.Local.Tokens += row.Local.Tokens
+		reconciliation.Local.Cost += row.Local.Cost
+	}
+	reconciliation.CostDelta = reconciliation.Console.Cost - reconciliation.Local.Cost
+	sort.Slice(reconciliation.Rows, func(i, j int) bool {
+		a, b := reconciliation.Rows[i], reconciliation.Rows[j]
+		if !a.Date.Equal(b.Date) {
+			return a.Date.Before(b.Date)
+		}
+		return a.Model < b.Model
+	})
+	return reconciliation
+}
@@ -0,0 +1,44 @@
+package calculations
+
+import (
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcile(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 6, d, 0, 0, 0, 0, time.UTC) }
+	console := []models.AnalysisResult{
+		{Timestamp: day(1), Model: models.ModelSonnet, TotalTokens: 1000, CostUSD: 10},
+		{Timestamp: day(1), Model: models.ModelOpus, TotalTokens: 500, CostUSD: 5},
+		{Timestamp: day(2), Model: models.ModelSonnet, TotalTokens: 200, CostUSD: 2},
+	}
+	local := []models.AnalysisResult{
+		{Timestamp: day(1).Add(3 * time.Hour), Model: models.ModelSonnet, TotalTokens: 800, CostUSD: 8},
+		{Timestamp: day(2).Add(23 * time.Hour), Model: models.ModelSonnet, TotalTokens: 200, CostUSD: 2},
+		{Timestamp: day(3).Add(time.Hour), Model: models.ModelSonnet, TotalTokens: 999, CostUSD: 99}, // Outside the export
+	}
+
+	r := Reconcile(console, local, false)
+	require.Len(t, r.Rows, 2)
+	assert.Equal(t, day(1), r.Rows[0].Date)
+	assert.Equal(t, SourceConsole, r.Rows[0].Console.Source)
+	assert.Equal(t, SourceLocal, r.Rows[0].Local.Source)
+	assert.Equal(t, 1500, r.Rows[0].Console.Tokens)
+	assert.InDelta(t, 7, r.Rows[0].CostDelta, 0.0001)
+	assert.InDelta(t, 8.0/15, r.Rows[0].Coverage(), 0.0001)
+	assert.InDelta(t, 1, r.Rows[1].Coverage(), 0.0001)
+	assert.InDelta(t, 17, r.Console.Cost, 0.0001)
+	assert.InDelta(t, 10, r.Local.Cost, 0.0001)
+	assert.InDelta(t, 7, r.CostDelta, 0.0001)
+
+	byModel := Reconcile(console, local, true)
+	require.Len(t, byModel.Rows, 3)
+	assert.Equal(t, models.NormalizeModelName(models.ModelOpus), byModel.Rows[1].Model)
+	assert.Zero(t, byModel.Rows[1].Local.Cost)
+
+	assert.Empty(t, Reconcile(nil, local, false).Rows)
+}
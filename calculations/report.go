@@ -0,0 +1,193 @@
+package calculations
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+)
+
+// Report periods
+const (
+	ReportPeriodWeek  = "week"
+	ReportPeriodMonth = "month"
+)
+
+// DefaultReportSessions is the number of most expensive sessions listed in a report
+const DefaultReportSessions = 10
+
+// ReportModel is the usage of one model over a report period
+type ReportModel struct {
+	Model               string  `json:"model"`
+	Entries             int     `json:"entries"`
+	InputTokens         int     `json:"input_tokens"`
+	OutputTokens        int     `json:"output_tokens"`
+	CacheCreationTokens int     `json:"cache_creation_tokens"`
+	CacheReadTokens     int     `json:"cache_read_tokens"`
+	TotalTokens         int     `json:"total_tokens"`
+	Cost                float64 `json:"cost"`
+	Share               float64 `json:"share"` // Fraction of the period's total cost
+}
+
+// ReportDay is the usage of one local day of a report period
+type ReportDay struct {
+	Date    time.Time `json:"date"`
+	Entries int       `json:"entries"`
+	Tokens  int       `json:"tokens"`
+	Cost    float64   `json:"cost"`
+}
+
+// Report summarizes a calendar week or month of usage
+type Report struct {
+	Period      string          `json:"period"`
+	Start       time.Time       `json:"start"`
+	End         time.Time       `json:"end"` // Exclusive
+	Complete    bool            `json:"complete"`
+	TotalCost   float64         `json:"total_cost"`
+	TotalTokens int             `json:"total_tokens"`
+	Entries     int             `json:"entries"`
+	Sessions    int             `json:"sessions"`
+	ActiveDays  int             `json:"active_days"`
+	Models      []ReportModel   `json:"models"`
+	Days        []ReportDay     `json:"days"` // Every day of the period, including days without usage
+	TopSessions []DigestSession `json:"top_sessions"`
+
+	Benchmark *BenchmarkComparison `json:"benchmark,omitempty"` // Set when a comparison with typical users is requested
+	Inputs    *ReportInputs        `json:"inputs,omitempty"`    // Set for reports reproducible as of a timestamp
+}
+
+// ReportInputs records what a reproducible report was computed from, so that a regenerated report
+// can be checked against the original
+type ReportInputs struct {
+	AsOf        time.Time `json:"as_of"`
+	Pricing     string    `json:"pricing"` // Name of the frozen pricing tables
+	PricingHash string    `json:"pricing_hash"`
+	ConfigHash  string    `json:"config_hash"` // Fingerprint of the settings the report depends on
+}
+
+// ReportBuilder builds usage reports from session blocks
+type ReportBuilder struct {
+	timezone    *time.Location
+	topSessions int
+}
+
+// NewReportBuilder creates a report builder for days in timezone
+func NewReportBuilder(timezone *time.Location) *ReportBuilder {
+	if timezone == nil {
+		timezone = time.Local
+	}
+	return &ReportBuilder{timezone: timezone, topSessions: DefaultReportSessions}
+}
+
+// ReportPeriodBounds returns the Monday-based week or calendar month containing date in loc
+func ReportPeriodBounds(period string, date time.Time, loc *time.Location) (time.Time, time.Time, error) {
+	day := StartOfDay(date, loc)
+	switch strings.ToLower(period) {
+	case ReportPeriodWeek:
+		start := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		return start, start.AddDate(0, 0, 7), nil
+	case ReportPeriodMonth:
+		start := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, loc)
+		return start, start.AddDate(0, 1, 0), nil
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid report period: %s (valid: week, month)", period)
+	}
+}
+
+// Build reports the week or month containing date. Sessions are attributed to the period they start in;
+// a period that has not ended by now is marked incomplete.
+func (b *ReportBuilder) Build(period string, blocks []models.SessionBlock, date, now time.Time) (Report, error) {
+	start, end, err := ReportPeriodBounds(period, date, b.timezone)
+	if err != nil {
+		return Report{}, err
+	}
+
+	report := Report{
+		Period:      strings.ToLower(period),
+		Start:       start,
+		End:         end,
+		Complete:    !now.Before(end),
+		Models:      []ReportModel{},
+		TopSessions: []DigestSession{},
+	}
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		report.Days = append(report.Days, ReportDay{Date: day})
+	}
+
+	byModel := make(map[string]*ReportModel)
+	var sessions []DigestSession
+	for _, block := range blocks {
+		if block.IsGap {
+			continue
+		}
+		for _, entry := range block.Entries {
+			if entry.Timestamp.Before(start) || !entry.Timestamp.Before(end) {
+				continue
+			}
+			report.TotalCost += entry.CostUSD
+			report.TotalTokens += entry.TotalTokens
+			report.Entries++
+
+			day := &report.Days[dayIndex(start, StartOfDay(entry.Timestamp, b.timezone))]
+			day.Entries++
+			day.Tokens += entry.TotalTokens
+			day.Cost += entry.CostUSD
+
+			model, ok := byModel[entry.Model]
+			if !ok {
+				model = &ReportModel{Model: entry.Model}
+				byModel[entry.Model] = model
+			}
+			model.Entries++
+			model.InputTokens += entry.InputTokens
+			model.OutputTokens += entry.OutputTokens
+			model.CacheCreationTokens += entry.CacheCreationTokens
+			model.CacheReadTokens += entry.CacheReadTokens
+			model.TotalTokens += entry.TotalTokens
+			model.Cost += entry.CostUSD
+		}
+		if !block.StartTime.Before(start) && block.StartTime.Before(end) {
+			sessions = append(sessions, summarizeSession(block))
+		}
+	}
+
+	for _, day := range report.Days {
+		if day.Entries > 0 {
+			report.ActiveDays++
+		}
+	}
+	for _, model := range byModel {
+		if report.TotalCost > 0 {
+			model.Share = model.Cost / report.TotalCost
+		}
+		report.Models = append(report.Models, *model)
+	}
+	sort.Slice(report.Models, func(i, j int) bool {
+		if report.Models[i].Cost != report.Models[j].Cost {
+			return report.Models[i].Cost > report.Models[j].Cost
+		}
+		return report.Models[i].Model < report.Models[j].Model
+	})
+
+	report.Sessions = len(sessions)
+	sort.SliceStable(sessions, func(i, j int) bool {
+		if sessions[i].Cost != sessions[j].Cost {
+			return sessions[i].Cost > sessions[j].Cost
+		}
+		return sessions[i].StartTime.Before(sessions[j].StartTime)
+	})
+	if len(sessions) > b.topSessions {
+		sessions = sessions[:b.topSessions]
+	}
+	report.TopSessions = append(report.TopSessions, sessions...)
+	return report, nil
+}
+
+// dayIndex returns the number of calendar days from start to day, counting DST-shortened days as whole days
+func dayIndex(start, day time.Time) int {
+	y1, m1, d1 := start.Date()
+	y2, m2, d2 := day.Date()
+	return int(time.Date(y2, m2, d2, 0, 0, 0, 0, time.UTC).Sub(time.Date(y1, m1, d1, 0, 0, 0, 0, time.UTC)).Hours() / 24)
+}
@@ -0,0 +1,80 @@
+package calculations
+
+import (
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportPeriodBounds(t *testing.T) {
+	// Sunday June 8, 2025 belongs to the week starting Monday June 2
+	date := time.Date(2025, 6, 8, 23, 30, 0, 0, time.UTC)
+
+	start, end, err := ReportPeriodBounds(ReportPeriodWeek, date, time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2025, 6, 9, 0, 0, 0, 0, time.UTC), end)
+
+	start, end, err = ReportPeriodBounds(ReportPeriodMonth, date, time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), end)
+
+	_, _, err = ReportPeriodBounds("year", date, time.UTC)
+	assert.Error(t, err)
+}
+
+func TestReportBuilder_Week(t *testing.T) {
+	day := func(d, hour int) time.Time { return time.Date(2025, 6, d, hour, 0, 0, 0, time.UTC) }
+
+	opus := digestBlock(day(4, 10), "infra", 6)
+	opus.Entries[0].Model = models.ModelOpus
+	opus.Entries[0].OutputTokens = 400
+	blocks := []models.SessionBlock{
+		// Starts the Sunday before, only its late entry counts toward the week
+		digestBlock(day(1, 22), "api", 1),
+		digestBlock(day(2, 9), "api", 2),
+		opus,
+		digestBlock(day(4, 16), "webapp", 2),
+		digestBlock(day(9, 10), "api", 9),
+		{StartTime: day(5, 10), CostUSD: 50, IsGap: true},
+	}
+	blocks[0].Entries = append(blocks[0].Entries, models.UsageEntry{
+		Timestamp: day(2, 1), Model: models.ModelSonnet, TotalTokens: 500, CostUSD: 0.5,
+	})
+
+	now := day(6, 12)
+	report, err := NewReportBuilder(time.UTC).Build(ReportPeriodWeek, blocks, day(4, 0), now)
+	require.NoError(t, err)
+
+	assert.Equal(t, day(2, 0), report.Start)
+	assert.False(t, report.Complete)
+	assert.InDelta(t, 10.5, report.TotalCost, 0.001)
+	assert.Equal(t, 4, report.Entries)
+	assert.Equal(t, 3, report.Sessions)
+	assert.Equal(t, 2, report.ActiveDays)
+
+	require.Len(t, report.Days, 7)
+	assert.InDelta(t, 2.5, report.Days[0].Cost, 0.001)
+	assert.InDelta(t, 8.0, report.Days[2].Cost, 0.001)
+	assert.Zero(t, report.Days[6].Entries)
+
+	require.Len(t, report.Models, 2)
+	assert.Equal(t, models.ModelOpus, report.Models[0].Model)
+	assert.Equal(t, 400, report.Models[0].OutputTokens)
+	assert.InDelta(t, 6.0/10.5, report.Models[0].Share, 0.001)
+	assert.Equal(t, 3, report.Models[1].Entries)
+
+	require.Len(t, report.TopSessions, 3)
+	assert.InDelta(t, 6.0, report.TopSessions[0].Cost, 0.001)
+	assert.Equal(t, []string{"infra"}, report.TopSessions[0].Projects)
+
+	completed, err := NewReportBuilder(time.UTC).Build(ReportPeriodWeek, nil, day(4, 0), day(20, 0))
+	require.NoError(t, err)
+	assert.True(t, completed.Complete)
+	assert.Empty(t, completed.Models)
+	assert.Empty(t, completed.TopSessions)
+}
@@ -0,0 +1,137 @@
+package calculations
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/models"
+)
+
+// SmoothingMode selects the window used to compute burn and cost rates
+type SmoothingMode string
+
+const (
+	// SmoothingInstant uses the last few minutes of activity; reacts quickly but is jumpy
+	SmoothingInstant SmoothingMode = "instant"
+	// SmoothingEMA uses a 10-minute exponential moving average over per-minute usage
+	SmoothingEMA SmoothingMode = "ema"
+	// SmoothingHourly averages usage over the last hour; stable but hides spikes
+	SmoothingHourly SmoothingMode = "hourly"
+)
+
+const (
+	instantWindow = 5 * time.Minute
+	emaPeriod     = 10 // minutes
+	emaLookback   = time.Hour
+)
+
+// ParseSmoothingMode parses a smoothing mode name; an empty string selects hourly
+func ParseSmoothingMode(s string) (SmoothingMode, error) {
+	switch mode := SmoothingMode(strings.ToLower(strings.TrimSpace(s))); mode {
+	case "":
+		return SmoothingHourly, nil
+	case SmoothingInstant, SmoothingEMA, SmoothingHourly:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid smoothing mode: %s (valid: %s)", s, strings.Join(config.SmoothingModes, ", "))
+	}
+}
+
+// Label returns a short human-readable description of the mode
+func (m SmoothingMode) Label() string {
+	switch m {
+	case SmoothingInstant:
+		return "last 5m"
+	case SmoothingEMA:
+		return "10-min EMA"
+	default:
+		return "hourly"
+	}
+}
+
+// UsageRates contains smoothed consumption rates
+type UsageRates struct {
+	TokensPerMinute float64 `json:"tokens_per_minute"`
+	CostPerMinute   float64 `json:"cost_per_minute"`
+}
+
+// CalculateSmoothedRates computes token and cost rates at currentTime using the given smoothing mode
+func (brc *BurnRateCalculator) CalculateSmoothedRates(blocks []models.SessionBlock, currentTime time.Time, mode SmoothingMode) UsageRates {
+	switch mode {
+	case SmoothingInstant:
+		tokens, cost := sumEntriesInWindow(blocks, currentTime.Add(-instantWindow), currentTime)
+		minutes := instantWindow.Minutes()
+		return UsageRates{TokensPerMinute: tokens / minutes, CostPerMinute: cost / minutes}
+	case SmoothingEMA:
+		return calculateEMARates(blocks, currentTime)
+	default:
+		oneHourAgo := currentTime.Add(-time.Hour)
+		var tokens, cost float64
+		for _, block := range blocks {
+			blockTokens := brc.processBlockForBurnRate(block, oneHourAgo, currentTime)
+			if blockTokens == 0 || block.TokenCounts.TotalTokens() == 0 {
+				continue
+			}
+			tokens += blockTokens
+			// Prorate cost by the same share of the block that fell into the last hour
+			cost += block.CostUSD * blockTokens / float64(block.TokenCounts.TotalTokens())
+		}
+		return UsageRates{TokensPerMinute: tokens / 60, CostPerMinute: cost / 60}
+	}
+}
+
+// sumEntriesInWindow sums tokens and cost of entries in (from, to]
+func sumEntriesInWindow(blocks []models.SessionBlock, from, to time.Time) (float64, float64) {
+	var tokens, cost float64
+	for _, block := range blocks {
+		if block.IsGap || block.EndTime.Before(from) {
+			continue
+		}
+		for _, entry := range block.Entries {
+			if entry.Timestamp.After(from) && !entry.Timestamp.After(to) {
+				tokens += float64(entry.TotalTokens)
+				cost += entry.CostUSD
+			}
+		}
+	}
+	return tokens, cost
+}
+
+// calculateEMARates computes an exponential moving average of per-minute usage over the last hour
+func calculateEMARates(blocks []models.SessionBlock, currentTime time.Time) UsageRates {
+	minutes := int(emaLookback.Minutes())
+	tokenBuckets := make([]float64, minutes)
+	costBuckets := make([]float64, minutes)
+
+	from := currentTime.Add(-emaLookback)
+	for _, block := range blocks {
+		if block.IsGap || block.EndTime.Before(from) {
+			continue
+		}
+		for _, entry := range block.Entries {
+			if !entry.Timestamp.After(from) || entry.Timestamp.After(currentTime) {
+				continue
+			}
+			idx := int(math.Ceil(entry.Timestamp.Sub(from).Minutes())) - 1
+			if idx < 0 {
+				idx = 0
+			}
+			if idx >= minutes {
+				idx = minutes - 1
+			}
+			tokenBuckets[idx] += float64(entry.TotalTokens)
+			costBuckets[idx] += entry.CostUSD
+		}
+	}
+
+	alpha := 2.0 / float64(emaPeriod+1)
+	var rates UsageRates
+	for i := 0; i < minutes; i++ {
+		rates.TokensPerMinute = alpha*tokenBuckets[i] + (1-alpha)*rates.TokensPerMinute
+		rates.CostPerMinute = alpha*costBuckets[i] + (1-alpha)*rates.CostPerMinute
+	}
+	return rates
+}
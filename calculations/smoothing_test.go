@@ -0,0 +1,81 @@
+package calculations
+
+import (
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func smoothingTestBlocks(now time.Time) []models.SessionBlock {
+	start := now.Add(-90 * time.Minute)
+	block := models.SessionBlock{
+		StartTime: start,
+		EndTime:   start.Add(5 * time.Hour),
+		IsActive:  true,
+	}
+	// Steady 100 tokens/min for the first 89 minutes, then a 3000 token spike
+	for i := 0; i < 89; i++ {
+		block.Entries = append(block.Entries, models.UsageEntry{
+			Timestamp:   start.Add(time.Duration(i) * time.Minute),
+			TotalTokens: 100,
+			InputTokens: 100,
+			CostUSD:     0.01,
+		})
+	}
+	block.Entries = append(block.Entries, models.UsageEntry{
+		Timestamp:   now.Add(-30 * time.Second),
+		TotalTokens: 3000,
+		InputTokens: 3000,
+		CostUSD:     0.30,
+	})
+	for _, entry := range block.Entries {
+		block.TokenCounts.InputTokens += entry.InputTokens
+		block.CostUSD += entry.CostUSD
+	}
+	return []models.SessionBlock{block}
+}
+
+func TestParseSmoothingMode(t *testing.T) {
+	mode, err := ParseSmoothingMode("")
+	require.NoError(t, err)
+	assert.Equal(t, SmoothingHourly, mode)
+
+	mode, err = ParseSmoothingMode("EMA")
+	require.NoError(t, err)
+	assert.Equal(t, SmoothingEMA, mode)
+	assert.Equal(t, "10-min EMA", mode.Label())
+
+	_, err = ParseSmoothingMode("weekly")
+	assert.Error(t, err)
+}
+
+func TestCalculateSmoothedRates(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	blocks := smoothingTestBlocks(now)
+	calc := NewBurnRateCalculator()
+
+	instant := calc.CalculateSmoothedRates(blocks, now, SmoothingInstant)
+	ema := calc.CalculateSmoothedRates(blocks, now, SmoothingEMA)
+	hourly := calc.CalculateSmoothedRates(blocks, now, SmoothingHourly)
+
+	// Entries in the last 5 minutes: 3 steady entries plus the spike
+	assert.InDelta(t, 3300.0/5, instant.TokensPerMinute, 1e-9)
+	assert.InDelta(t, 0.33/5, instant.CostPerMinute, 1e-9)
+
+	// The spike dominates the instant rate, is damped by the EMA and mostly hidden hourly
+	assert.Greater(t, instant.TokensPerMinute, ema.TokensPerMinute)
+	assert.Greater(t, ema.TokensPerMinute, hourly.TokensPerMinute)
+	assert.Greater(t, hourly.TokensPerMinute, 100.0)
+	assert.Greater(t, hourly.CostPerMinute, 0.0)
+}
+
+func TestCalculateSmoothedRates_NoActivity(t *testing.T) {
+	now := time.Now()
+	calc := NewBurnRateCalculator()
+	for _, mode := range []SmoothingMode{SmoothingInstant, SmoothingEMA, SmoothingHourly} {
+		assert.Equal(t, UsageRates{}, calc.CalculateSmoothedRates(nil, now, mode))
+	}
+}
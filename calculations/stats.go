@@ -2,6 +2,8 @@ package calculations
 
 import (
 	"time"
+
+	"github.com/penwyp/claudecat/models"
 )
 
 // StatsAggregator provides flexible aggregation for various time periods
@@ -10,6 +12,97 @@ type StatsAggregator struct {
 	calculator *CostCalculator
 }
 
+// NewStatsAggregator creates a new stats aggregator for the given timezone
+func NewStatsAggregator(timezone *time.Location) *StatsAggregator {
+	if timezone == nil {
+		timezone = time.Local
+	}
+	return &StatsAggregator{
+		timezone:   timezone,
+		calculator: NewCostCalculator(),
+	}
+}
+
+// SessionBaseline represents the historical cost baseline for a weekday and time of day
+type SessionBaseline struct {
+	Weekday     time.Weekday  `json:"weekday"`
+	DayPeriod   string        `json:"day_period"`
+	Elapsed     time.Duration `json:"elapsed"`
+	AverageCost float64       `json:"average_cost"`
+	SampleCount int           `json:"sample_count"`
+}
+
+// DayPeriod returns the time-of-day bucket (morning, afternoon, evening, night) for t
+func DayPeriod(t time.Time) string {
+	switch hour := t.Hour(); {
+	case hour >= 6 && hour < 12:
+		return "morning"
+	case hour >= 12 && hour < 18:
+		return "afternoon"
+	case hour >= 18:
+		return "evening"
+	default:
+		return "night"
+	}
+}
+
+// CalculateSessionBaseline computes the average cost that past sessions starting on the same
+// weekday and time of day as current had accumulated after the same elapsed time
+func (sa *StatsAggregator) CalculateSessionBaseline(blocks []models.SessionBlock, current models.SessionBlock, now time.Time) SessionBaseline {
+	start := current.StartTime.In(sa.timezone)
+	baseline := SessionBaseline{
+		Weekday:   start.Weekday(),
+		DayPeriod: DayPeriod(start),
+		Elapsed:   now.Sub(current.StartTime),
+	}
+	if baseline.Elapsed <= 0 {
+		return baseline
+	}
+
+	totalCost := 0.0
+	for _, block := range blocks {
+		if block.IsGap || block.IsActive || block.ID == current.ID || !block.StartTime.Before(current.StartTime) {
+			continue
+		}
+
+		blockStart := block.StartTime.In(sa.timezone)
+		if blockStart.Weekday() != baseline.Weekday || DayPeriod(blockStart) != baseline.DayPeriod {
+			continue
+		}
+
+		totalCost += costWithin(block, block.StartTime.Add(baseline.Elapsed))
+		baseline.SampleCount++
+	}
+
+	if baseline.SampleCount > 0 {
+		baseline.AverageCost = totalCost / float64(baseline.SampleCount)
+	}
+	return baseline
+}
+
+// CompareToBaseline returns the percentage difference of cost relative to the baseline average
+func (b SessionBaseline) CompareToBaseline(cost float64) (float64, bool) {
+	if b.SampleCount == 0 || b.AverageCost <= 0 {
+		return 0, false
+	}
+	return (cost - b.AverageCost) / b.AverageCost * 100, true
+}
+
+// costWithin sums the cost of block entries recorded before cutoff
+func costWithin(block models.SessionBlock, cutoff time.Time) float64 {
+	if len(block.Entries) == 0 {
+		return block.CostUSD
+	}
+
+	cost := 0.0
+	for _, entry := range block.Entries {
+		if entry.Timestamp.Before(cutoff) {
+			cost += entry.CostUSD
+		}
+	}
+	return cost
+}
+
 // PeriodStats represents aggregated statistics for a time period
 type PeriodStats struct {
 	Period              string                `json:"period"`
@@ -0,0 +1,84 @@
+package calculations
+
+import (
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDayPeriod(t *testing.T) {
+	tests := []struct {
+		hour     int
+		expected string
+	}{
+		{3, "night"},
+		{6, "morning"},
+		{11, "morning"},
+		{12, "afternoon"},
+		{17, "afternoon"},
+		{18, "evening"},
+		{23, "evening"},
+	}
+
+	for _, tt := range tests {
+		ts := time.Date(2024, 1, 2, tt.hour, 0, 0, 0, time.UTC)
+		assert.Equal(t, tt.expected, DayPeriod(ts))
+	}
+}
+
+func TestStatsAggregator_CalculateSessionBaseline(t *testing.T) {
+	agg := NewStatsAggregator(time.UTC)
+	require.NotNil(t, agg)
+
+	// Tuesday afternoon sessions one and two weeks earlier
+	current := models.SessionBlock{
+		ID:        "current",
+		StartTime: time.Date(2024, 1, 16, 14, 0, 0, 0, time.UTC),
+		IsActive:  true,
+	}
+	now := current.StartTime.Add(time.Hour)
+
+	pastBlock := func(id string, start time.Time, costs ...float64) models.SessionBlock {
+		block := models.SessionBlock{ID: id, StartTime: start, EndTime: start.Add(5 * time.Hour)}
+		for i, cost := range costs {
+			block.Entries = append(block.Entries, models.UsageEntry{
+				Timestamp: start.Add(time.Duration(i*30+10) * time.Minute),
+				CostUSD:   cost,
+			})
+			block.CostUSD += cost
+		}
+		return block
+	}
+
+	blocks := []models.SessionBlock{
+		pastBlock("week1", time.Date(2024, 1, 2, 13, 0, 0, 0, time.UTC), 1.0, 1.0, 5.0),
+		pastBlock("week2", time.Date(2024, 1, 9, 15, 0, 0, 0, time.UTC), 2.0, 2.0, 5.0),
+		pastBlock("morning", time.Date(2024, 1, 9, 8, 0, 0, 0, time.UTC), 10.0),
+		pastBlock("wednesday", time.Date(2024, 1, 10, 14, 0, 0, 0, time.UTC), 10.0),
+		{ID: "gap", StartTime: time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC), IsGap: true, CostUSD: 10.0},
+		current,
+	}
+
+	baseline := agg.CalculateSessionBaseline(blocks, current, now)
+
+	assert.Equal(t, time.Tuesday, baseline.Weekday)
+	assert.Equal(t, "afternoon", baseline.DayPeriod)
+	assert.Equal(t, time.Hour, baseline.Elapsed)
+	assert.Equal(t, 2, baseline.SampleCount)
+	// Only the first two entries of each matching block fall within the first hour
+	assert.InDelta(t, 3.0, baseline.AverageCost, 0.0001)
+
+	diff, ok := baseline.CompareToBaseline(4.02)
+	assert.True(t, ok)
+	assert.InDelta(t, 34.0, diff, 0.0001)
+}
+
+func TestSessionBaseline_CompareToBaseline_NoSamples(t *testing.T) {
+	baseline := SessionBaseline{}
+
+	_, ok := baseline.CompareToBaseline(5.0)
+	assert.False(t, ok)
+}
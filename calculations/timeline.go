@@ -0,0 +1,141 @@
+package calculations
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+)
+
+// TimelineDay is the activity of one project on one calendar day
+type TimelineDay struct {
+	Date    time.Time `json:"date"`
+	Entries int       `json:"entries"`
+	Tokens  int       `json:"tokens"`
+	Cost    float64   `json:"cost"`
+}
+
+// Granularity is the span of time one point of a chart covers
+type Granularity string
+
+// Granularities chart points are rolled up to, from finest to coarsest
+const (
+	GranularityDay   Granularity = "day"
+	GranularityWeek  Granularity = "week"
+	GranularityMonth Granularity = "month"
+)
+
+// ProjectTimeline is the day-by-day activity of a project over its whole lifetime
+type ProjectTimeline struct {
+	Project    string        `json:"project"`
+	FirstUsage time.Time     `json:"first_usage"`
+	LastUsage  time.Time     `json:"last_usage"`
+	ActiveDays int           `json:"active_days"`
+	Entries    int           `json:"entries"`
+	Tokens     int           `json:"tokens"`
+	TotalCost  float64       `json:"total_cost"`
+	Days       []TimelineDay `json:"days"` // Every calendar day from first to last usage, including idle days
+}
+
+// BuildProjectTimeline collects the results of a project, matched case-insensitively, into daily buckets
+// in the given timezone. It returns false if the project has no usage.
+func BuildProjectTimeline(results []models.AnalysisResult, project string, timezone *time.Location) (ProjectTimeline, bool) {
+	if timezone == nil {
+		timezone = time.Local
+	}
+	timeline := ProjectTimeline{Project: project}
+
+	daily := make(map[time.Time]*TimelineDay)
+	for _, result := range results {
+		if !strings.EqualFold(result.Project, project) {
+			continue
+		}
+		timeline.Project = result.Project
+		if timeline.Entries == 0 || result.Timestamp.Before(timeline.FirstUsage) {
+			timeline.FirstUsage = result.Timestamp
+		}
+		if result.Timestamp.After(timeline.LastUsage) {
+			timeline.LastUsage = result.Timestamp
+		}
+
+		local := result.Timestamp.In(timezone)
+		date := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, timezone)
+		day, ok := daily[date]
+		if !ok {
+			day = &TimelineDay{Date: date}
+			daily[date] = day
+		}
+		day.Entries += result.Count
+		day.Tokens += result.TotalTokens
+		day.Cost += result.CostUSD
+
+		timeline.Entries += result.Count
+		timeline.Tokens += result.TotalTokens
+		timeline.TotalCost += result.CostUSD
+	}
+	if timeline.Entries == 0 {
+		return timeline, false
+	}
+	timeline.ActiveDays = len(daily)
+
+	first := timeline.FirstUsage.In(timezone)
+	last := timeline.LastUsage.In(timezone)
+	for date := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, timezone); !date.After(last); date = date.AddDate(0, 0, 1) {
+		if day, ok := daily[date]; ok {
+			timeline.Days = append(timeline.Days, *day)
+		} else {
+			timeline.Days = append(timeline.Days, TimelineDay{Date: date})
+		}
+	}
+	return timeline, true
+}
+
+// ProjectNames returns the distinct non-empty project names in results, sorted
+func ProjectNames(results []models.AnalysisResult) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, result := range results {
+		if result.Project != "" && !seen[result.Project] {
+			seen[result.Project] = true
+			names = append(names, result.Project)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RollupTimelineDays aggregates consecutive days into weeks starting on Monday, and into calendar months
+// if there are still more than maxPoints weeks, so that a chart of a long range fits its width.
+// Days are returned unchanged when there are at most maxPoints of them. Each bucket is dated at its start.
+func RollupTimelineDays(days []TimelineDay, maxPoints int) ([]TimelineDay, Granularity) {
+	if maxPoints <= 0 || len(days) <= maxPoints {
+		return days, GranularityDay
+	}
+
+	weekly := rollupTimeline(days, func(date time.Time) time.Time {
+		return date.AddDate(0, 0, -(int(date.Weekday())+6)%7)
+	})
+	if len(weekly) <= maxPoints {
+		return weekly, GranularityWeek
+	}
+	return rollupTimeline(days, func(date time.Time) time.Time {
+		return time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
+	}), GranularityMonth
+}
+
+// rollupTimeline sums days sharing the same bucket start, keeping the order of days
+func rollupTimeline(days []TimelineDay, bucketStart func(time.Time) time.Time) []TimelineDay {
+	var buckets []TimelineDay
+	for _, day := range days {
+		start := bucketStart(day.Date)
+		if len(buckets) == 0 || !buckets[len(buckets)-1].Date.Equal(start) {
+			buckets = append(buckets, TimelineDay{Date: start})
+		}
+		bucket := &buckets[len(buckets)-1]
+		bucket.Entries += day.Entries
+		bucket.Tokens += day.Tokens
+		bucket.Cost += day.Cost
+	}
+	return buckets
+}
@@ -0,0 +1,82 @@
+package calculations
+
+import (
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildProjectTimeline(t *testing.T) {
+	at := func(day, hour int) time.Time { return time.Date(2025, 3, day, hour, 0, 0, 0, time.UTC) }
+	results := []models.AnalysisResult{
+		{Timestamp: at(3, 9), Project: "client-a", Count: 1, TotalTokens: 100, CostUSD: 1},
+		{Timestamp: at(3, 17), Project: "client-a", Count: 1, TotalTokens: 200, CostUSD: 2},
+		{Timestamp: at(6, 23), Project: "client-a", Count: 1, TotalTokens: 50, CostUSD: 0.5},
+		{Timestamp: at(4, 12), Project: "other", Count: 1, TotalTokens: 999, CostUSD: 9},
+	}
+
+	timeline, ok := BuildProjectTimeline(results, "Client-A", time.UTC)
+	require.True(t, ok)
+	assert.Equal(t, "client-a", timeline.Project)
+	assert.Equal(t, at(3, 9), timeline.FirstUsage)
+	assert.Equal(t, at(6, 23), timeline.LastUsage)
+	assert.Equal(t, 2, timeline.ActiveDays)
+	assert.Equal(t, 3, timeline.Entries)
+	assert.Equal(t, 350, timeline.Tokens)
+	assert.InDelta(t, 3.5, timeline.TotalCost, 0.0001)
+
+	// Idle days between first and last usage are included
+	require.Len(t, timeline.Days, 4)
+	assert.Equal(t, 2, timeline.Days[0].Entries)
+	assert.Zero(t, timeline.Days[1].Entries)
+	assert.Zero(t, timeline.Days[2].Entries)
+	assert.InDelta(t, 0.5, timeline.Days[3].Cost, 0.0001)
+
+	// Days follow the requested timezone
+	tokyo := time.FixedZone("JST", 9*3600)
+	timeline, ok = BuildProjectTimeline(results, "client-a", tokyo)
+	require.True(t, ok)
+	assert.Len(t, timeline.Days, 5)
+	assert.Equal(t, 7, timeline.Days[4].Date.Day())
+
+	_, ok = BuildProjectTimeline(results, "missing", time.UTC)
+	assert.False(t, ok)
+	assert.Equal(t, []string{"client-a", "other"}, ProjectNames(results))
+}
+
+func TestRollupTimelineDays(t *testing.T) {
+	// 2025-01-01 is a Wednesday; every day costs $1
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	days := func(n int) []TimelineDay {
+		var result []TimelineDay
+		for i := 0; i < n; i++ {
+			result = append(result, TimelineDay{Date: start.AddDate(0, 0, i), Entries: 1, Tokens: 10, Cost: 1})
+		}
+		return result
+	}
+
+	// Ranges that fit are returned unchanged
+	points, granularity := RollupTimelineDays(days(30), 60)
+	assert.Equal(t, GranularityDay, granularity)
+	assert.Len(t, points, 30)
+
+	// A year of days rolls up into Monday-based weeks, the first one partial
+	points, granularity = RollupTimelineDays(days(365), 60)
+	assert.Equal(t, GranularityWeek, granularity)
+	require.Len(t, points, 53)
+	assert.Equal(t, time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC), points[0].Date)
+	assert.Equal(t, 5, points[0].Entries)
+	assert.Equal(t, time.Monday, points[1].Date.Weekday())
+	assert.InDelta(t, 7, points[1].Cost, 0.0001)
+	assert.Equal(t, 70, points[1].Tokens)
+
+	// Too many weeks roll up into calendar months
+	points, granularity = RollupTimelineDays(days(731), 60)
+	assert.Equal(t, GranularityMonth, granularity)
+	require.Len(t, points, 25)
+	assert.Equal(t, time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), points[1].Date)
+	assert.InDelta(t, 28, points[1].Cost, 0.0001)
+}
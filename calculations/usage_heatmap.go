@@ -0,0 +1,74 @@
+package calculations
+
+import "time"
+
+// HeatmapCell is the usage recorded during one hour of one day of the week
+type HeatmapCell struct {
+	Hour    int     `json:"hour"`
+	Entries int     `json:"entries"`
+	Tokens  int     `json:"tokens"`
+	Cost    float64 `json:"cost"`
+}
+
+// HeatmapDay is the usage of one day of the week, by hour of day
+type HeatmapDay struct {
+	Weekday string        `json:"weekday"`
+	Hours   []HeatmapCell `json:"hours"` // 24 cells, from midnight
+}
+
+// UsageHeatmap accumulates usage by day of week and hour of day in a timezone, showing when in the
+// week usage peaks
+type UsageHeatmap struct {
+	loc   *time.Location
+	cells [7][24]HeatmapCell // By weekday from Monday, then hour
+}
+
+// NewUsageHeatmap creates an empty heatmap with days and hours in loc
+func NewUsageHeatmap(loc *time.Location) *UsageHeatmap {
+	if loc == nil {
+		loc = time.Local
+	}
+	h := &UsageHeatmap{loc: loc}
+	for day := range h.cells {
+		for hour := range h.cells[day] {
+			h.cells[day][hour].Hour = hour
+		}
+	}
+	return h
+}
+
+// Add records an entry of tokens and cost at timestamp
+func (h *UsageHeatmap) Add(timestamp time.Time, tokens int, cost float64) {
+	local := timestamp.In(h.loc)
+	cell := &h.cells[(int(local.Weekday())+6)%7][local.Hour()]
+	cell.Entries++
+	cell.Tokens += tokens
+	cell.Cost += cost
+}
+
+// Days returns the seven days of the week from Monday with their 24 hours
+func (h *UsageHeatmap) Days() []HeatmapDay {
+	days := make([]HeatmapDay, 0, len(h.cells))
+	for day := range h.cells {
+		days = append(days, HeatmapDay{
+			Weekday: time.Weekday((day + 1) % 7).String(),
+			Hours:   append([]HeatmapCell(nil), h.cells[day][:]...),
+		})
+	}
+	return days
+}
+
+// Peak returns the weekday and cell with the most of value, and false when nothing was recorded
+func (h *UsageHeatmap) Peak(value func(HeatmapCell) float64) (string, HeatmapCell, bool) {
+	var weekday string
+	var peak HeatmapCell
+	found := false
+	for day := range h.cells {
+		for _, cell := range h.cells[day] {
+			if cell.Entries > 0 && (!found || value(cell) > value(peak)) {
+				weekday, peak, found = time.Weekday((day+1)%7).String(), cell, true
+			}
+		}
+	}
+	return weekday, peak, found
+}
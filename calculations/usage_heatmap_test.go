@@ -0,0 +1,38 @@
+package calculations
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageHeatmap(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*3600)
+	heatmap := NewUsageHeatmap(tokyo)
+	// Sunday June 1, 2025 at 23:30 UTC is Monday 08:30 in Tokyo
+	heatmap.Add(time.Date(2025, 6, 1, 23, 30, 0, 0, time.UTC), 100, 1)
+	heatmap.Add(time.Date(2025, 6, 8, 23, 10, 0, 0, time.UTC), 50, 0.5)
+	heatmap.Add(time.Date(2025, 6, 7, 5, 0, 0, 0, time.UTC), 500, 0.2) // Saturday 14:00
+
+	days := heatmap.Days()
+	require.Len(t, days, 7)
+	assert.Equal(t, "Monday", days[0].Weekday)
+	assert.Equal(t, "Sunday", days[6].Weekday)
+	require.Len(t, days[0].Hours, 24)
+	assert.Equal(t, HeatmapCell{Hour: 8, Entries: 2, Tokens: 150, Cost: 1.5}, days[0].Hours[8])
+	assert.Equal(t, 500, days[5].Hours[14].Tokens)
+	assert.Zero(t, days[6].Hours[23].Entries, "days and hours are in the heatmap's timezone")
+
+	weekday, cell, ok := heatmap.Peak(func(c HeatmapCell) float64 { return c.Cost })
+	require.True(t, ok)
+	assert.Equal(t, "Monday", weekday)
+	assert.Equal(t, 8, cell.Hour)
+	weekday, cell, _ = heatmap.Peak(func(c HeatmapCell) float64 { return float64(c.Tokens) })
+	assert.Equal(t, "Saturday", weekday)
+	assert.Equal(t, 14, cell.Hour)
+
+	_, _, ok = NewUsageHeatmap(nil).Peak(func(c HeatmapCell) float64 { return c.Cost })
+	assert.False(t, ok)
+}
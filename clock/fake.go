@@ -0,0 +1,199 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Fake is a Clock that only moves when advanced. Tickers, timers and sleepers fire in order of their
+// deadlines as Advance passes them; like the time package, a tick is dropped when the previous one
+// has not been received yet.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*fakeWaiter
+	changed chan struct{} // Closed and replaced whenever waiters are added or removed
+}
+
+// fakeWaiter is a pending ticker, timer or sleeper of a Fake clock
+type fakeWaiter struct {
+	deadline time.Time
+	period   time.Duration // Zero for timers and sleepers
+	c        chan time.Time
+}
+
+// NewFake creates a fake clock set to now
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now, changed: make(chan struct{})}
+}
+
+// Now returns the time of the clock
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Since returns the time elapsed on the clock since t
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+// Until returns the time left on the clock until t
+func (f *Fake) Until(t time.Time) time.Duration {
+	return t.Sub(f.Now())
+}
+
+// After returns a channel receiving the time once the clock has advanced by d
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	return f.NewTimer(d).C()
+}
+
+// Sleep blocks until the clock has advanced by d
+func (f *Fake) Sleep(d time.Duration) {
+	<-f.After(d)
+}
+
+// NewTicker creates a ticker firing every d of clock time
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	w := &fakeWaiter{period: d, c: make(chan time.Time, 1)}
+	f.schedule(w, d)
+	return &fakeTicker{clock: f, w: w}
+}
+
+// NewTimer creates a timer firing once the clock has advanced by d
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	w := &fakeWaiter{c: make(chan time.Time, 1)}
+	f.schedule(w, d)
+	return &fakeTimer{clock: f, w: w}
+}
+
+// Advance moves the clock forward by d, firing the tickers, timers and sleepers due on the way
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	end := f.now.Add(d)
+	for {
+		sort.SliceStable(f.waiters, func(i, j int) bool {
+			return f.waiters[i].deadline.Before(f.waiters[j].deadline)
+		})
+		if len(f.waiters) == 0 || f.waiters[0].deadline.After(end) {
+			break
+		}
+		w := f.waiters[0]
+		f.now = w.deadline
+		select {
+		case w.c <- w.deadline:
+		default:
+		}
+		if w.period > 0 {
+			w.deadline = w.deadline.Add(w.period)
+		} else {
+			f.waiters = f.waiters[1:]
+			f.notify()
+		}
+	}
+	f.now = end
+	f.mu.Unlock()
+}
+
+// Set moves the clock to t, firing what falls due if t is later than the current time
+func (f *Fake) Set(t time.Time) {
+	if d := t.Sub(f.Now()); d > 0 {
+		f.Advance(d)
+		return
+	}
+	f.mu.Lock()
+	f.now = t
+	f.mu.Unlock()
+}
+
+// Waiters returns the number of pending tickers, timers and sleepers
+func (f *Fake) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.waiters)
+}
+
+// BlockUntil waits until at least n tickers, timers and sleepers are pending, so that a goroutine
+// under test has reached the point where it waits on the clock before the test advances it
+func (f *Fake) BlockUntil(n int) {
+	for {
+		f.mu.Lock()
+		pending, changed := len(f.waiters), f.changed
+		f.mu.Unlock()
+		if pending >= n {
+			return
+		}
+		<-changed
+	}
+}
+
+// schedule adds w with a deadline d from now; d <= 0 fires it right away
+func (f *Fake) schedule(w *fakeWaiter, d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.deadline = f.now.Add(d)
+	if d <= 0 && w.period == 0 {
+		select {
+		case w.c <- f.now:
+		default:
+		}
+		return
+	}
+	f.waiters = append(f.waiters, w)
+	f.notify()
+}
+
+// remove drops w from the pending waiters and reports whether it was pending
+func (f *Fake) remove(w *fakeWaiter) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, pending := range f.waiters {
+		if pending == w {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			f.notify()
+			return true
+		}
+	}
+	return false
+}
+
+// notify wakes BlockUntil callers; f.mu must be held
+func (f *Fake) notify() {
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+// fakeTicker is a Ticker of a Fake clock
+type fakeTicker struct {
+	clock *Fake
+	w     *fakeWaiter
+}
+
+func (t *fakeTicker) C() <-chan time.Time { return t.w.c }
+func (t *fakeTicker) Stop()               { t.clock.remove(t.w) }
+
+func (t *fakeTicker) Reset(d time.Duration) {
+	t.clock.remove(t.w)
+	t.w.period = d
+	t.clock.schedule(t.w, d)
+}
+
+// fakeTimer is a Timer of a Fake clock
+type fakeTimer struct {
+	clock *Fake
+	w     *fakeWaiter
+}
+
+func (t *fakeTimer) C() <-chan time.Time { return t.w.c }
+func (t *fakeTimer) Stop() bool          { return t.clock.remove(t.w) }
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	active := t.clock.remove(t.w)
+	t.clock.schedule(t.w, d)
+	return active
+}
@@ -0,0 +1,100 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var start = time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+
+// received returns the tick waiting on c, failing if there is none
+func received(t *testing.T, c <-chan time.Time) time.Time {
+	t.Helper()
+	select {
+	case tick := <-c:
+		return tick
+	default:
+		require.Fail(t, "no tick")
+		return time.Time{}
+	}
+}
+
+// assertNoTick fails if a tick is waiting on c
+func assertNoTick(t *testing.T, c <-chan time.Time) {
+	t.Helper()
+	select {
+	case tick := <-c:
+		assert.Fail(t, "unexpected tick", "%s", tick)
+	default:
+	}
+}
+
+func TestFake_Timer(t *testing.T) {
+	clock := NewFake(start)
+	timer := clock.NewTimer(time.Minute)
+	clock.Advance(59 * time.Second)
+	assertNoTick(t, timer.C())
+
+	clock.Advance(time.Second)
+	assert.Equal(t, start.Add(time.Minute), received(t, timer.C()))
+	assert.Zero(t, clock.Waiters())
+
+	assert.False(t, timer.Reset(time.Hour), "already fired")
+	assert.True(t, timer.Stop())
+	clock.Advance(2 * time.Hour)
+	assertNoTick(t, timer.C())
+}
+
+func TestFake_Ticker(t *testing.T) {
+	clock := NewFake(start)
+	ticker := clock.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+
+	clock.Advance(10 * time.Second)
+	assert.Equal(t, start.Add(10*time.Second), received(t, ticker.C()))
+
+	// Ticks not received are dropped, as with time.Ticker
+	clock.Advance(35 * time.Second)
+	assert.Equal(t, start.Add(20*time.Second), received(t, ticker.C()))
+	assertNoTick(t, ticker.C())
+	assert.Equal(t, start.Add(45*time.Second), clock.Now())
+
+	ticker.Reset(time.Minute)
+	clock.Advance(50 * time.Second)
+	assertNoTick(t, ticker.C())
+	clock.Advance(10 * time.Second)
+	assert.Equal(t, start.Add(105*time.Second), received(t, ticker.C()))
+}
+
+func TestFake_SleepAndBlockUntil(t *testing.T) {
+	clock := NewFake(start)
+	woke := make(chan time.Time)
+	go func() {
+		clock.Sleep(time.Hour)
+		woke <- clock.Now()
+	}()
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+	assert.Equal(t, start.Add(time.Hour), <-woke)
+	assert.Equal(t, time.Hour, clock.Since(start))
+	assert.Equal(t, -time.Hour, clock.Until(start))
+}
+
+func TestFake_Set(t *testing.T) {
+	clock := NewFake(start)
+	after := clock.After(time.Hour)
+	clock.Set(start.Add(-time.Hour))
+	assertNoTick(t, after)
+	clock.Set(start.Add(time.Hour))
+	assert.Equal(t, start.Add(time.Hour), received(t, after))
+}
+
+func TestOrReal(t *testing.T) {
+	assert.Equal(t, Real, OrReal(nil))
+	fake := NewFake(start)
+	assert.Equal(t, Clock(fake), OrReal(fake))
+}
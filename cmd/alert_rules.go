@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/penwyp/claudecat/calculations"
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/internal"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes of firing alert rules, as for claudecat budget; 1 is left to errors
+const (
+	alertExitWarning = 2
+	alertExitError   = 3
+)
+
+var alertsCheckOutput string
+
+var alertsCheckCmd = &cobra.Command{
+	Use:   "check [path...]",
+	Short: "Evaluate the alert rules against the current usage",
+	Long: `Evaluate the rules in the alerts.rules section of the configuration file against the usage
+of the last 30 days and print the value and threshold of each. The monitor and the watch command
+raise the same rules as notices and events while they run.
+
+A rule is a condition "<metric> <op> <value> [for <duration>]" with an optional name and level
+(warning or error):
+
+  daily_cost, daily_tokens, weekly_cost, monthly_cost  usage of the current day, week or month
+  session_cost, session_tokens, session_messages       usage of the active session
+  session_cost_pct, session_tokens_pct                 percent of the plan's session limits used
+  burn_rate, cost_rate                                 tokens/min and USD/h over the last 5m, or the for window
+
+The value is a number such as 20, $20 or 5k, or a percentile (p50, p75, p90, p99) of the completed
+sessions or past days. For example:
+
+  alerts:
+    rules:
+      - name: daily spend
+        when: daily_cost > 20
+        level: error
+      - when: burn_rate > 5000 for 10m
+      - when: session_messages > p90
+
+The exit status is 0 when no rule fires, 2 when a warning rule fires and 3 when an error rule fires.
+claudecat assert evaluates the same conditions given on the command line.
+
+Examples:
+  claudecat alerts check
+  claudecat alerts check -o json
+  claudecat alerts check || notify-send "Claude usage alert"`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output := strings.ToLower(alertsCheckOutput)
+		if output != "table" && output != "json" {
+			return fmt.Errorf("invalid output format: %s (valid: table, json)", alertsCheckOutput)
+		}
+		cfg, err := loadCacheCommandConfig(cmd, args)
+		if err != nil {
+			return err
+		}
+		results, err := evaluateAlertRules(cfg)
+		if err != nil {
+			return err
+		}
+
+		if output == "json" {
+			data, err := sonic.MarshalIndent(results, "", "  ")
+			if err != nil {
+				return err
+			}
+			if _, err := os.Stdout.Write(append(data, '\n')); err != nil {
+				return err
+			}
+		} else {
+			printAlertRules(results)
+		}
+		return alertRulesExit(results)
+	},
+}
+
+func init() {
+	alertsCheckCmd.Flags().StringVarP(&alertsCheckOutput, "output", "o", "table", "output format (table, json)")
+	alertsCmd.AddCommand(alertsCheckCmd)
+}
+
+// evaluateAlertRules evaluates the configured alert rules at the current time
+func evaluateAlertRules(cfg *config.Config) ([]calculations.AlertRuleResult, error) {
+	rules, err := config.ParseAlertRules(cfg.Alerts.Rules)
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("no alert rules configured: add them to alerts.rules in the configuration file")
+	}
+	analyzer, err := internal.NewAnalyzer(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create analyzer: %w", err)
+	}
+	results, err := analyzer.AlertRules(cfg.Data.Paths, rules, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("alert rule check failed: %w", err)
+	}
+	firing := 0
+	for _, result := range results {
+		if result.Firing {
+			firing++
+		}
+	}
+	recordCommandResult("firing", firing)
+	return results, nil
+}
+
+// printAlertRules prints one row per rule
+func printAlertRules(results []calculations.AlertRuleResult) {
+	table := newTableFormatter([]string{"Rule", "Level", "Condition", "Value", "Threshold", "Status"})
+	for _, result := range results {
+		value, threshold, status := "-", "-", "ok"
+		switch {
+		case result.NoData:
+			status = "no data"
+		case result.Firing:
+			status = "FIRING"
+		}
+		if !result.NoData {
+			value = internal.FormatRuleValue(result.Rule.Metric, result.Value)
+			threshold = internal.FormatRuleValue(result.Rule.Metric, result.Threshold)
+		}
+		table.addRow([]string{result.Rule.Name, result.Rule.Level, result.Rule.Condition(), value, threshold, status})
+	}
+	fmt.Println(table.render())
+}
+
+// printFiringAlertRules prints the firing rules to stderr, after the output of another command
+func printFiringAlertRules(results []calculations.AlertRuleResult) {
+	for _, result := range results {
+		if result.Firing {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", strings.ToUpper(result.Rule.Level[:1])+result.Rule.Level[1:], internal.RuleNotice(result).Message)
+		}
+	}
+}
+
+// alertRulesExit returns the exit status of the most severe firing rule, or nil when none fires
+func alertRulesExit(results []calculations.AlertRuleResult) error {
+	code := 0
+	for _, result := range results {
+		switch {
+		case !result.Firing:
+		case result.Rule.Level == "error":
+			code = alertExitError
+		case code == 0:
+			code = alertExitWarning
+		}
+	}
+	switch code {
+	case alertExitError:
+		return &ExitCodeError{Code: code, Reason: "alert rule fired"}
+	case alertExitWarning:
+		return &ExitCodeError{Code: code, Reason: "alert rule warning"}
+	}
+	return nil
+}
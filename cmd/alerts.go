@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/humanize"
+	"github.com/penwyp/claudecat/internal"
+	"github.com/penwyp/claudecat/terminal"
+	"github.com/spf13/cobra"
+)
+
+var (
+	alertsSince   string
+	alertsUntil   string
+	alertsMetric  string
+	alertsSession string
+	alertsLimit   int
+	alertsOutput  string
+)
+
+var alertsCmd = &cobra.Command{
+	Use:   "alerts",
+	Short: "Review alerts and threshold crossings raised by the monitor",
+	Long: `Review the persistent log of alerts raised by the monitor: session cost thresholds,
+Claude limit messages, burn rate alarms, absence alerts and the rules of alerts.rules. Each
+record holds the time, metric, value, threshold and session, so you can see when a budget was
+exceeded. Evaluate the rules against the current usage with alerts check.`,
+}
+
+var alertsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List recorded alerts",
+	Long: `List alerts recorded by the monitor, oldest first.
+
+Metrics: session_cost (USD), limit_message (session cost in USD when reached),
+burn_rate (tokens per minute), absence_hours (hours without usage) and the metrics of
+alert rules, such as daily_cost (see alerts check).
+
+Examples:
+  claudecat alerts list                              # The 50 most recent alerts
+  claudecat alerts list --since 2025-06-01           # Alerts since June 1st
+  claudecat alerts list --since 24h                  # Alerts in the last day
+  claudecat alerts list --metric session_cost -o json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !strings.EqualFold(alertsOutput, "table") && !strings.EqualFold(alertsOutput, "json") {
+			return fmt.Errorf("invalid output format: %s (valid: table, json)", alertsOutput)
+		}
+
+		filter := internal.AlertFilter{Metric: alertsMetric, SessionID: alertsSession}
+		var err error
+		if filter.Since, err = parseAlertTime(alertsSince); err != nil {
+			return fmt.Errorf("invalid --since: %w", err)
+		}
+		if filter.Until, err = parseAlertTime(alertsUntil); err != nil {
+			return fmt.Errorf("invalid --until: %w", err)
+		}
+
+		cfg, err := loadConfiguration(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		alertLog, err := internal.OpenAlertLog(cfg)
+		if err != nil {
+			return err
+		}
+		records, err := alertLog.Read()
+		if err != nil {
+			return err
+		}
+		records = internal.FilterAlerts(records, filter)
+		if alertsLimit > 0 && len(records) > alertsLimit {
+			records = records[len(records)-alertsLimit:]
+		}
+		recordCommandResult("alerts", len(records))
+
+		if strings.EqualFold(alertsOutput, "json") {
+			if records == nil {
+				records = []internal.AlertRecord{}
+			}
+			data, err := sonic.MarshalIndent(records, "", "  ")
+			if err != nil {
+				return err
+			}
+			_, err = os.Stdout.Write(append(data, '\n'))
+			return err
+		}
+
+		if len(records) == 0 {
+			fmt.Println("No alerts recorded.")
+			return nil
+		}
+
+		table := newTableFormatter([]string{"Time", "Level", "Metric", "Value", "Threshold", "Session", "Message"})
+		for _, record := range records {
+			threshold := "-"
+			if record.Threshold > 0 {
+				threshold = formatAlertValue(record.Metric, record.Threshold)
+			}
+			session := terminal.Link(terminal.SessionURL(record.SessionID), record.SessionID)
+			if session == "" {
+				session = "-"
+			}
+			table.addRow([]string{
+				record.Time.Local().Format("2006-01-02 15:04:05"),
+				record.Level,
+				record.Metric,
+				formatAlertValue(record.Metric, record.Value),
+				threshold,
+				session,
+				record.Message,
+			})
+		}
+		fmt.Println(table.render())
+		return nil
+	},
+}
+
+func init() {
+	alertsListCmd.Flags().StringVar(&alertsSince, "since", "", "only show alerts at or after this time (YYYY-MM-DD, RFC3339 or a duration like 24h)")
+	alertsListCmd.Flags().StringVar(&alertsUntil, "until", "", "only show alerts before this time (YYYY-MM-DD, RFC3339 or a duration like 24h)")
+	alertsListCmd.Flags().StringVar(&alertsMetric, "metric", "", "only show alerts for this metric")
+	alertsListCmd.Flags().StringVar(&alertsSession, "session", "", "only show alerts for this session ID")
+	_ = alertsListCmd.RegisterFlagCompletionFunc("session", completeAlertSessions)
+	alertsListCmd.Flags().IntVarP(&alertsLimit, "limit", "n", 50, "number of most recent alerts to show (0 = all)")
+	alertsListCmd.Flags().StringVarP(&alertsOutput, "output", "o", "table", "output format (table, json)")
+
+	alertsCmd.AddCommand(alertsListCmd)
+	rootCmd.AddCommand(alertsCmd)
+}
+
+// parseAlertTime parses an absolute time or a duration before now; empty returns the zero time
+func parseAlertTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	return parseTimeString(value)
+}
+
+// formatAlertValue renders a metric value in its unit
+func formatAlertValue(metric string, value float64) string {
+	switch metric {
+	case internal.AlertMetricSessionCost, internal.AlertMetricLimitMessage:
+		return humanize.Cost(value)
+	case internal.AlertMetricBurnRate:
+		return humanize.Count(int(value)) + "/min"
+	case internal.AlertMetricAbsence:
+		return strconv.FormatFloat(value, 'f', 1, 64) + "h"
+	default:
+		if slices.Contains(config.RuleMetrics, metric) {
+			return internal.FormatRuleValue(metric, value)
+		}
+		return strconv.FormatFloat(value, 'f', 2, 64)
+	}
+}
@@ -3,9 +3,10 @@ package cmd
 import (
 	"encoding/csv"
 	"fmt"
+	"io"
 	"os"
-	"path"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -13,10 +14,15 @@ import (
 
 	"github.com/bytedance/sonic"
 	"github.com/penwyp/claudecat/cache"
+	"github.com/penwyp/claudecat/calculations"
 	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/fileio"
+	"github.com/penwyp/claudecat/humanize"
 	"github.com/penwyp/claudecat/internal"
 	"github.com/penwyp/claudecat/logging"
 	"github.com/penwyp/claudecat/models"
+	"github.com/penwyp/claudecat/sessions"
+	"github.com/penwyp/claudecat/terminal"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -32,6 +38,16 @@ var (
 	analyzeBreakdown           bool
 	analyzeReset               bool
 	analyzeEnableDeduplication bool
+	analyzeAuditCosts          bool
+	analyzeAuditTolerance      float64
+	analyzeSample              string
+	analyzeSampleRate          float64
+	analyzeProvenance          bool
+	analyzeHeat                bool
+	analyzeAlerts              bool
+	analyzeLocal               bool
+	analyzeOutputFile          string
+	analyzeAppend              bool
 )
 
 var analyzeCmd = &cobra.Command{
@@ -48,7 +64,20 @@ Examples:
   claudecat analyze --output table --by-model              # Group by model
   claudecat analyze --from 2025-01-01 --to 2025-01-31     # Date range
   claudecat analyze --format json --sort-by cost --limit 10 # Top 10 by cost
-  claudecat analyze --group-by hour --output csv > report.csv # Hourly CSV report`,
+  claudecat analyze --group-by hour --output csv > report.csv # Hourly CSV report
+  claudecat analyze --from 2025-06-01 --to 2025-06-01 -o csv --output-file usage/daily.csv --append # Add a day to a CSV log
+  claudecat analyze --from 2025-06-01 -o html > usage.html   # Standalone page with a chart per row
+  claudecat analyze --audit-costs                          # Compare logged vs calculated cost
+  claudecat analyze --sample 10%                           # Fast approximate totals from 10% of files
+  claudecat analyze --provenance --sort-by cost --limit 10 # Costliest entries with their log file and line
+  claudecat analyze --group-by tool                        # Tokens of messages calling each MCP server
+  claudecat analyze --group-by tag                         # Cost per work item tagged with claudecat tag
+  claudecat analyze --group-by block                       # One row per 5-hour session block
+  claudecat analyze --group-by day --heat                  # Daily table with outliers colored red
+  claudecat analyze --alerts                               # Also evaluate alerts.rules and exit 2 or 3 when one fires
+
+When claudecat serve is running on api.address for the same data path and its history covers --from,
+the results come from its warm data instead of the files; --local always parses the files.`,
 
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Load configuration
@@ -75,19 +104,16 @@ Examples:
 		// Reset cache if requested
 		if analyzeReset {
 			// Use file-based cache for clearing
-			cacheDir := cfg.Cache.Dir
-			if cacheDir[:2] == "~/" {
-				homeDir, _ := os.UserHomeDir()
-				cacheDir = filepath.Join(homeDir, cacheDir[2:])
-			}
-			fileCache, err := cache.NewFileBasedSummaryCache(cacheDir)
+			cacheDir := config.ExpandHome(cfg.Cache.Dir)
+			fileCache, err := cache.OpenFileBasedSummaryCache(cacheDir, cfg.Cache.Encryption)
 			if err != nil {
 				return fmt.Errorf("failed to open cache: %w", err)
 			}
+			fileCache.SetTrashTTL(cfg.Cache.TrashTTL)
 			if err := fileCache.Clear(); err != nil {
 				return fmt.Errorf("failed to clear cache: %w", err)
 			}
-			logging.GetLogger().Info("Cache cleared successfully")
+			logging.GetLogger().Info("Cache cleared successfully (restore with 'claudecat cache restore <path>')")
 		}
 
 		// Create analyzer
@@ -96,34 +122,90 @@ Examples:
 			return fmt.Errorf("failed to create analyzer: %w", err)
 		}
 
+		analyzer.SetSampleRate(analyzeSampleRate)
+		analyzer.SetIncludeSource(analyzeProvenance)
+		analyzer.SetIncludeTools(analyzeGroupBy == "tool")
+		if !analyzeLocal && analyzeFrom != "" {
+			if from, err := parseTimeString(analyzeFrom); err == nil {
+				analyzer.SetDaemon(internal.NewDaemonClient(cfg.API), from)
+			}
+		}
+
+		// Audit logged costs instead of the regular analysis if requested
+		if analyzeAuditCosts {
+			return writeOutput(analyzeOutputFile, false, func(w io.Writer, _ bool) error {
+				return runCostAudit(w, analyzer, cfg.Data.Paths)
+			})
+		}
+
 		// Perform analysis
 		results, err := analyzer.Analyze(cfg.Data.Paths)
 		if err != nil {
 			return fmt.Errorf("analysis failed: %w", err)
 		}
 
+		recordCommandResult("entries", len(results))
+
 		// Apply filtering and grouping
 		results = applyFilters(results)
+		totals := sumResults(results)
 		results = applyGrouping(results)
 		results = applySorting(results)
 		results = applyLimit(results)
+		recordCommandResult("rows", len(results))
 
 		// Output results
-		return outputAnalysisResults(results)
+		err = writeOutput(analyzeOutputFile, analyzeAppend, func(w io.Writer, appended bool) error {
+			return outputAnalysisResults(w, results, cfg.Data.Paths, appended)
+		})
+		if err != nil {
+			return err
+		}
+		if sample := analyzer.Sampling(); sample != nil {
+			printSampleEstimate(sample, totals)
+		}
+		printGuardrailWarnings(analyzer.GuardrailHits())
+		if cfg.Data.DuplicateProjects != "merge" {
+			printDuplicateProjectWarnings(analyzer.DuplicateProjects())
+		}
+
+		// Monthly table reports end with a plan rightsizing recommendation
+		if analyzeGroupBy == "month" && analyzeOutput == "table" {
+			rec, err := analyzer.RecommendPlan(cfg.Data.Paths, time.Now())
+			if err != nil {
+				logging.LogWarnf("Failed to compute plan recommendation: %v", err)
+			} else {
+				fmt.Println()
+				printPlanRecommendation(rec)
+			}
+		}
+
+		// Firing alert rules are reported after the results and set the exit status
+		if analyzeAlerts {
+			alerts, err := evaluateAlertRules(cfg)
+			if err != nil {
+				return err
+			}
+			printFiringAlertRules(alerts)
+			return alertRulesExit(alerts)
+		}
+		return nil
 	},
 }
 
 func init() {
 	// Output format flags
-	analyzeCmd.Flags().StringVarP(&analyzeOutput, "output", "o", "table", "output format (table, json, csv, summary)")
+	analyzeCmd.Flags().StringVarP(&analyzeOutput, "output", "o", "table", "output format (table, json, csv, summary, html)")
 	analyzeCmd.Flags().StringVar(&analyzeFormat, "format", "", "alias for --output")
+	analyzeCmd.Flags().StringVar(&analyzeOutputFile, "output-file", "", "write the results to this file, replacing it only once they are complete")
+	analyzeCmd.Flags().BoolVar(&analyzeAppend, "append", false, "with --output-file and --output csv, add the rows to the file instead of replacing it")
 
 	// Date range flags
 	analyzeCmd.Flags().StringVar(&analyzeFrom, "from", "", "start date (YYYY-MM-DD or YYYY-MM-DD HH:MM:SS)")
 	analyzeCmd.Flags().StringVar(&analyzeTo, "to", "", "end date (YYYY-MM-DD or YYYY-MM-DD HH:MM:SS)")
 
 	// Grouping flags
-	analyzeCmd.Flags().StringVar(&analyzeGroupBy, "group-by", "", "group by field (model, project, day, week, month)")
+	analyzeCmd.Flags().StringVar(&analyzeGroupBy, "group-by", "", "group by field (model, project, tool, session, block, tag, entry, hour, day, week, month)")
 
 	// Sorting and limiting flags
 	analyzeCmd.Flags().StringVar(&analyzeSortBy, "sort-by", "timestamp", "sort by field (timestamp, cost, tokens, model)")
@@ -135,6 +217,23 @@ func init() {
 	// Reset flag
 	analyzeCmd.Flags().BoolVarP(&analyzeReset, "reset", "r", false, "Clear cache before analysis")
 
+	// Cost audit flags
+	analyzeCmd.Flags().BoolVar(&analyzeAuditCosts, "audit-costs", false, "report discrepancies between logged costUSD and calculated cost per model")
+	analyzeCmd.Flags().Float64Var(&analyzeAuditTolerance, "audit-tolerance", calculations.DefaultCostAuditTolerance, "relative tolerance for --audit-costs (0.01 = 1%)")
+
+	// Sampling flag
+	analyzeCmd.Flags().StringVar(&analyzeSample, "sample", "", "analyze a sample of files for a fast approximate report (e.g. 10% or 0.1)")
+
+	// Provenance flag
+	analyzeCmd.Flags().BoolVar(&analyzeProvenance, "provenance", false, "record the log file and line of each entry (implies --group-by entry unless another grouping is given)")
+
+	// Conditional formatting flag
+	analyzeCmd.Flags().BoolVar(&analyzeHeat, "heat", false, "color token and cost cells of tables green, yellow or red by their percentile in the column")
+
+	// Alert rules flag
+	analyzeCmd.Flags().BoolVar(&analyzeAlerts, "alerts", false, "evaluate alerts.rules after the analysis; firing rules exit with 2 (warning) or 3 (error)")
+	analyzeCmd.Flags().BoolVar(&analyzeLocal, "local", false, "parse the files even when claudecat serve is running")
+
 	// Deduplication flag (pricing flags are now global)
 	analyzeCmd.Flags().BoolVar(&analyzeEnableDeduplication, "deduplication", false, "enable deduplication of entries across all files")
 	_ = analyzeCmd.Flags().MarkHidden("deduplication")
@@ -160,11 +259,7 @@ func applyAnalyzeFlags(cfg *config.Config, args []string) error {
 		cfg.Data.Paths = args
 	}
 
-	homeDir, _ := os.UserHomeDir()
-	if len(cfg.Data.Paths) == 0 {
-		p := path.Join(homeDir, ".claude", "projects")
-		cfg.Data.Paths = []string{p}
-	}
+	discoverDataPaths(cfg)
 
 	// Use format as alias for output if provided
 	if analyzeFormat != "" {
@@ -172,7 +267,7 @@ func applyAnalyzeFlags(cfg *config.Config, args []string) error {
 	}
 
 	// Validate output format
-	validOutputs := []string{"table", "json", "csv", "summary"}
+	validOutputs := []string{"table", "json", "csv", "summary", "html"}
 	found := false
 	for _, output := range validOutputs {
 		if strings.EqualFold(analyzeOutput, output) {
@@ -185,6 +280,9 @@ func applyAnalyzeFlags(cfg *config.Config, args []string) error {
 		return fmt.Errorf("invalid output format: %s (valid options: %s)",
 			analyzeOutput, strings.Join(validOutputs, ", "))
 	}
+	if analyzeAppend && (analyzeOutputFile == "" || analyzeOutput != "csv") {
+		return fmt.Errorf("--append requires --output-file and --output csv")
+	}
 
 	// Validate sort field
 	if analyzeSortBy != "" {
@@ -211,9 +309,136 @@ func applyAnalyzeFlags(cfg *config.Config, args []string) error {
 		cfg.Data.Deduplication = true
 	}
 
+	// Parse sample rate
+	analyzeSampleRate = 0
+	if analyzeSample != "" {
+		rate, err := parseSampleRate(analyzeSample)
+		if err != nil {
+			return err
+		}
+		analyzeSampleRate = rate
+	}
+
+	// Provenance is per entry, so it lists entries unless a grouping was asked for
+	if analyzeProvenance && analyzeGroupBy == "" {
+		analyzeGroupBy = "entry"
+	}
+
+	// Validate audit tolerance
+	if analyzeAuditTolerance < 0 || analyzeAuditTolerance > 1 {
+		return fmt.Errorf("invalid audit tolerance: %v (must be between 0 and 1)", analyzeAuditTolerance)
+	}
+
 	return nil
 }
 
+// parseSampleRate parses a sample rate given as a percentage ("10%") or a fraction ("0.1")
+func parseSampleRate(value string) (float64, error) {
+	value = strings.TrimSpace(value)
+	var rate float64
+	var err error
+	if strings.HasSuffix(value, "%") {
+		rate, err = strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+		rate /= 100
+	} else {
+		rate, err = strconv.ParseFloat(value, 64)
+	}
+	if err != nil || rate <= 0 || rate > 1 {
+		return 0, fmt.Errorf("invalid sample rate: %s (use a percentage like 10%% or a fraction in (0, 1])", value)
+	}
+	return rate, nil
+}
+
+// sumResults totals cost and tokens over ungrouped results
+func sumResults(results []models.AnalysisResult) models.AnalysisResult {
+	var total models.AnalysisResult
+	for _, result := range results {
+		total.TotalTokens += result.TotalTokens
+		total.CostUSD += result.CostUSD
+	}
+	return total
+}
+
+// printSampleEstimate reports that totals are estimates and prints their 95% confidence intervals.
+// The interval covers all sampled files, so it is conservative when --from/--to narrow the range.
+// Structured outputs get the note on stderr so they stay machine-readable.
+func printSampleEstimate(sample *fileio.SamplingStats, totals models.AnalysisResult) {
+	out := os.Stdout
+	if analyzeOutput == "json" || analyzeOutput == "csv" || analyzeOutput == "html" {
+		out = os.Stderr
+	}
+	const z95 = 1.96
+	fmt.Fprintf(out, "\nApproximate results from a %.4g%% sample (%d of %d files); totals are scaled estimates.\n",
+		sample.Rate*100, sample.FilesSampled, sample.FilesTotal)
+	fmt.Fprint(out, terminal.Text(fmt.Sprintf("  Cost:   %s ± %s (95%% CI)\n", humanize.Cost(totals.CostUSD), humanize.Cost(z95*sample.CostStdErr()))))
+	fmt.Fprint(out, terminal.Text(fmt.Sprintf("  Tokens: %s ± %s (95%% CI)\n", humanize.Count(totals.TotalTokens), humanize.Count(int(z95*sample.TokenStdErr())))))
+}
+
+// maxGuardrailWarnings is the number of oversized messages listed after an analysis
+const maxGuardrailWarnings = 10
+
+// printGuardrailWarnings lists the messages within --from and --to whose input exceeded the
+// message-size guardrail, on stderr so that JSON and CSV output stay parseable
+func printGuardrailWarnings(hits []calculations.GuardrailHit) {
+	fromTime, _ := parseTimeString(analyzeFrom)
+	toTime, _ := parseTimeString(analyzeTo)
+	var shown []calculations.GuardrailHit
+	for _, hit := range hits {
+		if (!fromTime.IsZero() && hit.Timestamp.Before(fromTime)) || (!toTime.IsZero() && hit.Timestamp.After(toTime)) {
+			continue
+		}
+		shown = append(shown, hit)
+	}
+	if len(shown) == 0 {
+		return
+	}
+
+	noun := "messages"
+	if len(shown) == 1 {
+		noun = "message"
+	}
+	fmt.Fprintf(os.Stderr, "\nWarning: %d %s exceeded the message input guardrail (guardrails.message_input_tokens):\n", len(shown), noun)
+	for i, hit := range shown {
+		if i == maxGuardrailWarnings {
+			fmt.Fprintf(os.Stderr, "  ... and %d more\n", len(shown)-maxGuardrailWarnings)
+			break
+		}
+		line := fmt.Sprintf("  %s  %s  %s input tokens (limit %s)  %s  %s", hit.Timestamp.Local().Format("2006-01-02 15:04"),
+			hit.Model, humanize.Count(hit.InputTokens), humanize.Count(hit.Threshold), humanize.Cost(hit.CostUSD), hit.Project)
+		if hit.SessionID != "" {
+			line += "  session " + hit.SessionID
+		}
+		if hit.SourceFile != "" {
+			line += fmt.Sprintf("  %s:%d", hit.SourceFile, hit.SourceLine)
+		}
+		fmt.Fprintln(os.Stderr, line)
+	}
+	if !analyzeProvenance {
+		fmt.Fprintln(os.Stderr, "  Rerun with --provenance to list the log file and line of each message.")
+	}
+}
+
+// printDuplicateProjectWarnings lists the project directories whose conversations were counted twice
+// and how to stop it
+func printDuplicateProjectWarnings(duplicates []fileio.DuplicateProject) {
+	if len(duplicates) == 0 {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "\nWarning: conversations logged in several project directories are counted twice:\n")
+	for _, duplicate := range duplicates {
+		fmt.Fprintf(os.Stderr, "  %s: %s of %s conversations also in %s\n", filepath.Join(duplicate.DataPath, duplicate.Dir),
+			humanize.Count(duplicate.Shared), humanize.Count(duplicate.Files), duplicate.Original)
+	}
+	fmt.Fprintln(os.Stderr, "  Count them once with `claudecat config set data.duplicate_projects merge`, or skip the copies with")
+	patterns := make([]string, 0, len(duplicates))
+	for _, duplicate := range duplicates {
+		if !slices.Contains(patterns, duplicate.Pattern) {
+			patterns = append(patterns, duplicate.Pattern)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "  `claudecat config set data.exclude '%s'`.\n", strings.Join(patterns, ","))
+}
+
 func applyFilters(results []models.AnalysisResult) []models.AnalysisResult {
 	if analyzeFrom == "" && analyzeTo == "" {
 		return results
@@ -262,6 +487,16 @@ func applyGrouping(results []models.AnalysisResult) []models.AnalysisResult {
 		analyzeGroupBy = "day"
 	}
 
+	// Entry-level output lists results as loaded
+	if analyzeGroupBy == "entry" {
+		return results
+	}
+
+	// Blocks are detected from the entries rather than keyed on a field of each
+	if analyzeGroupBy == "block" {
+		return applyBlockGrouping(results)
+	}
+
 	// If breakdown is enabled and we're grouping by time, use special breakdown grouping
 	if analyzeBreakdown && (analyzeGroupBy == "hour" || analyzeGroupBy == "day" || analyzeGroupBy == "week" || analyzeGroupBy == "month") {
 		return applyBreakdownGrouping(results)
@@ -271,80 +506,97 @@ func applyGrouping(results []models.AnalysisResult) []models.AnalysisResult {
 	groups := make(map[string][]models.AnalysisResult)
 
 	for _, result := range results {
-		var key string
-		switch analyzeGroupBy {
-		case "model":
-			key = result.Model
-		case "project":
-			key = result.Project
-			if key == "" {
-				key = "unknown"
-			}
-		case "day":
-			key = result.Timestamp.Format("2006-01-02")
-		case "hour":
-			key = result.Timestamp.Format("2006-01-02 15:00")
-		case "week":
-			year, week := result.Timestamp.ISOWeek()
-			key = fmt.Sprintf("%d-W%02d", year, week)
-		case "month":
-			key = result.Timestamp.Format("2006-01")
-		case "session":
-			key = result.SessionID
-		default:
-			key = "all"
+		key := calculations.GroupKey(result, analyzeGroupBy)
+		groups[key] = append(groups[key], result)
+	}
+
+	// Aggregate grouped results across a bounded worker pool
+	timeBased := calculations.IsTimeGrouping(analyzeGroupBy)
+	groupResults := calculations.NewGroupAggregator(0).Aggregate(groups)
+	aggregated := make([]models.AnalysisResult, 0, len(groupResults))
+	for _, group := range groupResults {
+		agg := group.Result
+		// For time-based groupings, set the model to a comma-separated list
+		if timeBased {
+			sortModelsByPreference(group.Models)
+			agg.Model = strings.Join(group.Models, ", ")
 		}
+		aggregated = append(aggregated, agg)
+	}
 
-		groups[key] = append(groups[key], result)
+	return aggregated
+}
+
+// applyBlockGrouping aggregates results into the 5-hour session blocks the monitor detects, labelled
+// with their start and end and marked while still active
+func applyBlockGrouping(results []models.AnalysisResult) []models.AnalysisResult {
+	if len(results) == 0 {
+		return results
 	}
+	loc := results[0].Timestamp.Location()
 
-	// Aggregate grouped results
-	var aggregated []models.AnalysisResult
-	for groupKey, groupResults := range groups {
-		if len(groupResults) == 0 {
+	entries := make([]models.UsageEntry, 0, len(results))
+	for _, result := range results {
+		entries = append(entries, models.UsageEntry{
+			Timestamp:             result.Timestamp,
+			Model:                 result.Model,
+			InputTokens:           result.InputTokens,
+			OutputTokens:          result.OutputTokens,
+			CacheCreationTokens:   result.CacheCreationTokens,
+			CacheCreation1hTokens: result.CacheCreation1hTokens,
+			CacheReadTokens:       result.CacheReadTokens,
+			TotalTokens:           result.TotalTokens,
+			CostUSD:               result.CostUSD,
+			SessionID:             result.SessionID,
+			Project:               result.Project,
+		})
+	}
+
+	blocks := sessions.NewSessionAnalyzer(int(models.SessionDuration.Hours())).TransformToBlocks(entries)
+	aggregated := make([]models.AnalysisResult, 0, len(blocks))
+	for _, block := range blocks {
+		if block.IsGap {
 			continue
 		}
-
-		// Create aggregated result
+		end := block.EndTime.In(loc)
 		agg := models.AnalysisResult{
-			GroupKey:  groupKey,
-			Model:     "", // Clear model since we're aggregating across models
-			Timestamp: groupResults[0].Timestamp,
-			SessionID: groupResults[0].SessionID,
-			Project:   groupResults[0].Project,
-		}
-
-		// Aggregate values and collect unique models
-		modelSet := make(map[string]bool)
-		for _, result := range groupResults {
-			agg.InputTokens += result.InputTokens
-			agg.OutputTokens += result.OutputTokens
-			agg.CacheCreationTokens += result.CacheCreationTokens
-			agg.CacheReadTokens += result.CacheReadTokens
-			agg.TotalTokens += result.TotalTokens
-			agg.CostUSD += result.CostUSD
-			if result.Model != "" {
-				modelSet[result.Model] = true
-			}
+			GroupKey:    blockLabel(block.StartTime.In(loc), end, block.IsActive),
+			Timestamp:   block.StartTime.In(loc),
+			BlockEnd:    &end,
+			BlockActive: block.IsActive,
 		}
-		
-		// For time-based groupings, set the model to a comma-separated list
-		if analyzeGroupBy == "hour" || analyzeGroupBy == "day" || analyzeGroupBy == "week" || analyzeGroupBy == "month" {
-			var models []string
-			for model := range modelSet {
-				models = append(models, model)
-			}
-			sortModelsByPreference(models)
-			agg.Model = strings.Join(models, ", ")
+		for _, entry := range block.Entries {
+			agg.InputTokens += entry.InputTokens
+			agg.OutputTokens += entry.OutputTokens
+			agg.CacheCreationTokens += entry.CacheCreationTokens
+			agg.CacheCreation1hTokens += entry.CacheCreation1hTokens
+			agg.CacheReadTokens += entry.CacheReadTokens
+			agg.TotalTokens += entry.TotalTokens
+			agg.CostUSD += entry.CostUSD
+			agg.Count++
 		}
-
-		agg.Count = len(groupResults)
+		blockModels := slices.Clone(block.Models)
+		sortModelsByPreference(blockModels)
+		agg.Model = strings.Join(blockModels, ", ")
 		aggregated = append(aggregated, agg)
 	}
-
 	return aggregated
 }
 
+// blockLabel labels a session block with its start and end, the end without its date when it falls
+// on the same day, and a marker while the block is active
+func blockLabel(start, end time.Time, active bool) string {
+	endFormat := "2006-01-02 15:04"
+	if end.Year() == start.Year() && end.YearDay() == start.YearDay() {
+		endFormat = "15:04"
+	}
+	label := start.Format("2006-01-02 15:04") + " - " + end.Format(endFormat)
+	if active {
+		label += " (active)"
+	}
+	return label
+}
+
 func applyBreakdownGrouping(results []models.AnalysisResult) []models.AnalysisResult {
 	// Group by time period, then by model
 	type modelData struct {
@@ -393,6 +645,7 @@ func applyBreakdownGrouping(results []models.AnalysisResult) []models.AnalysisRe
 		modelResult.InputTokens += result.InputTokens
 		modelResult.OutputTokens += result.OutputTokens
 		modelResult.CacheCreationTokens += result.CacheCreationTokens
+		modelResult.CacheCreation1hTokens += result.CacheCreation1hTokens
 		modelResult.CacheReadTokens += result.CacheReadTokens
 		modelResult.TotalTokens += result.TotalTokens
 		modelResult.CostUSD += result.CostUSD
@@ -403,6 +656,7 @@ func applyBreakdownGrouping(results []models.AnalysisResult) []models.AnalysisRe
 		totalResult.InputTokens += result.InputTokens
 		totalResult.OutputTokens += result.OutputTokens
 		totalResult.CacheCreationTokens += result.CacheCreationTokens
+		totalResult.CacheCreation1hTokens += result.CacheCreation1hTokens
 		totalResult.CacheReadTokens += result.CacheReadTokens
 		totalResult.TotalTokens += result.TotalTokens
 		totalResult.CostUSD += result.CostUSD
@@ -475,59 +729,79 @@ func applyLimit(results []models.AnalysisResult) []models.AnalysisResult {
 	return results[:analyzeLimit]
 }
 
-func outputAnalysisResults(results []models.AnalysisResult) error {
+func outputAnalysisResults(w io.Writer, results []models.AnalysisResult, paths []string, appended bool) error {
 	switch analyzeOutput {
 	case "table":
-		return outputTable(results)
+		return outputTable(w, results, logLinks(paths))
 	case "json":
-		return outputJSON(results)
+		return outputJSON(w, results)
 	case "csv":
-		return outputCSV(results)
+		return outputCSV(w, results, !appended)
 	case "summary":
-		return outputSummary(results)
+		return outputSummary(w, results)
+	case "html":
+		return outputHTML(w, results, time.Now())
 	default:
 		return fmt.Errorf("unsupported output format: %s", analyzeOutput)
 	}
 }
 
-func outputTable(results []models.AnalysisResult) error {
+// outputTable prints the results as a table, linking project names and session IDs found in links
+func outputTable(w io.Writer, results []models.AnalysisResult, links *fileio.LogIndex) error {
 	if len(results) == 0 {
-		fmt.Println("No data to display.")
+		fmt.Fprintln(w, "No data to display.")
 		return nil
 	}
 
+	if analyzeGroupBy == "entry" {
+		return outputEntryTable(w, results, links)
+	}
 	if analyzeBreakdown {
-		return outputTableWithBreakdown(results)
+		return outputTableWithBreakdown(w, results)
 	}
-	return outputTableWithoutBreakdown(results)
+	return outputTableWithoutBreakdown(w, results, links)
 }
 
-func outputTableWithoutBreakdown(results []models.AnalysisResult) error {
-	// Determine the primary grouping column header
-	var groupColumnHeader string
+// groupColumnHeader returns the header of the column holding the group keys of the --group-by field
+func groupColumnHeader() string {
 	switch analyzeGroupBy {
 	case "project":
-		groupColumnHeader = "Project"
+		return "Project"
+	case "tool":
+		return "Tool Server"
 	case "model":
-		groupColumnHeader = "Model"
+		return "Model"
 	case "session":
-		groupColumnHeader = "Session"
+		return "Session"
+	case "block":
+		return "Block"
+	case "tag":
+		return "Tag"
 	case "hour", "day", "week", "month":
-		groupColumnHeader = "Date"
+		return "Date"
 	default:
-		groupColumnHeader = "Group"
+		return "Group"
 	}
+}
+
+func outputTableWithoutBreakdown(w io.Writer, results []models.AnalysisResult, links *fileio.LogIndex) error {
+	// Determine the primary grouping column header
+	groupColumnHeader := groupColumnHeader()
 
 	// Create table headers
 	headers := []string{groupColumnHeader, "Input", "Output", "Cache Create", "Cache Read", "Total Tokens", "Cost (USD)"}
-	if analyzeGroupBy != "model" && analyzeGroupBy != "project" {
+	if analyzeGroupBy == "session" {
+		// Show how confident session detection was; pinned sessions are 100%
+		headers = []string{groupColumnHeader, "Confidence", "Input", "Output", "Cache Create", "Cache Read", "Total Tokens", "Cost (USD)"}
+	} else if analyzeGroupBy != "model" && analyzeGroupBy != "project" && analyzeGroupBy != "tool" && analyzeGroupBy != "tag" {
 		// Add Models column for time-based groupings
 		headers = []string{groupColumnHeader, "Models", "Input", "Output", "Cache Create", "Cache Read", "Total Tokens", "Cost (USD)"}
 	}
 	table := newTableFormatter(headers)
+	table.heat = heatEnabled(analyzeHeat)
 
 	// For all groupings, we can use the aggregated results directly
-	if analyzeGroupBy != "model" && analyzeGroupBy != "project" && analyzeGroupBy != "session" {
+	if analyzeGroupBy != "model" && analyzeGroupBy != "project" && analyzeGroupBy != "tool" && analyzeGroupBy != "tag" && analyzeGroupBy != "session" {
 		// Time-based groupings - add Models column
 		// Sort results by group key
 		sort.Slice(results, func(i, j int) bool {
@@ -539,12 +813,12 @@ func outputTableWithoutBreakdown(results []models.AnalysisResult) error {
 			row := []string{
 				result.GroupKey,
 				result.Model, // This contains the comma-separated list of models
-				formatWithCommas(result.InputTokens),
-				formatWithCommas(result.OutputTokens),
-				formatWithCommas(result.CacheCreationTokens),
-				formatWithCommas(result.CacheReadTokens),
-				formatWithCommas(result.TotalTokens),
-				formatCost(result.CostUSD),
+				humanize.Count(result.InputTokens),
+				humanize.Count(result.OutputTokens),
+				humanize.Count(result.CacheCreationTokens),
+				humanize.Count(result.CacheReadTokens),
+				humanize.Count(result.TotalTokens),
+				humanize.Cost(result.CostUSD),
 			}
 			table.addRow(row)
 		}
@@ -552,7 +826,7 @@ func outputTableWithoutBreakdown(results []models.AnalysisResult) error {
 		// Add summary row
 		addSummaryRowWithModels(table, results)
 	} else {
-		// For non-time-based groupings (model, project, session)
+		// For non-time-based groupings (model, project, tool, session)
 		// Sort results by group key
 		sort.Slice(results, func(i, j int) bool {
 			return results[i].GroupKey < results[j].GroupKey
@@ -560,14 +834,24 @@ func outputTableWithoutBreakdown(results []models.AnalysisResult) error {
 
 		// Add rows directly from results
 		for _, result := range results {
+			key := result.GroupKey
+			switch analyzeGroupBy {
+			case "project":
+				key = projectLink(links, key)
+			case "session":
+				key = sessionLink(links, key, "")
+			}
 			row := []string{
-				result.GroupKey,
-				formatWithCommas(result.InputTokens),
-				formatWithCommas(result.OutputTokens),
-				formatWithCommas(result.CacheCreationTokens),
-				formatWithCommas(result.CacheReadTokens),
-				formatWithCommas(result.TotalTokens),
-				formatCost(result.CostUSD),
+				key,
+				humanize.Count(result.InputTokens),
+				humanize.Count(result.OutputTokens),
+				humanize.Count(result.CacheCreationTokens),
+				humanize.Count(result.CacheReadTokens),
+				humanize.Count(result.TotalTokens),
+				humanize.Cost(result.CostUSD),
+			}
+			if analyzeGroupBy == "session" {
+				row = append([]string{row[0], fmt.Sprintf("%.0f%%", result.SessionConfidence*100)}, row[1:]...)
 			}
 			table.addRow(row)
 		}
@@ -576,11 +860,48 @@ func outputTableWithoutBreakdown(results []models.AnalysisResult) error {
 		addSummaryRowSimple(table, results)
 	}
 
-	fmt.Print(table.render())
+	fmt.Fprint(w, table.render())
+	return nil
+}
+
+// outputEntryTable lists one row per entry in the current sort order, with the log file
+// and line of each entry when provenance was recorded
+func outputEntryTable(w io.Writer, results []models.AnalysisResult, links *fileio.LogIndex) error {
+	headers := []string{"Timestamp", "Model", "Project", "Session", "Total Tokens", "Cost (USD)"}
+	if analyzeProvenance {
+		headers = append(headers, "Source")
+	}
+	table := newTableFormatter(headers)
+	table.heat = heatEnabled(analyzeHeat)
+
+	for _, result := range results {
+		row := []string{
+			result.Timestamp.Format("2006-01-02 15:04:05"),
+			result.Model,
+			projectLink(links, result.Project),
+			sessionLink(links, result.SessionID, result.SourceFile),
+			humanize.Count(result.TotalTokens),
+			humanize.Cost(result.CostUSD),
+		}
+		if analyzeProvenance {
+			row = append(row, formatSource(result))
+		}
+		table.addRow(row)
+	}
+
+	fmt.Fprintln(w, table.render())
 	return nil
 }
 
-func outputTableWithBreakdown(results []models.AnalysisResult) error {
+// formatSource returns the file:line an entry was read from, or "" if it was not recorded
+func formatSource(result models.AnalysisResult) string {
+	if result.SourceFile == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", result.SourceFile, result.SourceLine)
+}
+
+func outputTableWithBreakdown(w io.Writer, results []models.AnalysisResult) error {
 	// Group results by date, then by model
 	dateGroups := make(map[string]*dateGroupWithModels)
 
@@ -614,6 +935,7 @@ func outputTableWithBreakdown(results []models.AnalysisResult) error {
 		stat.inputTokens += result.InputTokens
 		stat.outputTokens += result.OutputTokens
 		stat.cacheCreationTokens += result.CacheCreationTokens
+		stat.cacheCreation1hTokens += result.CacheCreation1hTokens
 		stat.cacheReadTokens += result.CacheReadTokens
 		stat.totalTokens += result.TotalTokens
 		stat.costUSD += result.CostUSD
@@ -622,14 +944,16 @@ func outputTableWithBreakdown(results []models.AnalysisResult) error {
 		group.totalInputTokens += result.InputTokens
 		group.totalOutputTokens += result.OutputTokens
 		group.totalCacheCreationTokens += result.CacheCreationTokens
+		group.totalCacheCreation1hTokens += result.CacheCreation1hTokens
 		group.totalCacheReadTokens += result.CacheReadTokens
 		group.totalTotalTokens += result.TotalTokens
 		group.totalCostUSD += result.CostUSD
 	}
 
-	// Create table
-	headers := []string{"Date", "Models", "Input", "Output", "Cache Create", "Cache Read", "Total Tokens", "Cost (USD)"}
+	// Create table; cache writes are split by tier since 5-minute and 1-hour writes are priced differently
+	headers := []string{"Date", "Models", "Input", "Output", "Cache 5m", "Cache 1h", "Cache Read", "Total Tokens", "Cost (USD)"}
 	table := newTableFormatter(headers)
+	table.heat = heatEnabled(analyzeHeat)
 
 	// Sort dates
 	var dates []string
@@ -659,12 +983,13 @@ func outputTableWithBreakdown(results []models.AnalysisResult) error {
 		row := []string{
 			date,
 			"", // Empty models column in breakdown mode
-			formatWithCommas(group.totalInputTokens),
-			formatWithCommas(group.totalOutputTokens),
-			formatWithCommas(group.totalCacheCreationTokens),
-			formatWithCommas(group.totalCacheReadTokens),
-			formatWithCommas(group.totalTotalTokens),
-			formatCost(group.totalCostUSD),
+			humanize.Count(group.totalInputTokens),
+			humanize.Count(group.totalOutputTokens),
+			humanize.Count(group.totalCacheCreationTokens - group.totalCacheCreation1hTokens),
+			humanize.Count(group.totalCacheCreation1hTokens),
+			humanize.Count(group.totalCacheReadTokens),
+			humanize.Count(group.totalTotalTokens),
+			humanize.Cost(group.totalCostUSD),
 		}
 		table.addRow(row)
 
@@ -674,12 +999,13 @@ func outputTableWithBreakdown(results []models.AnalysisResult) error {
 			breakdownRow := []string{
 				"",
 				"└─ " + model,
-				formatWithCommas(stat.inputTokens),
-				formatWithCommas(stat.outputTokens),
-				formatWithCommas(stat.cacheCreationTokens),
-				formatWithCommas(stat.cacheReadTokens),
-				formatWithCommas(stat.totalTokens),
-				formatCost(stat.costUSD),
+				humanize.Count(stat.inputTokens),
+				humanize.Count(stat.outputTokens),
+				humanize.Count(stat.cacheCreationTokens - stat.cacheCreation1hTokens),
+				humanize.Count(stat.cacheCreation1hTokens),
+				humanize.Count(stat.cacheReadTokens),
+				humanize.Count(stat.totalTokens),
+				humanize.Cost(stat.costUSD),
 			}
 			table.addRow(breakdownRow)
 		}
@@ -694,7 +1020,7 @@ func outputTableWithBreakdown(results []models.AnalysisResult) error {
 	// Add summary row for breakdown mode
 	addSummaryRowBreakdown(table, dateGroups)
 
-	fmt.Print(table.render())
+	fmt.Fprint(w, table.render())
 	return nil
 }
 
@@ -711,54 +1037,61 @@ type dateGroup struct {
 }
 
 type modelStat struct {
-	inputTokens         int
-	outputTokens        int
-	cacheCreationTokens int
-	cacheReadTokens     int
-	totalTokens         int
-	costUSD             float64
+	inputTokens           int
+	outputTokens          int
+	cacheCreationTokens   int
+	cacheCreation1hTokens int
+	cacheReadTokens       int
+	totalTokens           int
+	costUSD               float64
 }
 
 type dateGroupWithModels struct {
-	date                     string
-	modelStats               map[string]*modelStat
-	totalInputTokens         int
-	totalOutputTokens        int
-	totalCacheCreationTokens int
-	totalCacheReadTokens     int
-	totalTotalTokens         int
-	totalCostUSD             float64
+	date                       string
+	modelStats                 map[string]*modelStat
+	totalInputTokens           int
+	totalOutputTokens          int
+	totalCacheCreationTokens   int
+	totalCacheCreation1hTokens int
+	totalCacheReadTokens       int
+	totalTotalTokens           int
+	totalCostUSD               float64
 }
 
-func outputJSON(results []models.AnalysisResult) error {
+func outputJSON(w io.Writer, results []models.AnalysisResult) error {
 	data, err := sonic.MarshalIndent(results, "", "  ")
 	if err != nil {
 		return err
 	}
-	_, err = os.Stdout.Write(data)
+	_, err = w.Write(data)
 	if err != nil {
 		return err
 	}
-	_, err = os.Stdout.Write([]byte("\n"))
+	_, err = w.Write([]byte("\n"))
 	return err
 }
 
-func outputCSV(results []models.AnalysisResult) error {
-	writer := csv.NewWriter(os.Stdout)
+func outputCSV(w io.Writer, results []models.AnalysisResult, header bool) error {
+	writer := csv.NewWriter(w)
 	defer writer.Flush()
 
-	// Header
-	if analyzeGroupBy != "" {
+	// Header, left out when appending to an earlier export
+	entries := analyzeGroupBy == "entry"
+	if header && !entries {
 		_ = writer.Write([]string{"Group", "Model", "Entries", "Input Tokens", "Output Tokens",
 			"Cache Creation", "Cache Read", "Total Tokens", "Cost USD"})
-	} else {
-		_ = writer.Write([]string{"Timestamp", "Model", "Session", "Input Tokens", "Output Tokens",
-			"Cache Creation", "Cache Read", "Total Tokens", "Cost USD"})
+	} else if header {
+		columns := []string{"Timestamp", "Model", "Session", "Input Tokens", "Output Tokens",
+			"Cache Creation", "Cache Read", "Total Tokens", "Cost USD"}
+		if analyzeProvenance {
+			columns = append(columns, "Source File", "Source Line")
+		}
+		_ = writer.Write(columns)
 	}
 
 	// Data rows
 	for _, result := range results {
-		if analyzeGroupBy != "" {
+		if !entries {
 			_ = writer.Write([]string{
 				result.GroupKey,
 				result.Model,
@@ -771,7 +1104,7 @@ func outputCSV(results []models.AnalysisResult) error {
 				fmt.Sprintf("%.4f", result.CostUSD),
 			})
 		} else {
-			_ = writer.Write([]string{
+			row := []string{
 				result.Timestamp.Format("2006-01-02 15:04:05"),
 				result.Model,
 				result.SessionID,
@@ -781,16 +1114,20 @@ func outputCSV(results []models.AnalysisResult) error {
 				strconv.Itoa(result.CacheReadTokens),
 				strconv.Itoa(result.TotalTokens),
 				fmt.Sprintf("%.4f", result.CostUSD),
-			})
+			}
+			if analyzeProvenance {
+				row = append(row, result.SourceFile, strconv.Itoa(result.SourceLine))
+			}
+			_ = writer.Write(row)
 		}
 	}
 
 	return nil
 }
 
-func outputSummary(results []models.AnalysisResult) error {
+func outputSummary(w io.Writer, results []models.AnalysisResult) error {
 	if len(results) == 0 {
-		fmt.Println("No data found.")
+		fmt.Fprintln(w, "No data found.")
 		return nil
 	}
 
@@ -834,29 +1171,29 @@ func outputSummary(results []models.AnalysisResult) error {
 	}
 
 	// Output summary
-	fmt.Printf("Analysis Summary\n")
-	fmt.Printf("================\n\n")
-	fmt.Printf("Total Entries: %d\n", totalEntries)
-	fmt.Printf("Date Range: %s to %s\n",
+	fmt.Fprintf(w, "Analysis Summary\n")
+	fmt.Fprintf(w, "================\n\n")
+	fmt.Fprintf(w, "Total Entries: %d\n", totalEntries)
+	fmt.Fprintf(w, "Date Range: %s to %s\n",
 		results[0].Timestamp.Format("2006-01-02 15:04:05"),
 		results[len(results)-1].Timestamp.Format("2006-01-02 15:04:05"))
-	fmt.Printf("\nToken Usage:\n")
-	fmt.Printf("  Input Tokens: %d\n", totalInputTokens)
-	fmt.Printf("  Output Tokens: %d\n", totalOutputTokens)
-	fmt.Printf("  Cache Creation: %d\n", totalCacheCreation)
-	fmt.Printf("  Cache Read: %d\n", totalCacheRead)
-	fmt.Printf("  Total Tokens: %d\n", totalTokens)
-	fmt.Printf("\nCost: $%.4f\n\n", totalCost)
-
-	fmt.Printf("Models Used:\n")
+	fmt.Fprintf(w, "\nToken Usage:\n")
+	fmt.Fprintf(w, "  Input Tokens: %d\n", totalInputTokens)
+	fmt.Fprintf(w, "  Output Tokens: %d\n", totalOutputTokens)
+	fmt.Fprintf(w, "  Cache Creation: %d\n", totalCacheCreation)
+	fmt.Fprintf(w, "  Cache Read: %d\n", totalCacheRead)
+	fmt.Fprintf(w, "  Total Tokens: %d\n", totalTokens)
+	fmt.Fprintf(w, "\nCost: %s\n\n", humanize.CostDecimals(totalCost, 4))
+
+	fmt.Fprintf(w, "Models Used:\n")
 	for model, count := range modelCounts {
-		fmt.Printf("  %s: %d entries\n", model, count)
+		fmt.Fprintf(w, "  %s: %d entries\n", model, count)
 	}
 
 	// Show per-model breakdown if requested
 	if analyzeBreakdown {
-		fmt.Printf("\nPer-Model Cost Breakdown:\n")
-		fmt.Printf("========================\n")
+		fmt.Fprintf(w, "\nPer-Model Cost Breakdown:\n")
+		fmt.Fprintf(w, "========================\n")
 
 		// Sort models by cost (descending)
 		type modelBreakdown struct {
@@ -881,13 +1218,13 @@ func outputSummary(results []models.AnalysisResult) error {
 		})
 
 		for _, b := range breakdowns {
-			fmt.Printf("\n%s:\n", b.name)
-			fmt.Printf("  Input Tokens: %d\n", b.stats.InputTokens)
-			fmt.Printf("  Output Tokens: %d\n", b.stats.OutputTokens)
-			fmt.Printf("  Cache Creation: %d\n", b.stats.CacheCreationTokens)
-			fmt.Printf("  Cache Read: %d\n", b.stats.CacheReadTokens)
-			fmt.Printf("  Total Tokens: %d\n", b.stats.TotalTokens)
-			fmt.Printf("  Cost: $%.4f (%.1f%%)\n", b.stats.Cost, (b.stats.Cost/totalCost)*100)
+			fmt.Fprintf(w, "\n%s:\n", b.name)
+			fmt.Fprintf(w, "  Input Tokens: %d\n", b.stats.InputTokens)
+			fmt.Fprintf(w, "  Output Tokens: %d\n", b.stats.OutputTokens)
+			fmt.Fprintf(w, "  Cache Creation: %d\n", b.stats.CacheCreationTokens)
+			fmt.Fprintf(w, "  Cache Read: %d\n", b.stats.CacheReadTokens)
+			fmt.Fprintf(w, "  Total Tokens: %d\n", b.stats.TotalTokens)
+			fmt.Fprintf(w, "  Cost: %s (%.1f%%)\n", humanize.CostDecimals(b.stats.Cost, 4), (b.stats.Cost/totalCost)*100)
 		}
 	}
 
@@ -917,16 +1254,21 @@ func parseTimeString(timeStr string) (time.Time, error) {
 // Table formatting utilities for bordered tables
 
 type tableFormatter struct {
-	headers []string
-	rows    [][]string
-	widths  []int
+	headers  []string
+	rows     [][]string
+	widths   []int
+	maxWidth int  // Terminal width to fit into; 0 disables the narrow layouts
+	heat     bool // Color token and cost cells by their percentile in the column
+
+	heatByCell map[[2]int]calculations.HeatLevel // Heat of the cells by row and column, set by render
 }
 
 func newTableFormatter(headers []string) *tableFormatter {
 	return &tableFormatter{
-		headers: headers,
-		rows:    make([][]string, 0),
-		widths:  make([]int, len(headers)),
+		headers:  headers,
+		rows:     make([][]string, 0),
+		widths:   make([]int, len(headers)),
+		maxWidth: terminalWidth(),
 	}
 }
 
@@ -939,6 +1281,9 @@ func (tf *tableFormatter) addRow(row []string) {
 	} else if len(row) > len(tf.headers) {
 		row = row[:len(tf.headers)]
 	}
+	for i, cell := range row {
+		row[i] = terminal.TruncateColumn(tf.headers[i], cell)
+	}
 
 	tf.rows = append(tf.rows, row)
 }
@@ -975,24 +1320,43 @@ func (tf *tableFormatter) render() string {
 		return ""
 	}
 
+	// Terminals without Unicode get ASCII cells as well as borders, so the widths still line up
+	if !terminal.Unicode() {
+		tf.headers = textCells(tf.headers)
+		for i, row := range tf.rows {
+			tf.rows[i] = textCells(row)
+		}
+	}
 	tf.calculateWidths()
+	if tf.heat {
+		tf.heatByCell = tf.heatLevels()
+	}
+
+	// Fall back to a narrower layout instead of letting the terminal wrap the borders
+	if tf.maxWidth > 0 && tf.totalWidth() > tf.maxWidth {
+		if narrowed, hidden := tf.withoutLowPriorityColumns(); narrowed != nil {
+			return narrowed.render() + "\n" + fmt.Sprintf("(hidden columns: %s; widen the terminal or use --output json)", strings.Join(hidden, ", "))
+		}
+		return tf.renderStacked()
+	}
+
 	var lines []string
 
 	// Top border
 	lines = append(lines, tf.renderTopBorder())
 
 	// Headers
-	lines = append(lines, tf.renderRow(tf.headers))
+	lines = append(lines, tf.renderRow(tf.headers, -1))
 
 	// Header separator
 	lines = append(lines, tf.renderSeparator())
 
 	// Data rows
-	for _, row := range tf.rows {
+	for i, row := range tf.rows {
 		if len(row) > 0 && row[0] == "SEPARATOR" {
 			lines = append(lines, tf.renderSeparator())
 		} else {
-			lines = append(lines, tf.renderRow(row))
+			lines = append(lines, tf.renderRow(row, i))
 		}
 	}
 
@@ -1014,7 +1378,7 @@ func (tf *tableFormatter) renderTopBorder() string {
 	}
 
 	parts = append(parts, "┐")
-	return strings.Join(parts, "")
+	return terminal.Text(strings.Join(parts, ""))
 }
 
 func (tf *tableFormatter) renderBottomBorder() string {
@@ -1029,7 +1393,7 @@ func (tf *tableFormatter) renderBottomBorder() string {
 	}
 
 	parts = append(parts, "┘")
-	return strings.Join(parts, "")
+	return terminal.Text(strings.Join(parts, ""))
 }
 
 func (tf *tableFormatter) renderSeparator() string {
@@ -1044,10 +1408,11 @@ func (tf *tableFormatter) renderSeparator() string {
 	}
 
 	parts = append(parts, "┤")
-	return strings.Join(parts, "")
+	return terminal.Text(strings.Join(parts, ""))
 }
 
-func (tf *tableFormatter) renderRow(row []string) string {
+// renderRow renders the row at index of tf.rows, or the headers at index -1
+func (tf *tableFormatter) renderRow(row []string, index int) string {
 	var parts []string
 	parts = append(parts, "│")
 
@@ -1055,12 +1420,22 @@ func (tf *tableFormatter) renderRow(row []string) string {
 		if i < len(tf.widths) {
 			// Right-align numeric columns (tokens and cost), left-align others
 			padded := tf.padCell(cell, tf.widths[i], tf.isNumericColumn(i))
-			parts = append(parts, " "+padded+" ")
+			// Colors wrap the padded cell, so they do not count towards its width
+			parts = append(parts, heatCell(" "+padded+" ", tf.heatByCell[[2]int{index, i}]))
 			parts = append(parts, "│")
 		}
 	}
 
-	return strings.Join(parts, "")
+	return terminal.Text(strings.Join(parts, ""))
+}
+
+// textCells returns the cells in the charset of the terminal
+func textCells(cells []string) []string {
+	converted := make([]string, len(cells))
+	for i, cell := range cells {
+		converted[i] = terminal.Text(cell)
+	}
+	return converted
 }
 
 func (tf *tableFormatter) isNumericColumn(colIndex int) bool {
@@ -1076,10 +1451,11 @@ func (tf *tableFormatter) isNumericColumn(colIndex int) bool {
 		strings.Contains(header, "cost")
 }
 
-// runeWidth calculates the display width of a string, accounting for Unicode characters
+// runeWidth calculates the display width of a string, accounting for Unicode characters and
+// leaving out hyperlink sequences
 func runeWidth(s string) int {
 	width := 0
-	for _, r := range s {
+	for _, r := range terminal.StripHyperlinks(s) {
 		// Most printable ASCII characters have width 1
 		if r >= 32 && r <= 126 {
 			width++
@@ -1107,29 +1483,6 @@ func (tf *tableFormatter) padCell(text string, width int, rightAlign bool) strin
 	return text + strings.Repeat(" ", padding)
 }
 
-// Number formatting functions
-
-func formatWithCommas(n int) string {
-	str := strconv.Itoa(n)
-	if len(str) <= 3 {
-		return str
-	}
-
-	var result []byte
-	for i, digit := range []byte(str) {
-		if i > 0 && (len(str)-i)%3 == 0 {
-			result = append(result, ',')
-		}
-		result = append(result, digit)
-	}
-
-	return string(result)
-}
-
-func formatCost(cost float64) string {
-	return fmt.Sprintf("$%.2f", cost)
-}
-
 func formatModels(models []string) string {
 	if len(models) == 0 {
 		return ""
@@ -1205,12 +1558,12 @@ func addSummaryRow(table *tableFormatter, dateGroups map[string]*dateGroup) {
 	summaryRow := []string{
 		"TOTAL",
 		formatModels(modelList),
-		formatWithCommas(totalInput),
-		formatWithCommas(totalOutput),
-		formatWithCommas(totalCacheCreation),
-		formatWithCommas(totalCacheRead),
-		formatWithCommas(totalTokens),
-		formatCost(totalCost),
+		humanize.Count(totalInput),
+		humanize.Count(totalOutput),
+		humanize.Count(totalCacheCreation),
+		humanize.Count(totalCacheRead),
+		humanize.Count(totalTokens),
+		humanize.Cost(totalCost),
 	}
 	table.addRow(summaryRow)
 }
@@ -1235,12 +1588,15 @@ func addSummaryRowSimple(table *tableFormatter, results []models.AnalysisResult)
 	// Add summary row
 	summaryRow := []string{
 		"TOTAL",
-		formatWithCommas(totalInput),
-		formatWithCommas(totalOutput),
-		formatWithCommas(totalCacheCreation),
-		formatWithCommas(totalCacheRead),
-		formatWithCommas(totalTokens),
-		formatCost(totalCost),
+		humanize.Count(totalInput),
+		humanize.Count(totalOutput),
+		humanize.Count(totalCacheCreation),
+		humanize.Count(totalCacheRead),
+		humanize.Count(totalTokens),
+		humanize.Cost(totalCost),
+	}
+	if len(table.headers) > len(summaryRow) {
+		summaryRow = append([]string{summaryRow[0], ""}, summaryRow[1:]...)
 	}
 	table.addRow(summaryRow)
 }
@@ -1282,25 +1638,26 @@ func addSummaryRowWithModels(table *tableFormatter, results []models.AnalysisRes
 	summaryRow := []string{
 		"TOTAL",
 		formatModels(modelList),
-		formatWithCommas(totalInput),
-		formatWithCommas(totalOutput),
-		formatWithCommas(totalCacheCreation),
-		formatWithCommas(totalCacheRead),
-		formatWithCommas(totalTokens),
-		formatCost(totalCost),
+		humanize.Count(totalInput),
+		humanize.Count(totalOutput),
+		humanize.Count(totalCacheCreation),
+		humanize.Count(totalCacheRead),
+		humanize.Count(totalTokens),
+		humanize.Cost(totalCost),
 	}
 	table.addRow(summaryRow)
 }
 
 // addSummaryRowBreakdown adds a summary row to the table for breakdown mode
 func addSummaryRowBreakdown(table *tableFormatter, dateGroups map[string]*dateGroupWithModels) {
-	var totalInput, totalOutput, totalCacheCreation, totalCacheRead, totalTokens int
+	var totalInput, totalOutput, totalCacheCreation, totalCacheCreation1h, totalCacheRead, totalTokens int
 	var totalCost float64
 
 	for _, group := range dateGroups {
 		totalInput += group.totalInputTokens
 		totalOutput += group.totalOutputTokens
 		totalCacheCreation += group.totalCacheCreationTokens
+		totalCacheCreation1h += group.totalCacheCreation1hTokens
 		totalCacheRead += group.totalCacheReadTokens
 		totalTokens += group.totalTotalTokens
 		totalCost += group.totalCostUSD
@@ -1313,12 +1670,13 @@ func addSummaryRowBreakdown(table *tableFormatter, dateGroups map[string]*dateGr
 	summaryRow := []string{
 		"TOTAL",
 		"", // Empty models column in breakdown mode
-		formatWithCommas(totalInput),
-		formatWithCommas(totalOutput),
-		formatWithCommas(totalCacheCreation),
-		formatWithCommas(totalCacheRead),
-		formatWithCommas(totalTokens),
-		formatCost(totalCost),
+		humanize.Count(totalInput),
+		humanize.Count(totalOutput),
+		humanize.Count(totalCacheCreation - totalCacheCreation1h),
+		humanize.Count(totalCacheCreation1h),
+		humanize.Count(totalCacheRead),
+		humanize.Count(totalTokens),
+		humanize.Cost(totalCost),
 	}
 	table.addRow(summaryRow)
 }
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/penwyp/claudecat/calculations"
+	"github.com/penwyp/claudecat/humanize"
+	"github.com/penwyp/claudecat/internal"
+)
+
+// costAuditReport is the JSON representation of a cost audit
+type costAuditReport struct {
+	Tolerance     float64                       `json:"tolerance"`
+	Discrepancies bool                          `json:"discrepancies"`
+	Models        []calculations.ModelCostAudit `json:"models"`
+}
+
+// runCostAudit compares logged and calculated costs and prints a per-model report
+func runCostAudit(w io.Writer, analyzer *internal.Analyzer, paths []string) error {
+	var fromTime, toTime time.Time
+	var err error
+	if analyzeFrom != "" {
+		if fromTime, err = parseTimeString(analyzeFrom); err != nil {
+			return fmt.Errorf("invalid from date %s: %w", analyzeFrom, err)
+		}
+	}
+	if analyzeTo != "" {
+		if toTime, err = parseTimeString(analyzeTo); err != nil {
+			return fmt.Errorf("invalid to date %s: %w", analyzeTo, err)
+		}
+	}
+
+	auditor, err := analyzer.AuditCosts(paths, analyzeAuditTolerance, fromTime, toTime)
+	if err != nil {
+		return fmt.Errorf("cost audit failed: %w", err)
+	}
+	report := auditor.Report()
+	recordCommandResult("models", len(report))
+
+	if analyzeOutput == "json" {
+		data, err := sonic.MarshalIndent(costAuditReport{
+			Tolerance:     analyzeAuditTolerance,
+			Discrepancies: auditor.HasDiscrepancies(),
+			Models:        report,
+		}, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append(data, '\n'))
+		return err
+	}
+
+	if len(report) == 0 {
+		fmt.Fprintln(w, "No data to display.")
+		return nil
+	}
+
+	table := newTableFormatter([]string{"Model", "Entries", "Logged", "Mismatched", "Logged Cost", "Calculated Cost", "Drift", "Max Diff"})
+	withCost := 0
+	for _, stat := range report {
+		withCost += stat.EntriesWithCost
+		table.addRow([]string{
+			stat.Model,
+			humanize.Count(stat.Entries),
+			humanize.Count(stat.EntriesWithCost),
+			humanize.Count(stat.Discrepancies),
+			humanize.Cost(stat.CachedCost),
+			humanize.Cost(stat.CalculatedCost),
+			fmt.Sprintf("%+.2f%%", stat.DriftPercent()),
+			humanize.CostDecimals(stat.MaxAbsoluteDiff, 4),
+		})
+	}
+	fmt.Fprintln(w, table.render())
+
+	switch {
+	case withCost == 0:
+		fmt.Fprintln(w, "No entries with a logged costUSD were found; nothing to compare.")
+	case auditor.HasDiscrepancies():
+		fmt.Fprintf(w, "Cost discrepancies above %.2f%% tolerance found: check pricing source or Claude Code logging.\n", analyzeAuditTolerance*100)
+	default:
+		fmt.Fprintf(w, "All logged costs are within %.2f%% of calculated costs.\n", analyzeAuditTolerance*100)
+	}
+
+	return nil
+}
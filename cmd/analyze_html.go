@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/penwyp/claudecat/humanize"
+	"github.com/penwyp/claudecat/models"
+)
+
+// analyzeBarWidth is the width of the longest inline bar of the HTML analysis
+const analyzeBarWidth = 160
+
+// analyzeSegment is one kind of token in the inline token bar of a row
+type analyzeSegment struct {
+	Class    string
+	X, Width float64
+	Title    string
+}
+
+// analyzeHTMLRow is one row of the HTML analysis with its inline charts
+type analyzeHTMLRow struct {
+	Label, Models string
+	Result        models.AnalysisResult
+	Total         bool // Breakdown total across models
+	CostWidth     float64
+	Segments      []analyzeSegment
+}
+
+// analyzeHTMLData is the view model of analyzeHTMLTemplate
+type analyzeHTMLData struct {
+	Title, Range, Generated string
+	GroupHeader             string
+	ShowModels              bool
+	Rows                    []analyzeHTMLRow
+	Summary                 models.AnalysisResult
+	BarWidth                int
+	Heat                    [][]string // Heat classes of the table cells by row and numeric column
+}
+
+// outputHTML writes the grouped results as a standalone HTML page: the table of outputTable with an
+// inline cost bar and token mix bar per row
+func outputHTML(w io.Writer, results []models.AnalysisResult, now time.Time) error {
+	if analyzeGroupBy != "entry" && !analyzeBreakdown {
+		sort.Slice(results, func(i, j int) bool {
+			return results[i].GroupKey < results[j].GroupKey
+		})
+	}
+
+	data := analyzeHTMLData{
+		Title:       "Claude usage analysis by " + strings.ToLower(groupColumnHeader()),
+		Range:       analyzeHTMLRange(),
+		Generated:   now.Format("2006-01-02 15:04 MST"),
+		GroupHeader: groupColumnHeader(),
+		ShowModels:  analyzeGroupBy != "model" && analyzeGroupBy != "project" && analyzeGroupBy != "tool" && analyzeGroupBy != "tag" && analyzeGroupBy != "session",
+		BarWidth:    analyzeBarWidth,
+	}
+	if analyzeGroupBy == "entry" {
+		data.Title = "Claude usage analysis by entry"
+		data.GroupHeader = "Timestamp"
+	}
+
+	maxCost, maxTokens := 0.0, 0
+	for _, result := range results {
+		if analyzeBreakdown && result.Model == "TOTAL" {
+			continue
+		}
+		maxCost = math.Max(maxCost, result.CostUSD)
+		maxTokens = max(maxTokens, result.TotalTokens)
+	}
+
+	var input, output, cacheWrite, cacheRead, total, cost []float64
+	var charted []int // Rows with charts, the ones heat is ranked over
+	for _, result := range results {
+		row := analyzeHTMLRow{Label: result.GroupKey, Models: result.Model, Result: result}
+		if analyzeGroupBy == "entry" {
+			row.Label = result.Timestamp.Format("2006-01-02 15:04:05")
+			row.Result.Count = 1
+		}
+		if analyzeBreakdown && result.Model == "TOTAL" {
+			row.Total = true
+			data.Rows = append(data.Rows, row)
+			continue
+		}
+		if maxCost > 0 {
+			row.CostWidth = math.Max(result.CostUSD/maxCost*analyzeBarWidth, 1)
+		}
+		if maxTokens > 0 {
+			row.Segments = analyzeTokenSegments(result, float64(analyzeBarWidth)/float64(maxTokens))
+		}
+		charted = append(charted, len(data.Rows))
+		data.Rows = append(data.Rows, row)
+
+		data.Summary.InputTokens += result.InputTokens
+		data.Summary.OutputTokens += result.OutputTokens
+		data.Summary.CacheCreationTokens += result.CacheCreationTokens
+		data.Summary.CacheReadTokens += result.CacheReadTokens
+		data.Summary.TotalTokens += result.TotalTokens
+		data.Summary.CostUSD += result.CostUSD
+		data.Summary.Count += row.Result.Count
+
+		input = append(input, float64(result.InputTokens))
+		output = append(output, float64(result.OutputTokens))
+		cacheWrite = append(cacheWrite, float64(result.CacheCreationTokens))
+		cacheRead = append(cacheRead, float64(result.CacheReadTokens))
+		total = append(total, float64(result.TotalTokens))
+		cost = append(cost, result.CostUSD)
+	}
+	if analyzeHeat {
+		data.Heat = make([][]string, len(data.Rows))
+		for i, classes := range heatClasses(input, output, cacheWrite, cacheRead, total, cost) {
+			data.Heat[charted[i]] = classes
+		}
+	}
+
+	return analyzeHTMLTemplate.Execute(w, data)
+}
+
+// analyzeTokenSegments stacks the kinds of tokens of result into a bar scaled by scale pixels per token
+func analyzeTokenSegments(result models.AnalysisResult, scale float64) []analyzeSegment {
+	kinds := []struct {
+		class, name string
+		tokens      int
+	}{
+		{"input", "Input", result.InputTokens},
+		{"output", "Output", result.OutputTokens},
+		{"cache-write", "Cache write", result.CacheCreationTokens},
+		{"cache-read", "Cache read", result.CacheReadTokens},
+	}
+	var segments []analyzeSegment
+	x := 0.0
+	for _, kind := range kinds {
+		if kind.tokens == 0 {
+			continue
+		}
+		width := float64(kind.tokens) * scale
+		segments = append(segments, analyzeSegment{
+			Class: kind.class,
+			X:     x,
+			Width: width,
+			Title: fmt.Sprintf("%s: %s tokens", kind.name, humanize.Count(kind.tokens)),
+		})
+		x += width
+	}
+	return segments
+}
+
+// analyzeHTMLRange describes the --from and --to range of the analysis
+func analyzeHTMLRange() string {
+	switch {
+	case analyzeFrom != "" && analyzeTo != "":
+		return fmt.Sprintf("From %s to %s", analyzeFrom, analyzeTo)
+	case analyzeFrom != "":
+		return "Since " + analyzeFrom
+	case analyzeTo != "":
+		return "Until " + analyzeTo
+	default:
+		return "All recorded usage"
+	}
+}
+
+var analyzeHTMLTemplate = template.Must(template.New("analyze").Funcs(template.FuncMap{
+	"cost":   humanize.Cost,
+	"commas": humanize.Count,
+	"heat": func(classes [][]string, row, col int) string {
+		if row < len(classes) && col < len(classes[row]) {
+			return classes[row][col]
+		}
+		return ""
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; color: #1f2328; max-width: 1200px; margin: 2em auto; padding: 0 1em; }
+h1 { font-size: 1.6em; margin-bottom: 0.2em; }
+.range { color: #59636e; margin-top: 0; }
+table { border-collapse: collapse; width: 100%; font-size: 0.9em; }
+th, td { border-bottom: 1px solid #d1d9e0; padding: 0.4em 0.6em; text-align: left; }
+td.num, th.num { text-align: right; font-variant-numeric: tabular-nums; }
+tr.total td, tr.summary td { font-weight: 600; }
+svg { vertical-align: middle; }
+.bar { fill: #d97757; }
+.input { fill: #0969da; }
+.output { fill: #1a7f37; }
+.cache-write { fill: #bf8700; }
+.cache-read { fill: #8c959f; }
+.legend span { display: inline-block; width: 0.8em; height: 0.8em; margin: 0 0.3em 0 1em; vertical-align: -0.05em; }
+.legend .input { background: #0969da; }
+.legend .output { background: #1a7f37; }
+.legend .cache-write { background: #bf8700; }
+.legend .cache-read { background: #8c959f; }
+.heat-low { background: #dafbe1; }
+.heat-medium { background: #fff8c5; }
+.heat-high { background: #ffebe9; }
+footer { color: #59636e; font-size: 0.8em; margin-top: 2em; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="range">{{.Range}}</p>
+{{- if .Rows}}
+<p class="legend">Tokens:<span class="input"></span>Input<span class="output"></span>Output<span class="cache-write"></span>Cache write<span class="cache-read"></span>Cache read</p>
+<table>
+<tr><th>{{.GroupHeader}}</th>{{if .ShowModels}}<th>Models</th>{{end}}<th class="num">Entries</th><th class="num">Input</th><th class="num">Output</th><th class="num">Cache Create</th><th class="num">Cache Read</th><th class="num">Total Tokens</th><th>Tokens</th><th class="num">Cost (USD)</th><th>Cost</th></tr>
+{{- range $i, $row := .Rows}}
+{{- with .Result}}
+<tr{{if $row.Total}} class="total"{{end}}><td>{{$row.Label}}</td>{{if $.ShowModels}}<td>{{$row.Models}}</td>{{end}}<td class="num">{{commas .Count}}</td><td class="num{{heat $.Heat $i 0}}">{{commas .InputTokens}}</td><td class="num{{heat $.Heat $i 1}}">{{commas .OutputTokens}}</td><td class="num{{heat $.Heat $i 2}}">{{commas .CacheCreationTokens}}</td><td class="num{{heat $.Heat $i 3}}">{{commas .CacheReadTokens}}</td><td class="num{{heat $.Heat $i 4}}">{{commas .TotalTokens}}</td>
+<td>{{if $row.Segments}}<svg width="{{$.BarWidth}}" height="12" role="img" aria-label="Token mix">{{range $row.Segments}}<rect class="{{.Class}}" x="{{.X}}" width="{{.Width}}" height="12"><title>{{.Title}}</title></rect>{{end}}</svg>{{end}}</td>
+<td class="num{{heat $.Heat $i 5}}">{{cost .CostUSD}}</td>
+<td>{{if $row.CostWidth}}<svg width="{{$.BarWidth}}" height="12" role="img" aria-label="Cost"><rect class="bar" width="{{$row.CostWidth}}" height="12"><title>{{cost .CostUSD}}</title></rect></svg>{{end}}</td></tr>
+{{- end}}
+{{- end}}
+{{- with .Summary}}
+<tr class="summary"><td>TOTAL</td>{{if $.ShowModels}}<td></td>{{end}}<td class="num">{{commas .Count}}</td><td class="num">{{commas .InputTokens}}</td><td class="num">{{commas .OutputTokens}}</td><td class="num">{{commas .CacheCreationTokens}}</td><td class="num">{{commas .CacheReadTokens}}</td><td class="num">{{commas .TotalTokens}}</td><td></td><td class="num">{{cost .CostUSD}}</td><td></td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p>No data to display.</p>
+{{- end}}
+
+<footer>Generated by claudecat on {{.Generated}}</footer>
+</body>
+</html>
+`))
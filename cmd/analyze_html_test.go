@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputHTML(t *testing.T) {
+	defer func(groupBy string, breakdown, heat bool) {
+		analyzeGroupBy, analyzeBreakdown, analyzeHeat = groupBy, breakdown, heat
+	}(analyzeGroupBy, analyzeBreakdown, analyzeHeat)
+	analyzeGroupBy, analyzeBreakdown, analyzeHeat = "project", false, false
+
+	results := []models.AnalysisResult{
+		{GroupKey: "<script>alert(1)</script>", Count: 2, InputTokens: 600, OutputTokens: 400, TotalTokens: 1000, CostUSD: 2},
+		{GroupKey: "r&d", Count: 1, InputTokens: 500, TotalTokens: 500, CostUSD: 0.5},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, outputHTML(&buf, results, time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)))
+	page := buf.String()
+
+	assert.NotContains(t, page, "<script>", "project names are escaped")
+	assert.Contains(t, page, "<td>&lt;script&gt;alert(1)&lt;/script&gt;</td>")
+	assert.Contains(t, page, "<td>r&amp;d</td>")
+
+	// Cost bars are scaled to the most expensive row, token bars to the row with the most tokens
+	assert.Contains(t, page, `<rect class="bar" width="160"`)
+	assert.Contains(t, page, `<rect class="bar" width="40"`)
+	assert.Contains(t, page, `<rect class="input" x="0" width="96"`)
+	assert.Contains(t, page, `<rect class="output" x="96" width="64"`)
+	assert.Contains(t, page, `<rect class="input" x="0" width="80"`)
+	assert.Contains(t, page, `<td class="num">1,500</td>`, "the summary row totals the tokens")
+}
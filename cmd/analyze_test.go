@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockLabel(t *testing.T) {
+	start := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		end    time.Time
+		active bool
+		want   string
+	}{
+		{"same day", start.Add(5 * time.Hour), false, "2025-06-01 10:00 - 15:00"},
+		{"past midnight", start.Add(15 * time.Hour), false, "2025-06-01 10:00 - 2025-06-02 01:00"},
+		{"active", start.Add(5 * time.Hour), true, "2025-06-01 10:00 - 15:00 (active)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, blockLabel(start, tt.end, tt.active))
+		})
+	}
+}
+
+func TestApplyBlockGrouping(t *testing.T) {
+	defer func(groupBy string) { analyzeGroupBy = groupBy }(analyzeGroupBy)
+	analyzeGroupBy = "block"
+
+	start := time.Date(2025, 6, 1, 10, 20, 0, 0, time.UTC)
+	results := []models.AnalysisResult{
+		{Timestamp: start, Model: "claude-sonnet-4-20250514", InputTokens: 100, TotalTokens: 100, CostUSD: 1},
+		{Timestamp: start.Add(time.Hour), Model: "claude-opus-4-20250514", OutputTokens: 50, TotalTokens: 50, CostUSD: 2},
+		{Timestamp: start.Add(7 * time.Hour), Model: "claude-sonnet-4-20250514", CacheReadTokens: 10, TotalTokens: 10, CostUSD: 0.5},
+	}
+	assert.Empty(t, applyGrouping(nil))
+
+	grouped := applyGrouping(results)
+	tests := []struct {
+		label  string
+		count  int
+		tokens int
+		cost   float64
+	}{
+		{"2025-06-01 10:00 - 15:00", 2, 150, 3},
+		{"2025-06-01 17:00 - 22:00", 1, 10, 0.5},
+	}
+	require.Len(t, grouped, len(tests), "the gap between the blocks is left out")
+	assert.Contains(t, grouped[0].Model, ", ", "a block lists every model used in it")
+	for i, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			group := grouped[i]
+			assert.Equal(t, tt.label, group.GroupKey)
+			assert.Equal(t, tt.count, group.Count)
+			assert.Equal(t, tt.tokens, group.TotalTokens)
+			assert.InDelta(t, tt.cost, group.CostUSD, 1e-9)
+			require.NotNil(t, group.BlockEnd)
+			assert.Equal(t, group.Timestamp.Add(models.SessionDuration), *group.BlockEnd)
+			assert.False(t, group.BlockActive)
+		})
+	}
+}
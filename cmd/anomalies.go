@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/penwyp/claudecat/calculations"
+	"github.com/penwyp/claudecat/humanize"
+	"github.com/penwyp/claudecat/internal"
+	"github.com/spf13/cobra"
+)
+
+var (
+	anomaliesWindow  string
+	anomaliesOutput  string
+	anomaliesWebhook string
+)
+
+// anomaliesReport is the JSON output of the anomalies command and the payload of its webhook
+type anomaliesReport struct {
+	Start     time.Time                   `json:"start"`
+	End       time.Time                   `json:"end"`
+	Timezone  string                      `json:"timezone"`
+	Anomalies []calculations.UsageAnomaly `json:"anomalies"`
+}
+
+var anomaliesCmd = &cobra.Command{
+	Use:   "anomalies [path...]",
+	Short: "List the days of unusually high spend",
+	Long: `Rank the days of the window whose cost was unusually high. A day is an anomaly when its cost is
+more than two standard deviations above the daily average of the four weeks before it, the rule
+digest applies to its period. Each anomaly shows the cost expected from that average, the actual
+cost, its severity (medium at twice the average, high at four times) and the model and project that
+cost the most that day.
+
+With --webhook, the report is posted as JSON when at least one anomaly is found.
+
+Examples:
+  claudecat anomalies                                  # The last 90 days
+  claudecat anomalies --window 30d                     # The last 30 days
+  claudecat anomalies -o json                          # Machine-readable
+  claudecat anomalies --webhook https://hooks.example.com/claude`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output := strings.ToLower(anomaliesOutput)
+		if output != "table" && output != "json" {
+			return fmt.Errorf("invalid output format: %s (valid: table, json)", anomaliesOutput)
+		}
+		days, err := parseWindowDays(anomaliesWindow)
+		if err != nil {
+			return err
+		}
+		if anomaliesWebhook != "" {
+			if u, err := url.Parse(anomaliesWebhook); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("invalid webhook URL: %s", anomaliesWebhook)
+			}
+		}
+
+		cfg, err := loadCacheCommandConfig(cmd, args)
+		if err != nil {
+			return err
+		}
+		loc, err := time.LoadLocation(cfg.App.Timezone)
+		if err != nil {
+			loc = time.Local
+		}
+		analyzer, err := internal.NewAnalyzer(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create analyzer: %w", err)
+		}
+		now := time.Now()
+		anomalies, err := analyzer.Anomalies(cfg.Data.Paths, days, now)
+		if err != nil {
+			return fmt.Errorf("anomaly detection failed: %w", err)
+		}
+		recordCommandResult("anomalies", len(anomalies))
+
+		report := anomaliesReport{
+			Start:     calculations.StartOfDay(now, loc).AddDate(0, 0, -(days - 1)),
+			End:       now,
+			Timezone:  loc.String(),
+			Anomalies: anomalies,
+		}
+		data, err := sonic.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		if output == "json" {
+			if _, err := os.Stdout.Write(append(data, '\n')); err != nil {
+				return err
+			}
+		} else {
+			printAnomalies(report, loc)
+		}
+
+		if anomaliesWebhook != "" && len(anomalies) > 0 {
+			if err := internal.PostWebhook(context.Background(), anomaliesWebhook, data); err != nil {
+				return fmt.Errorf("webhook delivery failed: %w", err)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	anomaliesCmd.Flags().StringVar(&anomaliesWindow, "window", "90d", "days to check, up to today (e.g. 30d or 12w)")
+	anomaliesCmd.Flags().StringVarP(&anomaliesOutput, "output", "o", "table", "output format (table, json)")
+	anomaliesCmd.Flags().StringVar(&anomaliesWebhook, "webhook", "", "URL the report JSON is posted to when anomalies are found")
+	markHistorySecret(anomaliesCmd.Flags(), "webhook")
+	rootCmd.AddCommand(anomaliesCmd)
+}
+
+// parseWindowDays parses a window given in days or weeks, such as 90d or 12w
+func parseWindowDays(value string) (int, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if len(value) > 1 {
+		if n, err := strconv.Atoi(value[:len(value)-1]); err == nil && n > 0 {
+			switch value[len(value)-1] {
+			case 'd':
+				return n, nil
+			case 'w':
+				return n * 7, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("invalid window: %s (e.g. 90d or 12w)", value)
+}
+
+// printAnomalies prints one row per anomalous day, the most unusual first
+func printAnomalies(report anomaliesReport, loc *time.Location) {
+	window := fmt.Sprintf("%s to %s", report.Start.Format("2006-01-02"), report.End.In(loc).Format("2006-01-02"))
+	if len(report.Anomalies) == 0 {
+		fmt.Printf("No anomalies from %s.\n", window)
+		return
+	}
+	share := func(top *calculations.DigestShare) string {
+		if top == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%s (%.0f%%)", top.Name, top.Share*100)
+	}
+	table := newTableFormatter([]string{"Date", "Expected Cost", "Actual Cost", "Ratio", "Severity", "Top Model", "Top Project"})
+	for _, anomaly := range report.Anomalies {
+		table.addRow([]string{
+			anomaly.Date.Format("2006-01-02 Mon"),
+			humanize.Cost(anomaly.Expected),
+			humanize.Cost(anomaly.Actual),
+			fmt.Sprintf("%.1fx", anomaly.Ratio),
+			anomaly.Severity,
+			share(anomaly.TopModel),
+			share(anomaly.TopProject),
+		})
+	}
+	fmt.Println(table.render())
+	fmt.Printf("%d anomalous day(s) from %s\n", len(report.Anomalies), window)
+}
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/penwyp/claudecat/calculations"
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/internal"
+	"github.com/spf13/cobra"
+)
+
+// assertExitFailed is the exit code of a failed assertion; 1 is left to errors
+const assertExitFailed = 2
+
+var (
+	assertOutput   string
+	assertPaths    []string
+	assertListVars bool
+)
+
+var assertCmd = &cobra.Command{
+	Use:   "assert <expression>...",
+	Short: "Check usage against expressions and fail the ones that do not hold",
+	Long: `Evaluate each expression against the current usage and exit with status 2 if any does not
+hold, for usage gates in CI jobs and team policy scripts. An expression is "<variable> <op> <value>",
+with the syntax of the alert rules: the operators are <, <=, > and >=, the value is a number such as
+50, $50 or 5k, or a percentile (p50, p75, p90, p99) of the completed sessions or past days, and a
+rate takes an optional "for <duration>" window.
+
+Run claudecat assert --list-vars for the variables. Session variables have no value without an
+active session; such an expression is reported as "no data" and does not fail the check.
+
+The exit status is 0 when every expression holds, 2 when one does not and 1 on errors.
+
+Examples:
+  claudecat assert "daily_cost < 50" "session_tokens_pct < 90"
+  claudecat assert "weekly_cost <= $200" -o json
+  claudecat assert --list-vars`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if assertListVars {
+			printAssertVariables()
+			return nil
+		}
+		output := strings.ToLower(assertOutput)
+		if output != "table" && output != "json" {
+			return fmt.Errorf("invalid output format: %s (valid: table, json)", assertOutput)
+		}
+		if len(args) == 0 {
+			return fmt.Errorf("no expression given: see claudecat assert --list-vars for the variables")
+		}
+		rules := make([]config.AlertRule, 0, len(args))
+		for _, arg := range args {
+			rule, err := config.ParseAlertRule(config.AlertRuleConfig{When: arg})
+			if err != nil {
+				return err
+			}
+			rules = append(rules, rule)
+		}
+
+		cfg, err := loadCacheCommandConfig(cmd, assertPaths)
+		if err != nil {
+			return err
+		}
+		analyzer, err := internal.NewAnalyzer(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create analyzer: %w", err)
+		}
+		results, err := analyzer.AlertRules(cfg.Data.Paths, rules, time.Now())
+		if err != nil {
+			return fmt.Errorf("assertion check failed: %w", err)
+		}
+		failed := 0
+		for _, result := range results {
+			if !result.NoData && !result.Firing {
+				failed++
+			}
+		}
+		recordCommandResult("failed", failed)
+
+		if output == "json" {
+			data, err := sonic.MarshalIndent(assertResults(results), "", "  ")
+			if err != nil {
+				return err
+			}
+			if _, err := os.Stdout.Write(append(data, '\n')); err != nil {
+				return err
+			}
+		} else {
+			printAssertions(results)
+		}
+		if failed > 0 {
+			return &ExitCodeError{Code: assertExitFailed, Reason: fmt.Sprintf("%d of %d assertions failed", failed, len(results))}
+		}
+		return nil
+	},
+}
+
+// assertResult is the JSON output of an expression
+type assertResult struct {
+	Expression string  `json:"expression"`
+	Value      float64 `json:"value"`
+	Threshold  float64 `json:"threshold"` // The expression's value, or the percentile it resolved to
+	Status     string  `json:"status"`    // pass, fail or no_data
+	SessionID  string  `json:"session_id,omitempty"`
+}
+
+func init() {
+	assertCmd.Flags().StringVarP(&assertOutput, "output", "o", "table", "output format (table, json)")
+	assertCmd.Flags().StringSliceVarP(&assertPaths, "paths", "p", nil, "data paths to check (default: the configured paths)")
+	assertCmd.Flags().BoolVar(&assertListVars, "list-vars", false, "list the variables expressions can use")
+	rootCmd.AddCommand(assertCmd)
+}
+
+// assertStatus returns whether the expression of result holds
+func assertStatus(result calculations.AlertRuleResult) string {
+	switch {
+	case result.NoData:
+		return "no_data"
+	case result.Firing:
+		return "pass"
+	default:
+		return "fail"
+	}
+}
+
+// assertResults converts the rule results of the expressions to their JSON output
+func assertResults(results []calculations.AlertRuleResult) []assertResult {
+	out := make([]assertResult, 0, len(results))
+	for _, result := range results {
+		out = append(out, assertResult{
+			Expression: result.Rule.Condition(),
+			Value:      result.Value,
+			Threshold:  result.Threshold,
+			Status:     assertStatus(result),
+			SessionID:  result.SessionID,
+		})
+	}
+	return out
+}
+
+// printAssertions prints one row per expression
+func printAssertions(results []calculations.AlertRuleResult) {
+	table := newTableFormatter([]string{"Expression", "Value", "Threshold", "Status"})
+	for _, result := range results {
+		value, threshold := "-", "-"
+		if !result.NoData {
+			value = internal.FormatRuleValue(result.Rule.Metric, result.Value)
+			threshold = internal.FormatRuleValue(result.Rule.Metric, result.Threshold)
+		}
+		status := map[string]string{"pass": "ok", "fail": "FAILED", "no_data": "no data"}[assertStatus(result)]
+		table.addRow([]string{result.Rule.Condition(), value, threshold, status})
+	}
+	fmt.Println(table.render())
+}
+
+// printAssertVariables prints the variables of expressions with their descriptions
+func printAssertVariables() {
+	table := newTableFormatter([]string{"Variable", "Description"})
+	for _, metric := range config.RuleMetrics {
+		table.addRow([]string{metric, config.RuleMetricDescriptions[metric]})
+	}
+	fmt.Println(table.render())
+}
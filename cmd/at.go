@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/penwyp/claudecat/humanize"
+	"github.com/penwyp/claudecat/internal"
+	"github.com/penwyp/claudecat/sessions"
+	"github.com/spf13/cobra"
+)
+
+var atOutput string
+
+var atCmd = &cobra.Command{
+	Use:   "at <time> [path...]",
+	Short: "Show what the monitor showed at a past time",
+	Long: `Rebuild what the monitor would have shown at a past moment from the usage logged up to it: the
+session in progress with its token, cost and message usage against the plan's limits, the time
+elapsed and left, the burn rate, whether the tokens were on course to run out before the reset,
+and the limit messages Claude had logged in that session.
+
+The time is read in the configured timezone, as YYYY-MM-DD HH:MM[:SS], RFC 3339, or a weekday and
+time such as "tue 15:00" for its most recent past occurrence.
+
+Examples:
+  claudecat at "2025-06-03 15:00"          # Why the limit was hit that afternoon
+  claudecat at "tue 15:00"                 # Last Tuesday at 3pm
+  claudecat at "2025-06-03 15:00" -o json  # Machine-readable`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output := strings.ToLower(atOutput)
+		if output != "table" && output != "json" {
+			return fmt.Errorf("invalid output format: %s (valid: table, json)", atOutput)
+		}
+
+		cfg, err := loadCacheCommandConfig(cmd, args[1:])
+		if err != nil {
+			return err
+		}
+		loc, err := time.LoadLocation(cfg.App.Timezone)
+		if err != nil {
+			loc = time.Local
+		}
+		now := time.Now()
+		at, err := parseMoment(args[0], loc, now)
+		if err != nil {
+			return err
+		}
+		if at.After(now) {
+			return fmt.Errorf("%s is in the future", args[0])
+		}
+
+		analyzer, err := internal.NewAnalyzer(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create analyzer: %w", err)
+		}
+		moment, err := analyzer.At(cfg.Data.Paths, at, now)
+		if err != nil {
+			return fmt.Errorf("replay failed: %w", err)
+		}
+		recordCommandResult("active", boolCount(moment.Active != nil))
+
+		if output == "json" {
+			data, err := sonic.MarshalIndent(moment, "", "  ")
+			if err != nil {
+				return err
+			}
+			_, err = os.Stdout.Write(append(data, '\n'))
+			return err
+		}
+		printMoment(moment, loc)
+		return nil
+	},
+}
+
+func init() {
+	atCmd.Flags().StringVarP(&atOutput, "output", "o", "table", "output format (table, json)")
+	rootCmd.AddCommand(atCmd)
+}
+
+// momentWeekdays maps the weekday names accepted by parseMoment
+var momentWeekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// parseMoment parses a past time in loc: a date and time, RFC 3339, or a weekday and time meaning
+// its most recent occurrence before now
+func parseMoment(value string, loc *time.Location, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02 15:04:05", "2006-01-02T15:04", "2006-01-02T15:04:05"} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+
+	if day, clockTime, ok := strings.Cut(strings.ToLower(value), " "); ok && len(day) >= 3 {
+		weekday, known := momentWeekdays[day[:3]]
+		hm, err := time.Parse("15:04", strings.TrimSpace(clockTime))
+		if known && err == nil {
+			local := now.In(loc)
+			t := time.Date(local.Year(), local.Month(), local.Day(), hm.Hour(), hm.Minute(), 0, 0, loc)
+			t = t.AddDate(0, 0, -((int(local.Weekday()) - int(weekday) + 7) % 7))
+			if t.After(now) {
+				t = t.AddDate(0, 0, -7)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time: %s (use YYYY-MM-DD HH:MM, RFC 3339 or a weekday and time like \"tue 15:00\")", value)
+}
+
+// boolCount returns 1 for true and 0 for false
+func boolCount(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// printMoment prints the session of the moment with its usage, rates and predictions
+func printMoment(moment sessions.Moment, loc *time.Location) {
+	fmt.Printf("Monitor at %s\n\n", moment.At.In(loc).Format("Mon 2006-01-02 15:04 MST"))
+	block := moment.Block
+	if block == nil {
+		fmt.Println("No usage had been logged yet.")
+		return
+	}
+	window := fmt.Sprintf("%s - %s", block.Start.In(loc).Format("2006-01-02 15:04"), block.End.In(loc).Format("15:04"))
+	if moment.Active == nil {
+		fmt.Printf("No session was active. The last one, %s, used %s tokens and %s.\n", window,
+			humanize.Count(block.TotalTokens), humanize.Cost(block.CostUSD))
+		return
+	}
+
+	usage := func(percent float64, hasLimit bool) string {
+		if !hasLimit {
+			return ""
+		}
+		return fmt.Sprintf(" (%.1f%%)", percent)
+	}
+	fmt.Printf("Session:     %s, %s elapsed, %s to reset\n", window,
+		humanize.Duration(moment.Active.Elapsed), humanize.Duration(moment.Active.Remaining))
+	fmt.Printf("Cost:        %s / %s%s\n", humanize.Cost(block.CostUSD), humanize.Cost(moment.CostLimit),
+		usage(block.CostPercent, moment.CostLimit > 0))
+	fmt.Printf("Tokens:      %s / %s%s\n", humanize.Count(block.TotalTokens), humanize.Count(moment.TokenLimit),
+		usage(block.TokenPercent, moment.TokenLimit > 0))
+	fmt.Printf("Messages:    %d / %s%s\n", moment.Messages, humanize.Count(moment.MessageLimit),
+		usage(moment.MessagePercent, moment.MessageLimit > 0))
+	fmt.Printf("Burn rate:   %s tokens/min, %s/hour\n", humanize.Count(int(moment.Active.TokensPerMinute)),
+		humanize.Cost(moment.Active.CostPerHour))
+	fmt.Printf("Models:      %s\n", formatModels(block.Models))
+
+	fmt.Println()
+	switch {
+	case moment.TokenLimit > 0 && block.TotalTokens >= moment.TokenLimit:
+		fmt.Printf("The token limit had been reached; it resets at %s.\n", block.End.In(loc).Format("15:04"))
+	case moment.TokensRunOut != nil:
+		fmt.Printf("At this rate the tokens would run out at %s, before the reset at %s.\n",
+			moment.TokensRunOut.In(loc).Format("15:04"), block.End.In(loc).Format("15:04"))
+	default:
+		fmt.Printf("At this rate the tokens would last until the reset at %s.\n", block.End.In(loc).Format("15:04"))
+	}
+	if moment.Active.ProjectedTokens > 0 {
+		fmt.Printf("Projected by the reset: %s tokens, %s\n", humanize.Count(moment.Active.ProjectedTokens),
+			humanize.Cost(moment.Active.ProjectedCostUSD))
+	}
+
+	if len(moment.LimitMessages) > 0 {
+		fmt.Printf("\nLimit messages in this session:\n")
+		for _, limit := range moment.LimitMessages {
+			fmt.Printf("  %s  %s\n", limit.Timestamp.In(loc).Format("15:04"), limit.Message)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/penwyp/claudecat/cache"
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/fileio"
+	"github.com/penwyp/claudecat/humanize"
+	"github.com/penwyp/claudecat/logging"
+	"github.com/penwyp/claudecat/models"
+	"github.com/penwyp/claudecat/models/pricing"
+	"github.com/spf13/cobra"
+)
+
+var (
+	benchmarkOutput       string
+	benchmarkWorkers      []int
+	benchmarkMaxLineSizes []int
+	benchmarkNoCache      bool
+)
+
+var benchmarkCmd = &cobra.Command{
+	Use:   "benchmark [path...]",
+	Short: "Measure how fast usage data is loaded",
+	Long: `Measure loading your usage data: the discovery of the JSONL files, their parse throughput in
+MB/s and entries/s with each worker count and line buffer size, the overhead of deduplicating
+entries logged in several files, and the speedup of loading from cached summaries.
+
+The parse runs read every file without the cache. The cache runs load into a temporary cache and
+then again from it, so the configured cache is neither read nor changed. Compare worker counts
+and line buffer sizes to tune data.max_line_size for your disk and data.
+
+Examples:
+  claudecat benchmark                              # One run per default worker count
+  claudecat benchmark --workers 1,4,8,16           # Compare worker counts
+  claudecat benchmark --max-line-size 1048576,10485760
+  claudecat benchmark --no-cache -o json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !strings.EqualFold(benchmarkOutput, "table") && !strings.EqualFold(benchmarkOutput, "json") {
+			return fmt.Errorf("invalid output format: %s (valid: table, json)", benchmarkOutput)
+		}
+		for _, workers := range benchmarkWorkers {
+			if workers < 1 {
+				return fmt.Errorf("invalid worker count: %d (must be at least 1)", workers)
+			}
+		}
+		for _, size := range benchmarkMaxLineSizes {
+			if size < 1 {
+				return fmt.Errorf("invalid max line size: %d (must be at least 1)", size)
+			}
+		}
+		cfg, err := loadCacheCommandConfig(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		workers := benchmarkWorkers
+		if len(workers) == 0 {
+			workers = defaultBenchmarkWorkers()
+		}
+		lineSizes := benchmarkMaxLineSizes
+		if len(lineSizes) == 0 && cfg.Data.MaxLineSize > 0 {
+			lineSizes = []int{cfg.Data.MaxLineSize}
+		}
+		pricingProvider, err := pricing.CreatePricingProvider(&cfg.Data, config.ExpandHome(cfg.Cache.Dir))
+		if err != nil {
+			logging.LogWarnf("Failed to create pricing provider: %v", err)
+			pricingProvider = pricing.NewDefaultProvider()
+		}
+
+		reports := make([]fileio.BenchmarkReport, 0, len(cfg.Data.Paths))
+		for _, dataPath := range cfg.Data.Paths {
+			report, err := benchmarkDataPath(cmd, dataPath, workers, lineSizes, pricingProvider)
+			if err != nil {
+				return fmt.Errorf("benchmark of %s failed: %w", dataPath, err)
+			}
+			reports = append(reports, report)
+		}
+
+		files := 0
+		for _, report := range reports {
+			files += report.Files
+		}
+		recordCommandResult("files", files)
+		if strings.EqualFold(benchmarkOutput, "json") {
+			data, err := sonic.MarshalIndent(reports, "", "  ")
+			if err != nil {
+				return err
+			}
+			_, err = os.Stdout.Write(append(data, '\n'))
+			return err
+		}
+		for i, report := range reports {
+			if i > 0 {
+				fmt.Println()
+			}
+			printBenchmarkReport(report)
+		}
+		return nil
+	},
+}
+
+func init() {
+	benchmarkCmd.Flags().StringVarP(&benchmarkOutput, "output", "o", "table", "output format (table, json)")
+	benchmarkCmd.Flags().IntSliceVar(&benchmarkWorkers, "workers", nil, "worker counts to compare (default 1, half and all CPUs)")
+	benchmarkCmd.Flags().IntSliceVar(&benchmarkMaxLineSizes, "max-line-size", nil, "line buffer sizes in bytes to compare (default data.max_line_size)")
+	benchmarkCmd.Flags().BoolVar(&benchmarkNoCache, "no-cache", false, "skip the cold and warm cache runs")
+	rootCmd.AddCommand(benchmarkCmd)
+}
+
+// defaultBenchmarkWorkers returns one worker, half and all of the CPUs
+func defaultBenchmarkWorkers() []int {
+	var workers []int
+	for _, n := range []int{1, runtime.NumCPU() / 2, runtime.NumCPU()} {
+		if n > 0 && !slices.Contains(workers, n) {
+			workers = append(workers, n)
+		}
+	}
+	return workers
+}
+
+// benchmarkDataPath benchmarks a data path, with a temporary cache for the cache runs
+func benchmarkDataPath(cmd *cobra.Command, dataPath string, workers, lineSizes []int, pricingProvider models.PricingProvider) (fileio.BenchmarkReport, error) {
+	opts := fileio.BenchmarkOptions{
+		DataPath:        dataPath,
+		Mode:            models.CostModeCalculated,
+		PricingProvider: pricingProvider,
+		Workers:         workers,
+		MaxLineSizes:    lineSizes,
+	}
+	if !benchmarkNoCache {
+		dir, err := os.MkdirTemp("", "claudecat-benchmark-")
+		if err != nil {
+			return fileio.BenchmarkReport{}, fmt.Errorf("failed to create temporary cache: %w", err)
+		}
+		defer os.RemoveAll(dir)
+		store, err := cache.NewFileBasedSummaryCache(dir)
+		if err != nil {
+			return fileio.BenchmarkReport{}, fmt.Errorf("failed to create temporary cache: %w", err)
+		}
+		defer store.Close()
+		opts.CacheStore = store
+	}
+	return fileio.Benchmark(cmd.Context(), opts)
+}
+
+// printBenchmarkReport prints the discovery, parse runs, deduplication and cache results of a data path
+func printBenchmarkReport(report fileio.BenchmarkReport) {
+	fmt.Printf("Data path:  %s (%d files, %s)\n", report.DataPath, report.Files, humanize.Bytes(report.Bytes))
+	fmt.Printf("Discovery:  %v\n", report.Discovery.Round(time.Microsecond))
+	if report.Files == 0 {
+		return
+	}
+
+	fmt.Println()
+	table := newTableFormatter([]string{"Workers", "Line buffer", "Time", "MB/s", "Entries/s"})
+	for _, run := range report.Parse {
+		table.addRow([]string{
+			strconv.Itoa(run.Workers),
+			humanize.Bytes(int64(run.MaxLineSize)),
+			run.Duration.Round(time.Microsecond).String(),
+			fmt.Sprintf("%.1f", run.BytesPerSec/(1024*1024)),
+			humanize.Count(int(run.EntriesPerSec)),
+		})
+	}
+	fmt.Println(table.render())
+	if fastest, ok := report.Fastest(); ok && len(report.Parse) > 1 {
+		fmt.Printf("Fastest:    %d workers with a %s line buffer\n", fastest.Workers, humanize.Bytes(int64(fastest.MaxLineSize)))
+	}
+
+	fmt.Printf("Dedup:      %v merge, %v with deduplication (%.1f%% overhead), %s duplicates\n",
+		report.MergeDuration.Round(time.Microsecond), report.DedupDuration.Round(time.Microsecond),
+		report.DedupOverhead()*100, humanize.Count(report.Duplicates))
+	if speedup := report.CacheSpeedup(); speedup > 0 {
+		fmt.Printf("Cache:      %v cold, %v warm (%.1fx faster, %.0f%% hits)\n",
+			report.CacheCold.Round(time.Microsecond), report.CacheWarm.Round(time.Microsecond), speedup, report.CacheHitRate*100)
+	}
+}
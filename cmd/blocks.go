@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/penwyp/claudecat/humanize"
+	"github.com/penwyp/claudecat/internal"
+	"github.com/penwyp/claudecat/sessions"
+	"github.com/spf13/cobra"
+)
+
+var (
+	blocksOutput     string
+	blocksFrom       string
+	blocksTo         string
+	blocksActive     bool
+	blocksRecent     bool
+	blocksHideGaps   bool
+	blocksTokenLimit string
+)
+
+// blocksRecentDays is how far back --recent lists blocks
+const blocksRecentDays = 3
+
+var blocksCmd = &cobra.Command{
+	Use:   "blocks [path...]",
+	Short: "Report usage per 5-hour billing block",
+	Long: `Report usage aligned to Claude's 5-hour billing windows: each block's start and end, tokens,
+cost, share of the plan's limits and whether a limit was hit, along with the block in progress, its
+remaining time, burn rate and projected usage at the block end.
+
+A block hits a limit when Claude logged a limit message in it or its usage reached the token or cost
+limit. Limits come from the configured plan; --token-limit overrides the token limit, and
+--token-limit max measures blocks against the most tokens of any completed block without counting
+reaching it as a hit.
+
+Examples:
+  claudecat blocks                                  # Every billing block so far
+  claudecat blocks --recent                         # The last 3 days
+  claudecat blocks --active                         # Only the block in progress
+  claudecat blocks --token-limit max --hide-gaps    # Usage against your busiest block
+  claudecat blocks --from 2025-06-01 -o json        # Machine-readable`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output := strings.ToLower(blocksOutput)
+		if output != "table" && output != "json" {
+			return fmt.Errorf("invalid output format: %s (valid: table, json)", blocksOutput)
+		}
+		opts := sessions.BlockOptions{Filter: sessions.ListFilter{ActiveOnly: blocksActive, HideGaps: blocksHideGaps}}
+		switch {
+		case blocksTokenLimit == "":
+		case strings.EqualFold(blocksTokenLimit, "max"):
+			opts.MaxTokenLimit = true
+		default:
+			limit, err := strconv.Atoi(blocksTokenLimit)
+			if err != nil || limit <= 0 {
+				return fmt.Errorf("invalid token limit: %s (use a positive number or max)", blocksTokenLimit)
+			}
+			opts.TokenLimit = limit
+		}
+
+		cfg, err := loadCacheCommandConfig(cmd, args)
+		if err != nil {
+			return err
+		}
+		loc, err := time.LoadLocation(cfg.App.Timezone)
+		if err != nil {
+			loc = time.Local
+		}
+
+		now := time.Now()
+		if blocksFrom != "" {
+			if opts.Filter.From, err = parseSessionsBound(blocksFrom, loc, false); err != nil {
+				return fmt.Errorf("invalid from date %s: %w", blocksFrom, err)
+			}
+		} else if blocksRecent {
+			opts.Filter.From = now.AddDate(0, 0, -blocksRecentDays)
+		}
+		if blocksTo != "" {
+			if opts.Filter.To, err = parseSessionsBound(blocksTo, loc, true); err != nil {
+				return fmt.Errorf("invalid to date %s: %w", blocksTo, err)
+			}
+		}
+		if !opts.Filter.From.IsZero() && !opts.Filter.To.IsZero() && opts.Filter.To.Before(opts.Filter.From) {
+			return fmt.Errorf("--to must not be before --from")
+		}
+
+		analyzer, err := internal.NewAnalyzer(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create analyzer: %w", err)
+		}
+		report, err := analyzer.Blocks(cfg.Data.Paths, opts, now)
+		if err != nil {
+			return fmt.Errorf("block report failed: %w", err)
+		}
+		recordCommandResult("blocks", len(report.Blocks))
+
+		if output == "json" {
+			data, err := sonic.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return err
+			}
+			_, err = os.Stdout.Write(append(data, '\n'))
+			return err
+		}
+
+		if len(report.Blocks) == 0 {
+			fmt.Println("No blocks to display.")
+			return nil
+		}
+		printBlocks(report, loc, now)
+		return nil
+	},
+}
+
+func init() {
+	blocksCmd.Flags().StringVarP(&blocksOutput, "output", "o", "table", "output format (table, json)")
+	blocksCmd.Flags().StringVar(&blocksFrom, "from", "", "list blocks ending after this date (YYYY-MM-DD or YYYY-MM-DD HH:MM:SS)")
+	blocksCmd.Flags().StringVar(&blocksTo, "to", "", "list blocks starting before this date (YYYY-MM-DD or YYYY-MM-DD HH:MM:SS)")
+	blocksCmd.Flags().BoolVar(&blocksActive, "active", false, "only list the block in progress")
+	blocksCmd.Flags().BoolVar(&blocksRecent, "recent", false, fmt.Sprintf("only list blocks of the last %d days", blocksRecentDays))
+	blocksCmd.Flags().BoolVar(&blocksHideGaps, "hide-gaps", false, "omit the idle gaps between blocks")
+	blocksCmd.Flags().StringVar(&blocksTokenLimit, "token-limit", "", "token limit per block, or max for the largest completed block (default: the plan's)")
+	rootCmd.AddCommand(blocksCmd)
+}
+
+// printBlocks prints one row per block or gap, the totals and the block in progress
+func printBlocks(report sessions.BlockReport, loc *time.Location, now time.Time) {
+	table := newTableFormatter([]string{"Start", "End", "Status", "Total Tokens", "% Tokens", "Cost (USD)", "% Cost", "Models"})
+	count := 0
+	for _, block := range report.Blocks {
+		if block.Gap {
+			table.addRow([]string{
+				block.Start.In(loc).Format("2006-01-02 15:04"),
+				block.End.In(loc).Format("2006-01-02 15:04"),
+				"gap (" + humanize.Duration(block.Duration) + ")", "", "", "", "", "",
+			})
+			continue
+		}
+		status := "done"
+		if block.Active {
+			status = "active"
+		}
+		if block.LimitHit {
+			status += " (limit hit)"
+		}
+		count++
+		table.addRow([]string{
+			block.Start.In(loc).Format("2006-01-02 15:04"),
+			block.End.In(loc).Format("2006-01-02 15:04"),
+			status,
+			humanize.Count(block.TotalTokens),
+			formatBlockPercent(block.TokenPercent, report.TokenLimit > 0),
+			humanize.Cost(block.CostUSD),
+			formatBlockPercent(block.CostPercent, report.CostLimit > 0),
+			formatModels(block.Models),
+		})
+	}
+	fmt.Println(table.render())
+	fmt.Printf("%d block(s), %s tokens, %s, %d hit a limit\n", count, humanize.Count(report.TotalTokens),
+		humanize.Cost(report.CostUSD), report.LimitHits)
+	if report.TokenLimit > 0 || report.CostLimit > 0 {
+		fmt.Printf("Limits per block: %s tokens, %s\n", humanize.Count(report.TokenLimit), humanize.Cost(report.CostLimit))
+	}
+
+	if active := report.Active; active != nil {
+		fmt.Printf("\nActive block: %s elapsed, %s remaining (ends %s)\n", humanize.Duration(active.Elapsed),
+			humanize.Duration(active.Remaining), now.Add(active.Remaining).In(loc).Format("15:04"))
+		fmt.Printf("Burn rate: %s tokens/min, %s/hour\n", humanize.Count(int(active.TokensPerMinute)), humanize.Cost(active.CostPerHour))
+		if active.ProjectedTokens > 0 {
+			fmt.Printf("Projected at block end: %s tokens, %s\n", humanize.Count(active.ProjectedTokens), humanize.Cost(active.ProjectedCostUSD))
+		}
+	}
+}
+
+// formatBlockPercent formats a share of a block limit, or a dash when there is no limit
+func formatBlockPercent(percent float64, hasLimit bool) string {
+	if !hasLimit {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f%%", percent)
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/penwyp/claudecat/calculations"
+	"github.com/penwyp/claudecat/humanize"
+	"github.com/penwyp/claudecat/internal"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes of claudecat budget; 1 is left to errors
+const (
+	budgetExitWarning  = 2
+	budgetExitExceeded = 3
+)
+
+var (
+	budgetOutput        string
+	budgetDaily         float64
+	budgetWeekly        float64
+	budgetMonthly       float64
+	budgetWarnThreshold float64
+)
+
+var budgetCmd = &cobra.Command{
+	Use:   "budget [path...]",
+	Short: "Check spend against daily, weekly and monthly budgets",
+	Long: `Compare the spend of the current day, week (Monday to Sunday) and calendar month against the
+budgets in the budgets section of the configuration file, or those given as flags, and print a
+summary. Periods without a budget are skipped.
+
+The exit status makes the command usable from CI jobs and cron without parsing its output:
+  0  every budget is within its limit
+  1  the check failed
+  2  a budget reached the warn threshold (budgets.warn_threshold, off by default)
+  3  a budget was exceeded
+
+Examples:
+  claudecat budget --daily 20 --monthly 400
+  claudecat budget --weekly 100 --warn-threshold 0.8 -o table
+  claudecat budget || notify-send "Claude budget exceeded"`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output := strings.ToLower(budgetOutput)
+		if output != "json" && output != "table" {
+			return fmt.Errorf("invalid output format: %s (valid: json, table)", budgetOutput)
+		}
+
+		cfg, err := loadCacheCommandConfig(cmd, args)
+		if err != nil {
+			return err
+		}
+		budgets := cfg.Budgets
+		if cmd.Flags().Changed("daily") {
+			budgets.Daily = budgetDaily
+		}
+		if cmd.Flags().Changed("weekly") {
+			budgets.Weekly = budgetWeekly
+		}
+		if cmd.Flags().Changed("monthly") {
+			budgets.Monthly = budgetMonthly
+		}
+		if cmd.Flags().Changed("warn-threshold") {
+			budgets.WarnThreshold = budgetWarnThreshold
+		}
+		if budgets.Daily < 0 || budgets.Weekly < 0 || budgets.Monthly < 0 {
+			return fmt.Errorf("budgets must be non-negative")
+		}
+		if budgets.WarnThreshold < 0 || budgets.WarnThreshold > 1 {
+			return fmt.Errorf("invalid warn threshold: %g (must be between 0 and 1)", budgets.WarnThreshold)
+		}
+		if budgets.Daily == 0 && budgets.Weekly == 0 && budgets.Monthly == 0 {
+			return fmt.Errorf("no budget set: configure budgets.daily, budgets.weekly or budgets.monthly, or pass --daily, --weekly or --monthly")
+		}
+
+		analyzer, err := internal.NewAnalyzer(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create analyzer: %w", err)
+		}
+		status, err := analyzer.Budgets(cfg.Data.Paths, budgets, time.Now())
+		if err != nil {
+			return fmt.Errorf("budget check failed: %w", err)
+		}
+		recordCommandResult("budgets", len(status.Checks))
+
+		if output == "json" {
+			data, err := sonic.MarshalIndent(status, "", "  ")
+			if err != nil {
+				return err
+			}
+			if _, err := os.Stdout.Write(append(data, '\n')); err != nil {
+				return err
+			}
+		} else {
+			printBudgetStatus(status)
+		}
+
+		switch status.Status {
+		case calculations.BudgetExceeded:
+			return &ExitCodeError{Code: budgetExitExceeded, Reason: "budget exceeded"}
+		case calculations.BudgetWarning:
+			return &ExitCodeError{Code: budgetExitWarning, Reason: "budget warning threshold reached"}
+		}
+		return nil
+	},
+}
+
+func init() {
+	budgetCmd.Flags().StringVarP(&budgetOutput, "output", "o", "json", "output format (json, table)")
+	budgetCmd.Flags().Float64Var(&budgetDaily, "daily", 0, "daily budget in USD (overrides budgets.daily, 0 disables)")
+	budgetCmd.Flags().Float64Var(&budgetWeekly, "weekly", 0, "weekly budget in USD (overrides budgets.weekly, 0 disables)")
+	budgetCmd.Flags().Float64Var(&budgetMonthly, "monthly", 0, "monthly budget in USD (overrides budgets.monthly, 0 disables)")
+	budgetCmd.Flags().Float64Var(&budgetWarnThreshold, "warn-threshold", 0, "fraction of a budget that exits with a warning (overrides budgets.warn_threshold)")
+	rootCmd.AddCommand(budgetCmd)
+}
+
+// printBudgetStatus prints one row per checked budget
+func printBudgetStatus(status calculations.BudgetStatus) {
+	table := newTableFormatter([]string{"Period", "Since", "Budget", "Spent", "Remaining", "Used", "Status"})
+	for _, check := range status.Checks {
+		remaining := humanize.Cost(check.Remaining)
+		if check.Remaining < 0 {
+			remaining = "-" + humanize.Cost(-check.Remaining)
+		}
+		table.addRow([]string{
+			check.Period,
+			check.Start.Format("2006-01-02"),
+			humanize.Cost(check.Budget),
+			humanize.Cost(check.Spent),
+			remaining,
+			fmt.Sprintf("%.1f%%", check.Used*100),
+			strings.ToUpper(check.Status),
+		})
+	}
+	fmt.Println(table.render())
+}
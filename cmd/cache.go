@@ -0,0 +1,724 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/penwyp/claudecat/cache"
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/fileio"
+	"github.com/penwyp/claudecat/humanize"
+	"github.com/penwyp/claudecat/logging"
+	"github.com/penwyp/claudecat/models"
+	"github.com/penwyp/claudecat/models/pricing"
+	"github.com/penwyp/claudecat/terminal"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cacheWarmNoProgress bool
+	cacheWarmDryRun     bool
+	cacheWarmOutput     string
+	cacheCompactMonths  int
+	cacheStatsOutput    string
+	cacheClearOlderThan int
+	cacheVerifyRepair   bool
+	cacheVerifyOutput   string
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the file summary cache",
+	Long: `Inspect and maintain the file summary cache used to speed up loading of usage data.
+
+Examples:
+  claudecat cache warm                     # Pre-build summaries for ~/.claude/projects
+  claudecat cache warm ~/claude-logs       # Pre-build summaries for a custom path
+  claudecat cache build --dry-run          # Show what warming would parse, how long and how big
+  claudecat cache restore ~/claude-logs    # Restore summaries removed by --reset
+  claudecat cache compact --months 3       # Roll files older than 3 months into monthly archives
+  claudecat cache stats                    # Size, entry counts and hit rate per data path
+  claudecat cache gc                       # Prune summaries of deleted usage files
+  claudecat cache clear --older-than 90    # Drop summaries of files not modified for 90 days
+  claudecat cache verify --repair          # Check checksums and drop broken summaries
+  claudecat cache snapshot before.json     # Save the cache state to compare with claudecat diff`,
+}
+
+var cacheWarmCmd = &cobra.Command{
+	Use:     "warm [path...]",
+	Aliases: []string{"build"},
+	Short:   "Pre-build cache summaries for all usage files",
+	Long: `Process every usage file once and store its summary in the cache, so that the
+monitor and analyze commands start instantly afterwards. Useful for preparing a
+machine before a demo.
+
+With --dry-run nothing is written to the cache: the files without a fresh summary are listed
+along with the time parsing them is estimated to take and the size of the cache afterwards.
+The time is measured by parsing a sample of up to 8 MB of those files, so it reflects the disk
+they are on.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !strings.EqualFold(cacheWarmOutput, "table") && !strings.EqualFold(cacheWarmOutput, "json") {
+			return fmt.Errorf("invalid output format: %s (valid: table, json)", cacheWarmOutput)
+		}
+		if cmd.Flags().Changed("output") && !cacheWarmDryRun {
+			return fmt.Errorf("--output requires --dry-run")
+		}
+		cfg, err := loadCacheCommandConfig(cmd, args)
+		if err != nil {
+			return err
+		}
+		if cacheWarmDryRun {
+			return runCacheWarmDryRun(cfg)
+		}
+
+		fileCache, err := cache.OpenFileBasedSummaryCache(config.ExpandHome(cfg.Cache.Dir), cfg.Cache.Encryption)
+		if err != nil {
+			return fmt.Errorf("failed to open cache: %w", err)
+		}
+		defer fileCache.Close()
+
+		pricingProvider, err := pricing.CreatePricingProvider(&cfg.Data, config.ExpandHome(cfg.Cache.Dir))
+		if err != nil {
+			logging.LogWarnf("Failed to create pricing provider: %v", err)
+			pricingProvider = pricing.NewDefaultProvider()
+		}
+
+		startTime := time.Now()
+		var totalFiles, hits, misses, entries int
+
+		for _, dataPath := range cfg.Data.Paths {
+			opts := fileio.LoadUsageEntriesOptions{
+				DataPath:        dataPath,
+				Mode:            models.CostModeCalculated,
+				CacheStore:      fileCache,
+				PricingProvider: pricingProvider,
+				MaxLineSize:     cfg.Data.MaxLineSize,
+			}
+			if !cacheWarmNoProgress {
+				opts.Progress = newProgressPrinter(dataPath)
+			}
+
+			result, err := fileio.LoadUsageEntries(opts)
+			if !cacheWarmNoProgress {
+				fmt.Fprintln(os.Stderr)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to warm cache for %s: %w", dataPath, err)
+			}
+
+			totalFiles += result.Metadata.FilesProcessed
+			entries += result.Metadata.EntriesLoaded
+			if stats := result.Metadata.CacheStats; stats != nil {
+				hits += stats.Hits
+				misses += stats.Misses
+			}
+		}
+
+		recordCommandResult("files", totalFiles)
+		recordCommandResult("entries", entries)
+		fmt.Printf("Cache warmed: %d files (%d already cached, %d newly summarized), %d entries in %v\n",
+			totalFiles, hits, misses, entries, time.Since(startTime).Round(time.Millisecond))
+		return nil
+	},
+}
+
+var cacheRestoreCmd = &cobra.Command{
+	Use:   "restore <path>",
+	Short: "Restore invalidated cache summaries from the trash",
+	Long: `Invalidated and cleared summaries are kept in a trash directory for the configured
+cache.trash_ttl (7 days by default). Restore moves them back into the cache for a
+usage file, or for every file beneath a directory.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadCacheCommandConfig(cmd, nil)
+		if err != nil {
+			return err
+		}
+
+		target, err := filepath.Abs(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to resolve path %s: %w", args[0], err)
+		}
+
+		fileCache, err := cache.OpenFileBasedSummaryCache(config.ExpandHome(cfg.Cache.Dir), cfg.Cache.Encryption)
+		if err != nil {
+			return fmt.Errorf("failed to open cache: %w", err)
+		}
+		defer fileCache.Close()
+		fileCache.SetTrashTTL(cfg.Cache.TrashTTL)
+
+		restored, err := fileCache.RestoreFileSummaries(target)
+		if err != nil {
+			return fmt.Errorf("failed to restore cache: %w", err)
+		}
+		recordCommandResult("restored", restored)
+
+		if restored == 0 {
+			fmt.Printf("No trashed summaries found for %s\n", target)
+			return nil
+		}
+		fmt.Printf("Restored %d summaries for %s\n", restored, target)
+		return nil
+	},
+}
+
+var cacheCompactCmd = &cobra.Command{
+	Use:   "compact [path...]",
+	Short: "Roll old usage files into monthly archives",
+	Long: `Aggregate the usage of files not modified for the given number of full calendar months into
+one archive per month and project, and drop their per-file summaries. Archives keep hourly
+usage per model and project, so analyze and its day, week and month groupings report them
+as before while old files no longer need to be summarized or loaded one by one.
+
+Set cache.archive_after_months to compact automatically whenever analyze runs.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadCacheCommandConfig(cmd, args)
+		if err != nil {
+			return err
+		}
+		months := cfg.Cache.ArchiveAfterMonths
+		if cmd.Flags().Changed("months") {
+			months = cacheCompactMonths
+		}
+		if months <= 0 {
+			return fmt.Errorf("no archive age set: pass --months or configure cache.archive_after_months")
+		}
+
+		fileCache, err := cache.OpenFileBasedSummaryCache(config.ExpandHome(cfg.Cache.Dir), cfg.Cache.Encryption)
+		if err != nil {
+			return fmt.Errorf("failed to open cache: %w", err)
+		}
+		defer fileCache.Close()
+		fileCache.SetTrashTTL(cfg.Cache.TrashTTL)
+
+		pricingProvider, err := pricing.CreatePricingProvider(&cfg.Data, config.ExpandHome(cfg.Cache.Dir))
+		if err != nil {
+			logging.LogWarnf("Failed to create pricing provider: %v", err)
+			pricingProvider = pricing.NewDefaultProvider()
+		}
+
+		before := cache.ArchiveCutoff(months, time.Now())
+		totalFiles := 0
+		for _, dataPath := range cfg.Data.Paths {
+			result, err := fileio.CompactArchives(fileio.CompactOptions{
+				DataPath:        dataPath,
+				Before:          before,
+				Archives:        fileCache.Archives(),
+				CacheStore:      fileCache,
+				Mode:            models.CostModeCalculated,
+				PricingProvider: pricingProvider,
+				MaxLineSize:     cfg.Data.MaxLineSize,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to compact %s: %w", dataPath, err)
+			}
+			totalFiles += result.Files
+
+			if result.Files == 0 {
+				fmt.Printf("%s: no files last modified before %s to archive\n", dataPath, before.Format("2006-01-02"))
+				continue
+			}
+			fmt.Printf("%s: archived %d files (%s entries) into %s\n", dataPath, result.Files,
+				humanize.Count(result.Entries), strings.Join(result.Months, ", "))
+		}
+		recordCommandResult("files", totalFiles)
+		return nil
+	},
+}
+
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats [path...]",
+	Short: "Show cache size, entry counts and hit rate",
+	Long: `Report the number of cached summaries, the entries, tokens and cost they hold, the disk
+and trash usage, and for each data path the share of usage files the next load serves from
+the cache (the hit rate) along with files whose summary is stale or missing.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !strings.EqualFold(cacheStatsOutput, "table") && !strings.EqualFold(cacheStatsOutput, "json") {
+			return fmt.Errorf("invalid output format: %s (valid: table, json)", cacheStatsOutput)
+		}
+		cfg, err := loadCacheCommandConfig(cmd, args)
+		if err != nil {
+			return err
+		}
+		fileCache, err := openSummaryCache(cfg)
+		if err != nil {
+			return err
+		}
+		defer fileCache.Close()
+
+		stats := fileCache.GetStats()
+		trashFiles, trashBytes := fileCache.TrashUsage()
+		report := cacheStatsReport{
+			Dir:           config.ExpandHome(cfg.Cache.Dir),
+			Summaries:     stats["cached_files"].(int),
+			Entries:       stats["total_entries"].(int64),
+			Tokens:        stats["total_tokens"].(int64),
+			Cost:          stats["total_cost"].(float64),
+			DiskBytes:     fileCache.DiskUsage(),
+			MaxDiskSize:   cfg.Cache.MaxDiskSize,
+			TrashFiles:    trashFiles,
+			TrashBytes:    trashBytes,
+			SchemaVersion: cache.CurrentSchemaVersion,
+			Paths:         []cachePathCoverage{},
+		}
+		for _, dataPath := range cfg.Data.Paths {
+			files, err := fileio.DiscoverFiles(dataPath)
+			if err != nil {
+				logging.LogWarnf("Failed to discover files in %s: %v", dataPath, err)
+				continue
+			}
+			coverage := fileCache.Coverage(files)
+			report.Paths = append(report.Paths, cachePathCoverage{Path: dataPath, Coverage: coverage, HitRate: coverage.HitRate()})
+		}
+		recordCommandResult("summaries", report.Summaries)
+
+		if strings.EqualFold(cacheStatsOutput, "json") {
+			data, err := sonic.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return err
+			}
+			_, err = os.Stdout.Write(append(data, '\n'))
+			return err
+		}
+		printCacheStats(report, cfg.Cache.TrashTTL)
+		return nil
+	},
+}
+
+var cacheGCCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Prune summaries of deleted usage files",
+	Long: `Move summaries of usage files that no longer exist to the trash, remove leftovers of
+interrupted cache writes, forget the quota evictions of deleted files and purge trashed summaries
+older than cache.trash_ttl. Summaries of files on an unmounted drive are pruned too; restore them
+with 'claudecat cache restore <path>'.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadCacheCommandConfig(cmd, nil)
+		if err != nil {
+			return err
+		}
+		fileCache, err := openSummaryCache(cfg)
+		if err != nil {
+			return err
+		}
+		defer fileCache.Close()
+
+		result, err := fileCache.GC()
+		if err != nil {
+			return fmt.Errorf("failed to collect cache garbage: %w", err)
+		}
+		recordCommandResult("pruned", result.Pruned)
+		fmt.Printf("Pruned %d summaries of deleted files, removed %d temporary files, purged %d expired summaries from the trash\n",
+			result.Pruned, result.TempFiles, result.Purged)
+		return nil
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear [path...]",
+	Short: "Clear cached summaries by data path or age",
+	Long: `Move cached summaries to the trash so their usage files are parsed again. Without arguments
+every summary is cleared, like analyze --reset; paths limit clearing to usage files beneath them
+and --older-than to files not modified for the given number of days. Cleared summaries can be
+brought back with 'claudecat cache restore <path>' for cache.trash_ttl.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if cacheClearOlderThan < 0 {
+			return fmt.Errorf("invalid --older-than: %d days", cacheClearOlderThan)
+		}
+		cfg, err := loadCacheCommandConfig(cmd, nil)
+		if err != nil {
+			return err
+		}
+		fileCache, err := openSummaryCache(cfg)
+		if err != nil {
+			return err
+		}
+		defer fileCache.Close()
+
+		var cutoff time.Time
+		if cacheClearOlderThan > 0 {
+			cutoff = time.Now().AddDate(0, 0, -cacheClearOlderThan)
+		}
+		if len(args) == 0 && cutoff.IsZero() {
+			if err := fileCache.Clear(); err != nil {
+				return fmt.Errorf("failed to clear cache: %w", err)
+			}
+			fmt.Println("Cache cleared (restore with 'claudecat cache restore <path>')")
+			return nil
+		}
+
+		targets := []string{""}
+		if len(args) > 0 {
+			targets = targets[:0]
+			for _, arg := range args {
+				target, err := filepath.Abs(arg)
+				if err != nil {
+					return fmt.Errorf("failed to resolve path %s: %w", arg, err)
+				}
+				targets = append(targets, target)
+			}
+		}
+		total := 0
+		for _, target := range targets {
+			cleared, err := fileCache.ClearMatching(target, cutoff)
+			if err != nil {
+				return fmt.Errorf("failed to clear cache: %w", err)
+			}
+			total += cleared
+		}
+		recordCommandResult("cleared", total)
+
+		scope := "all data paths"
+		if len(args) > 0 {
+			scope = strings.Join(targets, ", ")
+		}
+		if !cutoff.IsZero() {
+			scope += fmt.Sprintf(", files not modified since %s", cutoff.Format("2006-01-02"))
+		}
+		fmt.Printf("Cleared %d summaries (%s)\n", total, scope)
+		return nil
+	},
+}
+
+var cacheSnapshotCmd = &cobra.Command{
+	Use:   "snapshot [file]",
+	Short: "Save the aggregate state of the cache for claudecat diff",
+	Long: `Save the usage files the cache summarizes and their usage per UTC day and model as JSON, to
+compare with claudecat diff after a backfill or a cache rebuild. The snapshot is written to file,
+or to stdout when none is given. It holds the paths of the usage files, so it is only readable by
+you.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadCacheCommandConfig(cmd, nil)
+		if err != nil {
+			return err
+		}
+		snapshot, err := loadSnapshot(cfg, cfg.Cache.Dir, time.Now())
+		if err != nil {
+			return err
+		}
+		data, err := sonic.MarshalIndent(snapshot, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode snapshot: %w", err)
+		}
+		data = append(data, '\n')
+		recordCommandResult("files", len(snapshot.Files))
+		if len(args) == 0 {
+			_, err = os.Stdout.Write(data)
+			return err
+		}
+
+		path := config.ExpandHome(args[0])
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			return fmt.Errorf("failed to write snapshot: %w", err)
+		}
+		entries, cost := snapshot.Totals()
+		fmt.Printf("Saved a snapshot of %d files (%s entries, %s) to %s\n", len(snapshot.Files),
+			humanize.Count(entries), humanize.Cost(cost), path)
+		return nil
+	},
+}
+
+var cacheVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify cached summaries",
+	Long: `Decode every cached summary and check that its checksum matches the usage file it was built
+from and that it is stored under the right file name. Summaries that cannot be decrypted or were
+written by a newer claudecat are reported as unreadable. --repair moves failed summaries to the
+trash so their files are parsed again on the next load. Exits with an error when problems remain.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !strings.EqualFold(cacheVerifyOutput, "table") && !strings.EqualFold(cacheVerifyOutput, "json") {
+			return fmt.Errorf("invalid output format: %s (valid: table, json)", cacheVerifyOutput)
+		}
+		cfg, err := loadCacheCommandConfig(cmd, nil)
+		if err != nil {
+			return err
+		}
+		fileCache, err := openSummaryCache(cfg)
+		if err != nil {
+			return err
+		}
+		defer fileCache.Close()
+
+		report, err := fileCache.Verify(cacheVerifyRepair)
+		if err != nil {
+			return fmt.Errorf("failed to verify cache: %w", err)
+		}
+		recordCommandResult("issues", len(report.Issues))
+
+		if strings.EqualFold(cacheVerifyOutput, "json") {
+			data, err := sonic.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return err
+			}
+			if _, err := os.Stdout.Write(append(data, '\n')); err != nil {
+				return err
+			}
+		} else {
+			printCacheVerify(report)
+		}
+		if remaining := len(report.Issues) - report.Repaired; remaining > 0 {
+			return fmt.Errorf("%d cached summaries failed verification (run with --repair to drop them)", remaining)
+		}
+		return nil
+	},
+}
+
+func init() {
+	cacheWarmCmd.Flags().BoolVar(&cacheWarmNoProgress, "no-progress", false, "disable the progress bar")
+	cacheWarmCmd.Flags().BoolVar(&cacheWarmDryRun, "dry-run", false, "report what would be parsed, the estimated time and cache size without writing anything")
+	cacheWarmCmd.Flags().StringVarP(&cacheWarmOutput, "output", "o", "table", "dry-run output format (table, json)")
+	cacheCompactCmd.Flags().IntVar(&cacheCompactMonths, "months", 0, "archive files not modified for this many full months (overrides cache.archive_after_months)")
+
+	cacheCmd.AddCommand(cacheWarmCmd)
+	cacheCmd.AddCommand(cacheRestoreCmd)
+	cacheStatsCmd.Flags().StringVarP(&cacheStatsOutput, "output", "o", "table", "output format (table, json)")
+	cacheClearCmd.Flags().IntVar(&cacheClearOlderThan, "older-than", 0, "only clear summaries of files not modified for this many days")
+	cacheVerifyCmd.Flags().BoolVar(&cacheVerifyRepair, "repair", false, "move summaries failing verification to the trash")
+	cacheVerifyCmd.Flags().StringVarP(&cacheVerifyOutput, "output", "o", "table", "output format (table, json)")
+
+	cacheCmd.AddCommand(cacheCompactCmd)
+	cacheCmd.AddCommand(cacheStatsCmd)
+	cacheCmd.AddCommand(cacheGCCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+	cacheCmd.AddCommand(cacheVerifyCmd)
+	cacheCmd.AddCommand(cacheSnapshotCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
+
+// loadCacheCommandConfig loads configuration and resolves data paths for cache subcommands
+func loadCacheCommandConfig(cmd *cobra.Command, args []string) (*config.Config, error) {
+	cfg, err := loadConfiguration(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	if len(args) > 0 {
+		for _, p := range args {
+			if _, err := os.Stat(p); os.IsNotExist(err) {
+				return nil, fmt.Errorf("path does not exist: %s", p)
+			}
+		}
+		cfg.Data.Paths = args
+	}
+	discoverDataPaths(cfg)
+
+	if debug {
+		cfg.Debug.Enabled = true
+		cfg.App.LogLevel = "debug"
+	}
+	logging.InitLogger(cfg.App.LogLevel, cfg.App.LogFile, cfg.Debug.Enabled)
+
+	return cfg, nil
+}
+
+// openSummaryCache opens the file summary cache configured in cfg with its trash TTL applied
+func openSummaryCache(cfg *config.Config) (*cache.FileBasedSummaryCache, error) {
+	fileCache, err := cache.OpenFileBasedSummaryCache(config.ExpandHome(cfg.Cache.Dir), cfg.Cache.Encryption)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cache: %w", err)
+	}
+	fileCache.SetTrashTTL(cfg.Cache.TrashTTL)
+	return fileCache, nil
+}
+
+// cacheDryRunSampleBytes is how much of the files to parse a dry run parses to measure the parse rate
+const cacheDryRunSampleBytes = 8 << 20
+
+// cacheWarmPlanReport is the output of cache warm --dry-run
+type cacheWarmPlanReport struct {
+	Dir string `json:"dir"`
+	cache.WarmPlan
+	DiskBytes     int64         `json:"disk_bytes"`     // Size of the cache now
+	ParseRate     float64       `json:"parse_rate"`     // Bytes parsed per second in the sample, 0 when nothing was sampled
+	EstimatedTime time.Duration `json:"estimated_time"` // Time to parse the files at the parse rate
+}
+
+// runCacheWarmDryRun reports what warming the cache for the configured data paths would do
+func runCacheWarmDryRun(cfg *config.Config) error {
+	dir := config.ExpandHome(cfg.Cache.Dir)
+	fileCache, err := cache.OpenFileBasedSummaryCacheReadOnly(dir, cfg.Cache.Encryption)
+	if err != nil {
+		return fmt.Errorf("failed to open cache: %w", err)
+	}
+	defer fileCache.Close()
+
+	var files []string
+	for _, dataPath := range cfg.Data.Paths {
+		found, err := fileio.DiscoverFiles(dataPath)
+		if err != nil {
+			return fmt.Errorf("failed to discover files in %s: %w", dataPath, err)
+		}
+		files = append(files, found...)
+	}
+	report := cacheWarmPlanReport{Dir: dir, WarmPlan: fileCache.PlanWarm(files), DiskBytes: fileCache.DiskUsage()}
+
+	if len(report.Files) > 0 {
+		pricingProvider, err := pricing.CreatePricingProvider(&cfg.Data, dir)
+		if err != nil {
+			logging.LogWarnf("Failed to create pricing provider: %v", err)
+			pricingProvider = pricing.NewDefaultProvider()
+		}
+		sample := make([]string, len(report.Files))
+		for i, file := range report.Files {
+			sample[i] = file.Path
+		}
+		report.ParseRate = fileio.MeasureParseRate(sample, cacheDryRunSampleBytes, fileio.LoadUsageEntriesOptions{
+			Mode:            models.CostModeCalculated,
+			PricingProvider: pricingProvider,
+			MaxLineSize:     cfg.Data.MaxLineSize,
+		})
+		if report.ParseRate > 0 {
+			report.EstimatedTime = time.Duration(float64(report.ParseBytes) / report.ParseRate * float64(time.Second))
+		}
+	}
+	recordCommandResult("files", len(report.Files))
+
+	if strings.EqualFold(cacheWarmOutput, "json") {
+		data, err := sonic.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(append(data, '\n'))
+		return err
+	}
+	printCacheWarmPlan(report)
+	return nil
+}
+
+// printCacheWarmPlan prints the files a warm would parse followed by the estimates
+func printCacheWarmPlan(report cacheWarmPlanReport) {
+	if len(report.Files) > 0 {
+		table := newTableFormatter([]string{"Usage File", "Bytes", "Summary"})
+		for _, file := range report.Files {
+			table.addRow([]string{file.Path, humanize.Count(int(file.Size)), file.Reason})
+		}
+		fmt.Println(table.render())
+		fmt.Println()
+	}
+
+	fmt.Printf("Would parse: %d files, %s (%d already cached)\n", len(report.Files), humanize.Bytes(report.ParseBytes), report.Cached)
+	switch {
+	case len(report.Files) == 0:
+		fmt.Println("Estimate:    nothing to do")
+	case report.ParseRate > 0:
+		fmt.Printf("Estimate:    %v at %s/s\n", report.EstimatedTime.Round(time.Millisecond), humanize.Bytes(int64(report.ParseRate)))
+	default:
+		fmt.Println("Estimate:    unknown, no file could be sampled")
+	}
+	fmt.Printf("Cache size:  %s now, about %s afterwards (%s)\n", humanize.Bytes(report.DiskBytes),
+		humanize.Bytes(report.CacheBytes), report.Dir)
+	fmt.Println("Dry run, nothing was written to the cache")
+}
+
+// cacheStatsReport is the output of cache stats
+type cacheStatsReport struct {
+	Dir           string              `json:"dir"`
+	Summaries     int                 `json:"summaries"`
+	Entries       int64               `json:"entries"`
+	Tokens        int64               `json:"tokens"`
+	Cost          float64             `json:"cost"`
+	DiskBytes     int64               `json:"disk_bytes"`
+	MaxDiskSize   int64               `json:"max_disk_size"`
+	TrashFiles    int                 `json:"trash_files"`
+	TrashBytes    int64               `json:"trash_bytes"`
+	SchemaVersion int                 `json:"schema_version"`
+	Paths         []cachePathCoverage `json:"paths"`
+}
+
+// cachePathCoverage is the cache coverage of the usage files of one data path
+type cachePathCoverage struct {
+	Path string `json:"path"`
+	cache.Coverage
+	HitRate float64 `json:"hit_rate"`
+}
+
+// printCacheStats prints the cache totals followed by the coverage of each data path
+func printCacheStats(report cacheStatsReport, trashTTL time.Duration) {
+	quota := "no quota"
+	if report.MaxDiskSize > 0 {
+		quota = fmt.Sprintf("quota %s", humanize.Bytes(report.MaxDiskSize))
+	}
+	if trashTTL <= 0 {
+		trashTTL = cache.DefaultTrashTTL
+	}
+
+	fmt.Printf("Cache:      %s (schema v%d)\n", report.Dir, report.SchemaVersion)
+	fmt.Printf("Summaries:  %s files, %s entries, %s tokens, %s\n", humanize.Count(report.Summaries),
+		humanize.Count(int(report.Entries)), humanize.Count(int(report.Tokens)), humanize.Cost(report.Cost))
+	fmt.Printf("Disk:       %s (%s)\n", humanize.Bytes(report.DiskBytes), quota)
+	kept := trashTTL.String()
+	if trashTTL%(24*time.Hour) == 0 {
+		kept = fmt.Sprintf("%d days", int(trashTTL/(24*time.Hour)))
+	}
+	fmt.Printf("Trash:      %d summaries, %s (kept for %s)\n", report.TrashFiles, humanize.Bytes(report.TrashBytes), kept)
+	if len(report.Paths) == 0 {
+		return
+	}
+
+	fmt.Println()
+	table := newTableFormatter([]string{"Data Path", "Files", "Cached", "Stale", "Missing", "Hit Rate"})
+	for _, path := range report.Paths {
+		table.addRow([]string{
+			path.Path,
+			humanize.Count(path.Files),
+			humanize.Count(path.Fresh),
+			humanize.Count(path.Stale),
+			humanize.Count(path.Missing),
+			fmt.Sprintf("%.1f%%", path.HitRate*100),
+		})
+	}
+	fmt.Println(table.render())
+}
+
+// printCacheVerify prints the verification summary and each failed summary
+func printCacheVerify(report cache.VerifyReport) {
+	fmt.Printf("Verified %d summaries (%d redacted): %d problems", report.Checked, report.Redacted, len(report.Issues))
+	if report.Repaired > 0 {
+		fmt.Printf(", %d moved to the trash", report.Repaired)
+	}
+	fmt.Println()
+	if len(report.Issues) == 0 {
+		return
+	}
+
+	fmt.Println()
+	table := newTableFormatter([]string{"Summary", "Usage File", "Problem", "Detail"})
+	for _, issue := range report.Issues {
+		path := issue.Path
+		if path == "" {
+			path = "-"
+		}
+		table.addRow([]string{filepath.Base(issue.File), path, issue.Problem, issue.Detail})
+	}
+	fmt.Println(table.render())
+}
+
+// newProgressPrinter returns a progress callback that renders a progress bar on stderr
+func newProgressPrinter(label string) fileio.ProgressFunc {
+	lastRender := time.Time{}
+
+	return func(p fileio.ProgressSnapshot) {
+		if !p.Done() && time.Since(lastRender) < 100*time.Millisecond {
+			return
+		}
+		lastRender = time.Now()
+
+		width := 30
+		filled := int(p.Percent() * float64(width) / 100)
+		if filled > width {
+			filled = width
+		}
+		bar := terminal.Text(strings.Repeat("█", filled) + strings.Repeat("░", width-filled))
+
+		fmt.Fprintf(os.Stderr, "\r%s [%s] %d/%d files %5.1f%%  %d remaining  ETA %-8s",
+			filepath.Base(label), bar, p.ProcessedFiles, p.TotalFiles, p.Percent(),
+			p.Remaining(), p.ETA.Round(time.Second))
+	}
+}
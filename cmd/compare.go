@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/penwyp/claudecat/calculations"
+	"github.com/penwyp/claudecat/humanize"
+	"github.com/penwyp/claudecat/internal"
+	"github.com/spf13/cobra"
+)
+
+var (
+	compareOutput   string
+	comparePeriod   string
+	compareDate     string
+	compareFrom     string
+	compareTo       string
+	compareBaseFrom string
+	compareBaseTo   string
+)
+
+var compareCmd = &cobra.Command{
+	Use:   "compare [path...]",
+	Short: "Compare cost, tokens and models between two periods",
+	Long: `Compare the usage of a period with an earlier one: cost, tokens, messages and sessions with
+their change, followed by the cost and share of each model in both periods.
+
+By default the current week is compared with the previous one. --period month compares calendar
+months, --date picks the period, and --from/--to compare an arbitrary range with the range of the
+same length before it, or with --base-from/--base-to.
+
+Examples:
+  claudecat compare                                   # This week vs last week
+  claudecat compare --period month --date 2025-06-15  # June vs May
+  claudecat compare --from 2025-06-01 --to 2025-06-14 --base-from 2025-05-01 --base-to 2025-05-14
+  claudecat compare -o json                           # Deltas as JSON`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !strings.EqualFold(compareOutput, "table") && !strings.EqualFold(compareOutput, "json") {
+			return fmt.Errorf("invalid output format: %s (valid: table, json)", compareOutput)
+		}
+		if _, _, err := calculations.ReportPeriodBounds(comparePeriod, time.Now(), time.Local); err != nil {
+			return err
+		}
+		if compareFrom == "" && (compareTo != "" || compareBaseFrom != "" || compareBaseTo != "") {
+			return fmt.Errorf("--to, --base-from and --base-to require --from")
+		}
+		if (compareBaseFrom == "") != (compareBaseTo == "") {
+			return fmt.Errorf("--base-from and --base-to must be given together")
+		}
+
+		cfg, err := loadCacheCommandConfig(cmd, args)
+		if err != nil {
+			return err
+		}
+		loc, err := time.LoadLocation(cfg.App.Timezone)
+		if err != nil {
+			loc = time.Local
+		}
+		base, current, err := compareRanges(time.Now(), loc)
+		if err != nil {
+			return err
+		}
+
+		analyzer, err := internal.NewAnalyzer(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create analyzer: %w", err)
+		}
+		results, err := analyzer.Analyze(cfg.Data.Paths)
+		if err != nil {
+			return fmt.Errorf("analysis failed: %w", err)
+		}
+		comparison := calculations.ComparePeriods(results, base, current)
+		recordCommandResult("compare", len(comparison.Models))
+
+		if strings.EqualFold(compareOutput, "json") {
+			data, err := sonic.MarshalIndent(comparison, "", "  ")
+			if err != nil {
+				return err
+			}
+			_, err = os.Stdout.Write(append(data, '\n'))
+			return err
+		}
+		printComparison(comparison, loc)
+		return nil
+	},
+}
+
+func init() {
+	compareCmd.Flags().StringVarP(&compareOutput, "output", "o", "table", "output format (table, json)")
+	compareCmd.Flags().StringVar(&comparePeriod, "period", calculations.ReportPeriodWeek, "compared period (week, month)")
+	compareCmd.Flags().StringVar(&compareDate, "date", "", "any day in the current period (YYYY-MM-DD, default today)")
+	compareCmd.Flags().StringVar(&compareFrom, "from", "", "start of a custom current range (YYYY-MM-DD or YYYY-MM-DD HH:MM:SS)")
+	compareCmd.Flags().StringVar(&compareTo, "to", "", "end of the custom current range (default now)")
+	compareCmd.Flags().StringVar(&compareBaseFrom, "base-from", "", "start of the base range (default: same length before --from)")
+	compareCmd.Flags().StringVar(&compareBaseTo, "base-to", "", "end of the base range")
+	rootCmd.AddCommand(compareCmd)
+}
+
+// compareRanges resolves the base and current ranges from the flags
+func compareRanges(now time.Time, loc *time.Location) (calculations.CompareRange, calculations.CompareRange, error) {
+	var base, current calculations.CompareRange
+	if compareFrom == "" {
+		date := now
+		if compareDate != "" {
+			parsed, err := parseTimeString(compareDate)
+			if err != nil {
+				return base, current, fmt.Errorf("invalid date %s: %w", compareDate, err)
+			}
+			// The date names a local calendar day; noon keeps it clear of DST transitions
+			date = time.Date(parsed.Year(), parsed.Month(), parsed.Day(), 12, 0, 0, 0, loc)
+		}
+		start, end, err := calculations.ReportPeriodBounds(comparePeriod, date, loc)
+		if err != nil {
+			return base, current, err
+		}
+		current = calculations.CompareRange{From: start, To: end.Add(-time.Nanosecond)}
+		// The previous calendar period, which for months may differ in length
+		baseStart, _, _ := calculations.ReportPeriodBounds(comparePeriod, start.Add(-time.Nanosecond), loc)
+		base = calculations.CompareRange{From: baseStart, To: start.Add(-time.Nanosecond)}
+		return base, current, nil
+	}
+
+	var err error
+	if current.From, err = parseSessionsBound(compareFrom, loc, false); err != nil {
+		return base, current, fmt.Errorf("invalid from date %s: %w", compareFrom, err)
+	}
+	current.To = now
+	if compareTo != "" {
+		if current.To, err = parseSessionsBound(compareTo, loc, true); err != nil {
+			return base, current, fmt.Errorf("invalid to date %s: %w", compareTo, err)
+		}
+	}
+	if current.To.Before(current.From) {
+		return base, current, fmt.Errorf("--to must not be before --from")
+	}
+
+	if compareBaseFrom == "" {
+		return calculations.PreviousRange(current), current, nil
+	}
+	if base.From, err = parseSessionsBound(compareBaseFrom, loc, false); err != nil {
+		return base, current, fmt.Errorf("invalid base from date %s: %w", compareBaseFrom, err)
+	}
+	if base.To, err = parseSessionsBound(compareBaseTo, loc, true); err != nil {
+		return base, current, fmt.Errorf("invalid base to date %s: %w", compareBaseTo, err)
+	}
+	if base.To.Before(base.From) {
+		return base, current, fmt.Errorf("--base-to must not be before --base-from")
+	}
+	return base, current, nil
+}
+
+// printComparison prints the metric deltas followed by the shift of each model
+func printComparison(comparison calculations.PeriodComparison, loc *time.Location) {
+	fmt.Printf("Base:    %s\n", formatCompareRange(comparison.Base.CompareRange, loc))
+	fmt.Printf("Current: %s\n\n", formatCompareRange(comparison.Current.CompareRange, loc))
+
+	table := newTableFormatter([]string{"Metric", "Base", "Current", "Change", "Change %"})
+	for _, delta := range comparison.Deltas {
+		format := func(v float64) string { return humanize.Count(int(v)) }
+		change := humanize.Signed(int(delta.Change))
+		if delta.Metric == "cost" {
+			format = humanize.Cost
+			change = humanize.CostDelta(delta.Change)
+		}
+		pct := "n/a"
+		if delta.ChangePct != nil {
+			pct = fmt.Sprintf("%+.1f%%", *delta.ChangePct)
+		}
+		table.addRow([]string{compareMetricLabel(delta.Metric), format(delta.Base), format(delta.Current), change, pct})
+	}
+	fmt.Println(table.render())
+
+	if len(comparison.Models) == 0 {
+		fmt.Println("No usage in either period")
+		return
+	}
+	fmt.Println()
+	table = newTableFormatter([]string{"Model", "Base Cost", "Current Cost", "Cost Change", "Token Change", "Base Share", "Current Share", "Share Shift"})
+	for _, shift := range comparison.Models {
+		table.addRow([]string{
+			shift.Model,
+			humanize.Cost(shift.BaseCost),
+			humanize.Cost(shift.CurrentCost),
+			humanize.CostDelta(shift.CostChange),
+			humanize.Signed(shift.TokenChange),
+			fmt.Sprintf("%.1f%%", shift.BaseShare*100),
+			fmt.Sprintf("%.1f%%", shift.CurrentShare*100),
+			fmt.Sprintf("%+.1f pp", shift.ShareChange*100),
+		})
+	}
+	fmt.Println(table.render())
+}
+
+// formatCompareRange prints a range by day when it spans whole days, otherwise to the minute
+func formatCompareRange(r calculations.CompareRange, loc *time.Location) string {
+	from, to := r.From.In(loc), r.To.In(loc)
+	if from.Equal(calculations.StartOfDay(from, loc)) && to.Add(time.Nanosecond).Equal(calculations.StartOfDay(to.Add(time.Nanosecond), loc)) {
+		return fmt.Sprintf("%s to %s", from.Format("2006-01-02"), to.Format("2006-01-02"))
+	}
+	return fmt.Sprintf("%s to %s", from.Format("2006-01-02 15:04"), to.Format("2006-01-02 15:04"))
+}
+
+// compareMetricLabel returns the table label of a metric
+func compareMetricLabel(metric string) string {
+	switch metric {
+	case "cost":
+		return "Cost (USD)"
+	case "total_tokens":
+		return "Total Tokens"
+	case "input_tokens":
+		return "Input Tokens"
+	case "output_tokens":
+		return "Output Tokens"
+	case "cache_creation_tokens":
+		return "Cache Creation"
+	case "cache_read_tokens":
+		return "Cache Read"
+	case "messages":
+		return "Messages"
+	case "sessions":
+		return "Sessions"
+	}
+	return metric
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/penwyp/claudecat/cache"
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/internal"
+	"github.com/spf13/cobra"
+)
+
+// completeModels completes model names from the summary cache of the data paths in args
+func completeModels(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return completeFromCache(cmd, args, toComplete, internal.ModelCompletions)
+}
+
+// completeProjects completes project names from the summary cache of the data paths in args
+func completeProjects(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return completeFromCache(cmd, args, toComplete, internal.ProjectCompletions)
+}
+
+// completeFromCache completes values listed from the summaries of the cache, opened read-only so
+// that pressing tab never parses usage files or writes anything
+func completeFromCache(cmd *cobra.Command, args []string, toComplete string, list func([]*cache.FileSummary, []string) []internal.Completion) ([]string, cobra.ShellCompDirective) {
+	cfg, err := loadCacheCommandConfig(cmd, args)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	fileCache, err := cache.OpenFileBasedSummaryCacheReadOnly(config.ExpandHome(cfg.Cache.Dir), cfg.Cache.Encryption)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	defer fileCache.Close()
+	return completionValues(list(fileCache.Summaries(), cfg.Data.Paths), toComplete, nil), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTagArgs completes the session ID of claudecat tag and then the labels already in use
+func completeTagArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	// Completion parses the flags a second time, which appends to --paths again
+	var paths []string
+	for _, path := range tagPaths {
+		if !slices.Contains(paths, path) {
+			paths = append(paths, path)
+		}
+	}
+	cfg, err := loadCacheCommandConfig(cmd, paths)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	if len(args) > 0 {
+		store, err := internal.OpenTagStore(cfg)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		tags, err := store.Load()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completionValues(tags.LabelCompletions(), toComplete, args[1:]), cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeSessionIDs(cfg, toComplete)
+}
+
+// completeSessionIDs completes the IDs of recent sessions, most recent first
+func completeSessionIDs(cfg *config.Config, toComplete string) ([]string, cobra.ShellCompDirective) {
+	analyzer, err := internal.NewAnalyzer(cfg)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	loc, err := time.LoadLocation(cfg.App.Timezone)
+	if err != nil {
+		loc = time.Local
+	}
+	completions, err := analyzer.SessionCompletions(cfg.Data.Paths, time.Now(), loc)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completionValues(completions, toComplete, nil), cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+// completeAlertSessions completes the session IDs recorded in the alert log
+func completeAlertSessions(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cfg, err := loadConfiguration(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	alertLog, err := internal.OpenAlertLog(cfg)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	records, err := alertLog.Read()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completionValues(internal.AlertSessionCompletions(records), toComplete, nil), cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+// completionValues formats the completions starting with toComplete as cobra expects them, value
+// and description separated by a tab, leaving out values already given
+func completionValues(completions []internal.Completion, toComplete string, given []string) []string {
+	values := make([]string, 0, len(completions))
+	for _, completion := range completions {
+		if !strings.HasPrefix(strings.ToLower(completion.Value), strings.ToLower(toComplete)) || slices.Contains(given, completion.Value) {
+			continue
+		}
+		values = append(values, completion.Value+"\t"+completion.Description)
+	}
+	return values
+}
@@ -0,0 +1,402 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/internal"
+	"github.com/spf13/cobra"
+)
+
+var (
+	configFile           string
+	configIncludeSecrets bool
+	configInitForce      bool
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Read and change configuration settings",
+	Long: `Read the effective configuration and change settings in the configuration file without
+editing it by hand. Keys are dotted YAML paths such as ui.theme or subscription.plan.
+
+set and unset edit the file given by --file, by default the last existing file of
+./claudecat.yaml, ~/.config/claudecat/config.yaml, ~/.claudecat/config.yaml and
+/etc/claudecat/config.yaml (later files take precedence), or ~/.config/claudecat/config.yaml when
+none exists. Comments and key order are preserved, and an edit that would make the configuration
+invalid is rejected.
+
+Examples:
+  claudecat config init
+  claudecat config show
+  claudecat config validate
+  claudecat config get ui.theme
+  claudecat config set subscription.plan max5
+  claudecat config set limits.notifications desktop,sound
+  claudecat config unset ui.timezone
+  claudecat config list
+  claudecat config export-alerts team-alerts.json
+  claudecat config import-alerts team-alerts.json`,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the effective value of a setting",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfigCommandConfig(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		value, err := config.GetValue(cfg, args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Println(config.FormatValue(value))
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Change a setting in the configuration file",
+	Long: `Change a setting in the configuration file. Durations are written like 30s or 2h, and lists
+as comma-separated values. Maps are set one entry at a time, such as ui.column_widths.project.
+Lists of objects such as api.cost_centers must be edited in the file. The file is only readable by
+you, as it may hold tokens; token and password values are masked in the command history.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		editor, err := config.OpenFileEditor(configEditPath())
+		if err != nil {
+			return err
+		}
+		if err := editor.Set(args[0], args[1]); err != nil {
+			return err
+		}
+		if err := editor.Save(); err != nil {
+			return err
+		}
+		fmt.Printf("Set %s in %s\n", args[0], editor.Path())
+		return nil
+	},
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Remove a setting from the configuration file, restoring its default",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		editor, err := config.OpenFileEditor(configEditPath())
+		if err != nil {
+			return err
+		}
+		removed, err := editor.Unset(args[0])
+		if err != nil {
+			return err
+		}
+		if !removed {
+			fmt.Printf("%s is not set in %s\n", args[0], editor.Path())
+			return nil
+		}
+		if err := editor.Save(); err != nil {
+			return err
+		}
+		fmt.Printf("Unset %s in %s\n", args[0], editor.Path())
+		return nil
+	},
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Print every setting with its effective value",
+	Long:  `Print every setting with its effective value. Tokens and passwords are masked; use config get to read them.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfigCommandConfig(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		for _, info := range config.Keys() {
+			value, err := config.GetValue(cfg, info.Key)
+			if err != nil {
+				return err
+			}
+			formatted := config.FormatValue(value)
+			if info.Secret && formatted != "" {
+				formatted = "********"
+			}
+			fmt.Printf("%s = %s\n", info.Key, formatted)
+		}
+		return nil
+	},
+}
+
+var configInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Write a commented starter configuration file",
+	Long: `Write a starter configuration file holding the settings most often changed, each with a comment
+on its values, to the file given by --file or ~/.config/claudecat/config.yaml. An existing file is
+only replaced with --force.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := configFile
+		if path == "" {
+			homeDir, _ := os.UserHomeDir()
+			path = filepath.Join(homeDir, ".config", "claudecat", "config.yaml")
+		}
+		path = config.ExpandHome(path)
+		if _, err := os.Stat(path); err == nil && !configInitForce {
+			return fmt.Errorf("%s already exists; use --force to replace it", path)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create config directory: %w", err)
+		}
+		// The file may later hold tokens, so it is only readable by the user
+		if err := os.WriteFile(path, []byte(config.StarterConfig), 0600); err != nil {
+			return fmt.Errorf("failed to write configuration: %w", err)
+		}
+		fmt.Printf("Wrote a starter configuration to %s\n", path)
+		return nil
+	},
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the effective configuration as YAML",
+	Long: `Print the effective configuration as YAML: the defaults merged with the configuration files,
+CLAWCAT_ environment variables and flags, in the order they take precedence. Tokens and passwords are
+masked unless --include-secrets is set.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfigCommandConfig(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		data, err := config.MarshalEffective(cfg, configIncludeSecrets)
+		if err != nil {
+			return err
+		}
+		files := strings.Join(configSourceFiles(), ", ")
+		if files == "" {
+			files = "none"
+		}
+		fmt.Printf("# Effective configuration; configuration files: %s\n", files)
+		_, err = os.Stdout.Write(data)
+		return err
+	},
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the configuration files and the effective configuration for invalid values",
+	Long: `Check each configuration file for YAML errors and keys that are not settings, which are
+otherwise silently ignored, and the effective configuration for invalid values such as an unknown
+timezone or plan. The exit status is 1 when a problem other than an unknown key is found.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var checks []internal.DoctorCheck
+		for _, path := range configSourceFiles() {
+			checks = append(checks, checkConfigFile(path)...)
+		}
+
+		// The effective configuration is checked without failing on the first invalid value
+		loader := config.NewLoader()
+		for _, path := range configSourceFiles() {
+			loader.AddSource(config.NewFileSource(path))
+		}
+		if configFile == "" {
+			loader.AddSource(config.NewEnvSource("CLAWCAT"))
+			loader.AddSource(config.NewFlagSource(cmd.Flags()))
+		}
+		cfg, err := loader.LoadWithDefaults()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		problems := config.NewStandardValidator().Problems(cfg)
+		for _, problem := range problems {
+			checks = append(checks, internal.DoctorCheck{Name: "effective", Status: internal.DoctorFail, Detail: problem,
+				Hint: "fix it with `claudecat config set` or `claudecat config unset`"})
+		}
+		if len(problems) == 0 {
+			checks = append(checks, internal.DoctorCheck{Name: "effective", Status: internal.DoctorPass, Detail: "all values are valid"})
+		}
+		printDoctorChecks(checks)
+
+		failed := 0
+		for _, check := range checks {
+			if check.Status == internal.DoctorFail {
+				failed++
+			}
+		}
+		if failed > 0 {
+			return &ExitCodeError{Code: 1, Reason: fmt.Sprintf("%d configuration problems", failed)}
+		}
+		return nil
+	},
+}
+
+// checkConfigFile reports whether the configuration file at path parses and holds only known keys
+func checkConfigFile(path string) []internal.DoctorCheck {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []internal.DoctorCheck{{Name: path, Status: internal.DoctorFail, Detail: err.Error()}}
+	}
+	unknown, err := config.UnknownKeys(data)
+	if err == nil {
+		_, err = config.NewFileSource(path).Load()
+	}
+	if err != nil {
+		return []internal.DoctorCheck{{Name: path, Status: internal.DoctorFail, Detail: err.Error(), Hint: "fix the syntax; this file is ignored until then"}}
+	}
+	if len(unknown) == 0 {
+		return []internal.DoctorCheck{{Name: path, Status: internal.DoctorPass, Detail: "valid"}}
+	}
+	var checks []internal.DoctorCheck
+	for _, key := range unknown {
+		checks = append(checks, internal.DoctorCheck{Name: path, Status: internal.DoctorWarn, Detail: "unknown key " + key,
+			Hint: "check its spelling in `claudecat config list`; it is ignored"})
+	}
+	return checks
+}
+
+// configSourceFiles returns the configuration files that are read: the --file one, or every
+// existing default path
+func configSourceFiles() []string {
+	if configFile != "" {
+		return []string{configEditPath()}
+	}
+	var files []string
+	for _, path := range config.ConfigPaths() {
+		path = os.ExpandEnv(path)
+		if _, err := os.Stat(path); err == nil {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+var configPathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Print the configuration file edited by set and unset",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fmt.Println(configEditPath())
+		return nil
+	},
+}
+
+var configExportAlertsCmd = &cobra.Command{
+	Use:   "export-alerts [file]",
+	Short: "Export budgets, thresholds and notification channels as portable JSON",
+	Long: `Export the alerting settings as portable JSON so that a team can share one alerting setup
+across developer machines with import-alerts. The profile holds the session warn and alert
+thresholds (subscription.warn_threshold, subscription.alert_threshold) and every limits, alerts,
+budgets and guardrails setting; the plan and all other settings stay per machine.
+
+The profile is written to file, or to stdout when none is given. The SMTP password is left out
+unless --include-secrets is set.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfigCommandConfig(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		profile, err := config.ExportAlerts(cfg, configIncludeSecrets, time.Now())
+		if err != nil {
+			return err
+		}
+		data, err := json.MarshalIndent(profile, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode alert profile: %w", err)
+		}
+		data = append(data, '\n')
+		if len(args) == 0 {
+			_, err = os.Stdout.Write(data)
+			return err
+		}
+
+		path := config.ExpandHome(args[0])
+		mode := os.FileMode(0644)
+		if configIncludeSecrets {
+			mode = 0600
+		}
+		if err := os.WriteFile(path, data, mode); err != nil {
+			return fmt.Errorf("failed to write alert profile: %w", err)
+		}
+		fmt.Printf("Exported %d alert settings to %s\n", len(profile.Settings), path)
+		return nil
+	},
+}
+
+var configImportAlertsCmd = &cobra.Command{
+	Use:   "import-alerts <file>",
+	Short: "Apply an alert profile exported with export-alerts",
+	Long: `Apply the alerting settings of a profile exported with export-alerts to the configuration file
+given by --file, as config set does. Settings the profile does not hold are left unchanged, and a
+profile that would make the configuration invalid is rejected.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data, err := os.ReadFile(config.ExpandHome(args[0]))
+		if err != nil {
+			return fmt.Errorf("failed to read alert profile: %w", err)
+		}
+		profile, err := config.ParseAlertProfile(data)
+		if err != nil {
+			return err
+		}
+		editor, err := config.OpenFileEditor(configEditPath())
+		if err != nil {
+			return err
+		}
+		if err := editor.ImportAlerts(profile); err != nil {
+			return err
+		}
+		if err := editor.Save(); err != nil {
+			return err
+		}
+		fmt.Printf("Imported %d alert settings into %s\n", len(profile.Settings), editor.Path())
+		return nil
+	},
+}
+
+// loadConfigCommandConfig loads the effective configuration, reading only the --file configuration file if given
+func loadConfigCommandConfig(cmd *cobra.Command) (*config.Config, error) {
+	if configFile == "" {
+		return loadConfiguration(cmd)
+	}
+	loader := config.NewLoader()
+	loader.AddSource(config.NewFileSource(configEditPath()))
+	loader.AddValidator(config.NewStandardValidator())
+	return loader.LoadWithDefaults()
+}
+
+// configEditPath returns the configuration file edited by config set and unset
+func configEditPath() string {
+	if configFile != "" {
+		return config.ExpandHome(configFile)
+	}
+	paths := config.ConfigPaths()
+	for i := len(paths) - 1; i >= 0; i-- {
+		path := os.ExpandEnv(paths[i])
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".config", "claudecat", "config.yaml")
+}
+
+func init() {
+	configCmd.PersistentFlags().StringVar(&configFile, "file", "", "configuration file to edit (default: see config --help)")
+	configExportAlertsCmd.Flags().BoolVar(&configIncludeSecrets, "include-secrets", false, "include the SMTP password in the profile")
+	configShowCmd.Flags().BoolVar(&configIncludeSecrets, "include-secrets", false, "print tokens and passwords instead of masking them")
+	configInitCmd.Flags().BoolVar(&configInitForce, "force", false, "replace an existing configuration file")
+	configCmd.AddCommand(configInitCmd, configShowCmd, configValidateCmd, configGetCmd, configSetCmd, configUnsetCmd,
+		configListCmd, configPathCmd, configExportAlertsCmd, configImportAlertsCmd)
+	rootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/fileio"
+)
+
+// discoverDataPaths fills in the data paths from every known Claude data directory when none were given.
+// Duplicate session logs across directories turn on deduplication so merged usage is not counted twice.
+func discoverDataPaths(cfg *config.Config) {
+	if len(cfg.Data.Paths) > 0 {
+		return
+	}
+
+	homeDir, _ := os.UserHomeDir()
+	statePath := ""
+	if cfg.Cache.Dir != "" {
+		statePath = filepath.Join(config.ExpandHome(cfg.Cache.Dir), fileio.DataDirsStateFile)
+	}
+	dirs := fileio.DiscoverDataDirs(fileio.DataDirCandidates(homeDir, runtime.GOOS, os.Getenv), statePath)
+	for _, warning := range dirs.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+	if dirs.DuplicateFiles > 0 {
+		cfg.Data.Deduplication = true
+	}
+
+	if len(dirs.Dirs) == 0 {
+		cfg.Data.Paths = []string{filepath.Join(homeDir, ".claude", "projects")}
+		return
+	}
+	cfg.Data.Paths = dirs.Dirs
+}
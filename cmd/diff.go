@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/penwyp/claudecat/cache"
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/humanize"
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffOutput     string
+	diffFiles      bool
+	diffFailOnLoss bool
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <before> [after]",
+	Short: "Compare two cache snapshots",
+	Long: `Compare two aggregate states of the summary cache and report what changed: usage files newly
+summarized, dropped or summarized from a changed file, entries and cost before and after, and
+the entries, tokens and cost added or lost per day and model. Run it around a backfill or a cache
+rebuild to verify that no usage was lost.
+
+Each side is a snapshot saved with claudecat cache snapshot, or a cache directory. The after side
+defaults to the configured cache. Days are UTC. Files rolled into monthly archives by cache compact
+no longer have a summary, so they show as dropped.
+
+Examples:
+  claudecat cache snapshot before.json                # Save the state before a rebuild
+  claudecat diff before.json                          # Compare it with the cache now
+  claudecat diff before.json after.json --files       # List the files that changed
+  claudecat diff before.json --fail-on-loss           # Exit with an error if usage was lost`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !strings.EqualFold(diffOutput, "table") && !strings.EqualFold(diffOutput, "json") {
+			return fmt.Errorf("invalid output format: %s (valid: table, json)", diffOutput)
+		}
+		cfg, err := loadCacheCommandConfig(cmd, nil)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		before, err := loadSnapshot(cfg, args[0], now)
+		if err != nil {
+			return err
+		}
+		afterSource := config.ExpandHome(cfg.Cache.Dir)
+		if len(args) > 1 {
+			afterSource = args[1]
+		}
+		after, err := loadSnapshot(cfg, afterSource, now)
+		if err != nil {
+			return err
+		}
+
+		diff := cache.DiffSnapshots(before, after)
+		recordCommandResult("changes", len(diff.Usage))
+		if strings.EqualFold(diffOutput, "json") {
+			data, err := sonic.MarshalIndent(diff, "", "  ")
+			if err != nil {
+				return err
+			}
+			if _, err := os.Stdout.Write(append(data, '\n')); err != nil {
+				return err
+			}
+		} else {
+			printSnapshotDiff(diff, diffFiles)
+		}
+
+		if diffFailOnLoss && diff.Lost() {
+			return fmt.Errorf("usage was lost: %d files dropped, %s entries before and %s after",
+				len(diff.FilesRemoved), humanize.Count(diff.EntriesBefore), humanize.Count(diff.EntriesAfter))
+		}
+		return nil
+	},
+}
+
+func init() {
+	diffCmd.Flags().StringVarP(&diffOutput, "output", "o", "table", "output format (table, json)")
+	diffCmd.Flags().BoolVar(&diffFiles, "files", false, "list the usage files added, dropped and changed")
+	diffCmd.Flags().BoolVar(&diffFailOnLoss, "fail-on-loss", false, "exit with an error when a file was dropped or a day and model lost entries")
+	rootCmd.AddCommand(diffCmd)
+}
+
+// loadSnapshot reads the snapshot saved in source, or takes one of the cache when source is a
+// cache directory; the cache is opened read-only
+func loadSnapshot(cfg *config.Config, source string, now time.Time) (*cache.Snapshot, error) {
+	source = config.ExpandHome(source)
+	info, err := os.Stat(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	if !info.IsDir() {
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read snapshot: %w", err)
+		}
+		snapshot, err := cache.ParseSnapshot(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", source, err)
+		}
+		return snapshot, nil
+	}
+
+	if _, err := os.Stat(filepath.Join(source, "summaries")); err != nil {
+		return nil, fmt.Errorf("%s is not a cache directory", source)
+	}
+	fileCache, err := cache.OpenFileBasedSummaryCacheReadOnly(source, cfg.Cache.Encryption)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cache: %w", err)
+	}
+	defer fileCache.Close()
+	return cache.NewSnapshot(fileCache.Summaries(), now), nil
+}
+
+// printSnapshotDiff prints the file and entry totals, the files when requested and the usage changes
+func printSnapshotDiff(diff cache.SnapshotDiff, files bool) {
+	fmt.Printf("Files:   %d added, %d dropped, %d changed, %d unchanged\n",
+		len(diff.FilesAdded), len(diff.FilesRemoved), len(diff.FilesChanged), diff.FilesSame)
+	fmt.Printf("Entries: %s -> %s (%s)\n", humanize.Count(diff.EntriesBefore), humanize.Count(diff.EntriesAfter),
+		humanize.Signed(diff.EntriesAfter-diff.EntriesBefore))
+	fmt.Printf("Cost:    %s -> %s (%s)\n", humanize.Cost(diff.CostBefore), humanize.Cost(diff.CostAfter),
+		humanize.CostDelta(diff.CostAfter-diff.CostBefore))
+
+	if files {
+		for _, group := range []struct {
+			label string
+			paths []string
+		}{{"Added", diff.FilesAdded}, {"Dropped", diff.FilesRemoved}, {"Changed", diff.FilesChanged}} {
+			if len(group.paths) == 0 {
+				continue
+			}
+			fmt.Printf("\n%s:\n", group.label)
+			for _, path := range group.paths {
+				fmt.Printf("  %s\n", path)
+			}
+		}
+	}
+
+	fmt.Println()
+	if len(diff.Usage) == 0 {
+		fmt.Println("No usage changed.")
+		return
+	}
+	table := newTableFormatter([]string{"Day", "Model", "Entries", "Change", "Tokens", "Cost"})
+	for _, delta := range diff.Usage {
+		table.addRow([]string{
+			delta.Day,
+			delta.Model,
+			fmt.Sprintf("%s -> %s", humanize.Count(delta.Before.Entries), humanize.Count(delta.After.Entries)),
+			humanize.Signed(delta.Entries),
+			humanize.Signed(delta.Tokens),
+			humanize.CostDelta(delta.CostUSD),
+		})
+	}
+	fmt.Println(table.render())
+}
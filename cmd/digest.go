@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/penwyp/claudecat/calculations"
+	"github.com/penwyp/claudecat/humanize"
+	"github.com/penwyp/claudecat/internal"
+	"github.com/spf13/cobra"
+)
+
+var (
+	digestPeriod string
+	digestOutput string
+)
+
+var digestCmd = &cobra.Command{
+	Use:   "digest [path...]",
+	Short: "Print a short usage digest to paste into chat or email",
+	Long: `Summarize a period of usage in a few lines: highlights, the most expensive sessions,
+days with unusually high spend and how the usage relates to the subscription plan.
+
+The period covers whole calendar days up to now and is compared with the period before it.
+A day is flagged as an anomaly when its cost is more than two standard deviations above the
+daily average of the preceding four weeks.
+
+Examples:
+  claudecat digest                     # Markdown digest of the last 7 days
+  claudecat digest --period month      # Last 30 days
+  claudecat digest -o text             # Plain text without Markdown markup
+  claudecat digest -o json             # Machine-readable output`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output := strings.ToLower(digestOutput)
+		if output != "markdown" && output != "text" && output != "json" {
+			return fmt.Errorf("invalid output format: %s (valid: markdown, text, json)", digestOutput)
+		}
+		if _, err := calculations.DigestPeriodDays(digestPeriod); err != nil {
+			return err
+		}
+
+		cfg, err := loadCacheCommandConfig(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		analyzer, err := internal.NewAnalyzer(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create analyzer: %w", err)
+		}
+		digest, err := analyzer.Digest(cfg.Data.Paths, digestPeriod, time.Now())
+		if err != nil {
+			return fmt.Errorf("digest failed: %w", err)
+		}
+		recordCommandResult("sessions", digest.Sessions)
+
+		if output == "json" {
+			data, err := sonic.MarshalIndent(digest, "", "  ")
+			if err != nil {
+				return err
+			}
+			_, err = os.Stdout.Write(append(data, '\n'))
+			return err
+		}
+
+		fmt.Print(renderDigest(digest, output == "markdown"))
+		return nil
+	},
+}
+
+func init() {
+	digestCmd.Flags().StringVar(&digestPeriod, "period", calculations.DigestPeriodWeek, "digest period (day, week, month)")
+	digestCmd.Flags().StringVarP(&digestOutput, "output", "o", "markdown", "output format (markdown, text, json)")
+	rootCmd.AddCommand(digestCmd)
+}
+
+// renderDigest formats a digest as Markdown, or as plain text when markdown is false
+func renderDigest(d calculations.Digest, markdown bool) string {
+	var b strings.Builder
+	heading := func(title string) {
+		if markdown {
+			fmt.Fprintf(&b, "\n### %s\n\n", title)
+		} else {
+			fmt.Fprintf(&b, "\n%s\n%s\n", title, strings.Repeat("-", len(title)))
+		}
+	}
+	bold := func(s string) string {
+		if markdown {
+			return "**" + s + "**"
+		}
+		return s
+	}
+
+	title := fmt.Sprintf("Claude usage digest: %s - %s", d.Start.Format("Jan 2"), d.End.In(d.Start.Location()).Format("Jan 2, 2006"))
+	if markdown {
+		fmt.Fprintf(&b, "## %s\n", title)
+	} else {
+		fmt.Fprintf(&b, "%s\n%s\n", title, strings.Repeat("=", len(title)))
+	}
+
+	heading("Highlights")
+	spend := fmt.Sprintf("- Spend: %s across %s sessions on %d active day(s), %s tokens",
+		bold(humanize.Cost(d.TotalCost)), humanize.Count(d.Sessions), d.ActiveDays, humanize.Count(d.TotalTokens))
+	if d.HasPrevious && d.PreviousCost > 0 {
+		spend += fmt.Sprintf(" (%+.0f%% vs previous %s)", d.CostChange, d.Period)
+	}
+	b.WriteString(spend + "\n")
+	if d.BusiestDay != nil {
+		fmt.Fprintf(&b, "- Busiest day: %s (%s)\n", d.BusiestDay.Format("Mon Jan 2"), humanize.Cost(d.BusiestDayCost))
+	}
+	if d.TopModel != nil {
+		fmt.Fprintf(&b, "- Top model: %s (%.0f%% of spend)\n", d.TopModel.Name, d.TopModel.Share*100)
+	}
+	if d.TopProject != nil {
+		fmt.Fprintf(&b, "- Top project: %s (%.0f%% of spend)\n", d.TopProject.Name, d.TopProject.Share*100)
+	}
+
+	heading("Notable sessions")
+	if len(d.NotableSessions) == 0 {
+		b.WriteString("- No sessions this period\n")
+	}
+	for _, session := range d.NotableSessions {
+		line := fmt.Sprintf("- %s: %s, %s tokens", session.StartTime.In(d.Start.Location()).Format("Mon Jan 2 15:04"),
+			humanize.Cost(session.Cost), humanize.Count(session.Tokens))
+		if len(session.Projects) > 0 {
+			line += " in " + strings.Join(session.Projects, ", ")
+		}
+		if session.LimitHit {
+			line += " (hit limit)"
+		}
+		b.WriteString(line + "\n")
+	}
+
+	heading("Anomalies")
+	if len(d.Anomalies) == 0 {
+		b.WriteString("- None: daily spend stayed within the usual range\n")
+	}
+	for _, anomaly := range d.Anomalies {
+		fmt.Fprintf(&b, "- %s: %s, %.1fx the daily average of %s\n", anomaly.Date.Format("Mon Jan 2"),
+			humanize.Cost(anomaly.Cost), anomaly.Ratio, humanize.Cost(anomaly.Baseline))
+	}
+
+	heading("Budget")
+	budget := d.Budget
+	if budget.MonthlyPrice > 0 {
+		fmt.Fprintf(&b, "- Plan: %s (%s/month, %s for this period); API-equivalent spend %s\n",
+			budget.Plan, humanize.Cost(budget.MonthlyPrice), humanize.Cost(budget.ProratedPrice), humanize.Cost(d.TotalCost))
+	} else {
+		fmt.Fprintf(&b, "- Plan: %s\n", budget.Plan)
+	}
+	if budget.SessionLimit > 0 {
+		fmt.Fprintf(&b, "- Peak session: %s of the %s session limit (%.0f%%)\n",
+			humanize.Cost(budget.PeakSessionCost), humanize.Cost(budget.SessionLimit), budget.PeakSessionCost/budget.SessionLimit*100)
+	}
+	if budget.WarnThreshold > 0 {
+		fmt.Fprintf(&b, "- Sessions above %.0f%% of the limit: %d; limit hits: %d\n",
+			budget.WarnThreshold*100, budget.SessionsOverWarn, budget.LimitHits)
+	} else {
+		fmt.Fprintf(&b, "- Limit hits: %d\n", budget.LimitHits)
+	}
+	return b.String()
+}
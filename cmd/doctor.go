@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/internal"
+	"github.com/penwyp/claudecat/logging"
+	"github.com/penwyp/claudecat/terminal"
+	"github.com/spf13/cobra"
+)
+
+var doctorOutput string
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor [path...]",
+	Short: "Diagnose the configuration, data paths, cache and pricing source",
+	Long: `Run diagnostics of the environment claudecat works in and print pass/fail results with hints on
+how to fix each problem:
+
+  config       the configuration files load and validate
+  timezone     app.timezone and ui.timezone are known timezones
+  data path    every data path exists
+  usage files  the JSONL usage files are readable and their first lines are valid JSON
+  cache        the cache directory is writable, its encryption key loads and it is within max_disk_size
+  pricing      the pricing source is usable; the LiteLLM price table is fetched to check it is reachable
+  clock        the system clock agrees with a server and with the times of the usage files
+
+The network is only used for the pricing and clock checks, and not at all with
+data.pricing_offline_mode. The exit status is 1 when a check fails.
+
+Examples:
+  claudecat doctor
+  claudecat doctor ~/claude-logs -o json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output := strings.ToLower(doctorOutput)
+		if output != "table" && output != "json" {
+			return fmt.Errorf("invalid output format: %s (valid: table, json)", doctorOutput)
+		}
+
+		// A broken configuration is a finding like any other; the remaining checks use the defaults
+		cfg, err := loadConfiguration(cmd)
+		checks := []internal.DoctorCheck{internal.ConfigCheck(err)}
+		if err != nil {
+			cfg = config.DefaultConfig()
+		}
+		if len(args) > 0 {
+			cfg.Data.Paths = args
+		}
+		discoverDataPaths(cfg)
+		logging.InitLogger(cfg.App.LogLevel, cfg.App.LogFile, cfg.Debug.Enabled)
+
+		checks = append(checks, internal.NewDoctor(cfg).Run(context.Background())...)
+		failed := 0
+		for _, check := range checks {
+			if check.Status == internal.DoctorFail {
+				failed++
+			}
+		}
+		recordCommandResult("failed", failed)
+
+		if output == "json" {
+			data, err := sonic.MarshalIndent(checks, "", "  ")
+			if err != nil {
+				return err
+			}
+			if _, err := os.Stdout.Write(append(data, '\n')); err != nil {
+				return err
+			}
+		} else {
+			printDoctorChecks(checks)
+		}
+
+		if failed > 0 {
+			return &ExitCodeError{Code: 1, Reason: fmt.Sprintf("%d checks failed", failed)}
+		}
+		return nil
+	},
+}
+
+func init() {
+	doctorCmd.Flags().StringVarP(&doctorOutput, "output", "o", "table", "output format (table, json)")
+	rootCmd.AddCommand(doctorCmd)
+}
+
+// printDoctorChecks prints one line per check, followed by its hint, and a summary of the outcomes
+func printDoctorChecks(checks []internal.DoctorCheck) {
+	width := 0
+	for _, check := range checks {
+		width = max(width, len(check.Name))
+	}
+
+	counts := make(map[string]int)
+	for _, check := range checks {
+		counts[check.Status]++
+		fmt.Printf("%-4s  %-*s  %s\n", strings.ToUpper(check.Status), width, check.Name, check.Detail)
+		if check.Hint != "" {
+			fmt.Print(terminal.Text(fmt.Sprintf("      %-*s  → %s\n", width, "", check.Hint)))
+		}
+	}
+	fmt.Printf("\n%d passed, %d warnings, %d failed, %d skipped\n", counts[internal.DoctorPass], counts[internal.DoctorWarn],
+		counts[internal.DoctorFail], counts[internal.DoctorSkip])
+}
@@ -0,0 +1,125 @@
+//go:build !noexport
+
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/export"
+	"github.com/penwyp/claudecat/humanize"
+	"github.com/penwyp/claudecat/internal"
+	"github.com/penwyp/claudecat/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportFormat string
+	exportFrom   string
+	exportTo     string
+)
+
+// exportFormatExtensions maps file extensions to the export format they imply
+var exportFormatExtensions = map[string]string{
+	".parquet": export.FormatParquet,
+	".pq":      export.FormatParquet,
+	".db":      export.FormatSQLite,
+	".sqlite":  export.FormatSQLite,
+	".sqlite3": export.FormatSQLite,
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export <file> [path...]",
+	Short: "Export every usage entry to Parquet or SQLite",
+	Long: fmt.Sprintf(`Write one row per parsed usage entry, not just aggregates, to a Parquet file or a SQLite
+database for use in DuckDB, Metabase or other tools. The format is inferred from the file
+extension (.parquet, .db, .sqlite) unless --format is given.
+
+A Parquet file is replaced; in a SQLite database the %[1]s table is replaced and other
+tables are kept. Exports record their schema version (currently %[2]d) in the Parquet key-value
+metadata (claudecat.schema_version) or in the claudecat_export table of the database, so queries
+can detect column changes across claudecat releases. Entries older than
+retention.redact_ids_after_days are written with their IDs redacted.
+
+Columns: timestamp (UTC), model, project, session_id, message_id, request_id, input_tokens,
+  output_tokens, cache_creation_tokens, cache_creation_1h_tokens, cache_read_tokens,
+  total_tokens, cost_usd, logged_cost_usd
+
+Examples:
+  claudecat export usage.parquet                           # All usage
+  claudecat export usage.db --from 2025-01-01              # Since a date, into SQLite
+  claudecat export june.out --format parquet --from 2025-06-01 --to 2025-06-30`, export.Table, export.SchemaVersion),
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		target := config.ExpandHome(args[0])
+		format := strings.ToLower(exportFormat)
+		if format == "" {
+			format = exportFormatExtensions[strings.ToLower(filepath.Ext(target))]
+			if format == "" {
+				return fmt.Errorf("cannot infer the export format of %s; use --format parquet or --format sqlite", args[0])
+			}
+		}
+		if format != export.FormatParquet && format != export.FormatSQLite {
+			return fmt.Errorf("invalid export format: %s (valid: parquet, sqlite)", exportFormat)
+		}
+
+		var fromTime, toTime time.Time
+		var err error
+		if exportFrom != "" {
+			if fromTime, err = parseTimeString(exportFrom); err != nil {
+				return fmt.Errorf("invalid from date %s: %w", exportFrom, err)
+			}
+		}
+		if exportTo != "" {
+			if toTime, err = parseTimeString(exportTo); err != nil {
+				return fmt.Errorf("invalid to date %s: %w", exportTo, err)
+			}
+		}
+
+		cfg, err := loadCacheCommandConfig(cmd, args[1:])
+		if err != nil {
+			return err
+		}
+		analyzer, err := internal.NewAnalyzer(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create analyzer: %w", err)
+		}
+		entries, err := analyzer.LoadEntries(cfg.Data.Paths, fromTime, toTime)
+		if err != nil {
+			return fmt.Errorf("failed to load usage entries: %w", err)
+		}
+
+		// Apply the retention policy before anything is written
+		redactExpiredEntries(entries, models.RedactionCutoff(cfg.Retention.RedactIDsAfterDays, time.Now()))
+
+		if err := export.WriteUsageEntries(target, format, entries, "claudecat "+Version); err != nil {
+			return err
+		}
+		recordCommandResult("entries", len(entries))
+		fmt.Printf("Exported %s entries to %s (%s, schema version %d)\n",
+			humanize.Count(len(entries)), target, format, export.SchemaVersion)
+		return nil
+	},
+}
+
+// redactExpiredEntries removes IDs from entries older than cutoff, keeping their token counts and costs
+func redactExpiredEntries(entries []models.UsageEntry, cutoff time.Time) {
+	if cutoff.IsZero() {
+		return
+	}
+	for i := range entries {
+		if entries[i].Timestamp.Before(cutoff) {
+			entries[i] = models.RedactEntryIDs(entries[i])
+		}
+	}
+}
+
+func init() {
+	exportCmd.Flags().StringVar(&exportFormat, "format", "", "export format (parquet, sqlite); inferred from the file extension by default")
+	exportCmd.Flags().StringVar(&exportFrom, "from", "", "start date (YYYY-MM-DD or YYYY-MM-DD HH:MM:SS)")
+	exportCmd.Flags().StringVar(&exportTo, "to", "", "end date (YYYY-MM-DD or YYYY-MM-DD HH:MM:SS)")
+	rootCmd.AddCommand(exportCmd)
+}
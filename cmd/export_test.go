@@ -0,0 +1,44 @@
+//go:build !noexport
+
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactExpiredEntries(t *testing.T) {
+	now := time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC)
+	sessionID := "0b6c3c1e-9a43-4a8e-b5d2-6f1f0e2d3c4b"
+	entry := func(age time.Duration) models.UsageEntry {
+		return models.UsageEntry{Timestamp: now.Add(-age), SessionID: sessionID, MessageID: "msg_01ABC",
+			RequestID: "req_01XYZ", Project: "webapp", InputTokens: 100, CostUSD: 0.5}
+	}
+
+	tests := []struct {
+		name      string
+		afterDays int
+		age       time.Duration
+		redacted  bool
+	}{
+		{"disabled", 0, 90 * 24 * time.Hour, false},
+		{"recent entry kept", 30, 24 * time.Hour, false},
+		{"old entry redacted", 30, 43 * 24 * time.Hour, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := []models.UsageEntry{entry(tt.age)}
+			redactExpiredEntries(entries, models.RedactionCutoff(tt.afterDays, now))
+
+			got := entries[0]
+			assert.Equal(t, tt.redacted, models.IsRedacted(got.SessionID))
+			assert.Equal(t, tt.redacted, models.IsRedacted(got.MessageID))
+			assert.Equal(t, tt.redacted, models.IsRedacted(got.RequestID))
+			assert.Equal(t, 100, got.InputTokens, "token counts are kept")
+			assert.Equal(t, 0.5, got.CostUSD, "costs are kept")
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/penwyp/claudecat/calculations"
+	"github.com/penwyp/claudecat/humanize"
+	"github.com/penwyp/claudecat/internal"
+	"github.com/spf13/cobra"
+)
+
+var (
+	forecastPeriod     string
+	forecastModel      string
+	forecastHistory    int
+	forecastConfidence float64
+	forecastOutput     string
+)
+
+var forecastCmd = &cobra.Command{
+	Use:   "forecast [path...]",
+	Short: "Project the cost of the current week or month",
+	Long: `Project the cost of the current calendar week or month from the daily costs of recent days.
+The linear model extends the trend of the daily costs; the EWMA model assumes the recent average
+continues. The projection comes with a confidence band derived from how far the daily costs
+strayed from the model, and the trend of the daily costs is reported for both models.
+
+Examples:
+  claudecat forecast                                # End-of-month cost from the last 28 days
+  claudecat forecast --period week --model ewma
+  claudecat forecast --history 60 --confidence 0.95 -o json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !strings.EqualFold(forecastOutput, "table") && !strings.EqualFold(forecastOutput, "json") {
+			return fmt.Errorf("invalid output format: %s (valid: table, json)", forecastOutput)
+		}
+		if _, _, err := calculations.ReportPeriodBounds(forecastPeriod, time.Now(), time.Local); err != nil {
+			return err
+		}
+
+		cfg, err := loadCacheCommandConfig(cmd, args)
+		if err != nil {
+			return err
+		}
+		analyzer, err := internal.NewAnalyzer(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create analyzer: %w", err)
+		}
+		now := time.Now()
+		forecast, err := analyzer.Forecast(cfg.Data.Paths, forecastPeriod, forecastModel, forecastHistory, forecastConfidence, now)
+		if err != nil {
+			return fmt.Errorf("forecast failed: %w", err)
+		}
+		recordCommandResult("forecast", len(forecast.Days))
+
+		if strings.EqualFold(forecastOutput, "json") {
+			data, err := sonic.MarshalIndent(forecast, "", "  ")
+			if err != nil {
+				return err
+			}
+			_, err = os.Stdout.Write(append(data, '\n'))
+			return err
+		}
+
+		loc, err := time.LoadLocation(cfg.App.Timezone)
+		if err != nil {
+			loc = time.Local
+		}
+		printForecast(forecast, loc)
+		return nil
+	},
+}
+
+func init() {
+	forecastCmd.Flags().StringVar(&forecastPeriod, "period", calculations.ReportPeriodMonth, "forecast period (week, month)")
+	forecastCmd.Flags().StringVar(&forecastModel, "model", calculations.ForecastLinear, "forecast model ("+strings.Join(calculations.ForecastModels, ", ")+")")
+	forecastCmd.Flags().IntVar(&forecastHistory, "history", calculations.DefaultForecastHistoryDays, "days of history the model is fitted to")
+	forecastCmd.Flags().Float64Var(&forecastConfidence, "confidence", calculations.DefaultForecastConfidence, "confidence of the band around the projection (between 0 and 1)")
+	forecastCmd.Flags().StringVarP(&forecastOutput, "output", "o", "table", "output format (table, json)")
+	rootCmd.AddCommand(forecastCmd)
+}
+
+// printForecast prints the projection followed by the actual and projected cost of each day
+func printForecast(forecast calculations.Forecast, loc *time.Location) {
+	last := forecast.End.AddDate(0, 0, -1)
+	fmt.Printf("Forecast for the %s of %s to %s (%s model, %d days of history)\n\n",
+		forecast.Period, forecast.Start.In(loc).Format("2006-01-02"), last.In(loc).Format("2006-01-02"),
+		forecast.Model, forecast.HistoryDays)
+	fmt.Printf("Spent so far:     %s\n", humanize.Cost(forecast.Spent))
+	fmt.Printf("Projected total:  %s (%.0f%% band %s to %s)\n",
+		humanize.Cost(forecast.Projected), forecast.Confidence*100, humanize.Cost(forecast.Low), humanize.Cost(forecast.High))
+	fmt.Printf("Next day:         %s\n", humanize.Cost(forecast.DailyCost))
+	fmt.Printf("Trend:            %s (%s/day)\n\n", forecast.Trend, humanize.CostDelta(forecast.TrendPerDay))
+
+	table := newTableFormatter([]string{"Date", "Cost (USD)", "Low", "High", "Status"})
+	for _, day := range forecast.Days {
+		low, high, status := "", "", "actual"
+		if day.Projected {
+			low, high, status = humanize.Cost(day.Low), humanize.Cost(day.High), "projected"
+		}
+		table.addRow([]string{day.Date.In(loc).Format("2006-01-02 Mon"), humanize.Cost(day.Cost), low, high, status})
+	}
+	fmt.Println(table.render())
+}
@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/penwyp/claudecat/calculations"
+	"github.com/penwyp/claudecat/humanize"
+	"github.com/penwyp/claudecat/internal"
+	"github.com/spf13/cobra"
+)
+
+var (
+	heatmapOutput string
+	heatmapMetric string
+	heatmapFrom   string
+	heatmapTo     string
+	heatmapModel  string
+)
+
+// heatmapShades are the cells of the terminal heatmap, from no usage to the busiest quarter
+var heatmapShades = []string{"··", "░░", "▒▒", "▓▓", "██"}
+
+// heatmapReport is the JSON representation of the heatmap
+type heatmapReport struct {
+	Timezone string                    `json:"timezone"`
+	Days     []calculations.HeatmapDay `json:"days"`
+}
+
+var heatmapCmd = &cobra.Command{
+	Use:   "heatmap [path...]",
+	Short: "Show usage by hour of day and day of week",
+	Long: `Render a heatmap of the tokens or cost of every message by day of week and hour of day in
+the configured timezone, showing when in the week usage peaks. Each cell is shaded by its share of
+the busiest hour; rows end with the day's total.
+
+The JSON and CSV output hold the entries, tokens and cost of each of the 168 hours.
+
+Examples:
+  claudecat heatmap                          # Tokens over all usage
+  claudecat heatmap --metric cost            # Cost instead
+  claudecat heatmap --from 2025-01-01        # Since a date
+  claudecat heatmap --model opus             # Models whose name contains "opus"
+  claudecat heatmap -o csv > heatmap.csv     # One row per day and hour`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output := strings.ToLower(heatmapOutput)
+		if output != "table" && output != "json" && output != "csv" {
+			return fmt.Errorf("invalid output format: %s (valid: table, json, csv)", heatmapOutput)
+		}
+		metric := strings.ToLower(heatmapMetric)
+		if metric != "tokens" && metric != "cost" {
+			return fmt.Errorf("invalid metric: %s (valid: tokens, cost)", heatmapMetric)
+		}
+		var fromTime, toTime time.Time
+		var err error
+		if heatmapFrom != "" {
+			if fromTime, err = parseTimeString(heatmapFrom); err != nil {
+				return fmt.Errorf("invalid from date %s: %w", heatmapFrom, err)
+			}
+		}
+		if heatmapTo != "" {
+			if toTime, err = parseTimeString(heatmapTo); err != nil {
+				return fmt.Errorf("invalid to date %s: %w", heatmapTo, err)
+			}
+		}
+
+		cfg, err := loadCacheCommandConfig(cmd, args)
+		if err != nil {
+			return err
+		}
+		analyzer, err := internal.NewAnalyzer(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create analyzer: %w", err)
+		}
+		results, err := analyzer.Analyze(cfg.Data.Paths)
+		if err != nil {
+			return fmt.Errorf("analysis failed: %w", err)
+		}
+		loc, err := time.LoadLocation(cfg.App.Timezone)
+		if err != nil {
+			loc = time.Local
+		}
+
+		heatmap := calculations.NewUsageHeatmap(loc)
+		entries := 0
+		for _, result := range results {
+			if !fromTime.IsZero() && result.Timestamp.Before(fromTime) {
+				continue
+			}
+			if !toTime.IsZero() && result.Timestamp.After(toTime) {
+				continue
+			}
+			if heatmapModel != "" && !strings.Contains(strings.ToLower(result.Model), strings.ToLower(heatmapModel)) {
+				continue
+			}
+			heatmap.Add(result.Timestamp, result.TotalTokens, result.CostUSD)
+			entries++
+		}
+		recordCommandResult("messages", entries)
+
+		switch output {
+		case "json":
+			data, err := sonic.MarshalIndent(heatmapReport{Timezone: loc.String(), Days: heatmap.Days()}, "", "  ")
+			if err != nil {
+				return err
+			}
+			_, err = os.Stdout.Write(append(data, '\n'))
+			return err
+		case "csv":
+			return writeHeatmapCSV(heatmap)
+		}
+
+		if entries == 0 {
+			fmt.Println("No data to display.")
+			return nil
+		}
+		printHeatmap(heatmap, metric, loc)
+		return nil
+	},
+}
+
+func init() {
+	heatmapCmd.Flags().StringVarP(&heatmapOutput, "output", "o", "table", "output format (table, json, csv)")
+	heatmapCmd.Flags().StringVar(&heatmapMetric, "metric", "tokens", "value shaded in the table (tokens, cost)")
+	heatmapCmd.Flags().StringVar(&heatmapFrom, "from", "", "start date (YYYY-MM-DD or YYYY-MM-DD HH:MM:SS)")
+	heatmapCmd.Flags().StringVar(&heatmapTo, "to", "", "end date (YYYY-MM-DD or YYYY-MM-DD HH:MM:SS)")
+	heatmapCmd.Flags().StringVar(&heatmapModel, "model", "", "only include models whose name contains this text")
+	_ = heatmapCmd.RegisterFlagCompletionFunc("model", completeModels)
+	rootCmd.AddCommand(heatmapCmd)
+}
+
+// heatmapValue returns the value of metric in cell
+func heatmapValue(metric string) func(calculations.HeatmapCell) float64 {
+	if metric == "cost" {
+		return func(cell calculations.HeatmapCell) float64 { return cell.Cost }
+	}
+	return func(cell calculations.HeatmapCell) float64 { return float64(cell.Tokens) }
+}
+
+// formatHeatmapValue formats a value of metric
+func formatHeatmapValue(metric string, value float64) string {
+	if metric == "cost" {
+		return humanize.Cost(value)
+	}
+	return humanize.Count(int(value))
+}
+
+// printHeatmap prints a row of shaded hours per weekday with the day's total
+func printHeatmap(heatmap *calculations.UsageHeatmap, metric string, loc *time.Location) {
+	value := heatmapValue(metric)
+	peakDay, peak, _ := heatmap.Peak(value)
+	busiest := value(peak)
+
+	fmt.Printf("%s by hour of day (%s)\n\n", strings.ToUpper(metric[:1])+metric[1:], loc)
+	var header strings.Builder
+	header.WriteString("     ")
+	for hour := 0; hour < 24; hour += 3 {
+		fmt.Fprintf(&header, "%02d    ", hour)
+	}
+	fmt.Println(strings.TrimRight(header.String(), " "))
+	for _, day := range heatmap.Days() {
+		var row strings.Builder
+		total := 0.0
+		for _, cell := range day.Hours {
+			shade := 0
+			if v := value(cell); cell.Entries > 0 && busiest > 0 {
+				shade = min(int(v/busiest*4-1e-9)+1, 4)
+				total += v
+			}
+			row.WriteString(heatmapShades[shade])
+		}
+		fmt.Printf("%-4s %s  %s\n", day.Weekday[:3], row.String(), formatHeatmapValue(metric, total))
+	}
+	fmt.Println()
+	fmt.Printf("%s none  %s up to 25%%  %s 50%%  %s 75%%  %s 100%% of the busiest hour, %s %02d:00 with %s\n",
+		heatmapShades[0], heatmapShades[1], heatmapShades[2], heatmapShades[3], heatmapShades[4], peakDay[:3], peak.Hour, formatHeatmapValue(metric, busiest))
+}
+
+// writeHeatmapCSV writes one record per weekday and hour
+func writeHeatmapCSV(heatmap *calculations.UsageHeatmap) error {
+	writer := csv.NewWriter(os.Stdout)
+	_ = writer.Write([]string{"Weekday", "Hour", "Entries", "Total Tokens", "Cost USD"})
+	for _, day := range heatmap.Days() {
+		for _, cell := range day.Hours {
+			_ = writer.Write([]string{
+				day.Weekday,
+				strconv.Itoa(cell.Hour),
+				strconv.Itoa(cell.Entries),
+				strconv.Itoa(cell.Tokens),
+				fmt.Sprintf("%.4f", cell.Cost),
+			})
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/penwyp/claudecat/calculations"
+	"github.com/penwyp/claudecat/humanize"
+	"github.com/penwyp/claudecat/internal"
+	"github.com/spf13/cobra"
+)
+
+var (
+	histogramOutput string
+	histogramFrom   string
+	histogramTo     string
+	histogramModel  string
+)
+
+// histogramBarWidth is the width of the bar drawn for a bucket holding every message
+const histogramBarWidth = 20
+
+// costHistogramReport is the JSON representation of the per-message cost histogram
+type costHistogramReport struct {
+	Total  calculations.CostDistribution   `json:"total"`
+	Models []calculations.CostDistribution `json:"models"`
+}
+
+var histogramCmd = &cobra.Command{
+	Use:   "histogram [path...]",
+	Short: "Show the distribution of per-message cost",
+	Long: `Bucket every message by its cost and show, overall and per model, how many messages
+fall in each bucket and how much of the spend they account for. This shows whether cost
+comes from many cheap messages or from a few huge ones.
+
+Examples:
+  claudecat histogram                        # All usage
+  claudecat histogram --from 2025-01-01      # Since a date
+  claudecat histogram --model opus           # Models whose name contains "opus"
+  claudecat histogram -o json                # Machine-readable output`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !strings.EqualFold(histogramOutput, "table") && !strings.EqualFold(histogramOutput, "json") {
+			return fmt.Errorf("invalid output format: %s (valid: table, json)", histogramOutput)
+		}
+		var fromTime, toTime time.Time
+		var err error
+		if histogramFrom != "" {
+			if fromTime, err = parseTimeString(histogramFrom); err != nil {
+				return fmt.Errorf("invalid from date %s: %w", histogramFrom, err)
+			}
+		}
+		if histogramTo != "" {
+			if toTime, err = parseTimeString(histogramTo); err != nil {
+				return fmt.Errorf("invalid to date %s: %w", histogramTo, err)
+			}
+		}
+
+		cfg, err := loadCacheCommandConfig(cmd, args)
+		if err != nil {
+			return err
+		}
+		analyzer, err := internal.NewAnalyzer(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create analyzer: %w", err)
+		}
+		results, err := analyzer.Analyze(cfg.Data.Paths)
+		if err != nil {
+			return fmt.Errorf("analysis failed: %w", err)
+		}
+
+		histogram := calculations.NewCostHistogram(nil)
+		for _, result := range results {
+			if !fromTime.IsZero() && result.Timestamp.Before(fromTime) {
+				continue
+			}
+			if !toTime.IsZero() && result.Timestamp.After(toTime) {
+				continue
+			}
+			if histogramModel != "" && !strings.Contains(strings.ToLower(result.Model), strings.ToLower(histogramModel)) {
+				continue
+			}
+			histogram.Add(result.Model, result.CostUSD)
+		}
+		total := histogram.Total()
+		recordCommandResult("messages", total.Messages)
+
+		if strings.EqualFold(histogramOutput, "json") {
+			data, err := sonic.MarshalIndent(costHistogramReport{Total: total, Models: histogram.ByModel()}, "", "  ")
+			if err != nil {
+				return err
+			}
+			_, err = os.Stdout.Write(append(data, '\n'))
+			return err
+		}
+
+		if total.Messages == 0 {
+			fmt.Println("No data to display.")
+			return nil
+		}
+		printCostDistribution("All models", total)
+		for _, dist := range histogram.ByModel() {
+			fmt.Println()
+			printCostDistribution(dist.Model, dist)
+		}
+		return nil
+	},
+}
+
+func init() {
+	histogramCmd.Flags().StringVarP(&histogramOutput, "output", "o", "table", "output format (table, json)")
+	histogramCmd.Flags().StringVar(&histogramFrom, "from", "", "start date (YYYY-MM-DD or YYYY-MM-DD HH:MM:SS)")
+	histogramCmd.Flags().StringVar(&histogramTo, "to", "", "end date (YYYY-MM-DD or YYYY-MM-DD HH:MM:SS)")
+	histogramCmd.Flags().StringVar(&histogramModel, "model", "", "only include models whose name contains this text")
+	_ = histogramCmd.RegisterFlagCompletionFunc("model", completeModels)
+	rootCmd.AddCommand(histogramCmd)
+}
+
+// printCostDistribution prints one histogram with a bar per bucket scaled to its share of messages
+func printCostDistribution(title string, dist calculations.CostDistribution) {
+	fmt.Printf("%s (%s messages, %s)\n", title, humanize.Count(dist.Messages), humanize.Cost(dist.Cost))
+
+	table := newTableFormatter([]string{"Bucket", "Messages", "% Messages", "Cost (USD)", "% Cost", "Distribution"})
+	for i, bucket := range dist.Buckets {
+		share := dist.MessageShare(i)
+		table.addRow([]string{
+			bucket.Label,
+			humanize.Count(bucket.Messages),
+			fmt.Sprintf("%.1f%%", share*100),
+			humanize.Cost(bucket.Cost),
+			fmt.Sprintf("%.1f%%", dist.CostShare(i)*100),
+			strings.Repeat("█", int(share*histogramBarWidth+0.5)),
+		})
+	}
+	fmt.Println(table.render())
+}
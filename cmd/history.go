@@ -0,0 +1,254 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/humanize"
+	"github.com/penwyp/claudecat/internal"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var (
+	historyLimit   int
+	historyCommand string
+	historyOutput  string
+)
+
+// commandResults collects result counts reported by the running command for the history log
+var commandResults = make(map[string]int)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show previous claudecat invocations",
+	Long: `Show the audit log of previous claudecat invocations, including flags, duration and
+result counts, to help reproduce past reports.
+
+Set CLAUDECAT_NO_HISTORY=1 to disable recording.
+
+Examples:
+  claudecat history                        # Show the 20 most recent invocations
+  claudecat history --command analyze      # Only show analyze runs
+  claudecat history --limit 0 -o json      # Dump the full log as JSON`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		validOutputs := []string{"table", "json"}
+		valid := false
+		for _, v := range validOutputs {
+			if strings.EqualFold(historyOutput, v) {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("invalid output format: %s (valid: %s)", historyOutput, strings.Join(validOutputs, ", "))
+		}
+
+		cfg, err := loadConfiguration(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		history, err := internal.OpenHistoryLog(cfg)
+		if err != nil {
+			return err
+		}
+		records, err := history.Read()
+		if err != nil {
+			return err
+		}
+
+		if historyCommand != "" {
+			var filtered []internal.HistoryRecord
+			for _, record := range records {
+				if strings.Contains(record.Command, historyCommand) {
+					filtered = append(filtered, record)
+				}
+			}
+			records = filtered
+		}
+		if historyLimit > 0 && len(records) > historyLimit {
+			records = records[len(records)-historyLimit:]
+		}
+
+		if strings.EqualFold(historyOutput, "json") {
+			if records == nil {
+				records = []internal.HistoryRecord{}
+			}
+			data, err := sonic.MarshalIndent(records, "", "  ")
+			if err != nil {
+				return err
+			}
+			_, err = os.Stdout.Write(append(data, '\n'))
+			return err
+		}
+
+		if len(records) == 0 {
+			fmt.Println("No history recorded yet.")
+			return nil
+		}
+
+		table := newTableFormatter([]string{"Time", "Command", "Flags", "Duration", "Status", "Results"})
+		for _, record := range records {
+			status := "ok"
+			if !record.Success {
+				status = "failed"
+			}
+			table.addRow([]string{
+				record.Time.Local().Format("2006-01-02 15:04:05"),
+				strings.TrimSpace(record.Command + " " + strings.Join(record.Args, " ")),
+				formatHistoryFlags(record.Flags),
+				record.Duration.Round(time.Millisecond).String(),
+				status,
+				formatHistoryResults(record.Results),
+			})
+		}
+		fmt.Println(table.render())
+		return nil
+	},
+}
+
+func init() {
+	historyCmd.Flags().IntVarP(&historyLimit, "limit", "n", 20, "number of most recent invocations to show (0 = all)")
+	historyCmd.Flags().StringVar(&historyCommand, "command", "", "only show invocations of this command")
+	historyCmd.Flags().StringVarP(&historyOutput, "output", "o", "table", "output format (table, json)")
+
+	rootCmd.AddCommand(historyCmd)
+}
+
+// recordCommandResult reports a result count for the current invocation's history record
+func recordCommandResult(name string, count int) {
+	commandResults[name] = count
+}
+
+// recordHistory appends the finished invocation to the history log
+func recordHistory(cmd *cobra.Command, start time.Time, runErr error) {
+	if cmd == nil || os.Getenv("CLAUDECAT_NO_HISTORY") != "" {
+		return
+	}
+	if skipHistory(cmd) {
+		return
+	}
+
+	record := internal.HistoryRecord{
+		Time:     start,
+		Command:  cmd.CommandPath(),
+		Args:     historyArgs(cmd),
+		Duration: time.Since(start),
+		Success:  runErr == nil,
+	}
+	if runErr != nil {
+		record.Error = runErr.Error()
+	}
+	if len(commandResults) > 0 {
+		record.Results = commandResults
+	}
+
+	record.Flags = historyFlags(cmd)
+
+	// Failing to record history must never fail the command itself. Without the configuration it is
+	// unknown whether the history must be encrypted, so nothing is recorded.
+	cfg, err := loadConfiguration(cmd)
+	if err != nil {
+		return
+	}
+	history, err := internal.OpenHistoryLog(cfg)
+	if err == nil {
+		err = history.Append(record)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record command history: %v\n", err)
+	}
+}
+
+// historySecretAnnotation marks flags whose values are masked in the history, see markHistorySecret
+const historySecretAnnotation = "claudecat_history_secret"
+
+// markHistorySecret masks the values of the named flags in the history, for tokens, webhook URLs and
+// commands that may embed credentials
+func markHistorySecret(flags *pflag.FlagSet, names ...string) {
+	for _, name := range names {
+		_ = flags.SetAnnotation(name, historySecretAnnotation, []string{"true"})
+	}
+}
+
+// historyArgs returns the arguments of cmd as they are recorded, with the value of config set masked
+// for tokens and passwords
+func historyArgs(cmd *cobra.Command) []string {
+	args := slices.Clone(cmd.Flags().Args())
+	if cmd == configSetCmd && len(args) == 2 {
+		if info, err := config.LookupKey(args[0]); err == nil && info.Secret {
+			args[1] = "********"
+		}
+	}
+	return args
+}
+
+// historyFlags returns the flags given to cmd as they are recorded, secrets masked
+func historyFlags(cmd *cobra.Command) map[string]string {
+	var flags map[string]string
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if flags == nil {
+			flags = make(map[string]string)
+		}
+		value := f.Value.String()
+		if _, secret := f.Annotations[historySecretAnnotation]; secret && value != "" {
+			value = "********"
+		}
+		flags[f.Name] = value
+	})
+	return flags
+}
+
+// skipHistory reports whether cmd is left out of the history: history and version themselves, help,
+// and shell completion, which runs on every tab press
+func skipHistory(cmd *cobra.Command) bool {
+	if help, err := cmd.Flags().GetBool("help"); err == nil && help {
+		return true
+	}
+	for c := cmd; c != nil; c = c.Parent() {
+		switch c.Name() {
+		case historyCmd.Name(), versionCmd.Name(), "help", "completion":
+			return true
+		}
+		if strings.HasPrefix(c.Name(), cobra.ShellCompRequestCmd) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatHistoryFlags renders flags as a sorted, reusable command line fragment
+func formatHistoryFlags(flags map[string]string) string {
+	names := make([]string, 0, len(flags))
+	for name := range flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("--%s=%s", name, flags[name]))
+	}
+	return strings.Join(parts, " ")
+}
+
+// formatHistoryResults renders result counts as a sorted name=count list
+func formatHistoryResults(results map[string]int) string {
+	names := make([]string, 0, len(results))
+	for name := range results {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s=%s", name, humanize.Count(results[name])))
+	}
+	return strings.Join(parts, " ")
+}
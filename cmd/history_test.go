@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSkipHistory(t *testing.T) {
+	root := &cobra.Command{Use: "claudecat"}
+	commands := make(map[string]*cobra.Command)
+	for _, name := range []string{"analyze", "history", "version", "help", "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd} {
+		commands[name] = &cobra.Command{Use: name}
+		root.AddCommand(commands[name])
+	}
+	bash := &cobra.Command{Use: "bash"}
+	commands["completion"].AddCommand(bash)
+	helped := &cobra.Command{Use: "report"}
+	root.AddCommand(helped)
+	helped.InitDefaultHelpFlag()
+	assert.NoError(t, helped.Flags().Set("help", "true"))
+
+	tests := []struct {
+		cmd  *cobra.Command
+		skip bool
+	}{
+		{commands["analyze"], false},
+		{root, false},
+		{commands["history"], true},
+		{commands["version"], true},
+		{commands["help"], true},
+		{bash, true},
+		{commands[cobra.ShellCompRequestCmd], true},
+		{commands[cobra.ShellCompNoDescRequestCmd], true},
+		{helped, true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.skip, skipHistory(tt.cmd), tt.cmd.CommandPath())
+	}
+}
+
+func TestHistoryFlags(t *testing.T) {
+	var webhook, exec, events string
+	cmd := &cobra.Command{Use: "watch"}
+	cmd.Flags().StringVar(&webhook, "webhook", "", "")
+	cmd.Flags().StringVar(&exec, "exec", "", "")
+	cmd.Flags().StringVar(&events, "events", "", "")
+	markHistorySecret(cmd.Flags(), "exec", "webhook")
+
+	assert.Nil(t, historyFlags(cmd))
+
+	assert.NoError(t, cmd.Flags().Set("webhook", "https://hooks.example.com/services/T000/B000/XXXX"))
+	assert.NoError(t, cmd.Flags().Set("exec", "curl -H 'Authorization: Bearer abc' https://example.com"))
+	assert.NoError(t, cmd.Flags().Set("events", "limit"))
+	assert.Equal(t, map[string]string{"webhook": "********", "exec": "********", "events": "limit"}, historyFlags(cmd))
+}
+
+func TestHistoryArgs(t *testing.T) {
+	defer configSetCmd.Flags().Parse(nil)
+
+	require.NoError(t, configSetCmd.Flags().Parse([]string{"api.token", "abc"}))
+	assert.Equal(t, []string{"api.token", "********"}, historyArgs(configSetCmd))
+	assert.Equal(t, []string{"api.token", "abc"}, configSetCmd.Flags().Args(), "the command still sees the value")
+
+	require.NoError(t, configSetCmd.Flags().Parse([]string{"ui.theme", "light"}))
+	assert.Equal(t, []string{"ui.theme", "light"}, historyArgs(configSetCmd))
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"github.com/penwyp/claudecat/fileio"
+	"github.com/penwyp/claudecat/terminal"
+)
+
+// logLinks indexes the logs under paths when hyperlinks are on, and returns nil otherwise so that
+// plain output does not pay for the walk
+func logLinks(paths []string) *fileio.LogIndex {
+	if !terminal.Hyperlinks() {
+		return nil
+	}
+	return fileio.IndexLogs(paths)
+}
+
+// projectLink links a project name to the directory holding its logs
+func projectLink(index *fileio.LogIndex, project string) string {
+	return terminal.Link(terminal.FileURL(index.ProjectDir(project)), project)
+}
+
+// sessionLink links a session ID to the log file an entry was read from, or to the log of the
+// conversation of that ID, or else to the drill-down URL of ui.session_link
+func sessionLink(index *fileio.LogIndex, sessionID, sourceFile string) string {
+	if sourceFile == "" {
+		sourceFile = index.SessionFile(sessionID)
+	}
+	if sourceFile == "" {
+		return terminal.Link(terminal.SessionURL(sessionID), sessionID)
+	}
+	return terminal.Link(terminal.FileURL(sourceFile), sessionID)
+}
@@ -0,0 +1,301 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/humanize"
+	"github.com/penwyp/claudecat/importers"
+	"github.com/penwyp/claudecat/internal"
+	"github.com/spf13/cobra"
+)
+
+var (
+	importFormat   string
+	importName     string
+	importProject  string
+	importModelMap []string
+	importDryRun   bool
+	importOutput   string
+)
+
+// importSniffSize is how much of an export is read to detect its format
+const importSniffSize = 4096
+
+// importSummary describes an imported batch
+type importSummary struct {
+	Name       string         `json:"name"`
+	Format     string         `json:"format"`
+	Source     string         `json:"source"`
+	ImportedAt time.Time      `json:"imported_at,omitempty"`
+	Entries    int            `json:"entries"`
+	Tokens     int            `json:"tokens"`
+	CostUSD    float64        `json:"cost_usd"`
+	From       time.Time      `json:"from,omitempty"`
+	To         time.Time      `json:"to,omitempty"`
+	Unpriced   map[string]int `json:"unpriced,omitempty"` // Models imported at no cost, by record count
+	DryRun     bool           `json:"dry_run,omitempty"`
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import usage exported by other tools",
+	Long: fmt.Sprintf(`Import usage exported by ccusage or OpenAI so that spend across tools shows up in
+the same reports (analyze, projects, timeline, query, compare, histogram, forecast and export).
+Imported usage is stored in imports/ under cache.dir, one batch per name; importing again under
+the same name replaces the batch. It stays out of session, plan and monitor calculations.
+
+Supported formats (detected automatically unless --format is given):
+%s
+Rows keep the cost reported by the export. Rows without one are priced by mapping their model to a
+Claude model with --model-map: a trailing '*' matches a prefix, and the target is opus, sonnet, haiku
+or a priced model name. Claude models map to their family automatically; other models without a
+cost are imported at $0 and listed after the import.
+
+ccusage reads the same Claude Code logs as claudecat, so importing its reports of this machine's
+logs counts that usage twice; import ccusage reports from other machines instead.
+
+Examples:
+  claudecat import ccusage-daily.json                         # ccusage daily --json from another machine
+  claudecat import usage.csv --model-map 'gpt-4o*=sonnet'     # OpenAI CSV priced like Sonnet
+  claudecat import costs.json --name openai-june --dry-run    # Preview without saving
+  claudecat import list                                       # Show imported batches
+  claudecat import remove openai-june                         # Drop a batch`, describeImportFormats()),
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !strings.EqualFold(importOutput, "table") && !strings.EqualFold(importOutput, "json") {
+			return fmt.Errorf("invalid output format: %s (valid: table, json)", importOutput)
+		}
+		mapping, err := importers.ParseModelMap(importModelMap)
+		if err != nil {
+			return err
+		}
+		cfg, err := loadCacheCommandConfig(cmd, nil)
+		if err != nil {
+			return err
+		}
+
+		path := config.ExpandHome(args[0])
+		adapter, err := importAdapter(path)
+		if err != nil {
+			return err
+		}
+		name := importName
+		if name == "" {
+			name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		}
+		if err := internal.ValidateImportName(name); err != nil {
+			return fmt.Errorf("%w; pass --name", err)
+		}
+		project := importProject
+		if project == "" {
+			project = adapter.Name()
+		}
+
+		loc, err := time.LoadLocation(cfg.App.Timezone)
+		if err != nil {
+			loc = time.Local
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open export: %w", err)
+		}
+		defer file.Close()
+		records, err := adapter.Parse(file, importers.Options{Location: loc})
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if len(records) == 0 {
+			return fmt.Errorf("no usage found in %s", path)
+		}
+
+		conversion := importers.Convert(records, mapping, project)
+		batch := internal.ImportBatch{
+			Name:       name,
+			Format:     adapter.Name(),
+			Source:     path,
+			ImportedAt: time.Now(),
+			Entries:    conversion.Entries,
+		}
+		if !importDryRun {
+			store, err := internal.OpenImportStore(cfg)
+			if err != nil {
+				return err
+			}
+			if err := store.Save(batch); err != nil {
+				return err
+			}
+		}
+		recordCommandResult("entries", len(batch.Entries))
+
+		summary := summarizeImport(batch)
+		summary.Unpriced = conversion.Unpriced
+		summary.DryRun = importDryRun
+		if strings.EqualFold(importOutput, "json") {
+			return printImportJSON(summary)
+		}
+		printImportSummary(summary, conversion.UnpricedModels())
+		return nil
+	},
+}
+
+var importListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List imported batches",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !strings.EqualFold(importOutput, "table") && !strings.EqualFold(importOutput, "json") {
+			return fmt.Errorf("invalid output format: %s (valid: table, json)", importOutput)
+		}
+		store, err := openImportStore(cmd)
+		if err != nil {
+			return err
+		}
+		batches, err := store.Load()
+		if err != nil {
+			return err
+		}
+		summaries := make([]importSummary, 0, len(batches))
+		for _, batch := range batches {
+			summaries = append(summaries, summarizeImport(batch))
+		}
+		recordCommandResult("batches", len(summaries))
+
+		if strings.EqualFold(importOutput, "json") {
+			return printImportJSON(summaries)
+		}
+		if len(summaries) == 0 {
+			fmt.Println("No imported usage")
+			return nil
+		}
+		table := newTableFormatter([]string{"Name", "Format", "Entries", "From", "To", "Tokens", "Cost", "Imported"})
+		for _, summary := range summaries {
+			table.addRow([]string{
+				summary.Name,
+				summary.Format,
+				humanize.Count(summary.Entries),
+				summary.From.Format("2006-01-02"),
+				summary.To.Format("2006-01-02"),
+				humanize.Count(summary.Tokens),
+				humanize.Cost(summary.CostUSD),
+				summary.ImportedAt.Local().Format("2006-01-02 15:04"),
+			})
+		}
+		fmt.Println(table.render())
+		return nil
+	},
+}
+
+var importRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove an imported batch",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openImportStore(cmd)
+		if err != nil {
+			return err
+		}
+		if err := store.Remove(args[0]); err != nil {
+			return err
+		}
+		fmt.Printf("Removed imported usage %s\n", args[0])
+		return nil
+	},
+}
+
+func init() {
+	importCmd.Flags().StringVar(&importFormat, "format", "auto", "export format: auto, "+strings.Join(importers.Names(), ", "))
+	importCmd.Flags().StringVar(&importName, "name", "", "batch name (default: the file name without extension)")
+	importCmd.Flags().StringVar(&importProject, "project", "", "project of rows without one (default: the format name)")
+	importCmd.Flags().StringArrayVar(&importModelMap, "model-map", nil, "price a model like a Claude model, e.g. 'gpt-4o*=sonnet' (repeatable)")
+	importCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "show what would be imported without saving")
+	importCmd.PersistentFlags().StringVarP(&importOutput, "output", "o", "table", "output format (table, json)")
+	importCmd.AddCommand(importListCmd, importRemoveCmd)
+	rootCmd.AddCommand(importCmd)
+}
+
+// describeImportFormats lists the registered import formats for the help text
+func describeImportFormats() string {
+	var b strings.Builder
+	for _, adapter := range importers.Adapters() {
+		fmt.Fprintf(&b, "  %-12s %s\n", adapter.Name(), adapter.Description())
+	}
+	return b.String()
+}
+
+// importAdapter returns the adapter selected with --format, or the one detecting the export at path
+func importAdapter(path string) (importers.Adapter, error) {
+	if !strings.EqualFold(importFormat, "auto") {
+		return importers.Lookup(importFormat)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open export: %w", err)
+	}
+	defer file.Close()
+	sample, _ := bufio.NewReaderSize(file, importSniffSize).Peek(importSniffSize)
+	return importers.Detect(sample)
+}
+
+// openImportStore opens the import store configured for cmd
+func openImportStore(cmd *cobra.Command) (*internal.ImportStore, error) {
+	cfg, err := loadCacheCommandConfig(cmd, nil)
+	if err != nil {
+		return nil, err
+	}
+	return internal.OpenImportStore(cfg)
+}
+
+// summarizeImport totals a batch
+func summarizeImport(batch internal.ImportBatch) importSummary {
+	summary := importSummary{
+		Name:       batch.Name,
+		Format:     batch.Format,
+		Source:     batch.Source,
+		ImportedAt: batch.ImportedAt,
+		Entries:    len(batch.Entries),
+	}
+	for _, entry := range batch.Entries {
+		summary.Tokens += entry.TotalTokens
+		summary.CostUSD += entry.CostUSD
+		if summary.From.IsZero() || entry.Timestamp.Before(summary.From) {
+			summary.From = entry.Timestamp
+		}
+		if entry.Timestamp.After(summary.To) {
+			summary.To = entry.Timestamp
+		}
+	}
+	return summary
+}
+
+// printImportSummary prints the totals of an import, followed by the models imported at no cost
+func printImportSummary(summary importSummary, unpriced []string) {
+	verb := "Imported"
+	if summary.DryRun {
+		verb = "Would import"
+	}
+	fmt.Printf("%s %s entries from %s (%s) as %q, %s to %s\n", verb, humanize.Count(summary.Entries),
+		summary.Source, summary.Format, summary.Name, summary.From.Format("2006-01-02"), summary.To.Format("2006-01-02"))
+	fmt.Printf("Total: %s tokens, %s\n", humanize.Count(summary.Tokens), humanize.Cost(summary.CostUSD))
+
+	for _, model := range unpriced {
+		fmt.Printf("Warning: %s has no cost or pricing; %d entries imported at $0 (map it with --model-map '%s=sonnet')\n",
+			model, summary.Unpriced[model], model)
+	}
+}
+
+// printImportJSON writes v as indented JSON
+func printImportJSON(v any) error {
+	data, err := sonic.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(append(data, '\n'))
+	return err
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/penwyp/claudecat/humanize"
+	"github.com/penwyp/claudecat/internal"
+	"github.com/penwyp/claudecat/sessions"
+	"github.com/penwyp/claudecat/terminal"
+	"github.com/spf13/cobra"
+)
+
+var (
+	limitsOutput string
+	limitsFrom   string
+	limitsTo     string
+	limitsRecent bool
+)
+
+// limitsRecentDays is how far back --recent lists limit hits
+const limitsRecentDays = 7
+
+// limitsMessageWidth is the width the limit messages are truncated to in the table
+const limitsMessageWidth = 60
+
+var limitsCmd = &cobra.Command{
+	Use:   "limits [path...]",
+	Short: "List when usage and rate limits were hit",
+	Long: `List the limit messages Claude logged: usage limits of the plan, Opus limits and rate limits
+returned to tools, with the 5-hour session block each was hit in and the time it cost.
+
+The time lost to a hit runs until usage resumed in the same block, or until the next hit, or until
+the block ended when usage did not resume before it.
+
+Examples:
+  claudecat limits                         # Every limit hit so far
+  claudecat limits --recent                # The last 7 days
+  claudecat limits --from 2025-06-01       # Since a date
+  claudecat limits -o json                 # Machine-readable`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output := strings.ToLower(limitsOutput)
+		if output != "table" && output != "json" {
+			return fmt.Errorf("invalid output format: %s (valid: table, json)", limitsOutput)
+		}
+		cfg, err := loadCacheCommandConfig(cmd, args)
+		if err != nil {
+			return err
+		}
+		loc, err := time.LoadLocation(cfg.App.Timezone)
+		if err != nil {
+			loc = time.Local
+		}
+
+		now := time.Now()
+		var filter sessions.ListFilter
+		if limitsFrom != "" {
+			if filter.From, err = parseSessionsBound(limitsFrom, loc, false); err != nil {
+				return fmt.Errorf("invalid from date %s: %w", limitsFrom, err)
+			}
+		} else if limitsRecent {
+			filter.From = now.AddDate(0, 0, -limitsRecentDays)
+		}
+		if limitsTo != "" {
+			if filter.To, err = parseSessionsBound(limitsTo, loc, true); err != nil {
+				return fmt.Errorf("invalid to date %s: %w", limitsTo, err)
+			}
+		}
+		if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+			return fmt.Errorf("--to must not be before --from")
+		}
+
+		analyzer, err := internal.NewAnalyzer(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create analyzer: %w", err)
+		}
+		report, err := analyzer.Limits(cfg.Data.Paths, filter, now)
+		if err != nil {
+			return fmt.Errorf("limit report failed: %w", err)
+		}
+		recordCommandResult("limits", len(report.Events))
+
+		if output == "json" {
+			data, err := sonic.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return err
+			}
+			_, err = os.Stdout.Write(append(data, '\n'))
+			return err
+		}
+
+		if len(report.Events) == 0 {
+			fmt.Println("No limit hits found.")
+			return nil
+		}
+		printLimits(report, loc)
+		return nil
+	},
+}
+
+func init() {
+	limitsCmd.Flags().StringVarP(&limitsOutput, "output", "o", "table", "output format (table, json)")
+	limitsCmd.Flags().StringVar(&limitsFrom, "from", "", "list hits after this date (YYYY-MM-DD or YYYY-MM-DD HH:MM:SS)")
+	limitsCmd.Flags().StringVar(&limitsTo, "to", "", "list hits before this date (YYYY-MM-DD or YYYY-MM-DD HH:MM:SS)")
+	limitsCmd.Flags().BoolVar(&limitsRecent, "recent", false, fmt.Sprintf("only list hits of the last %d days", limitsRecentDays))
+	rootCmd.AddCommand(limitsCmd)
+}
+
+// printLimits prints one row per limit hit and the totals by type
+func printLimits(report sessions.LimitReport, loc *time.Location) {
+	table := newTableFormatter([]string{"Time", "Type", "Session Block", "Resumed", "Lost", "Message"})
+	for _, event := range report.Events {
+		resumed := "-"
+		if event.ResumedAt != nil {
+			resumed = event.ResumedAt.In(loc).Format("15:04")
+		}
+		message := strings.Join(strings.Fields(event.Message), " ")
+		if len([]rune(message)) > limitsMessageWidth {
+			message = string([]rune(message)[:limitsMessageWidth-1]) + "…"
+		}
+		table.addRow([]string{
+			event.Time.In(loc).Format("2006-01-02 15:04"),
+			strings.TrimSuffix(event.Type, "_limit"),
+			terminal.Link(terminal.SessionURL(event.BlockID), event.BlockStart.In(loc).Format("2006-01-02 15:04")+" to "+event.BlockEnd.In(loc).Format("15:04")),
+			resumed,
+			humanize.Duration(event.Lost),
+			message,
+		})
+	}
+	fmt.Println(table.render())
+
+	types := make([]string, 0, len(report.ByType))
+	for kind := range report.ByType {
+		types = append(types, kind)
+	}
+	sort.Strings(types)
+	counts := make([]string, 0, len(types))
+	for _, kind := range types {
+		counts = append(counts, fmt.Sprintf("%d %s", report.ByType[kind], strings.TrimSuffix(kind, "_limit")))
+	}
+	fmt.Printf("%d limit hit(s) in %d session block(s) (%s), %s lost\n", len(report.Events), report.Blocks,
+		strings.Join(counts, ", "), humanize.Duration(report.Lost))
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// writeOutput passes write the writer command output goes to: stdout without a path, else a
+// temporary file next to path that replaces it once write succeeds, so a failed run never leaves a
+// truncated file behind. With appendTo the new output follows the content path already had, and
+// write is told whether there was any so it can leave out headers.
+func writeOutput(path string, appendTo bool, write func(w io.Writer, appended bool) error) error {
+	if path == "" {
+		return write(os.Stdout, false)
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	tmpFile, err := os.CreateTemp(dir, "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+	defer os.Remove(tmpPath)
+	defer tmpFile.Close()
+
+	appended := false
+	if appendTo {
+		previous, err := os.Open(path)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if err == nil {
+			n, err := io.Copy(tmpFile, previous)
+			previous.Close()
+			if err != nil {
+				return fmt.Errorf("failed to copy %s: %w", path, err)
+			}
+			appended = n > 0
+		}
+	}
+
+	buffered := bufio.NewWriter(tmpFile)
+	if err := write(buffered, appended); err != nil {
+		return err
+	}
+	if err := buffered.Flush(); err != nil {
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := os.Chmod(tmpPath, mode); err != nil {
+		return fmt.Errorf("failed to set file mode: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/penwyp/claudecat/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dirNames lists the names in dir, to catch temporary files left behind
+func dirNames(t *testing.T, dir string) []string {
+	t.Helper()
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		names = append(names, file.Name())
+	}
+	return names
+}
+
+func TestWriteOutput_FailedWriteKeepsFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "usage.csv")
+	require.NoError(t, os.WriteFile(path, []byte("old\n"), 0600))
+
+	err := writeOutput(path, false, func(w io.Writer, appended bool) error {
+		fmt.Fprintln(w, "partial")
+		return errors.New("query failed")
+	})
+	assert.EqualError(t, err, "query failed")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "old\n", string(data))
+	assert.Equal(t, []string{"usage.csv"}, dirNames(t, dir), "the temporary file is removed")
+}
+
+func TestWriteOutput_ReplacesFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "usage.csv")
+	require.NoError(t, os.WriteFile(path, []byte("old\n"), 0600))
+
+	require.NoError(t, writeOutput(path, false, func(w io.Writer, appended bool) error {
+		assert.False(t, appended)
+		_, err := fmt.Fprintln(w, "new")
+		return err
+	}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "new\n", string(data))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "the mode of the replaced file is kept")
+	assert.Equal(t, []string{"usage.csv"}, dirNames(t, dir))
+}
+
+func TestWriteOutput_Append(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "exports", "usage.csv")
+
+	write := func(row string) func(w io.Writer, appended bool) error {
+		return func(w io.Writer, appended bool) error {
+			if !appended {
+				fmt.Fprintln(w, "Group,Cost USD")
+			}
+			_, err := fmt.Fprintln(w, row)
+			return err
+		}
+	}
+	require.NoError(t, writeOutput(path, true, write("2025-06-01,1.0000")))
+	require.NoError(t, writeOutput(path, true, write("2025-06-02,2.0000")))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "Group,Cost USD\n2025-06-01,1.0000\n2025-06-02,2.0000\n", string(data), "the header is written once")
+	assert.Equal(t, []string{"usage.csv"}, dirNames(t, filepath.Dir(path)))
+}
+
+func TestApplyAnalyzeFlags_Append(t *testing.T) {
+	defer func(output, file string, appendTo bool) {
+		analyzeOutput, analyzeOutputFile, analyzeAppend = output, file, appendTo
+	}(analyzeOutput, analyzeOutputFile, analyzeAppend)
+
+	tests := []struct {
+		output  string
+		file    string
+		wantErr bool
+	}{
+		{"csv", "usage.csv", false},
+		{"json", "usage.json", true},
+		{"table", "usage.txt", true},
+		{"csv", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.output+" "+tt.file, func(t *testing.T) {
+			analyzeOutput, analyzeOutputFile, analyzeAppend = tt.output, tt.file, true
+			err := applyAnalyzeFlags(config.DefaultConfig(), []string{t.TempDir()})
+			if tt.wantErr {
+				assert.EqualError(t, err, "--append requires --output-file and --output csv")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
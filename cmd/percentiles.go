@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/penwyp/claudecat/calculations"
+	"github.com/penwyp/claudecat/humanize"
+	"github.com/penwyp/claudecat/internal"
+	"github.com/penwyp/claudecat/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	percentilesOutput string
+	percentilesDays   int
+)
+
+// percentilesReport is the JSON representation of the session percentiles
+type percentilesReport struct {
+	Days         int                             `json:"days"`
+	Sessions     calculations.SessionPercentiles `json:"sessions"`
+	CustomLimits models.PlanLimits               `json:"custom_limits"`
+}
+
+var percentilesCmd = &cobra.Command{
+	Use:   "percentiles [path...]",
+	Short: "Show per-session token, cost and message percentiles",
+	Long: `Show the P50, P75, P90 and P99 of tokens, cost and messages per completed 5-hour session,
+along with the limits the monitor estimates for the custom plan from this history.
+
+Examples:
+  claudecat percentiles              # Sessions of the last 30 days
+  claudecat percentiles --days 90    # A longer history
+  claudecat percentiles -o json      # Machine-readable output`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !strings.EqualFold(percentilesOutput, "table") && !strings.EqualFold(percentilesOutput, "json") {
+			return fmt.Errorf("invalid output format: %s (valid: table, json)", percentilesOutput)
+		}
+		if percentilesDays <= 0 {
+			return fmt.Errorf("invalid days: %d (must be positive)", percentilesDays)
+		}
+
+		cfg, err := loadCacheCommandConfig(cmd, args)
+		if err != nil {
+			return err
+		}
+		analyzer, err := internal.NewAnalyzer(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create analyzer: %w", err)
+		}
+		stats, limits, err := analyzer.SessionPercentiles(cfg.Data.Paths, percentilesDays)
+		if err != nil {
+			return fmt.Errorf("percentile calculation failed: %w", err)
+		}
+		recordCommandResult("sessions", stats.Sessions)
+
+		if strings.EqualFold(percentilesOutput, "json") {
+			data, err := sonic.MarshalIndent(percentilesReport{Days: percentilesDays, Sessions: stats, CustomLimits: limits}, "", "  ")
+			if err != nil {
+				return err
+			}
+			_, err = os.Stdout.Write(append(data, '\n'))
+			return err
+		}
+
+		if stats.Sessions == 0 {
+			fmt.Println("No completed sessions to display.")
+			return nil
+		}
+		printSessionPercentiles(stats, limits)
+		return nil
+	},
+}
+
+func init() {
+	percentilesCmd.Flags().StringVarP(&percentilesOutput, "output", "o", "table", "output format (table, json)")
+	percentilesCmd.Flags().IntVar(&percentilesDays, "days", 30, "number of days of history to include")
+	rootCmd.AddCommand(percentilesCmd)
+}
+
+// printSessionPercentiles prints the percentile table and the estimated custom plan limits
+func printSessionPercentiles(stats calculations.SessionPercentiles, limits models.PlanLimits) {
+	fmt.Printf("Session percentiles (last %d days, %d completed sessions, %d with limit messages)\n",
+		percentilesDays, stats.Sessions, stats.LimitSessions)
+
+	table := newTableFormatter([]string{"Metric", "P50", "P75", "P90", "P99"})
+	tokens := func(v float64) string { return humanize.Count(int(v)) }
+	rows := []struct {
+		name   string
+		values calculations.Percentiles
+		format func(float64) string
+	}{
+		{"Tokens", stats.Tokens, tokens},
+		{"Cost (USD)", stats.Cost, humanize.Cost},
+		{"Messages", stats.Messages, tokens},
+	}
+	for _, row := range rows {
+		table.addRow([]string{
+			row.name,
+			row.format(row.values.P50),
+			row.format(row.values.P75),
+			row.format(row.values.P90),
+			row.format(row.values.P99),
+		})
+	}
+	fmt.Println(table.render())
+
+	fmt.Printf("\nEstimated custom plan limits: %s tokens, %s, %s messages\n",
+		humanize.Count(limits.TokenLimit), humanize.Cost(limits.CostLimit), humanize.Count(limits.MessageLimit))
+}
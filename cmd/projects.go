@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/penwyp/claudecat/calculations"
+	"github.com/penwyp/claudecat/fileio"
+	"github.com/penwyp/claudecat/humanize"
+	"github.com/penwyp/claudecat/internal"
+	"github.com/spf13/cobra"
+)
+
+var (
+	projectsOutput string
+	projectsSortBy string
+	projectsLimit  int
+)
+
+var projectsCmd = &cobra.Command{
+	Use:   "projects [path...]",
+	Short: "Show cost, tokens and sessions per project",
+	Long: `Show the usage of each project, named after its directory under ~/.claude/projects: entries,
+5-hour sessions with activity in the project, tokens, cost and share of the total cost.
+
+Examples:
+  claudecat projects                       # Most expensive projects first
+  claudecat projects --sort-by last        # Most recently used first
+  claudecat projects --limit 5 -o json     # Top five as JSON`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !strings.EqualFold(projectsOutput, "table") && !strings.EqualFold(projectsOutput, "json") {
+			return fmt.Errorf("invalid output format: %s (valid: table, json)", projectsOutput)
+		}
+		if projectsLimit < 0 {
+			return fmt.Errorf("invalid limit: %d (must not be negative)", projectsLimit)
+		}
+
+		cfg, err := loadCacheCommandConfig(cmd, args)
+		if err != nil {
+			return err
+		}
+		analyzer, err := internal.NewAnalyzer(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create analyzer: %w", err)
+		}
+		results, err := analyzer.Analyze(cfg.Data.Paths)
+		if err != nil {
+			return fmt.Errorf("analysis failed: %w", err)
+		}
+
+		projects := calculations.BuildProjectBreakdown(results)
+		if err := calculations.SortProjects(projects, projectsSortBy); err != nil {
+			return err
+		}
+		total := len(projects)
+		if projectsLimit > 0 && projectsLimit < total {
+			projects = projects[:projectsLimit]
+		}
+		recordCommandResult("projects", len(projects))
+
+		if strings.EqualFold(projectsOutput, "json") {
+			data, err := sonic.MarshalIndent(projects, "", "  ")
+			if err != nil {
+				return err
+			}
+			_, err = os.Stdout.Write(append(data, '\n'))
+			return err
+		}
+
+		loc, err := time.LoadLocation(cfg.App.Timezone)
+		if err != nil {
+			loc = time.Local
+		}
+		printProjectBreakdown(projects, total, loc, logLinks(cfg.Data.Paths))
+		return nil
+	},
+}
+
+func init() {
+	projectsCmd.Flags().StringVarP(&projectsOutput, "output", "o", "table", "output format (table, json)")
+	projectsCmd.Flags().StringVar(&projectsSortBy, "sort-by", "cost", "sort by field ("+strings.Join(calculations.ProjectSortKeys, ", ")+")")
+	projectsCmd.Flags().IntVar(&projectsLimit, "limit", 0, "limit number of projects (0 = no limit)")
+	rootCmd.AddCommand(projectsCmd)
+}
+
+// printProjectBreakdown prints one row per project, linked to its logs when found in links, and
+// notes the projects left out by --limit
+func printProjectBreakdown(projects []calculations.ProjectUsage, total int, loc *time.Location, links *fileio.LogIndex) {
+	table := newTableFormatter([]string{"Project", "Sessions", "Entries", "Total Tokens", "Cost (USD)", "Share", "Last Used"})
+	for _, project := range projects {
+		table.addRow([]string{
+			projectLink(links, project.Project),
+			humanize.Count(project.Sessions),
+			humanize.Count(project.Entries),
+			humanize.Count(project.TotalTokens),
+			humanize.Cost(project.CostUSD),
+			fmt.Sprintf("%.1f%%", project.CostShare*100),
+			project.LastUsage.In(loc).Format("2006-01-02 15:04"),
+		})
+	}
+	fmt.Println(table.render())
+	if hidden := total - len(projects); hidden > 0 {
+		fmt.Printf("%d more project(s) not shown; raise --limit to include them\n", hidden)
+	}
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/penwyp/claudecat/internal"
+	"github.com/spf13/cobra"
+)
+
+var queryOutput string
+
+// queryResult is the column names and formatted rows returned by a SQL query
+type queryResult struct {
+	Columns []string   `json:"columns"`
+	Rows    [][]string `json:"rows"`
+}
+
+var queryCmd = &cobra.Command{
+	Use:   "query <sql> [path...]",
+	Short: "Run SQL over usage entries (experimental)",
+	Long: `Load every usage entry into an in-memory DuckDB table named "usage" and run an arbitrary
+SQL query against it. Requires a binary built with -tags duckdb.
+
+Columns of the usage table:
+  timestamp (TIMESTAMPTZ), day (DATE, in the configured timezone), model, project, session_id,
+  input_tokens, output_tokens, cache_creation_tokens, cache_read_tokens, total_tokens (BIGINT),
+  cost (DOUBLE)
+
+Examples:
+  claudecat query "SELECT model, sum(cost) FROM usage WHERE day > '2025-01-01' GROUP BY 1"
+  claudecat query "SELECT project, count(*) AS entries FROM usage GROUP BY 1 ORDER BY 2 DESC" -o csv`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format := strings.ToLower(queryOutput)
+		if format != "table" && format != "csv" && format != "json" {
+			return fmt.Errorf("invalid output format: %s (valid: table, csv, json)", queryOutput)
+		}
+		if !queryEngineAvailable {
+			return fmt.Errorf("query requires a binary built with DuckDB support (go build -tags duckdb)")
+		}
+
+		cfg, err := loadCacheCommandConfig(cmd, args[1:])
+		if err != nil {
+			return err
+		}
+		analyzer, err := internal.NewAnalyzer(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create analyzer: %w", err)
+		}
+		results, err := analyzer.Analyze(cfg.Data.Paths)
+		if err != nil {
+			return fmt.Errorf("analysis failed: %w", err)
+		}
+
+		loc, err := time.LoadLocation(cfg.App.Timezone)
+		if err != nil {
+			loc = time.Local
+		}
+		result, err := runUsageQuery(results, args[0], loc)
+		if err != nil {
+			return err
+		}
+		recordCommandResult("rows", len(result.Rows))
+
+		switch format {
+		case "json":
+			data, err := sonic.MarshalIndent(result, "", "  ")
+			if err != nil {
+				return err
+			}
+			_, err = os.Stdout.Write(append(data, '\n'))
+			return err
+		case "csv":
+			writer := csv.NewWriter(os.Stdout)
+			if err := writer.Write(result.Columns); err != nil {
+				return err
+			}
+			if err := writer.WriteAll(result.Rows); err != nil {
+				return err
+			}
+			return writer.Error()
+		}
+
+		table := newTableFormatter(result.Columns)
+		for _, row := range result.Rows {
+			table.addRow(row)
+		}
+		fmt.Println(table.render())
+		fmt.Printf("%d row(s)\n", len(result.Rows))
+		return nil
+	},
+}
+
+func init() {
+	queryCmd.Flags().StringVarP(&queryOutput, "output", "o", "table", "output format (table, csv, json)")
+	rootCmd.AddCommand(queryCmd)
+}
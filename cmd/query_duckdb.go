@@ -0,0 +1,131 @@
+//go:build duckdb
+
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/marcboeker/go-duckdb"
+	"github.com/penwyp/claudecat/models"
+)
+
+// queryEngineAvailable reports whether this binary can run SQL queries
+const queryEngineAvailable = true
+
+// usageTableSchema is the DuckDB table entries are loaded into
+const usageTableSchema = `CREATE TABLE usage (
+	timestamp TIMESTAMPTZ,
+	day DATE,
+	model VARCHAR,
+	project VARCHAR,
+	session_id VARCHAR,
+	input_tokens BIGINT,
+	output_tokens BIGINT,
+	cache_creation_tokens BIGINT,
+	cache_read_tokens BIGINT,
+	total_tokens BIGINT,
+	cost DOUBLE
+)`
+
+// runUsageQuery loads results into an in-memory DuckDB usage table and runs query against it
+func runUsageQuery(results []models.AnalysisResult, query string, loc *time.Location) (queryResult, error) {
+	ctx := context.Background()
+	connector, err := duckdb.NewConnector("", nil)
+	if err != nil {
+		return queryResult{}, fmt.Errorf("failed to open DuckDB: %w", err)
+	}
+	defer connector.Close()
+
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	if _, err := db.ExecContext(ctx, usageTableSchema); err != nil {
+		return queryResult{}, fmt.Errorf("failed to create usage table: %w", err)
+	}
+	if err := appendUsageRows(ctx, connector, results, loc); err != nil {
+		return queryResult{}, err
+	}
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return queryResult{}, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return queryResult{}, err
+	}
+	columns := make([]string, len(types))
+	for i, columnType := range types {
+		columns[i] = columnType.Name()
+	}
+	result := queryResult{Columns: columns, Rows: [][]string{}}
+	values := make([]any, len(columns))
+	pointers := make([]any, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			return queryResult{}, fmt.Errorf("failed to read row: %w", err)
+		}
+		row := make([]string, len(values))
+		for i, value := range values {
+			row[i] = formatQueryValue(value, types[i].DatabaseTypeName(), loc)
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	return result, rows.Err()
+}
+
+// appendUsageRows bulk-loads results into the usage table through a DuckDB appender
+func appendUsageRows(ctx context.Context, connector *duckdb.Connector, results []models.AnalysisResult, loc *time.Location) error {
+	conn, err := connector.Connect(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to DuckDB: %w", err)
+	}
+	defer conn.Close()
+
+	appender, err := duckdb.NewAppenderFromConn(conn, "", "usage")
+	if err != nil {
+		return fmt.Errorf("failed to create appender: %w", err)
+	}
+	for _, result := range results {
+		local := result.Timestamp.In(loc)
+		day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+		err := appender.AppendRow(
+			result.Timestamp.UTC(), day, result.Model, result.Project, result.SessionID,
+			int64(result.InputTokens), int64(result.OutputTokens), int64(result.CacheCreationTokens),
+			int64(result.CacheReadTokens), int64(result.TotalTokens), result.CostUSD,
+		)
+		if err != nil {
+			appender.Close()
+			return fmt.Errorf("failed to load usage entry: %w", err)
+		}
+	}
+	return appender.Close()
+}
+
+// formatQueryValue renders a scanned column value of the given DuckDB type for display
+func formatQueryValue(value driver.Value, dbType string, loc *time.Location) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		if dbType == "DATE" {
+			return v.Format("2006-01-02")
+		}
+		return v.In(loc).Format("2006-01-02 15:04:05")
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []byte:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
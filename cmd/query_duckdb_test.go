@@ -0,0 +1,69 @@
+//go:build duckdb
+
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatQueryValue(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	at := time.Date(2025, 6, 1, 22, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		value  any
+		dbType string
+		want   string
+	}{
+		{"null", nil, "VARCHAR", ""},
+		{"date", time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), "DATE", "2025-06-01"},
+		{"timestamp in the configured timezone", at, "TIMESTAMPTZ", "2025-06-02 00:30:00"},
+		{"double", 0.125, "DOUBLE", "0.125"},
+		{"blob", []byte("abc"), "BLOB", "abc"},
+		{"bigint", int64(42), "BIGINT", "42"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, formatQueryValue(tt.value, tt.dbType, loc))
+		})
+	}
+}
+
+func TestRunUsageQuery(t *testing.T) {
+	at := time.Date(2025, 6, 1, 22, 30, 0, 0, time.UTC)
+	results := []models.AnalysisResult{
+		{Timestamp: at, Model: "claude-sonnet-4", Project: "webapp", InputTokens: 100, TotalTokens: 150, CostUSD: 1.5},
+		{Timestamp: at.Add(2 * time.Hour), Model: "claude-sonnet-4", Project: "webapp", InputTokens: 50, TotalTokens: 50, CostUSD: 0.5},
+		{Timestamp: at, Model: "claude-opus-4", Project: "infra", TotalTokens: 10, CostUSD: 2},
+	}
+
+	tests := []struct {
+		name    string
+		query   string
+		columns []string
+		rows    [][]string
+	}{
+		{"group by model", "SELECT model, sum(cost) AS cost FROM usage GROUP BY 1 ORDER BY 1",
+			[]string{"model", "cost"}, [][]string{{"claude-opus-4", "2"}, {"claude-sonnet-4", "2"}}},
+		{"days in the configured timezone", "SELECT day, count(*) AS entries FROM usage GROUP BY 1 ORDER BY 1",
+			[]string{"day", "entries"}, [][]string{{"2025-06-01", "2"}, {"2025-06-02", "1"}}},
+		{"no rows", "SELECT project FROM usage WHERE project = 'docs'", []string{"project"}, [][]string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := runUsageQuery(results, tt.query, time.FixedZone("UTC+1", 60*60))
+			require.NoError(t, err)
+			assert.Equal(t, tt.columns, result.Columns)
+			assert.Equal(t, tt.rows, result.Rows)
+		})
+	}
+
+	_, err := runUsageQuery(results, "SELECT nope FROM usage", time.UTC)
+	assert.ErrorContains(t, err, "query failed")
+}
@@ -0,0 +1,18 @@
+//go:build !duckdb
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+)
+
+// queryEngineAvailable reports whether this binary can run SQL queries
+const queryEngineAvailable = false
+
+// runUsageQuery is unavailable without DuckDB, which is only linked into builds with the duckdb tag
+func runUsageQuery(results []models.AnalysisResult, query string, loc *time.Location) (queryResult, error) {
+	return queryResult{}, fmt.Errorf("query engine not available")
+}
@@ -0,0 +1,28 @@
+//go:build !duckdb
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryCmd_WithoutDuckDB(t *testing.T) {
+	defer func(output string) { queryOutput = output }(queryOutput)
+
+	tests := []struct {
+		output  string
+		wantErr string
+	}{
+		{"table", "query requires a binary built with DuckDB support (go build -tags duckdb)"},
+		{"CSV", "query requires a binary built with DuckDB support (go build -tags duckdb)"},
+		{"xml", "invalid output format: xml (valid: table, csv, json)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			queryOutput = tt.output
+			assert.EqualError(t, queryCmd.RunE(queryCmd, []string{"SELECT 1"}), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/penwyp/claudecat/calculations"
+	"github.com/penwyp/claudecat/humanize"
+	"github.com/penwyp/claudecat/internal"
+	"github.com/spf13/cobra"
+)
+
+var (
+	recommendPlanOutput  string
+	recommendPlanCurrent string
+)
+
+var recommendPlanCmd = &cobra.Command{
+	Use:   "recommend-plan [path...]",
+	Short: "Recommend a subscription plan based on the last month of usage",
+	Long: `Compare the last 30 days of usage against each subscription plan's session limits
+and monthly price, and recommend an upgrade or downgrade with the projected monthly savings.
+
+A session counts as a limit collision for a plan when its API-equivalent cost exceeds the
+plan's session cost limit. Limit messages found in the logs also count against the current plan.
+
+Examples:
+  claudecat recommend-plan                 # Use the configured subscription plan
+  claudecat recommend-plan --plan max20    # Evaluate as if subscribed to Max 20
+  claudecat recommend-plan -o json         # Machine-readable output`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !strings.EqualFold(recommendPlanOutput, "table") && !strings.EqualFold(recommendPlanOutput, "json") {
+			return fmt.Errorf("invalid output format: %s (valid: table, json)", recommendPlanOutput)
+		}
+
+		cfg, err := loadCacheCommandConfig(cmd, args)
+		if err != nil {
+			return err
+		}
+		if recommendPlanCurrent != "" {
+			cfg.Subscription.Plan = recommendPlanCurrent
+		}
+
+		analyzer, err := internal.NewAnalyzer(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create analyzer: %w", err)
+		}
+		rec, err := analyzer.RecommendPlan(cfg.Data.Paths, time.Now())
+		if err != nil {
+			return fmt.Errorf("plan recommendation failed: %w", err)
+		}
+		recordCommandResult("sessions", rec.Sessions)
+
+		if strings.EqualFold(recommendPlanOutput, "json") {
+			data, err := sonic.MarshalIndent(rec, "", "  ")
+			if err != nil {
+				return err
+			}
+			_, err = os.Stdout.Write(append(data, '\n'))
+			return err
+		}
+
+		printPlanRecommendation(rec)
+		return nil
+	},
+}
+
+func init() {
+	recommendPlanCmd.Flags().StringVarP(&recommendPlanOutput, "output", "o", "table", "output format (table, json)")
+	recommendPlanCmd.Flags().StringVar(&recommendPlanCurrent, "plan", "", "current plan to evaluate against (defaults to the configured plan)")
+	rootCmd.AddCommand(recommendPlanCmd)
+}
+
+// printPlanRecommendation prints the per-plan comparison and the resulting recommendation
+func printPlanRecommendation(rec calculations.PlanRecommendation) {
+	fmt.Printf("Plan recommendation (%s to %s, %d sessions, %s API-equivalent spend)\n",
+		rec.WindowStart.Format("2006-01-02"), rec.WindowEnd.Format("2006-01-02"), rec.Sessions, humanize.Cost(rec.APICost))
+
+	table := newTableFormatter([]string{"Plan", "Price/Month", "Session Limit", "Collisions", "Fits"})
+	for _, fit := range rec.Plans {
+		name := fit.Plan
+		if fit.Plan == rec.CurrentPlan {
+			name += " (current)"
+		}
+		fits := "no"
+		if fit.Fits {
+			fits = "yes"
+		}
+		table.addRow([]string{
+			name,
+			humanize.Cost(fit.MonthlyPrice),
+			humanize.Cost(fit.SessionLimit),
+			humanize.Count(fit.Collisions),
+			fits,
+		})
+	}
+	fmt.Println(table.render())
+
+	switch rec.Action {
+	case calculations.PlanActionKeep:
+		fmt.Printf("Recommendation: keep %s (%s)\n", rec.CurrentPlan, rec.Reason)
+	case calculations.PlanActionDowngrade:
+		fmt.Printf("Recommendation: downgrade to %s, saving %s/month (%s)\n",
+			rec.RecommendedPlan, humanize.Cost(rec.ProjectedSavings), rec.Reason)
+	case calculations.PlanActionUpgrade:
+		fmt.Printf("Recommendation: upgrade to %s for %s/month more (%s)\n",
+			rec.RecommendedPlan, humanize.Cost(-rec.ProjectedSavings), rec.Reason)
+	default:
+		fmt.Printf("Recommendation: %s (%s)\n", rec.RecommendedPlan, rec.Reason)
+	}
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/penwyp/claudecat/calculations"
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/fileio"
+	"github.com/penwyp/claudecat/humanize"
+	"github.com/penwyp/claudecat/internal"
+	"github.com/penwyp/claudecat/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reconcileConsole []string
+	reconcileByModel bool
+	reconcileOutput  string
+)
+
+// reconcileBarWidth is the width of the bar showing the local share of the console cost
+const reconcileBarWidth = 10
+
+var reconcileCmd = &cobra.Command{
+	Use:   "reconcile --console usage.csv [path...]",
+	Short: "Compare the Anthropic console usage export with local logs",
+	Long: `Import one or more organization usage CSVs downloaded from the Anthropic console and compare
+them day by day with the usage computed from local Claude Code logs. Console numbers are labeled
+"console" and locally-computed numbers "local"; the delta shows spend the local logs do not explain,
+such as API usage outside Claude Code.
+
+Days are UTC, matching the console export, and only days present in the export are compared.
+When the export has no cost column, console cost is computed from the model pricing.
+
+Examples:
+  claudecat reconcile --console usage.csv              # Daily comparison
+  claudecat reconcile --console usage.csv --by-model   # Per day and model
+  claudecat reconcile --console jan.csv --console feb.csv -o json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !strings.EqualFold(reconcileOutput, "table") && !strings.EqualFold(reconcileOutput, "json") {
+			return fmt.Errorf("invalid output format: %s (valid: table, json)", reconcileOutput)
+		}
+		if len(reconcileConsole) == 0 {
+			return fmt.Errorf("--console is required")
+		}
+
+		var console []models.AnalysisResult
+		for _, path := range reconcileConsole {
+			results, err := fileio.LoadConsoleUsageCSV(config.ExpandHome(path))
+			if err != nil {
+				return err
+			}
+			console = append(console, results...)
+		}
+		if len(console) == 0 {
+			return fmt.Errorf("no usage rows found in %s", strings.Join(reconcileConsole, ", "))
+		}
+
+		cfg, err := loadCacheCommandConfig(cmd, args)
+		if err != nil {
+			return err
+		}
+		analyzer, err := internal.NewAnalyzer(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create analyzer: %w", err)
+		}
+		// Imported usage is not in the local logs the console export is compared with
+		analyzer.SetIncludeImports(false)
+		local, err := analyzer.Analyze(cfg.Data.Paths)
+		if err != nil {
+			return fmt.Errorf("analysis failed: %w", err)
+		}
+
+		reconciliation := calculations.Reconcile(console, local, reconcileByModel)
+		recordCommandResult("rows", len(reconciliation.Rows))
+
+		if strings.EqualFold(reconcileOutput, "json") {
+			data, err := sonic.MarshalIndent(reconciliation, "", "  ")
+			if err != nil {
+				return err
+			}
+			_, err = os.Stdout.Write(append(data, '\n'))
+			return err
+		}
+
+		printReconciliation(reconciliation)
+		return nil
+	},
+}
+
+func init() {
+	reconcileCmd.Flags().StringArrayVar(&reconcileConsole, "console", nil, "Anthropic console usage CSV to import (repeatable)")
+	reconcileCmd.Flags().BoolVar(&reconcileByModel, "by-model", false, "compare per day and model")
+	reconcileCmd.Flags().StringVarP(&reconcileOutput, "output", "o", "table", "output format (table, json)")
+	rootCmd.AddCommand(reconcileCmd)
+}
+
+// printReconciliation prints the comparison table followed by the totals
+func printReconciliation(r calculations.Reconciliation) {
+	headers := []string{"Date (UTC)"}
+	if reconcileByModel {
+		headers = append(headers, "Model")
+	}
+	headers = append(headers, "Console Tokens", "Local Tokens", "Console Cost", "Local Cost", "Delta", "Local Share")
+
+	table := newTableFormatter(headers)
+	for _, row := range r.Rows {
+		cells := []string{row.Date.Format("2006-01-02")}
+		if reconcileByModel {
+			cells = append(cells, row.Model)
+		}
+		cells = append(cells,
+			humanize.Count(row.Console.Tokens),
+			humanize.Count(row.Local.Tokens),
+			humanize.Cost(row.Console.Cost),
+			humanize.Cost(row.Local.Cost),
+			humanize.CostDelta(row.CostDelta),
+			renderReconcileShare(row),
+		)
+		table.addRow(cells)
+	}
+	fmt.Println(table.render())
+
+	fmt.Printf("Console: %s tokens, %s\n", humanize.Count(r.Console.Tokens), humanize.Cost(r.Console.Cost))
+	fmt.Printf("Local:   %s tokens, %s\n", humanize.Count(r.Local.Tokens), humanize.Cost(r.Local.Cost))
+	fmt.Printf("Delta:   %s not explained by local logs\n", humanize.CostDelta(r.CostDelta))
+}
+
+// renderReconcileShare draws the local share of the console cost as a bar with a percentage
+func renderReconcileShare(row calculations.ReconcileRow) string {
+	if row.Console.Cost <= 0 {
+		return "-"
+	}
+	share := row.Coverage()
+	filled := min(int(share*reconcileBarWidth+0.5), reconcileBarWidth)
+	return fmt.Sprintf("%s%s %3.0f%%", strings.Repeat("█", filled), strings.Repeat("░", reconcileBarWidth-filled), share*100)
+}
@@ -0,0 +1,549 @@
+package cmd
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/penwyp/claudecat/calculations"
+	"github.com/penwyp/claudecat/humanize"
+	"github.com/penwyp/claudecat/internal"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reportPeriod     string
+	reportDate       string
+	reportOutput     string
+	reportBench      bool
+	reportAsOf       string
+	reportHeat       bool
+	reportOutputFile string
+)
+
+// reportBarWidth is the width of the longest bar in the Markdown daily chart
+const reportBarWidth = 40
+
+var reportCmd = &cobra.Command{
+	Use:   "report [path...]",
+	Short: "Render a weekly or monthly usage report as Markdown or HTML",
+	Long: `Render a usage report for a calendar week (Monday to Sunday) or month: totals, a per-model
+breakdown, a daily cost chart and the most expensive sessions. Markdown output suits team wikis and
+chat; HTML output is a single self-contained file with the charts embedded as SVG.
+
+The report covers the period containing --date, by default the current one, which is marked as
+in progress until it ends. Sessions are listed in the period they started in.
+
+With --as-of, the report is reproducible: it is computed as of the given time, usage files modified
+after it are skipped, and pricing is frozen to the tables saved in the cache directory the first
+time that timestamp is used. The footer records the timestamp, the pricing tables and a hash of the
+settings the report depends on, so that a report regenerated months later with the same --as-of
+matches the original byte-for-byte.
+
+With --benchmark, a monthly report also places your API-equivalent cost among typical users of your
+subscription plan, using an anonymized distribution shipped with claudecat. No usage data is sent
+anywhere; the cost of a month in progress is extrapolated to the whole month.
+
+Examples:
+  claudecat report                                  # This week as Markdown
+  claudecat report --period month --date 2025-06-01 -o html > june.html
+  claudecat report -o html --output-file reports/week.html  # Safe to publish from cron
+  claudecat report --period week --date 2025-06-09 -o json
+  claudecat report --period month --benchmark       # Compare with typical users of subscription.plan
+  claudecat report --period month --date 2025-06-01 --as-of 2025-07-01T00:00:00Z`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output := strings.ToLower(reportOutput)
+		if output != "markdown" && output != "html" && output != "json" {
+			return fmt.Errorf("invalid output format: %s (valid: markdown, html, json)", reportOutput)
+		}
+		if _, _, err := calculations.ReportPeriodBounds(reportPeriod, time.Now(), time.Local); err != nil {
+			return err
+		}
+		if reportBench && !strings.EqualFold(reportPeriod, calculations.ReportPeriodMonth) {
+			return fmt.Errorf("--benchmark requires --period month")
+		}
+
+		cfg, err := loadCacheCommandConfig(cmd, args)
+		if err != nil {
+			return err
+		}
+		loc, err := time.LoadLocation(cfg.App.Timezone)
+		if err != nil {
+			loc = time.Local
+		}
+		now := time.Now()
+		var asOf time.Time
+		if reportAsOf != "" {
+			asOf, err = parseTimeString(reportAsOf)
+			if err != nil {
+				return fmt.Errorf("invalid --as-of %s: %w", reportAsOf, err)
+			}
+			if asOf.After(now) {
+				return fmt.Errorf("--as-of %s is in the future", reportAsOf)
+			}
+			now = asOf
+		}
+		date := now
+		if reportDate != "" {
+			parsed, err := parseTimeString(reportDate)
+			if err != nil {
+				return fmt.Errorf("invalid date %s: %w", reportDate, err)
+			}
+			// The date names a local calendar day; noon keeps it clear of DST transitions
+			date = time.Date(parsed.Year(), parsed.Month(), parsed.Day(), 12, 0, 0, 0, loc)
+		}
+
+		analyzer, err := internal.NewAnalyzer(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create analyzer: %w", err)
+		}
+		analyzer.SetAsOf(asOf)
+		report, err := analyzer.Report(cfg.Data.Paths, reportPeriod, date, now)
+		if err != nil {
+			return fmt.Errorf("report failed: %w", err)
+		}
+		recordCommandResult("sessions", report.Sessions)
+
+		if reportBench {
+			benchmarks, err := calculations.LoadBenchmarks()
+			if err != nil {
+				return err
+			}
+			comparison, err := report.CompareBenchmark(benchmarks, cfg.Subscription.Plan, now)
+			if err != nil {
+				return err
+			}
+			report.Benchmark = &comparison
+		}
+
+		return writeOutput(reportOutputFile, false, func(w io.Writer, _ bool) error {
+			switch output {
+			case "json":
+				data, err := sonic.MarshalIndent(report, "", "  ")
+				if err != nil {
+					return err
+				}
+				_, err = w.Write(append(data, '\n'))
+				return err
+			case "html":
+				return renderReportHTML(w, report, now, reportHeat)
+			default:
+				_, err := io.WriteString(w, renderReportMarkdown(report, now))
+				return err
+			}
+		})
+	},
+}
+
+func init() {
+	reportCmd.Flags().StringVar(&reportPeriod, "period", calculations.ReportPeriodWeek, "report period (week, month)")
+	reportCmd.Flags().StringVar(&reportDate, "date", "", "any day in the reported period (YYYY-MM-DD, default today)")
+	reportCmd.Flags().StringVarP(&reportOutput, "output", "o", "markdown", "output format (markdown, html, json)")
+	reportCmd.Flags().StringVar(&reportOutputFile, "output-file", "", "write the report to this file, replacing it only once it is complete")
+	reportCmd.Flags().BoolVar(&reportBench, "benchmark", false, "compare monthly cost with typical users of the subscription plan")
+	reportCmd.Flags().StringVar(&reportAsOf, "as-of", "", "compute a reproducible report as of this time, with frozen pricing")
+	reportCmd.Flags().BoolVar(&reportHeat, "heat", false, "color HTML table cells and daily bars green, yellow or red by their percentile")
+	rootCmd.AddCommand(reportCmd)
+}
+
+// reportTitle names the period of a report, e.g. "week of Jun 2, 2025" or "June 2025"
+func reportTitle(r calculations.Report) string {
+	if r.Period == calculations.ReportPeriodMonth {
+		return r.Start.Format("January 2006")
+	}
+	return "week of " + r.Start.Format("Jan 2, 2006")
+}
+
+// reportRange describes the days covered by a report and whether it is still in progress
+func reportRange(r calculations.Report, now time.Time) string {
+	last := r.End.AddDate(0, 0, -1)
+	text := fmt.Sprintf("%s – %s", r.Start.Format("Mon Jan 2"), last.Format("Mon Jan 2, 2006"))
+	if !r.Complete {
+		text += fmt.Sprintf(" (in progress, as of %s)", now.In(r.Start.Location()).Format("Jan 2 15:04"))
+	}
+	return text
+}
+
+// reportBenchmark describes where the monthly cost of a report falls among typical users of its plan
+func reportBenchmark(r calculations.Report, c calculations.BenchmarkComparison) string {
+	cost := humanize.Cost(c.MonthlyCost)
+	if c.Projected {
+		cost = "a projected " + cost
+	}
+	return fmt.Sprintf("At %s of API-equivalent usage in %s, you are in the %s percentile of typical %s users (median %s).",
+		cost, reportTitle(r), ordinal(c.Percentile), c.PlanName, humanize.Cost(c.Median))
+}
+
+// reportBenchmarkSource names the benchmark data a comparison is based on
+func reportBenchmarkSource(c calculations.BenchmarkComparison) string {
+	return fmt.Sprintf("Based on the anonymized %s benchmark shipped with claudecat; no usage data leaves this machine.", c.Version)
+}
+
+// reportInputsText describes the inputs of a reproducible report
+func reportInputsText(inputs calculations.ReportInputs) string {
+	return fmt.Sprintf("Reproducible as of %s with %s pricing (%s), config %s.",
+		inputs.AsOf.UTC().Format(time.RFC3339), inputs.Pricing, inputs.PricingHash, inputs.ConfigHash)
+}
+
+// ordinal formats n as an English ordinal, e.g. 1st, 22nd or 13th
+func ordinal(n int) string {
+	suffix := "th"
+	if n%100 < 11 || n%100 > 13 {
+		switch n % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return fmt.Sprintf("%d%s", n, suffix)
+}
+
+// renderReportMarkdown formats a report as Markdown with a text bar chart of the daily cost
+func renderReportMarkdown(r calculations.Report, now time.Time) string {
+	var b strings.Builder
+	cell := func(s string) string {
+		return strings.ReplaceAll(s, "|", `\|`)
+	}
+
+	fmt.Fprintf(&b, "# Claude usage report: %s\n\n", reportTitle(r))
+	fmt.Fprintf(&b, "_%s_\n\n", reportRange(r, now))
+
+	b.WriteString("## Totals\n\n")
+	b.WriteString("| Cost | Tokens | Entries | Sessions | Active days |\n")
+	b.WriteString("|-----:|-------:|--------:|---------:|------------:|\n")
+	fmt.Fprintf(&b, "| %s | %s | %s | %s | %d of %d |\n\n", humanize.Cost(r.TotalCost), humanize.Count(r.TotalTokens),
+		humanize.Count(r.Entries), humanize.Count(r.Sessions), r.ActiveDays, len(r.Days))
+
+	if r.Benchmark != nil {
+		b.WriteString("## Benchmark\n\n")
+		fmt.Fprintf(&b, "%s\n\n_%s_\n\n", reportBenchmark(r, *r.Benchmark), reportBenchmarkSource(*r.Benchmark))
+	}
+
+	b.WriteString("## Models\n\n")
+	if len(r.Models) == 0 {
+		b.WriteString("No usage in this period.\n\n")
+	} else {
+		b.WriteString("| Model | Entries | Input | Output | Cache write | Cache read | Total tokens | Cost | Share |\n")
+		b.WriteString("|-------|--------:|------:|-------:|------------:|-----------:|-------------:|-----:|------:|\n")
+		for _, m := range r.Models {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s | %s | %s | %.1f%% |\n", cell(m.Model),
+				humanize.Count(m.Entries), humanize.Count(m.InputTokens), humanize.Count(m.OutputTokens),
+				humanize.Count(m.CacheCreationTokens), humanize.Count(m.CacheReadTokens),
+				humanize.Count(m.TotalTokens), humanize.Cost(m.Cost), m.Share*100)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("## Daily cost\n\n```text\n")
+	maxCost := 0.0
+	for _, day := range r.Days {
+		maxCost = math.Max(maxCost, day.Cost)
+	}
+	for _, day := range r.Days {
+		bar := 0
+		if maxCost > 0 {
+			bar = int(math.Round(day.Cost / maxCost * reportBarWidth))
+		}
+		if bar == 0 && day.Cost > 0 {
+			bar = 1
+		}
+		fmt.Fprintf(&b, "%s  %-*s %s\n", day.Date.Format("Mon Jan 02"), reportBarWidth,
+			strings.Repeat("█", bar), humanize.Cost(day.Cost))
+	}
+	b.WriteString("```\n\n")
+
+	b.WriteString("## Top sessions\n\n")
+	if len(r.TopSessions) == 0 {
+		b.WriteString("No sessions started in this period.\n")
+	} else {
+		b.WriteString("| Start | Cost | Tokens | Projects | Models | Tags |\n")
+		b.WriteString("|-------|-----:|-------:|----------|--------|------|\n")
+		for _, s := range r.TopSessions {
+			start := s.StartTime.In(r.Start.Location()).Format("Mon Jan 2 15:04")
+			if s.LimitHit {
+				start += " (hit limit)"
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s |\n", start, humanize.Cost(s.Cost), humanize.Count(s.Tokens),
+				cell(strings.Join(s.Projects, ", ")), cell(strings.Join(s.Models, ", ")), cell(strings.Join(s.Tags, ", ")))
+		}
+	}
+
+	if r.Inputs != nil {
+		fmt.Fprintf(&b, "\n---\n\n_%s_\n", reportInputsText(*r.Inputs))
+	}
+	return b.String()
+}
+
+// Dimensions of the SVG daily cost chart in the HTML report
+const (
+	reportChartWidth  = 760
+	reportChartHeight = 220
+	reportChartLeft   = 56 // Room for the cost axis labels
+	reportChartBottom = 28 // Room for the day labels
+)
+
+// reportBar is one bar of an SVG chart
+type reportBar struct {
+	X, Y, Width, Height float64
+	Label, Title        string
+	ShowLabel           bool
+	Heat                string // Heat class of the bar, with its leading space
+}
+
+// reportGridLine is a horizontal cost gridline of the daily chart
+type reportGridLine struct {
+	Y     float64
+	Label string
+}
+
+// reportHTMLData is the view model of reportHTMLTemplate
+type reportHTMLData struct {
+	Title, Range, Generated string
+	Benchmark, BenchSource  string
+	Inputs                  string
+	Report                  calculations.Report
+	Totals                  [][2]string
+	Bars                    []reportBar
+	Grid                    []reportGridLine
+	ModelBars               []reportBar
+	ChartWidth, ChartHeight int
+	ModelChartHeight        int
+	ModelHeat, SessionHeat  [][]string // Heat classes of the table cells by row and numeric column
+}
+
+// renderReportHTML writes a report as a self-contained HTML page with SVG charts; with heat, table
+// cells and daily bars are colored by their percentile
+func renderReportHTML(w io.Writer, r calculations.Report, now time.Time, heat bool) error {
+	data := reportHTMLData{
+		Title:       "Claude usage report: " + reportTitle(r),
+		Range:       reportRange(r, now),
+		Generated:   now.In(r.Start.Location()).Format("2006-01-02 15:04 MST"),
+		Report:      r,
+		ChartWidth:  reportChartWidth,
+		ChartHeight: reportChartHeight,
+		Totals: [][2]string{
+			{"Cost", humanize.Cost(r.TotalCost)},
+			{"Tokens", humanize.Count(r.TotalTokens)},
+			{"Entries", humanize.Count(r.Entries)},
+			{"Sessions", humanize.Count(r.Sessions)},
+			{"Active days", fmt.Sprintf("%d of %d", r.ActiveDays, len(r.Days))},
+		},
+	}
+
+	if r.Benchmark != nil {
+		data.Benchmark = reportBenchmark(r, *r.Benchmark)
+		data.BenchSource = reportBenchmarkSource(*r.Benchmark)
+	}
+	if r.Inputs != nil {
+		data.Inputs = reportInputsText(*r.Inputs)
+	}
+
+	maxCost := 0.0
+	for _, day := range r.Days {
+		maxCost = math.Max(maxCost, day.Cost)
+	}
+	plotHeight := float64(reportChartHeight - reportChartBottom - 8)
+	slot := float64(reportChartWidth-reportChartLeft) / float64(max(len(r.Days), 1))
+	labelEvery := int(math.Ceil(float64(len(r.Days)) / 16))
+	for i, day := range r.Days {
+		height := 0.0
+		if maxCost > 0 {
+			height = day.Cost / maxCost * plotHeight
+		}
+		label := day.Date.Format("Mon 2")
+		if r.Period == calculations.ReportPeriodMonth {
+			label = day.Date.Format("2")
+		}
+		data.Bars = append(data.Bars, reportBar{
+			X:         float64(reportChartLeft) + float64(i)*slot + slot*0.15,
+			Y:         8 + plotHeight - height,
+			Width:     slot * 0.7,
+			Height:    height,
+			Label:     label,
+			Title:     fmt.Sprintf("%s: %s, %s tokens", day.Date.Format("Mon Jan 2"), humanize.Cost(day.Cost), humanize.Count(day.Tokens)),
+			ShowLabel: i%labelEvery == 0,
+		})
+	}
+	for i := 0; i <= 4 && maxCost > 0; i++ {
+		data.Grid = append(data.Grid, reportGridLine{
+			Y:     8 + plotHeight - plotHeight*float64(i)/4,
+			Label: humanize.Cost(maxCost * float64(i) / 4),
+		})
+	}
+
+	const modelRow = 26
+	for i, m := range r.Models {
+		data.ModelBars = append(data.ModelBars, reportBar{
+			X:     220,
+			Y:     float64(i*modelRow + 4),
+			Width: math.Max(m.Share*float64(reportChartWidth-320), 1),
+			Label: m.Model,
+			Title: fmt.Sprintf("%s (%.1f%%)", humanize.Cost(m.Cost), m.Share*100),
+		})
+	}
+	data.ModelChartHeight = len(r.Models)*modelRow + 4
+
+	if heat {
+		dayCosts := make([]float64, len(r.Days))
+		for i, day := range r.Days {
+			dayCosts[i] = day.Cost
+		}
+		for i, class := range heatClasses(dayCosts) {
+			data.Bars[i].Heat = class[0]
+		}
+		var input, output, cacheWrite, cacheRead, total, cost []float64
+		for _, m := range r.Models {
+			input = append(input, float64(m.InputTokens))
+			output = append(output, float64(m.OutputTokens))
+			cacheWrite = append(cacheWrite, float64(m.CacheCreationTokens))
+			cacheRead = append(cacheRead, float64(m.CacheReadTokens))
+			total = append(total, float64(m.TotalTokens))
+			cost = append(cost, m.Cost)
+		}
+		data.ModelHeat = heatClasses(input, output, cacheWrite, cacheRead, total, cost)
+		var sessionCost, sessionTokens []float64
+		for _, s := range r.TopSessions {
+			sessionCost = append(sessionCost, s.Cost)
+			sessionTokens = append(sessionTokens, float64(s.Tokens))
+		}
+		data.SessionHeat = heatClasses(sessionCost, sessionTokens)
+	}
+
+	return reportHTMLTemplate.Execute(w, data)
+}
+
+// heatClasses returns the heat classes of rows of cells given the values of each column
+func heatClasses(columns ...[]float64) [][]string {
+	var rows [][]string
+	for col, values := range columns {
+		for row, level := range calculations.HeatLevels(values) {
+			if row == len(rows) {
+				rows = append(rows, make([]string, len(columns)))
+			}
+			if level != calculations.HeatNone {
+				rows[row][col] = " " + reportHeatClasses[level]
+			}
+		}
+	}
+	return rows
+}
+
+// reportHeatClasses are the CSS classes of the heat levels
+var reportHeatClasses = map[calculations.HeatLevel]string{
+	calculations.HeatLow:    "heat-low",
+	calculations.HeatMedium: "heat-medium",
+	calculations.HeatHigh:   "heat-high",
+}
+
+var reportHTMLTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"cost":   humanize.Cost,
+	"commas": humanize.Count,
+	"pct":    func(share float64) string { return fmt.Sprintf("%.1f%%", share*100) },
+	"join":   strings.Join,
+	"local": func(t time.Time, r calculations.Report) string {
+		return t.In(r.Start.Location()).Format("Mon Jan 2 15:04")
+	},
+	"add": func(a, b float64) float64 { return a + b },
+	"heat": func(classes [][]string, row, col int) string {
+		if row < len(classes) {
+			return classes[row][col]
+		}
+		return ""
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; color: #1f2328; max-width: 860px; margin: 2em auto; padding: 0 1em; }
+h1 { font-size: 1.6em; margin-bottom: 0.2em; }
+.range { color: #59636e; margin-top: 0; }
+.totals { display: flex; flex-wrap: wrap; gap: 1em; margin: 1.5em 0; }
+.total { border: 1px solid #d1d9e0; border-radius: 6px; padding: 0.6em 1em; min-width: 110px; }
+.total .value { font-size: 1.4em; font-weight: 600; }
+.total .name { color: #59636e; font-size: 0.85em; }
+table { border-collapse: collapse; width: 100%; font-size: 0.9em; }
+th, td { border-bottom: 1px solid #d1d9e0; padding: 0.4em 0.6em; text-align: left; }
+td.num, th.num { text-align: right; font-variant-numeric: tabular-nums; }
+svg text { font-size: 11px; fill: #59636e; }
+.bar { fill: #d97757; }
+.grid { stroke: #e6eaef; }
+.heat-low { background: #dafbe1; }
+.heat-medium { background: #fff8c5; }
+.heat-high { background: #ffebe9; }
+.bar.heat-low { fill: #4ac26b; }
+.bar.heat-medium { fill: #d4a72c; }
+.bar.heat-high { fill: #e5534b; }
+footer { color: #59636e; font-size: 0.8em; margin-top: 2em; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="range">{{.Range}}</p>
+
+<div class="totals">
+{{- range .Totals}}
+<div class="total"><div class="value">{{index . 1}}</div><div class="name">{{index . 0}}</div></div>
+{{- end}}
+</div>
+{{- if .Benchmark}}
+
+<h2>Benchmark</h2>
+<p>{{.Benchmark}}</p>
+<p class="range">{{.BenchSource}}</p>
+{{- end}}
+
+<h2>Daily cost</h2>
+<svg width="{{.ChartWidth}}" height="{{.ChartHeight}}" viewBox="0 0 {{.ChartWidth}} {{.ChartHeight}}" role="img" aria-label="Daily cost">
+{{- range .Grid}}
+<line class="grid" x1="56" x2="{{$.ChartWidth}}" y1="{{.Y}}" y2="{{.Y}}"/><text x="50" y="{{add .Y 4}}" text-anchor="end">{{.Label}}</text>
+{{- end}}
+{{- range .Bars}}
+<rect class="bar{{.Heat}}" x="{{.X}}" y="{{.Y}}" width="{{.Width}}" height="{{.Height}}"><title>{{.Title}}</title></rect>
+{{- if .ShowLabel}}<text x="{{add .X .Width}}" y="{{$.ChartHeight}}" dy="-10" text-anchor="end">{{.Label}}</text>{{end}}
+{{- end}}
+</svg>
+
+<h2>Models</h2>
+{{- if .Report.Models}}
+<svg width="{{.ChartWidth}}" height="{{.ModelChartHeight}}" viewBox="0 0 {{.ChartWidth}} {{.ModelChartHeight}}" role="img" aria-label="Cost share by model">
+{{- range .ModelBars}}
+<text x="212" y="{{add .Y 14}}" text-anchor="end">{{.Label}}</text>
+<rect class="bar" x="{{.X}}" y="{{.Y}}" width="{{.Width}}" height="18"><title>{{.Title}}</title></rect>
+<text x="{{add .X (add .Width 6)}}" y="{{add .Y 14}}">{{.Title}}</text>
+{{- end}}
+</svg>
+<table>
+<tr><th>Model</th><th class="num">Entries</th><th class="num">Input</th><th class="num">Output</th><th class="num">Cache write</th><th class="num">Cache read</th><th class="num">Total tokens</th><th class="num">Cost</th><th class="num">Share</th></tr>
+{{- range $i, $m := .Report.Models}}
+<tr><td>{{.Model}}</td><td class="num">{{commas .Entries}}</td><td class="num{{heat $.ModelHeat $i 0}}">{{commas .InputTokens}}</td><td class="num{{heat $.ModelHeat $i 1}}">{{commas .OutputTokens}}</td><td class="num{{heat $.ModelHeat $i 2}}">{{commas .CacheCreationTokens}}</td><td class="num{{heat $.ModelHeat $i 3}}">{{commas .CacheReadTokens}}</td><td class="num{{heat $.ModelHeat $i 4}}">{{commas .TotalTokens}}</td><td class="num{{heat $.ModelHeat $i 5}}">{{cost .Cost}}</td><td class="num">{{pct .Share}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p>No usage in this period.</p>
+{{- end}}
+
+<h2>Top sessions</h2>
+{{- if .Report.TopSessions}}
+<table>
+<tr><th>Start</th><th class="num">Cost</th><th class="num">Tokens</th><th>Projects</th><th>Models</th><th>Tags</th></tr>
+{{- range $i, $s := .Report.TopSessions}}
+<tr><td>{{local .StartTime $.Report}}{{if .LimitHit}} (hit limit){{end}}</td><td class="num{{heat $.SessionHeat $i 0}}">{{cost .Cost}}</td><td class="num{{heat $.SessionHeat $i 1}}">{{commas .Tokens}}</td><td>{{join .Projects ", "}}</td><td>{{join .Models ", "}}</td><td>{{join .Tags ", "}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p>No sessions started in this period.</p>
+{{- end}}
+
+<footer>Generated by claudecat on {{.Generated}}{{if .Inputs}}<br>{{.Inputs}}{{end}}</footer>
+</body>
+</html>
+`))
@@ -8,8 +8,10 @@ import (
 	"time"
 
 	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/humanize"
 	"github.com/penwyp/claudecat/internal"
 	"github.com/penwyp/claudecat/logging"
+	"github.com/penwyp/claudecat/terminal"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -20,6 +22,11 @@ var (
 	noColor  bool
 	debug    bool
 	verbose  bool
+	charset  string
+	// hyperlinks selects when project names and session IDs link to their logs
+	hyperlinks string
+	// fullNames shows table cells in full instead of capping them at ui.column_widths
+	fullNames bool
 	// Run command flags moved to root
 	runPaths      []string
 	runPlan       string
@@ -30,10 +37,21 @@ var (
 	// pricing and deduplication flags
 	pricingSource       string
 	pricingOffline      bool
+	costModel           string
 	enableDeduplication bool
 	// Monitor view flags
 	timezone   string
 	timeFormat string
+	smoothing  string
+	// Burn rate alarm flags
+	burnAlarm      float64
+	burnAlarmFor   time.Duration
+	burnAlarmStyle string
+	// InfluxDB export flags
+	influxFile string
+	influxURL  string
+	// Low-power mode flag
+	lowPower string
 )
 
 var rootCmd = &cobra.Command{
@@ -42,10 +60,25 @@ var rootCmd = &cobra.Command{
 	Long: `claudecat is a high-performance console application for monitoring Claude AI token usage and costs.
 
 It provides real-time monitoring, session analysis, cost calculations, and data export
-capabilities to help developers track their Claude API usage efficiently.`,
+capabilities to help developers track their Claude API usage efficiently.
+
+Press f in the monitor to toggle a minimal focus display with big digits. Press : to open the
+command palette, e.g. :range 7d, :group model, :export csv ~/out.csv, :theme dark, :about or :help.`,
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		switch strings.ToLower(charset) {
+		case terminal.CharsetAuto, terminal.CharsetUnicode, terminal.CharsetASCII:
+			terminal.SetCharset(strings.ToLower(charset))
+		default:
+			return fmt.Errorf("invalid charset: %s (valid options: auto, unicode, ascii)", charset)
+		}
+		switch strings.ToLower(hyperlinks) {
+		case terminal.HyperlinksAuto, terminal.HyperlinksAlways, terminal.HyperlinksNever:
+			terminal.SetHyperlinks(strings.ToLower(hyperlinks), stdoutIsTerminal())
+		default:
+			return fmt.Errorf("invalid hyperlinks mode: %s (valid options: auto, always, never)", hyperlinks)
+		}
 		return initializeConfig()
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -85,16 +118,30 @@ capabilities to help developers track their Claude API usage efficiently.`,
 	},
 }
 
+// ExitCodeError ends the process with Code instead of 1, for commands whose exit status reports a result
+// they have already printed
+type ExitCodeError struct {
+	Code   int
+	Reason string
+}
+
+func (e *ExitCodeError) Error() string {
+	return e.Reason
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately
 func Execute() error {
-	return rootCmd.Execute()
+	start := time.Now()
+	cmd, err := rootCmd.ExecuteC()
+	recordHistory(cmd, start, err)
+	return err
 }
 
 func init() {
 	cobra.OnInitialize(initConfig)
 
-	// Disable default completion command
-	rootCmd.CompletionOptions.DisableDefaultCmd = true
+	// Completion scripts complete models, projects and session IDs from the user's data
+	rootCmd.CompletionOptions.DisableDefaultCmd = false
 
 	// Disable help subcommand but allow -h/--help flags
 	rootCmd.SetHelpCommand(&cobra.Command{Hidden: true})
@@ -106,6 +153,9 @@ func init() {
 	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "disable colored output")
 	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "enable debug mode")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
+	rootCmd.PersistentFlags().StringVar(&charset, "charset", terminal.CharsetAuto, "output characters (auto detects the terminal, unicode, ascii)")
+	rootCmd.PersistentFlags().StringVar(&hyperlinks, "hyperlinks", terminal.HyperlinksAuto, "link project names and session IDs to their logs (auto detects OSC 8 support, always, never)")
+	rootCmd.PersistentFlags().BoolVar(&fullNames, "full-names", false, "show project names and other table cells in full instead of shortening them to ui.column_widths")
 
 	// Run command flags (now default behavior)
 	rootCmd.Flags().StringSliceVarP(&runPaths, "paths", "p", nil, "data paths to monitor (can be specified multiple times)")
@@ -118,6 +168,7 @@ func init() {
 	// Global pricing flags (moved from analyze command)
 	rootCmd.PersistentFlags().StringVar(&pricingSource, "pricing-source", "", "pricing source (default, litellm)")
 	rootCmd.PersistentFlags().BoolVar(&pricingOffline, "pricing-offline", false, "use cached pricing data for offline mode")
+	rootCmd.PersistentFlags().StringVar(&costModel, "cost-model", "", "cost all data paths as billed by subscription, api, bedrock or vertex (add -regional for regional endpoints)")
 
 	// Pricing and deduplication flags
 	rootCmd.Flags().BoolVar(&enableDeduplication, "deduplication", false, "enable deduplication of entries across all files")
@@ -125,6 +176,16 @@ func init() {
 	// Monitor view flags
 	rootCmd.Flags().StringVar(&timezone, "timezone", "", "timezone for display (e.g., Asia/Shanghai)")
 	rootCmd.Flags().StringVar(&timeFormat, "time-format", "", "time format (12h or 24h)")
+	rootCmd.Flags().StringVar(&smoothing, "smoothing", "", fmt.Sprintf("burn/cost rate smoothing (%s)", strings.Join(config.SmoothingModes, ", ")))
+	rootCmd.Flags().Float64Var(&burnAlarm, "burn-alarm", 0, "alarm when burn rate exceeds this many tokens/min (0 = disabled)")
+	rootCmd.Flags().DurationVar(&burnAlarmFor, "burn-alarm-for", 0, "how long the burn rate must exceed --burn-alarm (default 1m)")
+	rootCmd.Flags().StringVar(&burnAlarmStyle, "burn-alarm-style", "", "how to signal the burn rate alarm (bell, flash, both)")
+	rootCmd.Flags().StringVar(&lowPower, "low-power", "", "slow refreshes and pause background work (auto = on battery, on, off)")
+	rootCmd.Flags().Lookup("low-power").NoOptDefVal = "on"
+
+	// InfluxDB export flags
+	rootCmd.Flags().StringVar(&influxFile, "influx-file", "", "write usage as InfluxDB line protocol to this file on each refresh")
+	rootCmd.Flags().StringVar(&influxURL, "influx-url", "", "InfluxDB/VictoriaMetrics write endpoint for usage points")
 
 	// Bind flags to viper
 	if err := viper.BindPFlag("log.level", rootCmd.PersistentFlags().Lookup("log-level")); err != nil {
@@ -284,6 +345,19 @@ func loadConfiguration(cmd *cobra.Command) (*config.Config, error) {
 	if err != nil {
 		return nil, err
 	}
+	// A cost model given on the command line applies to every data path
+	if costModel != "" {
+		if err := config.ValidateCostModel(strings.ToLower(costModel)); err != nil {
+			return nil, err
+		}
+		cfg.Data.CostModel = strings.ToLower(costModel)
+		cfg.Data.CostModels = nil
+	}
+	// Validated above, so selecting the locale cannot fail
+	_ = humanize.SetLocale(cfg.UI.Locale)
+	terminal.SetSessionLink(cfg.UI.SessionLink)
+	terminal.SetColumnWidths(cfg.UI.ColumnWidths)
+	terminal.SetFullNames(fullNames)
 
 	return cfg, nil
 }
@@ -345,6 +419,39 @@ func applyRunFlags(cfg *config.Config) error {
 		}
 	}
 
+	// Apply burn rate smoothing if provided
+	if smoothing != "" {
+		if err := config.ValidateBurnRateSmoothing(strings.ToLower(smoothing)); err != nil {
+			return err
+		}
+		cfg.UI.BurnRateSmoothing = strings.ToLower(smoothing)
+	}
+
+	// Apply burn rate alarm if provided
+	if burnAlarm < 0 {
+		return fmt.Errorf("invalid burn alarm threshold: %v (must be non-negative)", burnAlarm)
+	}
+	if burnAlarm > 0 {
+		cfg.UI.BurnAlarmThreshold = burnAlarm
+	}
+	if burnAlarmFor > 0 {
+		cfg.UI.BurnAlarmDuration = burnAlarmFor
+	}
+	if burnAlarmStyle != "" {
+		if err := config.ValidateBurnAlarmStyle(strings.ToLower(burnAlarmStyle)); err != nil {
+			return err
+		}
+		cfg.UI.BurnAlarmStyle = strings.ToLower(burnAlarmStyle)
+	}
+
+	// Apply low-power mode if provided
+	if lowPower != "" {
+		if err := config.ValidateLowPowerMode(strings.ToLower(lowPower)); err != nil {
+			return err
+		}
+		cfg.UI.LowPower = strings.ToLower(lowPower)
+	}
+
 	// Apply watch flag
 	if runWatch {
 		cfg.Data.AutoDiscover = true
@@ -404,5 +511,18 @@ func applyRunFlags(cfg *config.Config) error {
 		}
 	}
 
+	// Apply InfluxDB export destinations if provided
+	if influxFile != "" {
+		cfg.Export.InfluxDB.Enabled = true
+		cfg.Export.InfluxDB.File = influxFile
+	}
+	if influxURL != "" {
+		if !strings.HasPrefix(influxURL, "http://") && !strings.HasPrefix(influxURL, "https://") {
+			return fmt.Errorf("invalid influx url: %s (must start with http:// or https://)", influxURL)
+		}
+		cfg.Export.InfluxDB.Enabled = true
+		cfg.Export.InfluxDB.URL = influxURL
+	}
+
 	return nil
 }
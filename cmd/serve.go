@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/penwyp/claudecat/internal"
+	"github.com/penwyp/claudecat/logging"
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveAddress string
+	serveToken   string
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve [path]",
+	Short: "Serve live usage data as a JSON HTTP API",
+	Long: `Monitor usage in the background and serve the latest data over HTTP, so dashboards and
+scripts can poll claudecat instead of re-parsing the JSONL logs.
+
+Endpoints:
+  GET /api/v1/health       Liveness probe; needs no token
+  GET /api/v1/monitoring   Latest monitoring data, including session blocks
+  GET /api/v1/blocks       Session blocks; ?active=true for the active block, ?entries=true to include entries,
+                           ?gaps=true to include the idle gaps
+  GET /api/v1/daily        Totals per local day; ?days=N for the last N days
+  GET /api/v1/burn-rate    Burn rate and projection of the active block
+  GET /api/v1/coverage     Data path and start of the history held by the server
+
+While the server runs, analyze --from and sessions on the same data path read its data instead of
+parsing the files when its history covers the requested range; pass --local to parse them anyway.
+
+When api.token is set, requests must send "Authorization: Bearer <token>". Serving on a
+non-loopback address requires a token. Requests forwarded by a trusted proxy are limited to the
+projects of their cost center (api.cost_centers); once cost centers are configured, requests from
+other remote addresses are refused and only local clients see every project. Interrupt or SIGTERM
+stops the server after in-flight requests finish.
+
+Examples:
+  claudecat serve                                   # http://127.0.0.1:8787
+  claudecat serve --addr 0.0.0.0:8787 --token "$TOKEN"
+  curl -H "Authorization: Bearer $TOKEN" http://host:8787/api/v1/daily?days=7`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfiguration(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		if len(args) > 0 {
+			if _, err := os.Stat(args[0]); err != nil {
+				return fmt.Errorf("path does not exist: %s", args[0])
+			}
+			cfg.Data.Paths = args
+		}
+		if serveAddress != "" {
+			cfg.API.Address = serveAddress
+		}
+		if serveToken != "" {
+			cfg.API.Token = serveToken
+		}
+		// Serve without the interactive display
+		cfg.UI.CompactMode = true
+
+		logging.InitLogger(cfg.App.LogLevel, cfg.App.LogFile, cfg.Debug.Enabled)
+
+		app, err := internal.NewEnhancedApplication(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create enhanced application: %w", err)
+		}
+		server, err := app.EnableAPI()
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(os.Stderr, "Serving the claudecat API on http://%s (press Ctrl+C to stop)\n", server.Addr())
+		return app.Run()
+	},
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveAddress, "addr", "", "bind address (default api.address, 127.0.0.1:8787)")
+	serveCmd.Flags().StringVar(&serveToken, "token", "", "bearer token required by requests (default api.token)")
+	markHistorySecret(serveCmd.Flags(), "token")
+	rootCmd.AddCommand(serveCmd)
+}
@@ -0,0 +1,287 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/penwyp/claudecat/humanize"
+	"github.com/penwyp/claudecat/internal"
+	"github.com/penwyp/claudecat/models"
+	"github.com/penwyp/claudecat/sessions"
+	"github.com/spf13/cobra"
+)
+
+var (
+	sessionsOutput   string
+	sessionsFrom     string
+	sessionsTo       string
+	sessionsActive   bool
+	sessionsHideGaps bool
+	sessionsPerModel bool
+	sessionsLocal    bool
+)
+
+var sessionsCmd = &cobra.Command{
+	Use:   "sessions [path...]",
+	Short: "List detected 5-hour session blocks",
+	Long: `List the 5-hour session blocks the monitor detects in the usage data, with their start, end,
+active duration, tokens, cost and models, along with the idle gaps between them.
+
+Dates given without a time are read in the configured timezone, and --to includes the whole day.
+
+With --per-model, each session is broken down into one row per model with every token category and
+its cost. The CSV keeps a fixed column order, ISO 8601 timestamps in the configured timezone and
+plain numbers, so it can be pivoted in Excel or Sheets as is.
+
+Examples:
+  claudecat sessions                                       # Every session so far
+  claudecat sessions --from 2025-06-01 --to 2025-06-07     # Sessions overlapping a week
+  claudecat sessions --active                              # Only the session in progress
+  claudecat sessions --hide-gaps -o csv > sessions.csv     # Spreadsheet export
+  claudecat sessions -o csv --per-model > models.csv       # One row per session and model for pivot tables
+
+When claudecat serve is running on api.address for the same data path and its history covers --from
+(or --active), the sessions come from its warm data instead of the files; --local always parses the files.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output := strings.ToLower(sessionsOutput)
+		if output != "table" && output != "json" && output != "csv" {
+			return fmt.Errorf("invalid output format: %s (valid: table, json, csv)", sessionsOutput)
+		}
+		if sessionsPerModel && output == "table" {
+			return fmt.Errorf("--per-model requires --output csv or json")
+		}
+
+		cfg, err := loadCacheCommandConfig(cmd, args)
+		if err != nil {
+			return err
+		}
+		loc, err := time.LoadLocation(cfg.App.Timezone)
+		if err != nil {
+			loc = time.Local
+		}
+
+		filter := sessions.ListFilter{ActiveOnly: sessionsActive, HideGaps: sessionsHideGaps}
+		if sessionsFrom != "" {
+			if filter.From, err = parseSessionsBound(sessionsFrom, loc, false); err != nil {
+				return fmt.Errorf("invalid from date %s: %w", sessionsFrom, err)
+			}
+		}
+		if sessionsTo != "" {
+			if filter.To, err = parseSessionsBound(sessionsTo, loc, true); err != nil {
+				return fmt.Errorf("invalid to date %s: %w", sessionsTo, err)
+			}
+		}
+		if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+			return fmt.Errorf("--to must not be before --from")
+		}
+
+		analyzer, err := internal.NewAnalyzer(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create analyzer: %w", err)
+		}
+		if !sessionsLocal {
+			from := filter.From
+			if from.IsZero() && filter.ActiveOnly {
+				from = time.Now().Add(-models.SessionDuration)
+			}
+			analyzer.SetDaemon(internal.NewDaemonClient(cfg.API), from)
+		}
+		if sessionsPerModel {
+			return runSessionsPerModel(analyzer, cfg.Data.Paths, filter, output, loc)
+		}
+		summaries, err := analyzer.Sessions(cfg.Data.Paths, filter, time.Now())
+		if err != nil {
+			return fmt.Errorf("session listing failed: %w", err)
+		}
+		recordCommandResult("sessions", len(summaries))
+
+		switch output {
+		case "json":
+			data, err := sonic.MarshalIndent(summaries, "", "  ")
+			if err != nil {
+				return err
+			}
+			_, err = os.Stdout.Write(append(data, '\n'))
+			return err
+		case "csv":
+			return writeSessionsCSV(summaries, loc)
+		}
+
+		if len(summaries) == 0 {
+			fmt.Println("No sessions to display.")
+			return nil
+		}
+		printSessions(summaries, loc)
+		return nil
+	},
+}
+
+func init() {
+	sessionsCmd.Flags().StringVarP(&sessionsOutput, "output", "o", "table", "output format (table, json, csv)")
+	sessionsCmd.Flags().StringVar(&sessionsFrom, "from", "", "list sessions ending after this date (YYYY-MM-DD or YYYY-MM-DD HH:MM:SS)")
+	sessionsCmd.Flags().StringVar(&sessionsTo, "to", "", "list sessions starting before this date (YYYY-MM-DD or YYYY-MM-DD HH:MM:SS)")
+	sessionsCmd.Flags().BoolVar(&sessionsActive, "active", false, "only list the active session")
+	sessionsCmd.Flags().BoolVar(&sessionsHideGaps, "hide-gaps", false, "omit the idle gaps between sessions")
+	sessionsCmd.Flags().BoolVar(&sessionsPerModel, "per-model", false, "export one row per session and model (csv or json)")
+	sessionsCmd.Flags().BoolVar(&sessionsLocal, "local", false, "parse the files even when claudecat serve is running")
+	rootCmd.AddCommand(sessionsCmd)
+}
+
+// parseSessionsBound parses a range bound; a bare date is read in loc and, as an upper bound, covers the whole day
+func parseSessionsBound(value string, loc *time.Location, upper bool) (time.Time, error) {
+	if day, err := time.ParseInLocation("2006-01-02", value, loc); err == nil {
+		if upper {
+			return day.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+		}
+		return day, nil
+	}
+	return parseTimeString(value)
+}
+
+// printSessions prints one row per session or gap, followed by the totals of the sessions
+func printSessions(summaries []sessions.SessionSummary, loc *time.Location) {
+	headers := []string{"Start", "End", "Duration", "Status", "Entries", "Total Tokens", "Cost (USD)", "Models"}
+	tagged := false
+	for _, summary := range summaries {
+		if len(summary.Tags) > 0 {
+			tagged = true
+		}
+	}
+	if tagged {
+		headers = append(headers, "Tags")
+	}
+	table := newTableFormatter(headers)
+	count, tokens, cost := 0, 0, 0.0
+	for _, summary := range summaries {
+		status := "done"
+		switch {
+		case summary.Gap:
+			row := []string{
+				summary.Start.In(loc).Format("2006-01-02 15:04"),
+				summary.End.In(loc).Format("2006-01-02 15:04"),
+				humanize.Duration(summary.Duration),
+				"gap", "", "", "", "",
+			}
+			if tagged {
+				row = append(row, "")
+			}
+			table.addRow(row)
+			continue
+		case summary.Active:
+			status = "active"
+		}
+		if summary.LimitHits > 0 {
+			status += " (limit)"
+		}
+		count++
+		tokens += summary.TotalTokens
+		cost += summary.CostUSD
+		row := []string{
+			summary.Start.In(loc).Format("2006-01-02 15:04"),
+			summary.End.In(loc).Format("2006-01-02 15:04"),
+			humanize.Duration(summary.Duration),
+			status,
+			humanize.Count(summary.Entries),
+			humanize.Count(summary.TotalTokens),
+			humanize.Cost(summary.CostUSD),
+			formatModels(summary.Models),
+		}
+		if tagged {
+			row = append(row, strings.Join(summary.Tags, ", "))
+		}
+		table.addRow(row)
+	}
+	fmt.Println(table.render())
+	fmt.Printf("%d session(s), %s tokens, %s\n", count, humanize.Count(tokens), humanize.Cost(cost))
+}
+
+// writeSessionsCSV writes one record per session or gap with the token breakdown
+func writeSessionsCSV(summaries []sessions.SessionSummary, loc *time.Location) error {
+	writer := csv.NewWriter(os.Stdout)
+	_ = writer.Write([]string{"ID", "Start", "End", "Last Activity", "Duration Minutes", "Active", "Gap", "Entries",
+		"Input Tokens", "Output Tokens", "Cache Creation", "Cache Read", "Total Tokens", "Cost USD", "Models", "Limit Hits", "Tags", "Note"})
+	for _, summary := range summaries {
+		lastActivity := ""
+		if summary.LastActivity != nil {
+			lastActivity = summary.LastActivity.In(loc).Format("2006-01-02 15:04:05")
+		}
+		_ = writer.Write([]string{
+			summary.ID,
+			summary.Start.In(loc).Format("2006-01-02 15:04:05"),
+			summary.End.In(loc).Format("2006-01-02 15:04:05"),
+			lastActivity,
+			strconv.Itoa(int(summary.Duration.Minutes())),
+			strconv.FormatBool(summary.Active),
+			strconv.FormatBool(summary.Gap),
+			strconv.Itoa(summary.Entries),
+			strconv.Itoa(summary.Tokens.InputTokens),
+			strconv.Itoa(summary.Tokens.OutputTokens),
+			strconv.Itoa(summary.Tokens.CacheCreationTokens),
+			strconv.Itoa(summary.Tokens.CacheReadTokens),
+			strconv.Itoa(summary.TotalTokens),
+			fmt.Sprintf("%.4f", summary.CostUSD),
+			strings.Join(summary.Models, ";"),
+			strconv.Itoa(summary.LimitHits),
+			strings.Join(summary.Tags, ";"),
+			summary.Note,
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// runSessionsPerModel exports the usage of each model in each selected session
+func runSessionsPerModel(analyzer *internal.Analyzer, paths []string, filter sessions.ListFilter, output string, loc *time.Location) error {
+	rows, err := analyzer.SessionModels(paths, filter, time.Now())
+	if err != nil {
+		return fmt.Errorf("session listing failed: %w", err)
+	}
+	recordCommandResult("rows", len(rows))
+
+	if output == "json" {
+		if rows == nil {
+			rows = []sessions.SessionModelUsage{}
+		}
+		data, err := sonic.MarshalIndent(rows, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(append(data, '\n'))
+		return err
+	}
+	return writeSessionModelsCSV(rows, loc)
+}
+
+// writeSessionModelsCSV writes one record per session and model, laid out for pivot tables: a fixed
+// column order, ISO 8601 dates and numbers without thousands separators
+func writeSessionModelsCSV(rows []sessions.SessionModelUsage, loc *time.Location) error {
+	writer := csv.NewWriter(os.Stdout)
+	_ = writer.Write([]string{"session_id", "session_date", "session_start", "session_end", "active", "model", "entries",
+		"input_tokens", "output_tokens", "cache_creation_tokens", "cache_read_tokens", "total_tokens", "cost_usd", "tags"})
+	for _, row := range rows {
+		start := row.Start.In(loc)
+		_ = writer.Write([]string{
+			row.SessionID,
+			start.Format("2006-01-02"),
+			start.Format(time.RFC3339),
+			row.End.In(loc).Format(time.RFC3339),
+			strconv.FormatBool(row.Active),
+			row.Model,
+			strconv.Itoa(row.Entries),
+			strconv.Itoa(row.Tokens.InputTokens),
+			strconv.Itoa(row.Tokens.OutputTokens),
+			strconv.Itoa(row.Tokens.CacheCreationTokens),
+			strconv.Itoa(row.Tokens.CacheReadTokens),
+			strconv.Itoa(row.TotalTokens),
+			strconv.FormatFloat(row.CostUSD, 'f', 6, 64),
+			strings.Join(row.Tags, ";"),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
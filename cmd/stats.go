@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/penwyp/claudecat/calculations"
+	"github.com/penwyp/claudecat/humanize"
+	"github.com/penwyp/claudecat/internal"
+	"github.com/spf13/cobra"
+)
+
+var (
+	statsOutput     string
+	statsMilestones bool
+	statsJSON       bool
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats [path...]",
+	Short: "Show lifetime usage totals and milestones",
+	Long: `Show the totals of all recorded usage: first and last use, days with usage, entries, tokens
+and cost. With --milestones, also show the longest and current streaks of consecutive days with
+usage, the most expensive day, and badges earned at lifetime thresholds such as 1B tokens, $1K
+spent or a 30-day streak, with the day each was reached and the progress toward the next one.
+
+Days are in the configured timezone. The same milestones are shown by :about in the monitor.
+
+With --json, print instead the totals of today, this week and this month and the active session, as
+JSON for scripts and prompt widgets. Only the current month is loaded, through the summary cache, so
+the command returns in milliseconds once the cache is warm. The fields are versioned: a change that
+breaks a consumer raises "version". "active_session" is null when no session is running.
+
+Examples:
+  claudecat stats                          # Lifetime totals
+  claudecat stats --milestones             # Streaks and badges too
+  claudecat stats --milestones -o json     # As JSON
+  claudecat stats --json                   # Today, this week, this month and the active session`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if statsJSON {
+			if statsMilestones || cmd.Flags().Changed("output") {
+				return fmt.Errorf("--json cannot be combined with --milestones or --output")
+			}
+			return runQuickStats(cmd, args)
+		}
+		if !strings.EqualFold(statsOutput, "table") && !strings.EqualFold(statsOutput, "json") {
+			return fmt.Errorf("invalid output format: %s (valid: table, json)", statsOutput)
+		}
+		cfg, err := loadCacheCommandConfig(cmd, args)
+		if err != nil {
+			return err
+		}
+		analyzer, err := internal.NewAnalyzer(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create analyzer: %w", err)
+		}
+		milestones, err := analyzer.Milestones(cfg.Data.Paths, time.Now())
+		if err != nil {
+			return fmt.Errorf("analysis failed: %w", err)
+		}
+		recordCommandResult("days", milestones.ActiveDays)
+
+		if strings.EqualFold(statsOutput, "json") {
+			var data []byte
+			if statsMilestones {
+				data, err = sonic.MarshalIndent(milestones, "", "  ")
+			} else {
+				data, err = sonic.MarshalIndent(statsTotals{
+					FirstUse:     milestones.FirstUse,
+					LastUse:      milestones.LastUse,
+					ActiveDays:   milestones.ActiveDays,
+					TotalEntries: milestones.TotalEntries,
+					TotalTokens:  milestones.TotalTokens,
+					TotalCost:    milestones.TotalCost,
+				}, "", "  ")
+			}
+			if err != nil {
+				return err
+			}
+			_, err = os.Stdout.Write(append(data, '\n'))
+			return err
+		}
+
+		loc, err := time.LoadLocation(cfg.App.Timezone)
+		if err != nil {
+			loc = time.Local
+		}
+		printStats(milestones, statsMilestones, loc)
+		return nil
+	},
+}
+
+// statsTotals is the JSON output of stats without --milestones
+type statsTotals struct {
+	FirstUse     time.Time `json:"first_use,omitempty"`
+	LastUse      time.Time `json:"last_use,omitempty"`
+	ActiveDays   int       `json:"active_days"`
+	TotalEntries int       `json:"total_entries"`
+	TotalTokens  int       `json:"total_tokens"`
+	TotalCost    float64   `json:"total_cost"`
+}
+
+func init() {
+	statsCmd.Flags().StringVarP(&statsOutput, "output", "o", "table", "output format (table, json)")
+	statsCmd.Flags().BoolVar(&statsMilestones, "milestones", false, "show streaks, the busiest day and badges")
+	statsCmd.Flags().BoolVar(&statsJSON, "json", false, "print today, this week, this month and the active session as JSON")
+	rootCmd.AddCommand(statsCmd)
+}
+
+// runQuickStats prints the totals of the current periods and the active session as JSON
+func runQuickStats(cmd *cobra.Command, args []string) error {
+	cfg, err := loadCacheCommandConfig(cmd, args)
+	if err != nil {
+		return err
+	}
+	analyzer, err := internal.NewAnalyzer(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create analyzer: %w", err)
+	}
+	stats, err := analyzer.QuickStats(cfg.Data.Paths, time.Now())
+	if err != nil {
+		return fmt.Errorf("analysis failed: %w", err)
+	}
+	recordCommandResult("entries", stats.Month.Entries)
+
+	data, err := sonic.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(append(data, '\n'))
+	return err
+}
+
+// printStats prints the lifetime totals and, when requested, the streaks and badges
+func printStats(milestones calculations.Milestones, withMilestones bool, loc *time.Location) {
+	if milestones.ActiveDays == 0 {
+		fmt.Println("No usage found.")
+		return
+	}
+	fmt.Printf("First use:   %s\n", milestones.FirstUse.In(loc).Format("2006-01-02"))
+	fmt.Printf("Last use:    %s\n", milestones.LastUse.In(loc).Format("2006-01-02 15:04"))
+	fmt.Printf("Active days: %d\n", milestones.ActiveDays)
+	fmt.Printf("Entries:     %s\n", humanize.Count(milestones.TotalEntries))
+	fmt.Printf("Tokens:      %s\n", humanize.Count(milestones.TotalTokens))
+	fmt.Printf("Cost:        %s\n", humanize.Cost(milestones.TotalCost))
+	if !withMilestones {
+		return
+	}
+
+	fmt.Println()
+	fmt.Printf("Longest streak: %s\n", formatStreak(milestones.LongestStreak))
+	fmt.Printf("Current streak: %s\n", formatStreak(milestones.CurrentStreak))
+	fmt.Printf("Busiest day:    %s, %s\n", milestones.BusiestDay.Date.Format("2006-01-02"), humanize.Cost(milestones.BusiestDay.Cost))
+
+	fmt.Println()
+	table := newTableFormatter([]string{"Badge", "Reached"})
+	for _, badge := range milestones.Badges {
+		reached := "-"
+		if badge.Reached {
+			reached = badge.ReachedOn.Format("2006-01-02")
+		}
+		table.addRow([]string{badge.Name, reached})
+	}
+	fmt.Println(table.render())
+	if next, progress, ok := milestones.NextBadge(); ok {
+		fmt.Printf("Next badge: %s, %.0f%% of the way\n", next.Name, progress*100)
+	}
+}
+
+// formatStreak formats a streak as its length and days
+func formatStreak(streak calculations.Streak) string {
+	switch streak.Days {
+	case 0:
+		return "none"
+	case 1:
+		return fmt.Sprintf("1 day (%s)", streak.Start.Format("2006-01-02"))
+	default:
+		return fmt.Sprintf("%d days (%s to %s)", streak.Days, streak.Start.Format("2006-01-02"), streak.End.Format("2006-01-02"))
+	}
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/penwyp/claudecat/calculations"
+	"github.com/penwyp/claudecat/humanize"
+)
+
+// heatColors are the ANSI backgrounds of the heat levels: green, yellow and red
+var heatColors = map[calculations.HeatLevel]string{
+	calculations.HeatLow:    "\033[30;42m",
+	calculations.HeatMedium: "\033[30;43m",
+	calculations.HeatHigh:   "\033[97;41m",
+}
+
+// heatEnabled reports whether heat coloring was requested and neither --no-color nor NO_COLOR is set
+func heatEnabled(requested bool) bool {
+	return requested && !noColor && os.Getenv("NO_COLOR") == ""
+}
+
+// heatLevels ranks the cells of the token and cost columns by percentile within their column.
+// Only rows labeled in the first column are ranked, which leaves out separators, breakdown
+// rows and the TOTAL row.
+func (tf *tableFormatter) heatLevels() map[[2]int]calculations.HeatLevel {
+	levels := make(map[[2]int]calculations.HeatLevel)
+	for col := range tf.headers {
+		if !tf.isNumericColumn(col) {
+			continue
+		}
+		var cells [][2]int
+		var values []float64
+		for i, row := range tf.rows {
+			if label := row[0]; label == "" || label == "SEPARATOR" || strings.EqualFold(label, "total") {
+				continue
+			}
+			value, ok := parseHeatValue(row[col])
+			if !ok {
+				continue
+			}
+			cells = append(cells, [2]int{i, col})
+			values = append(values, value)
+		}
+		for i, level := range calculations.HeatLevels(values) {
+			levels[cells[i]] = level
+		}
+	}
+	return levels
+}
+
+// parseHeatValue parses a count or cost formatted in the current locale, such as "1,234" or "$5.67"
+func parseHeatValue(cell string) (float64, bool) {
+	locale := humanize.CurrentLocale()
+	cell = strings.NewReplacer(locale.Thousands, "", locale.Decimal, ".", "$", "").Replace(strings.TrimSpace(cell))
+	value, err := strconv.ParseFloat(cell, 64)
+	return value, err == nil
+}
+
+// heatCell colors a padded cell with the background of level
+func heatCell(cell string, level calculations.HeatLevel) string {
+	color, ok := heatColors[level]
+	if !ok {
+		return cell
+	}
+	return color + cell + "\033[0m"
+}
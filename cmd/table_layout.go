@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/penwyp/claudecat/terminal"
+)
+
+// Column priorities for narrow terminals; higher values are hidden first
+const (
+	columnPriorityEssential = iota
+	columnPriorityHigh
+	columnPriorityMedium
+	columnPriorityLow
+)
+
+// terminalWidth returns the width of stdout if it is a terminal, or 0 otherwise.
+// COLUMNS overrides the detected width.
+func terminalWidth() int {
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	return stdoutTerminalWidth()
+}
+
+// columnPriority ranks a column by header; the first column always stays visible
+func columnPriority(index int, header string) int {
+	if index == 0 {
+		return columnPriorityEssential
+	}
+
+	h := strings.ToLower(header)
+	switch {
+	case strings.Contains(h, "cost"), strings.Contains(h, "total"):
+		return columnPriorityHigh
+	case strings.Contains(h, "cache"), strings.Contains(h, "flags"), strings.Contains(h, "max"):
+		return columnPriorityLow
+	default:
+		return columnPriorityMedium
+	}
+}
+
+// totalWidth returns the rendered width of the bordered table
+func (tf *tableFormatter) totalWidth() int {
+	width := 1
+	for _, w := range tf.widths {
+		width += w + 3
+	}
+	return width
+}
+
+// withoutLowPriorityColumns drops the lowest-priority columns until the table fits.
+// It returns nil if more than half of the columns would have to go.
+func (tf *tableFormatter) withoutLowPriorityColumns() (*tableFormatter, []string) {
+	order := make([]int, len(tf.headers))
+	for i := range order {
+		order[i] = i
+	}
+	// Least important first; among equals, drop rightmost columns first
+	sort.SliceStable(order, func(a, b int) bool {
+		pa, pb := columnPriority(order[a], tf.headers[order[a]]), columnPriority(order[b], tf.headers[order[b]])
+		if pa != pb {
+			return pa > pb
+		}
+		return order[a] > order[b]
+	})
+
+	hidden := make(map[int]bool)
+	width := tf.totalWidth()
+	for _, idx := range order {
+		if width <= tf.maxWidth {
+			break
+		}
+		if columnPriority(idx, tf.headers[idx]) == columnPriorityEssential || len(hidden)+1 > len(tf.headers)/2 {
+			return nil, nil
+		}
+		hidden[idx] = true
+		width -= tf.widths[idx] + 3
+	}
+	if width > tf.maxWidth {
+		return nil, nil
+	}
+
+	var headers, hiddenNames []string
+	for i, header := range tf.headers {
+		if hidden[i] {
+			hiddenNames = append(hiddenNames, header)
+			continue
+		}
+		headers = append(headers, header)
+	}
+
+	narrowed := &tableFormatter{headers: headers, widths: make([]int, len(headers)), heat: tf.heat}
+	for _, row := range tf.rows {
+		var kept []string
+		for i, cell := range row {
+			if !hidden[i] {
+				kept = append(kept, cell)
+			}
+		}
+		narrowed.rows = append(narrowed.rows, kept)
+	}
+	return narrowed, hiddenNames
+}
+
+// renderStacked renders each row as a block of "key: value" lines
+func (tf *tableFormatter) renderStacked() string {
+	labelWidth := 0
+	for _, header := range tf.headers {
+		if w := runeWidth(header); w > labelWidth {
+			labelWidth = w
+		}
+	}
+
+	divider := terminal.Text(strings.Repeat("─", min(tf.maxWidth, 40)))
+	var lines []string
+	for _, row := range tf.rows {
+		if len(row) > 0 && row[0] == "SEPARATOR" {
+			continue
+		}
+		if len(lines) > 0 {
+			lines = append(lines, divider)
+		}
+		for i, cell := range row {
+			if i < len(tf.headers) {
+				lines = append(lines, tf.padCell(tf.headers[i]+":", labelWidth+1, false)+" "+cell)
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/penwyp/claudecat/terminal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColumnPriority(t *testing.T) {
+	tests := []struct {
+		index  int
+		header string
+		want   int
+	}{
+		{0, "Cache Read", columnPriorityEssential},
+		{1, "Total Cost", columnPriorityHigh},
+		{2, "Total Tokens", columnPriorityHigh},
+		{3, "Cache Write", columnPriorityLow},
+		{4, "Flags", columnPriorityLow},
+		{5, "Max Burn", columnPriorityLow},
+		{6, "Model", columnPriorityMedium},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			assert.Equal(t, tt.want, columnPriority(tt.index, tt.header))
+		})
+	}
+}
+
+func TestTerminalWidth_Columns(t *testing.T) {
+	t.Setenv("COLUMNS", "72")
+	assert.Equal(t, 72, terminalWidth())
+}
+
+func TestTableFormatter_NarrowLayouts(t *testing.T) {
+	headers := []string{"Date", "Model", "Input", "Output", "Cache Write", "Cache Read", "Total Cost"}
+	row := []string{"2025-06-01", "claude-sonnet-4", "1,200", "3,400", "52,000", "910,000", "$12.34"}
+
+	tests := []struct {
+		name     string
+		maxWidth int
+		hidden   []string // Columns reported hidden; nil when the table is not narrowed
+		stacked  bool
+	}{
+		{"fits", 0, nil, false},
+		{"drops cache columns first", 70, []string{"Cache Write", "Cache Read"}, false},
+		{"stacks rows when too many columns would go", 30, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tf := &tableFormatter{headers: headers, widths: make([]int, len(headers)), maxWidth: tt.maxWidth}
+			tf.addRow(append([]string(nil), row...))
+			out := tf.render()
+
+			switch {
+			case tt.stacked:
+				assert.Contains(t, out, "Cache Read:  910,000", "labels are padded to the widest header")
+				assert.NotContains(t, out, "hidden columns")
+			case tt.hidden != nil:
+				assert.Contains(t, out, "(hidden columns: "+strings.Join(tt.hidden, ", ")+";")
+				for _, header := range tt.hidden {
+					assert.NotContains(t, strings.SplitN(out, "(hidden", 2)[0], header)
+				}
+				assert.Contains(t, out, "$12.34", "high-priority columns stay")
+			default:
+				assert.Contains(t, out, "Cache Read")
+				assert.NotContains(t, out, "hidden columns")
+			}
+			if tt.maxWidth > 0 && !tt.stacked {
+				for _, line := range strings.Split(strings.SplitN(out, "\n(hidden", 2)[0], "\n") {
+					assert.LessOrEqual(t, terminal.VisibleWidth(line), tt.maxWidth, line)
+				}
+			}
+		})
+	}
+}
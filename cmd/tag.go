@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/penwyp/claudecat/internal"
+	"github.com/spf13/cobra"
+)
+
+var (
+	tagNote   string
+	tagRemove bool
+	tagOutput string
+	tagPaths  []string
+)
+
+var tagCmd = &cobra.Command{
+	Use:   "tag <session-id> [tag...]",
+	Short: "Attach tags and a note to a session",
+	Long: `Attach tags and a note to a session so that its cost can be attributed to work items. Tags show
+up in sessions, blocks and report output, and analyze --group-by tag totals usage per tag.
+
+The session is named by the ID analyze --group-by session shows, such as session_2025-06-01_10, or by
+the ID sessions -o json lists. Tags are stored in session_tags.json under cache.dir, encrypted
+like the cache when cache.encryption is on, and cannot contain commas or spaces. A session with
+several tags is grouped under all of them joined, so that its cost is counted once.
+
+Examples:
+  claudecat tag session_2025-06-01_10 feature-x                   # Tag a session
+  claudecat tag session_2025-06-01_10 --note "Login page redesign"
+  claudecat tag session_2025-06-01_10 feature-x --remove          # Remove a tag
+  claudecat tag session_2025-06-01_10 --remove                    # Remove every tag and the note
+  claudecat tag list                                              # Show tagged sessions
+  claudecat analyze --group-by tag                                # Cost per tag`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeTagArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, labels := args[0], args[1:]
+		for _, label := range labels {
+			if err := internal.ValidateTagLabel(label); err != nil {
+				return err
+			}
+		}
+		var note *string
+		if cmd.Flags().Changed("note") {
+			if tagRemove {
+				return fmt.Errorf("--note cannot be combined with --remove")
+			}
+			note = &tagNote
+		}
+		if !tagRemove && len(labels) == 0 && note == nil {
+			return fmt.Errorf("give at least one tag, --note or --remove")
+		}
+
+		cfg, err := loadCacheCommandConfig(cmd, tagPaths)
+		if err != nil {
+			return err
+		}
+		analyzer, err := internal.NewAnalyzer(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create analyzer: %w", err)
+		}
+		start, err := analyzer.ResolveSessionStart(cfg.Data.Paths, id)
+		if err != nil {
+			return err
+		}
+
+		store, err := internal.OpenTagStore(cfg)
+		if err != nil {
+			return err
+		}
+		tags, err := store.Load()
+		if err != nil {
+			return err
+		}
+		if tagRemove {
+			if !tags.Untag(start, labels) {
+				fmt.Printf("Session %s has nothing to remove\n", id)
+				return nil
+			}
+		} else {
+			tags.Tag(start, labels, note)
+		}
+		if err := store.Save(tags); err != nil {
+			return err
+		}
+
+		session, ok := tags.Get(start)
+		switch {
+		case !ok:
+			fmt.Printf("Removed the tags of session %s\n", id)
+		case len(session.Labels) == 0:
+			fmt.Printf("Session %s has no tags\n", id)
+		default:
+			fmt.Printf("Session %s is tagged %s\n", id, strings.Join(session.Labels, ", "))
+		}
+		return nil
+	},
+}
+
+var tagListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List tagged sessions",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !strings.EqualFold(tagOutput, "table") && !strings.EqualFold(tagOutput, "json") {
+			return fmt.Errorf("invalid output format: %s (valid: table, json)", tagOutput)
+		}
+		cfg, err := loadCacheCommandConfig(cmd, nil)
+		if err != nil {
+			return err
+		}
+		store, err := internal.OpenTagStore(cfg)
+		if err != nil {
+			return err
+		}
+		tags, err := store.Load()
+		if err != nil {
+			return err
+		}
+		list := tags.List()
+		recordCommandResult("sessions", len(list))
+
+		if strings.EqualFold(tagOutput, "json") {
+			data, err := sonic.MarshalIndent(list, "", "  ")
+			if err != nil {
+				return err
+			}
+			_, err = os.Stdout.Write(append(data, '\n'))
+			return err
+		}
+		if len(list) == 0 {
+			fmt.Println("No tagged sessions")
+			return nil
+		}
+		loc, err := time.LoadLocation(cfg.App.Timezone)
+		if err != nil {
+			loc = time.Local
+		}
+		table := newTableFormatter([]string{"Session", "Start", "Tags", "Note"})
+		for _, session := range list {
+			table.addRow([]string{
+				session.ID(),
+				session.Start.In(loc).Format("2006-01-02 15:04"),
+				strings.Join(session.Labels, ", "),
+				session.Note,
+			})
+		}
+		fmt.Println(table.render())
+		return nil
+	},
+}
+
+func init() {
+	tagCmd.Flags().StringVar(&tagNote, "note", "", "note attached to the session; an empty note clears it")
+	tagCmd.Flags().StringSliceVarP(&tagPaths, "paths", "p", nil, "data paths to find session IDs in (default: the configured paths)")
+	tagCmd.Flags().BoolVar(&tagRemove, "remove", false, "remove the given tags, or every tag and the note when none are given")
+	tagListCmd.Flags().StringVarP(&tagOutput, "output", "o", "table", "output format (table, json)")
+	tagCmd.AddCommand(tagListCmd)
+	rootCmd.AddCommand(tagCmd)
+}
@@ -0,0 +1,8 @@
+//go:build !(darwin || linux || freebsd || netbsd || openbsd)
+
+package cmd
+
+// stdoutTerminalWidth is not supported on this platform; set COLUMNS to enable narrow layouts
+func stdoutTerminalWidth() int {
+	return 0
+}
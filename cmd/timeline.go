@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/penwyp/claudecat/calculations"
+	"github.com/penwyp/claudecat/humanize"
+	"github.com/penwyp/claudecat/internal"
+	"github.com/penwyp/claudecat/terminal"
+	"github.com/spf13/cobra"
+)
+
+var (
+	timelineProject string
+	timelineOutput  string
+	timelineIdle    bool
+)
+
+// timelineStripWidth is the number of days per line of the activity strip
+const timelineStripWidth = 60
+
+// timelineLevels are the strip characters from lowest to highest daily cost
+var timelineLevels = []rune("▁▂▃▄▅▆▇█")
+
+var timelineCmd = &cobra.Command{
+	Use:   "timeline [path...]",
+	Short: "Show the day-by-day activity of one project",
+	Long: `Show the lifetime activity of a project: first and last usage, total spend, a strip with
+one character per day scaled to that day's cost, and a table of daily entries, tokens and cost.
+Projects active for longer than the strip is wide are charted per week or month instead.
+
+Examples:
+  claudecat timeline --project webapp           # Active days of the webapp project
+  claudecat timeline --project webapp --idle    # Include idle days in the table
+  claudecat timeline --project webapp -o json   # Machine-readable output`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !strings.EqualFold(timelineOutput, "table") && !strings.EqualFold(timelineOutput, "json") {
+			return fmt.Errorf("invalid output format: %s (valid: table, json)", timelineOutput)
+		}
+		if timelineProject == "" {
+			return fmt.Errorf("--project is required")
+		}
+
+		cfg, err := loadCacheCommandConfig(cmd, args)
+		if err != nil {
+			return err
+		}
+		analyzer, err := internal.NewAnalyzer(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create analyzer: %w", err)
+		}
+		results, err := analyzer.Analyze(cfg.Data.Paths)
+		if err != nil {
+			return fmt.Errorf("analysis failed: %w", err)
+		}
+
+		loc, err := time.LoadLocation(cfg.App.Timezone)
+		if err != nil {
+			loc = time.Local
+		}
+		timeline, ok := calculations.BuildProjectTimeline(results, timelineProject, loc)
+		if !ok {
+			return fmt.Errorf("no usage found for project %s (known projects: %s)",
+				timelineProject, strings.Join(calculations.ProjectNames(results), ", "))
+		}
+		recordCommandResult("days", timeline.ActiveDays)
+
+		if strings.EqualFold(timelineOutput, "json") {
+			data, err := sonic.MarshalIndent(timeline, "", "  ")
+			if err != nil {
+				return err
+			}
+			_, err = os.Stdout.Write(append(data, '\n'))
+			return err
+		}
+
+		printProjectTimeline(timeline)
+		return nil
+	},
+}
+
+func init() {
+	timelineCmd.Flags().StringVarP(&timelineProject, "project", "p", "", "project name (case-insensitive)")
+	_ = timelineCmd.RegisterFlagCompletionFunc("project", completeProjects)
+	timelineCmd.Flags().StringVarP(&timelineOutput, "output", "o", "table", "output format (table, json)")
+	timelineCmd.Flags().BoolVar(&timelineIdle, "idle", false, "include idle days in the table")
+	rootCmd.AddCommand(timelineCmd)
+}
+
+// printProjectTimeline prints the lifetime summary, the activity strip and the daily table
+func printProjectTimeline(timeline calculations.ProjectTimeline) {
+	loc := timeline.Days[0].Date.Location()
+	fmt.Printf("Project %s\n", timeline.Project)
+	fmt.Printf("  First usage: %s\n", timeline.FirstUsage.In(loc).Format("2006-01-02 15:04"))
+	fmt.Printf("  Last usage:  %s\n", timeline.LastUsage.In(loc).Format("2006-01-02 15:04"))
+	fmt.Printf("  Lifetime:    %s over %d active of %d days, %s entries, %s tokens\n\n",
+		humanize.Cost(timeline.TotalCost), timeline.ActiveDays, len(timeline.Days),
+		humanize.Count(timeline.Entries), humanize.Count(timeline.Tokens))
+
+	for _, line := range renderTimelineStrip(timeline.Days) {
+		fmt.Println(line)
+	}
+	fmt.Println()
+
+	table := newTableFormatter([]string{"Date", "Entries", "Total Tokens", "Cost (USD)"})
+	for _, day := range timeline.Days {
+		if day.Entries == 0 && !timelineIdle {
+			continue
+		}
+		table.addRow([]string{
+			day.Date.Format("2006-01-02 Mon"),
+			humanize.Count(day.Entries),
+			humanize.Count(day.Tokens),
+			humanize.Cost(day.Cost),
+		})
+	}
+	fmt.Println(table.render())
+}
+
+// renderTimelineStrip draws one character per day scaled to the busiest day, with "·" for idle days.
+// Ranges wider than the strip are rolled up into weeks or months, noted on the last line.
+// Each line starts with the date of its first point.
+func renderTimelineStrip(days []calculations.TimelineDay) []string {
+	points, granularity := calculations.RollupTimelineDays(days, timelineStripWidth)
+	maxCost := 0.0
+	for _, point := range points {
+		if point.Cost > maxCost {
+			maxCost = point.Cost
+		}
+	}
+
+	var lines []string
+	for start := 0; start < len(points); start += timelineStripWidth {
+		end := min(start+timelineStripWidth, len(points))
+		var b strings.Builder
+		b.WriteString(points[start].Date.Format("2006-01-02") + " ")
+		for _, point := range points[start:end] {
+			switch {
+			case point.Entries == 0:
+				b.WriteRune('·')
+			case maxCost <= 0:
+				b.WriteRune(timelineLevels[0])
+			default:
+				level := int(point.Cost / maxCost * float64(len(timelineLevels)-1))
+				b.WriteRune(timelineLevels[level])
+			}
+		}
+		lines = append(lines, terminal.Text(b.String()))
+	}
+	if granularity != calculations.GranularityDay {
+		lines = append(lines, fmt.Sprintf("%s 1 character = 1 %s (%d days in %d %ss)",
+			strings.Repeat(" ", len("2006-01-02")), granularity, len(days), len(points), granularity))
+	}
+	return lines
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/penwyp/claudecat/internal"
+	"github.com/penwyp/claudecat/logging"
+	"github.com/spf13/cobra"
+)
+
+var (
+	topInterval time.Duration
+	topOneLine  bool
+	topOnce     bool
+)
+
+var topCmd = &cobra.Command{
+	Use:   "top [path]",
+	Short: "Print a live plain-text summary of the active session",
+	Long: `Monitor usage without the full-screen display, printing the tokens, cost, burn rate and time to
+reset of the active session after every refresh. In a terminal the summary is redrawn in place;
+when piped, each refresh is printed on new lines.
+
+The second line shows today's totals, the models of the session and the time of the last update.
+Use --one-line for narrow panes and --once for status bars that run a command periodically.
+
+Examples:
+  claudecat top                                     # two lines, redrawn every 10s
+  claudecat top --one-line --interval 30s
+  set -g status-right '#(claudecat top --once --one-line)'   # tmux status bar`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfiguration(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		if len(args) > 0 {
+			if _, err := os.Stat(args[0]); err != nil {
+				return fmt.Errorf("path does not exist: %s", args[0])
+			}
+			cfg.Data.Paths = args
+		}
+		if topInterval < 0 {
+			return fmt.Errorf("--interval must be positive")
+		}
+
+		logging.InitLogger(cfg.App.LogLevel, cfg.App.LogFile, cfg.Debug.Enabled)
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		return internal.RunTop(ctx, cfg, internal.TopOptions{
+			Interval: topInterval,
+			OneLine:  topOneLine,
+			Once:     topOnce,
+			Rewrite:  stdoutIsTerminal(),
+		}, os.Stdout)
+	},
+}
+
+// stdoutIsTerminal reports whether stdout is a terminal rather than a pipe or file
+func stdoutIsTerminal() bool {
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func init() {
+	topCmd.Flags().DurationVar(&topInterval, "interval", 10*time.Second, "time between data refreshes")
+	topCmd.Flags().BoolVar(&topOneLine, "one-line", false, "print a single line instead of two")
+	topCmd.Flags().BoolVar(&topOnce, "once", false, "print the summary once and exit")
+	rootCmd.AddCommand(topCmd)
+}
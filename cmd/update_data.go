@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/logging"
+	"github.com/penwyp/claudecat/models/pricing"
+	"github.com/spf13/cobra"
+)
+
+var (
+	updateDataURL       string
+	updateDataPublicKey string
+	updateDataCheck     bool
+	updateDataForce     bool
+)
+
+var updateDataCmd = &cobra.Command{
+	Use:   "update-data",
+	Short: "Refresh model pricing and plan limits from signed release data",
+	Long: `Download the latest pricing and plan limit tables published with claudecat releases,
+verify their ed25519 signature and install them into the cache directory. Installed
+tables are used instead of the built-in ones without upgrading the binary.
+
+Bundles are only installed when their signature matches the release signing key and
+their version is newer than the installed one.
+
+Examples:
+  claudecat update-data            # Install the latest data bundle
+  claudecat update-data --check    # Only report whether an update is available`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfiguration(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		logging.InitLogger(cfg.App.LogLevel, cfg.App.LogFile, cfg.Debug.Enabled)
+
+		if updateDataURL != "" {
+			if err := config.ValidateUpdateURL(updateDataURL); err != nil {
+				return fmt.Errorf("invalid --url: %w", err)
+			}
+			cfg.Data.UpdateURL = updateDataURL
+		}
+		if updateDataPublicKey != "" {
+			cfg.Data.UpdatePublicKey = updateDataPublicKey
+		}
+
+		publicKey, err := pricing.ResolveDataPublicKey(cfg.Data.UpdatePublicKey)
+		if err != nil {
+			return err
+		}
+
+		cacheDir := config.ExpandHome(cfg.Cache.Dir)
+		updater := pricing.NewDataUpdater(cfg.Data.UpdateURL, publicKey, cacheDir)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		bundle, data, signature, err := updater.Fetch(ctx)
+		if err != nil {
+			return err
+		}
+
+		installed := 0
+		if current, err := pricing.LoadDataBundle(cacheDir, publicKey); err == nil {
+			installed = current.Version
+		}
+
+		if updateDataCheck {
+			if bundle.Version > installed {
+				fmt.Printf("Update available: data v%d (installed: v%d)\n", bundle.Version, installed)
+			} else {
+				fmt.Printf("Data is up to date (v%d)\n", installed)
+			}
+			return nil
+		}
+
+		if bundle.Version <= installed && !updateDataForce {
+			fmt.Printf("Data is up to date (v%d)\n", installed)
+			return nil
+		}
+
+		if err := updater.Install(bundle, data, signature, updateDataForce); err != nil {
+			return fmt.Errorf("failed to install data bundle: %w", err)
+		}
+
+		recordCommandResult("version", bundle.Version)
+		fmt.Printf("Installed data v%d (published %s): %d model prices, %d plan limits\n",
+			bundle.Version, bundle.Published.Format("2006-01-02"), len(bundle.Pricing), len(bundle.Limits))
+		return nil
+	},
+}
+
+func init() {
+	updateDataCmd.Flags().StringVar(&updateDataURL, "url", "", "data bundle URL (default: latest release asset)")
+	updateDataCmd.Flags().StringVar(&updateDataPublicKey, "public-key", "", "base64 ed25519 key used to verify the bundle signature")
+	updateDataCmd.Flags().BoolVar(&updateDataCheck, "check", false, "only check whether newer data is available")
+	updateDataCmd.Flags().BoolVar(&updateDataForce, "force", false, "reinstall even if the version is not newer")
+
+	rootCmd.AddCommand(updateDataCmd)
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/penwyp/claudecat/internal"
+	"github.com/spf13/cobra"
+)
+
+var (
+	validateOutput string
+	validateChecks []string
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate [path...]",
+	Short: "Check that cached, session and daily totals agree with a fresh parse",
+	Long: `Check the consistency of the numbers claudecat reports: every check computes the usage of the
+data paths one way and compares the entries, tokens and cost with a fresh parse of the usage files:
+
+  cache     the cached file summaries, which most commands read instead of the files
+  sessions  the 5-hour session blocks of the monitor and the blocks command
+  daily     the daily grouping of analyze, in the configured timezone
+
+The results use the format of the doctor command. The exit status is 1 when a check fails and 2
+when the checks cannot run, for example because no usage files were found.
+
+Examples:
+  claudecat validate
+  claudecat validate --checks cache ~/claude-logs
+  claudecat validate -o json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output := strings.ToLower(validateOutput)
+		if output != "table" && output != "json" {
+			return fmt.Errorf("invalid output format: %s (valid: table, json)", validateOutput)
+		}
+
+		cfg, err := loadCacheCommandConfig(cmd, args)
+		if err != nil {
+			return validateError(err)
+		}
+		checks, err := internal.NewValidator(cfg).Run(validateChecks)
+		if err != nil {
+			return validateError(err)
+		}
+		failed := 0
+		for _, check := range checks {
+			if check.Status == internal.DoctorFail {
+				failed++
+			}
+		}
+		recordCommandResult("failed", failed)
+
+		if output == "json" {
+			data, err := sonic.MarshalIndent(checks, "", "  ")
+			if err != nil {
+				return err
+			}
+			if _, err := os.Stdout.Write(append(data, '\n')); err != nil {
+				return err
+			}
+		} else {
+			printDoctorChecks(checks)
+		}
+
+		if failed > 0 {
+			return &ExitCodeError{Code: 1, Reason: fmt.Sprintf("%d checks failed", failed)}
+		}
+		return nil
+	},
+}
+
+func init() {
+	validateCmd.Flags().StringVarP(&validateOutput, "output", "o", "table", "output format (table, json)")
+	validateCmd.Flags().StringSliceVar(&validateChecks, "checks", nil, "checks to run ("+strings.Join(internal.ValidateChecks, ", ")+"; default all)")
+	rootCmd.AddCommand(validateCmd)
+}
+
+// validateError reports err, which kept the checks from running, and exits with status 2
+func validateError(err error) error {
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	return &ExitCodeError{Code: 2, Reason: err.Error()}
+}
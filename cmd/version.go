@@ -6,6 +6,7 @@ import (
 	"runtime"
 
 	"github.com/bytedance/sonic"
+	"github.com/penwyp/claudecat/internal"
 	"github.com/spf13/cobra"
 )
 
@@ -29,6 +30,7 @@ type VersionInfo struct {
 	OS        string `json:"os"`
 	Arch      string `json:"arch"`
 	Compiler  string `json:"compiler"`
+	Lite      bool   `json:"lite"` // Built with -tags lite (no interactive monitor)
 }
 
 var versionCmd = &cobra.Command{
@@ -44,6 +46,7 @@ var versionCmd = &cobra.Command{
 			OS:        runtime.GOOS,
 			Arch:      runtime.GOARCH,
 			Compiler:  runtime.Compiler,
+			Lite:      internal.LiteBuild,
 		}
 
 		switch versionOutput {
@@ -76,6 +79,9 @@ func outputVersionDefault(info VersionInfo) error {
 	fmt.Printf("Go Version:  %s\n", info.GoVersion)
 	fmt.Printf("OS/Arch:     %s/%s\n", info.OS, info.Arch)
 	fmt.Printf("Compiler:    %s\n", info.Compiler)
+	if info.Lite {
+		fmt.Printf("Build:       lite (no interactive monitor)\n")
+	}
 	return nil
 }
 
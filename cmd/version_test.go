@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureStdout returns what fn writes to stdout
+func captureStdout(t *testing.T, fn func() error) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	require.NoError(t, fn())
+	require.NoError(t, w.Close())
+	out, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(out)
+}
+
+func TestOutputVersion_Lite(t *testing.T) {
+	tests := []struct {
+		name string
+		lite bool
+	}{
+		{"full build", false},
+		{"lite build", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := VersionInfo{Version: "1.2.3", GitCommit: "unknown", BuildTime: "unknown", Lite: tt.lite}
+
+			out := captureStdout(t, func() error { return outputVersionDefault(info) })
+			if tt.lite {
+				assert.Contains(t, out, "Build:       lite (no interactive monitor)")
+			} else {
+				assert.NotContains(t, out, "lite")
+			}
+
+			var decoded map[string]any
+			require.NoError(t, json.Unmarshal([]byte(captureStdout(t, func() error { return outputVersionJSON(info) })), &decoded))
+			assert.Equal(t, tt.lite, decoded["lite"])
+		})
+	}
+}
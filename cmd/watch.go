@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/penwyp/claudecat/internal"
+	"github.com/penwyp/claudecat/logging"
+	"github.com/spf13/cobra"
+)
+
+var (
+	watchInterval time.Duration
+	watchExec     string
+	watchWebhook  string
+	watchEvents   []string
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch [path]",
+	Short: "Monitor usage headlessly and fire a webhook or command on events",
+	Long: fmt.Sprintf(`Run the realtime monitor without a display and deliver an event for every data refresh,
+session cost threshold crossed, session started or ended and alert rule fired. Each event is
+printed as a JSON line, posted to --webhook and piped to --exec, one event at a time. The webhook
+URL and command are masked in the command history.
+
+Events:
+  update         Every refresh, with the active session
+  limit          The session crossed subscription.warn_threshold or alert_threshold of the plan's
+                 cost limit, or Claude logged a limit message
+  session_start  A session became active; the session active at startup is reported too
+  session_end    The active session ended
+  alert          A rule of alerts.rules started firing
+
+The --exec command runs through the shell with the event JSON on stdin and CLAUDECAT_EVENT,
+CLAUDECAT_SESSION_ID, CLAUDECAT_MESSAGE, CLAUDECAT_TOKENS and CLAUDECAT_COST_USD set. Webhook
+requests and commands time out after 30s; failures are reported and watching continues.
+
+Examples:
+  claudecat watch                                               # JSON lines on stdout
+  claudecat watch --events limit,session_start --webhook https://hooks.example.com/claude
+  claudecat watch --events limit --exec 'notify-send "claudecat" "$CLAUDECAT_MESSAGE"'
+  claudecat watch --interval 1m --exec 'jq -c .session >> ~/claude-usage.jsonl'
+
+Valid --events: %s`, strings.Join(internal.WatchEventTypes, ", ")),
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfiguration(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		if len(args) > 0 {
+			if _, err := os.Stat(args[0]); err != nil {
+				return fmt.Errorf("path does not exist: %s", args[0])
+			}
+			cfg.Data.Paths = args
+		}
+		if watchInterval < 0 {
+			return fmt.Errorf("--interval must be positive")
+		}
+		if err := internal.ValidateWatchEvents(watchEvents); err != nil {
+			return err
+		}
+		if watchWebhook != "" {
+			if u, err := url.Parse(watchWebhook); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("invalid webhook URL: %s", watchWebhook)
+			}
+		}
+
+		logging.InitLogger(cfg.App.LogLevel, cfg.App.LogFile, cfg.Debug.Enabled)
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		fmt.Fprintln(os.Stderr, "Watching usage (press Ctrl+C to stop)")
+		return internal.RunWatch(ctx, cfg, internal.WatchOptions{
+			Interval: watchInterval,
+			Exec:     watchExec,
+			Webhook:  watchWebhook,
+			Events:   watchEvents,
+		}, os.Stdout)
+	},
+}
+
+func init() {
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", 10*time.Second, "time between data refreshes")
+	watchCmd.Flags().StringVar(&watchExec, "exec", "", "shell command run for every event with the event JSON on stdin")
+	watchCmd.Flags().StringVar(&watchWebhook, "webhook", "", "URL the event JSON is posted to")
+	watchCmd.Flags().StringSliceVar(&watchEvents, "events", nil, "event types to deliver, comma-separated (default all)")
+	markHistorySecret(watchCmd.Flags(), "exec", "webhook")
+	rootCmd.AddCommand(watchCmd)
+}
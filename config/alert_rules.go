@@ -0,0 +1,187 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Metrics of alert rules
+const (
+	RuleDailyCost        = "daily_cost"         // USD spent today
+	RuleDailyTokens      = "daily_tokens"       // Tokens used today
+	RuleWeeklyCost       = "weekly_cost"        // USD spent since Monday
+	RuleMonthlyCost      = "monthly_cost"       // USD spent since the first of the month
+	RuleSessionCost      = "session_cost"       // USD of the active session
+	RuleSessionTokens    = "session_tokens"     // Tokens of the active session
+	RuleSessionMessages  = "session_messages"   // Messages sent in the active session
+	RuleSessionCostPct   = "session_cost_pct"   // Percent of the plan's session cost limit used
+	RuleSessionTokensPct = "session_tokens_pct" // Percent of the plan's session token limit used
+	RuleBurnRate         = "burn_rate"          // Tokens per minute over the rule's window
+	RuleCostRate         = "cost_rate"          // USD per hour over the rule's window
+)
+
+// RuleMetrics lists the metrics of alert rules
+var RuleMetrics = []string{
+	RuleDailyCost, RuleDailyTokens, RuleWeeklyCost, RuleMonthlyCost,
+	RuleSessionCost, RuleSessionTokens, RuleSessionMessages, RuleSessionCostPct, RuleSessionTokensPct,
+	RuleBurnRate, RuleCostRate,
+}
+
+// RuleMetricDescriptions describes each of RuleMetrics, for help output
+var RuleMetricDescriptions = map[string]string{
+	RuleDailyCost:        "USD spent today",
+	RuleDailyTokens:      "tokens used today",
+	RuleWeeklyCost:       "USD spent since Monday",
+	RuleMonthlyCost:      "USD spent since the first of the month",
+	RuleSessionCost:      "USD of the active session",
+	RuleSessionTokens:    "tokens of the active session",
+	RuleSessionMessages:  "messages sent in the active session",
+	RuleSessionCostPct:   "percent of the plan's session cost limit used by the active session",
+	RuleSessionTokensPct: "percent of the plan's session token limit used by the active session",
+	RuleBurnRate:         "tokens per minute over the last 5m, or the for window",
+	RuleCostRate:         "USD per hour over the last 5m, or the for window",
+}
+
+// RulePercentiles are the thresholds relative to past usage: the percentile of completed sessions
+// for session metrics and of past days for daily metrics
+var RulePercentiles = []string{"p50", "p75", "p90", "p99"}
+
+// DefaultRuleWindow is the window of a rate rule without a for clause
+const DefaultRuleWindow = 5 * time.Minute
+
+// AlertRule is a parsed alert rule: Metric Op Threshold, such as daily_cost > 20
+type AlertRule struct {
+	Name       string        `json:"name"`
+	Metric     string        `json:"metric"`
+	Op         string        `json:"op"`
+	Threshold  float64       `json:"threshold,omitempty"`
+	Percentile string        `json:"percentile,omitempty"` // Set instead of Threshold, e.g. p90
+	Window     time.Duration `json:"window,omitempty"`     // Rate metrics: the trailing window averaged
+	Level      string        `json:"level"`                // warning or error
+}
+
+// Compare reports whether value satisfies the rule's condition against threshold
+func (r AlertRule) Compare(value, threshold float64) bool {
+	switch r.Op {
+	case ">":
+		return value > threshold
+	case ">=":
+		return value >= threshold
+	case "<":
+		return value < threshold
+	default:
+		return value <= threshold
+	}
+}
+
+// IsRate reports whether the rule's metric is a rate averaged over a window
+func (r AlertRule) IsRate() bool {
+	return r.Metric == RuleBurnRate || r.Metric == RuleCostRate
+}
+
+// Condition formats the rule's condition in the syntax ParseAlertRule reads
+func (r AlertRule) Condition() string {
+	value := r.Percentile
+	if value == "" {
+		value = strconv.FormatFloat(r.Threshold, 'f', -1, 64)
+	}
+	condition := r.Metric + " " + r.Op + " " + value
+	if r.IsRate() {
+		window := strings.TrimSuffix(r.Window.String(), "0s")
+		if strings.HasSuffix(window, "h0m") {
+			window = strings.TrimSuffix(window, "0m")
+		}
+		condition += " for " + window
+	}
+	return condition
+}
+
+// ParseAlertRule parses a configured rule. The condition is "<metric> <op> <value> [for <duration>]":
+// the value is a number, optionally with a $ prefix or k or M suffix, or a percentile such as p90,
+// and the for clause sets the window of a rate metric.
+func ParseAlertRule(rule AlertRuleConfig) (AlertRule, error) {
+	fields := strings.Fields(strings.ToLower(rule.When))
+	if len(fields) != 3 && len(fields) != 5 {
+		return AlertRule{}, fmt.Errorf("invalid condition %q (expected \"<metric> <op> <value> [for <duration>]\")", rule.When)
+	}
+	parsed := AlertRule{Name: rule.Name, Metric: fields[0], Op: fields[1], Level: strings.ToLower(rule.Level)}
+	if parsed.Name == "" {
+		parsed.Name = strings.Join(strings.Fields(rule.When), " ")
+	}
+	if parsed.Level == "" {
+		parsed.Level = "warning"
+	}
+	if parsed.Level != "warning" && parsed.Level != "error" {
+		return AlertRule{}, fmt.Errorf("invalid level: %s (valid: warning, error)", rule.Level)
+	}
+	if !slices.Contains(RuleMetrics, parsed.Metric) {
+		return AlertRule{}, fmt.Errorf("unknown metric: %s (valid: %s)", fields[0], strings.Join(RuleMetrics, ", "))
+	}
+	if !slices.Contains([]string{">", ">=", "<", "<="}, parsed.Op) {
+		return AlertRule{}, fmt.Errorf("invalid operator: %s (valid: >, >=, <, <=)", fields[1])
+	}
+
+	if slices.Contains(RulePercentiles, fields[2]) {
+		if parsed.IsRate() || slices.Contains([]string{RuleWeeklyCost, RuleMonthlyCost, RuleSessionCostPct, RuleSessionTokensPct}, parsed.Metric) {
+			return AlertRule{}, fmt.Errorf("%s cannot be compared with a percentile", parsed.Metric)
+		}
+		parsed.Percentile = fields[2]
+	} else {
+		threshold, err := parseRuleValue(fields[2])
+		if err != nil {
+			return AlertRule{}, err
+		}
+		parsed.Threshold = threshold
+	}
+
+	if parsed.IsRate() {
+		parsed.Window = DefaultRuleWindow
+	}
+	if len(fields) == 5 {
+		if fields[3] != "for" {
+			return AlertRule{}, fmt.Errorf("invalid condition %q (expected \"for <duration>\" after the value)", rule.When)
+		}
+		if !parsed.IsRate() {
+			return AlertRule{}, fmt.Errorf("for applies to rates only (%s, %s)", RuleBurnRate, RuleCostRate)
+		}
+		window, err := time.ParseDuration(fields[4])
+		if err != nil || window < time.Minute {
+			return AlertRule{}, fmt.Errorf("invalid window: %s (must be a duration of at least 1m)", fields[4])
+		}
+		parsed.Window = window
+	}
+	return parsed, nil
+}
+
+// ParseAlertRules parses every configured rule, reporting the first invalid one
+func ParseAlertRules(rules []AlertRuleConfig) ([]AlertRule, error) {
+	parsed := make([]AlertRule, 0, len(rules))
+	for i, rule := range rules {
+		r, err := ParseAlertRule(rule)
+		if err != nil {
+			return nil, fmt.Errorf("rules[%d]: %w", i, err)
+		}
+		parsed = append(parsed, r)
+	}
+	return parsed, nil
+}
+
+// parseRuleValue parses a threshold such as 20, $20, 5000, 5k or 1.5M
+func parseRuleValue(s string) (float64, error) {
+	text := strings.ReplaceAll(strings.TrimPrefix(s, "$"), ",", "")
+	scale := 1.0
+	switch {
+	case strings.HasSuffix(text, "k"):
+		scale, text = 1e3, strings.TrimSuffix(text, "k")
+	case strings.HasSuffix(text, "m"):
+		scale, text = 1e6, strings.TrimSuffix(text, "m")
+	}
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid value: %s (expected a non-negative number or %s)", s, strings.Join(RulePercentiles, ", "))
+	}
+	return value * scale, nil
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAlertRule(t *testing.T) {
+	rule, err := ParseAlertRule(AlertRuleConfig{Name: "spend", When: "daily_cost > $20", Level: "Error"})
+	require.NoError(t, err)
+	assert.Equal(t, AlertRule{Name: "spend", Metric: RuleDailyCost, Op: ">", Threshold: 20, Level: "error"}, rule)
+	assert.Equal(t, "daily_cost > 20", rule.Condition())
+
+	rule, err = ParseAlertRule(AlertRuleConfig{When: "burn_rate  >= 5k for 10m"})
+	require.NoError(t, err)
+	assert.Equal(t, "burn_rate >= 5k for 10m", rule.Name, "the condition names an unnamed rule")
+	assert.Equal(t, "warning", rule.Level)
+	assert.Equal(t, 5000.0, rule.Threshold)
+	assert.Equal(t, 10*time.Minute, rule.Window)
+	assert.Equal(t, "burn_rate >= 5000 for 10m", rule.Condition())
+
+	rule, err = ParseAlertRule(AlertRuleConfig{When: "cost_rate > 2"})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultRuleWindow, rule.Window)
+
+	rule, err = ParseAlertRule(AlertRuleConfig{When: "session_messages > p90"})
+	require.NoError(t, err)
+	assert.Equal(t, "p90", rule.Percentile)
+	assert.Equal(t, "session_messages > p90", rule.Condition())
+
+	for when, message := range map[string]string{
+		"daily_cost":                  "invalid condition",
+		"hourly_cost > 20":            "unknown metric: hourly_cost",
+		"daily_cost = 20":             "invalid operator: =",
+		"daily_cost > twenty":         "invalid value: twenty",
+		"burn_rate > p90":             "burn_rate cannot be compared with a percentile",
+		"session_tokens_pct > p90":    "session_tokens_pct cannot be compared with a percentile",
+		"daily_cost > 20 for 10m":     "for applies to rates only",
+		"burn_rate > 5000 for 10s":    "invalid window: 10s",
+		"burn_rate > 5000 during 10m": "expected \"for <duration>\"",
+	} {
+		_, err := ParseAlertRule(AlertRuleConfig{When: when})
+		assert.ErrorContains(t, err, message, when)
+	}
+	_, err = ParseAlertRule(AlertRuleConfig{When: "daily_cost > 20", Level: "critical"})
+	assert.ErrorContains(t, err, "invalid level: critical")
+}
+
+func TestParseAlertRules(t *testing.T) {
+	rules, err := ParseAlertRules([]AlertRuleConfig{{When: "daily_cost > 20"}, {When: "weekly_cost > 1.5M"}})
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	assert.Equal(t, 1.5e6, rules[1].Threshold)
+
+	_, err = ParseAlertRules([]AlertRuleConfig{{When: "daily_cost > 20"}, {When: "bogus"}})
+	assert.ErrorContains(t, err, "rules[1]: invalid condition")
+}
+
+func TestAlertRule_Compare(t *testing.T) {
+	for op, want := range map[string][3]bool{">": {false, false, true}, ">=": {false, true, true}, "<": {true, false, false}, "<=": {true, true, false}} {
+		rule := AlertRule{Op: op}
+		assert.Equal(t, want, [3]bool{rule.Compare(1, 2), rule.Compare(2, 2), rule.Compare(3, 2)}, op)
+	}
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AlertProfileVersion is the version of the alert profile format written by ExportAlerts
+const AlertProfileVersion = 1
+
+// alertKeyPrefixes select the keys of an alert profile: session thresholds, notification channels,
+// usage alerts, budgets and guardrails. The plan and everything else stay per machine.
+var alertKeyPrefixes = []string{
+	"subscription.warn_threshold",
+	"subscription.alert_threshold",
+	"limits.",
+	"alerts.",
+	"budgets.",
+	"guardrails.",
+}
+
+// AlertProfile is a portable export of the alerting settings, keyed by dotted configuration key
+type AlertProfile struct {
+	Version    int                        `json:"version"`
+	ExportedAt time.Time                  `json:"exported_at"`
+	Settings   map[string]json.RawMessage `json:"settings"`
+}
+
+// AlertKeys returns the configuration keys included in an alert profile
+func AlertKeys() []KeyInfo {
+	var keys []KeyInfo
+	for _, info := range Keys() {
+		if isAlertKey(info.Key) {
+			keys = append(keys, info)
+		}
+	}
+	return keys
+}
+
+// isAlertKey reports whether key belongs in an alert profile
+func isAlertKey(key string) bool {
+	for _, prefix := range alertKeyPrefixes {
+		if key == prefix || (strings.HasSuffix(prefix, ".") && strings.HasPrefix(key, prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExportAlerts captures the alerting settings of cfg. Secrets such as the SMTP password are left
+// out unless includeSecrets is set.
+func ExportAlerts(cfg *Config, includeSecrets bool, now time.Time) (AlertProfile, error) {
+	profile := AlertProfile{Version: AlertProfileVersion, ExportedAt: now, Settings: make(map[string]json.RawMessage)}
+	for _, info := range AlertKeys() {
+		if info.Secret && !includeSecrets {
+			continue
+		}
+		value, err := GetValue(cfg, info.Key)
+		if err != nil {
+			return AlertProfile{}, err
+		}
+		if d, ok := value.(time.Duration); ok {
+			value = d.String()
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return AlertProfile{}, fmt.Errorf("failed to encode %s: %w", info.Key, err)
+		}
+		profile.Settings[info.Key] = data
+	}
+	return profile, nil
+}
+
+// ParseAlertProfile reads an alert profile, checking its version and keys
+func ParseAlertProfile(data []byte) (AlertProfile, error) {
+	var profile AlertProfile
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return AlertProfile{}, fmt.Errorf("failed to parse alert profile: %w", err)
+	}
+	if profile.Version < 1 || profile.Version > AlertProfileVersion {
+		return AlertProfile{}, fmt.Errorf("unsupported alert profile version: %d", profile.Version)
+	}
+	for key := range profile.Settings {
+		if !isAlertKey(key) {
+			return AlertProfile{}, fmt.Errorf("%s is not an alert setting", key)
+		}
+		if _, err := LookupKey(key); err != nil {
+			return AlertProfile{}, err
+		}
+	}
+	return profile, nil
+}
+
+// Keys returns the keys set by the profile, sorted
+func (p AlertProfile) Keys() []string {
+	keys := make([]string, 0, len(p.Settings))
+	for key := range p.Settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ImportAlerts stores every setting of the profile in the file; keys the profile does not set are
+// left as they are
+func (e *FileEditor) ImportAlerts(profile AlertProfile) error {
+	for _, key := range profile.Keys() {
+		info, err := LookupKey(key)
+		if err != nil {
+			return err
+		}
+		value, err := decodeSetting(info, profile.Settings[key])
+		if err != nil {
+			return err
+		}
+		if err := e.SetValue(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeSetting decodes a profile setting into a value of the key's type
+func decodeSetting(info KeyInfo, data json.RawMessage) (any, error) {
+	if info.Type == durationType {
+		var raw string
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("invalid value for %s (duration): %w", info.Key, err)
+		}
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s (duration): %w", info.Key, err)
+		}
+		return d, nil
+	}
+	value := reflect.New(info.Type)
+	if err := json.Unmarshal(data, value.Interface()); err != nil {
+		return nil, fmt.Errorf("invalid value for %s (%s): %w", info.Key, typeName(info.Type), err)
+	}
+	return value.Elem().Interface(), nil
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertProfileRoundTrip(t *testing.T) {
+	source := DefaultConfig()
+	source.Subscription.Plan = "max20"
+	source.Subscription.WarnThreshold = 0.6
+	source.Budgets.Daily = 25
+	source.Limits.Notifications = []NotificationType{NotifyWebhook}
+	source.Limits.WebhookURL = "https://hooks.example.com/claude"
+	source.Limits.EmailSMTP.Password = "secret"
+	source.Alerts.Absence.After = 90 * time.Minute
+	source.Guardrails.Models = []ModelGuardrailConfig{{Model: "claude-opus-*", MessageInputTokens: 50000}}
+
+	profile, err := ExportAlerts(source, false, time.Now())
+	require.NoError(t, err)
+	assert.NotContains(t, profile.Settings, "subscription.plan")
+	assert.NotContains(t, profile.Settings, "limits.email_smtp.password")
+	assert.JSONEq(t, `"1h30m0s"`, string(profile.Settings["alerts.absence.after"]))
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("# Team machine\nsubscription:\n  plan: pro\n"), 0600))
+	editor, err := OpenFileEditor(path)
+	require.NoError(t, err)
+	require.NoError(t, editor.ImportAlerts(profile))
+	require.NoError(t, editor.Save())
+
+	imported, err := NewFileSource(path).Load()
+	require.NoError(t, err)
+	assert.Equal(t, "pro", imported.Subscription.Plan)
+	assert.Equal(t, 0.6, imported.Subscription.WarnThreshold)
+	assert.Equal(t, 25.0, imported.Budgets.Daily)
+	assert.Equal(t, []NotificationType{NotifyWebhook}, imported.Limits.Notifications)
+	assert.Equal(t, "https://hooks.example.com/claude", imported.Limits.WebhookURL)
+	assert.Empty(t, imported.Limits.EmailSMTP.Password)
+	assert.Equal(t, 90*time.Minute, imported.Alerts.Absence.After)
+	assert.Equal(t, source.Guardrails.Models, imported.Guardrails.Models)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# Team machine")
+
+	withSecrets, err := ExportAlerts(source, true, time.Now())
+	require.NoError(t, err)
+	assert.JSONEq(t, `"secret"`, string(withSecrets.Settings["limits.email_smtp.password"]))
+}
+
+func TestParseAlertProfile(t *testing.T) {
+	profile, err := ParseAlertProfile([]byte(`{"version": 1, "settings": {"budgets.daily": 10}}`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"budgets.daily"}, profile.Keys())
+
+	_, err = ParseAlertProfile([]byte(`{"version": 2, "settings": {}}`))
+	assert.Error(t, err)
+	_, err = ParseAlertProfile([]byte(`{"version": 1, "settings": {"ui.theme": "light"}}`))
+	assert.ErrorContains(t, err, "not an alert setting")
+	_, err = ParseAlertProfile([]byte(`{"version": 1, "settings": {"budgets.hourly": 1}}`))
+	assert.Error(t, err)
+
+	// Values must match the key's type
+	profile, err = ParseAlertProfile([]byte(`{"version": 1, "settings": {"budgets.daily": "ten"}}`))
+	require.NoError(t, err)
+	editor, err := OpenFileEditor(filepath.Join(t.TempDir(), "config.yaml"))
+	require.NoError(t, err)
+	assert.ErrorContains(t, editor.ImportAlerts(profile), "budgets.daily")
+}
@@ -1,7 +1,10 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
 	"runtime"
+	"strings"
 	"time"
 )
 
@@ -30,6 +33,24 @@ type Config struct {
 
 	// Debug
 	Debug DebugConfig `yaml:"debug" json:"debug"`
+
+	// Export
+	Export ExportConfig `yaml:"export" json:"export"`
+
+	// Alerts
+	Alerts AlertsConfig `yaml:"alerts" json:"alerts"`
+
+	// Retention
+	Retention RetentionConfig `yaml:"retention" json:"retention"`
+
+	// API
+	API APIConfig `yaml:"api" json:"api"`
+
+	// Budgets
+	Budgets BudgetConfig `yaml:"budgets" json:"budgets"`
+
+	// Guardrails
+	Guardrails GuardrailsConfig `yaml:"guardrails" json:"guardrails"`
 }
 
 // AppConfig contains general application settings
@@ -48,12 +69,21 @@ type DataConfig struct {
 	AutoDiscover       bool               `yaml:"auto_discover" json:"auto_discover"`
 	WatchInterval      time.Duration      `yaml:"watch_interval" json:"watch_interval"`
 	MaxFileSize        int64              `yaml:"max_file_size" json:"max_file_size"`
+	MaxLineSize        int                `yaml:"max_line_size" json:"max_line_size"` // Oversized lines are compacted to usage fields
 	CacheEnabled       bool               `yaml:"cache_enabled" json:"cache_enabled"`
 	CacheSize          int                `yaml:"cache_size" json:"cache_size"`
 	SummaryCache       SummaryCacheConfig `yaml:"summary_cache" json:"summary_cache"`
 	PricingSource      string             `yaml:"pricing_source" json:"pricing_source"`             // default, litellm
 	PricingOfflineMode bool               `yaml:"pricing_offline_mode" json:"pricing_offline_mode"` // Use cached pricing
+	CostModel          string             `yaml:"cost_model" json:"cost_model"`                     // subscription, api, bedrock or vertex, the last two optionally -regional
+	CostModels         []CostModelPath    `yaml:"cost_models" json:"cost_models"`                   // Cost model per data path, overriding cost_model
+	RequestFee         float64            `yaml:"request_fee" json:"request_fee"`                   // USD added to the cost of every request, such as a gateway's fee
 	Deduplication      bool               `yaml:"deduplication" json:"deduplication"`               // Enable deduplication
+	UpdateURL          string             `yaml:"update_url" json:"update_url"`                     // Signed pricing/limits bundle URL
+	UpdatePublicKey    string             `yaml:"update_public_key" json:"update_public_key"`       // Base64 ed25519 key for bundle signatures
+	SessionOverrides   string             `yaml:"session_overrides" json:"session_overrides"`       // File of pinned session start times
+	Exclude            []string           `yaml:"exclude" json:"exclude"`                           // Log directories to skip: path.Match patterns relative to a data path, or absolute
+	DuplicateProjects  string             `yaml:"duplicate_projects" json:"duplicate_projects"`     // Conversations logged in several project directories: warn or merge
 }
 
 // SummaryCacheConfig contains file summary caching settings
@@ -65,9 +95,13 @@ type SummaryCacheConfig struct {
 
 // CacheConfig contains cache system settings
 type CacheConfig struct {
-	Dir         string `yaml:"dir" json:"dir"`                     // Cache directory path
-	MaxMemory   int64  `yaml:"max_memory" json:"max_memory"`       // L1 memory cache size
-	MaxDiskSize int64  `yaml:"max_disk_size" json:"max_disk_size"` // L2 disk cache size
+	Dir         string        `yaml:"dir" json:"dir"`                     // Cache directory path
+	MaxMemory   int64         `yaml:"max_memory" json:"max_memory"`       // L1 memory cache size
+	MaxDiskSize int64         `yaml:"max_disk_size" json:"max_disk_size"` // Quota for cached summaries in bytes; oldest are evicted beyond it, 0 disables
+	TrashTTL    time.Duration `yaml:"trash_ttl" json:"trash_ttl"`         // How long invalidated summaries stay restorable
+	Encryption  string        `yaml:"encryption" json:"encryption"`       // Encrypt summaries, history, alerts, imports and tags at rest: off, keychain or passphrase
+	// ArchiveAfterMonths rolls summaries of usage files untouched for N full months into monthly archives; 0 disables
+	ArchiveAfterMonths int `yaml:"archive_after_months" json:"archive_after_months"`
 }
 
 // UIConfig contains user interface settings
@@ -83,6 +117,23 @@ type UIConfig struct {
 	NoColor       bool          `yaml:"no_color" json:"no_color"`
 	ViewMode      string        `yaml:"view_mode" json:"view_mode"` // "dashboard" or "monitor"
 	Timezone      string        `yaml:"timezone" json:"timezone"`   // Timezone for display
+	// BurnRateSmoothing selects the burn/cost rate window: instant, ema (10-min) or hourly
+	BurnRateSmoothing string `yaml:"burn_rate_smoothing" json:"burn_rate_smoothing"`
+	// BurnAlarmThreshold is the tokens/min above which the monitor alarms (0 = disabled)
+	BurnAlarmThreshold float64       `yaml:"burn_alarm_threshold" json:"burn_alarm_threshold"`
+	BurnAlarmDuration  time.Duration `yaml:"burn_alarm_duration" json:"burn_alarm_duration"` // How long the threshold must be exceeded
+	BurnAlarmStyle     string        `yaml:"burn_alarm_style" json:"burn_alarm_style"`       // "bell", "flash" or "both"
+	// LowPower slows refreshes and pauses background work: auto (while on battery), on or off
+	LowPower            string        `yaml:"low_power" json:"low_power"`
+	LowPowerRefreshRate time.Duration `yaml:"low_power_refresh_rate" json:"low_power_refresh_rate"` // Refresh and redraw interval in low-power mode
+	// Locale sets the thousands and decimal separators of displayed numbers: en, de or fr
+	Locale string `yaml:"locale" json:"locale"`
+	// SessionLink is the URL session IDs link to where the terminal supports hyperlinks, such as a
+	// drill-down command registered as a URL handler, with {id} replaced by the session ID
+	SessionLink string `yaml:"session_link" json:"session_link"`
+	// ColumnWidths caps table columns by lowercase header, such as project: 40; longer cells are
+	// shortened in the middle unless --full-names is given (0 = unlimited)
+	ColumnWidths map[string]int `yaml:"column_widths" json:"column_widths"`
 }
 
 // PerformanceConfig contains performance tuning settings
@@ -105,7 +156,32 @@ type SubscriptionConfig struct {
 
 // DebugConfig contains debugging and profiling settings
 type DebugConfig struct {
-	Enabled bool `yaml:"enabled" json:"enabled"`
+	Enabled  bool           `yaml:"enabled" json:"enabled"`
+	Watchdog WatchdogConfig `yaml:"watchdog" json:"watchdog"`
+}
+
+// WatchdogConfig contains the thresholds of the memory and goroutine watchdog; zero values use the defaults
+type WatchdogConfig struct {
+	Enabled       bool          `yaml:"enabled" json:"enabled"`               // Also runs whenever debug mode is on
+	Interval      time.Duration `yaml:"interval" json:"interval"`             // Time between samples
+	MaxRSSMB      int           `yaml:"max_rss_mb" json:"max_rss_mb"`         // Resident memory warned above
+	MaxHeapMB     int           `yaml:"max_heap_mb" json:"max_heap_mb"`       // Live heap warned above
+	MaxGoroutines int           `yaml:"max_goroutines" json:"max_goroutines"` // Goroutine count warned above
+	ProfileDir    string        `yaml:"profile_dir" json:"profile_dir"`       // Heap profiles are written here when a threshold is exceeded
+}
+
+// ExportConfig contains settings for exporting usage to external systems
+type ExportConfig struct {
+	InfluxDB InfluxDBConfig `yaml:"influxdb" json:"influxdb"`
+}
+
+// InfluxDBConfig contains InfluxDB line protocol export settings
+type InfluxDBConfig struct {
+	Enabled     bool   `yaml:"enabled" json:"enabled"`
+	File        string `yaml:"file" json:"file"`               // Write line protocol snapshot to this file
+	URL         string `yaml:"url" json:"url"`                 // InfluxDB/VictoriaMetrics write endpoint
+	Token       string `yaml:"token" json:"token"`             // Optional API token
+	Measurement string `yaml:"measurement" json:"measurement"` // Measurement name prefix
 }
 
 // LimitsConfig contains subscription limit settings
@@ -117,6 +193,78 @@ type LimitsConfig struct {
 	EmailSMTP     SMTPConfig         `yaml:"email_smtp" json:"email_smtp"`
 }
 
+// RetentionConfig contains data-minimization settings for persisted artifacts. Redaction covers
+// everything under cache.dir: summaries, the eviction ledger, command history, the alert log,
+// imported usage, session tag notes and the monitor snapshot, as well as exported results.
+type RetentionConfig struct {
+	RedactIDsAfterDays int `yaml:"redact_ids_after_days" json:"redact_ids_after_days"` // Redact message/request/session IDs after N days; 0 disables
+}
+
+// AlertsConfig contains usage alert settings
+type AlertsConfig struct {
+	Absence AbsenceAlertConfig `yaml:"absence" json:"absence"`
+	Rules   []AlertRuleConfig  `yaml:"rules" json:"rules"` // Evaluated by the monitor, watch and analyze --alerts
+}
+
+// AlertRuleConfig is a user-defined alert, such as "daily_cost > 20" or "burn_rate > 5000 for 10m"
+type AlertRuleConfig struct {
+	Name  string `yaml:"name" json:"name"`   // Shown in alerts; the condition when empty
+	When  string `yaml:"when" json:"when"`   // <metric> <op> <value> [for <duration>]
+	Level string `yaml:"level" json:"level"` // warning (default) or error
+}
+
+// AbsenceAlertConfig alerts when no usage appears during work hours, e.g. after the log path changed
+type AbsenceAlertConfig struct {
+	Enabled   bool          `yaml:"enabled" json:"enabled"`
+	After     time.Duration `yaml:"after" json:"after"`           // Work time without entries before alerting
+	WorkStart string        `yaml:"work_start" json:"work_start"` // Start of work hours (HH:MM)
+	WorkEnd   string        `yaml:"work_end" json:"work_end"`     // End of work hours (HH:MM)
+	WorkDays  []string      `yaml:"work_days" json:"work_days"`   // Weekday abbreviations (mon..sun)
+}
+
+// APIConfig contains settings for the HTTP API
+type APIConfig struct {
+	Address        string             `yaml:"address" json:"address"`                 // Bind address of claudecat serve
+	Token          string             `yaml:"token" json:"token"`                     // Bearer token required by claudecat serve; empty disables auth
+	TrustedProxies []string           `yaml:"trusted_proxies" json:"trusted_proxies"` // IPs or CIDRs of reverse proxies whose identity header is trusted
+	IdentityHeader string             `yaml:"identity_header" json:"identity_header"` // Header set by the proxy to the authenticated user or groups
+	CostCenters    []CostCenterConfig `yaml:"cost_centers" json:"cost_centers"`       // When set, remote requests must come through a trusted proxy
+}
+
+// CostModelPath selects the cost model of the usage under one data path. It is a list entry rather
+// than a map key, since configuration keys lose their case and split on dots.
+type CostModelPath struct {
+	Path  string `yaml:"path" json:"path"`   // Data path, may start with ~/
+	Model string `yaml:"model" json:"model"` // As data.cost_model
+}
+
+// CostCenterConfig maps proxy identities to the projects whose usage they may query
+type CostCenterConfig struct {
+	Name       string   `yaml:"name" json:"name"`
+	Identities []string `yaml:"identities" json:"identities"` // Identity header values belonging to this cost center
+	Projects   []string `yaml:"projects" json:"projects"`     // Project name patterns (path.Match syntax, case-insensitive)
+}
+
+// BudgetConfig contains the spend budgets checked by claudecat budget
+type BudgetConfig struct {
+	Daily         float64 `yaml:"daily" json:"daily"`                   // USD per local day; 0 disables
+	Weekly        float64 `yaml:"weekly" json:"weekly"`                 // USD per Monday-based week; 0 disables
+	Monthly       float64 `yaml:"monthly" json:"monthly"`               // USD per calendar month; 0 disables
+	WarnThreshold float64 `yaml:"warn_threshold" json:"warn_threshold"` // Fraction of a budget reported as a warning; 0 disables
+}
+
+// GuardrailsConfig contains the message-size guardrails that warn about oversized requests
+type GuardrailsConfig struct {
+	MessageInputTokens int                    `yaml:"message_input_tokens" json:"message_input_tokens"` // Warn when one message adds more input tokens (uncached input plus cache writes); 0 disables
+	Models             []ModelGuardrailConfig `yaml:"models" json:"models"`                             // Per-model overrides, the first matching pattern wins
+}
+
+// ModelGuardrailConfig overrides the message input guardrail for matching models
+type ModelGuardrailConfig struct {
+	Model              string `yaml:"model" json:"model"` // Model name pattern (path.Match syntax, case-insensitive)
+	MessageInputTokens int    `yaml:"message_input_tokens" json:"message_input_tokens"`
+}
+
 // NotificationType represents the type of notification
 type NotificationType string
 
@@ -156,6 +304,16 @@ func ConfigPaths() []string {
 	}
 }
 
+// ExpandHome expands a leading ~/ in a configured path such as cache.dir
+func ExpandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(homeDir, rest)
+		}
+	}
+	return path
+}
+
 // Version will be set at build time
 var Version = "dev"
 
@@ -173,6 +331,7 @@ func DefaultConfig() *Config {
 			AutoDiscover:  true,
 			WatchInterval: 100 * time.Millisecond,
 			MaxFileSize:   100 * 1024 * 1024, // 100MB
+			MaxLineSize:   10 * 1024 * 1024,  // 10MB
 			CacheEnabled:  true,
 			CacheSize:     50, // 50MB
 			SummaryCache: SummaryCacheConfig{
@@ -183,6 +342,9 @@ func DefaultConfig() *Config {
 			PricingSource:      "default", // Use hardcoded pricing by default
 			PricingOfflineMode: false,     // Don't use offline mode by default
 			Deduplication:      false,     // Deduplication disabled by default
+			CostModel:          "subscription",
+			SessionOverrides:   "~/.config/claudecat/session_overrides.txt",
+			DuplicateProjects:  "warn",
 		},
 		UI: UIConfig{
 			Theme:         "dark",
@@ -193,6 +355,15 @@ func DefaultConfig() *Config {
 			TablePageSize: 20,
 			DateFormat:    "2006-01-02",
 			TimeFormat:    "15:04:05",
+
+			BurnRateSmoothing: "hourly",
+			BurnAlarmDuration: time.Minute,
+			BurnAlarmStyle:    "both",
+
+			LowPower:            "auto",
+			LowPowerRefreshRate: 30 * time.Second,
+
+			ColumnWidths: map[string]int{"project": 40},
 		},
 		Performance: PerformanceConfig{
 			WorkerCount: runtime.NumCPU(),
@@ -214,10 +385,34 @@ func DefaultConfig() *Config {
 			Dir:         "~/.cache/claudecat",
 			MaxMemory:   200 * 1024 * 1024,  // 200MB
 			MaxDiskSize: 1024 * 1024 * 1024, // 1GB
+			TrashTTL:    7 * 24 * time.Hour,
+			Encryption:  "off",
 		},
 		Debug: DebugConfig{
 			Enabled: false,
 		},
+		Export: ExportConfig{
+			InfluxDB: InfluxDBConfig{
+				Enabled:     false,
+				Measurement: "claudecat",
+			},
+		},
+		Alerts: AlertsConfig{
+			Absence: AbsenceAlertConfig{
+				Enabled:   false,
+				After:     3 * time.Hour,
+				WorkStart: "09:00",
+				WorkEnd:   "18:00",
+				WorkDays:  []string{"mon", "tue", "wed", "thu", "fri"},
+			},
+		},
+		API: APIConfig{
+			Address:        "127.0.0.1:8787",
+			IdentityHeader: "X-Forwarded-User",
+		},
+		Guardrails: GuardrailsConfig{
+			MessageInputTokens: 150000,
+		},
 	}
 }
 
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"path/filepath"
 	"runtime"
 	"testing"
 	"time"
@@ -93,6 +94,16 @@ func TestConfigPaths(t *testing.T) {
 	assert.Equal(t, expectedPaths, paths)
 }
 
+func TestExpandHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	assert.Equal(t, filepath.Join(home, ".cache", "claudecat"), ExpandHome("~/.cache/claudecat"))
+	for _, path := range []string{"", "~", "c", "/var/cache/claudecat", "cache/~/claudecat"} {
+		assert.Equal(t, path, ExpandHome(path))
+	}
+}
+
 func TestFormat(t *testing.T) {
 	assert.Equal(t, 0, int(FormatYAML))
 	assert.Equal(t, 1, int(FormatJSON))
@@ -0,0 +1,480 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// KeyInfo describes a leaf configuration key, named by its dotted YAML path such as ui.theme
+type KeyInfo struct {
+	Key    string
+	Type   reflect.Type
+	Secret bool   // Tokens and passwords, masked when listing
+	Entry  string // For an entry of a map setting, such as project of ui.column_widths.project
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// secretKeys are the final key segments whose values are masked when listing
+var secretKeys = map[string]bool{"token": true, "password": true}
+
+// Keys returns every leaf configuration key in declaration order
+func Keys() []KeyInfo {
+	var keys []KeyInfo
+	collectKeys(reflect.TypeOf(Config{}), "", &keys)
+	return keys
+}
+
+// collectKeys appends the leaf keys of a struct type under prefix
+func collectKeys(t reflect.Type, prefix string, keys *[]KeyInfo) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := yamlName(field)
+		if name == "" {
+			continue
+		}
+		key := prefix + name
+		if field.Type.Kind() == reflect.Struct && field.Type != durationType {
+			collectKeys(field.Type, key+".", keys)
+			continue
+		}
+		*keys = append(*keys, KeyInfo{Key: key, Type: field.Type, Secret: secretKeys[name]})
+	}
+}
+
+// yamlName returns the YAML key of a struct field, or "" if it is not configurable
+func yamlName(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+	name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// LookupKey returns the description of a leaf configuration key, or of an entry of a map setting
+// such as ui.column_widths.project
+func LookupKey(key string) (KeyInfo, error) {
+	for _, info := range Keys() {
+		if info.Key == key {
+			return info, nil
+		}
+		if entry, ok := strings.CutPrefix(key, info.Key+"."); ok && entry != "" && info.Type.Kind() == reflect.Map {
+			return KeyInfo{Key: key, Type: info.Type.Elem(), Secret: info.Secret, Entry: entry}, nil
+		}
+	}
+	return KeyInfo{}, fmt.Errorf("unknown configuration key: %s", key)
+}
+
+// path returns the YAML path of the key, keeping a map entry holding dots in one piece
+func (info KeyInfo) path() []string {
+	if info.Entry == "" {
+		return strings.Split(info.Key, ".")
+	}
+	return append(strings.Split(strings.TrimSuffix(info.Key, "."+info.Entry), "."), info.Entry)
+}
+
+// GetValue returns the value of a leaf configuration key in cfg; an unset map entry is the zero value
+func GetValue(cfg *Config, key string) (any, error) {
+	info, err := LookupKey(key)
+	if err != nil {
+		return nil, err
+	}
+	value := reflect.ValueOf(cfg).Elem()
+	for _, part := range info.path() {
+		if value.Kind() == reflect.Map {
+			entry := value.MapIndex(reflect.ValueOf(part).Convert(value.Type().Key()))
+			if !entry.IsValid() {
+				return reflect.Zero(info.Type).Interface(), nil
+			}
+			return entry.Interface(), nil
+		}
+		for i := 0; i < value.NumField(); i++ {
+			if yamlName(value.Type().Field(i)) == part {
+				value = value.Field(i)
+				break
+			}
+		}
+	}
+	return value.Interface(), nil
+}
+
+// FormatValue renders a configuration value the way config set accepts it: durations like 10s,
+// lists of strings comma-separated and lists of objects as JSON
+func FormatValue(value any) string {
+	if d, ok := value.(time.Duration); ok {
+		return d.String()
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.String {
+			items := make([]string, v.Len())
+			for i := range items {
+				items[i] = v.Index(i).String()
+			}
+			return strings.Join(items, ",")
+		}
+	case reflect.Bool, reflect.Int, reflect.Int64, reflect.Float64:
+		return fmt.Sprint(value)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// UnknownKeys returns the dotted keys of a YAML or JSON configuration that are not settings, such
+// as misspelled ones, which loading silently ignores
+func UnknownKeys(data []byte) ([]string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	known := make(map[string]bool)
+	for _, info := range Keys() {
+		known[info.Key] = true
+		for i := range info.Key {
+			if info.Key[i] == '.' {
+				known[info.Key[:i]+"."] = true // A section holding settings
+			}
+		}
+	}
+	var unknown []string
+	var walk func(mapping *yaml.Node, prefix string)
+	walk = func(mapping *yaml.Node, prefix string) {
+		for i := 0; i+1 < len(mapping.Content); i += 2 {
+			key, value := prefix+mapping.Content[i].Value, mapping.Content[i+1]
+			switch {
+			case known[key]:
+			case known[key+"."] && value.Kind == yaml.MappingNode:
+				walk(value, key+".")
+			default:
+				unknown = append(unknown, key)
+			}
+		}
+	}
+	walk(doc.Content[0], "")
+	return unknown, nil
+}
+
+// MarshalEffective renders cfg as YAML with every setting in declaration order. Tokens and
+// passwords are masked unless includeSecrets is set.
+func MarshalEffective(cfg *Config, includeSecrets bool) ([]byte, error) {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	for _, info := range Keys() {
+		value, err := GetValue(cfg, info.Key)
+		if err != nil {
+			return nil, err
+		}
+		node := &yaml.Node{}
+		switch {
+		case info.Type == durationType:
+			node = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: FormatValue(value)}
+		case info.Secret && !includeSecrets && FormatValue(value) != "":
+			node = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "********"}
+		default:
+			if err := node.Encode(value); err != nil {
+				return nil, fmt.Errorf("failed to encode %s: %w", info.Key, err)
+			}
+			if node.Kind == yaml.SequenceNode && len(node.Content) == 0 {
+				node.Style = yaml.FlowStyle
+			}
+		}
+
+		mapping := root
+		parts := strings.Split(info.Key, ".")
+		for _, part := range parts[:len(parts)-1] {
+			child := mappingValue(mapping, part)
+			if child == nil {
+				child = &yaml.Node{Kind: yaml.MappingNode}
+				mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: part}, child)
+			}
+			mapping = child
+		}
+		mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: parts[len(parts)-1]}, node)
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(root); err != nil {
+		return nil, fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// FileEditor edits a YAML configuration file in place, preserving its comments and key order
+type FileEditor struct {
+	path string
+	doc  yaml.Node
+}
+
+// OpenFileEditor reads the configuration file at path; a missing file starts out empty
+func OpenFileEditor(path string) (*FileEditor, error) {
+	if ext := strings.ToLower(filepath.Ext(path)); ext != ".yaml" && ext != ".yml" {
+		return nil, fmt.Errorf("only YAML configuration files can be edited: %s", path)
+	}
+	editor := &FileEditor{path: path}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := yaml.Unmarshal(data, &editor.doc); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	}
+	if editor.doc.Kind == 0 {
+		editor.doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	if editor.doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s does not contain a YAML mapping", path)
+	}
+	return editor, nil
+}
+
+// Path returns the path of the edited file
+func (e *FileEditor) Path() string {
+	return e.path
+}
+
+// Set parses raw according to the type of key and stores it in the file
+func (e *FileEditor) Set(key, raw string) error {
+	info, err := LookupKey(key)
+	if err != nil {
+		return err
+	}
+	value, err := valueNode(info, raw)
+	if err != nil {
+		return err
+	}
+	e.setNode(info.path(), value)
+	return nil
+}
+
+// SetValue stores a value of the key's type in the file, including lists of objects
+func (e *FileEditor) SetValue(key string, value any) error {
+	info, err := LookupKey(key)
+	if err != nil {
+		return err
+	}
+	if reflect.TypeOf(value) != info.Type {
+		return fmt.Errorf("invalid value for %s: expected %s", key, typeName(info.Type))
+	}
+	node := &yaml.Node{}
+	if d, ok := value.(time.Duration); ok {
+		node = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: d.String()}
+	} else if err := node.Encode(value); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", key, err)
+	}
+	e.setNode(info.path(), node)
+	return nil
+}
+
+// setNode stores value at the key path parts, creating the sections leading to it
+func (e *FileEditor) setNode(parts []string, value *yaml.Node) {
+	mapping := e.doc.Content[0]
+	for _, part := range parts[:len(parts)-1] {
+		child := mappingValue(mapping, part)
+		if child == nil {
+			child = &yaml.Node{Kind: yaml.MappingNode}
+			mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: part}, child)
+		} else if child.Kind != yaml.MappingNode {
+			// An empty section such as "cache:" parses as null
+			*child = yaml.Node{Kind: yaml.MappingNode, HeadComment: child.HeadComment, LineComment: child.LineComment}
+		}
+		mapping = child
+	}
+
+	last := parts[len(parts)-1]
+	if existing := mappingValue(mapping, last); existing != nil {
+		value.LineComment, value.FootComment = existing.LineComment, existing.FootComment
+		*existing = *value
+		return
+	}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: last}, value)
+}
+
+// Unset removes key from the file, along with sections left empty, and reports whether it was present
+func (e *FileEditor) Unset(key string) (bool, error) {
+	info, err := LookupKey(key)
+	if err != nil {
+		return false, err
+	}
+	return unsetPath(e.doc.Content[0], info.path()), nil
+}
+
+// unsetPath removes the key at parts below mapping, pruning mappings it leaves empty
+func unsetPath(mapping *yaml.Node, parts []string) bool {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != parts[0] {
+			continue
+		}
+		child := mapping.Content[i+1]
+		if len(parts) > 1 {
+			if child.Kind != yaml.MappingNode || !unsetPath(child, parts[1:]) {
+				return false
+			}
+			if len(child.Content) > 0 {
+				return true
+			}
+		}
+		mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+		return true
+	}
+	return false
+}
+
+// Save validates the edited configuration and atomically replaces the file. An edit that makes the
+// configuration invalid is rejected and the file is left unchanged.
+func (e *FileEditor) Save() error {
+	var buf bytes.Buffer
+	root := e.doc.Content[0]
+	if len(root.Content) > 0 || e.doc.HeadComment != "" || root.HeadComment != "" || root.FootComment != "" {
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(&e.doc); err != nil {
+			return fmt.Errorf("failed to encode configuration: %w", err)
+		}
+		if err := encoder.Close(); err != nil {
+			return fmt.Errorf("failed to encode configuration: %w", err)
+		}
+	}
+
+	// The file may hold tokens, so it is only readable by the user
+	mode := os.FileMode(0600)
+	dir := filepath.Dir(e.path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	tmpFile, err := os.CreateTemp(dir, ".claudecat-config-*"+filepath.Ext(e.path))
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+	defer os.Remove(tmpPath)
+	if _, err := tmpFile.Write(buf.Bytes()); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := os.Chmod(tmpPath, mode); err != nil {
+		return fmt.Errorf("failed to set file mode: %w", err)
+	}
+
+	cfg, err := NewFileSource(tmpPath).Load()
+	if err != nil {
+		return err
+	}
+	if err := NewStandardValidator().Validate((&DefaultMerger{}).Merge(DefaultConfig(), cfg)); err != nil {
+		return fmt.Errorf("configuration would be invalid: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, e.path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", e.path, err)
+	}
+	return nil
+}
+
+// mappingValue returns the value node of key in a mapping node
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// valueNode parses raw into a YAML node of the key's type
+func valueNode(info KeyInfo, raw string) (*yaml.Node, error) {
+	scalar := func(tag, value string) (*yaml.Node, error) {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value}, nil
+	}
+	invalid := func(err error) (*yaml.Node, error) {
+		return nil, fmt.Errorf("invalid value for %s (%s): %w", info.Key, typeName(info.Type), err)
+	}
+
+	if info.Type == durationType {
+		if _, err := time.ParseDuration(raw); err != nil {
+			return invalid(err)
+		}
+		return scalar("!!str", raw)
+	}
+	switch info.Type.Kind() {
+	case reflect.String:
+		return scalar("!!str", raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return invalid(err)
+		}
+		return scalar("!!bool", strconv.FormatBool(b))
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, info.Type.Bits())
+		if err != nil {
+			return invalid(err)
+		}
+		return scalar("!!int", strconv.FormatInt(n, 10))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return invalid(err)
+		}
+		return scalar("!!float", strconv.FormatFloat(f, 'g', -1, 64))
+	case reflect.Slice:
+		if info.Type.Elem().Kind() == reflect.String {
+			sequence := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+			for _, item := range strings.Split(raw, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					sequence.Content = append(sequence.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: item})
+				}
+			}
+			return sequence, nil
+		}
+	}
+	if info.Type.Kind() == reflect.Map {
+		return nil, fmt.Errorf("%s is a map; set its entries one at a time, such as %s.<name>", info.Key, info.Key)
+	}
+	return nil, fmt.Errorf("%s is a list of objects and cannot be set from the command line; edit the file instead", info.Key)
+}
+
+// typeName describes a key type in error messages
+func typeName(t reflect.Type) string {
+	if t == durationType {
+		return "duration"
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int64:
+		return "integer"
+	case reflect.Float64:
+		return "number"
+	case reflect.Slice:
+		return "comma-separated list"
+	}
+	return t.Kind().String()
+}
@@ -0,0 +1,198 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeysAndGetValue(t *testing.T) {
+	info, err := LookupKey("cache.trash_ttl")
+	require.NoError(t, err)
+	assert.Equal(t, "duration", typeName(info.Type))
+	info, err = LookupKey("limits.email_smtp.password")
+	require.NoError(t, err)
+	assert.True(t, info.Secret)
+	_, err = LookupKey("ui")
+	assert.Error(t, err)
+	info, err = LookupKey("ui.column_widths.project")
+	require.NoError(t, err)
+	assert.Equal(t, "integer", typeName(info.Type))
+	assert.Equal(t, []string{"ui", "column_widths", "project"}, info.path())
+	_, err = LookupKey("ui.theme.name")
+	assert.Error(t, err)
+
+	cfg := DefaultConfig()
+	value, err := GetValue(cfg, "ui.theme")
+	require.NoError(t, err)
+	assert.Equal(t, "dark", FormatValue(value))
+	value, err = GetValue(cfg, "cache.trash_ttl")
+	require.NoError(t, err)
+	assert.Equal(t, "168h0m0s", FormatValue(value))
+	value, err = GetValue(cfg, "limits.notifications")
+	require.NoError(t, err)
+	assert.Equal(t, "desktop", FormatValue(value))
+	value, err = GetValue(cfg, "ui.column_widths.project")
+	require.NoError(t, err)
+	assert.Equal(t, "40", FormatValue(value))
+	value, err = GetValue(cfg, "ui.column_widths.model")
+	require.NoError(t, err)
+	assert.Equal(t, "0", FormatValue(value), "an unset entry is the zero value")
+}
+
+func TestFileEditor(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	original := `# claudecat settings
+ui:
+  theme: light # preferred theme
+  timezone: UTC
+cache:
+subscription:
+  plan: pro
+`
+	require.NoError(t, os.WriteFile(path, []byte(original), 0640))
+
+	editor, err := OpenFileEditor(path)
+	require.NoError(t, err)
+	require.NoError(t, editor.Set("ui.theme", "dark"))
+	require.NoError(t, editor.Set("cache.trash_ttl", "2h"))
+	require.NoError(t, editor.Set("limits.notifications", "desktop, sound"))
+	require.NoError(t, editor.Set("app.log_level", "debug"))
+	require.NoError(t, editor.Set("ui.column_widths.model", "24"))
+	require.NoError(t, editor.Set("ui.column_widths.project", "60"))
+	removed, err := editor.Unset("ui.column_widths.model")
+	require.NoError(t, err)
+	assert.True(t, removed)
+	removed, err = editor.Unset("subscription.plan")
+	require.NoError(t, err)
+	assert.True(t, removed)
+	require.NoError(t, editor.Save())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `# claudecat settings
+ui:
+  theme: dark # preferred theme
+  timezone: UTC
+  column_widths:
+    project: 60
+cache:
+  trash_ttl: 2h
+limits:
+  notifications: [desktop, sound]
+app:
+  log_level: debug
+`, string(data))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "the file may hold tokens")
+
+	cfg, err := NewFileSource(path).Load()
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Hour, cfg.Cache.TrashTTL)
+	assert.Equal(t, []NotificationType{NotifyDesktop, NotifySound}, cfg.Limits.Notifications)
+	assert.Equal(t, map[string]int{"project": 60}, cfg.UI.ColumnWidths)
+
+	// Values are checked against the key type and the validator before the file is replaced
+	assert.Error(t, editor.Set("cache.trash_ttl", "soon"))
+	assert.Error(t, editor.Set("ui.chart_height", "tall"))
+	assert.Error(t, editor.Set("api.cost_centers", "web"))
+	assert.Error(t, editor.Set("ui.column_widths", "60"))
+	assert.Error(t, editor.Set("ui.column_widths.project", "wide"))
+	assert.Error(t, editor.Set("nope.key", "1"))
+	require.NoError(t, editor.Set("app.log_level", "loud"))
+	assert.Error(t, editor.Save())
+	after, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, data, after)
+}
+
+func TestFileEditor_NewFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "config.yaml")
+	editor, err := OpenFileEditor(path)
+	require.NoError(t, err)
+	require.NoError(t, editor.Set("api.token", "123"))
+	require.NoError(t, editor.Save())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "api:\n  token: \"123\"\n", string(data))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// Removing the last setting leaves an empty file
+	_, err = editor.Unset("api.token")
+	require.NoError(t, err)
+	require.NoError(t, editor.Save())
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Empty(t, data)
+
+	_, err = OpenFileEditor(filepath.Join(t.TempDir(), "config.toml"))
+	assert.Error(t, err)
+}
+
+func TestUnknownKeys(t *testing.T) {
+	unknown, err := UnknownKeys([]byte(`
+ui:
+  theme: light
+  colour: red
+subscripton:
+  plan: pro
+limits:
+  email_smtp:
+    host: smtp.example.com
+    hots: typo
+api:
+  cost_centers:
+    - name: platform
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ui.colour", "subscripton", "limits.email_smtp.hots"}, unknown)
+
+	_, err = UnknownKeys([]byte("ui: [\n"))
+	assert.Error(t, err)
+}
+
+func TestMarshalEffective(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.UI.Theme = "light"
+	cfg.API.Token = "secret"
+
+	data, err := MarshalEffective(cfg, false)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "token: '********'")
+	assert.NotContains(t, string(data), "secret")
+
+	// With secrets, the output loads back as the same configuration
+	data, err = MarshalEffective(cfg, true)
+	require.NoError(t, err)
+	unknown, err := UnknownKeys(data)
+	require.NoError(t, err)
+	assert.Empty(t, unknown)
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, data, 0600))
+	loaded, err := NewFileSource(path).Load()
+	require.NoError(t, err)
+	assert.Equal(t, "light", loaded.UI.Theme)
+	assert.Equal(t, "secret", loaded.API.Token)
+	assert.Equal(t, cfg.Cache.TrashTTL, loaded.Cache.TrashTTL)
+}
+
+func TestStarterConfig(t *testing.T) {
+	unknown, err := UnknownKeys([]byte(StarterConfig))
+	require.NoError(t, err)
+	assert.Empty(t, unknown)
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(StarterConfig), 0600))
+	loaded, err := NewFileSource(path).Load()
+	require.NoError(t, err)
+	assert.Empty(t, NewStandardValidator().Problems((&DefaultMerger{}).Merge(DefaultConfig(), loaded)))
+	assert.Equal(t, "pro", loaded.Subscription.Plan)
+}
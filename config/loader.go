@@ -7,6 +7,7 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/go-viper/mapstructure/v2"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
@@ -177,8 +178,9 @@ func (f *FileSource) Load() (*Config, error) {
 		return nil, fmt.Errorf("failed to read config file %s: %w", expandedPath, err)
 	}
 
+	// Decode by the yaml tags so that snake_case keys such as trash_ttl are not silently ignored
 	var config Config
-	if err := v.Unmarshal(&config); err != nil {
+	if err := v.Unmarshal(&config, func(dc *mapstructure.DecoderConfig) { dc.TagName = "yaml" }); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config from %s: %w", expandedPath, err)
 	}
 
@@ -241,8 +243,16 @@ func (e *EnvSource) setAllKeys(v *viper.Viper) {
 	v.SetDefault("data.auto_discover", false)
 	v.SetDefault("data.watch_interval", "")
 	v.SetDefault("data.max_file_size", 0)
+	v.SetDefault("data.max_line_size", 0)
 	v.SetDefault("data.cache_enabled", false)
 	v.SetDefault("data.cache_size", 0)
+	v.SetDefault("data.update_url", "")
+	v.SetDefault("data.update_public_key", "")
+	v.SetDefault("data.session_overrides", "")
+	v.SetDefault("data.exclude", []string{})
+	v.SetDefault("data.duplicate_projects", "")
+	v.SetDefault("data.cost_model", "")
+	v.SetDefault("data.request_fee", 0.0)
 
 	// UI config
 	v.SetDefault("ui.theme", "")
@@ -253,6 +263,14 @@ func (e *EnvSource) setAllKeys(v *viper.Viper) {
 	v.SetDefault("ui.table_page_size", 0)
 	v.SetDefault("ui.date_format", "")
 	v.SetDefault("ui.time_format", "")
+	v.SetDefault("ui.burn_rate_smoothing", "")
+	v.SetDefault("ui.burn_alarm_threshold", 0.0)
+	v.SetDefault("ui.burn_alarm_duration", 0)
+	v.SetDefault("ui.burn_alarm_style", "")
+	v.SetDefault("ui.low_power", "")
+	v.SetDefault("ui.low_power_refresh_rate", 0)
+	v.SetDefault("ui.locale", "")
+	v.SetDefault("ui.session_link", "")
 
 	// Performance config
 	v.SetDefault("performance.worker_count", 0)
@@ -274,6 +292,47 @@ func (e *EnvSource) setAllKeys(v *viper.Viper) {
 	v.SetDefault("debug.profile_memory", false)
 	v.SetDefault("debug.trace_file", "")
 	v.SetDefault("debug.metrics_port", 0)
+	v.SetDefault("debug.watchdog.enabled", false)
+	v.SetDefault("debug.watchdog.interval", 0)
+	v.SetDefault("debug.watchdog.max_rss_mb", 0)
+	v.SetDefault("debug.watchdog.max_heap_mb", 0)
+	v.SetDefault("debug.watchdog.max_goroutines", 0)
+	v.SetDefault("debug.watchdog.profile_dir", "")
+
+	// Cache config
+	v.SetDefault("cache.trash_ttl", 0)
+	v.SetDefault("cache.encryption", "")
+	v.SetDefault("cache.archive_after_months", 0)
+
+	// Export config
+	v.SetDefault("export.influxdb.enabled", false)
+	v.SetDefault("export.influxdb.file", "")
+	v.SetDefault("export.influxdb.url", "")
+	v.SetDefault("export.influxdb.token", "")
+	v.SetDefault("export.influxdb.measurement", "")
+
+	// Retention config
+	v.SetDefault("retention.redact_ids_after_days", 0)
+
+	// Alerts config
+	v.SetDefault("alerts.absence.enabled", false)
+	v.SetDefault("alerts.absence.after", 0)
+	v.SetDefault("alerts.absence.work_start", "")
+	v.SetDefault("alerts.absence.work_end", "")
+
+	// API config
+	v.SetDefault("api.address", "")
+	v.SetDefault("api.token", "")
+	v.SetDefault("api.identity_header", "")
+
+	// Budgets config
+	v.SetDefault("budgets.daily", 0.0)
+	v.SetDefault("budgets.weekly", 0.0)
+	v.SetDefault("budgets.monthly", 0.0)
+	v.SetDefault("budgets.warn_threshold", 0.0)
+
+	// Guardrails config
+	v.SetDefault("guardrails.message_input_tokens", 0)
 }
 
 // FlagSource loads configuration from command-line flags
@@ -372,9 +431,36 @@ func (m *DefaultMerger) Merge(base, override *Config) *Config {
 	if override.Data.MaxFileSize > 0 {
 		result.Data.MaxFileSize = override.Data.MaxFileSize
 	}
+	if override.Data.MaxLineSize > 0 {
+		result.Data.MaxLineSize = override.Data.MaxLineSize
+	}
 	if override.Data.CacheSize > 0 {
 		result.Data.CacheSize = override.Data.CacheSize
 	}
+	if override.Data.UpdateURL != "" {
+		result.Data.UpdateURL = override.Data.UpdateURL
+	}
+	if override.Data.UpdatePublicKey != "" {
+		result.Data.UpdatePublicKey = override.Data.UpdatePublicKey
+	}
+	if override.Data.SessionOverrides != "" {
+		result.Data.SessionOverrides = override.Data.SessionOverrides
+	}
+	if len(override.Data.Exclude) > 0 {
+		result.Data.Exclude = override.Data.Exclude
+	}
+	if override.Data.DuplicateProjects != "" {
+		result.Data.DuplicateProjects = override.Data.DuplicateProjects
+	}
+	if override.Data.CostModel != "" {
+		result.Data.CostModel = override.Data.CostModel
+	}
+	if len(override.Data.CostModels) > 0 {
+		result.Data.CostModels = override.Data.CostModels
+	}
+	if override.Data.RequestFee > 0 {
+		result.Data.RequestFee = override.Data.RequestFee
+	}
 
 	// Merge UI config
 	if override.UI.Theme != "" {
@@ -395,6 +481,33 @@ func (m *DefaultMerger) Merge(base, override *Config) *Config {
 	if override.UI.TimeFormat != "" {
 		result.UI.TimeFormat = override.UI.TimeFormat
 	}
+	if override.UI.BurnRateSmoothing != "" {
+		result.UI.BurnRateSmoothing = override.UI.BurnRateSmoothing
+	}
+	if override.UI.BurnAlarmThreshold > 0 {
+		result.UI.BurnAlarmThreshold = override.UI.BurnAlarmThreshold
+	}
+	if override.UI.BurnAlarmDuration > 0 {
+		result.UI.BurnAlarmDuration = override.UI.BurnAlarmDuration
+	}
+	if override.UI.BurnAlarmStyle != "" {
+		result.UI.BurnAlarmStyle = override.UI.BurnAlarmStyle
+	}
+	if override.UI.LowPower != "" {
+		result.UI.LowPower = override.UI.LowPower
+	}
+	if override.UI.LowPowerRefreshRate > 0 {
+		result.UI.LowPowerRefreshRate = override.UI.LowPowerRefreshRate
+	}
+	if override.UI.Locale != "" {
+		result.UI.Locale = override.UI.Locale
+	}
+	if override.UI.SessionLink != "" {
+		result.UI.SessionLink = override.UI.SessionLink
+	}
+	if len(override.UI.ColumnWidths) > 0 {
+		result.UI.ColumnWidths = override.UI.ColumnWidths
+	}
 
 	// Merge Performance config
 	if override.Performance.WorkerCount > 0 {
@@ -430,6 +543,109 @@ func (m *DefaultMerger) Merge(base, override *Config) *Config {
 		result.Subscription.AlertThreshold = override.Subscription.AlertThreshold
 	}
 
+	// Merge Cache config
+	if override.Cache.Dir != "" {
+		result.Cache.Dir = override.Cache.Dir
+	}
+	if override.Cache.TrashTTL > 0 {
+		result.Cache.TrashTTL = override.Cache.TrashTTL
+	}
+	if override.Cache.Encryption != "" {
+		result.Cache.Encryption = override.Cache.Encryption
+	}
+	if override.Cache.ArchiveAfterMonths > 0 {
+		result.Cache.ArchiveAfterMonths = override.Cache.ArchiveAfterMonths
+	}
+
+	// Merge Export config
+	if override.Export.InfluxDB.Enabled {
+		result.Export.InfluxDB.Enabled = true
+	}
+	if override.Export.InfluxDB.File != "" {
+		result.Export.InfluxDB.File = override.Export.InfluxDB.File
+	}
+	if override.Export.InfluxDB.URL != "" {
+		result.Export.InfluxDB.URL = override.Export.InfluxDB.URL
+	}
+	if override.Export.InfluxDB.Token != "" {
+		result.Export.InfluxDB.Token = override.Export.InfluxDB.Token
+	}
+	if override.Export.InfluxDB.Measurement != "" {
+		result.Export.InfluxDB.Measurement = override.Export.InfluxDB.Measurement
+	}
+
+	// Merge Limits notification channels
+	if len(override.Limits.Notifications) > 0 {
+		result.Limits.Notifications = override.Limits.Notifications
+	}
+	if override.Limits.WebhookURL != "" {
+		result.Limits.WebhookURL = override.Limits.WebhookURL
+	}
+
+	// Merge Retention config
+	if override.Retention.RedactIDsAfterDays > 0 {
+		result.Retention.RedactIDsAfterDays = override.Retention.RedactIDsAfterDays
+	}
+
+	// Merge Alerts config
+	if override.Alerts.Absence.Enabled {
+		result.Alerts.Absence.Enabled = true
+	}
+	if override.Alerts.Absence.After > 0 {
+		result.Alerts.Absence.After = override.Alerts.Absence.After
+	}
+	if override.Alerts.Absence.WorkStart != "" {
+		result.Alerts.Absence.WorkStart = override.Alerts.Absence.WorkStart
+	}
+	if override.Alerts.Absence.WorkEnd != "" {
+		result.Alerts.Absence.WorkEnd = override.Alerts.Absence.WorkEnd
+	}
+	if len(override.Alerts.Absence.WorkDays) > 0 {
+		result.Alerts.Absence.WorkDays = override.Alerts.Absence.WorkDays
+	}
+	if len(override.Alerts.Rules) > 0 {
+		result.Alerts.Rules = override.Alerts.Rules
+	}
+
+	// Merge API config
+	if override.API.Address != "" {
+		result.API.Address = override.API.Address
+	}
+	if override.API.Token != "" {
+		result.API.Token = override.API.Token
+	}
+	if len(override.API.TrustedProxies) > 0 {
+		result.API.TrustedProxies = override.API.TrustedProxies
+	}
+	if override.API.IdentityHeader != "" {
+		result.API.IdentityHeader = override.API.IdentityHeader
+	}
+	if len(override.API.CostCenters) > 0 {
+		result.API.CostCenters = override.API.CostCenters
+	}
+
+	// Merge Budgets config
+	if override.Budgets.Daily > 0 {
+		result.Budgets.Daily = override.Budgets.Daily
+	}
+	if override.Budgets.Weekly > 0 {
+		result.Budgets.Weekly = override.Budgets.Weekly
+	}
+	if override.Budgets.Monthly > 0 {
+		result.Budgets.Monthly = override.Budgets.Monthly
+	}
+	if override.Budgets.WarnThreshold > 0 {
+		result.Budgets.WarnThreshold = override.Budgets.WarnThreshold
+	}
+
+	// Merge Guardrails config
+	if override.Guardrails.MessageInputTokens > 0 {
+		result.Guardrails.MessageInputTokens = override.Guardrails.MessageInputTokens
+	}
+	if len(override.Guardrails.Models) > 0 {
+		result.Guardrails.Models = override.Guardrails.Models
+	}
+
 	// Merge Debug config (boolean fields always override)
 	result.Debug = override.Debug
 
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSource_CostModels(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`data:
+  cost_model: api
+  cost_models:
+    - path: ~/Work.Client/.claude/projects
+      model: bedrock-regional
+    - path: /Volumes/Backup/claude
+      model: vertex
+`), 0600))
+
+	cfg, err := NewFileSource(path).Load()
+	require.NoError(t, err)
+	assert.Equal(t, "api", cfg.Data.CostModel)
+	assert.Equal(t, []CostModelPath{
+		{Path: "~/Work.Client/.claude/projects", Model: "bedrock-regional"},
+		{Path: "/Volumes/Backup/claude", Model: "vertex"},
+	}, cfg.Data.CostModels, "paths keep their case and dots")
+	assert.Empty(t, NewStandardValidator().Problems((&DefaultMerger{}).Merge(DefaultConfig(), cfg)))
+}
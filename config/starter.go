@@ -0,0 +1,71 @@
+package config
+
+// StarterConfig is the configuration file written by config init: the settings most often changed,
+// with the others left at their defaults. Every value is valid as written.
+const StarterConfig = `# claudecat configuration
+#
+# Settings left out or commented out keep their defaults; run "claudecat config list" to see every
+# setting and "claudecat config validate" after editing this file.
+
+app:
+  # IANA timezone such as Europe/Berlin, used for days, weeks and reset times; Local follows the system
+  timezone: Local
+  # debug, info, warn or error
+  log_level: info
+
+subscription:
+  # free, pro, team, max5, max20 or custom
+  plan: pro
+  # With plan custom, the token and cost limits of a 5-hour session
+  # custom_token_limit: 0
+  # custom_cost_limit: 0
+  # Fractions of the session limit at which to warn and alert
+  warn_threshold: 0.8
+  alert_threshold: 0.95
+
+data:
+  # Directories holding Claude Code logs; when none are given they are discovered
+  # paths:
+  #   - ~/.claude/projects
+  # Count a message logged in several files once
+  deduplication: false
+  # A conversation logged in several project directories, as when synced between machines:
+  # warn about it, or merge by loading its largest copy only
+  duplicate_projects: warn
+  # Log directories to skip, relative to a data path
+  # exclude:
+  #   - -Users-me-Dropbox-*
+  # Prices: default (shipped with claudecat or installed by update-data) or litellm
+  pricing_source: default
+  # Never fetch prices from the network
+  pricing_offline_mode: false
+  # Billing the costs are shown for: subscription (list prices), api, bedrock or vertex,
+  # the last two with -regional for regional endpoints; cost_models sets it per data path
+  cost_model: subscription
+  # cost_models:
+  #   - path: ~/work/.claude/projects
+  #     model: bedrock
+
+ui:
+  # dark, light, high-contrast or auto
+  theme: dark
+  refresh_rate: 1s
+  # Timezone of the monitor when it differs from app.timezone
+  # timezone: America/New_York
+  # Separators of displayed numbers: en (1,234.56), de (1.234,56) or fr (1 234,56)
+  # locale: en
+
+limits:
+  # Notify when a session nears its limit: desktop, sound, webhook, email
+  notifications: [desktop]
+  # webhook_url: https://hooks.example.com/claudecat
+
+# Spending budgets in USD, 0 for none
+budgets:
+  daily: 0
+  weekly: 0
+  monthly: 0
+
+cache:
+  dir: ~/.cache/claudecat
+`
@@ -2,10 +2,17 @@ package config
 
 import (
 	"fmt"
+	"maps"
+	"net"
+	"net/netip"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
+
+	"github.com/penwyp/claudecat/humanize"
 )
 
 // ValidationRule represents a single validation rule
@@ -38,38 +45,40 @@ func (v *StandardValidator) AddRule(rule ValidationRule) {
 
 // Validate validates the entire configuration
 func (v *StandardValidator) Validate(cfg *Config) error {
-	var errors []string
-
-	// Validate App config
-	if err := v.validateApp(&cfg.App); err != nil {
-		errors = append(errors, fmt.Sprintf("app: %v", err))
-	}
-
-	// Validate Data config
-	if err := v.validateData(&cfg.Data); err != nil {
-		errors = append(errors, fmt.Sprintf("data: %v", err))
-	}
-
-	// Validate UI config
-	if err := v.validateUI(&cfg.UI); err != nil {
-		errors = append(errors, fmt.Sprintf("ui: %v", err))
-	}
-
-	// Validate Performance config
-	if err := v.validatePerformance(&cfg.Performance); err != nil {
-		errors = append(errors, fmt.Sprintf("performance: %v", err))
-	}
-
-	// Validate Subscription config
-	if err := v.validateSubscription(&cfg.Subscription); err != nil {
-		errors = append(errors, fmt.Sprintf("subscription: %v", err))
+	if problems := v.Problems(cfg); len(problems) > 0 {
+		return fmt.Errorf("validation errors: %s", strings.Join(problems, "; "))
 	}
+	return nil
+}
 
-	if len(errors) > 0 {
-		return fmt.Errorf("validation errors: %s", strings.Join(errors, "; "))
+// Problems returns every invalid setting of cfg, each as "section: key: reason"
+func (v *StandardValidator) Problems(cfg *Config) []string {
+	var problems []string
+	add := func(section string, err error) {
+		if err == nil {
+			return
+		}
+		for _, problem := range strings.Split(err.Error(), "; ") {
+			problems = append(problems, section+": "+problem)
+		}
 	}
 
-	return nil
+	add("app", v.validateApp(&cfg.App))
+	add("data", v.validateData(&cfg.Data))
+	add("ui", v.validateUI(&cfg.UI))
+	add("performance", v.validatePerformance(&cfg.Performance))
+	add("subscription", v.validateSubscription(&cfg.Subscription))
+	add("cache", v.validateCache(&cfg.Cache))
+	add("export", v.validateExport(&cfg.Export))
+	add("alerts", v.validateAlerts(&cfg.Alerts))
+	add("api", v.validateAPI(&cfg.API))
+	add("budgets", v.validateBudgets(&cfg.Budgets))
+	add("guardrails", v.validateGuardrails(&cfg.Guardrails))
+	add("debug", v.validateDebug(&cfg.Debug))
+	if cfg.Retention.RedactIDsAfterDays < 0 {
+		problems = append(problems, "retention: redact_ids_after_days: must be non-negative")
+	}
+	return problems
 }
 
 // addStandardRules adds the standard validation rules
@@ -135,6 +144,14 @@ func (v *StandardValidator) validateData(data *DataConfig) error {
 		errors = append(errors, "max_file_size: must not exceed 10GB")
 	}
 
+	// Validate max line size (0 uses the default)
+	if data.MaxLineSize != 0 && data.MaxLineSize < 64*1024 {
+		errors = append(errors, "max_line_size: must be at least 64KB")
+	}
+	if data.MaxLineSize > 1024*1024*1024 {
+		errors = append(errors, "max_line_size: must not exceed 1GB")
+	}
+
 	// Validate cache size
 	if data.CacheSize < 0 {
 		errors = append(errors, "cache_size: must be non-negative")
@@ -143,6 +160,45 @@ func (v *StandardValidator) validateData(data *DataConfig) error {
 		errors = append(errors, "cache_size: must not exceed 10GB")
 	}
 
+	// Validate pricing source
+	if data.PricingSource != "" && data.PricingSource != "default" && data.PricingSource != "litellm" {
+		errors = append(errors, fmt.Sprintf("pricing_source: unknown pricing source: %s (valid: default, litellm)", data.PricingSource))
+	}
+
+	// Validate cost models
+	if data.CostModel != "" {
+		if err := ValidateCostModel(data.CostModel); err != nil {
+			errors = append(errors, fmt.Sprintf("cost_model: %v", err))
+		}
+	}
+	for i, entry := range data.CostModels {
+		if entry.Path == "" {
+			errors = append(errors, fmt.Sprintf("cost_models[%d].path: required", i))
+		}
+		if err := ValidateCostModel(entry.Model); err != nil {
+			errors = append(errors, fmt.Sprintf("cost_models[%d].model: %v", i, err))
+		}
+	}
+	if data.RequestFee < 0 {
+		errors = append(errors, "request_fee: must be non-negative")
+	}
+
+	// Validate signed data bundle source
+	if data.UpdateURL != "" {
+		if err := ValidateUpdateURL(data.UpdateURL); err != nil {
+			errors = append(errors, fmt.Sprintf("update_url: %v", err))
+		}
+	}
+
+	for i, pattern := range data.Exclude {
+		if _, err := path.Match(pattern, ""); err != nil {
+			errors = append(errors, fmt.Sprintf("exclude[%d]: invalid pattern: %s", i, pattern))
+		}
+	}
+	if data.DuplicateProjects != "" && data.DuplicateProjects != "warn" && data.DuplicateProjects != "merge" {
+		errors = append(errors, fmt.Sprintf("duplicate_projects: invalid mode: %s (valid: warn, merge)", data.DuplicateProjects))
+	}
+
 	if len(errors) > 0 {
 		return fmt.Errorf("%s", strings.Join(errors, "; "))
 	}
@@ -158,6 +214,13 @@ func (v *StandardValidator) validateUI(ui *UIConfig) error {
 		errors = append(errors, fmt.Sprintf("theme: %v", err))
 	}
 
+	// Validate timezone
+	if ui.Timezone != "" && ui.Timezone != "Local" {
+		if _, err := time.LoadLocation(ui.Timezone); err != nil {
+			errors = append(errors, fmt.Sprintf("timezone: invalid timezone: %s", ui.Timezone))
+		}
+	}
+
 	// Validate refresh rate
 	if ui.RefreshRate < 100*time.Millisecond {
 		errors = append(errors, "refresh_rate: must be at least 100ms")
@@ -196,6 +259,53 @@ func (v *StandardValidator) validateUI(ui *UIConfig) error {
 		}
 	}
 
+	// Validate burn rate smoothing
+	if ui.BurnRateSmoothing != "" {
+		if err := ValidateBurnRateSmoothing(ui.BurnRateSmoothing); err != nil {
+			errors = append(errors, fmt.Sprintf("burn_rate_smoothing: %v", err))
+		}
+	}
+
+	// Validate burn rate alarm
+	if ui.BurnAlarmThreshold < 0 {
+		errors = append(errors, "burn_alarm_threshold: must be non-negative")
+	}
+	if ui.BurnAlarmDuration < 0 {
+		errors = append(errors, "burn_alarm_duration: must be non-negative")
+	}
+	if ui.BurnAlarmStyle != "" {
+		if err := ValidateBurnAlarmStyle(ui.BurnAlarmStyle); err != nil {
+			errors = append(errors, fmt.Sprintf("burn_alarm_style: %v", err))
+		}
+	}
+
+	// Validate low-power mode
+	if ui.LowPower != "" {
+		if err := ValidateLowPowerMode(ui.LowPower); err != nil {
+			errors = append(errors, fmt.Sprintf("low_power: %v", err))
+		}
+	}
+	if ui.LowPowerRefreshRate < 0 {
+		errors = append(errors, "low_power_refresh_rate: must be non-negative")
+	}
+
+	// Validate number locale
+	if !humanize.ValidLocale(ui.Locale) {
+		errors = append(errors, fmt.Sprintf("locale: invalid locale: %s (valid: %s)", ui.Locale, strings.Join(humanize.LocaleNames(), ", ")))
+	}
+
+	// Validate the session link template
+	if ui.SessionLink != "" && !strings.Contains(ui.SessionLink, "{id}") {
+		errors = append(errors, "session_link: must contain {id}")
+	}
+
+	// Validate the column widths
+	for _, column := range slices.Sorted(maps.Keys(ui.ColumnWidths)) {
+		if ui.ColumnWidths[column] < 0 {
+			errors = append(errors, fmt.Sprintf("column_widths.%s: must be non-negative", column))
+		}
+	}
+
 	if len(errors) > 0 {
 		return fmt.Errorf("%s", strings.Join(errors, "; "))
 	}
@@ -286,18 +396,204 @@ func (v *StandardValidator) validateSubscription(sub *SubscriptionConfig) error
 	return nil
 }
 
+func (v *StandardValidator) validateCache(cache *CacheConfig) error {
+	var errors []string
+
+	if cache.TrashTTL < 0 {
+		errors = append(errors, "trash_ttl: must be non-negative")
+	}
+	if cache.MaxDiskSize < 0 {
+		errors = append(errors, "max_disk_size: must be non-negative (0 disables the quota)")
+	}
+	if cache.ArchiveAfterMonths < 0 {
+		errors = append(errors, "archive_after_months: must be non-negative (0 disables archiving)")
+	}
+	switch cache.Encryption {
+	case "", "off", "keychain", "passphrase":
+	default:
+		errors = append(errors, fmt.Sprintf("encryption: must be off, keychain or passphrase, got %q", cache.Encryption))
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("%s", strings.Join(errors, "; "))
+	}
+	return nil
+}
+
+func (v *StandardValidator) validateExport(export *ExportConfig) error {
+	var errors []string
+
+	influx := export.InfluxDB
+	if influx.Enabled && influx.File == "" && influx.URL == "" {
+		errors = append(errors, "influxdb: file or url is required when enabled")
+	}
+	if influx.URL != "" && !strings.HasPrefix(influx.URL, "http://") && !strings.HasPrefix(influx.URL, "https://") {
+		errors = append(errors, "influxdb.url: must start with http:// or https://")
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("%s", strings.Join(errors, "; "))
+	}
+	return nil
+}
+
+func (v *StandardValidator) validateAPI(api *APIConfig) error {
+	var errors []string
+
+	if api.Address != "" {
+		if _, _, err := net.SplitHostPort(api.Address); err != nil {
+			errors = append(errors, fmt.Sprintf("address: %v", err))
+		}
+	}
+	for _, proxy := range api.TrustedProxies {
+		if _, err := ParseTrustedProxy(proxy); err != nil {
+			errors = append(errors, fmt.Sprintf("trusted_proxies: %v", err))
+		}
+	}
+	if len(api.CostCenters) > 0 && api.IdentityHeader == "" {
+		errors = append(errors, "identity_header: required when cost centers are configured")
+	}
+	names := make(map[string]bool)
+	for i, center := range api.CostCenters {
+		if center.Name == "" {
+			errors = append(errors, fmt.Sprintf("cost_centers[%d].name: required", i))
+		} else if names[center.Name] {
+			errors = append(errors, fmt.Sprintf("cost_centers[%d].name: duplicate %s", i, center.Name))
+		}
+		names[center.Name] = true
+		for _, pattern := range center.Projects {
+			if _, err := path.Match(pattern, ""); err != nil {
+				errors = append(errors, fmt.Sprintf("cost_centers[%d].projects: invalid pattern %s", i, pattern))
+			}
+		}
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("%s", strings.Join(errors, "; "))
+	}
+	return nil
+}
+
+func (v *StandardValidator) validateBudgets(budgets *BudgetConfig) error {
+	var errors []string
+
+	for _, budget := range []struct {
+		name  string
+		value float64
+	}{{"daily", budgets.Daily}, {"weekly", budgets.Weekly}, {"monthly", budgets.Monthly}} {
+		if budget.value < 0 {
+			errors = append(errors, fmt.Sprintf("%s: must be non-negative", budget.name))
+		}
+	}
+	if budgets.WarnThreshold < 0 || budgets.WarnThreshold > 1 {
+		errors = append(errors, "warn_threshold: must be between 0 and 1")
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("%s", strings.Join(errors, "; "))
+	}
+	return nil
+}
+
+func (v *StandardValidator) validateGuardrails(guardrails *GuardrailsConfig) error {
+	var errors []string
+
+	if guardrails.MessageInputTokens < 0 {
+		errors = append(errors, "message_input_tokens: must be non-negative")
+	}
+	for i, model := range guardrails.Models {
+		if model.Model == "" {
+			errors = append(errors, fmt.Sprintf("models[%d].model: required", i))
+		} else if _, err := path.Match(model.Model, ""); err != nil {
+			errors = append(errors, fmt.Sprintf("models[%d].model: invalid pattern %s", i, model.Model))
+		}
+		if model.MessageInputTokens < 0 {
+			errors = append(errors, fmt.Sprintf("models[%d].message_input_tokens: must be non-negative", i))
+		}
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("%s", strings.Join(errors, "; "))
+	}
+	return nil
+}
+
+// validateDebug validates the watchdog thresholds
+func (v *StandardValidator) validateDebug(debug *DebugConfig) error {
+	var errors []string
+
+	watchdog := debug.Watchdog
+	if watchdog.Interval < 0 {
+		errors = append(errors, "watchdog.interval: must be non-negative")
+	}
+	if watchdog.MaxRSSMB < 0 {
+		errors = append(errors, "watchdog.max_rss_mb: must be non-negative")
+	}
+	if watchdog.MaxHeapMB < 0 {
+		errors = append(errors, "watchdog.max_heap_mb: must be non-negative")
+	}
+	if watchdog.MaxGoroutines < 0 {
+		errors = append(errors, "watchdog.max_goroutines: must be non-negative")
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("%s", strings.Join(errors, "; "))
+	}
+	return nil
+}
+
+func (v *StandardValidator) validateAlerts(alerts *AlertsConfig) error {
+	var errors []string
+
+	absence := alerts.Absence
+	if absence.Enabled {
+		if absence.After <= 0 {
+			errors = append(errors, "absence.after: must be positive when enabled")
+		}
+		start, startErr := ParseClockTime(absence.WorkStart)
+		if startErr != nil {
+			errors = append(errors, fmt.Sprintf("absence.work_start: %v", startErr))
+		}
+		end, endErr := ParseClockTime(absence.WorkEnd)
+		if endErr != nil {
+			errors = append(errors, fmt.Sprintf("absence.work_end: %v", endErr))
+		}
+		if startErr == nil && endErr == nil && end <= start {
+			errors = append(errors, "absence.work_end: must be after work_start")
+		}
+		for _, day := range absence.WorkDays {
+			if _, err := ParseWeekday(day); err != nil {
+				errors = append(errors, fmt.Sprintf("absence.work_days: %v", err))
+			}
+		}
+	}
+	for i, rule := range alerts.Rules {
+		if _, err := ParseAlertRule(rule); err != nil {
+			errors = append(errors, fmt.Sprintf("rules[%d]: %v", i, err))
+		}
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("%s", strings.Join(errors, "; "))
+	}
+	return nil
+}
+
 // Built-in validation functions
 
 // ValidatePlan validates subscription plan
 func ValidatePlan(plan string) error {
 	validPlans := map[string]bool{
-		"free": true,
-		"pro":  true,
-		"team": true,
+		"free":   true,
+		"pro":    true,
+		"team":   true,
+		"max5":   true,
+		"max20":  true,
+		"custom": true,
 	}
 
 	if !validPlans[plan] {
-		return fmt.Errorf("invalid plan: %s (valid: free, pro, team)", plan)
+		return fmt.Errorf("invalid plan: %s (valid: free, pro, team, max5, max20, custom)", plan)
 	}
 	return nil
 }
@@ -317,6 +613,82 @@ func ValidateTheme(theme string) error {
 	return nil
 }
 
+// ValidateUpdateURL validates the URL data bundles are downloaded from
+func ValidateUpdateURL(url string) error {
+	if !strings.HasPrefix(url, "https://") {
+		return fmt.Errorf("must use https://")
+	}
+	return nil
+}
+
+// SmoothingModes lists the burn rate smoothing modes, as ui.burn_rate_smoothing and --smoothing take them
+var SmoothingModes = []string{"instant", "ema", "hourly"}
+
+// ValidateBurnRateSmoothing validates the burn rate smoothing mode
+func ValidateBurnRateSmoothing(mode string) error {
+	if !slices.Contains(SmoothingModes, mode) {
+		return fmt.Errorf("invalid smoothing mode: %s (valid: %s)", mode, strings.Join(SmoothingModes, ", "))
+	}
+	return nil
+}
+
+// ValidateBurnAlarmStyle validates how the burn rate alarm is signalled
+func ValidateBurnAlarmStyle(style string) error {
+	validStyles := map[string]bool{
+		"bell":  true,
+		"flash": true,
+		"both":  true,
+	}
+
+	if !validStyles[style] {
+		return fmt.Errorf("invalid alarm style: %s (valid: bell, flash, both)", style)
+	}
+	return nil
+}
+
+// ValidateCostModel validates the billing arrangement entries are costed under
+func ValidateCostModel(model string) error {
+	validModels := map[string]bool{
+		"subscription":     true,
+		"api":              true,
+		"bedrock":          true,
+		"bedrock-regional": true,
+		"vertex":           true,
+		"vertex-regional":  true,
+	}
+
+	if !validModels[model] {
+		return fmt.Errorf("invalid cost model: %s (valid: subscription, api, bedrock, bedrock-regional, vertex, vertex-regional)", model)
+	}
+	return nil
+}
+
+// ValidateLowPowerMode validates when low-power mode applies
+func ValidateLowPowerMode(mode string) error {
+	validModes := map[string]bool{
+		"auto": true,
+		"on":   true,
+		"off":  true,
+	}
+
+	if !validModes[mode] {
+		return fmt.Errorf("invalid low-power mode: %s (valid: auto, on, off)", mode)
+	}
+	return nil
+}
+
+// ParseTrustedProxy parses a trusted proxy given as an IP address or CIDR prefix
+func ParseTrustedProxy(proxy string) (netip.Prefix, error) {
+	if prefix, err := netip.ParsePrefix(proxy); err == nil {
+		return prefix.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(proxy)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid address or CIDR: %s", proxy)
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
 // ValidateLogLevel validates log level
 func ValidateLogLevel(level string) error {
 	validLevels := map[string]bool{
@@ -355,3 +727,24 @@ func ValidatePaths(paths []string) error {
 
 	return nil
 }
+
+// ParseClockTime parses an HH:MM time of day into an offset from midnight
+func ParseClockTime(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day: %s (expected HH:MM)", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// ParseWeekday parses a weekday name or three-letter abbreviation
+func ParseWeekday(s string) (time.Weekday, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		full := strings.ToLower(d.String())
+		if name == full || name == full[:3] {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid weekday: %s", s)
+}
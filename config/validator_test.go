@@ -15,6 +15,9 @@ func TestValidatePlan(t *testing.T) {
 		{"free", false},
 		{"pro", false},
 		{"team", false},
+		{"max5", false},
+		{"max20", false},
+		{"custom", false},
 		{"invalid", true},
 		{"", true},
 	}
@@ -56,6 +59,29 @@ func TestValidateTheme(t *testing.T) {
 	}
 }
 
+func TestValidateUpdateURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr bool
+	}{
+		{"https://example.com/claudecat-data.json", false},
+		{"http://example.com/claudecat-data.json", true},
+		{"file:///tmp/claudecat-data.json", true},
+		{"example.com/claudecat-data.json", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			err := ValidateUpdateURL(tt.url)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestValidateLogLevel(t *testing.T) {
 	tests := []struct {
 		level   string
@@ -271,6 +297,17 @@ func TestStandardValidator_ValidateUI(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "invalid low-power mode",
+			ui: UIConfig{
+				Theme:         "dark",
+				RefreshRate:   time.Second,
+				ChartHeight:   10,
+				TablePageSize: 20,
+				LowPower:      "battery",
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -409,6 +446,64 @@ func TestStandardValidator_ValidateSubscription(t *testing.T) {
 	}
 }
 
+func TestStandardValidator_ValidateAPI(t *testing.T) {
+	validator := NewStandardValidator()
+	center := CostCenterConfig{Name: "web", Identities: []string{"web-team"}, Projects: []string{"web*"}}
+
+	tests := []struct {
+		name    string
+		api     APIConfig
+		wantErr bool
+	}{
+		{
+			name: "valid config",
+			api: APIConfig{
+				TrustedProxies: []string{"10.0.0.0/8", "::1"},
+				IdentityHeader: "X-Forwarded-User",
+				CostCenters:    []CostCenterConfig{center},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "invalid trusted proxy",
+			api:     APIConfig{TrustedProxies: []string{"10.0.0.0/40"}},
+			wantErr: true,
+		},
+		{
+			name:    "cost centers without identity header",
+			api:     APIConfig{CostCenters: []CostCenterConfig{center}},
+			wantErr: true,
+		},
+		{
+			name: "duplicate cost center",
+			api: APIConfig{
+				IdentityHeader: "X-Forwarded-User",
+				CostCenters:    []CostCenterConfig{center, center},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid project pattern",
+			api: APIConfig{
+				IdentityHeader: "X-Forwarded-User",
+				CostCenters:    []CostCenterConfig{{Name: "web", Projects: []string{"web["}}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validator.validateAPI(&tt.api)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestStandardValidator_Validate(t *testing.T) {
 	validator := NewStandardValidator()
 
@@ -423,3 +518,36 @@ func TestStandardValidator_Validate(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "app:")
 }
+
+func TestStandardValidator_Problems(t *testing.T) {
+	validator := NewStandardValidator()
+	assert.Empty(t, validator.Problems(DefaultConfig()))
+
+	cfg := DefaultConfig()
+	cfg.App.Timezone = "Mars/Olympus"
+	cfg.UI.Timezone = "Europe/Atlantis"
+	cfg.Subscription.Plan = "enterprise"
+	cfg.Data.PricingSource = "guess"
+	cfg.Data.CostModels = []CostModelPath{{Path: "~/work", Model: "azure"}, {Model: "api"}}
+	cfg.Data.RequestFee = -0.01
+	cfg.UI.Locale = "klingon"
+	cfg.UI.SessionLink = "claudecat://sessions"
+	cfg.UI.ColumnWidths = map[string]int{"project": -5}
+	cfg.Debug.Watchdog.MaxGoroutines = -1
+	cfg.Data.Exclude = []string{"-Users-me-*", "[bad"}
+	cfg.Data.DuplicateProjects = "ignore"
+	problems := validator.Problems(cfg)
+	assert.Contains(t, problems, "app: timezone: invalid timezone: Mars/Olympus")
+	assert.Contains(t, problems, "ui: timezone: invalid timezone: Europe/Atlantis")
+	assert.Contains(t, problems, "subscription: plan: invalid plan: enterprise (valid: free, pro, team, max5, max20, custom)")
+	assert.Contains(t, problems, "data: pricing_source: unknown pricing source: guess (valid: default, litellm)")
+	assert.Contains(t, problems, "data: cost_models[0].model: invalid cost model: azure (valid: subscription, api, bedrock, bedrock-regional, vertex, vertex-regional)")
+	assert.Contains(t, problems, "data: cost_models[1].path: required")
+	assert.Contains(t, problems, "data: request_fee: must be non-negative")
+	assert.Contains(t, problems, "ui: locale: invalid locale: klingon (valid: de, en, fr)")
+	assert.Contains(t, problems, "ui: session_link: must contain {id}")
+	assert.Contains(t, problems, "ui: column_widths.project: must be non-negative")
+	assert.Contains(t, problems, "debug: watchdog.max_goroutines: must be non-negative")
+	assert.Contains(t, problems, "data: exclude[1]: invalid pattern: [bad")
+	assert.Contains(t, problems, "data: duplicate_projects: invalid mode: ignore (valid: warn, merge)")
+}
@@ -1,26 +1,36 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"runtime"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
 )
 
+// defaultPollInterval is how often a configuration file that cannot be watched is checked for changes
+const defaultPollInterval = 2 * time.Second
+
 // Watcher watches configuration files for changes and reloads them
 type Watcher struct {
 	path      string
 	config    *Config
 	loader    *Loader
 	onChange  func(*Config)
-	watcher   *fsnotify.Watcher
+	watcher   *fsnotify.Watcher // Nil when polling
 	stopCh    chan struct{}
 	mu        sync.RWMutex
 	debouncer *debouncer
+
+	// Polling replaces file system events when the inotify limits are reached
+	polling      bool
+	pollInterval time.Duration
 }
 
 // NewWatcher creates a new configuration file watcher
@@ -28,10 +38,16 @@ func NewWatcher(path string, onChange func(*Config)) (*Watcher, error) {
 	// Expand environment variables in path
 	expandedPath := os.ExpandEnv(path)
 
-	// Create fsnotify watcher
+	// Create fsnotify watcher, polling instead when no more inotify instances can be created
 	fsWatcher, err := fsnotify.NewWatcher()
+	polling := false
 	if err != nil {
-		return nil, fmt.Errorf("failed to create file watcher: %w", err)
+		if !IsWatchLimitError(err) {
+			return nil, fmt.Errorf("failed to create file watcher: %w", err)
+		}
+		log.Printf("failed to create file watcher for %s: %v; polling every %v instead. %s",
+			expandedPath, err, defaultPollInterval, WatchLimitHint(err))
+		fsWatcher, polling = nil, true
 	}
 
 	// Create loader for reloading configuration
@@ -40,12 +56,14 @@ func NewWatcher(path string, onChange func(*Config)) (*Watcher, error) {
 	loader.AddValidator(NewStandardValidator())
 
 	w := &Watcher{
-		path:      expandedPath,
-		loader:    loader,
-		onChange:  onChange,
-		watcher:   fsWatcher,
-		stopCh:    make(chan struct{}),
-		debouncer: newDebouncer(500 * time.Millisecond),
+		path:         expandedPath,
+		loader:       loader,
+		onChange:     onChange,
+		watcher:      fsWatcher,
+		stopCh:       make(chan struct{}),
+		debouncer:    newDebouncer(500 * time.Millisecond),
+		polling:      polling,
+		pollInterval: defaultPollInterval,
 	}
 
 	return w, nil
@@ -63,13 +81,26 @@ func (w *Watcher) Start() error {
 	w.config = cfg
 	w.mu.Unlock()
 
-	// Watch the file and its directory
-	if err := w.addWatches(); err != nil {
-		return fmt.Errorf("failed to add file watches: %w", err)
+	// Watch the file and its directory, polling the file instead when the watch limit is reached
+	if !w.polling {
+		if err := w.addWatches(); err != nil {
+			if !IsWatchLimitError(err) {
+				return fmt.Errorf("failed to add file watches: %w", err)
+			}
+			log.Printf("%v; polling every %v instead. %s", err, w.pollInterval, WatchLimitHint(err))
+			_ = w.watcher.Close()
+			w.watcher, w.polling = nil, true
+		}
 	}
 
 	// Start event processing goroutine
-	go w.processEvents()
+	if w.polling {
+		// The state to compare with is taken now so that changes made after Start returns are seen
+		mod, size := w.fileState()
+		go w.poll(mod, size)
+	} else {
+		go w.processEvents()
+	}
 
 	return nil
 }
@@ -78,9 +109,37 @@ func (w *Watcher) Start() error {
 func (w *Watcher) Stop() error {
 	close(w.stopCh)
 	w.debouncer.stop()
+	if w.watcher == nil {
+		return nil
+	}
 	return w.watcher.Close()
 }
 
+// Polling reports whether the configuration file is polled because it could not be watched
+func (w *Watcher) Polling() bool {
+	return w.polling
+}
+
+// IsWatchLimitError reports whether err means the inotify limits were reached: ENOSPC when adding
+// a watch past fs.inotify.max_user_watches, EMFILE when creating a watcher past
+// fs.inotify.max_user_instances
+func IsWatchLimitError(err error) bool {
+	return errors.Is(err, syscall.ENOSPC) || errors.Is(err, syscall.EMFILE)
+}
+
+// WatchLimitHint suggests the sysctl setting that raises the limit behind a watch limit error
+func WatchLimitHint(err error) string {
+	if runtime.GOOS != "linux" {
+		return "Raise the open file limit to watch it again."
+	}
+	setting := "fs.inotify.max_user_watches=524288"
+	if errors.Is(err, syscall.EMFILE) {
+		setting = "fs.inotify.max_user_instances=512"
+	}
+	return fmt.Sprintf("To watch it again, raise the inotify limit with: sudo sysctl %s "+
+		"(add it to /etc/sysctl.d/ to keep it after a reboot)", setting)
+}
+
 // Current returns the current configuration
 func (w *Watcher) Current() *Config {
 	w.mu.RLock()
@@ -128,6 +187,37 @@ func (w *Watcher) processEvents() {
 	}
 }
 
+// poll checks the configuration file for changes from the given state every pollInterval, for when
+// it cannot be watched
+func (w *Watcher) poll(lastMod time.Time, lastSize int64) {
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			mod, size := w.fileState()
+			if mod.Equal(lastMod) && size == lastSize {
+				continue
+			}
+			lastMod, lastSize = mod, size
+			w.handleEvent(fsnotify.Event{Name: w.path, Op: fsnotify.Write})
+
+		case <-w.stopCh:
+			return
+		}
+	}
+}
+
+// fileState returns the modification time and size of the configuration file, -1 when it is missing
+func (w *Watcher) fileState() (time.Time, int64) {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return time.Time{}, -1
+	}
+	return info.ModTime(), info.Size()
+}
+
 // handleEvent handles a single file system event
 func (w *Watcher) handleEvent(event fsnotify.Event) {
 	// We're interested in the config file
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsWatchLimitError(t *testing.T) {
+	assert.True(t, IsWatchLimitError(fmt.Errorf("failed to watch: %w", syscall.ENOSPC)))
+	assert.True(t, IsWatchLimitError(syscall.EMFILE))
+	assert.False(t, IsWatchLimitError(os.ErrNotExist))
+
+	if runtime.GOOS == "linux" {
+		assert.Contains(t, WatchLimitHint(syscall.ENOSPC), "sysctl fs.inotify.max_user_watches=")
+		assert.Contains(t, WatchLimitHint(syscall.EMFILE), "sysctl fs.inotify.max_user_instances=")
+	}
+}
+
+func TestWatcher_Polling(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("app:\n  log_level: info\n"), 0644))
+
+	var reloads atomic.Int32
+	w, err := NewWatcher(path, func(*Config) { reloads.Add(1) })
+	require.NoError(t, err)
+	// As after a watch limit error
+	require.NoError(t, w.watcher.Close())
+	w.watcher, w.polling, w.pollInterval = nil, true, 10*time.Millisecond
+	require.NoError(t, w.Start())
+	defer w.Stop()
+	assert.True(t, w.Polling())
+
+	require.NoError(t, os.WriteFile(path, []byte("app:\n  log_level: debug\n"), 0644))
+	require.Eventually(t, func() bool { return reloads.Load() > 0 }, 5*time.Second, 20*time.Millisecond)
+	assert.Equal(t, "debug", w.Current().App.LogLevel)
+}
@@ -0,0 +1,89 @@
+// Package events provides a typed publish/subscribe bus shared by claudecat subsystems.
+// Events are plain Go values; subscribers are selected by the static type of the event.
+package events
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/penwyp/claudecat/logging"
+)
+
+// Bus delivers published events to the handlers subscribed to their type
+type Bus struct {
+	mu     sync.RWMutex
+	nextID uint64
+	subs   map[reflect.Type][]subscription
+}
+
+// subscription is a registered handler; handler holds a func(T) for the keyed type T
+type subscription struct {
+	id      uint64
+	handler any
+}
+
+// NewBus creates an empty event bus
+func NewBus() *Bus {
+	return &Bus{subs: make(map[reflect.Type][]subscription)}
+}
+
+// typeOf returns the key under which handlers for events of type T are stored
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// Subscribe registers handler for events of type T and returns a function that removes it
+func Subscribe[T any](b *Bus, handler func(T)) (unsubscribe func()) {
+	key := typeOf[T]()
+
+	b.mu.Lock()
+	b.nextID++
+	id := b.nextID
+	b.subs[key] = append(b.subs[key], subscription{id: id, handler: handler})
+	b.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			subs := b.subs[key]
+			for i, sub := range subs {
+				if sub.id == id {
+					b.subs[key] = append(subs[:i:i], subs[i+1:]...)
+					break
+				}
+			}
+		})
+	}
+}
+
+// Publish synchronously delivers event to every handler subscribed to type T, in subscription
+// order. A panicking handler is logged and does not prevent delivery to the others.
+func Publish[T any](b *Bus, event T) {
+	b.mu.RLock()
+	subs := b.subs[typeOf[T]()]
+	handlers := make([]func(T), len(subs))
+	for i, sub := range subs {
+		handlers[i] = sub.handler.(func(T))
+	}
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					logging.LogErrorf("Event handler panic for %T: %v", event, r)
+				}
+			}()
+			handler(event)
+		}()
+	}
+}
+
+// Subscribers returns the number of handlers subscribed to type T
+func Subscribers[T any](b *Bus) int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subs[typeOf[T]()])
+}
@@ -0,0 +1,59 @@
+package events
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/penwyp/claudecat/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+type testEvent struct {
+	Value int
+}
+
+type otherEvent struct{}
+
+func TestBus_PublishByType(t *testing.T) {
+	bus := NewBus()
+
+	var got []int
+	Subscribe(bus, func(e testEvent) { got = append(got, e.Value) })
+	Subscribe(bus, func(e testEvent) { got = append(got, e.Value*10) })
+
+	others := 0
+	Subscribe(bus, func(otherEvent) { others++ })
+
+	Publish(bus, testEvent{Value: 2})
+	assert.Equal(t, []int{2, 20}, got)
+	assert.Zero(t, others)
+	assert.Equal(t, 2, Subscribers[testEvent](bus))
+	assert.Equal(t, 0, Subscribers[string](bus))
+}
+
+func TestBus_Unsubscribe(t *testing.T) {
+	bus := NewBus()
+
+	calls := 0
+	unsubscribe := Subscribe(bus, func(testEvent) { calls++ })
+	Subscribe(bus, func(testEvent) { calls += 100 })
+
+	unsubscribe()
+	unsubscribe() // Safe to call twice
+
+	Publish(bus, testEvent{})
+	assert.Equal(t, 100, calls)
+	assert.Equal(t, 1, Subscribers[testEvent](bus))
+}
+
+func TestBus_HandlerPanicDoesNotStopDelivery(t *testing.T) {
+	logging.InitLogger("error", filepath.Join(t.TempDir(), "test.log"), false)
+	bus := NewBus()
+
+	delivered := false
+	Subscribe(bus, func(testEvent) { panic("boom") })
+	Subscribe(bus, func(testEvent) { delivered = true })
+
+	assert.NotPanics(t, func() { Publish(bus, testEvent{}) })
+	assert.True(t, delivered)
+}
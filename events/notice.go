@@ -0,0 +1,37 @@
+package events
+
+// NoticeLevel is the severity of a user-facing notice
+type NoticeLevel int
+
+const (
+	NoticeInfo NoticeLevel = iota
+	NoticeWarning
+	NoticeError
+)
+
+// String returns the lowercase name of the level
+func (l NoticeLevel) String() string {
+	switch l {
+	case NoticeWarning:
+		return "warning"
+	case NoticeError:
+		return "error"
+	default:
+		return "info"
+	}
+}
+
+// Notice is a state change worth surfacing to the user, such as a toast in the monitor
+type Notice struct {
+	Level    NoticeLevel
+	Message  string
+	Crossing *Crossing // Set when the notice was caused by a metric crossing a threshold
+}
+
+// Crossing describes the threshold crossing behind a notice, for the persistent alert log
+type Crossing struct {
+	Metric    string
+	Value     float64
+	Threshold float64
+	SessionID string
+}
@@ -0,0 +1,316 @@
+// Package export writes usage entries to Parquet files and SQLite databases. Its encoders are large,
+// so only the export command imports it, and builds with the noexport tag leave it out.
+package export
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow-go/v18/parquet"
+	"github.com/apache/arrow-go/v18/parquet/compress"
+	"github.com/apache/arrow-go/v18/parquet/file"
+	"github.com/apache/arrow-go/v18/parquet/schema"
+	"github.com/penwyp/claudecat/models"
+	_ "modernc.org/sqlite" // Registers the pure-Go "sqlite" database/sql driver
+)
+
+// SchemaVersion is the version of the exported usage entry schema. It is bumped whenever a column
+// is added, removed or changes meaning, so that downstream queries can detect the layout they read.
+const SchemaVersion = 1
+
+// Export formats supported by WriteUsageEntries
+const (
+	FormatParquet = "parquet"
+	FormatSQLite  = "sqlite"
+)
+
+const (
+	// Table is the SQLite table usage entries are exported to
+	Table = "usage_entries"
+	// exportMetaTable holds the schema version and provenance of a SQLite export
+	exportMetaTable = "claudecat_export"
+	// exportRowGroupSize is the number of entries per Parquet row group
+	exportRowGroupSize = 100000
+	// exportTimestampFormat stores timestamps as fixed-width UTC text in SQLite, so they sort chronologically
+	exportTimestampFormat = "2006-01-02T15:04:05.000Z"
+)
+
+// exportKind is the type of an exported column
+type exportKind int
+
+const (
+	exportTimestamp exportKind = iota
+	exportString
+	exportInt
+	exportFloat
+)
+
+// exportColumn is one column of the exported schema and how it is read from an entry
+type exportColumn struct {
+	name  string
+	kind  exportKind
+	value func(*models.UsageEntry) any // time.Time, string, int or float64 according to kind
+}
+
+// exportColumns is the exported schema, version SchemaVersion, shared by every format
+var exportColumns = []exportColumn{
+	{"timestamp", exportTimestamp, func(e *models.UsageEntry) any { return e.Timestamp }},
+	{"model", exportString, func(e *models.UsageEntry) any { return e.Model }},
+	{"project", exportString, func(e *models.UsageEntry) any { return e.Project }},
+	{"session_id", exportString, func(e *models.UsageEntry) any { return e.SessionID }},
+	{"message_id", exportString, func(e *models.UsageEntry) any { return e.MessageID }},
+	{"request_id", exportString, func(e *models.UsageEntry) any { return e.RequestID }},
+	{"input_tokens", exportInt, func(e *models.UsageEntry) any { return e.InputTokens }},
+	{"output_tokens", exportInt, func(e *models.UsageEntry) any { return e.OutputTokens }},
+	{"cache_creation_tokens", exportInt, func(e *models.UsageEntry) any { return e.CacheCreationTokens }},
+	{"cache_creation_1h_tokens", exportInt, func(e *models.UsageEntry) any { return e.CacheCreation1hTokens }},
+	{"cache_read_tokens", exportInt, func(e *models.UsageEntry) any { return e.CacheReadTokens }},
+	{"total_tokens", exportInt, func(e *models.UsageEntry) any { return e.TotalTokens }},
+	{"cost_usd", exportFloat, func(e *models.UsageEntry) any { return e.CostUSD }},
+	{"logged_cost_usd", exportFloat, func(e *models.UsageEntry) any { return e.CachedCostUSD }},
+}
+
+// WriteUsageEntries writes entries to path in the given format. A Parquet file is replaced atomically;
+// in a SQLite database only the usage_entries table is replaced, so other tables are kept.
+// createdBy identifies the exporting program in the file metadata.
+func WriteUsageEntries(path, format string, entries []models.UsageEntry, createdBy string) error {
+	switch format {
+	case FormatParquet:
+		return exportParquet(path, entries, createdBy)
+	case FormatSQLite:
+		return exportSQLite(path, entries, createdBy)
+	default:
+		return fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// exportParquet writes entries to a temporary file next to path and renames it into place
+func exportParquet(path string, entries []models.UsageEntry, createdBy string) error {
+	fields := make(schema.FieldList, len(exportColumns))
+	for i, column := range exportColumns {
+		node, err := parquetNode(column)
+		if err != nil {
+			return fmt.Errorf("failed to build Parquet schema: %w", err)
+		}
+		fields[i] = node
+	}
+	root, err := schema.NewGroupNode("schema", parquet.Repetitions.Required, fields, -1)
+	if err != nil {
+		return fmt.Errorf("failed to build Parquet schema: %w", err)
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	props := parquet.NewWriterProperties(
+		parquet.WithCompression(compress.Codecs.Snappy),
+		parquet.WithCreatedBy(createdBy),
+		parquet.WithMaxRowGroupLength(exportRowGroupSize),
+	)
+	// Closing the writer also closes tmpFile
+	writer := file.NewParquetWriter(tmpFile, root, file.WithWriterProps(props))
+	if err := writeParquetRows(writer, entries); err != nil {
+		writer.Close()
+		return err
+	}
+	for key, value := range exportMetadata(len(entries), createdBy) {
+		if err := writer.AppendKeyValueMetadata("claudecat."+key, value); err != nil {
+			writer.Close()
+			return fmt.Errorf("failed to write Parquet metadata: %w", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to finish Parquet file: %w", err)
+	}
+	if err := os.Rename(tmpFile.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
+
+// parquetNode returns the required Parquet column of an exported column
+func parquetNode(column exportColumn) (schema.Node, error) {
+	switch column.kind {
+	case exportTimestamp:
+		return schema.NewPrimitiveNodeLogical(column.name, parquet.Repetitions.Required,
+			schema.NewTimestampLogicalType(true, schema.TimeUnitMicros), parquet.Types.Int64, -1, -1)
+	case exportString:
+		return schema.NewPrimitiveNodeLogical(column.name, parquet.Repetitions.Required,
+			schema.StringLogicalType{}, parquet.Types.ByteArray, -1, -1)
+	case exportInt:
+		return schema.NewInt64Node(column.name, parquet.Repetitions.Required, -1), nil
+	default:
+		return schema.NewFloat64Node(column.name, parquet.Repetitions.Required, -1), nil
+	}
+}
+
+// writeParquetRows writes entries column by column, one row group per exportRowGroupSize entries
+func writeParquetRows(writer *file.Writer, entries []models.UsageEntry) error {
+	for start := 0; start < len(entries); start += exportRowGroupSize {
+		rows := entries[start:min(start+exportRowGroupSize, len(entries))]
+		rowGroup := writer.AppendRowGroup()
+		for _, column := range exportColumns {
+			chunk, err := rowGroup.NextColumn()
+			if err != nil {
+				return fmt.Errorf("failed to write column %s: %w", column.name, err)
+			}
+			switch w := chunk.(type) {
+			case *file.Int64ColumnChunkWriter:
+				values := make([]int64, len(rows))
+				for i := range rows {
+					switch v := column.value(&rows[i]).(type) {
+					case time.Time:
+						values[i] = v.UnixMicro()
+					case int:
+						values[i] = int64(v)
+					}
+				}
+				_, err = w.WriteBatch(values, nil, nil)
+			case *file.ByteArrayColumnChunkWriter:
+				values := make([]parquet.ByteArray, len(rows))
+				for i := range rows {
+					values[i] = parquet.ByteArray(column.value(&rows[i]).(string))
+				}
+				_, err = w.WriteBatch(values, nil, nil)
+			case *file.Float64ColumnChunkWriter:
+				values := make([]float64, len(rows))
+				for i := range rows {
+					values[i] = column.value(&rows[i]).(float64)
+				}
+				_, err = w.WriteBatch(values, nil, nil)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to write column %s: %w", column.name, err)
+			}
+			if err := chunk.Close(); err != nil {
+				return fmt.Errorf("failed to write column %s: %w", column.name, err)
+			}
+		}
+		if err := rowGroup.Close(); err != nil {
+			return fmt.Errorf("failed to write row group: %w", err)
+		}
+	}
+	return nil
+}
+
+// exportSQLite replaces the usage_entries table of the database at path in a single transaction.
+// A database exported by a newer schema version is left untouched.
+func exportSQLite(path string, entries []models.UsageEntry, createdBy string) error {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return fmt.Errorf("failed to open SQLite database: %w", err)
+	}
+	defer db.Close()
+
+	if version, err := sqliteExportVersion(db); err != nil {
+		return err
+	} else if version > SchemaVersion {
+		return fmt.Errorf("%s was exported with schema version %d, newer than version %d written by this claudecat; "+
+			"upgrade claudecat or export to a new file", path, version, SchemaVersion)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	columns := make([]string, len(exportColumns))
+	placeholders := make([]string, len(exportColumns))
+	for i, column := range exportColumns {
+		columns[i] = column.name + " " + sqliteType(column.kind) + " NOT NULL"
+		placeholders[i] = "?"
+	}
+	statements := []string{
+		"DROP TABLE IF EXISTS " + Table,
+		"CREATE TABLE " + Table + " (\n\t" + strings.Join(columns, ",\n\t") + "\n)",
+		"CREATE TABLE IF NOT EXISTS " + exportMetaTable + " (key TEXT PRIMARY KEY, value TEXT NOT NULL)",
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return fmt.Errorf("failed to create %s: %w", Table, err)
+		}
+	}
+
+	insert, err := tx.Prepare("INSERT INTO " + Table + " VALUES (" + strings.Join(placeholders, ", ") + ")")
+	if err != nil {
+		return fmt.Errorf("failed to prepare insert: %w", err)
+	}
+	defer insert.Close()
+	values := make([]any, len(exportColumns))
+	for i := range entries {
+		for j, column := range exportColumns {
+			values[j] = column.value(&entries[i])
+			if t, ok := values[j].(time.Time); ok {
+				values[j] = t.UTC().Format(exportTimestampFormat)
+			}
+		}
+		if _, err := insert.Exec(values...); err != nil {
+			return fmt.Errorf("failed to insert usage entry: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec("CREATE INDEX " + Table + "_timestamp ON " + Table + " (timestamp)"); err != nil {
+		return fmt.Errorf("failed to index %s: %w", Table, err)
+	}
+	for key, value := range exportMetadata(len(entries), createdBy) {
+		if _, err := tx.Exec("INSERT OR REPLACE INTO "+exportMetaTable+" (key, value) VALUES (?, ?)", key, value); err != nil {
+			return fmt.Errorf("failed to record export metadata: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit export: %w", err)
+	}
+	return nil
+}
+
+// sqliteExportVersion returns the schema version of an earlier export into db, or 0 if there is none
+func sqliteExportVersion(db *sql.DB) (int, error) {
+	var count int
+	err := db.QueryRow("SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = ?", exportMetaTable).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read SQLite database: %w", err)
+	}
+	if count == 0 {
+		return 0, nil
+	}
+	var value string
+	err = db.QueryRow("SELECT value FROM " + exportMetaTable + " WHERE key = 'schema_version'").Scan(&value)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read export metadata: %w", err)
+	}
+	return strconv.Atoi(value)
+}
+
+// sqliteType returns the SQLite column type of an exported column
+func sqliteType(kind exportKind) string {
+	switch kind {
+	case exportInt:
+		return "INTEGER"
+	case exportFloat:
+		return "REAL"
+	default:
+		return "TEXT"
+	}
+}
+
+// exportMetadata is the provenance recorded with an export
+func exportMetadata(entries int, createdBy string) map[string]string {
+	return map[string]string{
+		"schema_version": strconv.Itoa(SchemaVersion),
+		"exported_at":    time.Now().UTC().Format(time.RFC3339),
+		"created_by":     createdBy,
+		"entries":        strconv.Itoa(entries),
+	}
+}
@@ -0,0 +1,98 @@
+package export
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/parquet"
+	"github.com/apache/arrow-go/v18/parquet/file"
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func exportTestEntries() []models.UsageEntry {
+	at := time.Date(2025, 6, 2, 9, 30, 0, 123000000, time.UTC)
+	return []models.UsageEntry{
+		{Timestamp: at, Model: "claude-sonnet-4-20250514", InputTokens: 100, OutputTokens: 50, CacheReadTokens: 1000,
+			TotalTokens: 1150, CostUSD: 0.0125, MessageID: "msg_1", RequestID: "req_1", SessionID: "s1", Project: "webapp"},
+		{Timestamp: at.Add(time.Hour), Model: "claude-opus-4-20250514", InputTokens: 10, OutputTokens: 20,
+			CacheCreationTokens: 300, CacheCreation1hTokens: 100, TotalTokens: 330, CostUSD: 0.5, CachedCostUSD: 0.49,
+			MessageID: "msg_2", RequestID: "req_2", SessionID: "s1", Project: "webapp"},
+	}
+}
+
+func TestExportUsageEntries_Parquet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.parquet")
+	require.NoError(t, WriteUsageEntries(path, FormatParquet, exportTestEntries(), "claudecat test"))
+
+	reader, err := file.OpenParquetFile(path, false)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	assert.EqualValues(t, 2, reader.NumRows())
+	assert.Len(t, exportColumns, reader.MetaData().Schema.NumColumns())
+	version := reader.MetaData().KeyValueMetadata().FindValue("claudecat.schema_version")
+	require.NotNil(t, version)
+	assert.Equal(t, "1", *version)
+
+	column := func(name string) file.ColumnChunkReader {
+		index := reader.MetaData().Schema.ColumnIndexByName(name)
+		require.GreaterOrEqual(t, index, 0, name)
+		chunk, err := reader.RowGroup(0).Column(index)
+		require.NoError(t, err)
+		return chunk
+	}
+
+	timestamps := make([]int64, 2)
+	_, _, err = column("timestamp").(*file.Int64ColumnChunkReader).ReadBatch(2, timestamps, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, exportTestEntries()[0].Timestamp.UnixMicro(), timestamps[0])
+
+	names := make([]parquet.ByteArray, 2)
+	_, _, err = column("model").(*file.ByteArrayColumnChunkReader).ReadBatch(2, names, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "claude-opus-4-20250514", string(names[1]))
+
+	costs := make([]float64, 2)
+	_, _, err = column("logged_cost_usd").(*file.Float64ColumnChunkReader).ReadBatch(2, costs, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{0, 0.49}, costs)
+}
+
+func TestExportUsageEntries_SQLite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.db")
+	require.NoError(t, WriteUsageEntries(path, FormatSQLite, exportTestEntries(), "claudecat test"))
+
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Other tables survive a re-export, which replaces the entries
+	_, err = db.Exec("CREATE TABLE notes (text TEXT)")
+	require.NoError(t, err)
+	require.NoError(t, WriteUsageEntries(path, FormatSQLite, exportTestEntries()[:1], "claudecat test"))
+
+	var count, tokens int
+	var timestamp string
+	require.NoError(t, db.QueryRow("SELECT count(*), sum(total_tokens), min(timestamp) FROM usage_entries").Scan(&count, &tokens, &timestamp))
+	assert.Equal(t, 1, count)
+	assert.Equal(t, 1150, tokens)
+	assert.Equal(t, "2025-06-02T09:30:00.123Z", timestamp)
+	require.NoError(t, db.QueryRow("SELECT count(*) FROM notes").Scan(&count))
+
+	version, err := sqliteExportVersion(db)
+	require.NoError(t, err)
+	assert.Equal(t, SchemaVersion, version)
+
+	// A database written by a newer schema version is not overwritten
+	_, err = db.Exec("UPDATE claudecat_export SET value = '99' WHERE key = 'schema_version'")
+	require.NoError(t, err)
+	err = WriteUsageEntries(path, FormatSQLite, exportTestEntries(), "claudecat test")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "schema version 99")
+	require.NoError(t, db.QueryRow("SELECT count(*) FROM usage_entries").Scan(&count))
+	assert.Equal(t, 1, count)
+}
@@ -0,0 +1,137 @@
+package fileio
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+)
+
+// ActivityWindow is how recently a file must have been written to count as active
+const ActivityWindow = time.Minute
+
+// FileActivity describes recent writes to a single usage file
+type FileActivity struct {
+	Path          string    `json:"path"`
+	Project       string    `json:"project"`
+	BytesAppended int64     `json:"bytes_appended"`
+	EntriesParsed int       `json:"entries_parsed"`
+	LastWrite     time.Time `json:"last_write"`
+}
+
+// ActivityTracker remembers file sizes between refreshes to report which files are being written to
+type ActivityTracker struct {
+	mu     sync.Mutex
+	window time.Duration
+	files  map[string]*fileObservation
+}
+
+// fileObservation is the last known state of a file and its recent growth
+type fileObservation struct {
+	size    int64
+	modTime time.Time
+	growth  []fileGrowth
+}
+
+// fileGrowth records bytes appended to a file between two observations
+type fileGrowth struct {
+	at    time.Time
+	bytes int64
+}
+
+// NewActivityTracker creates a tracker reporting files written within window; 0 uses ActivityWindow
+func NewActivityTracker(window time.Duration) *ActivityTracker {
+	if window <= 0 {
+		window = ActivityWindow
+	}
+	return &ActivityTracker{
+		window: window,
+		files:  make(map[string]*fileObservation),
+	}
+}
+
+// Observe stats the given files and returns those written within the window, most recent first.
+// Bytes appended are only known once a file has been seen before; entries are matched to their
+// file by session ID, which Claude uses as the conversation file name.
+func (t *ActivityTracker) Observe(paths []string, entries []models.UsageEntry, now time.Time) []FileActivity {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	cutoff := now.Add(-t.window)
+	seen := make(map[string]bool, len(paths))
+	var active []FileActivity
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		seen[path] = true
+
+		obs, known := t.files[path]
+		if !known {
+			obs = &fileObservation{}
+			t.files[path] = obs
+		} else if delta := info.Size() - obs.size; delta > 0 {
+			obs.growth = append(obs.growth, fileGrowth{at: now, bytes: delta})
+		} else if delta < 0 {
+			// File was truncated or replaced, start over from its current size
+			obs.growth = nil
+		}
+		obs.size = info.Size()
+		obs.modTime = info.ModTime()
+
+		var appended int64
+		recent := obs.growth[:0]
+		for _, g := range obs.growth {
+			if g.at.After(cutoff) {
+				appended += g.bytes
+				recent = append(recent, g)
+			}
+		}
+		obs.growth = recent
+
+		if obs.modTime.Before(cutoff) {
+			continue
+		}
+		active = append(active, FileActivity{
+			Path:          path,
+			Project:       extractProjectFromPath(path),
+			BytesAppended: appended,
+			LastWrite:     obs.modTime,
+		})
+	}
+
+	// Forget files that disappeared so the map does not grow without bound
+	for path := range t.files {
+		if !seen[path] {
+			delete(t.files, path)
+		}
+	}
+
+	if len(active) == 0 {
+		return nil
+	}
+
+	bySession := make(map[string]int, len(active))
+	for i := range active {
+		bySession[strings.TrimSuffix(filepath.Base(active[i].Path), ".jsonl")] = i
+	}
+	for _, entry := range entries {
+		if entry.Timestamp.Before(cutoff) {
+			continue
+		}
+		if i, ok := bySession[entry.SessionID]; ok {
+			active[i].EntriesParsed++
+		}
+	}
+
+	sort.Slice(active, func(i, j int) bool {
+		return active[i].LastWrite.After(active[j].LastWrite)
+	})
+	return active
+}
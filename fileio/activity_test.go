@@ -0,0 +1,59 @@
+package fileio
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActivityTracker_Observe(t *testing.T) {
+	projectDir := filepath.Join(t.TempDir(), "-Users-dev-webapp")
+	require.NoError(t, os.MkdirAll(projectDir, 0755))
+
+	active := filepath.Join(projectDir, "session-a.jsonl")
+	idle := filepath.Join(projectDir, "session-b.jsonl")
+	require.NoError(t, os.WriteFile(active, []byte("first line\n"), 0644))
+	require.NoError(t, os.WriteFile(idle, []byte("old line\n"), 0644))
+
+	now := time.Now()
+	old := now.Add(-10 * time.Minute)
+	require.NoError(t, os.Chtimes(idle, old, old))
+
+	tracker := NewActivityTracker(0)
+	files := []string{active, idle}
+
+	// The first observation only establishes a baseline size
+	got := tracker.Observe(files, nil, now)
+	require.Len(t, got, 1)
+	assert.Equal(t, active, got[0].Path)
+	assert.Equal(t, "webapp", got[0].Project)
+	assert.Zero(t, got[0].BytesAppended)
+
+	f, err := os.OpenFile(active, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString("second line\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	entries := []models.UsageEntry{
+		{SessionID: "session-a", Timestamp: now},
+		{SessionID: "session-a", Timestamp: now.Add(-time.Hour)},
+		{SessionID: "session-b", Timestamp: now},
+	}
+	got = tracker.Observe(files, entries, now.Add(10*time.Second))
+	require.Len(t, got, 1)
+	assert.Equal(t, int64(len("second line\n")), got[0].BytesAppended)
+	assert.Equal(t, 1, got[0].EntriesParsed)
+
+	// Growth falls out of the window once it is older than a minute
+	later := now.Add(2 * time.Minute)
+	require.NoError(t, os.Chtimes(active, later, later))
+	got = tracker.Observe(files, nil, later)
+	require.Len(t, got, 1)
+	assert.Zero(t, got[0].BytesAppended)
+}
@@ -0,0 +1,198 @@
+package fileio
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/penwyp/claudecat/cache"
+	"github.com/penwyp/claudecat/logging"
+	"github.com/penwyp/claudecat/models"
+)
+
+// CompactOptions configures the compaction of old usage files into monthly archives
+type CompactOptions struct {
+	DataPath        string                 // Path to Claude data directory
+	Before          time.Time              // Files last modified before this are archived, see cache.ArchiveCutoff
+	Archives        *cache.ArchiveStore    // Store receiving the monthly archives
+	CacheStore      CacheStore             // Optional; valid summaries are reused and invalidated once archived
+	Mode            models.CostMode        // Cost calculation mode for files without a valid summary
+	PricingProvider models.PricingProvider // Optional pricing provider for cost calculations
+	MaxLineSize     int                    // Max bytes buffered per line (0 = DefaultMaxLineSize)
+
+	pricing *pricingResolver // Memoized PricingProvider lookups, shared by every file compacted
+}
+
+// CompactResult describes the files rolled into archives by CompactArchives
+type CompactResult struct {
+	Files   int      `json:"files"`
+	Entries int      `json:"entries"`
+	Months  []string `json:"months"` // Archives created or extended, e.g. "2025-03"
+}
+
+// CompactArchives rolls the usage files of a data path last modified before opts.Before into monthly
+// archives. Archived files still on disk are skipped by LoadUsageEntries, which loads the archives instead.
+func CompactArchives(opts CompactOptions) (CompactResult, error) {
+	var result CompactResult
+	opts.pricing = newPricingResolver(opts.PricingProvider)
+	dataPath, err := filepath.Abs(opts.DataPath)
+	if err != nil {
+		dataPath = opts.DataPath
+	}
+
+	existing, err := opts.Archives.Load(dataPath)
+	if err != nil {
+		return result, fmt.Errorf("failed to load archives: %w", err)
+	}
+	months := make(map[string]*cache.MonthArchive, len(existing))
+	archived := make(map[string]bool)
+	for _, archive := range existing {
+		months[archive.Month] = archive
+		for _, file := range archive.Files {
+			archived[file.PathHash] = true
+		}
+	}
+
+	files, err := findJSONLFiles(dataPath)
+	if err != nil {
+		return result, fmt.Errorf("failed to find JSONL files: %w", err)
+	}
+
+	changed := make(map[string]bool)
+	var compacted []string
+	for _, filePath := range files {
+		absPath, err := filepath.Abs(filePath)
+		if err != nil {
+			absPath = filePath
+		}
+		info, err := os.Stat(filePath)
+		if err != nil || !info.ModTime().Before(opts.Before) || archived[cache.PathHash(absPath)] {
+			continue
+		}
+
+		summary, err := archivableSummary(absPath, filePath, info, opts)
+		if err != nil {
+			logging.LogWarnf("Skipping %s during compaction: %v", filepath.Base(filePath), err)
+			continue
+		}
+		file := cache.ArchivedFile{PathHash: cache.PathHash(absPath), ModTime: info.ModTime(), FileSize: info.Size()}
+		fileMonths := make(map[string]bool)
+		for key := range summary.HourlyBuckets {
+			if hour, err := time.Parse("2006-01-02 15", key); err == nil {
+				fileMonths[hour.Format("2006-01")] = true
+				if end := hour.Add(time.Hour - time.Nanosecond); end.After(file.LastEntry) {
+					file.LastEntry = end
+				}
+			}
+		}
+		// Files without usage are recorded in the month they were last modified
+		if len(fileMonths) == 0 {
+			fileMonths[info.ModTime().UTC().Format("2006-01")] = true
+		}
+
+		for month := range fileMonths {
+			archive, ok := months[month]
+			if !ok {
+				start, _ := time.Parse("2006-01", month)
+				archive = cache.NewMonthArchive(dataPath, start)
+				months[month] = archive
+			}
+			archive.Add(file, summary)
+			changed[month] = true
+		}
+		result.Files++
+		result.Entries += summary.EntryCount
+		compacted = append(compacted, absPath)
+	}
+
+	for month := range changed {
+		if err := opts.Archives.Save(months[month]); err != nil {
+			return CompactResult{}, fmt.Errorf("failed to save archive %s: %w", month, err)
+		}
+		result.Months = append(result.Months, month)
+	}
+	sort.Strings(result.Months)
+
+	// The summaries are superseded once the archives are written; they stay restorable from the trash
+	if opts.CacheStore != nil {
+		for _, absPath := range compacted {
+			if opts.CacheStore.HasFileSummary(absPath) {
+				if err := opts.CacheStore.InvalidateFileSummary(absPath); err != nil {
+					logging.LogWarnf("Failed to remove archived summary of %s: %v", filepath.Base(absPath), err)
+				}
+			}
+		}
+	}
+
+	if result.Files > 0 {
+		logging.LogInfof("Archived %d files of %s into %d monthly archives", result.Files, dataPath, len(result.Months))
+	}
+	return result, nil
+}
+
+// archivableSummary returns the cached summary of a file if it is still valid, otherwise parses the file
+func archivableSummary(absPath, filePath string, info os.FileInfo, opts CompactOptions) (*cache.FileSummary, error) {
+	if opts.CacheStore != nil {
+		if summary, err := opts.CacheStore.GetFileSummary(absPath); err == nil && !summary.IsExpired(info.ModTime(), info.Size()) {
+			if summary.HasNoAssistantMessages || len(summary.HourlyBuckets) > 0 {
+				return summary, nil
+			}
+		}
+	}
+
+	loadOpts := &LoadUsageEntriesOptions{Mode: opts.Mode, PricingProvider: opts.PricingProvider, MaxLineSize: opts.MaxLineSize, pricing: opts.pricing}
+	entries, _, err := processSingleFileWithDedup(filePath, opts.Mode, nil, false, nil, loadOpts)
+	if err != nil {
+		return nil, err
+	}
+	return createSummaryFromEntries(absPath, filePath, entries, info), nil
+}
+
+// applyArchives removes the files covered by the archives of the data path from files and returns the
+// archived entries after cutoff, along with the archived files modified since, keyed by the time of
+// their last archived entry
+func applyArchives(archives *cache.ArchiveStore, dataPath string, files []string, cutoff *time.Time) ([]string, []models.UsageEntry, map[string]time.Time, error) {
+	if abs, err := filepath.Abs(dataPath); err == nil {
+		dataPath = abs
+	}
+	months, err := archives.Load(dataPath)
+	if err != nil || len(months) == 0 {
+		return files, nil, nil, err
+	}
+
+	covered := make(map[string]cache.ArchivedFile)
+	var entries []models.UsageEntry
+	for _, archive := range months {
+		for _, file := range archive.Files {
+			if previous, ok := covered[file.PathHash]; !ok || file.LastEntry.After(previous.LastEntry) {
+				covered[file.PathHash] = file
+			}
+		}
+		for _, project := range archive.Projects {
+			entries = append(entries, createEntriesFromSummary(project, cutoff)...)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+
+	kept := files[:0:0]
+	modified := make(map[string]time.Time)
+	for _, filePath := range files {
+		absPath, err := filepath.Abs(filePath)
+		if err != nil {
+			absPath = filePath
+		}
+		file, ok := covered[cache.PathHash(absPath)]
+		if !ok {
+			kept = append(kept, filePath)
+			continue
+		}
+		if info, err := os.Stat(filePath); err == nil && (!info.ModTime().Equal(file.ModTime) || info.Size() != file.FileSize) {
+			modified[filePath] = file.LastEntry
+		}
+	}
+	return kept, entries, modified, nil
+}
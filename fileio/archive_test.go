@@ -0,0 +1,81 @@
+package fileio
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/cache"
+	"github.com/penwyp/claudecat/logging"
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompactArchives(t *testing.T) {
+	logging.InitLogger("error", filepath.Join(t.TempDir(), "test.log"), false)
+	assistant := func(ts, id string, input int) string {
+		return fmt.Sprintf(`{"type":"assistant","timestamp":"%s","requestId":"req-%s","message":{"id":"msg-%s","model":"claude-3-5-sonnet-20241022","usage":{"input_tokens":%d,"output_tokens":5}}}`, ts, id, id, input)
+	}
+	dataDir := t.TempDir()
+	projectDir := filepath.Join(dataDir, "-Users-dev-webapp")
+	require.NoError(t, os.MkdirAll(projectDir, 0755))
+
+	oldFile := filepath.Join(projectDir, "old.jsonl")
+	require.NoError(t, os.WriteFile(oldFile, []byte(strings.Join([]string{
+		assistant("2024-01-31T23:30:00Z", "1", 10),
+		assistant("2024-02-01T00:10:00Z", "2", 100),
+	}, "\n")+"\n"), 0644))
+	oldTime := time.Date(2024, 2, 1, 1, 0, 0, 0, time.UTC)
+	require.NoError(t, os.Chtimes(oldFile, oldTime, oldTime))
+	recentFile := filepath.Join(projectDir, "recent.jsonl")
+	require.NoError(t, os.WriteFile(recentFile, []byte(assistant("2024-03-15T10:30:00Z", "3", 10)+"\n"), 0644))
+
+	store, err := cache.NewFileBasedSummaryCache(t.TempDir())
+	require.NoError(t, err)
+	archives := store.Archives()
+	opts := LoadUsageEntriesOptions{DataPath: dataDir, Mode: models.CostModeCalculated, CacheStore: store, Archives: archives}
+	totals := func() (int, int) {
+		result, err := LoadUsageEntries(opts)
+		require.NoError(t, err)
+		tokens := 0
+		for _, entry := range result.Entries {
+			tokens += entry.InputTokens
+		}
+		return len(result.Entries), tokens
+	}
+	entriesBefore, tokensBefore := totals()
+	require.Equal(t, 3, entriesBefore)
+	require.True(t, store.HasFileSummary(oldFile))
+
+	result, err := CompactArchives(CompactOptions{DataPath: dataDir, Before: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Archives: archives, CacheStore: store, Mode: models.CostModeCalculated})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Files)
+	assert.Equal(t, 2, result.Entries)
+	assert.Equal(t, []string{"2024-01", "2024-02"}, result.Months)
+	assert.False(t, store.HasFileSummary(oldFile), "archived summaries are invalidated")
+	assert.True(t, store.HasFileSummary(recentFile))
+
+	// Compacting again finds nothing new
+	result, err = CompactArchives(CompactOptions{DataPath: dataDir, Before: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Archives: archives, CacheStore: store, Mode: models.CostModeCalculated})
+	require.NoError(t, err)
+	assert.Zero(t, result.Files)
+
+	entriesAfter, tokensAfter := totals()
+	assert.Equal(t, entriesBefore, entriesAfter)
+	assert.Equal(t, tokensBefore, tokensAfter)
+
+	// Lines appended to an archived file are loaded without counting the archived ones twice
+	f, err := os.OpenFile(oldFile, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString(assistant("2024-03-20T09:00:00Z", "4", 1000) + "\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	entriesAppended, tokensAppended := totals()
+	assert.Equal(t, entriesBefore+1, entriesAppended)
+	assert.Equal(t, tokensBefore+1000, tokensAppended)
+}
@@ -0,0 +1,169 @@
+package fileio
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+)
+
+// BenchmarkOptions configures a load benchmark of a data directory
+type BenchmarkOptions struct {
+	DataPath        string
+	Mode            models.CostMode
+	PricingProvider models.PricingProvider
+	Workers         []int      // Worker counts to compare (empty = runtime.NumCPU())
+	MaxLineSizes    []int      // Line buffer sizes to compare (empty = DefaultMaxLineSize)
+	CacheStore      CacheStore // Empty cache for the cold and warm runs; nil skips them
+}
+
+// BenchmarkRun is the parse of every file with a worker count and line buffer size, without a cache
+type BenchmarkRun struct {
+	Workers       int           `json:"workers"`
+	MaxLineSize   int           `json:"max_line_size"`
+	Duration      time.Duration `json:"duration"`
+	Entries       int           `json:"entries"`
+	BytesPerSec   float64       `json:"bytes_per_sec"`
+	EntriesPerSec float64       `json:"entries_per_sec"`
+}
+
+// BenchmarkReport is the result of a load benchmark
+type BenchmarkReport struct {
+	DataPath      string         `json:"data_path"`
+	Files         int            `json:"files"`
+	Bytes         int64          `json:"bytes"`
+	Discovery     time.Duration  `json:"discovery"`
+	Parse         []BenchmarkRun `json:"parse"`
+	MergeDuration time.Duration  `json:"merge_duration"` // Merging the files' entries of the fastest run
+	DedupDuration time.Duration  `json:"dedup_duration"` // The same merge, skipping duplicate entries
+	Duplicates    int            `json:"duplicates"`
+	CacheCold     time.Duration  `json:"cache_cold,omitempty"` // Load into an empty cache, writing the summaries
+	CacheWarm     time.Duration  `json:"cache_warm,omitempty"` // Load again, from the summaries
+	CacheHitRate  float64        `json:"cache_hit_rate,omitempty"`
+}
+
+// Fastest returns the parse run with the highest throughput
+func (r BenchmarkReport) Fastest() (BenchmarkRun, bool) {
+	if len(r.Parse) == 0 {
+		return BenchmarkRun{}, false
+	}
+	fastest := r.Parse[0]
+	for _, run := range r.Parse[1:] {
+		if run.Duration < fastest.Duration {
+			fastest = run
+		}
+	}
+	return fastest, true
+}
+
+// DedupOverhead returns the time deduplication adds to the fastest load, as a fraction of it
+func (r BenchmarkReport) DedupOverhead() float64 {
+	fastest, ok := r.Fastest()
+	if !ok || fastest.Duration <= 0 {
+		return 0
+	}
+	return float64(max(r.DedupDuration-r.MergeDuration, 0)) / float64(fastest.Duration+r.MergeDuration)
+}
+
+// CacheSpeedup returns how many times faster the warm load was than the cold one, 0 when not measured
+func (r BenchmarkReport) CacheSpeedup() float64 {
+	if r.CacheCold <= 0 || r.CacheWarm <= 0 {
+		return 0
+	}
+	return float64(r.CacheCold) / float64(r.CacheWarm)
+}
+
+// Benchmark measures the discovery of the JSONL files of a data directory, their parse with each
+// worker count and line buffer size, the overhead of deduplication and, given a cache, the speedup
+// of a load from cached summaries
+func Benchmark(ctx context.Context, opts BenchmarkOptions) (BenchmarkReport, error) {
+	report := BenchmarkReport{DataPath: opts.DataPath}
+	start := time.Now()
+	files, err := findJSONLFiles(opts.DataPath)
+	if err != nil {
+		return report, fmt.Errorf("failed to find JSONL files: %w", err)
+	}
+	report.Discovery = time.Since(start)
+	report.Files = len(files)
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			report.Bytes += info.Size()
+		}
+	}
+
+	workers := opts.Workers
+	if len(workers) == 0 {
+		workers = []int{runtime.NumCPU()}
+	}
+	lineSizes := opts.MaxLineSizes
+	if len(lineSizes) == 0 {
+		lineSizes = []int{DefaultMaxLineSize}
+	}
+	loadOpts := LoadUsageEntriesOptions{
+		DataPath:        opts.DataPath,
+		Mode:            opts.Mode,
+		PricingProvider: opts.PricingProvider,
+		pricing:         newPricingResolver(opts.PricingProvider),
+	}
+
+	var fastest []FileResult
+	var fastestDuration time.Duration
+	for _, lineSize := range lineSizes {
+		for _, workerCount := range workers {
+			if err := ctx.Err(); err != nil {
+				return report, err
+			}
+			loadOpts.MaxLineSize = lineSize
+			start := time.Now()
+			results, err := NewConcurrentLoader(workerCount).LoadFiles(ctx, files, loadOpts, nil)
+			if err != nil {
+				return report, fmt.Errorf("failed to parse files: %w", err)
+			}
+			run := BenchmarkRun{Workers: workerCount, MaxLineSize: lineSize, Duration: time.Since(start)}
+			for _, result := range results {
+				run.Entries += len(result.Entries)
+			}
+			if seconds := run.Duration.Seconds(); seconds > 0 {
+				run.BytesPerSec = float64(report.Bytes) / seconds
+				run.EntriesPerSec = float64(run.Entries) / seconds
+			}
+			report.Parse = append(report.Parse, run)
+			if fastest == nil || run.Duration < fastestDuration {
+				fastest, fastestDuration = results, run.Duration
+			}
+		}
+	}
+
+	start = time.Now()
+	merged, _, _ := MergeResults(fastest)
+	report.MergeDuration = time.Since(start)
+	start = time.Now()
+	deduped, _, _ := MergeResultsWithDedup(fastest, make(map[string]bool))
+	report.DedupDuration = time.Since(start)
+	report.Duplicates = len(merged) - len(deduped)
+
+	if opts.CacheStore != nil {
+		cacheOpts := LoadUsageEntriesOptions{
+			DataPath:            opts.DataPath,
+			Mode:                opts.Mode,
+			PricingProvider:     opts.PricingProvider,
+			CacheStore:          opts.CacheStore,
+			EnableDeduplication: true,
+		}
+		cold, err := LoadUsageEntries(cacheOpts)
+		if err != nil {
+			return report, fmt.Errorf("cold load failed: %w", err)
+		}
+		warm, err := LoadUsageEntries(cacheOpts)
+		if err != nil {
+			return report, fmt.Errorf("warm load failed: %w", err)
+		}
+		report.CacheCold = cold.Metadata.LoadDuration
+		report.CacheWarm = warm.Metadata.LoadDuration
+		report.CacheHitRate = warm.Metadata.CacheStats.HitRate
+	}
+	return report, nil
+}
@@ -0,0 +1,55 @@
+package fileio
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/penwyp/claudecat/cache"
+	"github.com/penwyp/claudecat/logging"
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBenchmark(t *testing.T) {
+	logging.InitLogger("error", filepath.Join(t.TempDir(), "test.log"), false)
+	dataDir := t.TempDir()
+	projectDir := filepath.Join(dataDir, "-Users-dev-webapp")
+	require.NoError(t, os.MkdirAll(projectDir, 0755))
+	line := `{"type":"assistant","timestamp":"2024-03-15T10:30:00Z","request_id":"req-1","message":{"id":"msg-1","model":"claude-3-5-sonnet-20241022","usage":{"input_tokens":10,"output_tokens":5}}}` + "\n"
+	// The same message logged in a resumed session
+	for _, name := range []string{"a.jsonl", "b.jsonl"} {
+		require.NoError(t, os.WriteFile(filepath.Join(projectDir, name), []byte(line), 0644))
+	}
+
+	store, err := cache.NewFileBasedSummaryCache(t.TempDir())
+	require.NoError(t, err)
+	report, err := Benchmark(context.Background(), BenchmarkOptions{
+		DataPath:   dataDir,
+		Mode:       models.CostModeCalculated,
+		Workers:    []int{1, 2},
+		CacheStore: store,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, report.Files)
+	assert.Equal(t, int64(2*len(line)), report.Bytes)
+	require.Len(t, report.Parse, 2)
+	for i, workers := range []int{1, 2} {
+		assert.Equal(t, workers, report.Parse[i].Workers)
+		assert.Equal(t, DefaultMaxLineSize, report.Parse[i].MaxLineSize)
+		assert.Equal(t, 2, report.Parse[i].Entries)
+	}
+	_, ok := report.Fastest()
+	assert.True(t, ok)
+	assert.Equal(t, 1, report.Duplicates)
+	assert.Positive(t, report.CacheHitRate, "the warm load reads the summaries of the cold one")
+	assert.Positive(t, report.CacheSpeedup())
+
+	_, ok = BenchmarkReport{}.Fastest()
+	assert.False(t, ok)
+	assert.Zero(t, BenchmarkReport{}.CacheSpeedup())
+	assert.Zero(t, BenchmarkReport{}.DedupOverhead())
+}
@@ -1,8 +1,6 @@
 package fileio
 
 import (
-	"crypto/md5"
-	"fmt"
 	"os"
 	"time"
 
@@ -34,18 +32,28 @@ func createEntriesFromSummary(summary *cache.FileSummary, cutoffTime *time.Time)
 			// Create entries for each model in this hour
 			for _, modelStat := range hourBucket.ModelStats {
 				if modelStat.EntryCount > 0 {
+					// The largest message keeps its input in the first entry; the rest is shared by the others
+					largestInput, largestCacheCreation := 0, 0
+					shared := modelStat.EntryCount
+					if modelStat.LargestInputTokens+modelStat.LargestCacheCreationTokens > 0 && modelStat.EntryCount > 1 {
+						largestInput, largestCacheCreation = modelStat.LargestInputTokens, modelStat.LargestCacheCreationTokens
+						shared--
+					}
+
 					// Create individual synthetic entries to preserve granularity
 					// Calculate average values per entry
-					avgInputTokens := modelStat.InputTokens / modelStat.EntryCount
+					avgInputTokens := (modelStat.InputTokens - largestInput) / shared
 					avgOutputTokens := modelStat.OutputTokens / modelStat.EntryCount
-					avgCacheCreationTokens := modelStat.CacheCreationTokens / modelStat.EntryCount
+					avgCacheCreationTokens := (modelStat.CacheCreationTokens - largestCacheCreation) / shared
+					avgCacheCreation1hTokens := modelStat.CacheCreation1hTokens / modelStat.EntryCount
 					avgCacheReadTokens := modelStat.CacheReadTokens / modelStat.EntryCount
 					avgCostUSD := modelStat.TotalCost / float64(modelStat.EntryCount)
 
 					// Handle remainders to ensure totals match exactly
-					remainderInputTokens := modelStat.InputTokens % modelStat.EntryCount
+					remainderInputTokens := (modelStat.InputTokens - largestInput) % shared
 					remainderOutputTokens := modelStat.OutputTokens % modelStat.EntryCount
-					remainderCacheCreationTokens := modelStat.CacheCreationTokens % modelStat.EntryCount
+					remainderCacheCreationTokens := (modelStat.CacheCreationTokens - largestCacheCreation) % shared
+					remainderCacheCreation1hTokens := modelStat.CacheCreation1hTokens % modelStat.EntryCount
 					remainderCacheReadTokens := modelStat.CacheReadTokens % modelStat.EntryCount
 
 					for i := 0; i < modelStat.EntryCount; i++ {
@@ -53,30 +61,44 @@ func createEntriesFromSummary(summary *cache.FileSummary, cutoffTime *time.Time)
 						inputTokens := avgInputTokens
 						outputTokens := avgOutputTokens
 						cacheCreationTokens := avgCacheCreationTokens
+						cacheCreation1hTokens := avgCacheCreation1hTokens
 						cacheReadTokens := avgCacheReadTokens
 
-						if i < remainderInputTokens {
+						// Index among the entries sharing input, -1 for the largest message
+						sharedIndex := i - (modelStat.EntryCount - shared)
+						if sharedIndex < 0 {
+							inputTokens, cacheCreationTokens = largestInput, largestCacheCreation
+						}
+						if sharedIndex >= 0 && sharedIndex < remainderInputTokens {
 							inputTokens++
 						}
 						if i < remainderOutputTokens {
 							outputTokens++
 						}
-						if i < remainderCacheCreationTokens {
+						if sharedIndex >= 0 && sharedIndex < remainderCacheCreationTokens {
 							cacheCreationTokens++
 						}
+						if i < remainderCacheCreation1hTokens {
+							cacheCreation1hTokens++
+						}
 						if i < remainderCacheReadTokens {
 							cacheReadTokens++
 						}
 
+						// Keep synthetic timestamps inside the recorded session so it is not split
+						timestamp := placeInSession(summary.SessionHints, hourTime, hourTime.Add(time.Hour),
+							i, modelStat.EntryCount, hourTime.Add(time.Duration(i)*time.Minute))
+
 						entry := models.UsageEntry{
-							Timestamp:           hourTime.Add(time.Duration(i) * time.Minute),
-							Model:               modelStat.Model,
-							InputTokens:         inputTokens,
-							OutputTokens:        outputTokens,
-							CacheCreationTokens: cacheCreationTokens,
-							CacheReadTokens:     cacheReadTokens,
-							TotalTokens:         inputTokens + outputTokens + cacheCreationTokens + cacheReadTokens,
-							CostUSD:             avgCostUSD,
+							Timestamp:             timestamp,
+							Model:                 modelStat.Model,
+							InputTokens:           inputTokens,
+							OutputTokens:          outputTokens,
+							CacheCreationTokens:   cacheCreationTokens,
+							CacheCreation1hTokens: cacheCreation1hTokens,
+							CacheReadTokens:       cacheReadTokens,
+							TotalTokens:           inputTokens + outputTokens + cacheCreationTokens + cacheReadTokens,
+							CostUSD:               avgCostUSD,
 						}
 
 						entry.NormalizeModel()
@@ -108,18 +130,21 @@ func createEntriesFromSummary(summary *cache.FileSummary, cutoffTime *time.Time)
 					avgInputTokens := modelStat.InputTokens / modelStat.EntryCount
 					avgOutputTokens := modelStat.OutputTokens / modelStat.EntryCount
 					avgCacheCreationTokens := modelStat.CacheCreationTokens / modelStat.EntryCount
+					avgCacheCreation1hTokens := modelStat.CacheCreation1hTokens / modelStat.EntryCount
 					avgCacheReadTokens := modelStat.CacheReadTokens / modelStat.EntryCount
 					avgCostUSD := modelStat.TotalCost / float64(modelStat.EntryCount)
 
 					remainderInputTokens := modelStat.InputTokens % modelStat.EntryCount
 					remainderOutputTokens := modelStat.OutputTokens % modelStat.EntryCount
 					remainderCacheCreationTokens := modelStat.CacheCreationTokens % modelStat.EntryCount
+					remainderCacheCreation1hTokens := modelStat.CacheCreation1hTokens % modelStat.EntryCount
 					remainderCacheReadTokens := modelStat.CacheReadTokens % modelStat.EntryCount
 
 					for i := 0; i < modelStat.EntryCount; i++ {
 						inputTokens := avgInputTokens
 						outputTokens := avgOutputTokens
 						cacheCreationTokens := avgCacheCreationTokens
+						cacheCreation1hTokens := avgCacheCreation1hTokens
 						cacheReadTokens := avgCacheReadTokens
 
 						if i < remainderInputTokens {
@@ -131,19 +156,26 @@ func createEntriesFromSummary(summary *cache.FileSummary, cutoffTime *time.Time)
 						if i < remainderCacheCreationTokens {
 							cacheCreationTokens++
 						}
+						if i < remainderCacheCreation1hTokens {
+							cacheCreation1hTokens++
+						}
 						if i < remainderCacheReadTokens {
 							cacheReadTokens++
 						}
 
+						timestamp := placeInSession(summary.SessionHints, dayTime, dayTime.AddDate(0, 0, 1),
+							i, modelStat.EntryCount, dayTime.Add(time.Duration(i)*time.Hour))
+
 						entry := models.UsageEntry{
-							Timestamp:           dayTime.Add(time.Duration(i) * time.Hour),
-							Model:               modelStat.Model,
-							InputTokens:         inputTokens,
-							OutputTokens:        outputTokens,
-							CacheCreationTokens: cacheCreationTokens,
-							CacheReadTokens:     cacheReadTokens,
-							TotalTokens:         inputTokens + outputTokens + cacheCreationTokens + cacheReadTokens,
-							CostUSD:             avgCostUSD,
+							Timestamp:             timestamp,
+							Model:                 modelStat.Model,
+							InputTokens:           inputTokens,
+							OutputTokens:          outputTokens,
+							CacheCreationTokens:   cacheCreationTokens,
+							CacheCreation1hTokens: cacheCreation1hTokens,
+							CacheReadTokens:       cacheReadTokens,
+							TotalTokens:           inputTokens + outputTokens + cacheCreationTokens + cacheReadTokens,
+							CostUSD:               avgCostUSD,
 						}
 
 						entry.NormalizeModel()
@@ -159,14 +191,15 @@ func createEntriesFromSummary(summary *cache.FileSummary, cutoffTime *time.Time)
 			if modelStat.EntryCount > 0 {
 				// Create a single aggregated entry per model
 				entry := models.UsageEntry{
-					Timestamp:           summary.ProcessedAt,
-					Model:               modelName,
-					InputTokens:         modelStat.InputTokens,
-					OutputTokens:        modelStat.OutputTokens,
-					CacheCreationTokens: modelStat.CacheCreationTokens,
-					CacheReadTokens:     modelStat.CacheReadTokens,
-					TotalTokens:         modelStat.InputTokens + modelStat.OutputTokens + modelStat.CacheCreationTokens + modelStat.CacheReadTokens,
-					CostUSD:             modelStat.TotalCost,
+					Timestamp:             summary.ProcessedAt,
+					Model:                 modelName,
+					InputTokens:           modelStat.InputTokens,
+					OutputTokens:          modelStat.OutputTokens,
+					CacheCreationTokens:   modelStat.CacheCreationTokens,
+					CacheCreation1hTokens: modelStat.CacheCreation1hTokens,
+					CacheReadTokens:       modelStat.CacheReadTokens,
+					TotalTokens:           modelStat.InputTokens + modelStat.OutputTokens + modelStat.CacheCreationTokens + modelStat.CacheReadTokens,
+					CostUSD:               modelStat.TotalCost,
 				}
 
 				entry.NormalizeModel()
@@ -194,8 +227,7 @@ func createSummaryFromEntries(absPath, filePath string, entries []models.UsageEn
 	}
 
 	// Calculate checksum (simple approach based on file mod time and size)
-	summary.Checksum = fmt.Sprintf("%x", md5.Sum([]byte(fmt.Sprintf("%s_%d_%d",
-		absPath, fileInfo.ModTime().Unix(), fileInfo.Size()))))
+	summary.Checksum = cache.SummaryChecksum(absPath, fileInfo.ModTime(), fileInfo.Size())
 
 	// Process entries to create statistics
 	var totalCost float64
@@ -227,6 +259,7 @@ func createSummaryFromEntries(absPath, filePath string, entries []models.UsageEn
 		modelStat.InputTokens += entry.InputTokens
 		modelStat.OutputTokens += entry.OutputTokens
 		modelStat.CacheCreationTokens += entry.CacheCreationTokens
+		modelStat.CacheCreation1hTokens += entry.CacheCreation1hTokens
 		modelStat.CacheReadTokens += entry.CacheReadTokens
 		summary.ModelStats[entry.Model] = modelStat
 
@@ -257,7 +290,12 @@ func createSummaryFromEntries(absPath, filePath string, entries []models.UsageEn
 		hourModelStat.InputTokens += entry.InputTokens
 		hourModelStat.OutputTokens += entry.OutputTokens
 		hourModelStat.CacheCreationTokens += entry.CacheCreationTokens
+		hourModelStat.CacheCreation1hTokens += entry.CacheCreation1hTokens
 		hourModelStat.CacheReadTokens += entry.CacheReadTokens
+		if entry.InputTokens+entry.CacheCreationTokens > hourModelStat.LargestInputTokens+hourModelStat.LargestCacheCreationTokens {
+			hourModelStat.LargestInputTokens = entry.InputTokens
+			hourModelStat.LargestCacheCreationTokens = entry.CacheCreationTokens
+		}
 
 		// Update daily bucket
 		dayKey := entry.Timestamp.Format("2006-01-02")
@@ -286,11 +324,13 @@ func createSummaryFromEntries(absPath, filePath string, entries []models.UsageEn
 		dayModelStat.InputTokens += entry.InputTokens
 		dayModelStat.OutputTokens += entry.OutputTokens
 		dayModelStat.CacheCreationTokens += entry.CacheCreationTokens
+		dayModelStat.CacheCreation1hTokens += entry.CacheCreation1hTokens
 		dayModelStat.CacheReadTokens += entry.CacheReadTokens
 	}
 
 	summary.TotalCost = totalCost
 	summary.TotalTokens = totalTokens
+	summary.SessionHints = buildSessionHints(entries)
 
 	return summary
 }
@@ -312,8 +352,7 @@ func createEmptySummaryForFile(absPath, filePath string) *cache.FileSummary {
 	}
 
 	// Calculate checksum
-	summary.Checksum = fmt.Sprintf("%x", md5.Sum([]byte(fmt.Sprintf("%s_%d_%d",
-		absPath, fileInfo.ModTime().Unix(), fileInfo.Size()))))
+	summary.Checksum = cache.SummaryChecksum(absPath, fileInfo.ModTime(), fileInfo.Size())
 
 	return summary
-}
\ No newline at end of file
+}
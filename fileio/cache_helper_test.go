@@ -0,0 +1,38 @@
+package fileio
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateEntriesFromSummary_KeepsLargestMessage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte("{}\n"), 0644))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+
+	start := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	original := []models.UsageEntry{
+		{Timestamp: start, Model: "claude-3-5-sonnet", InputTokens: 10, CacheCreationTokens: 100, CostUSD: 0.01},
+		{Timestamp: start.Add(time.Minute), Model: "claude-3-5-sonnet", InputTokens: 1000, CacheCreationTokens: 180000, CostUSD: 0.7},
+		{Timestamp: start.Add(2 * time.Minute), Model: "claude-3-5-sonnet", InputTokens: 21, CacheCreationTokens: 301, CostUSD: 0.02},
+	}
+	entries := createEntriesFromSummary(createSummaryFromEntries(path, path, original, info), nil)
+	require.Len(t, entries, 3)
+
+	largest, input, cacheCreation := 0, 0, 0
+	for _, entry := range entries {
+		largest = max(largest, entry.InputTokens+entry.CacheCreationTokens)
+		input += entry.InputTokens
+		cacheCreation += entry.CacheCreationTokens
+	}
+	assert.Equal(t, 181000, largest)
+	assert.Equal(t, 1031, input)
+	assert.Equal(t, 180401, cacheCreation)
+}
@@ -66,6 +66,12 @@ func (cl *ConcurrentLoader) LoadFiles(ctx context.Context, files []string, opts
 		TotalFiles: int32(len(files)),
 	}
 
+	// Per-file progress for callers that requested it
+	var tracker *progressTracker
+	if opts.Progress != nil {
+		tracker = newProgressTracker(len(files), opts.Progress)
+	}
+
 	// Create channels
 	fileChan := make(chan string, cl.bufferSize)
 	resultChan := make(chan FileResult, cl.bufferSize)
@@ -84,7 +90,7 @@ func (cl *ConcurrentLoader) LoadFiles(ctx context.Context, files []string, opts
 	for i := 0; i < cl.workerCount; i++ {
 		go func(workerID int) {
 			defer wg.Done()
-			cl.worker(ctx, workerID, fileChan, resultChan, opts, cutoffTime, progress, progressCallback)
+			cl.worker(ctx, workerID, fileChan, resultChan, opts, cutoffTime, progress, progressCallback, tracker)
 		}(i)
 	}
 
@@ -136,6 +142,7 @@ func (cl *ConcurrentLoader) worker(
 	cutoffTime *time.Time,
 	progress *LoadProgress,
 	progressCallback func(*LoadProgress),
+	tracker *progressTracker,
 ) {
 	for {
 		select {
@@ -174,6 +181,8 @@ func (cl *ConcurrentLoader) worker(
 				atomic.AddInt32(&progress.TotalEntries, int32(len(entries)))
 			}
 
+			tracker.record(fromCache, err)
+
 			// Send progress update
 			if progressCallback != nil && atomic.LoadInt32(&progress.ProcessedFiles)%10 == 0 {
 				progressCallback(progress)
@@ -194,13 +203,17 @@ func (cl *ConcurrentLoader) worker(
 
 // LoadFilesWithProgress is a convenience method that provides a default progress printer
 func (cl *ConcurrentLoader) LoadFilesWithProgress(ctx context.Context, files []string, opts LoadUsageEntriesOptions) ([]FileResult, error) {
+	var mu sync.Mutex
 	lastUpdate := time.Now()
 
 	progressCallback := func(progress *LoadProgress) {
+		mu.Lock()
 		if time.Since(lastUpdate) < 100*time.Millisecond {
+			mu.Unlock()
 			return // Throttle updates
 		}
 		lastUpdate = time.Now()
+		mu.Unlock()
 
 		processed := atomic.LoadInt32(&progress.ProcessedFiles)
 		total := atomic.LoadInt32(&progress.TotalFiles)
@@ -222,35 +235,31 @@ func (cl *ConcurrentLoader) LoadFilesWithProgress(ctx context.Context, files []s
 
 // MergeResults combines results from concurrent loading into a single sorted list
 func MergeResults(results []FileResult) ([]models.UsageEntry, []map[string]interface{}, []error) {
-	var allEntries []models.UsageEntry
 	var allRawEntries []map[string]interface{}
 	var errors []error
 
 	// Calculate total capacity needed
-	totalEntries := 0
+	runs := make([][]models.UsageEntry, 0, len(results))
 	totalRaw := 0
 	for _, result := range results {
 		if result.Error != nil {
 			errors = append(errors, fmt.Errorf("%s: %w", result.FilePath, result.Error))
 			continue
 		}
-		totalEntries += len(result.Entries)
+		runs = append(runs, result.Entries)
 		totalRaw += len(result.RawEntries)
 	}
 
 	// Pre-allocate slices
-	allEntries = make([]models.UsageEntry, 0, totalEntries)
 	if totalRaw > 0 {
 		allRawEntries = make([]map[string]interface{}, 0, totalRaw)
 	}
 
-	// Merge results
+	// Merge the per-file runs, which are already chronological
+	allEntries := mergeSortedRuns(runs)
 	for _, result := range results {
-		if result.Error == nil {
-			allEntries = append(allEntries, result.Entries...)
-			if result.RawEntries != nil {
-				allRawEntries = append(allRawEntries, result.RawEntries...)
-			}
+		if result.Error == nil && result.RawEntries != nil {
+			allRawEntries = append(allRawEntries, result.RawEntries...)
 		}
 	}
 
@@ -0,0 +1,171 @@
+package fileio
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+)
+
+// consoleUsageColumns maps each field of the Anthropic console usage export to the header names it has used
+var consoleUsageColumns = map[string][]string{
+	"date":          {"usage_date_utc", "usage_date", "date", "day"},
+	"model":         {"model_version", "model", "model_name"},
+	"workspace":     {"workspace", "workspace_name"},
+	"input":         {"uncached_input_tokens", "input_tokens", "input"},
+	"output":        {"output_tokens", "output"},
+	"cache_read":    {"cache_read_input_tokens", "cache_read_tokens", "cache_read"},
+	"cache_write":   {"cache_creation_input_tokens", "cache_write_tokens", "cache_creation_tokens", "cache_creation_5m_input_tokens", "cache_write_5m_tokens"},
+	"cache_write1h": {"cache_creation_1h_input_tokens", "cache_write_1h_tokens"},
+	"cost":          {"cost_usd", "cost", "amount_usd", "amount"},
+}
+
+// consoleUsageDateFormats are the date layouts accepted in the date column
+var consoleUsageDateFormats = []string{"2006-01-02", time.RFC3339, "2006-01-02 15:04:05", "01/02/2006"}
+
+// LoadConsoleUsageCSV reads an organization usage CSV downloaded from the Anthropic console
+func LoadConsoleUsageCSV(path string) ([]models.AnalysisResult, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open console usage CSV: %w", err)
+	}
+	defer file.Close()
+
+	results, err := ParseConsoleUsageCSV(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return results, nil
+}
+
+// ParseConsoleUsageCSV parses console usage rows into one result per row, dated at the start of the UTC day.
+// When the export has no cost column, the cost is computed from the model's pricing.
+func ParseConsoleUsageCSV(r io.Reader) ([]models.AnalysisResult, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = normalizeConsoleHeader(name)
+		for field, aliases := range consoleUsageColumns {
+			if _, ok := columns[field]; ok {
+				continue
+			}
+			for _, alias := range aliases {
+				if name == alias {
+					columns[field] = i
+				}
+			}
+		}
+	}
+	for _, field := range []string{"date", "model"} {
+		if _, ok := columns[field]; !ok {
+			return nil, fmt.Errorf("CSV has no %s column (expected one of: %s)", field, strings.Join(consoleUsageColumns[field], ", "))
+		}
+	}
+	_, hasCost := columns["cost"]
+
+	var results []models.AnalysisResult
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		if field("date") == "" && field("model") == "" {
+			continue
+		}
+
+		date, err := parseConsoleDate(field("date"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		entry := models.UsageEntry{Model: field("model")}
+		counts := map[string]*int{
+			"input":         &entry.InputTokens,
+			"output":        &entry.OutputTokens,
+			"cache_read":    &entry.CacheReadTokens,
+			"cache_write":   &entry.CacheCreationTokens,
+			"cache_write1h": &entry.CacheCreation1hTokens,
+		}
+		for name, target := range counts {
+			if *target, err = parseConsoleNumber[int](field(name)); err != nil {
+				return nil, fmt.Errorf("line %d: invalid %s: %w", line, name, err)
+			}
+		}
+		entry.CacheCreationTokens += entry.CacheCreation1hTokens
+
+		result := models.AnalysisResult{
+			Timestamp:             date,
+			Model:                 models.NormalizeModelName(entry.Model),
+			InputTokens:           entry.InputTokens,
+			OutputTokens:          entry.OutputTokens,
+			CacheCreationTokens:   entry.CacheCreationTokens,
+			CacheCreation1hTokens: entry.CacheCreation1hTokens,
+			CacheReadTokens:       entry.CacheReadTokens,
+			TotalTokens:           entry.CalculateTotalTokens(),
+			Count:                 1,
+			Project:               field("workspace"),
+		}
+		if hasCost {
+			if result.CostUSD, err = parseConsoleNumber[float64](field("cost")); err != nil {
+				return nil, fmt.Errorf("line %d: invalid cost: %w", line, err)
+			}
+		} else {
+			result.CostUSD = entry.CalculateCost(models.GetPricing(entry.Model))
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// normalizeConsoleHeader lowercases a header and joins its words with underscores
+func normalizeConsoleHeader(name string) string {
+	name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+	name = strings.NewReplacer("(", "", ")", "", "-", "_").Replace(name)
+	return strings.Join(strings.Fields(name), "_")
+}
+
+// parseConsoleDate parses a usage date as the start of its UTC day
+func parseConsoleDate(value string) (time.Time, error) {
+	for _, format := range consoleUsageDateFormats {
+		if t, err := time.Parse(format, value); err == nil {
+			t = t.UTC()
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unable to parse date: %q", value)
+}
+
+// parseConsoleNumber parses a count or amount, ignoring currency signs and thousands separators
+func parseConsoleNumber[T int | float64](value string) (T, error) {
+	value = strings.NewReplacer(",", "", "$", "").Replace(value)
+	if value == "" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	return T(f), nil
+}
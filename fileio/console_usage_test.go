@@ -0,0 +1,56 @@
+package fileio
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConsoleUsageCSV(t *testing.T) {
+	data := "\ufeffUsage Date (UTC),Model Version,Workspace,Uncached Input Tokens,Cache Read Input Tokens,Cache Creation Input Tokens,Cache Creation 1h Input Tokens,Output Tokens,Cost (USD)\n" +
+		"2025-06-01,claude-sonnet-4-20250514,Default,\"1,000\",200,300,100,500,$1.25\n" +
+		",,,,,,,,\n" +
+		"2025-06-02T15:00:00Z,claude-opus-4-20250514,Research,10,0,0,0,20,0.50\n"
+
+	results, err := ParseConsoleUsageCSV(strings.NewReader(data))
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	first := results[0]
+	assert.Equal(t, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), first.Timestamp)
+	assert.Equal(t, models.NormalizeModelName("claude-sonnet-4-20250514"), first.Model)
+	assert.Equal(t, "Default", first.Project)
+	assert.Equal(t, 1000, first.InputTokens)
+	assert.Equal(t, 200, first.CacheReadTokens)
+	assert.Equal(t, 400, first.CacheCreationTokens)
+	assert.Equal(t, 100, first.CacheCreation1hTokens)
+	assert.Equal(t, 2100, first.TotalTokens)
+	assert.InDelta(t, 1.25, first.CostUSD, 0.0001)
+
+	// Timestamps are truncated to the UTC day
+	assert.Equal(t, time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC), results[1].Timestamp)
+}
+
+func TestParseConsoleUsageCSV_ComputesMissingCost(t *testing.T) {
+	data := "date,model,input_tokens,output_tokens\n2025-06-01,claude-3-5-haiku-20241022,1000000,0\n"
+
+	results, err := ParseConsoleUsageCSV(strings.NewReader(data))
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.InDelta(t, models.GetPricing(models.ModelHaiku).Input, results[0].CostUSD, 0.0001)
+}
+
+func TestParseConsoleUsageCSV_Errors(t *testing.T) {
+	_, err := ParseConsoleUsageCSV(strings.NewReader("model,output_tokens\nclaude,1\n"))
+	assert.ErrorContains(t, err, "no date column")
+
+	_, err = ParseConsoleUsageCSV(strings.NewReader("date,model,output_tokens\nyesterday,claude,1\n"))
+	assert.ErrorContains(t, err, "line 2")
+
+	_, err = ParseConsoleUsageCSV(strings.NewReader("date,model,output_tokens\n2025-06-01,claude,many\n"))
+	assert.ErrorContains(t, err, "invalid output")
+}
@@ -0,0 +1,154 @@
+package fileio
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+)
+
+// DataDirsStateFile is the file in the cache directory recording the last resolved Claude data directories
+const DataDirsStateFile = "data_dirs.json"
+
+// DataDirs is a resolved set of Claude Code project log directories
+type DataDirs struct {
+	Dirs           []string  `json:"dirs"`
+	ResolvedAt     time.Time `json:"resolved_at"`
+	DuplicateFiles int       `json:"duplicate_files"` // Session logs present in more than one directory
+	Warnings       []string  `json:"-"`
+}
+
+// DataDirCandidates returns the locations Claude Code has used for project logs on a platform,
+// most preferred first. CLAUDE_CONFIG_DIR may list several directories separated by commas.
+func DataDirCandidates(homeDir, goos string, getenv func(string) string) []string {
+	var candidates []string
+	for _, dir := range strings.Split(getenv("CLAUDE_CONFIG_DIR"), ",") {
+		if dir = strings.TrimSpace(dir); dir != "" {
+			candidates = append(candidates, filepath.Join(dir, "projects"))
+		}
+	}
+
+	candidates = append(candidates, filepath.Join(homeDir, ".claude", "projects"))
+	if xdg := getenv("XDG_CONFIG_HOME"); xdg != "" {
+		candidates = append(candidates, filepath.Join(xdg, "claude", "projects"))
+	}
+	candidates = append(candidates, filepath.Join(homeDir, ".config", "claude", "projects"))
+
+	switch goos {
+	case "darwin":
+		candidates = append(candidates, filepath.Join(homeDir, "Library", "Application Support", "Claude", "projects"))
+	case "windows":
+		for _, env := range []string{"APPDATA", "LOCALAPPDATA"} {
+			if dir := getenv(env); dir != "" {
+				candidates = append(candidates, filepath.Join(dir, "Claude", "projects"))
+			}
+		}
+	}
+	return candidates
+}
+
+// ResolveDataDirs keeps the candidates that exist, skipping paths that resolve to a directory already
+// found, and warns when the same session log is present in several directories
+func ResolveDataDirs(candidates []string) DataDirs {
+	resolved := DataDirs{Dirs: []string{}}
+	realPaths := make(map[string]string)
+	for _, dir := range candidates {
+		info, err := os.Stat(dir)
+		if err != nil || !info.IsDir() {
+			continue
+		}
+		real, err := filepath.EvalSymlinks(dir)
+		if err != nil {
+			real = dir
+		}
+		if first, ok := realPaths[real]; ok {
+			if first != dir {
+				resolved.Warnings = append(resolved.Warnings, fmt.Sprintf("%s is the same directory as %s; using it once", dir, first))
+			}
+			continue
+		}
+		realPaths[real] = dir
+		resolved.Dirs = append(resolved.Dirs, dir)
+	}
+
+	// Session logs are named by session ID, so the same relative path in two directories is the same session
+	owners := make(map[string]string)
+	duplicates := make(map[[2]string]int)
+	for _, dir := range resolved.Dirs {
+		files, err := findJSONLFiles(dir)
+		if err != nil {
+			continue
+		}
+		for _, file := range files {
+			rel, err := filepath.Rel(dir, file)
+			if err != nil {
+				continue
+			}
+			if owner, ok := owners[rel]; ok {
+				duplicates[[2]string{owner, dir}]++
+				resolved.DuplicateFiles++
+				continue
+			}
+			owners[rel] = dir
+		}
+	}
+	pairs := make([][2]string, 0, len(duplicates))
+	for pair := range duplicates {
+		pairs = append(pairs, pair)
+	}
+	slices.SortFunc(pairs, func(a, b [2]string) int { return strings.Compare(a[0]+a[1], b[0]+b[1]) })
+	for _, pair := range pairs {
+		resolved.Warnings = append(resolved.Warnings, fmt.Sprintf("%d session log(s) exist in both %s and %s",
+			duplicates[pair], pair[0], pair[1]))
+	}
+
+	resolved.ResolvedAt = time.Now()
+	return resolved
+}
+
+// LoadDataDirs reads the data directories persisted by SaveDataDirs
+func LoadDataDirs(path string) (*DataDirs, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var dirs DataDirs
+	if err := sonic.Unmarshal(data, &dirs); err != nil {
+		return nil, fmt.Errorf("failed to parse data dirs: %w", err)
+	}
+	return &dirs, nil
+}
+
+// SaveDataDirs persists a resolved set of data directories
+func SaveDataDirs(path string, dirs DataDirs) error {
+	data, err := sonic.MarshalIndent(dirs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode data dirs: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// DiscoverDataDirs resolves the known data directory candidates and persists the result to statePath,
+// adding a warning when the set differs from the previously persisted one. An empty statePath skips persistence.
+func DiscoverDataDirs(candidates []string, statePath string) DataDirs {
+	resolved := ResolveDataDirs(candidates)
+	if statePath == "" {
+		return resolved
+	}
+
+	if previous, err := LoadDataDirs(statePath); err == nil && !slices.Equal(previous.Dirs, resolved.Dirs) {
+		resolved.Warnings = append(resolved.Warnings, fmt.Sprintf("Claude data directories changed from [%s] to [%s]",
+			strings.Join(previous.Dirs, ", "), strings.Join(resolved.Dirs, ", ")))
+	}
+	if err := SaveDataDirs(statePath, resolved); err != nil {
+		resolved.Warnings = append(resolved.Warnings, fmt.Sprintf("failed to persist data directories: %v", err))
+	}
+	return resolved
+}
@@ -0,0 +1,68 @@
+package fileio
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataDirCandidates(t *testing.T) {
+	env := map[string]string{
+		"CLAUDE_CONFIG_DIR": "/custom/a, /custom/b",
+		"APPDATA":           "/appdata",
+	}
+	getenv := func(key string) string { return env[key] }
+
+	linux := DataDirCandidates("/home/me", "linux", getenv)
+	assert.Equal(t, []string{
+		filepath.Join("/custom/a", "projects"),
+		filepath.Join("/custom/b", "projects"),
+		filepath.Join("/home/me", ".claude", "projects"),
+		filepath.Join("/home/me", ".config", "claude", "projects"),
+	}, linux)
+
+	darwin := DataDirCandidates("/home/me", "darwin", getenv)
+	assert.Contains(t, darwin, filepath.Join("/home/me", "Library", "Application Support", "Claude", "projects"))
+
+	windows := DataDirCandidates("/home/me", "windows", getenv)
+	assert.Contains(t, windows, filepath.Join("/appdata", "Claude", "projects"))
+}
+
+func TestDiscoverDataDirs(t *testing.T) {
+	home := t.TempDir()
+	legacy := filepath.Join(home, ".claude", "projects")
+	current := filepath.Join(home, ".config", "claude", "projects")
+	for _, dir := range []string{legacy, current} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "app"), 0755))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(legacy, "app", "s1.jsonl"), []byte("{}\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(current, "app", "s1.jsonl"), []byte("{}\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(current, "app", "s2.jsonl"), []byte("{}\n"), 0644))
+
+	alias := filepath.Join(home, "alias")
+	require.NoError(t, os.Symlink(current, alias))
+
+	missing := filepath.Join(home, "missing")
+	statePath := filepath.Join(t.TempDir(), DataDirsStateFile)
+
+	dirs := DiscoverDataDirs([]string{legacy, current, alias, missing}, statePath)
+	assert.Equal(t, []string{legacy, current}, dirs.Dirs)
+	assert.Equal(t, 1, dirs.DuplicateFiles)
+	require.Len(t, dirs.Warnings, 2)
+	assert.Contains(t, dirs.Warnings[0], "same directory")
+	assert.Contains(t, dirs.Warnings[1], "1 session log(s)")
+
+	saved, err := LoadDataDirs(statePath)
+	require.NoError(t, err)
+	assert.Equal(t, dirs.Dirs, saved.Dirs)
+
+	// A change in the resolved set is reported against the persisted one
+	require.NoError(t, os.RemoveAll(legacy))
+	dirs = DiscoverDataDirs([]string{legacy, current}, statePath)
+	assert.Equal(t, []string{current}, dirs.Dirs)
+	require.Len(t, dirs.Warnings, 1)
+	assert.Contains(t, dirs.Warnings[0], "changed")
+}
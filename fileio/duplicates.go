@@ -0,0 +1,178 @@
+package fileio
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// DuplicateProject is a project directory whose conversations are copies of those in another one, as
+// when a data directory synced between machines names a project after each machine's checkout path
+type DuplicateProject struct {
+	DataPath string `json:"data_path"`
+	Dir      string `json:"dir"`      // Directory of the copies, relative to DataPath
+	Original string `json:"original"` // Directory holding the other copies
+	Shared   int    `json:"shared"`   // Conversations found in both directories
+	Files    int    `json:"files"`    // Conversation logs in Dir
+	Pattern  string `json:"pattern"`  // data.exclude pattern skipping Dir
+}
+
+// DuplicateScan is the result of ScanDuplicateProjects
+type DuplicateScan struct {
+	Projects []DuplicateProject
+	// Redundant holds the copies skipped when merging: every copy of a conversation but the largest,
+	// which a lagging sync has not truncated
+	Redundant map[string]bool
+}
+
+// conversationCopy is one log of a conversation
+type conversationCopy struct {
+	dataPath string
+	dir      string
+	file     string
+	size     int64
+}
+
+// ScanDuplicateProjects finds the conversations logged in more than one directory of paths. Claude Code
+// names each session log after its conversation ID, so logs with the same name are copies of one
+// conversation. Files matching exclude are left out.
+func ScanDuplicateProjects(paths []string, exclude []string) DuplicateScan {
+	copies := make(map[string][]conversationCopy)
+	var ids []string
+	dirFiles := make(map[string]int)
+	dirOrder := make(map[string]int)
+	for _, dataPath := range paths {
+		files, err := findJSONLFiles(dataPath)
+		if err != nil {
+			continue
+		}
+		for _, file := range files {
+			if Excluded(dataPath, file, exclude) {
+				continue
+			}
+			info, err := os.Stat(file)
+			if err != nil {
+				continue
+			}
+			dir := filepath.Dir(file)
+			if _, ok := dirOrder[dir]; !ok {
+				dirOrder[dir] = len(dirOrder)
+			}
+			dirFiles[dir]++
+			id := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+			if len(copies[id]) == 0 {
+				ids = append(ids, id)
+			}
+			copies[id] = append(copies[id], conversationCopy{dataPath: dataPath, dir: dir, file: file, size: info.Size()})
+		}
+	}
+
+	scan := DuplicateScan{Redundant: make(map[string]bool)}
+	type dirPair struct{ a, b conversationCopy }
+	shared := make(map[[2]string]int)
+	var pairs []dirPair
+	for _, id := range ids {
+		group := copies[id]
+		if len(group) < 2 {
+			continue
+		}
+		// Equal copies are kept in the directory with more logs, the one reported as the original
+		largest := 0
+		for i, c := range group {
+			if c.size > group[largest].size || (c.size == group[largest].size && dirFiles[c.dir] > dirFiles[group[largest].dir]) {
+				largest = i
+			}
+		}
+		for i, c := range group {
+			if i != largest {
+				scan.Redundant[c.file] = true
+			}
+			for _, other := range group[i+1:] {
+				if other.dir == c.dir {
+					continue
+				}
+				key := [2]string{c.dir, other.dir}
+				if shared[key] == 0 {
+					pairs = append(pairs, dirPair{c, other})
+				}
+				shared[key]++
+			}
+		}
+	}
+
+	for _, pair := range pairs {
+		// The directory with fewer logs is the partial copy; on a tie, the one found later
+		duplicate, original := pair.b, pair.a
+		if dirFiles[pair.a.dir] < dirFiles[pair.b.dir] || (dirFiles[pair.a.dir] == dirFiles[pair.b.dir] && dirOrder[pair.a.dir] > dirOrder[pair.b.dir]) {
+			duplicate, original = pair.a, pair.b
+		}
+		rel := relativeDir(duplicate)
+		// A directory of the same name in another data path would match the relative pattern too
+		pattern := rel
+		if rel == relativeDir(original) {
+			if abs, err := filepath.Abs(duplicate.dir); err == nil {
+				pattern = filepath.ToSlash(abs)
+			}
+		}
+		scan.Projects = append(scan.Projects, DuplicateProject{
+			DataPath: duplicate.dataPath,
+			Dir:      rel,
+			Original: original.dir,
+			Shared:   shared[[2]string{pair.a.dir, pair.b.dir}],
+			Files:    dirFiles[duplicate.dir],
+			Pattern:  escapePattern(pattern),
+		})
+	}
+	slices.SortStableFunc(scan.Projects, func(a, b DuplicateProject) int { return b.Shared - a.Shared })
+	return scan
+}
+
+// relativeDir returns the directory of a copy relative to its data path, with forward slashes
+func relativeDir(c conversationCopy) string {
+	rel, err := filepath.Rel(c.dataPath, c.dir)
+	if err != nil {
+		rel = c.dir
+	}
+	return filepath.ToSlash(rel)
+}
+
+// Excluded reports whether the directory of file matches one of the data.exclude patterns: relative to
+// dataPath, such as -Users-me-Dropbox-* for the project directories of a synced checkout, or absolute
+func Excluded(dataPath, file string, patterns []string) bool {
+	if len(patterns) == 0 {
+		return false
+	}
+	dir := filepath.Dir(file)
+	rel, err := filepath.Rel(dataPath, dir)
+	if err != nil {
+		rel = dir
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		abs = dir
+	}
+	for _, pattern := range patterns {
+		target := filepath.ToSlash(rel)
+		if filepath.IsAbs(filepath.FromSlash(pattern)) {
+			target = filepath.ToSlash(abs)
+		}
+		if matched, _ := path.Match(pattern, target); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// escapePattern quotes the path.Match metacharacters of name
+func escapePattern(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if strings.ContainsRune(`*?[\`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,95 @@
+package fileio
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/penwyp/claudecat/logging"
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeSessionLog writes lines assistant messages to dataDir/project/id.jsonl
+func writeSessionLog(t *testing.T, dataDir, project, id string, lines int) string {
+	t.Helper()
+	var b strings.Builder
+	for i := range lines {
+		b.WriteString(`{"type":"assistant","timestamp":"2024-03-15T10:3` + string(rune('0'+i)) + `:00Z","message":{"id":"` + id + string(rune('a'+i)) +
+			`","model":"claude-3-5-sonnet-20241022","usage":{"input_tokens":10,"output_tokens":5}}}` + "\n")
+	}
+	dir := filepath.Join(dataDir, project)
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	path := filepath.Join(dir, id+".jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(b.String()), 0644))
+	return path
+}
+
+func TestScanDuplicateProjects(t *testing.T) {
+	dataDir := t.TempDir()
+	writeSessionLog(t, dataDir, "-Users-me-webapp", "conv-1", 3)
+	writeSessionLog(t, dataDir, "-Users-me-webapp", "conv-2", 2)
+	writeSessionLog(t, dataDir, "-Users-me-webapp", "conv-3", 1)
+	writeSessionLog(t, dataDir, "-Users-me-webapp", "conv-5", 1)
+	// The synced copy lags behind on conv-1 and has a conversation of its own
+	lagging := writeSessionLog(t, dataDir, "-Users-me-Dropbox-webapp", "conv-1", 2)
+	copied := writeSessionLog(t, dataDir, "-Users-me-Dropbox-webapp", "conv-2", 2)
+	writeSessionLog(t, dataDir, "-Users-me-Dropbox-webapp", "conv-4", 1)
+
+	scan := ScanDuplicateProjects([]string{dataDir}, nil)
+	require.Len(t, scan.Projects, 1)
+	assert.Equal(t, DuplicateProject{
+		DataPath: dataDir,
+		Dir:      "-Users-me-Dropbox-webapp",
+		Original: filepath.Join(dataDir, "-Users-me-webapp"),
+		Shared:   2,
+		Files:    3,
+		Pattern:  "-Users-me-Dropbox-webapp",
+	}, scan.Projects[0])
+	assert.Equal(t, map[string]bool{lagging: true, copied: true}, scan.Redundant, "the largest copy of each conversation is kept")
+
+	assert.Empty(t, ScanDuplicateProjects([]string{dataDir}, []string{"-Users-me-Dropbox-*"}).Projects)
+}
+
+func TestScanDuplicateProjects_SameNameInTwoDataPaths(t *testing.T) {
+	local, synced := t.TempDir(), t.TempDir()
+	writeSessionLog(t, local, "-Users-me-webapp", "conv-1", 1)
+	writeSessionLog(t, local, "-Users-me-webapp", "conv-2", 1)
+	writeSessionLog(t, synced, "-Users-me-webapp", "conv-1", 1)
+
+	scan := ScanDuplicateProjects([]string{local, synced}, nil)
+	require.Len(t, scan.Projects, 1)
+	assert.Equal(t, synced, scan.Projects[0].DataPath)
+	abs, err := filepath.Abs(filepath.Join(synced, "-Users-me-webapp"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.ToSlash(abs), scan.Projects[0].Pattern, "a relative pattern would skip both directories")
+	assert.True(t, Excluded(synced, filepath.Join(synced, "-Users-me-webapp", "conv-1.jsonl"), []string{scan.Projects[0].Pattern}))
+	assert.False(t, Excluded(local, filepath.Join(local, "-Users-me-webapp", "conv-1.jsonl"), []string{scan.Projects[0].Pattern}))
+}
+
+func TestExcluded(t *testing.T) {
+	file := filepath.Join("data", "-Users-me-Dropbox-webapp", "conv.jsonl")
+	assert.True(t, Excluded("data", file, []string{"-Users-me-Dropbox-*"}))
+	assert.False(t, Excluded("data", file, []string{"-Users-me-webapp"}))
+	assert.False(t, Excluded("data", file, nil))
+	assert.Equal(t, `a\*b\?c`, escapePattern("a*b?c"))
+}
+
+func TestLoadUsageEntries_SkipFiles(t *testing.T) {
+	logging.InitLogger("error", filepath.Join(t.TempDir(), "test.log"), false)
+	dataDir := t.TempDir()
+	writeSessionLog(t, dataDir, "-Users-me-webapp", "conv-1", 3)
+	writeSessionLog(t, dataDir, "-Users-me-Dropbox-webapp", "conv-1", 2)
+	writeSessionLog(t, dataDir, "-Users-me-scratch", "conv-2", 1)
+
+	load := func(exclude []string, skip map[string]bool) int {
+		result, err := LoadUsageEntries(LoadUsageEntriesOptions{DataPath: dataDir, Mode: models.CostModeCalculated, Exclude: exclude, SkipFiles: skip})
+		require.NoError(t, err)
+		return len(result.Entries)
+	}
+	assert.Equal(t, 6, load(nil, nil), "the copy is counted twice")
+	assert.Equal(t, 4, load(nil, ScanDuplicateProjects([]string{dataDir}, nil).Redundant))
+	assert.Equal(t, 3, load([]string{"-Users-me-Dropbox-*", "*scratch"}, nil))
+}
@@ -0,0 +1,82 @@
+package fileio
+
+import (
+	"container/heap"
+	"sort"
+
+	"github.com/penwyp/claudecat/models"
+)
+
+// mergeSortedRuns merges per-file entry runs into one chronological slice with a k-way merge.
+// Entries within a log file are normally already chronological; a run that is not is sorted first.
+// Entries with equal timestamps keep the order of their runs.
+func mergeSortedRuns(runs [][]models.UsageEntry) []models.UsageEntry {
+	total := 0
+	nonEmpty := runs[:0:0]
+	for _, run := range runs {
+		if len(run) == 0 {
+			continue
+		}
+		if !sort.SliceIsSorted(run, func(i, j int) bool { return run[i].Timestamp.Before(run[j].Timestamp) }) {
+			sort.SliceStable(run, func(i, j int) bool { return run[i].Timestamp.Before(run[j].Timestamp) })
+		}
+		total += len(run)
+		nonEmpty = append(nonEmpty, run)
+	}
+
+	merged := make([]models.UsageEntry, 0, total)
+	switch len(nonEmpty) {
+	case 0:
+		return merged
+	case 1:
+		return append(merged, nonEmpty[0]...)
+	}
+
+	cursors := make(runHeap, len(nonEmpty))
+	for i, run := range nonEmpty {
+		cursors[i] = &runCursor{run: run, order: i}
+	}
+	heap.Init(&cursors)
+	for len(cursors) > 0 {
+		cursor := cursors[0]
+		merged = append(merged, cursor.run[cursor.pos])
+		cursor.pos++
+		if cursor.pos == len(cursor.run) {
+			heap.Pop(&cursors)
+		} else {
+			heap.Fix(&cursors, 0)
+		}
+	}
+	return merged
+}
+
+// runCursor is the read position within one sorted run
+type runCursor struct {
+	run   []models.UsageEntry
+	pos   int
+	order int // Index of the run, used to break timestamp ties
+}
+
+// runHeap is a min-heap of run cursors ordered by the timestamp of their current entry
+type runHeap []*runCursor
+
+func (h runHeap) Len() int { return len(h) }
+
+func (h runHeap) Less(i, j int) bool {
+	a, b := h[i].run[h[i].pos].Timestamp, h[j].run[h[j].pos].Timestamp
+	if a.Equal(b) {
+		return h[i].order < h[j].order
+	}
+	return a.Before(b)
+}
+
+func (h runHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *runHeap) Push(x any) { *h = append(*h, x.(*runCursor)) }
+
+func (h *runHeap) Pop() any {
+	old := *h
+	cursor := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return cursor
+}
@@ -0,0 +1,96 @@
+package fileio
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeSortedRuns(t *testing.T) {
+	base := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	entry := func(minute int, id string) models.UsageEntry {
+		return models.UsageEntry{Timestamp: base.Add(time.Duration(minute) * time.Minute), MessageID: id}
+	}
+
+	runs := [][]models.UsageEntry{
+		{entry(1, "a1"), entry(4, "a4"), entry(9, "a9")},
+		nil,
+		{entry(2, "b2"), entry(4, "b4"), entry(5, "b5")},
+		{entry(8, "c8"), entry(3, "c3")}, // Out of order runs are sorted first
+	}
+	merged := mergeSortedRuns(runs)
+
+	var ids []string
+	for _, e := range merged {
+		ids = append(ids, e.MessageID)
+	}
+	// Equal timestamps keep the order of their runs
+	assert.Equal(t, []string{"a1", "b2", "c3", "a4", "b4", "b5", "c8", "a9"}, ids)
+
+	assert.Empty(t, mergeSortedRuns(nil))
+	single := mergeSortedRuns([][]models.UsageEntry{{entry(1, "x")}})
+	require.Len(t, single, 1)
+}
+
+func TestMergeResultsWithDedup_KeepsEarliest(t *testing.T) {
+	base := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	results := []FileResult{
+		{FilePath: "a.jsonl", Entries: []models.UsageEntry{
+			{Timestamp: base.Add(2 * time.Minute), MessageID: "m1", RequestID: "r1", InputTokens: 1},
+			{Timestamp: base.Add(5 * time.Minute), MessageID: "m2", RequestID: "r2", InputTokens: 2},
+		}},
+		{FilePath: "b.jsonl", Entries: []models.UsageEntry{
+			{Timestamp: base.Add(2 * time.Minute), MessageID: "m1", RequestID: "r1", InputTokens: 10},
+			{Timestamp: base.Add(3 * time.Minute), MessageID: "m3", RequestID: "r3", InputTokens: 3},
+		}},
+	}
+
+	entries, _, errs := MergeResultsWithDedup(results, make(map[string]bool))
+	require.Empty(t, errs)
+	require.Len(t, entries, 3)
+	assert.Equal(t, 1, entries[0].InputTokens, "the duplicate from the earlier file is kept")
+	assert.Equal(t, "m3", entries[1].MessageID)
+	assert.Equal(t, "m2", entries[2].MessageID)
+}
+
+// benchmarkRuns generates files of chronological entries with interleaved time ranges
+func benchmarkRuns(files, perFile int) [][]models.UsageEntry {
+	rng := rand.New(rand.NewSource(1))
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	runs := make([][]models.UsageEntry, files)
+	for i := range runs {
+		ts := base.Add(time.Duration(rng.Intn(1000)) * time.Hour)
+		run := make([]models.UsageEntry, perFile)
+		for j := range run {
+			ts = ts.Add(time.Duration(rng.Intn(120)) * time.Second)
+			run[j] = models.UsageEntry{Timestamp: ts}
+		}
+		runs[i] = run
+	}
+	return runs
+}
+
+func BenchmarkMergeSortedRuns(b *testing.B) {
+	runs := benchmarkRuns(2000, 500)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		mergeSortedRuns(runs)
+	}
+}
+
+func BenchmarkGlobalSort(b *testing.B) {
+	runs := benchmarkRuns(2000, 500)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var all []models.UsageEntry
+		for _, run := range runs {
+			all = append(all, run...)
+		}
+		sort.Slice(all, func(i, j int) bool { return all[i].Timestamp.Before(all[j].Timestamp) })
+	}
+}
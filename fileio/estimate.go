@@ -0,0 +1,34 @@
+package fileio
+
+import (
+	"os"
+	"time"
+)
+
+// MeasureParseRate parses files in order, without a cache, until about maxBytes were read and returns
+// the bytes parsed per second, or 0 when nothing could be parsed. Only the mode, pricing provider
+// and max line size of opts are used.
+func MeasureParseRate(files []string, maxBytes int64, opts LoadUsageEntriesOptions) float64 {
+	opts.pricing = newPricingResolver(opts.PricingProvider)
+	var parsed int64
+	var elapsed time.Duration
+	for _, file := range files {
+		if parsed >= maxBytes {
+			break
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		start := time.Now()
+		if _, _, err := processSingleFileWithDedup(file, opts.Mode, nil, false, nil, &opts); err != nil {
+			continue
+		}
+		elapsed += time.Since(start)
+		parsed += info.Size()
+	}
+	if parsed == 0 || elapsed <= 0 {
+		return 0
+	}
+	return float64(parsed) / elapsed.Seconds()
+}
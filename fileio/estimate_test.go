@@ -0,0 +1,24 @@
+package fileio
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeasureParseRate(t *testing.T) {
+	dir := t.TempDir()
+	line := `{"type":"assistant","timestamp":"2025-06-01T10:00:00Z","message":{"model":"claude-sonnet-4-20250514","usage":{"input_tokens":10,"output_tokens":5}}}`
+	path := filepath.Join(dir, "session.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(strings.Repeat(line+"\n", 200)), 0644))
+
+	opts := LoadUsageEntriesOptions{Mode: models.CostModeCalculated}
+	assert.Positive(t, MeasureParseRate([]string{path}, 1<<20, opts))
+	assert.Zero(t, MeasureParseRate([]string{filepath.Join(dir, "missing.jsonl")}, 1<<20, opts))
+	assert.Zero(t, MeasureParseRate([]string{path}, 0, opts), "nothing is parsed past the byte budget")
+}
@@ -0,0 +1,296 @@
+package fileio
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Session patterns of generated test data
+const (
+	PatternSteady   = "steady"   // A few sessions every day during working hours
+	PatternWeekdays = "weekdays" // Like steady, without weekends
+	PatternBursty   = "bursty"   // Long, heavy sessions on a few days, little usage in between
+)
+
+// GeneratePatterns lists the session patterns of generated test data
+var GeneratePatterns = []string{PatternSteady, PatternWeekdays, PatternBursty}
+
+// DefaultGenerateModels are the models of generated sessions when none are given
+var DefaultGenerateModels = []string{"claude-sonnet-4-20250514", "claude-opus-4-20250514"}
+
+// generateProjects name the projects of generated sessions
+var generateProjects = []string{"webapp", "api", "cli", "infra", "docs", "mobile", "data-pipeline", "design-system"}
+
+// GenerateOptions configures synthetic Claude Code conversation logs
+type GenerateOptions struct {
+	Dir            string    // Written as <Dir>/<project>/<session>.jsonl, like ~/.claude/projects
+	End            time.Time // The last day of data; its location sets the working hours
+	Now            time.Time // No line is later than Now (zero = time.Now())
+	Days           int
+	Models         []string // The first model is used most (empty = DefaultGenerateModels)
+	Projects       int
+	SessionsPerDay int // Average on a day with usage
+	Pattern        string
+	MalformedRate  float64 // Fraction of lines written truncated or garbled
+	Seed           int64   // The same seed and options write the same files
+}
+
+// GenerateResult counts what GenerateTestData wrote
+type GenerateResult struct {
+	Files     int `json:"files"`
+	Lines     int `json:"lines"`
+	Entries   int `json:"entries"`   // Well-formed assistant messages with usage
+	Malformed int `json:"malformed"` // Lines written malformed
+}
+
+// generatedLine is a line of a Claude Code conversation log
+type generatedLine struct {
+	ParentUUID  *string          `json:"parentUuid"`
+	IsSidechain bool             `json:"isSidechain"`
+	UserType    string           `json:"userType"`
+	Cwd         string           `json:"cwd"`
+	SessionID   string           `json:"sessionId"`
+	Version     string           `json:"version"`
+	Type        string           `json:"type"`
+	Message     generatedMessage `json:"message"`
+	RequestID   string           `json:"requestId,omitempty"`
+	UUID        string           `json:"uuid"`
+	Timestamp   string           `json:"timestamp"`
+}
+
+// generatedMessage is the message of a generated line
+type generatedMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type,omitempty"`
+	Role    string          `json:"role"`
+	Model   string          `json:"model,omitempty"`
+	Content []generatedText `json:"content"`
+	Usage   *generatedUsage `json:"usage,omitempty"`
+}
+
+// generatedText is a text content block
+type generatedText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// generatedUsage is the token usage of an assistant message
+type generatedUsage struct {
+	InputTokens              int `json:"input_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+}
+
+// GenerateTestData writes synthetic conversation logs of opts.Days days ending on opts.End, for
+// reproducing bugs and benchmarking without private data
+func GenerateTestData(opts GenerateOptions) (GenerateResult, error) {
+	var result GenerateResult
+	if err := validateGenerateOptions(&opts); err != nil {
+		return result, err
+	}
+	rng := rand.New(rand.NewSource(opts.Seed))
+	loc := opts.End.Location()
+	last := time.Date(opts.End.Year(), opts.End.Month(), opts.End.Day(), 0, 0, 0, 0, loc)
+
+	for i := opts.Days - 1; i >= 0; i-- {
+		day := last.AddDate(0, 0, -i)
+		sessions, messages := sessionsForDay(rng, opts, day)
+		for range sessions {
+			project := generateProjects[rng.Intn(opts.Projects)]
+			model := opts.Models[0]
+			if len(opts.Models) > 1 && rng.Float64() < 0.35 {
+				model = opts.Models[1+rng.Intn(len(opts.Models)-1)]
+			}
+			start := day.Add(time.Duration(9*60+rng.Intn(9*60)) * time.Minute)
+			n := messages[0] + rng.Intn(messages[1]-messages[0]+1)
+			if start.After(opts.Now) {
+				// Sessions later than now would be active alongside the current one
+				continue
+			}
+			if err := writeGeneratedSession(rng, opts, project, model, start, n, &result); err != nil {
+				return result, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// validateGenerateOptions checks opts and fills in the defaults
+func validateGenerateOptions(opts *GenerateOptions) error {
+	if opts.Dir == "" {
+		return fmt.Errorf("no output directory")
+	}
+	if opts.Days < 1 {
+		return fmt.Errorf("invalid days: %d (must be at least 1)", opts.Days)
+	}
+	if opts.Projects < 1 || opts.Projects > len(generateProjects) {
+		return fmt.Errorf("invalid projects: %d (must be between 1 and %d)", opts.Projects, len(generateProjects))
+	}
+	if opts.SessionsPerDay < 1 {
+		return fmt.Errorf("invalid sessions per day: %d (must be at least 1)", opts.SessionsPerDay)
+	}
+	if opts.MalformedRate < 0 || opts.MalformedRate > 1 {
+		return fmt.Errorf("invalid malformed rate: %g (must be between 0 and 1)", opts.MalformedRate)
+	}
+	if opts.Pattern == "" {
+		opts.Pattern = PatternSteady
+	}
+	if !slices.Contains(GeneratePatterns, opts.Pattern) {
+		return fmt.Errorf("invalid pattern: %s (valid: %s)", opts.Pattern, strings.Join(GeneratePatterns, ", "))
+	}
+	if len(opts.Models) == 0 {
+		opts.Models = DefaultGenerateModels
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	if opts.End.IsZero() {
+		opts.End = opts.Now
+	}
+	return nil
+}
+
+// sessionsForDay returns the number of sessions of a day and the range of their message counts
+func sessionsForDay(rng *rand.Rand, opts GenerateOptions, day time.Time) (int, [2]int) {
+	vary := func(n int) int {
+		return max(n/2+rng.Intn(n+1), 1)
+	}
+	switch opts.Pattern {
+	case PatternWeekdays:
+		if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+			return 0, [2]int{}
+		}
+	case PatternBursty:
+		if rng.Float64() < 0.25 {
+			return vary(opts.SessionsPerDay * 2), [2]int{40, 160}
+		}
+		if rng.Float64() < 0.5 {
+			return 0, [2]int{}
+		}
+		return 1, [2]int{2, 8}
+	}
+	return vary(opts.SessionsPerDay), [2]int{5, 40}
+}
+
+// writeGeneratedSession writes a session of n exchanges of a user prompt and an assistant reply,
+// ending early at the last exchange before opts.Now
+func writeGeneratedSession(rng *rand.Rand, opts GenerateOptions, project, model string, start time.Time, n int, result *GenerateResult) error {
+	sessionID := generateUUID(rng)
+	var w bytes.Buffer
+
+	var parent *string
+	at := start
+	cached := 0
+	for i := range n {
+		user := generatedLine{
+			ParentUUID: parent,
+			UserType:   "external",
+			Cwd:        "/Users/dev/" + project,
+			SessionID:  sessionID,
+			Version:    "1.0.0",
+			Type:       "user",
+			Message:    generatedMessage{Role: "user", Content: []generatedText{{Type: "text", Text: fmt.Sprintf("Step %d of the task", i+1)}}},
+			UUID:       generateUUID(rng),
+			Timestamp:  at.UTC().Format("2006-01-02T15:04:05.000Z"),
+		}
+		at = at.Add(time.Duration(5+rng.Intn(85)) * time.Second)
+
+		// Later turns read a larger cached context
+		created := rng.Intn(8000)
+		output := 50 + rng.Intn(1500)
+		usage := &generatedUsage{
+			InputTokens:              3 + rng.Intn(400),
+			CacheCreationInputTokens: created,
+			CacheReadInputTokens:     cached,
+			OutputTokens:             output,
+		}
+		cached += created + output
+		assistant := generatedLine{
+			ParentUUID: &user.UUID,
+			UserType:   "external",
+			Cwd:        user.Cwd,
+			SessionID:  sessionID,
+			Version:    "1.0.0",
+			Type:       "assistant",
+			Message: generatedMessage{
+				ID:      "msg_" + generateID(rng, 24),
+				Type:    "message",
+				Role:    "assistant",
+				Model:   model,
+				Content: []generatedText{{Type: "text", Text: "Done."}},
+				Usage:   usage,
+			},
+			RequestID: "req_" + generateID(rng, 24),
+			UUID:      generateUUID(rng),
+			Timestamp: at.UTC().Format("2006-01-02T15:04:05.000Z"),
+		}
+		if at.After(opts.Now) {
+			break
+		}
+		parent = &assistant.UUID
+		at = at.Add(time.Duration(20+rng.Intn(240)) * time.Second)
+
+		for _, line := range []generatedLine{user, assistant} {
+			data, err := json.Marshal(line)
+			if err != nil {
+				return err
+			}
+			if rng.Float64() < opts.MalformedRate {
+				data = malformLine(rng, data)
+				result.Malformed++
+			} else if line.Type == "assistant" {
+				result.Entries++
+			}
+			w.Write(data)
+			w.WriteByte('\n')
+			result.Lines++
+		}
+	}
+	if w.Len() == 0 {
+		return nil
+	}
+	projectDir := filepath.Join(opts.Dir, "-Users-dev-"+project)
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", projectDir, err)
+	}
+	if err := os.WriteFile(filepath.Join(projectDir, sessionID+".jsonl"), w.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write session log: %w", err)
+	}
+	result.Files++
+	return nil
+}
+
+// malformLine corrupts a line the ways logs get corrupted: cut short by a crash, or overwritten
+func malformLine(rng *rand.Rand, data []byte) []byte {
+	if rng.Intn(2) == 0 {
+		return data[:len(data)/2]
+	}
+	return []byte(`{"type":"assistant","message":` + generateID(rng, 16))
+}
+
+// generateUUID returns a random version 4 UUID drawn from rng
+func generateUUID(rng *rand.Rand) string {
+	var b [16]byte
+	rng.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// generateID returns n random alphanumeric characters drawn from rng
+func generateID(rng *rand.Rand, n int) string {
+	const chars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = chars[rng.Intn(len(chars))]
+	}
+	return string(b)
+}
@@ -0,0 +1,92 @@
+package fileio
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/logging"
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateTestData(t *testing.T) {
+	logging.InitLogger("error", filepath.Join(t.TempDir(), "test.log"), false)
+	end := time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC) // A Sunday
+	opts := GenerateOptions{Dir: t.TempDir(), End: end, Days: 14, Projects: 3, SessionsPerDay: 2, Pattern: PatternWeekdays, Seed: 7}
+	result, err := GenerateTestData(opts)
+	require.NoError(t, err)
+	assert.Positive(t, result.Files)
+	assert.Equal(t, 2*result.Entries, result.Lines)
+	assert.Zero(t, result.Malformed)
+
+	loaded, err := LoadUsageEntries(LoadUsageEntriesOptions{DataPath: opts.Dir, Mode: models.CostModeCalculated, EnableDeduplication: true})
+	require.NoError(t, err)
+	assert.Len(t, loaded.Entries, result.Entries)
+	for _, entry := range loaded.Entries {
+		weekday := entry.Timestamp.Weekday()
+		assert.True(t, weekday != time.Saturday && weekday != time.Sunday, "no usage on weekends")
+		assert.False(t, entry.Timestamp.Before(end.AddDate(0, 0, -13)), "within the requested days")
+		assert.Contains(t, DefaultGenerateModels, entry.Model)
+	}
+
+	// The same seed writes the same files
+	again := opts
+	again.Dir = t.TempDir()
+	_, err = GenerateTestData(again)
+	require.NoError(t, err)
+	files, err := findJSONLFiles(opts.Dir)
+	require.NoError(t, err)
+	for _, file := range files {
+		rel, _ := filepath.Rel(opts.Dir, file)
+		want, _ := os.ReadFile(file)
+		got, err := os.ReadFile(filepath.Join(again.Dir, rel))
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+}
+
+func TestGenerateTestData_NotAfterNow(t *testing.T) {
+	logging.InitLogger("error", filepath.Join(t.TempDir(), "test.log"), false)
+	now := time.Date(2025, 6, 13, 12, 30, 0, 0, time.UTC) // A Friday, mid working day
+	opts := GenerateOptions{Dir: t.TempDir(), End: now, Now: now, Days: 3, Projects: 2, SessionsPerDay: 6, Seed: 3}
+	result, err := GenerateTestData(opts)
+	require.NoError(t, err)
+	assert.Equal(t, 2*result.Entries, result.Lines, "sessions end on a whole exchange")
+
+	loaded, err := LoadUsageEntries(LoadUsageEntriesOptions{DataPath: opts.Dir, Mode: models.CostModeCalculated})
+	require.NoError(t, err)
+	assert.Len(t, loaded.Entries, result.Entries)
+	today := 0
+	for _, entry := range loaded.Entries {
+		assert.False(t, entry.Timestamp.After(now), "no usage after now: %s", entry.Timestamp)
+		if entry.Timestamp.Day() == now.Day() {
+			today++
+		}
+	}
+	assert.Positive(t, today, "the end day keeps the usage before now")
+}
+
+func TestGenerateTestData_Malformed(t *testing.T) {
+	logging.InitLogger("error", filepath.Join(t.TempDir(), "test.log"), false)
+	opts := GenerateOptions{Dir: t.TempDir(), End: time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC), Days: 5, Projects: 1, SessionsPerDay: 3, MalformedRate: 0.2, Seed: 1}
+	result, err := GenerateTestData(opts)
+	require.NoError(t, err)
+	assert.Positive(t, result.Malformed)
+
+	loaded, err := LoadUsageEntries(LoadUsageEntriesOptions{DataPath: opts.Dir, Mode: models.CostModeCalculated})
+	require.NoError(t, err)
+	assert.Len(t, loaded.Entries, result.Entries, "malformed lines are skipped")
+
+	for _, invalid := range []GenerateOptions{
+		{Dir: opts.Dir, Days: 0, Projects: 1, SessionsPerDay: 1},
+		{Dir: opts.Dir, Days: 1, Projects: 9, SessionsPerDay: 1},
+		{Dir: opts.Dir, Days: 1, Projects: 1, SessionsPerDay: 1, Pattern: "random"},
+		{Dir: opts.Dir, Days: 1, Projects: 1, SessionsPerDay: 1, MalformedRate: 2},
+	} {
+		_, err := GenerateTestData(invalid)
+		assert.Error(t, err)
+	}
+}
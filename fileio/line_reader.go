@@ -0,0 +1,191 @@
+package fileio
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+const (
+	// DefaultMaxLineSize is the default maximum size of a single JSONL line (10MB)
+	DefaultMaxLineSize = 10 * 1024 * 1024
+
+	// maxRetainedStringSize is the longest string value kept when compacting an oversized line
+	maxRetainedStringSize = 4 * 1024
+
+	// lineReaderBufferSize is the read buffer size used for streaming lines
+	lineReaderBufferSize = 64 * 1024
+)
+
+// lineReader reads JSONL lines with bounded memory usage.
+// Lines larger than maxLineSize are streamed through a compactor that drops
+// long string values (message content, tool output) while keeping the JSON
+// structure, so usage fields can still be extracted without buffering the line.
+type lineReader struct {
+	reader      *bufio.Reader
+	maxLineSize int
+	lineNumber  int
+}
+
+// lineResult describes a single line returned by the line reader
+type lineResult struct {
+	Data       []byte // Line content, compacted if Oversized
+	Oversized  bool   // Line exceeded the max line size
+	Size       int64  // Original line size in bytes
+	Compacted  bool   // Oversized line was compacted successfully
+	LineNumber int    // 1-based line number
+}
+
+// newLineReader creates a new line reader; maxLineSize <= 0 uses DefaultMaxLineSize
+func newLineReader(r io.Reader, maxLineSize int) *lineReader {
+	if maxLineSize <= 0 {
+		maxLineSize = DefaultMaxLineSize
+	}
+	return &lineReader{
+		reader:      bufio.NewReaderSize(r, lineReaderBufferSize),
+		maxLineSize: maxLineSize,
+	}
+}
+
+// Next returns the next line, or io.EOF when the input is exhausted
+func (lr *lineReader) Next() (*lineResult, error) {
+	var buf []byte
+	var compactor *jsonCompactor
+	var size int64
+
+	for {
+		chunk, err := lr.reader.ReadSlice('\n')
+		size += int64(len(chunk))
+
+		if compactor != nil {
+			compactor.Write(chunk)
+		} else {
+			buf = append(buf, chunk...)
+			if len(buf) > lr.maxLineSize {
+				// Switch to streaming mode and release the line buffer
+				compactor = newJSONCompactor(lr.maxLineSize)
+				compactor.Write(buf)
+				buf = nil
+			}
+		}
+
+		if err == nil {
+			break
+		}
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		if errors.Is(err, io.EOF) && size > 0 {
+			break
+		}
+		return nil, err
+	}
+
+	lr.lineNumber++
+	result := &lineResult{
+		Size:       size,
+		LineNumber: lr.lineNumber,
+	}
+
+	if compactor != nil {
+		result.Oversized = true
+		if data, ok := compactor.Bytes(); ok {
+			result.Data = data
+			result.Compacted = true
+		}
+		return result, nil
+	}
+
+	result.Data = trimLineEnding(buf)
+	return result, nil
+}
+
+// trimLineEnding removes a trailing \n or \r\n
+func trimLineEnding(line []byte) []byte {
+	if n := len(line); n > 0 && line[n-1] == '\n' {
+		line = line[:n-1]
+		if n := len(line); n > 0 && line[n-1] == '\r' {
+			line = line[:n-1]
+		}
+	}
+	return line
+}
+
+// jsonCompactor streams a JSON document and drops string values longer than
+// maxRetainedStringSize, replacing them with empty strings
+type jsonCompactor struct {
+	out      []byte
+	str      []byte
+	limit    int
+	inString bool
+	escaped  bool
+	dropped  bool
+	overflow bool
+}
+
+// newJSONCompactor creates a compactor whose output is limited to limit bytes
+func newJSONCompactor(limit int) *jsonCompactor {
+	return &jsonCompactor{limit: limit}
+}
+
+// Write feeds the next chunk of the document to the compactor
+func (c *jsonCompactor) Write(chunk []byte) {
+	for _, b := range chunk {
+		if c.overflow {
+			return
+		}
+
+		if !c.inString {
+			switch b {
+			case '"':
+				c.inString = true
+				c.dropped = false
+				c.str = c.str[:0]
+			case '\n', '\r':
+				continue
+			}
+			c.emit(b)
+			continue
+		}
+
+		if c.escaped {
+			c.escaped = false
+		} else if b == '\\' {
+			c.escaped = true
+		} else if b == '"' {
+			c.inString = false
+			if !c.dropped {
+				c.emit(c.str...)
+			}
+			c.emit(b)
+			continue
+		}
+
+		if c.dropped {
+			continue
+		}
+		c.str = append(c.str, b)
+		if len(c.str) > maxRetainedStringSize {
+			c.dropped = true
+			c.str = c.str[:0]
+		}
+	}
+}
+
+// emit appends bytes to the output, marking overflow when the limit is exceeded
+func (c *jsonCompactor) emit(b ...byte) {
+	if len(c.out)+len(b) > c.limit {
+		c.overflow = true
+		c.out = nil
+		return
+	}
+	c.out = append(c.out, b...)
+}
+
+// Bytes returns the compacted document and whether compaction succeeded
+func (c *jsonCompactor) Bytes() ([]byte, bool) {
+	if c.overflow || c.inString {
+		return nil, false
+	}
+	return c.out, true
+}
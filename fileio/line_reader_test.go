@@ -0,0 +1,101 @@
+package fileio
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// giantAssistantLine builds an assistant message whose content is contentSize bytes
+func giantAssistantLine(contentSize int, messageID string) string {
+	return `{"type":"assistant","timestamp":"2024-03-15T10:30:00Z","requestId":"req-` + messageID + `",` +
+		`"message":{"id":"` + messageID + `","model":"claude-3-5-sonnet-20241022","role":"assistant",` +
+		`"content":[{"type":"text","text":"` + strings.Repeat("a\\\"b", contentSize/4) + `"}],` +
+		`"usage":{"input_tokens":1000,"output_tokens":500,"cache_creation_input_tokens":200,"cache_read_input_tokens":100}}}`
+}
+
+func TestLineReader_NormalLines(t *testing.T) {
+	reader := newLineReader(strings.NewReader("first\r\nsecond\nthird"), 1024*1024)
+
+	var lines []string
+	for {
+		result, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		assert.False(t, result.Oversized)
+		lines = append(lines, string(result.Data))
+	}
+
+	assert.Equal(t, []string{"first", "second", "third"}, lines)
+}
+
+func TestLineReader_OversizedLineIsCompacted(t *testing.T) {
+	line := giantAssistantLine(2*1024*1024, "msg-1")
+	reader := newLineReader(strings.NewReader(line+"\n"+`{"type":"user"}`+"\n"), 64*1024)
+
+	result, err := reader.Next()
+	require.NoError(t, err)
+	assert.True(t, result.Oversized)
+	assert.True(t, result.Compacted)
+	assert.Equal(t, int64(len(line)+1), result.Size)
+	assert.Less(t, len(result.Data), 64*1024)
+	assert.Contains(t, string(result.Data), `"usage":{"input_tokens":1000`)
+
+	// The following line is unaffected
+	result, err = reader.Next()
+	require.NoError(t, err)
+	assert.False(t, result.Oversized)
+	assert.Equal(t, `{"type":"user"}`, string(result.Data))
+	assert.Equal(t, 2, result.LineNumber)
+
+	_, err = reader.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestLineReader_OversizedStructureIsSkipped(t *testing.T) {
+	// Many short values cannot be compacted below the limit
+	line := "[" + strings.Repeat(`"x",`, 100*1024) + `"x"]`
+	reader := newLineReader(strings.NewReader(line), 64*1024)
+
+	result, err := reader.Next()
+	require.NoError(t, err)
+	assert.True(t, result.Oversized)
+	assert.False(t, result.Compacted)
+	assert.Nil(t, result.Data)
+}
+
+func TestProcessSingleFile_GiantLines(t *testing.T) {
+	tempDir := t.TempDir()
+	filePath := filepath.Join(tempDir, "giant.jsonl")
+
+	content := strings.Join([]string{
+		giantAssistantLine(3*1024*1024, "msg-giant"),
+		`{"type":"assistant","timestamp":"2024-03-15T10:31:00Z","requestId":"req-small","message":{"id":"msg-small","model":"claude-3-5-sonnet-20241022","usage":{"input_tokens":10,"output_tokens":5}}}`,
+		"[" + strings.Repeat(`"x",`, 100*1024) + `"x"]`,
+	}, "\n")
+	require.NoError(t, os.WriteFile(filePath, []byte(content), 0644))
+
+	opts := &LoadUsageEntriesOptions{MaxLineSize: 64 * 1024}
+	entries, _, err := processSingleFileWithDedup(filePath, models.CostModeCalculated, nil, false, nil, opts)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	giant := entries[0]
+	assert.Equal(t, "msg-giant", giant.MessageID)
+	assert.Equal(t, 1000, giant.InputTokens)
+	assert.Equal(t, 500, giant.OutputTokens)
+	assert.Equal(t, 200, giant.CacheCreationTokens)
+	assert.Equal(t, 100, giant.CacheReadTokens)
+	assert.Greater(t, giant.CostUSD, 0.0)
+
+	assert.Equal(t, "msg-small", entries[1].MessageID)
+	assert.True(t, hasAssistantMessages(filePath, 64*1024))
+}
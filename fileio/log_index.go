@@ -0,0 +1,56 @@
+package fileio
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// LogIndex locates the log files of conversations and the directories of projects, for linking to them
+type LogIndex struct {
+	projects map[string]string // Project name to its log directory
+	sessions map[string]string // Conversation session ID to its log file
+}
+
+// IndexLogs indexes the usage files under paths. Claude Code names each log file after the session ID
+// of its conversation; a project name found in several directories maps to the one with the most logs.
+func IndexLogs(paths []string) *LogIndex {
+	index := &LogIndex{projects: make(map[string]string), sessions: make(map[string]string)}
+	logsPerDir := make(map[string]int)
+	for _, path := range paths {
+		files, err := DiscoverFiles(path)
+		if err != nil {
+			continue
+		}
+		for _, file := range files {
+			id := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+			if _, ok := index.sessions[id]; !ok {
+				index.sessions[id] = file
+			}
+			logsPerDir[filepath.Dir(file)]++
+		}
+	}
+	for dir, count := range logsPerDir {
+		project := extractProjectFromPath(filepath.Join(dir, "x.jsonl"))
+		current, ok := index.projects[project]
+		if !ok || count > logsPerDir[current] || (count == logsPerDir[current] && dir < current) {
+			index.projects[project] = dir
+		}
+	}
+	return index
+}
+
+// ProjectDir returns the log directory of project, or "" when it is unknown or the index is nil
+func (i *LogIndex) ProjectDir(project string) string {
+	if i == nil {
+		return ""
+	}
+	return i.projects[project]
+}
+
+// SessionFile returns the log file of the conversation sessionID, or "" when it is unknown or the index is nil
+func (i *LogIndex) SessionFile(sessionID string) string {
+	if i == nil {
+		return ""
+	}
+	return i.sessions[sessionID]
+}
@@ -0,0 +1,28 @@
+package fileio
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndexLogs(t *testing.T) {
+	dataDir := t.TempDir()
+	first := writeSessionLog(t, dataDir, "-Users-me-webapp", "conv-1", 1)
+	writeSessionLog(t, dataDir, "-Users-me-webapp", "conv-2", 1)
+	writeSessionLog(t, dataDir, "-Users-me-Dropbox-webapp", "conv-3", 1)
+	api := writeSessionLog(t, dataDir, "-Users-me-api", "conv-4", 1)
+
+	index := IndexLogs([]string{dataDir, filepath.Join(dataDir, "missing")})
+	assert.Equal(t, first, index.SessionFile("conv-1"))
+	assert.Equal(t, api, index.SessionFile("conv-4"))
+	assert.Empty(t, index.SessionFile("conv-9"))
+	assert.Equal(t, filepath.Dir(first), index.ProjectDir("webapp"), "the directory with the most logs wins")
+	assert.Equal(t, filepath.Dir(api), index.ProjectDir("api"))
+	assert.Empty(t, index.ProjectDir("unknown"))
+
+	var none *LogIndex
+	assert.Empty(t, none.ProjectDir("webapp"))
+	assert.Empty(t, none.SessionFile("conv-1"))
+}
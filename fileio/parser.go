@@ -1,11 +1,12 @@
 package fileio
 
 import (
-	"bufio"
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/bytedance/sonic"
@@ -13,27 +14,31 @@ import (
 )
 
 // hasAssistantMessages checks if a file contains assistant messages
-func hasAssistantMessages(filePath string) bool {
+func hasAssistantMessages(filePath string, maxLineSize int) bool {
 	file, err := os.Open(filePath)
 	if err != nil {
 		return false
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-	lineCount := 0
+	reader := newLineReader(file, maxLineSize)
 
 	// Check first 50 lines for assistant messages
-	for scanner.Scan() && lineCount < 50 {
-		line := scanner.Text()
-		lineCount++
+	for lineCount := 0; lineCount < 50; lineCount++ {
+		result, err := reader.Next()
+		if err != nil {
+			break
+		}
 
-		if strings.TrimSpace(line) == "" {
+		if result.Oversized && !result.Compacted {
+			continue
+		}
+		if len(bytes.TrimSpace(result.Data)) == 0 {
 			continue
 		}
 
 		var data map[string]interface{}
-		if err := sonic.Unmarshal([]byte(line), &data); err != nil {
+		if err := sonic.Unmarshal(result.Data, &data); err != nil {
 			continue
 		}
 
@@ -57,18 +62,59 @@ func hasAssistantMessages(filePath string) bool {
 	return false
 }
 
+// parseCacheCreationTiers reads the cache_creation breakdown into 5-minute and 1-hour cache writes.
+// Older logs only report the total, which is then billed entirely at the 5-minute tier.
+func parseCacheCreationTiers(usage map[string]interface{}, entry *models.UsageEntry) {
+	detail, ok := usage["cache_creation"].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	var tokens5m, tokens1h int
+	if val, ok := detail["ephemeral_5m_input_tokens"].(float64); ok {
+		tokens5m = int(val)
+	}
+	if val, ok := detail["ephemeral_1h_input_tokens"].(float64); ok {
+		tokens1h = int(val)
+	}
+
+	if entry.CacheCreationTokens < tokens5m+tokens1h {
+		entry.CacheCreationTokens = tokens5m + tokens1h
+	}
+	entry.CacheCreation1hTokens = tokens1h
+}
+
+// ProjectOf returns the project entries loaded from the usage file at filePath are attributed to
+func ProjectOf(filePath string) string {
+	return extractProjectFromPath(filePath)
+}
+
+// projectNames caches the project name of each project directory name
+var projectNames sync.Map
+
 // extractProjectFromPath extracts the project name from a Claude projects directory path
 // For example: /Users/user/.claude/projects/-Users-user-Dat-MoviePilot/conversation.jsonl -> MoviePilot
 func extractProjectFromPath(filePath string) string {
-	// Get the directory path
-	dir := filepath.Dir(filePath)
-
 	// Get the project directory name (last component)
-	projectDir := filepath.Base(dir)
+	projectDir := filepath.Base(filepath.Dir(filePath))
+	if name, ok := projectNames.Load(projectDir); ok {
+		return name.(string)
+	}
+	name := projectNameOf(projectDir)
+	projectNames.Store(projectDir, name)
+	return name
+}
 
+// projectNameOf returns the project name of a project directory name
+func projectNameOf(projectDir string) string {
 	// Handle the special format where paths are converted to dashes
 	// Format: -Users-user-path-to-project
 	if strings.HasPrefix(projectDir, "-") {
+		// Names with dashes of their own are only recovered from the directory they encode
+		if dir := resolveEncodedPath(projectDir); dir != "" {
+			return filepath.Base(dir)
+		}
+
 		// Split by dash and get the last meaningful part
 		parts := strings.Split(projectDir, "-")
 		if len(parts) > 0 {
@@ -85,6 +131,66 @@ func extractProjectFromPath(filePath string) string {
 	return projectDir
 }
 
+// encodeProjectPath encodes a path the way Claude Code names project directories, replacing every
+// character other than ASCII letters and digits with a dash
+func encodeProjectPath(path string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '-'
+	}, path)
+}
+
+// resolveEncodedPath finds the absolute directory a project directory name was encoded from by
+// walking down from the root, at each level taking the longest directory whose encoded name
+// matches. It returns "" when the directory does not exist on this machine.
+func resolveEncodedPath(name string) string {
+	dir := string(filepath.Separator)
+	rest := strings.TrimPrefix(name, "-")
+	for rest != "" {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return ""
+		}
+		best, bestEncoded := "", ""
+		for _, entry := range entries {
+			encoded := encodeProjectPath(entry.Name())
+			if len(encoded) <= len(bestEncoded) || (rest != encoded && !strings.HasPrefix(rest, encoded+"-")) {
+				continue
+			}
+			if info, err := os.Stat(filepath.Join(dir, entry.Name())); err == nil && info.IsDir() {
+				best, bestEncoded = entry.Name(), encoded
+			}
+		}
+		if best == "" {
+			return ""
+		}
+		dir = filepath.Join(dir, best)
+		rest = strings.TrimPrefix(strings.TrimPrefix(rest, bestEncoded), "-")
+	}
+	return dir
+}
+
+// extractToolServer returns the server of the first tool_use block in a message's content.
+// MCP tools are named mcp__<server>__<tool>; any other tool is built into Claude Code.
+func extractToolServer(content []interface{}) string {
+	for _, item := range content {
+		block, ok := item.(map[string]interface{})
+		if !ok || block["type"] != "tool_use" {
+			continue
+		}
+		name, _ := block["name"].(string)
+		if server, ok := strings.CutPrefix(name, "mcp__"); ok {
+			if idx := strings.Index(server, "__"); idx > 0 {
+				return server[:idx]
+			}
+		}
+		return models.BuiltinToolServer
+	}
+	return ""
+}
+
 // convertRawToUsageEntry converts raw JSON data to a UsageEntry with cost calculation
 func convertRawToUsageEntry(data map[string]interface{}, mode models.CostMode) (models.UsageEntry, error) {
 	entry, hasUsage := extractUsageEntry(data)
@@ -156,6 +262,11 @@ func extractUsageEntry(data map[string]interface{}) (models.UsageEntry, bool) {
 				entry.MessageID = id
 			}
 
+			// Extract the server of the first tool called
+			if content, ok := message["content"].([]interface{}); ok {
+				entry.ToolServer = extractToolServer(content)
+			}
+
 			// Extract usage
 			if usage, ok := message["usage"].(map[string]interface{}); ok {
 				if val, ok := usage["input_tokens"]; ok {
@@ -172,6 +283,7 @@ func extractUsageEntry(data map[string]interface{}) (models.UsageEntry, bool) {
 				if val, ok := usage["cache_read_input_tokens"]; ok {
 					entry.CacheReadTokens = int(val.(float64))
 				}
+				parseCacheCreationTiers(usage, &entry)
 			}
 		}
 	} else if typeStr == "message" || !hasType {
@@ -195,12 +307,27 @@ func extractUsageEntry(data map[string]interface{}) (models.UsageEntry, bool) {
 			if val, ok := usage["cache_read_tokens"]; ok {
 				entry.CacheReadTokens = int(val.(float64))
 			}
+			parseCacheCreationTiers(usage, &entry)
 		}
 	}
 
-	// Extract request ID (at top level for both message types)
-	if requestID, ok := data["request_id"].(string); ok {
+	// Extract cost recorded by Claude Code, used by cached cost mode and cost audits
+	if cost, ok := data["costUSD"].(float64); ok {
+		entry.CachedCostUSD = cost
+	} else if cost, ok := data["cost_usd"].(float64); ok {
+		entry.CachedCostUSD = cost
+	}
+
+	// Extract request ID (at top level for both message types; Claude Code writes requestId)
+	if requestID, ok := data["requestId"].(string); ok {
 		entry.RequestID = requestID
+	} else if requestID, ok := data["request_id"].(string); ok {
+		entry.RequestID = requestID
+	}
+
+	// Extract the Claude Code conversation the message belongs to
+	if sessionID, ok := data["sessionId"].(string); ok {
+		entry.SessionID = sessionID
 	}
 
 	// Calculate total tokens
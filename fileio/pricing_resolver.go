@@ -0,0 +1,45 @@
+package fileio
+
+import (
+	"context"
+	"sync"
+
+	"github.com/penwyp/claudecat/models"
+)
+
+// pricingResolver memoizes the pricing of each raw model string for the duration of one load, so that
+// the provider's lookup and name normalization run once per model instead of once per entry. It is
+// shared by the loader's workers.
+type pricingResolver struct {
+	provider models.PricingProvider // Nil uses the built-in pricing
+
+	mu      sync.RWMutex
+	pricing map[string]models.ModelPricing
+}
+
+// newPricingResolver creates a resolver over provider, which may be nil
+func newPricingResolver(provider models.PricingProvider) *pricingResolver {
+	return &pricingResolver{provider: provider, pricing: make(map[string]models.ModelPricing)}
+}
+
+// Pricing returns the pricing of model from the provider, falling back to the built-in pricing when
+// the provider fails
+func (r *pricingResolver) Pricing(model string) models.ModelPricing {
+	r.mu.RLock()
+	pricing, ok := r.pricing[model]
+	r.mu.RUnlock()
+	if ok {
+		return pricing
+	}
+
+	pricing = models.GetPricing(model)
+	if r.provider != nil {
+		if resolved, err := r.provider.GetPricing(context.Background(), model); err == nil {
+			pricing = resolved
+		}
+	}
+	r.mu.Lock()
+	r.pricing[model] = pricing
+	r.mu.Unlock()
+	return pricing
+}
@@ -0,0 +1,71 @@
+package fileio
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/penwyp/claudecat/models"
+	"github.com/penwyp/claudecat/models/pricing"
+	"github.com/stretchr/testify/assert"
+)
+
+// countingProvider counts lookups and fails for models without pricing
+type countingProvider struct {
+	models.PricingProvider
+	pricing map[string]models.ModelPricing
+	calls   atomic.Int64
+}
+
+func (p *countingProvider) GetPricing(_ context.Context, model string) (models.ModelPricing, error) {
+	p.calls.Add(1)
+	if pricing, ok := p.pricing[model]; ok {
+		return pricing, nil
+	}
+	return models.ModelPricing{}, errors.New("lookup failed")
+}
+
+func TestPricingResolver(t *testing.T) {
+	custom := models.ModelPricing{Input: 1, Output: 2}
+	provider := &countingProvider{pricing: map[string]models.ModelPricing{"claude-custom": custom}}
+	resolver := newPricingResolver(provider)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				assert.Equal(t, custom, resolver.Pricing("claude-custom"))
+				assert.Equal(t, models.GetPricing(models.ModelOpus), resolver.Pricing(models.ModelOpus), "provider failures fall back to the built-in pricing")
+			}
+		}()
+	}
+	wg.Wait()
+	// Concurrent first lookups may race, but each model is resolved a handful of times at most
+	assert.LessOrEqual(t, provider.calls.Load(), int64(16))
+
+	assert.Equal(t, models.GetPricing(models.ModelHaiku), newPricingResolver(nil).Pricing(models.ModelHaiku))
+}
+
+// benchmarkModels are raw model strings as logged, none of which match a pricing key exactly
+var benchmarkModels = []string{"claude-sonnet-4-20250514", "claude-opus-4-20250514", "claude-3-5-haiku-latest", "<synthetic>"}
+
+func BenchmarkPricingProvider(b *testing.B) {
+	provider := pricing.NewDefaultProvider()
+	ctx := context.Background()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = provider.GetPricing(ctx, benchmarkModels[i%len(benchmarkModels)])
+	}
+}
+
+func BenchmarkPricingResolver(b *testing.B) {
+	resolver := newPricingResolver(pricing.NewDefaultProvider())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		resolver.Pricing(benchmarkModels[i%len(benchmarkModels)])
+	}
+}
@@ -0,0 +1,92 @@
+package fileio
+
+import (
+	"sync"
+	"time"
+)
+
+// ProgressFunc receives loading progress snapshots
+type ProgressFunc func(ProgressSnapshot)
+
+// ProgressSnapshot is a point-in-time view of file loading progress
+type ProgressSnapshot struct {
+	TotalFiles     int           `json:"total_files"`
+	ProcessedFiles int           `json:"processed_files"`
+	CacheHits      int           `json:"cache_hits"`
+	CacheMisses    int           `json:"cache_misses"`
+	Errors         int           `json:"errors"`
+	Elapsed        time.Duration `json:"elapsed"`
+	ETA            time.Duration `json:"eta"`
+}
+
+// Remaining returns the number of files not yet processed
+func (p ProgressSnapshot) Remaining() int {
+	if remaining := p.TotalFiles - p.ProcessedFiles; remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// Percent returns the completion percentage
+func (p ProgressSnapshot) Percent() float64 {
+	if p.TotalFiles == 0 {
+		return 100
+	}
+	return float64(p.ProcessedFiles) / float64(p.TotalFiles) * 100
+}
+
+// Done reports whether all files have been processed
+func (p ProgressSnapshot) Done() bool {
+	return p.ProcessedFiles >= p.TotalFiles
+}
+
+// progressTracker accumulates per-file results and reports snapshots
+type progressTracker struct {
+	mu       sync.Mutex
+	snapshot ProgressSnapshot
+	start    time.Time
+	callback ProgressFunc
+}
+
+// newProgressTracker creates a tracker for totalFiles; callback may be nil
+func newProgressTracker(totalFiles int, callback ProgressFunc) *progressTracker {
+	return &progressTracker{
+		snapshot: ProgressSnapshot{TotalFiles: totalFiles},
+		start:    time.Now(),
+		callback: callback,
+	}
+}
+
+// record registers a processed file and notifies the callback
+func (pt *progressTracker) record(fromCache bool, err error) {
+	if pt == nil {
+		return
+	}
+
+	pt.mu.Lock()
+	pt.snapshot.ProcessedFiles++
+	if err != nil {
+		pt.snapshot.Errors++
+	} else if fromCache {
+		pt.snapshot.CacheHits++
+	} else {
+		pt.snapshot.CacheMisses++
+	}
+	pt.snapshot.Elapsed = time.Since(pt.start)
+	pt.snapshot.ETA = estimateETA(pt.snapshot)
+
+	// Callbacks are serialized so they need no synchronization of their own
+	if pt.callback != nil {
+		pt.callback(pt.snapshot)
+	}
+	pt.mu.Unlock()
+}
+
+// estimateETA extrapolates the remaining duration from the average time per file
+func estimateETA(p ProgressSnapshot) time.Duration {
+	if p.ProcessedFiles == 0 || p.Remaining() == 0 {
+		return 0
+	}
+	perFile := p.Elapsed / time.Duration(p.ProcessedFiles)
+	return perFile * time.Duration(p.Remaining())
+}
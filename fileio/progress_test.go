@@ -0,0 +1,80 @@
+package fileio
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgressSnapshot_Helpers(t *testing.T) {
+	p := ProgressSnapshot{TotalFiles: 4, ProcessedFiles: 1}
+	assert.Equal(t, 3, p.Remaining())
+	assert.Equal(t, 25.0, p.Percent())
+	assert.False(t, p.Done())
+
+	empty := ProgressSnapshot{}
+	assert.True(t, empty.Done())
+	assert.Equal(t, 100.0, empty.Percent())
+}
+
+func TestProgressTracker_Record(t *testing.T) {
+	var snapshots []ProgressSnapshot
+	tracker := newProgressTracker(3, func(p ProgressSnapshot) {
+		snapshots = append(snapshots, p)
+	})
+
+	tracker.record(true, nil)
+	tracker.record(false, nil)
+	tracker.record(false, errors.New("boom"))
+
+	require.Len(t, snapshots, 3)
+	last := snapshots[2]
+	assert.Equal(t, 3, last.ProcessedFiles)
+	assert.Equal(t, 1, last.CacheHits)
+	assert.Equal(t, 1, last.CacheMisses)
+	assert.Equal(t, 1, last.Errors)
+	assert.Equal(t, time.Duration(0), last.ETA)
+	assert.True(t, last.Done())
+
+	// A nil tracker is a no-op
+	var nilTracker *progressTracker
+	nilTracker.record(true, nil)
+}
+
+func TestEstimateETA(t *testing.T) {
+	p := ProgressSnapshot{TotalFiles: 10, ProcessedFiles: 2, Elapsed: 4 * time.Second}
+	assert.Equal(t, 16*time.Second, estimateETA(p))
+}
+
+func TestLoadUsageEntries_ReportsProgress(t *testing.T) {
+	logging.InitLogger("error", filepath.Join(t.TempDir(), "test.log"), false)
+	tempDir := t.TempDir()
+	// More than 10 files exercises the concurrent loader
+	for i := 0; i < 12; i++ {
+		line := fmt.Sprintf(`{"type":"assistant","timestamp":"2024-03-15T10:30:00Z","message":{"id":"msg-%d","model":"claude-3-5-sonnet-20241022","usage":{"input_tokens":10,"output_tokens":5}}}`, i)
+		require.NoError(t, os.WriteFile(filepath.Join(tempDir, fmt.Sprintf("f%d.jsonl", i)), []byte(line+"\n"), 0644))
+	}
+
+	var last ProgressSnapshot
+	calls := 0
+	result, err := LoadUsageEntries(LoadUsageEntriesOptions{
+		DataPath: tempDir,
+		Progress: func(p ProgressSnapshot) {
+			calls++
+			last = p
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Len(t, result.Entries, 12)
+	assert.Equal(t, 12, calls)
+	assert.Equal(t, 12, last.TotalFiles)
+	assert.True(t, last.Done())
+}
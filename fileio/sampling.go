@@ -0,0 +1,90 @@
+package fileio
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+
+	"github.com/penwyp/claudecat/models"
+)
+
+// SamplingStats describes a file sample and the variance of the totals estimated from it.
+// Files are sampled independently with probability Rate (Poisson sampling), so sampled values
+// weighted by 1/Rate give unbiased Horvitz-Thompson estimates of the full totals.
+type SamplingStats struct {
+	Rate          float64 `json:"rate"`
+	FilesTotal    int     `json:"files_total"`
+	FilesSampled  int     `json:"files_sampled"`
+	CostVariance  float64 `json:"cost_variance"`
+	TokenVariance float64 `json:"token_variance"`
+}
+
+// Weight returns the factor applied to sampled values to estimate full totals
+func (s *SamplingStats) Weight() float64 {
+	if s == nil || s.Rate <= 0 {
+		return 1
+	}
+	return 1 / s.Rate
+}
+
+// CostStdErr returns the standard error of the estimated total cost
+func (s *SamplingStats) CostStdErr() float64 {
+	if s == nil {
+		return 0
+	}
+	return math.Sqrt(s.CostVariance)
+}
+
+// TokenStdErr returns the standard error of the estimated total tokens
+func (s *SamplingStats) TokenStdErr() float64 {
+	if s == nil {
+		return 0
+	}
+	return math.Sqrt(s.TokenVariance)
+}
+
+// Merge adds the stats of an independent sample taken at the same rate
+func (s *SamplingStats) Merge(other *SamplingStats) {
+	if other == nil {
+		return
+	}
+	s.Rate = other.Rate
+	s.FilesTotal += other.FilesTotal
+	s.FilesSampled += other.FilesSampled
+	s.CostVariance += other.CostVariance
+	s.TokenVariance += other.TokenVariance
+}
+
+// record adds the variance contribution of one sampled file's entries
+func (s *SamplingStats) record(entries []models.UsageEntry) {
+	if s == nil {
+		return
+	}
+	var cost, tokens float64
+	for _, entry := range entries {
+		cost += entry.CostUSD
+		tokens += float64(entry.TotalTokens)
+	}
+	// Variance estimator for Poisson sampling: sum of (1-p)/p^2 * y_i^2 over sampled files
+	factor := (1 - s.Rate) / (s.Rate * s.Rate)
+	s.CostVariance += factor * cost * cost
+	s.TokenVariance += factor * tokens * tokens
+}
+
+// sampleFiles keeps each file with probability rate. Selection is a deterministic hash of
+// the path, so repeated runs sample the same files and results stay comparable.
+func sampleFiles(files []string, rate float64) []string {
+	if rate >= 1 {
+		return files
+	}
+	// Compare the top 53 bits of the hash so the threshold is exact in float64
+	threshold := uint64(rate * (1 << 53))
+	var sampled []string
+	for _, file := range files {
+		sum := sha256.Sum256([]byte(file))
+		if binary.BigEndian.Uint64(sum[:8])>>11 < threshold {
+			sampled = append(sampled, file)
+		}
+	}
+	return sampled
+}
@@ -0,0 +1,45 @@
+package fileio
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSampleFiles_DeterministicAndProportional(t *testing.T) {
+	files := make([]string, 2000)
+	for i := range files {
+		files[i] = fmt.Sprintf("/data/project-%d/session-%d.jsonl", i%17, i)
+	}
+
+	sampled := sampleFiles(files, 0.1)
+	assert.Equal(t, sampled, sampleFiles(files, 0.1))
+	assert.InDelta(t, 200, len(sampled), 60)
+
+	assert.Len(t, sampleFiles(files, 1), len(files))
+}
+
+func TestSamplingStats_Estimates(t *testing.T) {
+	stats := &SamplingStats{Rate: 0.5}
+	stats.record([]models.UsageEntry{{CostUSD: 1, TotalTokens: 100}, {CostUSD: 1, TotalTokens: 100}})
+	stats.record([]models.UsageEntry{{CostUSD: 4, TotalTokens: 400}})
+
+	assert.Equal(t, 2.0, stats.Weight())
+	// (1-p)/p^2 = 2, so variance = 2*(2^2 + 4^2)
+	assert.InDelta(t, 40.0, stats.CostVariance, 1e-9)
+	assert.InDelta(t, math.Sqrt(40), stats.CostStdErr(), 1e-9)
+	assert.InDelta(t, 2*(200*200+400*400), stats.TokenVariance, 1e-6)
+
+	merged := &SamplingStats{}
+	merged.Merge(&SamplingStats{Rate: 0.5, FilesTotal: 10, FilesSampled: 4, CostVariance: 1})
+	merged.Merge(&SamplingStats{Rate: 0.5, FilesTotal: 6, FilesSampled: 3, CostVariance: 3})
+	assert.Equal(t, 16, merged.FilesTotal)
+	assert.Equal(t, 7, merged.FilesSampled)
+	assert.InDelta(t, 2.0, merged.CostStdErr(), 1e-9)
+
+	var none *SamplingStats
+	assert.Equal(t, 1.0, none.Weight())
+}
@@ -0,0 +1,72 @@
+package fileio
+
+import (
+	"sort"
+	"time"
+
+	"github.com/penwyp/claudecat/cache"
+	"github.com/penwyp/claudecat/models"
+)
+
+// sessionHintDuration matches the 5-hour session window used by the session analyzer
+const sessionHintDuration = 5 * time.Hour
+
+// buildSessionHints detects session boundaries in entries using the session analyzer rules:
+// a session starts at the hour of its first entry and ends after 5 hours or a 5-hour gap
+func buildSessionHints(entries []models.UsageEntry) []cache.SessionHint {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	timestamps := make([]time.Time, len(entries))
+	for i, entry := range entries {
+		timestamps[i] = entry.Timestamp
+	}
+	sort.Slice(timestamps, func(i, j int) bool {
+		return timestamps[i].Before(timestamps[j])
+	})
+
+	var hints []cache.SessionHint
+	var current *cache.SessionHint
+	for _, ts := range timestamps {
+		if current == nil ||
+			!ts.Before(current.StartTime.Add(sessionHintDuration)) ||
+			ts.Sub(current.EndTime) >= sessionHintDuration {
+			hints = append(hints, cache.SessionHint{StartTime: ts.Truncate(time.Hour)})
+			current = &hints[len(hints)-1]
+		}
+		current.EndTime = ts
+		current.EntryCount++
+	}
+
+	return hints
+}
+
+// placeInSession returns a synthetic timestamp for the index-th of count entries in [from, to),
+// keeping it inside the sessions recorded in hints so reconstructed sessions are not split
+func placeInSession(hints []cache.SessionHint, from, to time.Time, index, count int, fallback time.Time) time.Time {
+	type window struct{ start, end time.Time }
+	var windows []window
+	// Stay strictly before the end of the period so entries do not leak into the next bucket
+	last := to.Add(-time.Second)
+	for _, hint := range hints {
+		if start, end, ok := hint.Overlap(from, to); ok {
+			if end.After(last) {
+				end = last
+			}
+			windows = append(windows, window{start, end})
+		}
+	}
+	if len(windows) == 0 || count <= 0 {
+		return fallback
+	}
+
+	// Spread entries round-robin over the overlapping sessions, evenly within each
+	w := windows[index%len(windows)]
+	perWindow := (count + len(windows) - 1) / len(windows)
+	position := index / len(windows)
+	if perWindow <= 1 {
+		return w.start
+	}
+	return w.start.Add(w.end.Sub(w.start) * time.Duration(position) / time.Duration(perWindow-1))
+}
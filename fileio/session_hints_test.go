@@ -0,0 +1,100 @@
+package fileio
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/cache"
+	"github.com/penwyp/claudecat/models"
+	"github.com/penwyp/claudecat/sessions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildSessionHints(t *testing.T) {
+	base := time.Date(2024, 3, 15, 22, 30, 0, 0, time.UTC)
+	entries := []models.UsageEntry{
+		{Timestamp: base.Add(2 * time.Hour)}, // 00:30, after midnight
+		{Timestamp: base},
+		{Timestamp: base.Add(4 * time.Hour)}, // 02:30, still within 22:00-03:00
+		{Timestamp: base.Add(5 * time.Hour)}, // 03:30, starts a new session
+		{Timestamp: base.Add(11 * time.Hour)},
+	}
+
+	hints := buildSessionHints(entries)
+	require.Len(t, hints, 3)
+	assert.Equal(t, time.Date(2024, 3, 15, 22, 0, 0, 0, time.UTC), hints[0].StartTime)
+	assert.Equal(t, base.Add(4*time.Hour), hints[0].EndTime)
+	assert.Equal(t, 3, hints[0].EntryCount)
+	assert.Equal(t, time.Date(2024, 3, 16, 3, 0, 0, 0, time.UTC), hints[1].StartTime)
+	assert.Equal(t, 1, hints[2].EntryCount)
+
+	assert.Nil(t, buildSessionHints(nil))
+}
+
+func TestCreateEntriesFromSummary_StitchesAcrossMidnight(t *testing.T) {
+	start := time.Date(2024, 3, 15, 23, 10, 0, 0, time.UTC)
+	var original []models.UsageEntry
+	for i := 0; i < 6; i++ {
+		original = append(original, models.UsageEntry{
+			Timestamp:   start.Add(time.Duration(i) * 30 * time.Minute),
+			Model:       "claude-3-5-sonnet",
+			InputTokens: 100,
+			TotalTokens: 100,
+			CostUSD:     0.01,
+		})
+	}
+
+	summary := &cache.FileSummary{
+		Path:         "/tmp/project/session.jsonl",
+		SessionHints: buildSessionHints(original),
+		DailyBuckets: map[string]*cache.TemporalBucket{},
+	}
+	// Daily buckets only: 2 entries on the 15th, 4 on the 16th
+	for _, entry := range original {
+		key := entry.Timestamp.Format("2006-01-02")
+		bucket, ok := summary.DailyBuckets[key]
+		if !ok {
+			bucket = &cache.TemporalBucket{Period: key, ModelStats: map[string]*cache.ModelStat{}}
+			summary.DailyBuckets[key] = bucket
+		}
+		stat, ok := bucket.ModelStats[entry.Model]
+		if !ok {
+			stat = &cache.ModelStat{Model: entry.Model}
+			bucket.ModelStats[entry.Model] = stat
+		}
+		stat.EntryCount++
+		stat.InputTokens += entry.InputTokens
+		stat.TotalCost += entry.CostUSD
+	}
+
+	entries := createEntriesFromSummary(summary, nil)
+	require.Len(t, entries, 6)
+	for _, entry := range entries {
+		assert.False(t, entry.Timestamp.Before(summary.SessionHints[0].StartTime))
+		assert.False(t, entry.Timestamp.After(summary.SessionHints[0].EndTime))
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+	blocks := sessions.NewSessionAnalyzer(5).TransformToBlocks(entries)
+	var sessionBlocks int
+	for _, block := range blocks {
+		if !block.IsGap {
+			sessionBlocks++
+		}
+	}
+	assert.Equal(t, 1, sessionBlocks)
+}
+
+func TestPlaceInSession_Fallback(t *testing.T) {
+	from := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	fallback := from.Add(3 * time.Minute)
+	assert.Equal(t, fallback, placeInSession(nil, from, from.Add(time.Hour), 3, 5, fallback))
+
+	hints := []cache.SessionHint{{StartTime: from.Add(-2 * time.Hour), EndTime: from.Add(3 * time.Hour)}}
+	ts := placeInSession(hints, from, from.Add(time.Hour), 4, 5, fallback)
+	assert.True(t, ts.Before(from.Add(time.Hour)), "entries must stay inside the bucket")
+}
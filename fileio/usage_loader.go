@@ -1,13 +1,12 @@
 package fileio
 
 import (
-	"bufio"
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
-	"sort"
-	"strings"
 	"time"
 
 	"github.com/bytedance/sonic"
@@ -30,6 +29,19 @@ type LoadUsageEntriesOptions struct {
 	CacheStore          CacheStore             // Optional cache store for file summaries
 	EnableDeduplication bool                   // Whether to enable deduplication across all files
 	PricingProvider     models.PricingProvider // Optional pricing provider for cost calculations
+	CostModel           models.CostModel       // Billing arrangement of the data path; nil bills list prices
+	MaxLineSize         int                    // Max bytes buffered per line; larger lines are compacted (0 = DefaultMaxLineSize)
+	Progress            ProgressFunc           // Optional callback invoked after each file is processed
+	SampleRate          float64                // Fraction of files to load for approximate analysis (0 or 1 = all files)
+	IncludeSource       bool                   // Record the source file and line of each entry; bypasses the summary cache
+	IncludeTools        bool                   // Keep the tool server of each entry, which summaries do not retain; bypasses the summary cache
+	SeenFiles           map[string]bool        // Session logs, relative to their data path, already loaded from another data path; updated in place
+	Exclude             []string               // data.exclude patterns of directories to skip, see Excluded
+	SkipFiles           map[string]bool        // Files to skip, such as the redundant copies of a DuplicateScan
+	Archives            *cache.ArchiveStore    // Monthly archives replacing the files they cover; used along with CacheStore and not when sampling
+	ModifiedBefore      time.Time              // Skip files modified after this time, for reproducible reports (zero = all files)
+
+	pricing *pricingResolver // Memoized PricingProvider lookups, shared by every file of a load
 }
 
 // CacheStore defines the interface for file summary caching
@@ -56,6 +68,7 @@ type LoadMetadata struct {
 	ProcessingErrors []string               `json:"processing_errors,omitempty"`
 	CacheMissReasons map[string]int         `json:"cache_miss_reasons,omitempty"`
 	CacheStats       *CachePerformanceStats `json:"cache_stats,omitempty"`
+	Sampling         *SamplingStats         `json:"sampling,omitempty"` // Set when only a sample of files was loaded
 }
 
 // CachePerformanceStats tracks cache performance metrics
@@ -69,15 +82,112 @@ type CachePerformanceStats struct {
 	OtherMisses         int     `json:"other_misses"`
 }
 
+// skipSeenFiles drops files whose path relative to dataPath is in seen, such as the same session log
+// synced into several Claude data directories, and records the remaining ones
+func skipSeenFiles(dataPath string, files []string, seen map[string]bool) []string {
+	kept := files[:0]
+	for _, file := range files {
+		rel, err := filepath.Rel(dataPath, file)
+		if err != nil {
+			kept = append(kept, file)
+			continue
+		}
+		if seen[rel] {
+			logging.LogDebugf("Skipping %s, already loaded from another data path", file)
+			continue
+		}
+		seen[rel] = true
+		kept = append(kept, file)
+	}
+	return kept
+}
+
+// skipExcludedFiles drops files in directories matching exclude and the files in skip
+func skipExcludedFiles(dataPath string, files []string, exclude []string, skip map[string]bool) []string {
+	kept := files[:0]
+	for _, file := range files {
+		if skip[file] {
+			logging.LogDebugf("Skipping %s, a copy of a conversation logged in another project directory", file)
+			continue
+		}
+		if Excluded(dataPath, file, exclude) {
+			logging.LogDebugf("Skipping %s, excluded by data.exclude", file)
+			continue
+		}
+		kept = append(kept, file)
+	}
+	return kept
+}
+
+// skipModifiedAfter drops files modified after t
+func skipModifiedAfter(files []string, t time.Time) []string {
+	kept := files[:0]
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil && info.ModTime().After(t) {
+			logging.LogDebugf("Skipping %s, modified after %s", file, t.Format(time.RFC3339))
+			continue
+		}
+		kept = append(kept, file)
+	}
+	return kept
+}
+
 // LoadUsageEntries loads and converts JSONL files to UsageEntry objects
 func LoadUsageEntries(opts LoadUsageEntriesOptions) (*LoadUsageEntriesResult, error) {
 	startTime := time.Now()
+	if opts.pricing == nil {
+		opts.pricing = newPricingResolver(opts.PricingProvider)
+	}
+
+	// Cached summaries do not retain individual lines or tool calls, and hold list-price costs, so these
+	// require reading the files
+	if opts.IncludeSource || opts.IncludeTools || opts.CostModel != nil {
+		opts.CacheStore = nil
+	}
 
 	// Find all JSONL files
 	jsonlFiles, err := findJSONLFiles(opts.DataPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find JSONL files: %w", err)
 	}
+	if len(opts.Exclude) > 0 || len(opts.SkipFiles) > 0 {
+		jsonlFiles = skipExcludedFiles(opts.DataPath, jsonlFiles, opts.Exclude, opts.SkipFiles)
+	}
+	if opts.SeenFiles != nil {
+		jsonlFiles = skipSeenFiles(opts.DataPath, jsonlFiles, opts.SeenFiles)
+	}
+	if !opts.ModifiedBefore.IsZero() {
+		jsonlFiles = skipModifiedAfter(jsonlFiles, opts.ModifiedBefore)
+	}
+
+	// Calculate cutoff time if specified
+	var cutoffTime *time.Time
+	if opts.HoursBack != nil {
+		cutoff := time.Now().UTC().Add(-time.Duration(*opts.HoursBack) * time.Hour)
+		cutoffTime = &cutoff
+	}
+
+	// Files rolled into monthly archives are replaced by the archived usage, which cannot be sampled
+	var archivedEntries []models.UsageEntry
+	var modifiedArchived map[string]time.Time
+	if opts.Archives != nil && opts.CacheStore != nil && (opts.SampleRate <= 0 || opts.SampleRate >= 1) {
+		covered := len(jsonlFiles)
+		jsonlFiles, archivedEntries, modifiedArchived, err = applyArchives(opts.Archives, opts.DataPath, jsonlFiles, cutoffTime)
+		if err != nil {
+			logging.LogWarnf("Ignoring archives of %s: %v", opts.DataPath, err)
+		} else if covered > len(jsonlFiles) {
+			logging.LogInfof("Loaded %d archived entries in place of %d archived files", len(archivedEntries), covered-len(jsonlFiles))
+		}
+	}
+
+	// Load only a sample of files when an approximate analysis was requested
+	var sampling *SamplingStats
+	if opts.SampleRate > 0 && opts.SampleRate < 1 {
+		sampling = &SamplingStats{Rate: opts.SampleRate, FilesTotal: len(jsonlFiles)}
+		jsonlFiles = sampleFiles(jsonlFiles, opts.SampleRate)
+		sampling.FilesSampled = len(jsonlFiles)
+		logging.LogInfof("Sampling %d of %d files (rate %.1f%%)", sampling.FilesSampled, sampling.FilesTotal, opts.SampleRate*100)
+	}
 
 	// Check if we should use concurrent loading
 	useConcurrent := len(jsonlFiles) > 10 // Use concurrent loading for more than 10 files
@@ -128,6 +238,7 @@ func LoadUsageEntries(opts LoadUsageEntriesOptions) (*LoadUsageEntriesResult, er
 		// Calculate cache stats and collect summaries
 		for _, result := range results {
 			if result.Error == nil {
+				sampling.record(result.Entries)
 				if result.FromCache {
 					cacheHits++
 				} else {
@@ -144,19 +255,19 @@ func LoadUsageEntries(opts LoadUsageEntriesOptions) (*LoadUsageEntriesResult, er
 		}
 	} else {
 		// Use sequential loading for small file counts
-		// Calculate cutoff time if specified
-		var cutoffTime *time.Time
-		if opts.HoursBack != nil {
-			cutoff := time.Now().UTC().Add(-time.Duration(*opts.HoursBack) * time.Hour)
-			cutoffTime = &cutoff
+		var progress *progressTracker
+		if opts.Progress != nil {
+			progress = newProgressTracker(len(jsonlFiles), opts.Progress)
 		}
 
+		runs := make([][]models.UsageEntry, 0, len(jsonlFiles))
 		for i, filePath := range jsonlFiles {
 			if i < 5 || i%100 == 0 { // Log first 5 files and every 100th file
 				logging.LogDebugf("Processing file %d/%d: %s", i+1, len(jsonlFiles), filepath.Base(filePath))
 			}
 
 			entries, rawEntries, fromCache, missReason, err, summary := processSingleFileWithCacheAndDedup(filePath, opts, cutoffTime, deduplicationSet)
+			progress.record(fromCache, err)
 			if err != nil {
 				if i < 5 { // Log errors for first 5 files
 					logging.LogErrorf("Error processing file %s: %v", filepath.Base(filePath), err)
@@ -178,7 +289,8 @@ func LoadUsageEntries(opts LoadUsageEntriesOptions) (*LoadUsageEntriesResult, er
 				logging.LogDebugf("File %s processed: %d entries (from cache: %v)", filepath.Base(filePath), len(entries), fromCache)
 			}
 
-			allEntries = append(allEntries, entries...)
+			sampling.record(entries)
+			runs = append(runs, entries)
 			if opts.IncludeRaw && rawEntries != nil {
 				allRawEntries = append(allRawEntries, rawEntries...)
 			}
@@ -188,12 +300,29 @@ func LoadUsageEntries(opts LoadUsageEntriesOptions) (*LoadUsageEntriesResult, er
 				summariesToCache = append(summariesToCache, summary)
 			}
 		}
+
+		// Merge the per-file runs, which are already chronological
+		allEntries = mergeSortedRuns(runs)
 	}
 
-	// Sort entries by timestamp
-	sort.Slice(allEntries, func(i, j int) bool {
-		return allEntries[i].Timestamp.Before(allEntries[j].Timestamp)
-	})
+	// Add the archived usage, and the entries appended to archived files since they were archived
+	if len(archivedEntries) > 0 || len(modifiedArchived) > 0 {
+		runs := [][]models.UsageEntry{allEntries, archivedEntries}
+		for filePath, lastEntry := range modifiedArchived {
+			after := lastEntry
+			if cutoffTime != nil && cutoffTime.After(after) {
+				after = *cutoffTime
+			}
+			entries, rawEntries, err := processSingleFileWithDedup(filePath, opts.Mode, &after, opts.IncludeRaw, deduplicationSet, &opts)
+			if err != nil {
+				processingErrors = append(processingErrors, fmt.Sprintf("%s: %v", filePath, err))
+				continue
+			}
+			runs = append(runs, entries)
+			allRawEntries = append(allRawEntries, rawEntries...)
+		}
+		allEntries = mergeSortedRuns(runs)
+	}
 
 	// Batch write summaries if we have any
 	if len(summariesToCache) > 0 && opts.CacheStore != nil {
@@ -252,6 +381,7 @@ func LoadUsageEntries(opts LoadUsageEntriesOptions) (*LoadUsageEntriesResult, er
 				NoAssistantMessages: cacheMissReasons["no_assistant_messages"],
 				OtherMisses:         cacheMissReasons["other"],
 			},
+			Sampling: sampling,
 		},
 	}
 
@@ -326,7 +456,7 @@ func processSingleFileWithCacheAndDedup(filePath string, opts LoadUsageEntriesOp
 		}
 
 		// Cache miss or expired - now check if file has assistant messages
-		if !hasAssistantMessages(filePath) {
+		if !hasAssistantMessages(filePath, opts.MaxLineSize) {
 			// File has no assistant messages - create empty summary and cache it
 			summary = createEmptySummaryForFile(absPath, filePath)
 			// Return empty results
@@ -378,25 +508,54 @@ func processSingleFileWithDedup(filePath string, mode models.CostMode, cutoffTim
 	var entries []models.UsageEntry
 	var rawEntries []map[string]interface{}
 
-	scanner := bufio.NewScanner(file)
-	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024) // 10MB max line size
+	maxLineSize := 0
+	var provider models.PricingProvider
+	var resolver *pricingResolver
+	var costModel models.CostModel
+	if opts != nil {
+		maxLineSize = opts.MaxLineSize
+		provider, resolver, costModel = opts.PricingProvider, opts.pricing, opts.CostModel
+	}
+	if resolver == nil {
+		resolver = newPricingResolver(provider)
+	}
+	reader := newLineReader(file, maxLineSize)
 
 	lineNumber := 0
 	processedLines := 0
 	skippedLines := 0
+	oversizedLines := 0
 
-	for scanner.Scan() {
-		lineNumber++
-		line := scanner.Text()
+	for {
+		result, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("error reading file: %w", err)
+		}
+		lineNumber = result.LineNumber
+
+		if result.Oversized {
+			oversizedLines++
+			if !result.Compacted {
+				logging.LogWarnf("Skipping oversized line %d in %s (%d bytes exceeds max line size %d)",
+					lineNumber, filepath.Base(filePath), result.Size, reader.maxLineSize)
+				skippedLines++
+				continue
+			}
+			logging.LogWarnf("Line %d in %s is oversized (%d bytes), extracting usage fields only",
+				lineNumber, filepath.Base(filePath), result.Size)
+		}
 
 		// Skip empty lines
-		if strings.TrimSpace(line) == "" {
+		if len(bytes.TrimSpace(result.Data)) == 0 {
 			continue
 		}
 
 		// Parse JSON
 		var data map[string]interface{}
-		if err := sonic.Unmarshal([]byte(line), &data); err != nil {
+		if err := sonic.Unmarshal(result.Data, &data); err != nil {
 			logging.LogDebugf("Skipping invalid JSON at line %d in %s: %v", lineNumber, filepath.Base(filePath), err)
 			skippedLines++
 			continue
@@ -431,18 +590,14 @@ func processSingleFileWithDedup(filePath string, mode models.CostMode, cutoffTim
 		}
 
 		// Calculate cost based on mode
-		if opts != nil && opts.PricingProvider != nil {
-			// Use pricing provider if available
-			pricing, err := opts.PricingProvider.GetPricing(context.Background(), entry.Model)
-			if err != nil {
-				// Fall back to default pricing on error
-				pricing = models.GetPricing(entry.Model)
-			}
-			entry.CostUSD = entry.CalculateCost(pricing)
+		if mode == models.CostModeCached && entry.CachedCostUSD > 0 {
+			// Trust the cost recorded in the log
+			entry.CostUSD = entry.CachedCostUSD
+		} else if costModel != nil {
+			entry.CostUSD = costModel.Cost(entry, resolver.Pricing(entry.Model))
 		} else {
-			// Use default pricing
-			pricing := models.GetPricing(entry.Model)
-			entry.CostUSD = entry.CalculateCost(pricing)
+			// Use the pricing provider if available, memoized per model
+			entry.CostUSD = entry.CalculateCost(resolver.Pricing(entry.Model))
 		}
 
 		// Normalize model name
@@ -451,18 +606,23 @@ func processSingleFileWithDedup(filePath string, mode models.CostMode, cutoffTim
 		// Extract project from file path
 		entry.Project = extractProjectFromPath(filePath)
 
+		if opts != nil && opts.IncludeSource {
+			entry.SourceFile = filePath
+			entry.SourceLine = lineNumber
+		}
+
 		entries = append(entries, entry)
 		processedLines++
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, nil, fmt.Errorf("error reading file: %w", err)
-	}
-
 	if lineNumber > 0 && skippedLines > 0 {
 		logging.LogDebugf("File %s: processed %d/%d lines, skipped %d invalid lines",
 			filepath.Base(filePath), processedLines, lineNumber, skippedLines)
 	}
+	if oversizedLines > 0 {
+		logging.LogWarnf("File %s: %d oversized lines exceeded max line size %d",
+			filepath.Base(filePath), oversizedLines, reader.maxLineSize)
+	}
 
 	return entries, rawEntries, nil
 }
\ No newline at end of file
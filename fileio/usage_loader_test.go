@@ -1,10 +1,15 @@
 package fileio
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/bytedance/sonic"
+	"github.com/penwyp/claudecat/cache"
+	"github.com/penwyp/claudecat/logging"
 	"github.com/penwyp/claudecat/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -56,6 +61,64 @@ func TestConvertRawToUsageEntry_ConversationLogFormat(t *testing.T) {
 	assert.Greater(t, entry.CostUSD, 0.0)
 }
 
+func TestExtractUsageEntry_RequestID(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{"Claude Code requestId", `{"type":"assistant","timestamp":"2024-03-15T10:30:00Z","requestId":"req-1","message":{"id":"msg-1","model":"claude-3-5-sonnet-20241022","usage":{"input_tokens":10,"output_tokens":5}}}`},
+		{"legacy request_id", `{"type":"assistant","timestamp":"2024-03-15T10:30:00Z","request_id":"req-1","message":{"id":"msg-1","model":"claude-3-5-sonnet-20241022","usage":{"input_tokens":10,"output_tokens":5}}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var data map[string]interface{}
+			require.NoError(t, sonic.Unmarshal([]byte(tt.line), &data))
+
+			entry, hasUsage := extractUsageEntry(data)
+			require.True(t, hasUsage)
+			assert.Equal(t, "req-1", entry.RequestID)
+		})
+	}
+}
+
+func TestConvertRawToUsageEntry_CacheCreationTiers(t *testing.T) {
+	jsonData := `{
+		"type": "assistant",
+		"timestamp": "2025-08-15T10:30:00Z",
+		"message": {
+			"id": "msg-789",
+			"model": "claude-sonnet-4-20250514",
+			"role": "assistant",
+			"usage": {
+				"input_tokens": 10,
+				"output_tokens": 20,
+				"cache_creation_input_tokens": 3000,
+				"cache_read_input_tokens": 0,
+				"cache_creation": {
+					"ephemeral_5m_input_tokens": 1000,
+					"ephemeral_1h_input_tokens": 2000
+				}
+			}
+		}
+	}`
+
+	var rawData map[string]interface{}
+	require.NoError(t, sonic.Unmarshal([]byte(jsonData), &rawData))
+
+	entry, err := convertRawToUsageEntry(rawData, models.CostModeCalculated)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3000, entry.CacheCreationTokens)
+	assert.Equal(t, 2000, entry.CacheCreation1hTokens)
+	assert.Equal(t, 1000, entry.CacheCreation5mTokens())
+	assert.Equal(t, 3030, entry.TotalTokens)
+
+	// Without the tier detail every cache write is billed at the 5-minute rate
+	plain := entry
+	plain.CacheCreation1hTokens = 0
+	assert.Greater(t, entry.CostUSD, plain.CalculateCost(models.GetPricing(entry.Model)))
+}
+
 func TestConvertRawToUsageEntry_LegacyFormat(t *testing.T) {
 	// Test data representing legacy format
 	jsonData := `{
@@ -109,3 +172,127 @@ func TestConvertRawToUsageEntry_NonAssistantMessage(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "not an assistant message")
 }
+
+func TestLoadUsageEntries_IncludeSource(t *testing.T) {
+	logging.InitLogger("error", filepath.Join(t.TempDir(), "test.log"), false)
+	dataDir := t.TempDir()
+	projectDir := filepath.Join(dataDir, "-Users-dev-webapp")
+	require.NoError(t, os.MkdirAll(projectDir, 0755))
+
+	filePath := filepath.Join(projectDir, "session.jsonl")
+	content := strings.Join([]string{
+		`{"type":"user","timestamp":"2024-03-15T10:29:00Z","message":{"role":"user","content":"hi"}}`,
+		`{"type":"assistant","timestamp":"2024-03-15T10:30:00Z","requestId":"req-1","message":{"id":"msg-1","model":"claude-3-5-sonnet-20241022","usage":{"input_tokens":10,"output_tokens":5}}}`,
+		``,
+		`{"type":"assistant","timestamp":"2024-03-15T10:31:00Z","requestId":"req-2","message":{"id":"msg-2","model":"claude-3-5-sonnet-20241022","usage":{"input_tokens":20,"output_tokens":5}}}`,
+	}, "\n")
+	require.NoError(t, os.WriteFile(filePath, []byte(content), 0644))
+
+	store, err := cache.NewFileBasedSummaryCache(t.TempDir())
+	require.NoError(t, err)
+	opts := LoadUsageEntriesOptions{DataPath: dataDir, Mode: models.CostModeCalculated, CacheStore: store}
+
+	// Populate the summary cache; provenance is not recorded by default
+	result, err := LoadUsageEntries(opts)
+	require.NoError(t, err)
+	require.Len(t, result.Entries, 2)
+	assert.Empty(t, result.Entries[0].SourceFile)
+	require.True(t, store.HasFileSummary(filePath))
+
+	// Provenance bypasses the cached summary and points at the raw lines
+	opts.IncludeSource = true
+	result, err = LoadUsageEntries(opts)
+	require.NoError(t, err)
+	require.Len(t, result.Entries, 2)
+	for _, entry := range result.Entries {
+		assert.Equal(t, filePath, entry.SourceFile)
+	}
+	lines := []int{result.Entries[0].SourceLine, result.Entries[1].SourceLine}
+	assert.ElementsMatch(t, []int{2, 4}, lines)
+}
+
+func TestLoadUsageEntries_SeenFiles(t *testing.T) {
+	logging.InitLogger("error", filepath.Join(t.TempDir(), "test.log"), false)
+	line := `{"type":"assistant","timestamp":"2024-03-15T10:30:00Z","requestId":"req-1","message":{"id":"msg-1","model":"claude-3-5-sonnet-20241022","usage":{"input_tokens":10,"output_tokens":5}}}`
+
+	// The same session log synced into two data directories
+	var dataDirs []string
+	for range 2 {
+		dataDir := t.TempDir()
+		projectDir := filepath.Join(dataDir, "-Users-dev-webapp")
+		require.NoError(t, os.MkdirAll(projectDir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(projectDir, "session.jsonl"), []byte(line+"\n"), 0644))
+		dataDirs = append(dataDirs, dataDir)
+	}
+
+	seen := make(map[string]bool)
+	var total int
+	for _, dataDir := range dataDirs {
+		result, err := LoadUsageEntries(LoadUsageEntriesOptions{DataPath: dataDir, Mode: models.CostModeCalculated, SeenFiles: seen})
+		require.NoError(t, err)
+		total += len(result.Entries)
+	}
+	assert.Equal(t, 1, total)
+	assert.True(t, seen[filepath.Join("-Users-dev-webapp", "session.jsonl")])
+}
+
+func TestLoadUsageEntries_ModifiedBefore(t *testing.T) {
+	logging.InitLogger("error", filepath.Join(t.TempDir(), "test.log"), false)
+	dataDir := t.TempDir()
+	projectDir := filepath.Join(dataDir, "-Users-dev-webapp")
+	require.NoError(t, os.MkdirAll(projectDir, 0755))
+	asOf := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	for name, modTime := range map[string]time.Time{"old.jsonl": asOf.Add(-time.Hour), "new.jsonl": asOf.Add(time.Hour)} {
+		path := filepath.Join(projectDir, name)
+		line := `{"type":"assistant","timestamp":"2024-03-15T10:30:00Z","message":{"id":"` + name + `","model":"claude-3-5-sonnet-20241022","usage":{"input_tokens":10,"output_tokens":5}}}`
+		require.NoError(t, os.WriteFile(path, []byte(line+"\n"), 0644))
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+
+	result, err := LoadUsageEntries(LoadUsageEntriesOptions{DataPath: dataDir, Mode: models.CostModeCalculated, ModifiedBefore: asOf})
+	require.NoError(t, err)
+	require.Len(t, result.Entries, 1)
+	assert.Equal(t, "old.jsonl", result.Entries[0].MessageID)
+}
+
+func TestExtractUsageEntry_ToolServer(t *testing.T) {
+	line := func(content string) map[string]interface{} {
+		var data map[string]interface{}
+		require.NoError(t, sonic.Unmarshal([]byte(`{"type":"assistant","timestamp":"2025-06-01T10:00:00Z","message":{"model":"claude-sonnet-4-20250514","content":`+content+`,"usage":{"input_tokens":10,"output_tokens":5}}}`), &data))
+		return data
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"mcp tool", `[{"type":"text","text":"Opening the page"},{"type":"tool_use","name":"mcp__playwright__browser_navigate","input":{}}]`, "playwright"},
+		{"server with underscores", `[{"type":"tool_use","name":"mcp__my_db__query","input":{}}]`, "my_db"},
+		{"built-in tool", `[{"type":"tool_use","name":"Bash","input":{}},{"type":"tool_use","name":"mcp__github__create_pr","input":{}}]`, models.BuiltinToolServer},
+		{"no tool", `[{"type":"text","text":"Done"}]`, ""},
+		{"string content", `"Done"`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, ok := extractUsageEntry(line(tt.content))
+			require.True(t, ok)
+			assert.Equal(t, tt.want, entry.ToolServer)
+		})
+	}
+}
+
+func TestExtractProjectFromPath(t *testing.T) {
+	workspace := t.TempDir()
+	project := filepath.Join(workspace, "my-app.v2")
+	require.NoError(t, os.MkdirAll(filepath.Join(project, "src"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(workspace, "my"), 0755))
+	projectsDir := filepath.Join(t.TempDir(), "projects")
+
+	assert.Equal(t, "my-app.v2", extractProjectFromPath(filepath.Join(projectsDir, encodeProjectPath(project), "conv.jsonl")),
+		"dashes of the project name are kept when its directory exists")
+	assert.Equal(t, "src", ProjectOf(filepath.Join(projectsDir, encodeProjectPath(filepath.Join(project, "src")), "conv.jsonl")))
+	assert.Equal(t, "app", extractProjectFromPath(filepath.Join(projectsDir, "-Nowhere-me-my-app", "conv.jsonl")),
+		"the last part is used when the directory is not on this machine")
+	assert.Equal(t, "exported", extractProjectFromPath(filepath.Join(projectsDir, "exported", "conv.jsonl")))
+}
@@ -0,0 +1,220 @@
+// Package humanize formats counts, costs, sizes, durations and relative times for people reading
+// the monitor, CLI tables and reports. Separators follow the locale selected with SetLocale; machine
+// readable output such as CSV and JSON should not use this package.
+package humanize
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Locale holds the separators of formatted numbers
+type Locale struct {
+	Name      string
+	Thousands string // Between groups of three digits
+	Decimal   string // Between the integer and the fraction
+}
+
+// Locales selectable with SetLocale
+var Locales = map[string]Locale{
+	"en": {Name: "en", Thousands: ",", Decimal: "."},
+	"de": {Name: "de", Thousands: ".", Decimal: ","},
+	"fr": {Name: "fr", Thousands: " ", Decimal: ","},
+}
+
+// DefaultLocale is used until SetLocale selects another
+const DefaultLocale = "en"
+
+// current is the locale of formatted numbers
+var current atomic.Pointer[Locale]
+
+func init() {
+	locale := Locales[DefaultLocale]
+	current.Store(&locale)
+}
+
+// LocaleNames lists the names of the selectable locales
+func LocaleNames() []string {
+	names := make([]string, 0, len(Locales))
+	for name := range Locales {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidLocale reports whether name selects a locale; empty selects the default
+func ValidLocale(name string) bool {
+	if name == "" {
+		return true
+	}
+	_, ok := Locales[strings.ToLower(name)]
+	return ok
+}
+
+// SetLocale selects the locale of formatted numbers; empty selects the default
+func SetLocale(name string) error {
+	if name == "" {
+		name = DefaultLocale
+	}
+	locale, ok := Locales[strings.ToLower(name)]
+	if !ok {
+		return fmt.Errorf("invalid locale: %s (valid: %s)", name, strings.Join(LocaleNames(), ", "))
+	}
+	current.Store(&locale)
+	return nil
+}
+
+// CurrentLocale returns the selected locale
+func CurrentLocale() Locale {
+	return *current.Load()
+}
+
+// Count formats n with thousands separators, e.g. 1,234,567
+func Count(n int) string {
+	if n < 0 {
+		return "-" + group(strconv.FormatUint(uint64(-int64(n)), 10))
+	}
+	return group(strconv.Itoa(n))
+}
+
+// Signed formats a change with an explicit sign, e.g. +1,234 or -56
+func Signed(n int) string {
+	if n < 0 {
+		return Count(n)
+	}
+	return "+" + Count(n)
+}
+
+// Cost formats an amount in USD with cents, e.g. $1,234.56
+func Cost(usd float64) string {
+	return CostDecimals(usd, 2)
+}
+
+// CostDecimals formats an amount in USD with the given number of decimals
+func CostDecimals(usd float64, decimals int) string {
+	if math.Round(usd*math.Pow10(decimals)) < 0 {
+		return "-$" + Decimal(-usd, decimals)
+	}
+	return "$" + Decimal(math.Abs(usd), decimals)
+}
+
+// CostDelta formats a signed cost difference, treating sub-cent differences as zero
+func CostDelta(usd float64) string {
+	switch {
+	case usd <= -0.005:
+		return "-" + Cost(-usd)
+	case usd >= 0.005:
+		return "+" + Cost(usd)
+	default:
+		return Cost(0)
+	}
+}
+
+// Decimal formats v with thousands separators and the given number of decimals
+func Decimal(v float64, decimals int) string {
+	text := strconv.FormatFloat(v, 'f', decimals, 64)
+	sign := ""
+	if strings.HasPrefix(text, "-") {
+		sign, text = "-", text[1:]
+	}
+	whole, fraction, _ := strings.Cut(text, ".")
+	if fraction == "" {
+		return sign + group(whole)
+	}
+	return sign + group(whole) + CurrentLocale().Decimal + fraction
+}
+
+// Compact abbreviates n with a K, M or B suffix, e.g. 950, 8.8K, 182K, 1.2M or 3B
+func Compact(n int) string {
+	if n < 0 {
+		return "-" + Compact(-n)
+	}
+	for _, unit := range []struct {
+		size   int
+		suffix string
+	}{{1e9, "B"}, {1e6, "M"}, {1e3, "K"}} {
+		if n >= unit.size {
+			return trimZero(float64(n)/float64(unit.size)) + unit.suffix
+		}
+	}
+	return strconv.Itoa(n)
+}
+
+// trimZero formats v with one decimal below 10 and none above, without a trailing .0
+func trimZero(v float64) string {
+	if v >= 10 {
+		return strconv.FormatFloat(math.Round(v), 'f', 0, 64)
+	}
+	text := strconv.FormatFloat(v, 'f', 1, 64)
+	text = strings.TrimSuffix(text, ".0")
+	return strings.Replace(text, ".", CurrentLocale().Decimal, 1)
+}
+
+// Bytes formats a size with a binary unit, e.g. 512 B or 1.5 MiB
+func Bytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit && exp < 3; m /= unit {
+		div *= unit
+		exp++
+	}
+	return Decimal(float64(n)/float64(div), 1) + " " + string("KMGT"[exp]) + "iB"
+}
+
+// Duration formats d at the precision people read it: 45s, 12m, 2h 15m or 3d 4h
+func Duration(d time.Duration) string {
+	if d < 0 {
+		return "-" + Duration(-d)
+	}
+	if d.Round(time.Second) < time.Minute {
+		return fmt.Sprintf("%ds", int(d.Round(time.Second)/time.Second))
+	}
+	minutes := int(d.Round(time.Minute) / time.Minute)
+	switch {
+	case minutes < 60:
+		return fmt.Sprintf("%dm", minutes)
+	case minutes < 24*60:
+		return fmt.Sprintf("%dh %dm", minutes/60, minutes%60)
+	default:
+		hours := int(d.Round(time.Hour) / time.Hour)
+		return fmt.Sprintf("%dd %dh", hours/24, hours%24)
+	}
+}
+
+// Ago formats t relative to now, e.g. just now, 3m ago or in 2h 5m
+func Ago(t, now time.Time) string {
+	d := now.Sub(t)
+	switch {
+	case d.Abs() < time.Minute:
+		return "just now"
+	case d < 0:
+		return "in " + Duration(-d)
+	default:
+		return Duration(d) + " ago"
+	}
+}
+
+// group inserts the thousands separator of the locale into a string of digits
+func group(digits string) string {
+	if len(digits) <= 3 {
+		return digits
+	}
+	sep := CurrentLocale().Thousands
+	var b strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteString(sep)
+		}
+		b.WriteRune(digit)
+	}
+	return b.String()
+}
@@ -0,0 +1,103 @@
+package humanize
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withLocale selects the named locale for the rest of the test
+func withLocale(t *testing.T, name string) {
+	t.Helper()
+	require.NoError(t, SetLocale(name))
+	t.Cleanup(func() { _ = SetLocale(DefaultLocale) })
+}
+
+func TestCount(t *testing.T) {
+	assert.Equal(t, "0", Count(0))
+	assert.Equal(t, "999", Count(999))
+	assert.Equal(t, "1,000", Count(1000))
+	assert.Equal(t, "1,234,567", Count(1234567))
+	assert.Equal(t, "-12,345", Count(-12345))
+	assert.Equal(t, "+1,234", Signed(1234))
+	assert.Equal(t, "-56", Signed(-56))
+	assert.Equal(t, "+0", Signed(0))
+}
+
+func TestCost(t *testing.T) {
+	assert.Equal(t, "$0.00", Cost(0))
+	assert.Equal(t, "$1,234.56", Cost(1234.555))
+	assert.Equal(t, "-$3.50", Cost(-3.5))
+	assert.Equal(t, "$0.00", Cost(-0.001), "rounds to zero without a sign")
+	assert.Equal(t, "$0.0123", CostDecimals(0.0123, 4))
+
+	assert.Equal(t, "+$1.25", CostDelta(1.25))
+	assert.Equal(t, "-$0.50", CostDelta(-0.5))
+	assert.Equal(t, "$0.00", CostDelta(0.004), "sub-cent differences are zero")
+}
+
+func TestCompact(t *testing.T) {
+	for n, want := range map[int]string{
+		0:             "0",
+		950:           "950",
+		1000:          "1K",
+		8800:          "8.8K",
+		182_400:       "182K",
+		1_200_000:     "1.2M",
+		1_000_000:     "1M",
+		3_000_000_000: "3B",
+		-2500:         "-2.5K",
+	} {
+		assert.Equal(t, want, Compact(n), "%v", n)
+	}
+}
+
+func TestBytes(t *testing.T) {
+	assert.Equal(t, "512 B", Bytes(512))
+	assert.Equal(t, "1.0 KiB", Bytes(1024))
+	assert.Equal(t, "1.5 MiB", Bytes(3*512*1024))
+	assert.Equal(t, "2.0 TiB", Bytes(2<<40))
+	assert.Equal(t, "2,048.0 TiB", Bytes(2<<50), "TiB is the largest unit")
+}
+
+func TestDuration(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		0:                                     "0s",
+		30 * time.Second:                      "30s",
+		59*time.Second + 600*time.Millisecond: "1m",
+		45 * time.Minute:                      "45m",
+		2*time.Hour + 15*time.Minute:          "2h 15m",
+		3 * time.Hour:                         "3h 0m",
+		76*time.Hour + 20*time.Minute:         "3d 4h",
+		-5 * time.Minute:                      "-5m",
+	} {
+		assert.Equal(t, want, Duration(d), "%v", d)
+	}
+}
+
+func TestAgo(t *testing.T) {
+	now := time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, "just now", Ago(now.Add(-20*time.Second), now))
+	assert.Equal(t, "3m ago", Ago(now.Add(-3*time.Minute), now))
+	assert.Equal(t, "2h 5m ago", Ago(now.Add(-125*time.Minute), now))
+	assert.Equal(t, "in 5m", Ago(now.Add(5*time.Minute), now))
+}
+
+func TestLocale(t *testing.T) {
+	withLocale(t, "de")
+	assert.Equal(t, "1.234.567", Count(1234567))
+	assert.Equal(t, "$1.234,56", Cost(1234.56))
+	assert.Equal(t, "1,2M", Compact(1_200_000))
+	assert.Equal(t, "1,5 MiB", Bytes(3*512*1024))
+
+	require.NoError(t, SetLocale("FR"))
+	assert.Equal(t, "1 234,50", Decimal(1234.5, 2))
+
+	assert.Error(t, SetLocale("xx"))
+	assert.Equal(t, "fr", CurrentLocale().Name, "an invalid locale keeps the current one")
+	assert.True(t, ValidLocale(""))
+	assert.False(t, ValidLocale("xx"))
+	assert.Equal(t, []string{"de", "en", "fr"}, LocaleNames())
+}
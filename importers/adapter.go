@@ -0,0 +1,116 @@
+// Package importers converts usage exported by other tools into claudecat usage entries
+package importers
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Record is one row of imported usage, aggregated over a period by most exporters
+type Record struct {
+	Timestamp           time.Time
+	Model               string
+	Project             string
+	SessionID           string
+	InputTokens         int // Uncached input
+	OutputTokens        int
+	CacheCreationTokens int
+	CacheReadTokens     int
+	CostUSD             float64
+	HasCost             bool // The export reported the cost; otherwise it is computed from the model's pricing
+}
+
+// TotalTokens returns the sum of all token types
+func (r Record) TotalTokens() int {
+	return r.InputTokens + r.OutputTokens + r.CacheCreationTokens + r.CacheReadTokens
+}
+
+// Options control how an adapter reads an export
+type Options struct {
+	// Location dates without a time zone are read in; nil means UTC
+	Location *time.Location
+}
+
+// location returns the configured location, UTC when unset
+func (o Options) location() *time.Location {
+	if o.Location == nil {
+		return time.UTC
+	}
+	return o.Location
+}
+
+// Adapter reads the usage export of one tool
+type Adapter interface {
+	// Name is the format name selected with --format
+	Name() string
+	// Description is a one-line summary shown in help and errors
+	Description() string
+	// Detect reports whether sample, the start of an export, looks like this format
+	Detect(sample []byte) bool
+	// Parse reads every usage record of an export
+	Parse(r io.Reader, opts Options) ([]Record, error)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Adapter)
+)
+
+// Register adds an adapter, replacing any registered under the same name
+func Register(adapter Adapter) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[adapter.Name()] = adapter
+}
+
+// Lookup returns the adapter registered under name
+func Lookup(name string) (Adapter, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	if adapter, ok := registry[strings.ToLower(name)]; ok {
+		return adapter, nil
+	}
+	return nil, fmt.Errorf("unknown import format: %s (valid: %s)", name, strings.Join(namesLocked(), ", "))
+}
+
+// Names returns the names of the registered adapters, sorted
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return namesLocked()
+}
+
+// Adapters returns the registered adapters sorted by name
+func Adapters() []Adapter {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	adapters := make([]Adapter, 0, len(registry))
+	for _, name := range namesLocked() {
+		adapters = append(adapters, registry[name])
+	}
+	return adapters
+}
+
+// Detect returns the adapter recognizing sample; adapters are tried in name order
+func Detect(sample []byte) (Adapter, error) {
+	for _, adapter := range Adapters() {
+		if adapter.Detect(sample) {
+			return adapter, nil
+		}
+	}
+	return nil, fmt.Errorf("unrecognized export format; pass --format (valid: %s)", strings.Join(Names(), ", "))
+}
+
+// namesLocked returns the sorted adapter names; callers must hold registryMu
+func namesLocked() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
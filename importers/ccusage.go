@@ -0,0 +1,185 @@
+package importers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// ccusagePeriodKeys are the top-level keys of the ccusage daily, weekly, monthly, session and blocks reports
+var ccusagePeriodKeys = []string{`"daily"`, `"weekly"`, `"monthly"`, `"sessions"`, `"blocks"`, `"projects"`}
+
+// ccusageExport is the JSON written by `ccusage <report> --json`
+type ccusageExport struct {
+	Daily    []ccusagePeriod            `json:"daily"`
+	Weekly   []ccusagePeriod            `json:"weekly"`
+	Monthly  []ccusagePeriod            `json:"monthly"`
+	Sessions []ccusagePeriod            `json:"sessions"`
+	Blocks   []ccusageBlock             `json:"blocks"`
+	Projects map[string][]ccusagePeriod `json:"projects"` // Daily report grouped by project with --instances
+}
+
+// ccusagePeriod is one row of a daily, weekly, monthly or session report
+type ccusagePeriod struct {
+	Date                string             `json:"date"`
+	Week                string             `json:"week"`
+	Month               string             `json:"month"`
+	LastActivity        string             `json:"lastActivity"`
+	SessionID           string             `json:"sessionId"`
+	ProjectPath         string             `json:"projectPath"`
+	InputTokens         int                `json:"inputTokens"`
+	OutputTokens        int                `json:"outputTokens"`
+	CacheCreationTokens int                `json:"cacheCreationTokens"`
+	CacheReadTokens     int                `json:"cacheReadTokens"`
+	TotalCost           float64            `json:"totalCost"`
+	ModelsUsed          []string           `json:"modelsUsed"`
+	ModelBreakdowns     []ccusageBreakdown `json:"modelBreakdowns"`
+}
+
+// ccusageBreakdown is the usage of one model within a period
+type ccusageBreakdown struct {
+	ModelName           string  `json:"modelName"`
+	InputTokens         int     `json:"inputTokens"`
+	OutputTokens        int     `json:"outputTokens"`
+	CacheCreationTokens int     `json:"cacheCreationTokens"`
+	CacheReadTokens     int     `json:"cacheReadTokens"`
+	Cost                float64 `json:"cost"`
+}
+
+// ccusageBlock is one 5-hour billing block of the blocks report
+type ccusageBlock struct {
+	StartTime   time.Time `json:"startTime"`
+	IsGap       bool      `json:"isGap"`
+	TokenCounts struct {
+		InputTokens              int `json:"inputTokens"`
+		OutputTokens             int `json:"outputTokens"`
+		CacheCreationInputTokens int `json:"cacheCreationInputTokens"`
+		CacheReadInputTokens     int `json:"cacheReadInputTokens"`
+	} `json:"tokenCounts"`
+	CostUSD float64  `json:"costUSD"`
+	Models  []string `json:"models"`
+}
+
+// CCUsageAdapter reads the JSON reports of ccusage
+type CCUsageAdapter struct{}
+
+func init() {
+	Register(CCUsageAdapter{})
+}
+
+// Name returns the format name
+func (CCUsageAdapter) Name() string { return "ccusage" }
+
+// Description returns a one-line summary of the format
+func (CCUsageAdapter) Description() string {
+	return "ccusage daily, weekly, monthly, session or blocks report (--json)"
+}
+
+// Detect looks for a ccusage report key alongside camel-cased token counts
+func (CCUsageAdapter) Detect(sample []byte) bool {
+	if !isJSONObject(sample) || !bytes.Contains(sample, []byte(`"inputTokens"`)) {
+		return false
+	}
+	for _, key := range ccusagePeriodKeys {
+		if bytes.Contains(sample, []byte(key)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse reads one record per model and period, or per block; periods are dated at their start
+// in opts.Location
+func (CCUsageAdapter) Parse(r io.Reader, opts Options) ([]Record, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var export ccusageExport
+	if err := json.Unmarshal(bytes.TrimPrefix(data, []byte(utf8BOM)), &export); err != nil {
+		return nil, fmt.Errorf("failed to parse ccusage report: %w", err)
+	}
+
+	var records []Record
+	add := func(periods []ccusagePeriod, project string) error {
+		for i, period := range periods {
+			periodRecords, err := period.records(opts.location(), project)
+			if err != nil {
+				return fmt.Errorf("row %d: %w", i+1, err)
+			}
+			records = append(records, periodRecords...)
+		}
+		return nil
+	}
+	for _, periods := range [][]ccusagePeriod{export.Daily, export.Weekly, export.Monthly, export.Sessions} {
+		if err := add(periods, ""); err != nil {
+			return nil, err
+		}
+	}
+	projects := make([]string, 0, len(export.Projects))
+	for project := range export.Projects {
+		projects = append(projects, project)
+	}
+	sort.Strings(projects)
+	for _, project := range projects {
+		if err := add(export.Projects[project], project); err != nil {
+			return nil, err
+		}
+	}
+	for _, block := range export.Blocks {
+		if block.IsGap {
+			continue
+		}
+		records = append(records, Record{
+			Timestamp:           block.StartTime.UTC(),
+			Model:               singleModel(block.Models),
+			InputTokens:         block.TokenCounts.InputTokens,
+			OutputTokens:        block.TokenCounts.OutputTokens,
+			CacheCreationTokens: block.TokenCounts.CacheCreationInputTokens,
+			CacheReadTokens:     block.TokenCounts.CacheReadInputTokens,
+			CostUSD:             block.CostUSD,
+			HasCost:             true,
+		})
+	}
+	return records, nil
+}
+
+// records returns the per-model records of a period, or a single record when it has no breakdown
+func (p ccusagePeriod) records(loc *time.Location, project string) ([]Record, error) {
+	date := p.Date
+	for _, value := range []string{p.Week, p.Month, p.LastActivity} {
+		if date == "" {
+			date = value
+		}
+	}
+	timestamp, err := parseTime(date, loc)
+	if err != nil {
+		return nil, err
+	}
+	if project == "" {
+		project = p.ProjectPath
+	}
+	base := Record{Timestamp: timestamp, Project: project, SessionID: p.SessionID, HasCost: true}
+
+	if len(p.ModelBreakdowns) == 0 {
+		record := base
+		record.Model = singleModel(p.ModelsUsed)
+		record.InputTokens, record.OutputTokens = p.InputTokens, p.OutputTokens
+		record.CacheCreationTokens, record.CacheReadTokens = p.CacheCreationTokens, p.CacheReadTokens
+		record.CostUSD = p.TotalCost
+		return []Record{record}, nil
+	}
+	records := make([]Record, 0, len(p.ModelBreakdowns))
+	for _, breakdown := range p.ModelBreakdowns {
+		record := base
+		record.Model = breakdown.ModelName
+		record.InputTokens, record.OutputTokens = breakdown.InputTokens, breakdown.OutputTokens
+		record.CacheCreationTokens, record.CacheReadTokens = breakdown.CacheCreationTokens, breakdown.CacheReadTokens
+		record.CostUSD = breakdown.Cost
+		records = append(records, record)
+	}
+	return records, nil
+}
@@ -0,0 +1,87 @@
+package importers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCCUsageAdapter_Daily(t *testing.T) {
+	data := `{
+  "daily": [
+    {
+      "date": "2025-06-01",
+      "inputTokens": 1100, "outputTokens": 600, "cacheCreationTokens": 30, "cacheReadTokens": 400,
+      "totalCost": 1.5,
+      "modelsUsed": ["claude-sonnet-4-20250514", "claude-opus-4-20250514"],
+      "modelBreakdowns": [
+        {"modelName": "claude-sonnet-4-20250514", "inputTokens": 1000, "outputTokens": 500, "cacheCreationTokens": 30, "cacheReadTokens": 400, "cost": 0.5},
+        {"modelName": "claude-opus-4-20250514", "inputTokens": 100, "outputTokens": 100, "cost": 1.0}
+      ]
+    },
+    {"date": "2025-06-02", "inputTokens": 10, "outputTokens": 20, "totalCost": 0.25, "modelsUsed": ["claude-3-5-haiku-20241022"]}
+  ],
+  "totals": {"inputTokens": 1110}
+}`
+	require.True(t, CCUsageAdapter{}.Detect([]byte(data)))
+
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	records, err := CCUsageAdapter{}.Parse(strings.NewReader(data), Options{Location: loc})
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+
+	assert.Equal(t, Record{
+		Timestamp:           time.Date(2025, 6, 1, 0, 0, 0, 0, loc).UTC(),
+		Model:               "claude-sonnet-4-20250514",
+		InputTokens:         1000,
+		OutputTokens:        500,
+		CacheCreationTokens: 30,
+		CacheReadTokens:     400,
+		CostUSD:             0.5,
+		HasCost:             true,
+	}, records[0])
+	assert.Equal(t, "claude-opus-4-20250514", records[1].Model)
+
+	// Periods without a breakdown keep their totals
+	assert.Equal(t, "claude-3-5-haiku-20241022", records[2].Model)
+	assert.Equal(t, 30, records[2].TotalTokens())
+	assert.InDelta(t, 0.25, records[2].CostUSD, 1e-9)
+}
+
+func TestCCUsageAdapter_SessionsProjectsAndBlocks(t *testing.T) {
+	data := `{
+  "sessions": [{"sessionId": "s1", "projectPath": "api", "lastActivity": "2025-06-03", "inputTokens": 1, "outputTokens": 2, "totalCost": 0.1, "modelsUsed": ["a", "b"]}],
+  "monthly": [{"month": "2025-05", "inputTokens": 5, "outputTokens": 5, "totalCost": 0.2, "modelsUsed": ["claude-sonnet-4-20250514"]}],
+  "projects": {"web": [{"date": "2025-06-04", "inputTokens": 3, "outputTokens": 3, "totalCost": 0.3, "modelsUsed": ["claude-sonnet-4-20250514"]}]},
+  "blocks": [
+    {"startTime": "2025-06-05T10:00:00.000Z", "isGap": false, "tokenCounts": {"inputTokens": 7, "outputTokens": 8, "cacheCreationInputTokens": 9, "cacheReadInputTokens": 10}, "costUSD": 0.4, "models": ["claude-opus-4-20250514"]},
+    {"startTime": "2025-06-05T15:00:00.000Z", "isGap": true, "tokenCounts": {"inputTokens": 0}, "costUSD": 0, "models": []}
+  ]
+}`
+	records, err := CCUsageAdapter{}.Parse(strings.NewReader(data), Options{})
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+
+	monthly, session, project, block := records[0], records[1], records[2], records[3]
+	assert.Equal(t, time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC), monthly.Timestamp)
+	assert.Equal(t, "s1", session.SessionID)
+	assert.Equal(t, "api", session.Project)
+	assert.Equal(t, "mixed", session.Model)
+	assert.Equal(t, "web", project.Project)
+	assert.Equal(t, time.Date(2025, 6, 5, 10, 0, 0, 0, time.UTC), block.Timestamp)
+	assert.Equal(t, 34, block.TotalTokens())
+	assert.Equal(t, "claude-opus-4-20250514", block.Model)
+}
+
+func TestCCUsageAdapter_Errors(t *testing.T) {
+	_, err := CCUsageAdapter{}.Parse(strings.NewReader(`{"daily": [`), Options{})
+	assert.ErrorContains(t, err, "failed to parse ccusage report")
+
+	_, err = CCUsageAdapter{}.Parse(strings.NewReader(`{"daily": [{"date": "yesterday"}]}`), Options{})
+	assert.ErrorContains(t, err, "row 1")
+
+	assert.False(t, CCUsageAdapter{}.Detect([]byte(`{"object": "page", "data": []}`)))
+}
@@ -0,0 +1,62 @@
+package importers
+
+import (
+	"sort"
+
+	"github.com/penwyp/claudecat/models"
+)
+
+// Conversion is the result of converting imported records into usage entries
+type Conversion struct {
+	Entries  []models.UsageEntry
+	Unpriced map[string]int // Records without a reported cost whose model has no pricing, by model
+}
+
+// UnpricedModels returns the unpriced models, sorted
+func (c Conversion) UnpricedModels() []string {
+	names := make([]string, 0, len(c.Unpriced))
+	for name := range c.Unpriced {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Convert turns records into usage entries sorted by time. Records keep their reported cost, or are
+// priced with the model mapping picks; records with neither are imported at no cost. project names
+// the records that have none.
+func Convert(records []Record, mapping ModelMap, project string) Conversion {
+	conversion := Conversion{
+		Entries:  make([]models.UsageEntry, 0, len(records)),
+		Unpriced: make(map[string]int),
+	}
+	for _, record := range records {
+		entry := models.UsageEntry{
+			Timestamp:           record.Timestamp,
+			Model:               models.NormalizeModelName(record.Model),
+			InputTokens:         record.InputTokens,
+			OutputTokens:        record.OutputTokens,
+			CacheCreationTokens: record.CacheCreationTokens,
+			CacheReadTokens:     record.CacheReadTokens,
+			TotalTokens:         record.TotalTokens(),
+			CostUSD:             record.CostUSD,
+			SessionID:           record.SessionID,
+			Project:             record.Project,
+		}
+		if entry.Project == "" {
+			entry.Project = project
+		}
+		if !record.HasCost {
+			if model, ok := mapping.PricingModel(record.Model); ok {
+				entry.CostUSD = entry.CalculateCost(models.GetPricing(model))
+			} else if entry.TotalTokens > 0 {
+				conversion.Unpriced[record.Model]++
+			}
+		}
+		conversion.Entries = append(conversion.Entries, entry)
+	}
+	sort.SliceStable(conversion.Entries, func(i, j int) bool {
+		return conversion.Entries[i].Timestamp.Before(conversion.Entries[j].Timestamp)
+	})
+	return conversion
+}
@@ -0,0 +1,105 @@
+package importers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/penwyp/claudecat/models"
+)
+
+// modelFamilies maps the Claude family names accepted as mapping targets to their pricing models
+var modelFamilies = []struct {
+	name  string
+	model string
+}{
+	{"opus", models.ModelOpus},
+	{"sonnet-4", models.ModelSonnet4},
+	{"sonnet", models.ModelSonnet},
+	{"haiku", models.ModelHaiku},
+}
+
+// modelPrefix maps every model starting with prefix
+type modelPrefix struct {
+	prefix string
+	target string
+}
+
+// ModelMap maps the model names of imported usage to the models priced by models.GetPricing.
+// The zero value maps Claude models by family only.
+type ModelMap struct {
+	exact    map[string]string
+	prefixes []modelPrefix // Longest first
+}
+
+// ParseModelMap parses from=to mappings; from is a model name, or a prefix when it ends in '*', and to
+// is a priced model or one of the families opus, sonnet and haiku
+func ParseModelMap(pairs []string) (ModelMap, error) {
+	m := ModelMap{exact: make(map[string]string)}
+	for _, pair := range pairs {
+		from, to, ok := strings.Cut(pair, "=")
+		from, to = strings.ToLower(strings.TrimSpace(from)), strings.TrimSpace(to)
+		if !ok || from == "" || from == "*" || to == "" {
+			return ModelMap{}, fmt.Errorf("invalid model mapping: %q (expected from=to)", pair)
+		}
+		target, err := pricingTarget(to)
+		if err != nil {
+			return ModelMap{}, err
+		}
+		if prefix, ok := strings.CutSuffix(from, "*"); ok {
+			m.prefixes = append(m.prefixes, modelPrefix{prefix: prefix, target: target})
+		} else {
+			m.exact[from] = target
+		}
+	}
+	sort.SliceStable(m.prefixes, func(i, j int) bool {
+		return len(m.prefixes[i].prefix) > len(m.prefixes[j].prefix)
+	})
+	return m, nil
+}
+
+// PricingModel returns the model whose pricing applies to model: an explicit mapping, then the Claude
+// family of the name, then the name itself when it is priced. It reports false for unpriced models.
+func (m ModelMap) PricingModel(model string) (string, bool) {
+	name := strings.ToLower(strings.TrimSpace(model))
+	if target, ok := m.exact[name]; ok {
+		return target, true
+	}
+	for _, p := range m.prefixes {
+		if strings.HasPrefix(name, p.prefix) {
+			return p.target, true
+		}
+	}
+	if _, ok := models.GetAllPricings()[name]; ok {
+		return name, true
+	}
+	for _, family := range modelFamilies {
+		if strings.Contains(name, family.name) {
+			return family.model, true
+		}
+	}
+	return "", false
+}
+
+// pricingTarget resolves a mapping target to a priced model
+func pricingTarget(to string) (string, error) {
+	pricings := models.GetAllPricings()
+	if _, ok := pricings[to]; ok {
+		return to, nil
+	}
+	for _, family := range modelFamilies {
+		if strings.EqualFold(to, family.name) {
+			return family.model, nil
+		}
+	}
+	names := make([]string, 0, len(modelFamilies)+len(pricings))
+	for _, family := range modelFamilies {
+		names = append(names, family.name)
+	}
+	priced := make([]string, 0, len(pricings))
+	for name := range pricings {
+		priced = append(priced, name)
+	}
+	sort.Strings(priced)
+	return "", fmt.Errorf("unknown pricing model: %s (valid: %s)", to, strings.Join(append(names, priced...), ", "))
+}
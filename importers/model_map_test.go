@@ -0,0 +1,99 @@
+package importers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModelMap_PricingModel(t *testing.T) {
+	mapping, err := ParseModelMap([]string{"gpt-4o=sonnet", "gpt-4*=opus", "gpt-4o-mini*=haiku", "o1=" + models.ModelOpus})
+	require.NoError(t, err)
+
+	tests := []struct {
+		model string
+		want  string
+		ok    bool
+	}{
+		{"GPT-4o", models.ModelSonnet, true},
+		{"gpt-4-turbo", models.ModelOpus, true},
+		{"gpt-4o-mini-2024-07-18", models.ModelHaiku, true}, // Longest prefix wins
+		{"o1", models.ModelOpus, true},
+		{"claude-sonnet-4-20250514", models.ModelSonnet4, true},
+		{"claude-3-7-sonnet-20250219", models.ModelSonnet, true},
+		{"claude-sonnet-4-5-20250929", models.ModelSonnet4, true},
+		{models.ModelHaiku, models.ModelHaiku, true},
+		{"gemini-2.5-pro", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := mapping.PricingModel(tt.model)
+		assert.Equal(t, tt.want, got, tt.model)
+		assert.Equal(t, tt.ok, ok, tt.model)
+	}
+
+	got, ok := ModelMap{}.PricingModel("claude-opus-4-20250514")
+	assert.True(t, ok)
+	assert.Equal(t, models.ModelOpus, got)
+}
+
+func TestParseModelMap_Errors(t *testing.T) {
+	for _, pair := range []string{"gpt-4o", "=sonnet", "*=sonnet", "gpt-4o="} {
+		_, err := ParseModelMap([]string{pair})
+		assert.ErrorContains(t, err, "invalid model mapping", pair)
+	}
+	_, err := ParseModelMap([]string{"gpt-4o=gpt-4o"})
+	assert.ErrorContains(t, err, "unknown pricing model: gpt-4o")
+}
+
+func TestConvert(t *testing.T) {
+	day := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	mapping, err := ParseModelMap([]string{"gpt-4o=sonnet"})
+	require.NoError(t, err)
+
+	conversion := Convert([]Record{
+		{Timestamp: day.Add(time.Hour), Model: "claude-sonnet-4-20250514", Project: "api", InputTokens: 10, CostUSD: 2, HasCost: true},
+		{Timestamp: day, Model: "gpt-4o", InputTokens: 1_000_000},
+		{Timestamp: day, Model: "gemini-2.5-pro", OutputTokens: 5},
+		{Timestamp: day, Model: "gemini-2.5-pro", OutputTokens: 5},
+	}, mapping, "openai")
+
+	require.Len(t, conversion.Entries, 4)
+	priced := conversion.Entries[0]
+	assert.Equal(t, "gpt-4o", priced.Model)
+	assert.Equal(t, "openai", priced.Project)
+	assert.InDelta(t, models.GetPricing(models.ModelSonnet).Input, priced.CostUSD, 1e-9)
+	assert.Equal(t, 1_000_000, priced.TotalTokens)
+
+	reported := conversion.Entries[3]
+	assert.Equal(t, "api", reported.Project)
+	assert.InDelta(t, 2, reported.CostUSD, 1e-9)
+
+	assert.Equal(t, 0.0, conversion.Entries[1].CostUSD)
+	assert.Equal(t, map[string]int{"gemini-2.5-pro": 2}, conversion.Unpriced)
+	assert.Equal(t, []string{"gemini-2.5-pro"}, conversion.UnpricedModels())
+}
+
+func TestDetect(t *testing.T) {
+	tests := map[string]string{
+		`{"daily": [{"date": "2025-06-01", "inputTokens": 1}]}`:                 "ccusage",
+		`{"object": "page", "data": [{"object": "bucket", "results": []}]}`:     "openai-json",
+		"date,model,input_tokens,input_cached_tokens,output_tokens\n2025-06-01": "openai-csv",
+	}
+	for sample, want := range tests {
+		adapter, err := Detect([]byte(sample))
+		require.NoError(t, err, sample)
+		assert.Equal(t, want, adapter.Name())
+	}
+
+	_, err := Detect([]byte("hello"))
+	assert.ErrorContains(t, err, "unrecognized export format")
+
+	adapter, err := Lookup("CCUSAGE")
+	require.NoError(t, err)
+	assert.Equal(t, "ccusage", adapter.Name())
+	_, err = Lookup("csv")
+	assert.ErrorContains(t, err, "valid: ccusage, openai-csv, openai-json")
+}
@@ -0,0 +1,229 @@
+package importers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// openAIDefaultModel names usage the OpenAI export did not attribute to a model
+const openAIDefaultModel = "openai"
+
+// openAIPage is one page of the organization usage or costs API
+type openAIPage struct {
+	Object string         `json:"object"`
+	Data   []openAIBucket `json:"data"`
+}
+
+// openAIBucket holds the results of one time bucket
+type openAIBucket struct {
+	StartTime int64          `json:"start_time"`
+	Results   []openAIResult `json:"results"`
+}
+
+// openAIResult is a completions usage result or, when Amount is set, a costs result
+type openAIResult struct {
+	Model             string `json:"model"`
+	ProjectID         string `json:"project_id"`
+	InputTokens       int    `json:"input_tokens"`
+	InputCachedTokens int    `json:"input_cached_tokens"`
+	OutputTokens      int    `json:"output_tokens"`
+	LineItem          string `json:"line_item"`
+	Amount            *struct {
+		Value    float64 `json:"value"`
+		Currency string  `json:"currency"`
+	} `json:"amount"`
+}
+
+// openAIRecord returns the record of a result; cached input is counted as cache reads
+func openAIRecord(timestamp time.Time, model, project string, input, cached, output int) Record {
+	if model == "" {
+		model = openAIDefaultModel
+	}
+	return Record{
+		Timestamp:       timestamp,
+		Model:           model,
+		Project:         project,
+		InputTokens:     max(input-cached, 0),
+		CacheReadTokens: cached,
+		OutputTokens:    output,
+	}
+}
+
+// OpenAIJSONAdapter reads pages of the OpenAI organization usage and costs APIs
+type OpenAIJSONAdapter struct{}
+
+// OpenAICSVAdapter reads usage CSVs exported from the OpenAI dashboard
+type OpenAICSVAdapter struct{}
+
+func init() {
+	Register(OpenAIJSONAdapter{})
+	Register(OpenAICSVAdapter{})
+}
+
+// Name returns the format name
+func (OpenAIJSONAdapter) Name() string { return "openai-json" }
+
+// Description returns a one-line summary of the format
+func (OpenAIJSONAdapter) Description() string {
+	return "OpenAI organization usage (completions) or costs API response, a page or an array of pages"
+}
+
+// Detect looks for the bucket objects of the usage API
+func (OpenAIJSONAdapter) Detect(sample []byte) bool {
+	return isJSONObject(sample) && (bytes.Contains(sample, []byte(`"bucket"`)) || bytes.Contains(sample, []byte(`"organization.`)))
+}
+
+// Parse reads one record per bucket result, dated at the start of the bucket
+func (OpenAIJSONAdapter) Parse(r io.Reader, _ Options) ([]Record, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimSpace(bytes.TrimPrefix(data, []byte(utf8BOM)))
+
+	var pages []openAIPage
+	if len(data) > 0 && data[0] == '[' {
+		err = json.Unmarshal(data, &pages)
+	} else {
+		var page openAIPage
+		err = json.Unmarshal(data, &page)
+		pages = []openAIPage{page}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAI usage: %w", err)
+	}
+
+	var records []Record
+	for _, page := range pages {
+		for _, bucket := range page.Data {
+			timestamp := time.Unix(bucket.StartTime, 0).UTC()
+			for _, result := range bucket.Results {
+				if result.Amount == nil {
+					records = append(records, openAIRecord(timestamp, result.Model, result.ProjectID,
+						result.InputTokens, result.InputCachedTokens, result.OutputTokens))
+					continue
+				}
+				if currency := result.Amount.Currency; currency != "" && !strings.EqualFold(currency, "usd") {
+					return nil, fmt.Errorf("unsupported currency: %s", currency)
+				}
+				record := openAIRecord(timestamp, result.LineItem, result.ProjectID, 0, 0, 0)
+				record.CostUSD, record.HasCost = result.Amount.Value, true
+				records = append(records, record)
+			}
+		}
+	}
+	return records, nil
+}
+
+// openAICSVColumns maps each field of the OpenAI usage export to the header names it has used
+var openAICSVColumns = map[string][]string{
+	"date":    {"start_time_iso", "start_time", "timestamp", "date", "day", "usage_date"},
+	"model":   {"model", "snapshot_id", "model_name"},
+	"project": {"project_name", "project_id", "project"},
+	"input":   {"input_tokens", "n_context_tokens_total", "prompt_tokens", "context_tokens"},
+	"cached":  {"input_cached_tokens", "n_cached_context_tokens_total", "cached_tokens", "cached_input_tokens"},
+	"output":  {"output_tokens", "n_generated_tokens_total", "completion_tokens", "generated_tokens"},
+	"cost":    {"cost_usd", "cost", "amount_usd", "amount_value", "amount"},
+}
+
+// openAICSVMarkers are headers only OpenAI exports use, recognized by Detect
+var openAICSVMarkers = []string{"input_cached_tokens", "num_model_requests", "n_context_tokens_total", "n_generated_tokens_total", "n_requests", "snapshot_id"}
+
+// Name returns the format name
+func (OpenAICSVAdapter) Name() string { return "openai-csv" }
+
+// Description returns a one-line summary of the format
+func (OpenAICSVAdapter) Description() string {
+	return "OpenAI dashboard usage CSV export"
+}
+
+// Detect looks for an OpenAI-specific column in the header
+func (OpenAICSVAdapter) Detect(sample []byte) bool {
+	header, _, _ := bytes.Cut(sample, []byte("\n"))
+	for _, name := range strings.Split(string(header), ",") {
+		name = normalizeHeader(strings.Trim(strings.TrimSpace(name), `"`))
+		for _, marker := range openAICSVMarkers {
+			if name == marker {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Parse reads one record per row; dates without a time zone are read in opts.Location
+func (OpenAICSVAdapter) Parse(r io.Reader, opts Options) ([]Record, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = normalizeHeader(name)
+		for field, aliases := range openAICSVColumns {
+			if _, ok := columns[field]; ok {
+				continue
+			}
+			for _, alias := range aliases {
+				if name == alias {
+					columns[field] = i
+				}
+			}
+		}
+	}
+	if _, ok := columns["date"]; !ok {
+		return nil, fmt.Errorf("CSV has no date column (expected one of: %s)", strings.Join(openAICSVColumns["date"], ", "))
+	}
+	_, hasCost := columns["cost"]
+
+	var records []Record
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+		if field("date") == "" {
+			continue
+		}
+
+		timestamp, err := parseTime(field("date"), opts.location())
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		counts := map[string]int{}
+		for _, name := range []string{"input", "cached", "output"} {
+			if counts[name], err = parseNumber[int](field(name)); err != nil {
+				return nil, fmt.Errorf("line %d: invalid %s: %w", line, name, err)
+			}
+		}
+		record := openAIRecord(timestamp, field("model"), field("project"), counts["input"], counts["cached"], counts["output"])
+		if hasCost && field("cost") != "" {
+			if record.CostUSD, err = parseNumber[float64](field("cost")); err != nil {
+				return nil, fmt.Errorf("line %d: invalid cost: %w", line, err)
+			}
+			record.HasCost = true
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
@@ -0,0 +1,94 @@
+package importers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAIJSONAdapter(t *testing.T) {
+	usage := `{
+  "object": "page",
+  "data": [{
+    "object": "bucket", "start_time": 1748736000, "end_time": 1748822400,
+    "results": [
+      {"object": "organization.usage.completions.result", "input_tokens": 1000, "input_cached_tokens": 400, "output_tokens": 200, "num_model_requests": 3, "project_id": "proj_1", "model": "gpt-4o-2024-08-06"},
+      {"object": "organization.usage.completions.result", "input_tokens": 10, "output_tokens": 5, "model": null}
+    ]
+  }],
+  "has_more": false
+}`
+	require.True(t, OpenAIJSONAdapter{}.Detect([]byte(usage)))
+	records, err := OpenAIJSONAdapter{}.Parse(strings.NewReader(usage), Options{})
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, Record{
+		Timestamp:       time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+		Model:           "gpt-4o-2024-08-06",
+		Project:         "proj_1",
+		InputTokens:     600,
+		CacheReadTokens: 400,
+		OutputTokens:    200,
+	}, records[0])
+	assert.Equal(t, openAIDefaultModel, records[1].Model)
+
+	costs := `[{"object": "page", "data": [{"object": "bucket", "start_time": 1748736000, "results": [
+    {"object": "organization.costs.result", "amount": {"value": 1.25, "currency": "usd"}, "line_item": "gpt-4o, input"}
+  ]}]}]`
+	records, err = OpenAIJSONAdapter{}.Parse(strings.NewReader(costs), Options{})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.True(t, records[0].HasCost)
+	assert.InDelta(t, 1.25, records[0].CostUSD, 1e-9)
+	assert.Equal(t, "gpt-4o, input", records[0].Model)
+
+	_, err = OpenAIJSONAdapter{}.Parse(strings.NewReader(strings.Replace(costs, `"usd"`, `"eur"`, 1)), Options{})
+	assert.ErrorContains(t, err, "unsupported currency")
+}
+
+func TestOpenAICSVAdapter(t *testing.T) {
+	data := "\ufeffstart_time_iso,model,project_name,input_tokens,input_cached_tokens,output_tokens,num_model_requests,cost\n" +
+		"2025-06-01T00:00:00Z,gpt-4o,web,\"1,000\",100,50,2,$0.75\n" +
+		",,,,,,,\n" +
+		"2025-06-02,gpt-4o-mini,,10,0,5,1,\n"
+	require.True(t, OpenAICSVAdapter{}.Detect([]byte(data)))
+
+	loc := time.FixedZone("UTC-5", -5*60*60)
+	records, err := OpenAICSVAdapter{}.Parse(strings.NewReader(data), Options{Location: loc})
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, Record{
+		Timestamp:       time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+		Model:           "gpt-4o",
+		Project:         "web",
+		InputTokens:     900,
+		CacheReadTokens: 100,
+		OutputTokens:    50,
+		CostUSD:         0.75,
+		HasCost:         true,
+	}, records[0])
+
+	// Local dates are read in the configured location; an empty cost is computed later
+	assert.Equal(t, time.Date(2025, 6, 2, 5, 0, 0, 0, time.UTC), records[1].Timestamp)
+	assert.False(t, records[1].HasCost)
+
+	unix := "timestamp,snapshot_id,n_context_tokens_total,n_generated_tokens_total\n1748736000,gpt-4,5,6\n"
+	records, err = OpenAICSVAdapter{}.Parse(strings.NewReader(unix), Options{})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), records[0].Timestamp)
+	assert.Equal(t, 11, records[0].TotalTokens())
+}
+
+func TestOpenAICSVAdapter_Errors(t *testing.T) {
+	_, err := OpenAICSVAdapter{}.Parse(strings.NewReader("model,input_tokens\ngpt-4o,1\n"), Options{})
+	assert.ErrorContains(t, err, "no date column")
+
+	_, err = OpenAICSVAdapter{}.Parse(strings.NewReader("date,model,output_tokens\n2025-06-01,gpt-4o,many\n"), Options{})
+	assert.ErrorContains(t, err, "line 2: invalid output")
+
+	assert.False(t, OpenAICSVAdapter{}.Detect([]byte("date,model,input_tokens,output_tokens\n")))
+}
@@ -0,0 +1,66 @@
+package importers
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// utf8BOM is the byte order mark some tools write at the start of an export
+const utf8BOM = "\ufeff"
+
+// localTimeFormats are the layouts of dates exported without a time zone, read in Options.Location
+var localTimeFormats = []string{"2006-01-02", "2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01", "01/02/2006"}
+
+// parseTime parses a Unix timestamp in seconds, an RFC 3339 time or a local date
+func parseTime(value string, loc *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	for _, format := range localTimeFormats {
+		if t, err := time.ParseInLocation(format, value, loc); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unable to parse date: %q", value)
+}
+
+// parseNumber parses a count or amount, ignoring currency signs and thousands separators
+func parseNumber[T int | float64](value string) (T, error) {
+	value = strings.NewReplacer(",", "", "$", "").Replace(strings.TrimSpace(value))
+	if value == "" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	return T(f), nil
+}
+
+// normalizeHeader lowercases a CSV header and joins its words with underscores
+func normalizeHeader(name string) string {
+	name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, utf8BOM)))
+	name = strings.NewReplacer("(", "", ")", "", "-", "_").Replace(name)
+	return strings.Join(strings.Fields(name), "_")
+}
+
+// isJSONObject reports whether sample starts with a JSON object or array
+func isJSONObject(sample []byte) bool {
+	sample = bytes.TrimSpace(bytes.TrimPrefix(sample, []byte(utf8BOM)))
+	return len(sample) > 0 && (sample[0] == '{' || sample[0] == '[')
+}
+
+// singleModel returns the only model of a period, or "mixed" when the export did not break it down
+func singleModel(models []string) string {
+	if len(models) == 1 {
+		return models[0]
+	}
+	return "mixed"
+}
@@ -0,0 +1,124 @@
+package internal
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/models"
+)
+
+// AbsenceMonitor detects stretches of work hours without any usage entries.
+// A silent day usually means Claude's log location changed and data is no longer being collected.
+type AbsenceMonitor struct {
+	after     time.Duration
+	workStart time.Duration
+	workEnd   time.Duration
+	workDays  map[time.Weekday]bool
+	loc       *time.Location
+
+	since    time.Time // Start of observation; absence before the monitor ran is ignored
+	lastSeen time.Time
+	alerted  bool
+}
+
+// NewAbsenceMonitor creates an absence monitor observing from now
+func NewAbsenceMonitor(cfg config.AbsenceAlertConfig, loc *time.Location, now time.Time) (*AbsenceMonitor, error) {
+	start, err := config.ParseClockTime(cfg.WorkStart)
+	if err != nil {
+		return nil, err
+	}
+	end, err := config.ParseClockTime(cfg.WorkEnd)
+	if err != nil {
+		return nil, err
+	}
+	if end <= start {
+		return nil, fmt.Errorf("work end %s must be after work start %s", cfg.WorkEnd, cfg.WorkStart)
+	}
+
+	days := make(map[time.Weekday]bool)
+	for _, name := range cfg.WorkDays {
+		day, err := config.ParseWeekday(name)
+		if err != nil {
+			return nil, err
+		}
+		days[day] = true
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+
+	return &AbsenceMonitor{
+		after:     cfg.After,
+		workStart: start,
+		workEnd:   end,
+		workDays:  days,
+		loc:       loc,
+		since:     now,
+	}, nil
+}
+
+// Observe records the latest entry time and reports the quiet work time when an alert should fire.
+// It fires once per quiet stretch and re-arms as soon as a new entry appears.
+func (am *AbsenceMonitor) Observe(lastEntry, now time.Time) (time.Duration, bool) {
+	if lastEntry.After(am.lastSeen) {
+		am.lastSeen = lastEntry
+		am.alerted = false
+	}
+
+	from := am.since
+	if am.lastSeen.After(from) {
+		from = am.lastSeen
+	}
+
+	quiet := am.workTimeBetween(from, now)
+	if quiet < am.after || am.alerted {
+		return quiet, false
+	}
+	am.alerted = true
+	return quiet, true
+}
+
+// workTimeBetween returns how much of (from, to] falls inside configured work hours
+func (am *AbsenceMonitor) workTimeBetween(from, to time.Time) time.Duration {
+	if !to.After(from) {
+		return 0
+	}
+
+	var total time.Duration
+	from, to = from.In(am.loc), to.In(am.loc)
+	day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, am.loc)
+	for !day.After(to) {
+		if am.workDays[day.Weekday()] {
+			start := day.Add(am.workStart)
+			end := day.Add(am.workEnd)
+			if start.Before(from) {
+				start = from
+			}
+			if end.After(to) {
+				end = to
+			}
+			if end.After(start) {
+				total += end.Sub(start)
+			}
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return total
+}
+
+// latestEntryTime returns the timestamp of the newest entry across blocks
+func latestEntryTime(blocks []models.SessionBlock) time.Time {
+	var latest time.Time
+	for _, block := range blocks {
+		if block.IsGap {
+			continue
+		}
+		for _, entry := range block.Entries {
+			if entry.Timestamp.After(latest) {
+				latest = entry.Timestamp
+			}
+		}
+	}
+	return latest
+}
@@ -0,0 +1,71 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func absenceConfig() config.AbsenceAlertConfig {
+	return config.AbsenceAlertConfig{
+		Enabled:   true,
+		After:     3 * time.Hour,
+		WorkStart: "09:00",
+		WorkEnd:   "18:00",
+		WorkDays:  []string{"mon", "tue", "wed", "thu", "fri"},
+	}
+}
+
+func TestAbsenceMonitor_FiresOncePerQuietStretch(t *testing.T) {
+	// Monday 2024-03-11
+	start := time.Date(2024, 3, 11, 8, 0, 0, 0, time.UTC)
+	monitor, err := NewAbsenceMonitor(absenceConfig(), time.UTC, start)
+	require.NoError(t, err)
+
+	lastEntry := time.Date(2024, 3, 11, 9, 30, 0, 0, time.UTC)
+
+	// 2.5 hours of quiet work time is below the threshold
+	_, fire := monitor.Observe(lastEntry, time.Date(2024, 3, 11, 12, 0, 0, 0, time.UTC))
+	assert.False(t, fire)
+
+	quiet, fire := monitor.Observe(lastEntry, time.Date(2024, 3, 11, 12, 45, 0, 0, time.UTC))
+	assert.True(t, fire)
+	assert.Equal(t, 3*time.Hour+15*time.Minute, quiet)
+
+	// Does not repeat for the same stretch
+	_, fire = monitor.Observe(lastEntry, time.Date(2024, 3, 11, 15, 0, 0, 0, time.UTC))
+	assert.False(t, fire)
+
+	// A new entry re-arms the alert
+	newEntry := time.Date(2024, 3, 11, 15, 0, 0, 0, time.UTC)
+	_, fire = monitor.Observe(newEntry, time.Date(2024, 3, 11, 16, 0, 0, 0, time.UTC))
+	assert.False(t, fire)
+	_, fire = monitor.Observe(newEntry, time.Date(2024, 3, 12, 10, 0, 0, 0, time.UTC))
+	assert.True(t, fire)
+}
+
+func TestAbsenceMonitor_IgnoresOffHours(t *testing.T) {
+	// Friday evening through Monday morning
+	start := time.Date(2024, 3, 15, 17, 0, 0, 0, time.UTC)
+	monitor, err := NewAbsenceMonitor(absenceConfig(), time.UTC, start)
+	require.NoError(t, err)
+
+	quiet, fire := monitor.Observe(time.Time{}, time.Date(2024, 3, 18, 10, 0, 0, 0, time.UTC))
+	assert.False(t, fire)
+	assert.Equal(t, 2*time.Hour, quiet)
+}
+
+func TestNewAbsenceMonitor_InvalidConfig(t *testing.T) {
+	cfg := absenceConfig()
+	cfg.WorkEnd = "08:00"
+	_, err := NewAbsenceMonitor(cfg, time.UTC, time.Now())
+	assert.Error(t, err)
+
+	cfg = absenceConfig()
+	cfg.WorkDays = []string{"funday"}
+	_, err = NewAbsenceMonitor(cfg, time.UTC, time.Now())
+	assert.Error(t, err)
+}
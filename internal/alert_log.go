@@ -0,0 +1,149 @@
+package internal
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/penwyp/claudecat/cache"
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/events"
+	"github.com/penwyp/claudecat/models"
+)
+
+const (
+	// maxAlertLogFileSize triggers trimming of the alert log once exceeded
+	maxAlertLogFileSize = 1024 * 1024
+	// alertLogKeepRecords is the number of most recent alerts kept when trimming
+	alertLogKeepRecords = 2000
+)
+
+// Metrics recorded in the alert log
+const (
+	AlertMetricSessionCost  = "session_cost"
+	AlertMetricLimitMessage = "limit_message"
+	AlertMetricBurnRate     = "burn_rate"
+	AlertMetricAbsence      = "absence_hours"
+	AlertMetricMessageInput = "message_input_tokens"
+)
+
+// AlertRecord is a single alert or threshold crossing
+type AlertRecord struct {
+	Time      time.Time `json:"time"`
+	Level     string    `json:"level"`
+	Metric    string    `json:"metric"`
+	Value     float64   `json:"value"`
+	Threshold float64   `json:"threshold,omitempty"`
+	SessionID string    `json:"session_id,omitempty"`
+	Message   string    `json:"message"`
+}
+
+// NewAlertRecord builds the record of a notice caused by a threshold crossing
+func NewAlertRecord(notice events.Notice, at time.Time) (AlertRecord, bool) {
+	if notice.Crossing == nil {
+		return AlertRecord{}, false
+	}
+	return AlertRecord{
+		Time:      at,
+		Level:     notice.Level.String(),
+		Metric:    notice.Crossing.Metric,
+		Value:     notice.Crossing.Value,
+		Threshold: notice.Crossing.Threshold,
+		SessionID: notice.Crossing.SessionID,
+		Message:   notice.Message,
+	}, true
+}
+
+// activeSessionID returns the ID of the active session in blocks, or "" when none is active
+func activeSessionID(blocks []models.SessionBlock) string {
+	for _, block := range blocks {
+		if block.IsActive && !block.IsGap {
+			return block.ID
+		}
+	}
+	return ""
+}
+
+// AlertLog is an append-only JSONL log of alerts raised by the monitor
+type AlertLog struct {
+	jsonlLog[AlertRecord]
+}
+
+// NewAlertLog creates an alert log stored at path
+func NewAlertLog(path string) *AlertLog {
+	return newAlertLog(path, nil)
+}
+
+// newAlertLog creates an alert log stored at path whose records are encrypted with enc when set
+func newAlertLog(path string, enc *cache.Encryptor) *AlertLog {
+	return &AlertLog{jsonlLog[AlertRecord]{path: path, name: "alert", maxSize: maxAlertLogFileSize, keep: alertLogKeepRecords, enc: enc}}
+}
+
+// OpenAlertLog opens the default alert log, encrypted according to cache.encryption like the
+// command history. Alerts recorded before encryption was enabled are encrypted on open.
+func OpenAlertLog(cfg *config.Config) (*AlertLog, error) {
+	enc, err := cacheEncryptor(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load alert log encryption key: %w", err)
+	}
+
+	a := newAlertLog(DefaultAlertLogPath(cfg), enc)
+	if enc != nil {
+		if err := a.sealPlaintext(); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+// DefaultAlertLogPath returns the default location of the alert log, under cache.dir
+func DefaultAlertLogPath(cfg *config.Config) string {
+	return filepath.Join(cacheDirPath(cfg), "alerts.jsonl")
+}
+
+// RedactBefore removes IDs from the session IDs and messages of records older than cutoff.
+// Metrics, values and thresholds are kept.
+func (a *AlertLog) RedactBefore(cutoff time.Time) (int, error) {
+	if cutoff.IsZero() {
+		return 0, nil
+	}
+
+	return a.redactRecords(func(record *AlertRecord) bool {
+		if !record.Time.Before(cutoff) {
+			return false
+		}
+		sessionID, message := models.RedactIDs(record.SessionID), models.RedactIDs(record.Message)
+		changed := sessionID != record.SessionID || message != record.Message
+		record.SessionID, record.Message = sessionID, message
+		return changed
+	})
+}
+
+// AlertFilter selects alert records for listing
+type AlertFilter struct {
+	Since     time.Time // Zero includes all records
+	Until     time.Time // Zero includes all records
+	Metric    string
+	SessionID string
+}
+
+// FilterAlerts returns the records matching filter, keeping their order
+func FilterAlerts(records []AlertRecord, filter AlertFilter) []AlertRecord {
+	var filtered []AlertRecord
+	for _, record := range records {
+		if !filter.Since.IsZero() && record.Time.Before(filter.Since) {
+			continue
+		}
+		if !filter.Until.IsZero() && !record.Time.Before(filter.Until) {
+			continue
+		}
+		if filter.Metric != "" && record.Metric != filter.Metric {
+			continue
+		}
+		if filter.SessionID != "" && record.SessionID != filter.SessionID {
+			continue
+		}
+		filtered = append(filtered, record)
+	}
+	return filtered
+}
@@ -0,0 +1,110 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/cache"
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertLog_AppendRead(t *testing.T) {
+	log := NewAlertLog(filepath.Join(t.TempDir(), "nested", "alerts.jsonl"))
+
+	records, err := log.Read()
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	at := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	notice := events.Notice{
+		Level:    events.NoticeError,
+		Message:  "Session at 95% of the $18.00 cost limit",
+		Crossing: &events.Crossing{Metric: AlertMetricSessionCost, Value: 17.2, Threshold: 17.1, SessionID: "s1"},
+	}
+	record, ok := NewAlertRecord(notice, at)
+	require.True(t, ok)
+	require.NoError(t, log.Append(record))
+	require.NoError(t, log.Append(AlertRecord{Time: at.Add(time.Hour), Level: "warning", Metric: AlertMetricBurnRate, Value: 900}))
+
+	info, err := os.Stat(log.Path())
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	records, err = log.Read()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "error", records[0].Level)
+	assert.Equal(t, AlertMetricSessionCost, records[0].Metric)
+	assert.InDelta(t, 17.1, records[0].Threshold, 0.0001)
+	assert.Equal(t, "s1", records[0].SessionID)
+	assert.Equal(t, at, records[0].Time)
+
+	// Notices without a crossing are not alerts
+	_, ok = NewAlertRecord(events.Notice{Message: "info"}, at)
+	assert.False(t, ok)
+}
+
+func TestAlertLog_Trim(t *testing.T) {
+	log := NewAlertLog(filepath.Join(t.TempDir(), "alerts.jsonl"))
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		require.NoError(t, log.Append(AlertRecord{Time: start.Add(time.Duration(i) * time.Minute), Metric: AlertMetricBurnRate}))
+	}
+
+	require.NoError(t, log.trim(2))
+	records, err := log.Read()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, start.Add(3*time.Minute), records[0].Time)
+}
+
+func TestOpenAlertLog_Encrypted(t *testing.T) {
+	t.Setenv(cache.PassphraseEnv, "correct horse battery staple")
+	cfg := config.DefaultConfig()
+	cfg.Cache.Dir = t.TempDir()
+	at := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	// Alerts recorded before encryption was enabled are encrypted on open
+	plain := NewAlertLog(DefaultAlertLogPath(cfg))
+	require.NoError(t, plain.Append(AlertRecord{Time: at, Metric: AlertMetricSessionCost, SessionID: "secret-session"}))
+
+	cfg.Cache.Encryption = cache.EncryptionPassphrase
+	log, err := OpenAlertLog(cfg)
+	require.NoError(t, err)
+	require.NoError(t, log.Append(AlertRecord{Time: at.Add(time.Hour), Metric: AlertMetricBurnRate}))
+
+	data, err := os.ReadFile(log.Path())
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "secret-session")
+
+	records, err := log.Read()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "secret-session", records[0].SessionID)
+
+	records, err = plain.Read()
+	require.NoError(t, err)
+	assert.Empty(t, records, "encrypted alerts are skipped without the key")
+}
+
+func TestFilterAlerts(t *testing.T) {
+	at := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	records := []AlertRecord{
+		{Time: at, Metric: AlertMetricSessionCost, SessionID: "s1"},
+		{Time: at.Add(time.Hour), Metric: AlertMetricBurnRate, SessionID: "s1"},
+		{Time: at.Add(2 * time.Hour), Metric: AlertMetricSessionCost, SessionID: "s2"},
+	}
+
+	assert.Len(t, FilterAlerts(records, AlertFilter{}), 3)
+	assert.Len(t, FilterAlerts(records, AlertFilter{Metric: AlertMetricSessionCost}), 2)
+	assert.Len(t, FilterAlerts(records, AlertFilter{SessionID: "s1"}), 2)
+
+	window := FilterAlerts(records, AlertFilter{Since: at.Add(time.Hour), Until: at.Add(2 * time.Hour)})
+	require.Len(t, window, 1)
+	assert.Equal(t, AlertMetricBurnRate, window[0].Metric)
+}
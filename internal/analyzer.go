@@ -1,23 +1,59 @@
 package internal
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
-	"os"
+	"math"
 	"path/filepath"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/penwyp/claudecat/cache"
+	"github.com/penwyp/claudecat/calculations"
 	"github.com/penwyp/claudecat/config"
 	"github.com/penwyp/claudecat/fileio"
 	"github.com/penwyp/claudecat/logging"
 	"github.com/penwyp/claudecat/models"
 	"github.com/penwyp/claudecat/models/pricing"
+	"github.com/penwyp/claudecat/sessions"
 )
 
 // Analyzer provides data analysis functionality
 type Analyzer struct {
 	config *config.Config
+
+	// Approximate analysis over a sample of files
+	sampleRate float64
+	sampling   *fileio.SamplingStats
+
+	// Record the log file and line of each result
+	includeSource bool
+	includeTools  bool
+
+	// Messages over the message-size guardrail found by the last Analyze call
+	guardrailHits []calculations.GuardrailHit
+
+	// Project directories holding copies of conversations logged in another, found by the last load
+	duplicates []fileio.DuplicateProject
+
+	// Usage imported from other tools, opened from the default store when nil
+	imports     *ImportStore
+	skipImports bool
+
+	// Session tags, opened from the default store when nil
+	tags *TagStore
+
+	// Reproducible reports: files modified after asOf are skipped and pricing is frozen
+	asOf          time.Time
+	frozenPricing *pricing.FrozenProvider
+
+	// Running claudecat serve queried instead of the files when it covers the range from daemonFrom on
+	daemon     *DaemonClient
+	daemonFrom time.Time
 }
 
 // NewAnalyzer creates a new analyzer instance
@@ -31,6 +67,166 @@ func NewAnalyzer(cfg *config.Config) (*Analyzer, error) {
 	}, nil
 }
 
+// SetSampleRate makes Analyze load only the given fraction of files and scale results to
+// estimate full totals; 0 or 1 analyzes every file
+func (a *Analyzer) SetSampleRate(rate float64) {
+	a.sampleRate = rate
+}
+
+// SetIncludeSource makes Analyze record the source file and line of each result.
+// The summary cache is bypassed because it does not retain individual lines.
+func (a *Analyzer) SetIncludeSource(include bool) {
+	a.includeSource = include
+}
+
+// SetIncludeTools makes Analyze record the tool server each result called.
+// The summary cache is bypassed because it does not retain tool calls.
+func (a *Analyzer) SetIncludeTools(include bool) {
+	a.includeTools = include
+}
+
+// SetIncludeImports controls whether Analyze and LoadEntries add usage imported from other tools;
+// it is included by default
+func (a *Analyzer) SetIncludeImports(include bool) {
+	a.skipImports = !include
+}
+
+// SetDaemon makes Analyze and the session listings use the data of a running claudecat serve when it
+// monitors the data path and its history reaches back to from; otherwise the files are parsed as usual
+func (a *Analyzer) SetDaemon(client *DaemonClient, from time.Time) {
+	a.daemon = client
+	a.daemonFrom = from
+}
+
+// daemonBlocks returns the session blocks of the daemon set by SetDaemon, or false when it does not
+// answer or the analysis needs details it does not keep (sampling, provenance, tools or an as-of time)
+func (a *Analyzer) daemonBlocks(paths []string) ([]models.SessionBlock, bool) {
+	if a.daemon == nil || (a.sampleRate > 0 && a.sampleRate < 1) || a.includeSource || a.includeTools || !a.asOf.IsZero() {
+		return nil, false
+	}
+	ctx := context.Background()
+	if !a.daemon.Covers(ctx, paths, a.daemonFrom) {
+		return nil, false
+	}
+	blocks, err := a.daemon.Blocks(ctx)
+	if err != nil {
+		logging.LogWarnf("Failed to query claudecat serve, parsing the files instead: %v", err)
+		return nil, false
+	}
+	logging.LogInfof("Using %d session blocks from claudecat serve", len(blocks))
+	return blocks, true
+}
+
+// SetImportStore sets the store imported usage is read from
+func (a *Analyzer) SetImportStore(store *ImportStore) {
+	a.imports = store
+}
+
+// importedEntries returns the imported usage unless it is excluded; a store that cannot be read is
+// logged and skipped so that local usage is still reported
+func (a *Analyzer) importedEntries() []models.UsageEntry {
+	if a.skipImports {
+		return nil
+	}
+	if a.imports == nil {
+		store, err := OpenImportStore(a.config)
+		if err != nil {
+			logging.LogWarnf("Failed to open imported usage: %v", err)
+			return nil
+		}
+		a.imports = store
+	}
+	entries, err := a.imports.Entries()
+	if err != nil {
+		logging.LogWarnf("Failed to load imported usage: %v", err)
+		return nil
+	}
+	return entries
+}
+
+// SetAsOf makes Report reproducible: files modified after asOf are skipped, pricing is frozen to the
+// tables saved for asOf and the inputs are recorded in the report; the zero time disables it
+func (a *Analyzer) SetAsOf(asOf time.Time) {
+	a.asOf = asOf
+}
+
+// SetTagStore sets the store session tags are read from
+func (a *Analyzer) SetTagStore(store *TagStore) {
+	a.tags = store
+}
+
+// sessionTags returns the session tags; a store that cannot be read is logged and skipped so that
+// usage is still reported
+func (a *Analyzer) sessionTags() SessionTagSet {
+	if a.tags == nil {
+		store, err := OpenTagStore(a.config)
+		if err != nil {
+			logging.LogWarnf("Failed to open session tags: %v", err)
+			return nil
+		}
+		a.tags = store
+	}
+	tags, err := a.tags.Load()
+	if err != nil {
+		logging.LogWarnf("Failed to load session tags: %v", err)
+		return nil
+	}
+	return tags
+}
+
+// Sampling returns the sample taken by the last Analyze call, or nil if all files were analyzed
+func (a *Analyzer) Sampling() *fileio.SamplingStats {
+	return a.sampling
+}
+
+// GuardrailHits returns the messages found by the last Analyze call whose input exceeded the
+// message-size guardrail of their model, largest first
+func (a *Analyzer) GuardrailHits() []calculations.GuardrailHit {
+	return a.guardrailHits
+}
+
+// DuplicateProjects returns the project directories found by the last load whose conversations are
+// copies of those in another directory; they are counted twice unless data.duplicate_projects is merge
+func (a *Analyzer) DuplicateProjects() []fileio.DuplicateProject {
+	return a.duplicates
+}
+
+// skippedFiles scans paths for conversations logged in several project directories and returns the
+// copies to skip when data.duplicate_projects is merge
+func (a *Analyzer) skippedFiles(paths []string) map[string]bool {
+	scan, skip := scanDuplicates(a.config, paths)
+	a.duplicates = scan.Projects
+	return skip
+}
+
+// scanDuplicates finds the conversations of paths logged in several project directories, returning the
+// redundant copies when cfg merges them and nil when they are only warned about
+func scanDuplicates(cfg *config.Config, paths []string) (fileio.DuplicateScan, map[string]bool) {
+	scan := fileio.ScanDuplicateProjects(paths, cfg.Data.Exclude)
+	if cfg.Data.DuplicateProjects == "merge" {
+		return scan, scan.Redundant
+	}
+	return scan, nil
+}
+
+// newSeenFiles returns the set shared across data paths so a session log synced into several data
+// directories is loaded once, or nil when deduplication is disabled
+func (a *Analyzer) newSeenFiles() map[string]bool {
+	if !a.config.Data.Deduplication {
+		return nil
+	}
+	return make(map[string]bool)
+}
+
+// costModel returns the cost model of the data path, or nil when it bills list prices
+func (a *Analyzer) costModel(path string) models.CostModel {
+	model, err := pricing.CostModelForPath(&a.config.Data, path)
+	if err != nil {
+		logging.LogWarnf("Costing %s at list prices: %v", path, err)
+	}
+	return model
+}
+
 // Analyze performs analysis on the specified data paths
 func (a *Analyzer) Analyze(paths []string) ([]models.AnalysisResult, error) {
 	if len(paths) == 0 {
@@ -39,24 +235,79 @@ func (a *Analyzer) Analyze(paths []string) ([]models.AnalysisResult, error) {
 
 	logging.LogInfof("Starting analysis of %d paths: %v", len(paths), paths)
 
-	// Expand cache directory path for use in both cache and pricing
-	cacheDir := a.config.Cache.Dir
-	if cacheDir != "" && cacheDir[:2] == "~/" {
-		homeDir, _ := os.UserHomeDir()
-		cacheDir = filepath.Join(homeDir, cacheDir[2:])
+	a.sampling = nil
+	a.guardrailHits = nil
+	var allResults []models.AnalysisResult
+	if blocks, ok := a.daemonBlocks(paths); ok {
+		guardrail := calculations.NewMessageGuardrail(a.config.Guardrails)
+		for _, block := range blocks {
+			if guardrail.Enabled() {
+				a.guardrailHits = append(a.guardrailHits, guardrail.Scan(block.Entries)...)
+			}
+			for _, entry := range block.Entries {
+				allResults = append(allResults, entryResult(entry))
+			}
+		}
+	} else {
+		allResults = a.parseResults(paths)
 	}
 
-	// Create BadgerDB cache store if caching is enabled
-	var cacheStore fileio.CacheStore
-	// Use file-based cache with memory preloading
-	fileCache, err := cache.NewFileBasedSummaryCache(cacheDir)
-	if err != nil {
-		logging.LogErrorf("Failed to create file-based cache: %v", err)
-		// Cache is disabled on error
-	} else {
-		cacheStore = fileCache
+	// Sort results by timestamp
+	sort.Slice(allResults, func(i, j int) bool {
+		return allResults[i].Timestamp.Before(allResults[j].Timestamp)
+	})
+	sort.SliceStable(a.guardrailHits, func(i, j int) bool {
+		return a.guardrailHits[i].InputTokens > a.guardrailHits[j].InputTokens
+	})
+	assignSessionIDs(allResults, a.loadPinnedSessionStarts())
+	if tags := a.sessionTags(); len(tags) > 0 {
+		for i := range allResults {
+			if start, ok := parseSessionID(allResults[i].SessionID); ok {
+				allResults[i].Tags = tags.Labels(start)
+			}
+		}
 	}
 
+	// Imported usage is aggregated per period, so it stays out of session detection
+	if imported := a.importedEntries(); len(imported) > 0 {
+		for _, entry := range imported {
+			allResults = append(allResults, models.AnalysisResult{
+				Timestamp:           entry.Timestamp,
+				Model:               entry.Model,
+				SessionID:           entry.SessionID,
+				InputTokens:         entry.InputTokens,
+				OutputTokens:        entry.OutputTokens,
+				CacheCreationTokens: entry.CacheCreationTokens,
+				CacheReadTokens:     entry.CacheReadTokens,
+				TotalTokens:         entry.TotalTokens,
+				CostUSD:             entry.CostUSD,
+				Count:               1,
+				Project:             entry.Project,
+			})
+		}
+		sort.SliceStable(allResults, func(i, j int) bool {
+			return allResults[i].Timestamp.Before(allResults[j].Timestamp)
+		})
+		logging.LogInfof("Added %d imported entries", len(imported))
+	}
+
+	if len(allResults) == 0 {
+		return nil, fmt.Errorf("no usage data found in any of the specified paths: %v\n\nExpected data format:\n- JSONL files with usage data\n- Files should contain either 'type: message' with usage field, or 'type: assistant' with message.usage field\n- Check that the paths contain Claude conversation or API usage logs", paths)
+	}
+
+	logging.LogInfof("Analysis completed: %d results from %d paths", len(allResults), len(paths))
+	return allResults, nil
+}
+
+// parseResults loads the usage entries of paths through the summary cache as analysis results,
+// recording the sample taken and the guardrail hits
+func (a *Analyzer) parseResults(paths []string) []models.AnalysisResult {
+	// Expand cache directory path for use in both cache and pricing
+	cacheDir := config.ExpandHome(a.config.Cache.Dir)
+
+	cacheStore, archives := a.openSummaryCache(cacheDir)
+	ApplyRetention(a.config)
+
 	// Create pricing provider
 	pricingProvider, err := pricing.CreatePricingProvider(&a.config.Data, cacheDir)
 	if err != nil {
@@ -65,7 +316,28 @@ func (a *Analyzer) Analyze(paths []string) ([]models.AnalysisResult, error) {
 		pricingProvider = pricing.NewDefaultProvider()
 	}
 
+	// Roll files untouched for the configured number of months into monthly archives
+	if archives != nil && a.config.Cache.ArchiveAfterMonths > 0 {
+		before := cache.ArchiveCutoff(a.config.Cache.ArchiveAfterMonths, time.Now())
+		for _, path := range paths {
+			if _, err := fileio.CompactArchives(fileio.CompactOptions{
+				DataPath:        path,
+				Before:          before,
+				Archives:        archives,
+				CacheStore:      cacheStore,
+				Mode:            models.CostModeCalculated,
+				PricingProvider: pricingProvider,
+				MaxLineSize:     a.config.Data.MaxLineSize,
+			}); err != nil {
+				logging.LogWarnf("Failed to archive old usage files of %s: %v", path, err)
+			}
+		}
+	}
+
+	guardrail := calculations.NewMessageGuardrail(a.config.Guardrails)
 	var allResults []models.AnalysisResult
+	seenFiles := a.newSeenFiles()
+	skipFiles := a.skippedFiles(paths)
 	for _, path := range paths {
 		// Use LoadUsageEntries with caching support
 		opts := fileio.LoadUsageEntriesOptions{
@@ -74,6 +346,15 @@ func (a *Analyzer) Analyze(paths []string) ([]models.AnalysisResult, error) {
 			CacheStore:          cacheStore,
 			EnableDeduplication: a.config.Data.Deduplication,
 			PricingProvider:     pricingProvider,
+			CostModel:           a.costModel(path),
+			MaxLineSize:         a.config.Data.MaxLineSize,
+			SampleRate:          a.sampleRate,
+			IncludeSource:       a.includeSource,
+			IncludeTools:        a.includeTools,
+			Archives:            archives,
+			SeenFiles:           seenFiles,
+			Exclude:             a.config.Data.Exclude,
+			SkipFiles:           skipFiles,
 		}
 
 		result, err := fileio.LoadUsageEntries(opts)
@@ -82,20 +363,24 @@ func (a *Analyzer) Analyze(paths []string) ([]models.AnalysisResult, error) {
 			continue
 		}
 
+		weight := 1.0
+		if sample := result.Metadata.Sampling; sample != nil {
+			if a.sampling == nil {
+				a.sampling = &fileio.SamplingStats{}
+			}
+			a.sampling.Merge(sample)
+			weight = sample.Weight()
+		}
+
+		if guardrail.Enabled() {
+			a.guardrailHits = append(a.guardrailHits, guardrail.Scan(result.Entries)...)
+		}
+
 		// Convert usage entries to analysis results
 		for _, entry := range result.Entries {
-			analysisResult := models.AnalysisResult{
-				Timestamp:           entry.Timestamp,
-				Model:               entry.Model,
-				SessionID:           a.generateSessionID(entry.Timestamp),
-				InputTokens:         entry.InputTokens,
-				OutputTokens:        entry.OutputTokens,
-				CacheCreationTokens: entry.CacheCreationTokens,
-				CacheReadTokens:     entry.CacheReadTokens,
-				TotalTokens:         entry.TotalTokens,
-				CostUSD:             entry.CostUSD,
-				Count:               1,
-				Project:             entry.Project,
+			analysisResult := entryResult(entry)
+			if weight != 1 {
+				scaleResult(&analysisResult, weight)
 			}
 			allResults = append(allResults, analysisResult)
 		}
@@ -105,25 +390,687 @@ func (a *Analyzer) Analyze(paths []string) ([]models.AnalysisResult, error) {
 			result.Metadata.FilesProcessed,
 			len(result.Metadata.ProcessingErrors))
 	}
+	return allResults
+}
 
-	// Sort results by timestamp
-	sort.Slice(allResults, func(i, j int) bool {
-		return allResults[i].Timestamp.Before(allResults[j].Timestamp)
+// openSummaryCache opens the summary cache in cacheDir with its archives; both are nil when the cache
+// cannot be opened, so that the files are parsed
+func (a *Analyzer) openSummaryCache(cacheDir string) (fileio.CacheStore, *cache.ArchiveStore) {
+	// Use file-based cache with memory preloading
+	fileCache, err := cache.OpenFileBasedSummaryCache(cacheDir, a.config.Cache.Encryption)
+	if err != nil {
+		logging.LogErrorf("Failed to create file-based cache: %v", err)
+		return nil, nil
+	}
+	fileCache.SetTrashTTL(a.config.Cache.TrashTTL)
+	fileCache.SetRedactAfterDays(a.config.Retention.RedactIDsAfterDays)
+	fileCache.SetMaxDiskSize(a.config.Cache.MaxDiskSize)
+	return fileCache, fileCache.Archives()
+}
+
+// entryResult converts a usage entry to an analysis result
+func entryResult(entry models.UsageEntry) models.AnalysisResult {
+	return models.AnalysisResult{
+		Timestamp:             entry.Timestamp,
+		Model:                 entry.Model,
+		InputTokens:           entry.InputTokens,
+		OutputTokens:          entry.OutputTokens,
+		CacheCreationTokens:   entry.CacheCreationTokens,
+		CacheCreation1hTokens: entry.CacheCreation1hTokens,
+		CacheReadTokens:       entry.CacheReadTokens,
+		TotalTokens:           entry.TotalTokens,
+		CostUSD:               entry.CostUSD,
+		Count:                 1,
+		Project:               entry.Project,
+		ToolServer:            entry.ToolServer,
+		SourceFile:            entry.SourceFile,
+		SourceLine:            entry.SourceLine,
+	}
+}
+
+// AuditCosts compares costs recorded in the logs with calculated costs for entries in [from, to].
+// The summary cache is bypassed because summaries do not retain logged costs.
+func (a *Analyzer) AuditCosts(paths []string, tolerance float64, from, to time.Time) (*calculations.CostAuditor, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no data paths found - please specify paths as arguments")
+	}
+
+	cacheDir := config.ExpandHome(a.config.Cache.Dir)
+
+	pricingProvider, err := pricing.CreatePricingProvider(&a.config.Data, cacheDir)
+	if err != nil {
+		logging.LogErrorf("Failed to create pricing provider: %v", err)
+		pricingProvider = pricing.NewDefaultProvider()
+	}
+
+	auditor := calculations.NewCostAuditor(tolerance)
+	seenFiles := a.newSeenFiles()
+	skipFiles := a.skippedFiles(paths)
+	for _, path := range paths {
+		result, err := fileio.LoadUsageEntries(fileio.LoadUsageEntriesOptions{
+			DataPath:            path,
+			Mode:                models.CostModeCalculated,
+			EnableDeduplication: a.config.Data.Deduplication,
+			PricingProvider:     pricingProvider,
+			MaxLineSize:         a.config.Data.MaxLineSize,
+			SeenFiles:           seenFiles,
+			Exclude:             a.config.Data.Exclude,
+			SkipFiles:           skipFiles,
+		})
+		if err != nil {
+			logging.LogErrorf("Failed to load usage entries from %s: %v", path, err)
+			continue
+		}
+
+		for _, entry := range result.Entries {
+			if !from.IsZero() && entry.Timestamp.Before(from) {
+				continue
+			}
+			if !to.IsZero() && entry.Timestamp.After(to) {
+				continue
+			}
+			auditor.Record(entry)
+		}
+	}
+
+	return auditor, nil
+}
+
+// LoadEntries returns the parsed usage entries in [from, to] sorted by time; zero bounds are open.
+// The summary cache is bypassed because summaries only retain aggregates, not individual entries.
+func (a *Analyzer) LoadEntries(paths []string, from, to time.Time) ([]models.UsageEntry, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no data paths found - please specify paths as arguments")
+	}
+
+	cacheDir := config.ExpandHome(a.config.Cache.Dir)
+
+	pricingProvider, err := pricing.CreatePricingProvider(&a.config.Data, cacheDir)
+	if err != nil {
+		logging.LogErrorf("Failed to create pricing provider: %v", err)
+		pricingProvider = pricing.NewDefaultProvider()
+	}
+
+	var entries []models.UsageEntry
+	seenFiles := a.newSeenFiles()
+	skipFiles := a.skippedFiles(paths)
+	for _, path := range paths {
+		result, err := fileio.LoadUsageEntries(fileio.LoadUsageEntriesOptions{
+			DataPath:            path,
+			Mode:                models.CostModeCalculated,
+			EnableDeduplication: a.config.Data.Deduplication,
+			PricingProvider:     pricingProvider,
+			CostModel:           a.costModel(path),
+			MaxLineSize:         a.config.Data.MaxLineSize,
+			SeenFiles:           seenFiles,
+			Exclude:             a.config.Data.Exclude,
+			SkipFiles:           skipFiles,
+		})
+		if err != nil {
+			logging.LogErrorf("Failed to load usage entries from %s: %v", path, err)
+			continue
+		}
+
+		for _, entry := range result.Entries {
+			if !from.IsZero() && entry.Timestamp.Before(from) {
+				continue
+			}
+			if !to.IsZero() && entry.Timestamp.After(to) {
+				continue
+			}
+			entries = append(entries, entry)
+		}
+	}
+	for _, entry := range a.importedEntries() {
+		if (from.IsZero() || !entry.Timestamp.Before(from)) && (to.IsZero() || !entry.Timestamp.After(to)) {
+			entries = append(entries, entry)
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
 	})
+	return entries, nil
+}
 
-	if len(allResults) == 0 {
-		return nil, fmt.Errorf("no usage data found in any of the specified paths: %v\n\nExpected data format:\n- JSONL files with usage data\n- Files should contain either 'type: message' with usage field, or 'type: assistant' with message.usage field\n- Check that the paths contain Claude conversation or API usage logs", paths)
+// RecommendPlan compares the last month of usage against each subscription plan.
+// Limit messages found in the logs count as collisions for the configured plan.
+func (a *Analyzer) RecommendPlan(paths []string, now time.Time) (calculations.PlanRecommendation, error) {
+	hoursBack := int(calculations.DefaultRecommendationWindow/time.Hour) + int(models.SessionDuration/time.Hour)
+	blocks, limits, err := a.loadSessionBlocks(paths, hoursBack)
+	if err != nil {
+		return calculations.PlanRecommendation{}, err
 	}
+	return calculations.NewPlanRecommender(limits).Recommend(a.config.Subscription.Plan, blocks, now), nil
+}
 
-	logging.LogInfof("Analysis completed: %d results from %d paths", len(allResults), len(paths))
-	return allResults, nil
+// Digest summarizes the usage of the period ending at now for the configured plan
+func (a *Analyzer) Digest(paths []string, period string, now time.Time) (calculations.Digest, error) {
+	days, err := calculations.DigestPeriodDays(period)
+	if err != nil {
+		return calculations.Digest{}, err
+	}
+
+	// Load the previous period and the anomaly baseline as well, plus a day of slack for the timezone
+	hoursBack := (2*days+calculations.DigestBaselineDays+1)*24 + int(models.SessionDuration/time.Hour)
+	blocks, limits, err := a.loadSessionBlocks(paths, hoursBack)
+	if err != nil {
+		return calculations.Digest{}, err
+	}
+
+	loc, err := time.LoadLocation(a.config.App.Timezone)
+	if err != nil {
+		loc = time.Local
+	}
+	sub := a.config.Subscription
+	return calculations.NewDigestBuilder(loc, limits).Build(period, sub.Plan, sub.WarnThreshold, blocks, now)
 }
 
-// generateSessionID generates a session ID based on timestamp
-func (a *Analyzer) generateSessionID(timestamp time.Time) string {
-	// Simple session ID generation - group by 5-hour blocks
-	sessionStart := timestamp.Truncate(5 * time.Hour)
-	return fmt.Sprintf("session_%s", sessionStart.Format("2006-01-02_15"))
+// Anomalies detects the days of unusually high cost among the last days calendar days up to now
+func (a *Analyzer) Anomalies(paths []string, days int, now time.Time) ([]calculations.UsageAnomaly, error) {
+	// Load the baseline of the first day as well, plus a day of slack for the timezone
+	hoursBack := (days+calculations.DigestBaselineDays+1)*24 + int(models.SessionDuration/time.Hour)
+	blocks, _, err := a.loadSessionBlocks(paths, hoursBack)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, err := time.LoadLocation(a.config.App.Timezone)
+	if err != nil {
+		loc = time.Local
+	}
+	start := calculations.StartOfDay(now, loc).AddDate(0, 0, -(days - 1))
+	return calculations.DetectAnomalies(blocks, start, now, loc), nil
+}
+
+// Report builds the usage report of the week or month containing date
+func (a *Analyzer) Report(paths []string, period string, date, now time.Time) (calculations.Report, error) {
+	loc, err := time.LoadLocation(a.config.App.Timezone)
+	if err != nil {
+		loc = time.Local
+	}
+	start, _, err := calculations.ReportPeriodBounds(period, date, loc)
+	if err != nil {
+		return calculations.Report{}, err
+	}
+
+	// Load from the period start, plus a session of slack so that sessions running into the period are
+	// detected as usual; the cutoff is relative to the clock, which is later than now for --as-of
+	hoursBack := int(math.Ceil(time.Since(start).Hours())) + int(models.SessionDuration/time.Hour)
+	if hoursBack < 0 {
+		hoursBack = 0
+	}
+	blocks, _, err := a.loadSessionBlocks(paths, hoursBack)
+	if err != nil {
+		return calculations.Report{}, err
+	}
+	report, err := calculations.NewReportBuilder(loc).Build(period, blocks, date, now)
+	if err != nil {
+		return calculations.Report{}, err
+	}
+	if tags := a.sessionTags(); len(tags) > 0 {
+		for i := range report.TopSessions {
+			report.TopSessions[i].Tags = tags.Labels(report.TopSessions[i].StartTime)
+		}
+	}
+	if a.frozenPricing != nil {
+		report.Inputs = &calculations.ReportInputs{
+			AsOf:        a.asOf,
+			Pricing:     a.frozenPricing.GetProviderName(),
+			PricingHash: a.frozenPricing.Snapshot().Hash(),
+			ConfigHash:  reportConfigHash(a.config, paths),
+		}
+	}
+	return report, nil
+}
+
+// reportConfigHash returns a short fingerprint of the settings a report depends on
+func reportConfigHash(cfg *config.Config, paths []string) string {
+	absPaths := make([]string, len(paths))
+	for i, path := range paths {
+		absPaths[i] = path
+		if abs, err := filepath.Abs(path); err == nil {
+			absPaths[i] = abs
+		}
+	}
+	// Costed at list prices, reports hash as they did before cost models
+	costModel := cfg.Data.CostModel
+	if costModel == pricing.CostModelSubscription {
+		costModel = ""
+	}
+	data, _ := json.Marshal(struct {
+		Paths         []string               `json:"paths"`
+		Timezone      string                 `json:"timezone"`
+		Deduplication bool                   `json:"deduplication"`
+		PricingSource string                 `json:"pricing_source"`
+		Plan          string                 `json:"plan"`
+		CostModel     string                 `json:"cost_model,omitempty"`
+		CostModels    []config.CostModelPath `json:"cost_models,omitempty"`
+		RequestFee    float64                `json:"request_fee,omitempty"`
+	}{absPaths, cfg.App.Timezone, cfg.Data.Deduplication, cfg.Data.PricingSource, cfg.Subscription.Plan,
+		costModel, cfg.Data.CostModels, cfg.Data.RequestFee})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:6])
+}
+
+// Forecast projects the cost of the current week or month from the daily costs of the days before today
+func (a *Analyzer) Forecast(paths []string, period, model string, historyDays int, confidence float64, now time.Time) (calculations.Forecast, error) {
+	loc, err := time.LoadLocation(a.config.App.Timezone)
+	if err != nil {
+		loc = time.Local
+	}
+	results, err := a.Analyze(paths)
+	if err != nil {
+		return calculations.Forecast{}, err
+	}
+	return calculations.ForecastPeriod(results, period, model, historyDays, confidence, now, loc)
+}
+
+// Milestones computes the lifetime totals, streaks and badges of all usage, with days in the configured timezone
+func (a *Analyzer) Milestones(paths []string, now time.Time) (calculations.Milestones, error) {
+	loc, err := time.LoadLocation(a.config.App.Timezone)
+	if err != nil {
+		loc = time.Local
+	}
+	results, err := a.Analyze(paths)
+	if err != nil {
+		return calculations.Milestones{}, err
+	}
+	return calculations.ComputeMilestones(results, now, loc), nil
+}
+
+// QuickStats totals the current day, week and month and snapshots the active session at now. Only the
+// entries from the earliest period start on are loaded, through the summary cache and without archiving
+// old files, so that a warm cache answers in milliseconds.
+func (a *Analyzer) QuickStats(paths []string, now time.Time) (calculations.QuickStats, error) {
+	if len(paths) == 0 {
+		return calculations.QuickStats{}, fmt.Errorf("no data paths found - please specify paths as arguments")
+	}
+	loc, err := time.LoadLocation(a.config.App.Timezone)
+	if err != nil {
+		loc = time.Local
+	}
+
+	// The week may start in the previous month; load a session of slack as for reports
+	earliest, _, _ := calculations.ReportPeriodBounds(calculations.ReportPeriodMonth, now, loc)
+	if weekStart, _, _ := calculations.ReportPeriodBounds(calculations.ReportPeriodWeek, now, loc); weekStart.Before(earliest) {
+		earliest = weekStart
+	}
+	hoursBack := int(math.Ceil(time.Since(earliest).Hours())) + int(models.SessionDuration/time.Hour)
+	if hoursBack < 0 {
+		hoursBack = 0
+	}
+
+	cacheDir := config.ExpandHome(a.config.Cache.Dir)
+	cacheStore, archives := a.openSummaryCache(cacheDir)
+	pricingProvider, err := pricing.CreatePricingProvider(&a.config.Data, cacheDir)
+	if err != nil {
+		logging.LogErrorf("Failed to create pricing provider: %v", err)
+		pricingProvider = pricing.NewDefaultProvider()
+	}
+
+	var entries []models.UsageEntry
+	seenFiles := a.newSeenFiles()
+	skipFiles := a.skippedFiles(paths)
+	for _, path := range paths {
+		result, err := fileio.LoadUsageEntries(fileio.LoadUsageEntriesOptions{
+			DataPath:            path,
+			HoursBack:           &hoursBack,
+			Mode:                models.CostModeCalculated,
+			CacheStore:          cacheStore,
+			EnableDeduplication: a.config.Data.Deduplication,
+			PricingProvider:     pricingProvider,
+			CostModel:           a.costModel(path),
+			MaxLineSize:         a.config.Data.MaxLineSize,
+			Archives:            archives,
+			SeenFiles:           seenFiles,
+			Exclude:             a.config.Data.Exclude,
+			SkipFiles:           skipFiles,
+		})
+		if err != nil {
+			logging.LogErrorf("Failed to load usage entries from %s: %v", path, err)
+			continue
+		}
+		entries = append(entries, result.Entries...)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+
+	blocks := sessions.NewSessionAnalyzer(int(models.SessionDuration / time.Hour)).TransformToBlocks(entries)
+	limits := planLimits(installedLimits(&a.config.Data, cacheDir), a.config.Subscription.Plan)
+	return calculations.ComputeQuickStats(blocks, limits, now, loc), nil
+}
+
+// Budgets checks the spend of the current day, week and month against budgets
+func (a *Analyzer) Budgets(paths []string, budgets config.BudgetConfig, now time.Time) (calculations.BudgetStatus, error) {
+	loc, err := time.LoadLocation(a.config.App.Timezone)
+	if err != nil {
+		loc = time.Local
+	}
+
+	// Load from the earliest period start, plus a session of slack as for reports
+	earliest := now
+	for _, check := range calculations.BudgetPeriods(budgets, now, loc) {
+		if check.Start.Before(earliest) {
+			earliest = check.Start
+		}
+	}
+	hoursBack := int(math.Ceil(now.Sub(earliest).Hours())) + int(models.SessionDuration/time.Hour)
+	blocks, _, err := a.loadSessionBlocks(paths, hoursBack)
+	if err != nil {
+		return calculations.BudgetStatus{}, err
+	}
+	return calculations.CheckBudgets(budgets, blocks, now, loc), nil
+}
+
+// alertRuleHistoryDays is the history loaded for percentile alert rules
+const alertRuleHistoryDays = 30
+
+// AlertRules evaluates the alert rules at now over the last alertRuleHistoryDays days of usage
+func (a *Analyzer) AlertRules(paths []string, rules []config.AlertRule, now time.Time) ([]calculations.AlertRuleResult, error) {
+	loc, err := time.LoadLocation(a.config.App.Timezone)
+	if err != nil {
+		loc = time.Local
+	}
+	hoursBack := alertRuleHistoryDays*24 + int(models.SessionDuration/time.Hour)
+	blocks, limits, err := a.loadSessionBlocks(paths, hoursBack)
+	if err != nil {
+		return nil, err
+	}
+	return calculations.EvaluateAlertRules(rules, blocks, planLimits(limits, a.config.Subscription.Plan), now, loc), nil
+}
+
+// SessionPercentiles computes per-session percentiles over the last days of usage and the custom
+// plan limits estimated from them
+func (a *Analyzer) SessionPercentiles(paths []string, days int) (calculations.SessionPercentiles, models.PlanLimits, error) {
+	hoursBack := days*24 + int(models.SessionDuration/time.Hour)
+	blocks, _, err := a.loadSessionBlocks(paths, hoursBack)
+	if err != nil {
+		return calculations.SessionPercentiles{}, models.PlanLimits{}, err
+	}
+
+	loc, err := time.LoadLocation(a.config.App.Timezone)
+	if err != nil {
+		loc = time.Local
+	}
+	stats := calculations.NewStatsAggregator(loc).SessionPercentiles(blocks)
+	return stats, calculations.NewCustomLimitEstimator().EstimateLimits(blocks), nil
+}
+
+// Sessions lists the session blocks and idle gaps selected by filter
+func (a *Analyzer) Sessions(paths []string, filter sessions.ListFilter, now time.Time) ([]sessions.SessionSummary, error) {
+	blocks, _, err := a.loadBlocks(paths, sessionsHoursBack(filter.From, now))
+	if err != nil {
+		return nil, err
+	}
+	summaries := sessions.List(blocks, filter)
+	if tags := a.sessionTags(); len(tags) > 0 {
+		for i := range summaries {
+			tags.apply(&summaries[i])
+		}
+	}
+	return summaries, nil
+}
+
+// SessionModels breaks the session blocks selected by filter down by model
+func (a *Analyzer) SessionModels(paths []string, filter sessions.ListFilter, now time.Time) ([]sessions.SessionModelUsage, error) {
+	blocks, _, err := a.loadBlocks(paths, sessionsHoursBack(filter.From, now))
+	if err != nil {
+		return nil, err
+	}
+	rows := sessions.ListPerModel(blocks, filter)
+	if tags := a.sessionTags(); len(tags) > 0 {
+		for i := range rows {
+			rows[i].Tags = tags.Labels(rows[i].Start)
+		}
+	}
+	return rows, nil
+}
+
+// ResolveSessionStart returns the start of the session with id, either an analyze session ID such
+// as session_2025-06-01_10 or a session block ID listed by claudecat sessions
+func (a *Analyzer) ResolveSessionStart(paths []string, id string) (time.Time, error) {
+	if start, ok := parseSessionID(id); ok {
+		return start, nil
+	}
+	blocks, _, err := a.loadBlocks(paths, nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+	for _, block := range blocks {
+		if block.ID == id && !block.IsGap {
+			return block.StartTime, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("no session with ID %s (see claudecat sessions -o json or analyze --group-by session)", id)
+}
+
+// parseSessionID returns the start encoded in a session ID formatted by sessionIDFor
+func parseSessionID(id string) (time.Time, bool) {
+	value, ok := strings.CutPrefix(id, "session_")
+	if !ok {
+		return time.Time{}, false
+	}
+	for _, layout := range []string{"2006-01-02_15", "2006-01-02_1504"} {
+		if start, err := time.Parse(layout, value); err == nil {
+			return start, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// sessionsHoursBack returns how much history to load for sessions from from on: the range start plus a
+// session of slack so that sessions running into it are detected as usual, or nil for the whole history
+func sessionsHoursBack(from, now time.Time) *int {
+	if from.IsZero() {
+		return nil
+	}
+	hours := max(0, int(math.Ceil(now.Sub(from).Hours()))) + int(models.SessionDuration/time.Hour)
+	return &hours
+}
+
+// Limits reports the limit messages hit in the session blocks selected by filter
+func (a *Analyzer) Limits(paths []string, filter sessions.ListFilter, now time.Time) (sessions.LimitReport, error) {
+	blocks, _, err := a.loadBlocks(paths, sessionsHoursBack(filter.From, now))
+	if err != nil {
+		return sessions.LimitReport{}, err
+	}
+	return sessions.Limits(blocks, filter, now), nil
+}
+
+// At rebuilds what the monitor showed at a past time from the usage logged by then, against the
+// configured plan's limits
+func (a *Analyzer) At(paths []string, at, now time.Time) (sessions.Moment, error) {
+	blocks, limits, err := a.loadBlocks(paths, sessionsHoursBack(at.Add(-models.SessionDuration), now))
+	if err != nil {
+		return sessions.Moment{}, err
+	}
+
+	var entries []models.UsageEntry
+	var limitMessages []models.LimitMessage
+	for _, block := range blocks {
+		entries = append(entries, block.Entries...)
+		limitMessages = append(limitMessages, block.LimitMessages...)
+	}
+	return sessions.Replay(entries, limitMessages, planLimits(limits, a.config.Subscription.Plan), at), nil
+}
+
+// Blocks reports the 5-hour billing windows selected by opts.Filter against the configured plan's
+// limits, preferring those of the installed data bundle
+func (a *Analyzer) Blocks(paths []string, opts sessions.BlockOptions, now time.Time) (sessions.BlockReport, error) {
+	blocks, limits, err := a.loadBlocks(paths, sessionsHoursBack(opts.Filter.From, now))
+	if err != nil {
+		return sessions.BlockReport{}, err
+	}
+
+	opts.Limits = planLimits(limits, a.config.Subscription.Plan)
+	report := sessions.Blocks(blocks, opts, now)
+	if tags := a.sessionTags(); len(tags) > 0 {
+		for i := range report.Blocks {
+			tags.apply(&report.Blocks[i].SessionSummary)
+		}
+	}
+	return report, nil
+}
+
+// loadSessionBlocks builds session blocks from the last hoursBack hours of usage with detected
+// limit messages attached, and returns the plan limits from the installed data bundle, if any
+func (a *Analyzer) loadSessionBlocks(paths []string, hoursBack int) ([]models.SessionBlock, map[string]models.PlanLimits, error) {
+	return a.loadBlocks(paths, &hoursBack)
+}
+
+// loadBlocks is loadSessionBlocks where a nil hoursBack loads the whole history
+func (a *Analyzer) loadBlocks(paths []string, hoursBack *int) ([]models.SessionBlock, map[string]models.PlanLimits, error) {
+	if len(paths) == 0 {
+		return nil, nil, fmt.Errorf("no data paths found - please specify paths as arguments")
+	}
+
+	cacheDir := config.ExpandHome(a.config.Cache.Dir)
+
+	if blocks, ok := a.daemonBlocks(paths); ok {
+		return blocks, installedLimits(&a.config.Data, cacheDir), nil
+	}
+
+	pricingProvider, err := pricing.CreatePricingProvider(&a.config.Data, cacheDir)
+	if err != nil {
+		logging.LogErrorf("Failed to create pricing provider: %v", err)
+		pricingProvider = pricing.NewDefaultProvider()
+	}
+	if !a.asOf.IsZero() {
+		// The first run for a timestamp saves the current tables, later runs reuse them
+		snapshotPath := filepath.Join(cacheDir, "pricing", "as-of-"+a.asOf.UTC().Format("20060102T150405Z")+".json")
+		frozen, err := pricing.FreezePricing(context.Background(), pricingProvider, snapshotPath, time.Now())
+		if err != nil {
+			return nil, nil, err
+		}
+		pricingProvider = frozen
+		a.frozenPricing = frozen
+	}
+
+	analyzer := sessions.NewSessionAnalyzer(int(models.SessionDuration / time.Hour))
+	var blocks []models.SessionBlock
+	seenFiles := a.newSeenFiles()
+	skipFiles := a.skippedFiles(paths)
+	for _, path := range paths {
+		result, err := fileio.LoadUsageEntries(fileio.LoadUsageEntriesOptions{
+			DataPath:            path,
+			HoursBack:           hoursBack,
+			Mode:                models.CostModeCalculated,
+			IncludeRaw:          true,
+			EnableDeduplication: a.config.Data.Deduplication,
+			PricingProvider:     pricingProvider,
+			CostModel:           a.costModel(path),
+			MaxLineSize:         a.config.Data.MaxLineSize,
+			SeenFiles:           seenFiles,
+			Exclude:             a.config.Data.Exclude,
+			SkipFiles:           skipFiles,
+			ModifiedBefore:      a.asOf,
+		})
+		if err != nil {
+			logging.LogErrorf("Failed to load usage entries from %s: %v", path, err)
+			continue
+		}
+
+		pathBlocks := analyzer.TransformToBlocks(result.Entries)
+		for _, limit := range analyzer.DetectLimits(result.RawEntries) {
+			for i := range pathBlocks {
+				if !limit.Timestamp.Before(pathBlocks[i].StartTime) && !limit.Timestamp.After(pathBlocks[i].EndTime) {
+					pathBlocks[i].LimitMessages = append(pathBlocks[i].LimitMessages, limit)
+					break
+				}
+			}
+		}
+		blocks = append(blocks, pathBlocks...)
+	}
+
+	return blocks, installedLimits(&a.config.Data, cacheDir), nil
+}
+
+// installedLimits returns the plan limits of the installed data bundle, if any
+func installedLimits(data *config.DataConfig, cacheDir string) map[string]models.PlanLimits {
+	if bundle := pricing.LoadInstalledDataBundle(data, cacheDir); bundle != nil {
+		return bundle.Limits
+	}
+	return nil
+}
+
+// planLimits returns the session limits of plan, preferring those of the installed data bundle
+func planLimits(installed map[string]models.PlanLimits, plan string) models.PlanLimits {
+	if limits, ok := installed[plan]; ok {
+		return limits
+	}
+	return models.GetPlanLimits(plan)
+}
+
+// scaleResult weights a sampled result so that sums over the sample estimate the full totals
+func scaleResult(result *models.AnalysisResult, weight float64) {
+	scale := func(n int) int {
+		return int(math.Round(float64(n) * weight))
+	}
+	result.InputTokens = scale(result.InputTokens)
+	result.OutputTokens = scale(result.OutputTokens)
+	result.CacheCreationTokens = scale(result.CacheCreationTokens)
+	result.CacheCreation1hTokens = scale(result.CacheCreation1hTokens)
+	result.CacheReadTokens = scale(result.CacheReadTokens)
+	result.TotalTokens = scale(result.TotalTokens)
+	result.CostUSD *= weight
+}
+
+// assignSessionIDs groups time-sorted results into 5-hour sessions. Sessions are detected from
+// activity rather than fixed windows so sessions crossing midnight or cache buckets stay intact.
+// Pinned starts from the session overrides file are respected as ground truth.
+func assignSessionIDs(results []models.AnalysisResult, pinned []time.Time) {
+	if len(results) == 0 {
+		return
+	}
+
+	entries := make([]models.UsageEntry, len(results))
+	for i := range results {
+		entries[i] = models.UsageEntry{Timestamp: results[i].Timestamp.UTC()}
+	}
+
+	detector := sessions.NewDetector()
+	detector.SetPinnedStarts(pinned)
+	boundaries := detector.DetectSessions(entries).Sessions
+
+	current := 0
+	for i := range results {
+		ts := results[i].Timestamp.UTC()
+		for current+1 < len(boundaries) && !ts.Before(boundaries[current+1].StartTime) {
+			current++
+		}
+		results[i].SessionID = sessionIDFor(boundaries[current].StartTime)
+		results[i].SessionConfidence = boundaries[current].Confidence
+	}
+}
+
+// sessionIDFor formats a session ID from its start; pinned starts may not be hour-aligned
+func sessionIDFor(start time.Time) string {
+	start = start.UTC()
+	if start.Minute() != 0 || start.Second() != 0 {
+		return fmt.Sprintf("session_%s", start.Format("2006-01-02_1504"))
+	}
+	return fmt.Sprintf("session_%s", start.Format("2006-01-02_15"))
+}
+
+// loadPinnedSessionStarts reads the configured session overrides file, if any
+func (a *Analyzer) loadPinnedSessionStarts() []time.Time {
+	path := a.config.Data.SessionOverrides
+	if path == "" {
+		return nil
+	}
+	path = config.ExpandHome(path)
+
+	pinned, err := sessions.LoadPinnedStarts(path)
+	if err != nil {
+		logging.LogWarnf("Ignoring session overrides: %v", err)
+		return nil
+	}
+	if len(pinned) > 0 {
+		logging.LogInfof("Loaded %d pinned session starts from %s", len(pinned), path)
+	}
+	return pinned
 }
 
 // GetSummaryStats returns summary statistics for the results
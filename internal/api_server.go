@@ -0,0 +1,381 @@
+package internal
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/penwyp/claudecat/calculations"
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/logging"
+	"github.com/penwyp/claudecat/models"
+	"github.com/penwyp/claudecat/orchestrator"
+)
+
+// apiShutdownTimeout bounds how long in-flight API requests may take to finish on shutdown
+const apiShutdownTimeout = 5 * time.Second
+
+// APIServer serves the monitor's latest data as JSON over HTTP
+type APIServer struct {
+	address  string
+	token    string
+	dataPath string // Data path of the monitor, reported to CLI clients
+	resolver *CostCenterResolver
+	loc      *time.Location
+	snapshot func() orchestrator.MonitoringData
+	now      func() time.Time
+
+	server   *http.Server
+	listener net.Listener
+}
+
+// APIBurnRate is the burn rate of the active session block
+type APIBurnRate struct {
+	Active                bool                    `json:"active"`
+	BlockID               string                  `json:"block_id,omitempty"`
+	BurnRate              *models.BurnRate        `json:"burn_rate,omitempty"`
+	Projection            *models.UsageProjection `json:"projection,omitempty"`
+	HourlyTokensPerMinute float64                 `json:"hourly_tokens_per_minute"` // Across all blocks over the last hour
+}
+
+// APICoverage describes the history the server holds, so that CLI clients can tell whether it
+// answers their query instead of parsing the files themselves
+type APICoverage struct {
+	DataPath    string    `json:"data_path,omitempty"` // Left out for callers limited to a cost center
+	Since       time.Time `json:"since"`               // Start of the loaded history; zero before the first load
+	LastRefresh time.Time `json:"last_refresh"`        // When the data was last refreshed
+}
+
+// NewAPIServer creates a server for the data returned by snapshot. It refuses to listen on a
+// non-loopback address without a token, since the data reveals projects and spending.
+func NewAPIServer(cfg *config.Config, snapshot func() orchestrator.MonitoringData) (*APIServer, error) {
+	if cfg.API.Token == "" && !isLoopbackAddress(cfg.API.Address) {
+		return nil, fmt.Errorf("refusing to serve on %s without api.token; set a token or bind to 127.0.0.1", cfg.API.Address)
+	}
+	resolver, err := NewCostCenterResolver(cfg.API)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := time.LoadLocation(cfg.App.Timezone)
+	if err != nil {
+		loc = time.Local
+	}
+	return &APIServer{
+		address:  cfg.API.Address,
+		token:    cfg.API.Token,
+		resolver: resolver,
+		loc:      loc,
+		snapshot: snapshot,
+		now:      time.Now,
+	}, nil
+}
+
+// Handler returns the HTTP handler serving the API endpoints
+func (s *APIServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/health", s.handleHealth)
+	mux.HandleFunc("GET /api/v1/monitoring", s.authorized(s.handleMonitoring))
+	mux.HandleFunc("GET /api/v1/blocks", s.authorized(s.handleBlocks))
+	mux.HandleFunc("GET /api/v1/daily", s.authorized(s.handleDaily))
+	mux.HandleFunc("GET /api/v1/burn-rate", s.authorized(s.handleBurnRate))
+	mux.HandleFunc("GET /api/v1/coverage", s.authorized(s.handleCoverage))
+	return mux
+}
+
+// Start binds the listen address and serves requests in the background
+func (s *APIServer) Start() error {
+	listener, err := net.Listen("tcp", s.address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.address, err)
+	}
+	s.listener = listener
+	s.server = &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			logging.LogErrorf("API server error: %v", err)
+		}
+	}()
+	logging.LogInfof("API server listening on %s", listener.Addr())
+	return nil
+}
+
+// Addr returns the address the server listens on, or the configured address before Start
+func (s *APIServer) Addr() string {
+	if s.listener != nil {
+		return s.listener.Addr().String()
+	}
+	return s.address
+}
+
+// Shutdown stops accepting connections and waits for in-flight requests to finish
+func (s *APIServer) Shutdown() error {
+	if s.server == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), apiShutdownTimeout)
+	defer cancel()
+	return s.server.Shutdown(ctx)
+}
+
+// authorized wraps a handler with the bearer token check and resolves the caller's project scope
+func (s *APIServer) authorized(handler func(http.ResponseWriter, *http.Request, ProjectScope)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.token != "" {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="claudecat"`)
+				writeAPIError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+				return
+			}
+		}
+		scope, err := s.resolver.Resolve(r)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, ErrUnknownCostCenter) || errors.Is(err, ErrUntrustedPeer) {
+				status = http.StatusForbidden
+			}
+			writeAPIError(w, status, err.Error())
+			return
+		}
+		handler(w, r, scope)
+	}
+}
+
+// handleHealth reports that the server is up; it needs no token so that it can serve as a probe
+func (s *APIServer) handleHealth(w http.ResponseWriter, r *http.Request) {
+	data := s.snapshot()
+	writeAPIJSON(w, map[string]any{
+		"status":       "healthy",
+		"time":         s.now().Format(time.RFC3339),
+		"last_refresh": data.Data.Metadata.GeneratedAt,
+	})
+}
+
+// handleMonitoring returns the latest monitoring data
+func (s *APIServer) handleMonitoring(w http.ResponseWriter, r *http.Request, scope ProjectScope) {
+	writeAPIJSON(w, scopeMonitoring(s.snapshot(), scope))
+}
+
+// handleBlocks returns the session blocks without gaps. Entries are only included with ?entries=true,
+// idle gaps with ?gaps=true, and ?active=true limits the result to the active block.
+func (s *APIServer) handleBlocks(w http.ResponseWriter, r *http.Request, scope ProjectScope) {
+	withEntries, _ := strconv.ParseBool(r.URL.Query().Get("entries"))
+	withGaps, _ := strconv.ParseBool(r.URL.Query().Get("gaps"))
+	activeOnly, _ := strconv.ParseBool(r.URL.Query().Get("active"))
+
+	blocks := make([]models.SessionBlock, 0)
+	for _, block := range scopeBlocks(s.snapshot().Data.Blocks, scope) {
+		if (block.IsGap && !withGaps) || (activeOnly && !block.IsActive) {
+			continue
+		}
+		if !withEntries {
+			block.Entries = nil
+		}
+		blocks = append(blocks, block)
+	}
+	writeAPIJSON(w, blocks)
+}
+
+// handleDaily returns usage totals per local day, oldest first; ?days=N limits the result to the last N days
+func (s *APIServer) handleDaily(w http.ResponseWriter, r *http.Request, scope ProjectScope) {
+	days := dailyTotals(scopeBlocks(s.snapshot().Data.Blocks, scope), s.loc)
+	if value := r.URL.Query().Get("days"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			writeAPIError(w, http.StatusBadRequest, "days must be a positive integer")
+			return
+		}
+		if limit < len(days) {
+			days = days[len(days)-limit:]
+		}
+	}
+	writeAPIJSON(w, days)
+}
+
+// handleBurnRate returns the burn rate and projection of the active block
+func (s *APIServer) handleBurnRate(w http.ResponseWriter, r *http.Request, scope ProjectScope) {
+	blocks := scopeBlocks(s.snapshot().Data.Blocks, scope)
+	now := s.now()
+	calculator := calculations.NewBurnRateCalculator()
+
+	result := APIBurnRate{HourlyTokensPerMinute: calculator.CalculateHourlyBurnRate(blocks, now)}
+	for _, block := range blocks {
+		if block.IsActive && !block.IsGap {
+			result.Active = true
+			result.BlockID = block.ID
+			result.BurnRate = calculator.CalculateBurnRate(block)
+			result.Projection = calculator.ProjectBlockUsageAt(block, now)
+			break
+		}
+	}
+	writeAPIJSON(w, result)
+}
+
+// handleCoverage returns the data path and the start of the history the server holds
+func (s *APIServer) handleCoverage(w http.ResponseWriter, r *http.Request, scope ProjectScope) {
+	metadata := s.snapshot().Data.Metadata
+	coverage := APICoverage{LastRefresh: metadata.GeneratedAt}
+	if scope.Unrestricted() {
+		coverage.DataPath = s.dataPath // Local paths reveal user and directory names to other teams
+	}
+	if hours, err := strconv.Atoi(metadata.HoursAnalyzed); err == nil && !metadata.GeneratedAt.IsZero() {
+		coverage.Since = metadata.GeneratedAt.Add(-time.Duration(hours) * time.Hour)
+	}
+	writeAPIJSON(w, coverage)
+}
+
+// scopeMonitoring returns data with the blocks outside scope removed. The counts, session,
+// realtime metrics and arguments describing the whole history are recomputed from the scoped
+// blocks or left out.
+func scopeMonitoring(data orchestrator.MonitoringData, scope ProjectScope) orchestrator.MonitoringData {
+	if scope.Unrestricted() {
+		return data
+	}
+
+	data.Data.Blocks = scopeBlocks(data.Data.Blocks, scope)
+	metadata := &data.Data.Metadata
+	metadata.EntriesProcessed, metadata.BlocksCreated, metadata.LimitsDetected = 0, len(data.Data.Blocks), 0
+	sessionID := ""
+	for _, block := range data.Data.Blocks {
+		metadata.EntriesProcessed += len(block.Entries)
+		if block.ID == data.SessionID {
+			sessionID = block.ID
+		}
+	}
+	data.SessionID = sessionID
+	data.SessionCount = len(data.Data.Blocks)
+	data.Args = nil
+	data.Metrics = nil
+	return data
+}
+
+// scopeBlocks returns the blocks with the entries of projects outside scope removed and their
+// totals recomputed. Blocks left without entries are dropped.
+func scopeBlocks(blocks []models.SessionBlock, scope ProjectScope) []models.SessionBlock {
+	if scope.Unrestricted() {
+		return blocks
+	}
+
+	scoped := make([]models.SessionBlock, 0, len(blocks))
+	for _, block := range blocks {
+		if block.IsGap {
+			continue
+		}
+		entries := make([]models.UsageEntry, 0, len(block.Entries))
+		for _, entry := range block.Entries {
+			if scope.AllowsProject(entry.Project) {
+				entries = append(entries, entry)
+			}
+		}
+		if len(entries) == 0 {
+			continue
+		}
+
+		block.Entries = entries
+		block.TokenCounts = models.TokenCounts{}
+		block.CostUSD = 0
+		block.Models = nil
+		block.ModelStats = make(map[string]models.ModelStat)
+		block.PerModelStats = nil
+		block.BurnRate, block.BurnRateSnapshot, block.ProjectionData = nil, nil, nil
+		for _, entry := range entries {
+			block.TokenCounts.InputTokens += entry.InputTokens
+			block.TokenCounts.OutputTokens += entry.OutputTokens
+			block.TokenCounts.CacheCreationTokens += entry.CacheCreationTokens
+			block.TokenCounts.CacheReadTokens += entry.CacheReadTokens
+			block.CostUSD += entry.CostUSD
+
+			model := models.NormalizeModelName(entry.Model)
+			stat, seen := block.ModelStats[model]
+			if !seen {
+				block.Models = append(block.Models, model)
+			}
+			stat.InputTokens += entry.InputTokens
+			stat.OutputTokens += entry.OutputTokens
+			stat.CacheCreationTokens += entry.CacheCreationTokens
+			stat.CacheReadTokens += entry.CacheReadTokens
+			stat.TotalTokens += entry.TotalTokens
+			stat.Cost += entry.CostUSD
+			block.ModelStats[model] = stat
+		}
+		last := entries[len(entries)-1].Timestamp
+		block.ActualEndTime = &last
+		block.SentMessagesCount = len(entries)
+		block.TotalCost = block.CostUSD
+		block.TotalTokens = block.TokenCounts.TotalTokens()
+		scoped = append(scoped, block)
+	}
+	return scoped
+}
+
+// dailyTotals sums the entries of blocks per local day in loc, oldest first
+func dailyTotals(blocks []models.SessionBlock, loc *time.Location) []calculations.DayTotals {
+	byDay := make(map[time.Time]*calculations.DayTotals)
+	for _, block := range blocks {
+		if block.IsGap {
+			continue
+		}
+		for _, entry := range block.Entries {
+			day := calculations.StartOfDay(entry.Timestamp, loc)
+			totals, ok := byDay[day]
+			if !ok {
+				totals = &calculations.DayTotals{Date: day}
+				byDay[day] = totals
+			}
+			totals.Entries++
+			totals.Tokens += entry.CalculateTotalTokens()
+			totals.Cost += entry.CostUSD
+		}
+	}
+
+	days := make([]calculations.DayTotals, 0, len(byDay))
+	for _, totals := range byDay {
+		days = append(days, *totals)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Date.Before(days[j].Date) })
+	return days
+}
+
+// isLoopbackAddress reports whether a host:port address only accepts local connections
+func isLoopbackAddress(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// writeAPIJSON writes value as a JSON response
+func writeAPIJSON(w http.ResponseWriter, value any) {
+	data, err := sonic.Marshal(value)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// writeAPIError writes a JSON error response
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	data, _ := sonic.Marshal(map[string]string{"error": message})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(data)
+}
@@ -0,0 +1,215 @@
+package internal
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/calculations"
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/models"
+	"github.com/penwyp/claudecat/orchestrator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAPIServer(t *testing.T, api config.APIConfig) *APIServer {
+	cfg := config.DefaultConfig()
+	cfg.App.Timezone = "UTC"
+	cfg.API = api
+
+	start := time.Date(2025, 6, 1, 22, 0, 0, 0, time.UTC)
+	block := models.SessionBlock{
+		ID:        "block-1",
+		StartTime: start,
+		EndTime:   start.Add(5 * time.Hour),
+		IsActive:  true,
+		Entries: []models.UsageEntry{
+			{Timestamp: start.Add(30 * time.Minute), Model: "claude-sonnet-4-20250514", InputTokens: 100, OutputTokens: 50, TotalTokens: 150, CostUSD: 1, Project: "webapp"},
+			{Timestamp: start.Add(150 * time.Minute), Model: "claude-opus-4-20250514", InputTokens: 200, TotalTokens: 200, CostUSD: 4, Project: "infra"},
+		},
+		TokenCounts: models.TokenCounts{InputTokens: 300, OutputTokens: 50},
+		CostUSD:     5,
+	}
+	data := orchestrator.MonitoringData{
+		Data: orchestrator.AnalysisResult{
+			Blocks:   []models.SessionBlock{block},
+			Metadata: orchestrator.AnalysisMetadata{GeneratedAt: start.Add(3 * time.Hour), HoursAnalyzed: "24", EntriesProcessed: 2, BlocksCreated: 4},
+		},
+		Args:         map[string]any{"data_path": "/home/alice/.claude/projects"},
+		SessionID:    "block-1",
+		SessionCount: 4,
+		Metrics:      &calculations.RealtimeMetrics{CurrentTokens: 350, CurrentCost: 5},
+	}
+
+	server, err := NewAPIServer(cfg, func() orchestrator.MonitoringData { return data })
+	require.NoError(t, err)
+	server.now = func() time.Time { return start.Add(3 * time.Hour) }
+	return server
+}
+
+func getAPI(t *testing.T, server *APIServer, path string, header http.Header, into any) int {
+	req := httptest.NewRequest("GET", path, nil)
+	req.RemoteAddr = "10.0.0.1:4000"
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, req)
+	if into != nil && rec.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), into))
+	}
+	return rec.Code
+}
+
+func TestAPIServer_Endpoints(t *testing.T) {
+	server := newTestAPIServer(t, config.APIConfig{Address: "127.0.0.1:0"})
+
+	var blocks []models.SessionBlock
+	require.Equal(t, http.StatusOK, getAPI(t, server, "/api/v1/blocks", nil, &blocks))
+	require.Len(t, blocks, 1)
+	assert.Empty(t, blocks[0].Entries)
+	require.Equal(t, http.StatusOK, getAPI(t, server, "/api/v1/blocks?entries=true", nil, &blocks))
+	assert.Len(t, blocks[0].Entries, 2)
+
+	// The block spans midnight, so its entries fall on two days
+	var days []calculations.DayTotals
+	require.Equal(t, http.StatusOK, getAPI(t, server, "/api/v1/daily", nil, &days))
+	require.Len(t, days, 2)
+	assert.Equal(t, 150, days[0].Tokens)
+	assert.Equal(t, 4.0, days[1].Cost)
+	require.Equal(t, http.StatusOK, getAPI(t, server, "/api/v1/daily?days=1", nil, &days))
+	require.Len(t, days, 1)
+	assert.Equal(t, 2, days[0].Date.Day())
+	assert.Equal(t, http.StatusBadRequest, getAPI(t, server, "/api/v1/daily?days=0", nil, nil))
+
+	var burn APIBurnRate
+	require.Equal(t, http.StatusOK, getAPI(t, server, "/api/v1/burn-rate", nil, &burn))
+	assert.True(t, burn.Active)
+	assert.Equal(t, "block-1", burn.BlockID)
+	require.NotNil(t, burn.BurnRate)
+	assert.Greater(t, burn.BurnRate.TokensPerMinute, 0.0)
+
+	assert.Equal(t, http.StatusMethodNotAllowed, func() int {
+		req := httptest.NewRequest("POST", "/api/v1/blocks", nil)
+		rec := httptest.NewRecorder()
+		server.Handler().ServeHTTP(rec, req)
+		return rec.Code
+	}())
+}
+
+func TestAPIServer_Auth(t *testing.T) {
+	server := newTestAPIServer(t, config.APIConfig{Address: "0.0.0.0:8787", Token: "s3cret"})
+
+	assert.Equal(t, http.StatusUnauthorized, getAPI(t, server, "/api/v1/monitoring", nil, nil))
+	assert.Equal(t, http.StatusUnauthorized, getAPI(t, server, "/api/v1/monitoring", http.Header{"Authorization": {"Bearer wrong"}}, nil))
+	assert.Equal(t, http.StatusOK, getAPI(t, server, "/api/v1/monitoring", http.Header{"Authorization": {"Bearer s3cret"}}, nil))
+	assert.Equal(t, http.StatusOK, getAPI(t, server, "/api/v1/health", nil, nil))
+
+	// A public address without a token is refused
+	_, err := NewAPIServer(&config.Config{API: config.APIConfig{Address: "0.0.0.0:8787"}}, nil)
+	assert.Error(t, err)
+}
+
+func TestAPIServer_CostCenterScope(t *testing.T) {
+	server := newTestAPIServer(t, config.APIConfig{
+		Address:        "127.0.0.1:0",
+		TrustedProxies: []string{"10.0.0.0/8"},
+		IdentityHeader: "X-Forwarded-User",
+		CostCenters:    []config.CostCenterConfig{{Name: "web", Identities: []string{"alice"}, Projects: []string{"webapp"}}},
+	})
+
+	var data orchestrator.MonitoringData
+	require.Equal(t, http.StatusOK, getAPI(t, server, "/api/v1/monitoring", http.Header{"X-Forwarded-User": {"alice"}}, &data))
+	require.Len(t, data.Data.Blocks, 1)
+	block := data.Data.Blocks[0]
+	require.Len(t, block.Entries, 1)
+	assert.Equal(t, "webapp", block.Entries[0].Project)
+	assert.Equal(t, 1.0, block.CostUSD)
+	assert.Equal(t, 150, block.TokenCounts.TotalTokens())
+	assert.Equal(t, []string{"claude-sonnet-4-20250514"}, block.Models)
+
+	assert.Equal(t, http.StatusForbidden, getAPI(t, server, "/api/v1/daily", http.Header{"X-Forwarded-User": {"mallory"}}, nil))
+}
+
+func TestAPIServer_CostCenterScopeHidesHistory(t *testing.T) {
+	server := newTestAPIServer(t, config.APIConfig{
+		Address:        "127.0.0.1:0",
+		TrustedProxies: []string{"10.0.0.0/8"},
+		IdentityHeader: "X-Forwarded-User",
+		CostCenters: []config.CostCenterConfig{
+			{Name: "web", Identities: []string{"alice"}, Projects: []string{"webapp"}},
+			{Name: "docs", Identities: []string{"bob"}, Projects: []string{"docs"}},
+		},
+	})
+	server.dataPath = "/home/alice/.claude/projects"
+
+	tests := []struct {
+		name         string
+		user         string
+		entries      int
+		blocks       int
+		sessionID    string
+		sessionCount int
+	}{
+		{"tenant with usage in the active block", "alice", 1, 1, "block-1", 1},
+		{"tenant without usage", "bob", 0, 0, "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{"X-Forwarded-User": {tt.user}}
+
+			var data orchestrator.MonitoringData
+			require.Equal(t, http.StatusOK, getAPI(t, server, "/api/v1/monitoring", header, &data))
+			assert.Equal(t, tt.entries, data.Data.Metadata.EntriesProcessed)
+			assert.Equal(t, tt.blocks, data.Data.Metadata.BlocksCreated)
+			assert.Equal(t, tt.sessionID, data.SessionID)
+			assert.Equal(t, tt.sessionCount, data.SessionCount)
+			assert.Nil(t, data.Args)
+			assert.Nil(t, data.Metrics, "the realtime metrics cover every project")
+
+			var coverage APICoverage
+			require.Equal(t, http.StatusOK, getAPI(t, server, "/api/v1/coverage", header, &coverage))
+			assert.Empty(t, coverage.DataPath)
+			assert.False(t, coverage.Since.IsZero())
+		})
+	}
+}
+
+func TestAPIServer_CostCenterPeers(t *testing.T) {
+	server := newTestAPIServer(t, config.APIConfig{
+		Address:        "127.0.0.1:0",
+		TrustedProxies: []string{"10.0.0.0/8"},
+		IdentityHeader: "X-Forwarded-User",
+		CostCenters:    []config.CostCenterConfig{{Name: "web", Identities: []string{"alice"}, Projects: []string{"webapp"}}},
+	})
+	server.dataPath = "/home/alice/.claude/projects"
+
+	tests := []struct {
+		name     string
+		remote   string
+		status   int
+		dataPath string
+	}{
+		{"local client", "127.0.0.1:4000", http.StatusOK, "/home/alice/.claude/projects"},
+		{"untrusted peer", "192.168.1.5:4000", http.StatusForbidden, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/coverage", nil)
+			req.RemoteAddr = tt.remote
+			req.Header.Set("X-Forwarded-User", "alice")
+			rec := httptest.NewRecorder()
+			server.Handler().ServeHTTP(rec, req)
+			require.Equal(t, tt.status, rec.Code)
+
+			if tt.status == http.StatusOK {
+				var coverage APICoverage
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &coverage))
+				assert.Equal(t, tt.dataPath, coverage.DataPath)
+			}
+		})
+	}
+}
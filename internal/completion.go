@@ -0,0 +1,172 @@
+package internal
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/penwyp/claudecat/cache"
+	"github.com/penwyp/claudecat/fileio"
+	"github.com/penwyp/claudecat/humanize"
+	"github.com/penwyp/claudecat/sessions"
+)
+
+// CompletionSessionDays is how far back session IDs are offered for completion
+const CompletionSessionDays = 14
+
+// Completion is a value offered when completing a command line argument
+type Completion struct {
+	Value       string
+	Description string
+}
+
+// usageTotals accumulates the usage behind a completion so that the busiest values come first
+type usageTotals struct {
+	entries int
+	cost    float64
+}
+
+// ModelCompletions returns the models of the cached usage files under paths, most expensive first
+func ModelCompletions(summaries []*cache.FileSummary, paths []string) []Completion {
+	totals := make(map[string]*usageTotals)
+	for _, summary := range summariesUnder(summaries, paths) {
+		for key, stat := range summary.ModelStats {
+			model := stat.Model
+			if model == "" {
+				model = key
+			}
+			total := totalsFor(totals, model)
+			total.entries += stat.EntryCount
+			total.cost += stat.TotalCost
+		}
+	}
+	return usageCompletions(totals)
+}
+
+// ProjectCompletions returns the projects of the cached usage files under paths, most expensive first
+func ProjectCompletions(summaries []*cache.FileSummary, paths []string) []Completion {
+	totals := make(map[string]*usageTotals)
+	for _, summary := range summariesUnder(summaries, paths) {
+		project := fileio.ProjectOf(summary.AbsolutePath)
+		if project == "" || project == "." {
+			continue
+		}
+		total := totalsFor(totals, project)
+		total.entries += summary.EntryCount
+		total.cost += summary.TotalCost
+	}
+	return usageCompletions(totals)
+}
+
+// SessionCompletions returns the IDs of the sessions of the last CompletionSessionDays days, most
+// recent first, described by their start, cost and tags
+func (a *Analyzer) SessionCompletions(paths []string, now time.Time, loc *time.Location) ([]Completion, error) {
+	filter := sessions.ListFilter{From: now.AddDate(0, 0, -CompletionSessionDays), HideGaps: true}
+	summaries, err := a.Sessions(paths, filter, now)
+	if err != nil {
+		return nil, err
+	}
+	completions := make([]Completion, 0, len(summaries))
+	for i := len(summaries) - 1; i >= 0; i-- {
+		summary := summaries[i]
+		description := summary.Start.In(loc).Format("2006-01-02 15:04") + ", " + humanize.Cost(summary.CostUSD)
+		if len(summary.Tags) > 0 {
+			description += ", " + strings.Join(summary.Tags, ",")
+		}
+		completions = append(completions, Completion{Value: sessionIDFor(summary.Start), Description: description})
+	}
+	return completions, nil
+}
+
+// LabelCompletions returns the labels in use, most used first
+func (t SessionTagSet) LabelCompletions() []Completion {
+	counts := make(map[string]int)
+	for _, session := range t {
+		for _, label := range session.Labels {
+			counts[label]++
+		}
+	}
+	completions := make([]Completion, 0, len(counts))
+	for label, count := range counts {
+		completions = append(completions, Completion{Value: label, Description: fmt.Sprintf("%d sessions", count)})
+	}
+	sort.Slice(completions, func(i, j int) bool {
+		if counts[completions[i].Value] != counts[completions[j].Value] {
+			return counts[completions[i].Value] > counts[completions[j].Value]
+		}
+		return completions[i].Value < completions[j].Value
+	})
+	return completions
+}
+
+// AlertSessionCompletions returns the sessions that raised the given alerts, most recent first
+func AlertSessionCompletions(records []AlertRecord) []Completion {
+	seen := make(map[string]bool)
+	var completions []Completion
+	for i := len(records) - 1; i >= 0; i-- {
+		record := records[i]
+		if record.SessionID == "" || seen[record.SessionID] {
+			continue
+		}
+		seen[record.SessionID] = true
+		completions = append(completions, Completion{
+			Value:       record.SessionID,
+			Description: fmt.Sprintf("last alert %s", record.Time.Local().Format("2006-01-02 15:04")),
+		})
+	}
+	return completions
+}
+
+// summariesUnder returns the summaries of usage files beneath one of paths
+func summariesUnder(summaries []*cache.FileSummary, paths []string) []*cache.FileSummary {
+	roots := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if abs, err := filepath.Abs(path); err == nil {
+			roots = append(roots, abs)
+		}
+	}
+	var under []*cache.FileSummary
+	for _, summary := range summaries {
+		if summary.HasNoAssistantMessages {
+			continue
+		}
+		for _, root := range roots {
+			if rel, err := filepath.Rel(root, summary.AbsolutePath); err == nil && !strings.HasPrefix(rel, "..") {
+				under = append(under, summary)
+				break
+			}
+		}
+	}
+	return under
+}
+
+// totalsFor returns the totals of key, adding them when missing
+func totalsFor(totals map[string]*usageTotals, key string) *usageTotals {
+	total, ok := totals[key]
+	if !ok {
+		total = &usageTotals{}
+		totals[key] = total
+	}
+	return total
+}
+
+// usageCompletions lists the keys of totals by cost, then name
+func usageCompletions(totals map[string]*usageTotals) []Completion {
+	completions := make([]Completion, 0, len(totals))
+	for key, total := range totals {
+		completions = append(completions, Completion{
+			Value:       key,
+			Description: fmt.Sprintf("%s, %d entries", humanize.Cost(total.cost), total.entries),
+		})
+	}
+	sort.Slice(completions, func(i, j int) bool {
+		a, b := totals[completions[i].Value], totals[completions[j].Value]
+		if a.cost != b.cost {
+			return a.cost > b.cost
+		}
+		return completions[i].Value < completions[j].Value
+	})
+	return completions
+}
@@ -0,0 +1,76 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheCompletions(t *testing.T) {
+	summaries := []*cache.FileSummary{
+		{
+			AbsolutePath: "/data/projects/-Users-x-api/s1.jsonl",
+			EntryCount:   3,
+			TotalCost:    1.5,
+			ModelStats: map[string]cache.ModelStat{
+				"claude-opus-4-20250514":   {Model: "claude-opus-4-20250514", EntryCount: 1, TotalCost: 1.2},
+				"claude-sonnet-4-20250514": {Model: "claude-sonnet-4-20250514", EntryCount: 2, TotalCost: 0.3},
+			},
+		},
+		{
+			AbsolutePath: "/data/projects/-Users-x-web/s2.jsonl",
+			EntryCount:   4,
+			TotalCost:    0.4,
+			ModelStats: map[string]cache.ModelStat{
+				"claude-sonnet-4-20250514": {Model: "claude-sonnet-4-20250514", EntryCount: 4, TotalCost: 0.4},
+			},
+		},
+		{AbsolutePath: "/data/projects/-Users-x-docs/s3.jsonl", HasNoAssistantMessages: true},
+		{
+			AbsolutePath: "/elsewhere/-Users-x-other/s4.jsonl",
+			EntryCount:   1,
+			TotalCost:    9,
+			ModelStats:   map[string]cache.ModelStat{"claude-haiku": {EntryCount: 1, TotalCost: 9}},
+		},
+	}
+	paths := []string{"/data/projects"}
+
+	assert.Equal(t, []Completion{
+		{Value: "claude-opus-4-20250514", Description: "$1.20, 1 entries"},
+		{Value: "claude-sonnet-4-20250514", Description: "$0.70, 6 entries"},
+	}, ModelCompletions(summaries, paths))
+	assert.Equal(t, []Completion{
+		{Value: "api", Description: "$1.50, 3 entries"},
+		{Value: "web", Description: "$0.40, 4 entries"},
+	}, ProjectCompletions(summaries, paths))
+	assert.Equal(t, "claude-haiku", ModelCompletions(summaries, []string{"/elsewhere"})[0].Value, "the key names models without one")
+}
+
+func TestLabelCompletions(t *testing.T) {
+	tags := make(SessionTagSet)
+	start := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	tags.Tag(start, []string{"feature-x", "bug-12"}, nil)
+	tags.Tag(start.Add(5*time.Hour), []string{"feature-x"}, nil)
+
+	assert.Equal(t, []Completion{
+		{Value: "feature-x", Description: "2 sessions"},
+		{Value: "bug-12", Description: "1 sessions"},
+	}, tags.LabelCompletions())
+}
+
+func TestAlertSessionCompletions(t *testing.T) {
+	at := time.Date(2025, 6, 1, 10, 0, 0, 0, time.Local)
+	records := []AlertRecord{
+		{Time: at, SessionID: "s1"},
+		{Time: at.Add(time.Hour)},
+		{Time: at.Add(2 * time.Hour), SessionID: "s2"},
+		{Time: at.Add(3 * time.Hour), SessionID: "s1"},
+	}
+
+	assert.Equal(t, []Completion{
+		{Value: "s1", Description: "last alert 2025-06-01 13:00"},
+		{Value: "s2", Description: "last alert 2025-06-01 12:00"},
+	}, AlertSessionCompletions(records))
+}
@@ -0,0 +1,372 @@
+//go:build !lite
+
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/penwyp/claudecat/calculations"
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/events"
+	"github.com/penwyp/claudecat/fileio"
+	"github.com/penwyp/claudecat/humanize"
+	"github.com/penwyp/claudecat/logging"
+	"github.com/penwyp/claudecat/models/pricing"
+	"github.com/penwyp/claudecat/orchestrator"
+	"github.com/penwyp/claudecat/output"
+	"github.com/penwyp/claudecat/terminal"
+)
+
+// LiteBuild reports whether this binary was built without the interactive monitor
+const LiteBuild = false
+
+// burnAlarmFlashDuration is how long the screen stays in reverse video when the burn rate alarm flashes
+const burnAlarmFlashDuration = 200 * time.Millisecond
+
+// consoleUI renders the interactive monitor
+type consoleUI = output.ConsoleFormatter
+
+// initConsole creates and configures the console formatter
+func (ea *EnhancedApplication) initConsole() {
+	// Initialize console formatter
+	ea.formatter = output.NewConsoleFormatter(
+		ea.config.Subscription.Plan,
+		ea.config.UI.Timezone,
+		ea.config.UI.TimeFormat,
+	)
+	// Plan limits from a verified data bundle override the built-in tables
+	cacheDir := config.ExpandHome(ea.config.Cache.Dir)
+	if bundle := pricing.LoadInstalledDataBundle(&ea.config.Data, cacheDir); bundle != nil {
+		ea.formatter.SetPlanLimits(bundle.Limits)
+	}
+	if mode, err := calculations.ParseSmoothingMode(ea.config.UI.BurnRateSmoothing); err == nil {
+		ea.formatter.SetSmoothing(mode)
+	} else {
+		logging.LogWarnf("Ignoring burn rate smoothing: %v", err)
+	}
+	if ea.config.UI.BurnAlarmThreshold > 0 {
+		ea.formatter.SetBurnAlarm(calculations.NewBurnRateAlarm(ea.config.UI.BurnAlarmThreshold, ea.config.UI.BurnAlarmDuration))
+	}
+	ea.formatter.SetTheme(ea.config.UI.Theme)
+	ea.formatter.RollDay(ea.orchestrator.Today())
+}
+
+// rollDay resets the daily totals shown in the console when local midnight passes
+func (ea *EnhancedApplication) rollDay(rollover orchestrator.DayRollover) {
+	ea.formatter.RollDay(rollover.Day)
+}
+
+// soundBurnAlarm rings the terminal bell and/or briefly flashes the screen in reverse video
+func (ea *EnhancedApplication) soundBurnAlarm() {
+	style := ea.config.UI.BurnAlarmStyle
+	if style == "" || style == "bell" || style == "both" {
+		fmt.Print("\a")
+	}
+	if style == "" || style == "flash" || style == "both" {
+		fmt.Print("\033[?5h")
+		time.AfterFunc(burnAlarmFlashDuration, func() { fmt.Print("\033[?5l") })
+	}
+}
+
+// showNotice displays a notice as a toast in the console
+func (ea *EnhancedApplication) showNotice(notice events.Notice) {
+	ea.formatter.Notify(notice.Level, notice.Message)
+}
+
+// runInteractive starts the console output application
+func (ea *EnhancedApplication) runInteractive() error {
+	ea.logger.Info("Starting interactive console mode")
+
+	// Clear screen initially
+	fmt.Print("\033[H\033[2J")
+
+	// Create ticker for refresh
+	ticker := time.NewTicker(ea.redrawInterval())
+	defer ticker.Stop()
+
+	// Keys toggle views between refreshes; ':' opens the command palette
+	keys, restoreTerminal := readKeys()
+	defer restoreTerminal()
+	var view PaletteView
+	panels := make(chan *output.ReportPanel, 1)
+
+	// Draw right away, showing the restored snapshot if the initial load is still running
+	ea.render()
+
+	for {
+		select {
+		case <-ea.ctx.Done():
+			return nil
+		case key := <-keys:
+			palette := ea.formatter.Palette()
+			switch {
+			case palette.IsOpen():
+				if line, submitted := palette.Type(key); submitted && line != "" {
+					ea.runPaletteCommand(line, &view, panels)
+				}
+			case key == ':':
+				palette.Open()
+			case key == 'f' || key == 'F':
+				ea.formatter.SetFocusMode(!ea.formatter.FocusMode())
+			default:
+				continue
+			}
+			ea.render()
+		case panel := <-panels:
+			ea.formatter.SetPanel(panel)
+			ea.render()
+		case <-ea.powerChanged:
+			ticker.Reset(ea.redrawInterval())
+			ea.render()
+		case <-ticker.C:
+			ea.render()
+		}
+	}
+}
+
+// redrawInterval returns how often the monitor is redrawn, slower in low-power mode
+func (ea *EnhancedApplication) redrawInterval() time.Duration {
+	refreshRate := ea.config.UI.RefreshRate
+	if refreshRate <= 0 {
+		refreshRate = time.Second
+	}
+	if ea.lowPower.Load() {
+		return lowPowerInterval(refreshRate, ea.config.UI.LowPowerRefreshRate)
+	}
+	return refreshRate
+}
+
+// render redraws the monitor with the latest data
+func (ea *EnhancedApplication) render() {
+	// Clear screen and move cursor to top
+	fmt.Print("\033[H\033[2J")
+
+	// Get current data
+	ea.dataMutex.RLock()
+	metrics := ea.currentMetrics
+	blocks := ea.currentData.Data.Blocks
+	restoredAt := ea.restoredAt
+	ea.dataMutex.RUnlock()
+	ea.formatter.SetRefreshing(restoredAt)
+
+	// Surface cache warm-up progress while the initial load is running
+	progress := ea.orchestrator.GetLoadProgress()
+	if progress.Done() {
+		ea.formatter.SetWarmupProgress(0, 0, 0)
+	} else {
+		ea.formatter.SetWarmupProgress(progress.ProcessedFiles, progress.TotalFiles, progress.ETA)
+	}
+	ea.formatter.SetActiveFiles(ea.orchestrator.GetActiveFiles())
+	ea.formatter.SetLowPower(ea.lowPower.Load())
+	if ea.config.Debug.Enabled && ea.watchdog != nil {
+		ea.formatter.SetDebugPanel(ea.watchdog.PanelLines())
+	}
+
+	// Format and print
+	output := ea.formatter.Format(metrics, blocks)
+	fmt.Print(output)
+	if rate, fired := ea.formatter.TakeBurnAlarm(); fired {
+		ea.soundBurnAlarm()
+		ea.recordAlert(events.Notice{
+			Level:   events.NoticeWarning,
+			Message: fmt.Sprintf("Burn rate %.0f tokens/min above %.0f", rate, ea.formatter.BurnAlarmThreshold()),
+			Crossing: &events.Crossing{
+				Metric:    AlertMetricBurnRate,
+				Value:     rate,
+				Threshold: ea.formatter.BurnAlarmThreshold(),
+				SessionID: activeSessionID(blocks),
+			},
+		})
+	}
+}
+
+// runPaletteCommand runs a command entered in the palette; reports are built in the background and
+// sent on panels
+func (ea *EnhancedApplication) runPaletteCommand(line string, view *PaletteView, panels chan *output.ReportPanel) {
+	command, err := ParsePaletteCommand(line)
+	if err != nil {
+		ea.formatter.Notify(events.NoticeError, err.Error())
+		return
+	}
+
+	switch command.Name {
+	case "range", "group":
+		if command.Name == "range" {
+			view.Range = command.Args[0]
+		} else {
+			view.GroupBy = command.Args[0]
+		}
+		if view.Range == "" {
+			view.Range = "today"
+		}
+		if view.GroupBy == "" {
+			view.GroupBy = "model"
+		}
+		go ea.buildPalettePanel(*view, panels)
+	case "export":
+		go ea.exportPaletteView(*view, command.Args[0], config.ExpandHome(command.Args[1]))
+	case "theme":
+		ea.config.UI.Theme = command.Args[0]
+		ea.formatter.SetTheme(command.Args[0])
+		ea.formatter.Notify(events.NoticeInfo, "Theme set to "+command.Args[0])
+	case "about":
+		go ea.buildAboutPanel(panels)
+	case "close":
+		*view = PaletteView{}
+		ea.formatter.SetPanel(nil)
+	case "help":
+		panel := &output.ReportPanel{Title: "Commands", Headers: []string{"Command", "Usage"}}
+		for _, usage := range strings.Split(PaletteHelp(), "  ") {
+			name, _, _ := strings.Cut(strings.TrimPrefix(usage, ":"), " ")
+			panel.Rows = append(panel.Rows, []string{":" + name, usage})
+		}
+		ea.formatter.SetPanel(panel)
+	}
+}
+
+// paletteConfig returns the configuration for palette reports, reading the monitored data path
+func (ea *EnhancedApplication) paletteConfig() *config.Config {
+	cfg := *ea.config
+	if len(cfg.Data.Paths) == 0 {
+		cfg.Data.Paths = []string{ea.getDataPath()}
+	}
+	return &cfg
+}
+
+// paletteLocation returns the timezone of palette reports, the one used by the CLI
+func (ea *EnhancedApplication) paletteLocation() *time.Location {
+	loc, err := time.LoadLocation(ea.config.App.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// buildPalettePanel analyzes usage like the analyze command and sends the view's report on panels
+func (ea *EnhancedApplication) buildPalettePanel(view PaletteView, panels chan *output.ReportPanel) {
+	ea.formatter.Notify(events.NoticeInfo, fmt.Sprintf("Loading usage for %s by %s…", view.Range, view.GroupBy))
+	cfg := ea.paletteConfig()
+	analyzer, err := NewAnalyzer(cfg)
+	if err != nil {
+		ea.formatter.Notify(events.NoticeError, err.Error())
+		return
+	}
+	analyzer.SetIncludeTools(view.GroupBy == "tool")
+	results, err := analyzer.Analyze(cfg.Data.Paths)
+	if err != nil {
+		ea.formatter.Notify(events.NoticeError, fmt.Sprintf("Analysis failed: %v", err))
+		return
+	}
+
+	now, loc := time.Now(), ea.paletteLocation()
+	report, err := view.Report(results, now, loc)
+	if err != nil {
+		ea.formatter.Notify(events.NoticeError, err.Error())
+		return
+	}
+	title := fmt.Sprintf("Usage by %s, all time", view.GroupBy)
+	if since, _ := view.Since(now, loc); !since.IsZero() {
+		title = fmt.Sprintf("Usage by %s since %s", view.GroupBy, since.In(loc).Format("2006-01-02 15:04"))
+	}
+	panel := &output.ReportPanel{
+		Title:   title,
+		Headers: []string{strings.ToUpper(view.GroupBy[:1]) + view.GroupBy[1:], "Entries", "Tokens", "Cost"},
+	}
+	var links *fileio.LogIndex
+	if view.GroupBy == "project" && terminal.Hyperlinks() {
+		links = fileio.IndexLogs(cfg.Data.Paths)
+	}
+	for _, group := range report {
+		label := group.Result.GroupKey
+		if dir := links.ProjectDir(label); dir != "" {
+			label = terminal.Link(terminal.FileURL(dir), label)
+		}
+		panel.Rows = append(panel.Rows, []string{
+			label,
+			strconv.Itoa(group.Result.Count),
+			humanize.Compact(group.Result.TotalTokens),
+			humanize.Cost(group.Result.CostUSD),
+		})
+	}
+
+	sendPalettePanel(panels, panel)
+}
+
+// buildAboutPanel computes the lifetime milestones of all usage and sends them on panels
+func (ea *EnhancedApplication) buildAboutPanel(panels chan *output.ReportPanel) {
+	ea.formatter.Notify(events.NoticeInfo, "Loading lifetime usage…")
+	cfg := ea.paletteConfig()
+	analyzer, err := NewAnalyzer(cfg)
+	if err != nil {
+		ea.formatter.Notify(events.NoticeError, err.Error())
+		return
+	}
+	milestones, err := analyzer.Milestones(cfg.Data.Paths, time.Now())
+	if err != nil {
+		ea.formatter.Notify(events.NoticeError, fmt.Sprintf("Analysis failed: %v", err))
+		return
+	}
+
+	loc := ea.paletteLocation()
+	panel := &output.ReportPanel{Title: "About your usage", Headers: []string{"Milestone", "Value"}}
+	if milestones.ActiveDays == 0 {
+		panel.Rows = append(panel.Rows, []string{"First use", "no usage yet"})
+		sendPalettePanel(panels, panel)
+		return
+	}
+	panel.Rows = append(panel.Rows,
+		[]string{"First use", milestones.FirstUse.In(loc).Format("2006-01-02")},
+		[]string{"Active days", strconv.Itoa(milestones.ActiveDays)},
+		[]string{"Lifetime tokens", humanize.Compact(milestones.TotalTokens)},
+		[]string{"Lifetime cost", humanize.Cost(milestones.TotalCost)},
+		[]string{"Longest streak", fmt.Sprintf("%d days", milestones.LongestStreak.Days)},
+		[]string{"Current streak", fmt.Sprintf("%d days", milestones.CurrentStreak.Days)},
+	)
+	for _, badge := range milestones.Badges {
+		if badge.Reached {
+			panel.Rows = append(panel.Rows, []string{"Badge " + badge.Name, badge.ReachedOn.Format("2006-01-02")})
+		}
+	}
+	if next, progress, ok := milestones.NextBadge(); ok {
+		panel.Rows = append(panel.Rows, []string{"Next badge " + next.Name, fmt.Sprintf("%.0f%%", progress*100)})
+	}
+	sendPalettePanel(panels, panel)
+}
+
+// sendPalettePanel sends panel to the monitor; only the latest report is kept when several are
+// built before the monitor takes one
+func sendPalettePanel(panels chan *output.ReportPanel, panel *output.ReportPanel) {
+	select {
+	case <-panels:
+	default:
+	}
+	select {
+	case panels <- panel:
+	default:
+	}
+}
+
+// exportPaletteView exports the usage within the view's range, all usage without a range
+func (ea *EnhancedApplication) exportPaletteView(view PaletteView, format, path string) {
+	options := ExportOptions{Format: format, TimeRange: "all", OutputFile: path}
+	if view.Range != "" {
+		if since, err := view.Since(time.Now(), ea.paletteLocation()); err == nil && !since.IsZero() {
+			options.TimeRange = "custom"
+			options.FromTime = since.UTC().Format(time.RFC3339)
+		}
+	}
+	exporter, err := NewExporter(ea.paletteConfig())
+	if err != nil {
+		ea.formatter.Notify(events.NoticeError, err.Error())
+		return
+	}
+	result, err := exporter.Export(options)
+	if err != nil {
+		ea.formatter.Notify(events.NoticeError, fmt.Sprintf("Export failed: %v", err))
+		return
+	}
+	ea.formatter.Notify(events.NoticeInfo, fmt.Sprintf("Exported %d records to %s", result.RecordCount, path))
+}
@@ -0,0 +1,31 @@
+//go:build lite
+
+package internal
+
+import (
+	"github.com/penwyp/claudecat/events"
+	"github.com/penwyp/claudecat/orchestrator"
+)
+
+// LiteBuild reports whether this binary was built without the interactive monitor
+const LiteBuild = true
+
+// consoleUI is empty in lite builds, which ship without the interactive monitor
+type consoleUI struct{}
+
+// initConsole is a no-op in lite builds
+func (ea *EnhancedApplication) initConsole() {}
+
+// rollDay is a no-op in lite builds, which show no daily totals
+func (ea *EnhancedApplication) rollDay(orchestrator.DayRollover) {}
+
+// showNotice logs notices since lite builds have no console to show them in
+func (ea *EnhancedApplication) showNotice(notice events.Notice) {
+	ea.logger.Infof("Notice (%s): %s", notice.Level, notice.Message)
+}
+
+// runInteractive falls back to headless mode in lite builds
+func (ea *EnhancedApplication) runInteractive() error {
+	ea.logger.Info("Interactive monitor is not included in lite builds; running headless")
+	return ea.runBackground()
+}
@@ -0,0 +1,156 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/models"
+)
+
+// ErrUnknownCostCenter is returned for proxied requests whose identity maps to no cost center
+var ErrUnknownCostCenter = errors.New("identity does not belong to a cost center")
+
+// ErrUntrustedPeer is returned, once cost centers are configured, for requests from a remote peer
+// that is not a trusted proxy, since they carry no identity the server can rely on
+var ErrUntrustedPeer = errors.New("requests must come through a trusted proxy")
+
+// CostCenterResolver maps API requests forwarded by a trusted reverse proxy to the projects the
+// requesting team may query
+type CostCenterResolver struct {
+	proxies []netip.Prefix
+	header  string
+	centers []config.CostCenterConfig
+}
+
+// ProjectScope limits query results to the projects of one or more cost centers
+type ProjectScope struct {
+	CostCenters []string // Names of the matched cost centers; empty for an unrestricted scope
+	patterns    []string
+	restricted  bool
+}
+
+// NewCostCenterResolver creates a resolver from the API configuration
+func NewCostCenterResolver(cfg config.APIConfig) (*CostCenterResolver, error) {
+	resolver := &CostCenterResolver{
+		header:  cfg.IdentityHeader,
+		centers: cfg.CostCenters,
+	}
+	for _, proxy := range cfg.TrustedProxies {
+		prefix, err := config.ParseTrustedProxy(proxy)
+		if err != nil {
+			return nil, err
+		}
+		resolver.proxies = append(resolver.proxies, prefix)
+	}
+	return resolver, nil
+}
+
+// Resolve returns the project scope of a request. Without cost centers the scope is unrestricted. With
+// them, proxied requests must carry an identity, or comma-separated identities, of a configured cost
+// center; other requests are rejected unless they come from the loopback interface, which keeps the
+// unrestricted scope for local clients. Their identity header is ignored, since anyone could set it.
+func (r *CostCenterResolver) Resolve(req *http.Request) (ProjectScope, error) {
+	if len(r.centers) == 0 {
+		return ProjectScope{}, nil
+	}
+	peer, ok := peerAddr(req)
+	if !ok || !r.trusted(peer) {
+		if ok && peer.IsLoopback() {
+			return ProjectScope{}, nil
+		}
+		return ProjectScope{}, ErrUntrustedPeer
+	}
+
+	identities := make(map[string]bool)
+	for _, value := range req.Header.Values(r.header) {
+		for _, identity := range strings.Split(value, ",") {
+			if identity = strings.TrimSpace(identity); identity != "" {
+				identities[strings.ToLower(identity)] = true
+			}
+		}
+	}
+	if len(identities) == 0 {
+		return ProjectScope{}, fmt.Errorf("%w: missing %s header", ErrUnknownCostCenter, r.header)
+	}
+
+	scope := ProjectScope{restricted: true}
+	for _, center := range r.centers {
+		for _, identity := range center.Identities {
+			if identities[strings.ToLower(identity)] {
+				scope.CostCenters = append(scope.CostCenters, center.Name)
+				for _, pattern := range center.Projects {
+					scope.patterns = append(scope.patterns, strings.ToLower(pattern))
+				}
+				break
+			}
+		}
+	}
+	if len(scope.CostCenters) == 0 {
+		return ProjectScope{}, ErrUnknownCostCenter
+	}
+	sort.Strings(scope.CostCenters)
+	return scope, nil
+}
+
+// peerAddr returns the address of the request's direct peer
+func peerAddr(req *http.Request) (netip.Addr, bool) {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+// trusted reports whether addr is a configured trusted proxy
+func (r *CostCenterResolver) trusted(addr netip.Addr) bool {
+	for _, prefix := range r.proxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Unrestricted reports whether the scope allows every project
+func (s ProjectScope) Unrestricted() bool {
+	return !s.restricted
+}
+
+// AllowsProject reports whether usage of a project may be returned in this scope
+func (s ProjectScope) AllowsProject(project string) bool {
+	if !s.restricted {
+		return true
+	}
+	project = strings.ToLower(project)
+	for _, pattern := range s.patterns {
+		if ok, _ := path.Match(pattern, project); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterResults returns the results of the projects allowed in this scope
+func (s ProjectScope) FilterResults(results []models.AnalysisResult) []models.AnalysisResult {
+	if !s.restricted {
+		return results
+	}
+	filtered := make([]models.AnalysisResult, 0, len(results))
+	for _, result := range results {
+		if s.AllowsProject(result.Project) {
+			filtered = append(filtered, result)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,78 @@
+package internal
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCostCenterResolver(t *testing.T) {
+	resolver, err := NewCostCenterResolver(config.APIConfig{
+		TrustedProxies: []string{"10.0.0.0/8", "127.0.0.1"},
+		IdentityHeader: "X-Forwarded-Groups",
+		CostCenters: []config.CostCenterConfig{
+			{Name: "platform", Identities: []string{"platform-team", "alice"}, Projects: []string{"api-*", "infra"}},
+			{Name: "web", Identities: []string{"web-team"}, Projects: []string{"webapp"}},
+		},
+	})
+	require.NoError(t, err)
+
+	resolve := func(remote string, groups ...string) (ProjectScope, error) {
+		req := httptest.NewRequest("GET", "/api/usage", nil)
+		req.RemoteAddr = remote
+		for _, value := range groups {
+			req.Header.Add("X-Forwarded-Groups", value)
+		}
+		return resolver.Resolve(req)
+	}
+
+	// Requests not coming through a trusted proxy are rejected, except local ones, which ignore the
+	// identity header
+	_, err = resolve("192.168.1.5:4000", "web-team")
+	assert.ErrorIs(t, err, ErrUntrustedPeer)
+	_, err = resolve("not-an-address", "web-team")
+	assert.ErrorIs(t, err, ErrUntrustedPeer)
+	scope, err := resolve("[::1]:4000", "web-team")
+	require.NoError(t, err)
+	assert.True(t, scope.Unrestricted())
+	assert.True(t, scope.AllowsProject("infra"))
+
+	// Proxied requests are limited to the projects of their cost centers
+	scope, err = resolve("10.1.2.3:4000", "Web-Team")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web"}, scope.CostCenters)
+	assert.True(t, scope.AllowsProject("WebApp"))
+	assert.False(t, scope.AllowsProject("api-billing"))
+
+	scope, err = resolve("127.0.0.1:4000", "web-team, platform-team")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"platform", "web"}, scope.CostCenters)
+	results := scope.FilterResults([]models.AnalysisResult{
+		{Project: "api-billing"}, {Project: "webapp"}, {Project: "secret"},
+	})
+	require.Len(t, results, 2)
+	assert.Equal(t, "api-billing", results[0].Project)
+	assert.Equal(t, "webapp", results[1].Project)
+
+	// Proxied requests without a known identity are rejected
+	_, err = resolve("10.0.0.1:4000")
+	assert.ErrorIs(t, err, ErrUnknownCostCenter)
+	_, err = resolve("10.0.0.1:4000", "marketing")
+	assert.ErrorIs(t, err, ErrUnknownCostCenter)
+
+	// Without cost centers everything is unrestricted
+	open, err := NewCostCenterResolver(config.APIConfig{TrustedProxies: []string{"10.0.0.0/8"}})
+	require.NoError(t, err)
+	req := httptest.NewRequest("GET", "/api/usage", nil)
+	req.RemoteAddr = "10.0.0.1:4000"
+	scope, err = open.Resolve(req)
+	require.NoError(t, err)
+	assert.True(t, scope.Unrestricted())
+
+	_, err = NewCostCenterResolver(config.APIConfig{TrustedProxies: []string{"not-an-ip"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,116 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/logging"
+	"github.com/penwyp/claudecat/models"
+)
+
+const (
+	// daemonProbeTimeout bounds the coverage request, so that a CLI command without a running
+	// claudecat serve falls back to the files without a noticeable delay
+	daemonProbeTimeout = time.Second
+	// daemonFetchTimeout bounds the download of the session blocks
+	daemonFetchTimeout = 30 * time.Second
+)
+
+// DaemonClient queries the API of a running claudecat serve, so that CLI commands reuse its warm
+// data instead of parsing the JSONL logs
+type DaemonClient struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// NewDaemonClient creates a client for the server configured in api; a wildcard bind address is
+// reached over loopback
+func NewDaemonClient(api config.APIConfig) *DaemonClient {
+	address := api.Address
+	if host, port, err := net.SplitHostPort(address); err == nil {
+		if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+			address = net.JoinHostPort("127.0.0.1", port)
+		}
+	}
+	return &DaemonClient{
+		baseURL: "http://" + address,
+		token:   api.Token,
+		client:  &http.Client{},
+	}
+}
+
+// Coverage returns the data path and history of the server
+func (c *DaemonClient) Coverage(ctx context.Context) (APICoverage, error) {
+	ctx, cancel := context.WithTimeout(ctx, daemonProbeTimeout)
+	defer cancel()
+	var coverage APICoverage
+	err := c.get(ctx, "/api/v1/coverage", &coverage)
+	return coverage, err
+}
+
+// Blocks returns the session blocks of the server with their entries and the idle gaps between them
+func (c *DaemonClient) Blocks(ctx context.Context) ([]models.SessionBlock, error) {
+	ctx, cancel := context.WithTimeout(ctx, daemonFetchTimeout)
+	defer cancel()
+	var blocks []models.SessionBlock
+	err := c.get(ctx, "/api/v1/blocks?entries=true&gaps=true", &blocks)
+	return blocks, err
+}
+
+// Covers reports whether the server monitors the only data path in paths and holds its history from
+// from on, with a session of slack so that sessions running into the range are detected as usual
+func (c *DaemonClient) Covers(ctx context.Context, paths []string, from time.Time) bool {
+	if len(paths) != 1 || from.IsZero() {
+		return false
+	}
+	coverage, err := c.Coverage(ctx)
+	if err != nil {
+		logging.LogDebugf("No claudecat serve to query at %s: %v", c.baseURL, err)
+		return false
+	}
+	if coverage.Since.IsZero() || !samePath(coverage.DataPath, paths[0]) {
+		logging.LogDebugf("claudecat serve at %s monitors %s, not %s", c.baseURL, coverage.DataPath, paths[0])
+		return false
+	}
+	return !from.Before(coverage.Since.Add(models.SessionDuration))
+}
+
+// get decodes the JSON response to path into into
+func (c *DaemonClient) get(ctx context.Context, path string, into any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", path, resp.Status)
+	}
+	return sonic.Unmarshal(body, into)
+}
+
+// samePath reports whether two data paths name the same directory
+func samePath(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
@@ -0,0 +1,103 @@
+package internal
+
+import (
+	"context"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/models"
+	"github.com/penwyp/claudecat/orchestrator"
+	"github.com/penwyp/claudecat/sessions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestDaemon serves one session block and an idle gap for dataPath with 24 hours of history
+func newTestDaemon(t *testing.T, dataPath, token string) *DaemonClient {
+	cfg := config.DefaultConfig()
+	cfg.API = config.APIConfig{Address: "127.0.0.1:0", Token: token}
+
+	refreshed := time.Date(2025, 6, 2, 12, 0, 0, 0, time.UTC)
+	start := refreshed.Add(-3 * time.Hour)
+	data := orchestrator.MonitoringData{Data: orchestrator.AnalysisResult{
+		Blocks: []models.SessionBlock{
+			{ID: "gap", StartTime: start.Add(-4 * time.Hour), EndTime: start, IsGap: true},
+			{
+				ID: "block-1", StartTime: start, EndTime: start.Add(models.SessionDuration), IsActive: true,
+				Entries:     []models.UsageEntry{{Timestamp: start.Add(time.Hour), Model: "claude-sonnet-4-20250514", InputTokens: 100, TotalTokens: 100, CostUSD: 1}},
+				TokenCounts: models.TokenCounts{InputTokens: 100},
+				CostUSD:     1,
+			},
+		},
+		Metadata: orchestrator.AnalysisMetadata{GeneratedAt: refreshed, HoursAnalyzed: "24"},
+	}}
+	server, err := NewAPIServer(cfg, func() orchestrator.MonitoringData { return data })
+	require.NoError(t, err)
+	server.dataPath = dataPath
+
+	ts := httptest.NewServer(server.Handler())
+	t.Cleanup(ts.Close)
+	return NewDaemonClient(config.APIConfig{Address: strings.TrimPrefix(ts.URL, "http://"), Token: token})
+}
+
+func TestDaemonClient(t *testing.T) {
+	dataPath := t.TempDir()
+	client := newTestDaemon(t, dataPath, "s3cret")
+	ctx := context.Background()
+
+	coverage, err := client.Coverage(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, dataPath, coverage.DataPath)
+	assert.Equal(t, time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC), coverage.Since)
+
+	blocks, err := client.Blocks(ctx)
+	require.NoError(t, err)
+	require.Len(t, blocks, 2, "gaps are included")
+	assert.Len(t, blocks[1].Entries, 1)
+
+	since := coverage.Since.Add(models.SessionDuration)
+	assert.True(t, client.Covers(ctx, []string{dataPath}, since))
+	assert.True(t, client.Covers(ctx, []string{filepath.Join(dataPath, ".")}, since))
+	assert.False(t, client.Covers(ctx, []string{dataPath}, since.Add(-time.Minute)), "sessions running into the range may start before the history")
+	assert.False(t, client.Covers(ctx, []string{dataPath}, time.Time{}), "the whole history")
+	assert.False(t, client.Covers(ctx, []string{t.TempDir()}, since), "another data path")
+	assert.False(t, client.Covers(ctx, []string{dataPath, t.TempDir()}, since))
+
+	unauthorized := *client
+	unauthorized.token = ""
+	_, err = unauthorized.Coverage(ctx)
+	assert.ErrorContains(t, err, "401")
+	assert.False(t, unauthorized.Covers(ctx, []string{dataPath}, since))
+}
+
+func TestNewDaemonClient_WildcardAddress(t *testing.T) {
+	assert.Equal(t, "http://127.0.0.1:8787", NewDaemonClient(config.APIConfig{Address: "0.0.0.0:8787"}).baseURL)
+	assert.Equal(t, "http://127.0.0.1:8787", NewDaemonClient(config.APIConfig{Address: ":8787"}).baseURL)
+	assert.Equal(t, "http://host:8787", NewDaemonClient(config.APIConfig{Address: "host:8787"}).baseURL)
+}
+
+func TestAnalyzer_SessionsFromDaemon(t *testing.T) {
+	dataPath := t.TempDir() // No logs: the sessions can only come from the daemon
+	cfg := config.DefaultConfig()
+	cfg.Cache.Dir = t.TempDir()
+	analyzer, err := NewAnalyzer(cfg)
+	require.NoError(t, err)
+	analyzer.SetTagStore(NewTagStore(filepath.Join(t.TempDir(), "tags.json")))
+	analyzer.SetImportStore(NewImportStore(t.TempDir()))
+
+	analyzer.SetDaemon(newTestDaemon(t, dataPath, ""), time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC))
+	summaries, err := analyzer.Sessions([]string{dataPath}, sessions.ListFilter{}, time.Date(2025, 6, 2, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, summaries, 2)
+	assert.True(t, summaries[0].Gap)
+	assert.Equal(t, "block-1", summaries[1].ID)
+
+	results, err := analyzer.Analyze([]string{dataPath})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, 1.0, results[0].CostUSD)
+}
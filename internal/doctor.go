@@ -0,0 +1,410 @@
+package internal
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/penwyp/claudecat/cache"
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/fileio"
+	"github.com/penwyp/claudecat/humanize"
+	"github.com/penwyp/claudecat/models/pricing"
+)
+
+// Outcomes of a diagnostic check
+const (
+	DoctorPass = "pass"
+	DoctorWarn = "warn"
+	DoctorFail = "fail"
+	DoctorSkip = "skip"
+)
+
+const (
+	// doctorSampleLines is the number of lines of each usage file checked for valid JSON
+	doctorSampleLines = 20
+	// doctorMaxListed is the number of problem files named in a check result
+	doctorMaxListed = 3
+	// maxClockSkew is the clock difference beyond which session windows and "today" drift noticeably
+	maxClockSkew = 2 * time.Minute
+	// doctorRequestTimeout bounds each network request of the diagnostics
+	doctorRequestTimeout = 10 * time.Second
+)
+
+// DoctorCheck is the result of one diagnostic check
+type DoctorCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+	Hint   string `json:"hint,omitempty"` // Remediation when the check did not pass
+}
+
+// Doctor diagnoses the configuration and environment claudecat runs in
+type Doctor struct {
+	cfg        *config.Config
+	httpClient *http.Client
+	now        func() time.Time
+
+	// URL of the LiteLLM price table probed by the pricing check
+	pricingURL string
+	// URL probed for the clock check when pricing is not fetched from the network
+	referenceURL string
+	// Newest modification time of the usage files, collected by the JSONL check
+	newestFile time.Time
+	// Date reported by the last server contacted, for the clock check
+	serverDate time.Time
+}
+
+// NewDoctor creates a doctor for cfg, whose data paths must already be resolved
+func NewDoctor(cfg *config.Config) *Doctor {
+	referenceURL := cfg.Data.UpdateURL
+	if referenceURL == "" {
+		referenceURL = pricing.DefaultDataBundleURL
+	}
+	return &Doctor{
+		cfg:          cfg,
+		httpClient:   &http.Client{Timeout: doctorRequestTimeout},
+		now:          time.Now,
+		pricingURL:   pricing.LiteLLMPricingURL,
+		referenceURL: referenceURL,
+	}
+}
+
+// Run performs all checks in order; later checks use what earlier ones found
+func (d *Doctor) Run(ctx context.Context) []DoctorCheck {
+	checks := []DoctorCheck{d.checkTimezone()}
+	checks = append(checks, d.checkDataPaths()...)
+	checks = append(checks, d.checkUsageFiles(), d.checkDuplicateProjects(), d.checkCache(), d.checkPricing(ctx), d.checkClock(ctx))
+	return checks
+}
+
+// ConfigCheck reports the outcome of loading the configuration files, which happens before a Doctor
+// can be created
+func ConfigCheck(loadErr error) DoctorCheck {
+	check := DoctorCheck{Name: "config", Status: DoctorPass, Detail: "defaults, no configuration file found"}
+	for _, path := range config.ConfigPaths() {
+		if _, err := os.Stat(path); err == nil {
+			check.Detail = path
+			break
+		}
+	}
+	if loadErr != nil {
+		check.Status = DoctorFail
+		check.Detail = loadErr.Error()
+		check.Hint = "fix the reported keys with `claudecat config set` or `claudecat config unset`; the other checks use the defaults"
+	}
+	return check
+}
+
+// checkTimezone verifies that the configured timezones load
+func (d *Doctor) checkTimezone() DoctorCheck {
+	check := DoctorCheck{Name: "timezone"}
+	var details []string
+	for _, tz := range []struct{ key, name string }{{"app.timezone", d.cfg.App.Timezone}, {"ui.timezone", d.cfg.UI.Timezone}} {
+		if tz.name == "" {
+			continue
+		}
+		loc, err := time.LoadLocation(tz.name)
+		if err != nil {
+			check.Status = DoctorFail
+			check.Detail = fmt.Sprintf("%s: unknown timezone %q", tz.key, tz.name)
+			check.Hint = fmt.Sprintf("set %s to an IANA name such as Europe/Berlin, or Local", tz.key)
+			return check
+		}
+		name, offset := d.now().In(loc).Zone()
+		details = append(details, fmt.Sprintf("%s %s (%s, UTC%+d)", tz.key, tz.name, name, offset/3600))
+	}
+	check.Status = DoctorPass
+	check.Detail = strings.Join(details, "; ")
+	if check.Detail == "" {
+		check.Detail = "system local time"
+	}
+	return check
+}
+
+// checkDataPaths verifies that every data path exists
+func (d *Doctor) checkDataPaths() []DoctorCheck {
+	if len(d.cfg.Data.Paths) == 0 {
+		return []DoctorCheck{{
+			Name:   "data path",
+			Status: DoctorFail,
+			Detail: "no data path configured or discovered",
+			Hint:   "pass the Claude projects directory as an argument or set data.paths",
+		}}
+	}
+
+	var checks []DoctorCheck
+	for _, path := range d.cfg.Data.Paths {
+		check := DoctorCheck{Name: "data path", Status: DoctorPass, Detail: path}
+		info, err := os.Stat(path)
+		switch {
+		case os.IsNotExist(err):
+			check.Status = DoctorFail
+			check.Detail = path + " does not exist"
+			check.Hint = "run Claude Code once to create it, or point data.paths at the directory holding the project logs"
+		case err != nil:
+			check.Status = DoctorFail
+			check.Detail = fmt.Sprintf("%s: %v", path, err)
+			check.Hint = "check the permissions of the directory and its parents"
+		case !info.IsDir() && !strings.HasSuffix(strings.ToLower(path), ".jsonl"):
+			check.Status = DoctorFail
+			check.Detail = path + " is neither a directory nor a .jsonl file"
+			check.Hint = "point data.paths at the directory holding the project logs"
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// checkUsageFiles verifies that the usage files can be opened and that their first lines are valid JSON
+func (d *Doctor) checkUsageFiles() DoctorCheck {
+	check := DoctorCheck{Name: "usage files"}
+	var files, unreadable, invalid []string
+	for _, path := range d.cfg.Data.Paths {
+		found, err := fileio.DiscoverFiles(path)
+		if err != nil {
+			continue
+		}
+		files = append(files, found...)
+	}
+	if len(files) == 0 {
+		check.Status = DoctorWarn
+		check.Detail = "no .jsonl usage files found"
+		check.Hint = "usage appears once Claude Code has been used; check that the data path is the projects directory"
+		return check
+	}
+
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil && info.ModTime().After(d.newestFile) {
+			d.newestFile = info.ModTime()
+		}
+		valid, err := sampleJSONL(file)
+		if err != nil {
+			unreadable = append(unreadable, filepath.Base(file))
+		} else if !valid {
+			invalid = append(invalid, filepath.Base(file))
+		}
+	}
+
+	check.Status = DoctorPass
+	check.Detail = fmt.Sprintf("%d files readable", len(files)-len(unreadable))
+	if len(invalid) > 0 {
+		check.Status = DoctorWarn
+		check.Detail += fmt.Sprintf("; %d with invalid lines (%s)", len(invalid), listFiles(invalid))
+		check.Hint = "invalid lines are skipped; they usually come from a log truncated while being written"
+	}
+	if len(unreadable) > 0 {
+		check.Status = DoctorFail
+		check.Detail += fmt.Sprintf("; %d unreadable (%s)", len(unreadable), listFiles(unreadable))
+		check.Hint = "make the files readable by the user running claudecat"
+	}
+	return check
+}
+
+// checkDuplicateProjects looks for conversations logged in several project directories, whose usage is
+// counted twice unless they are merged or excluded
+func (d *Doctor) checkDuplicateProjects() DoctorCheck {
+	check := DoctorCheck{Name: "duplicate projects", Status: DoctorPass, Detail: "no conversation logged in two project directories"}
+	scan := fileio.ScanDuplicateProjects(d.cfg.Data.Paths, d.cfg.Data.Exclude)
+	if len(scan.Projects) == 0 {
+		return check
+	}
+
+	var dirs []string
+	for _, duplicate := range scan.Projects {
+		dirs = append(dirs, fmt.Sprintf("%s shares %d with %s", duplicate.Dir, duplicate.Shared, filepath.Base(duplicate.Original)))
+	}
+	check.Detail = fmt.Sprintf("%d copied conversations in %d directories (%s)", len(scan.Redundant), len(scan.Projects), listFiles(dirs))
+	if d.cfg.Data.DuplicateProjects == "merge" {
+		check.Detail += "; merged"
+		return check
+	}
+	check.Status = DoctorWarn
+	check.Hint = fmt.Sprintf("their usage is counted twice; set data.duplicate_projects to merge, or add %q to data.exclude", scan.Projects[0].Pattern)
+	return check
+}
+
+// sampleJSONL reports whether the first lines of a usage file are valid JSON
+func sampleJSONL(path string) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	for range doctorSampleLines {
+		line, err := reader.ReadBytes('\n')
+		if trimmed := strings.TrimSpace(string(line)); trimmed != "" && !json.Valid([]byte(trimmed)) {
+			// A last line without newline may still be being written
+			if err == nil {
+				return false, nil
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	return true, nil
+}
+
+// listFiles names the first few of files
+func listFiles(files []string) string {
+	if len(files) <= doctorMaxListed {
+		return strings.Join(files, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(files[:doctorMaxListed], ", "), len(files)-doctorMaxListed)
+}
+
+// checkCache verifies that the cache directory is writable, its encryption key loads and it is within its size limit
+func (d *Doctor) checkCache() DoctorCheck {
+	check := DoctorCheck{Name: "cache"}
+	dir := config.ExpandHome(d.cfg.Cache.Dir)
+	if dir == "" {
+		check.Status = DoctorSkip
+		check.Detail = "no cache directory configured"
+		return check
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		check.Status = DoctorFail
+		check.Detail = fmt.Sprintf("cannot create %s: %v", dir, err)
+		check.Hint = "set cache.dir to a writable directory"
+		return check
+	}
+	probe, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		check.Status = DoctorFail
+		check.Detail = fmt.Sprintf("%s is not writable: %v", dir, err)
+		check.Hint = "fix the permissions of the directory or set cache.dir to a writable one"
+		return check
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+
+	fileCache, err := cache.OpenFileBasedSummaryCache(dir, d.cfg.Cache.Encryption)
+	if err != nil {
+		check.Status = DoctorFail
+		check.Detail = fmt.Sprintf("cannot open the summary cache: %v", err)
+		check.Hint = "check cache.encryption and its key; `claudecat analyze --reset` rebuilds the cache"
+		return check
+	}
+	defer fileCache.Close()
+
+	usage := fileCache.DiskUsage()
+	check.Status = DoctorPass
+	check.Detail = fmt.Sprintf("%s, %s used", dir, humanize.Bytes(usage))
+	if limit := d.cfg.Cache.MaxDiskSize; limit > 0 {
+		check.Detail += " of " + humanize.Bytes(limit)
+		if usage*10 > limit*9 {
+			check.Status = DoctorWarn
+			check.Hint = "the oldest summaries are evicted at the limit; raise cache.max_disk_size or run `claudecat cache compact`"
+		}
+	}
+	return check
+}
+
+// checkPricing verifies that the pricing source can be used, reaching it over the network if it is fetched from there
+func (d *Doctor) checkPricing(ctx context.Context) DoctorCheck {
+	check := DoctorCheck{Name: "pricing"}
+	cacheDir := config.ExpandHome(d.cfg.Cache.Dir)
+	switch d.cfg.Data.PricingSource {
+	case "", "default":
+		check.Status = DoctorPass
+		check.Detail = "built-in price table"
+		if bundle := pricing.LoadInstalledDataBundle(&d.cfg.Data, cacheDir); bundle != nil {
+			check.Detail = fmt.Sprintf("data bundle v%d published %s", bundle.Version, bundle.Published.Format("2006-01-02"))
+		}
+		return check
+	case "litellm":
+	default:
+		check.Status = DoctorFail
+		check.Detail = "unknown pricing source " + d.cfg.Data.PricingSource
+		check.Hint = "set data.pricing_source to default or litellm"
+		return check
+	}
+
+	if d.cfg.Data.PricingOfflineMode {
+		manager, err := pricing.NewCacheManager(cacheDir)
+		if err != nil || !manager.HasCache() {
+			check.Status = DoctorFail
+			check.Detail = "offline mode is on but no pricing has been cached"
+			check.Hint = "run once without data.pricing_offline_mode to cache the LiteLLM prices"
+			return check
+		}
+		age, _ := manager.GetCacheAge()
+		check.Status = DoctorPass
+		check.Detail = "offline, using LiteLLM prices cached " + humanize.Duration(age) + " ago"
+		return check
+	}
+
+	status, err := d.probe(ctx, d.pricingURL)
+	if err != nil {
+		check.Status = DoctorFail
+		check.Detail = fmt.Sprintf("LiteLLM prices unreachable: %v", err)
+		check.Hint = "check the network or proxy settings, or set data.pricing_source to default"
+		return check
+	}
+	if status != http.StatusOK {
+		check.Status = DoctorFail
+		check.Detail = fmt.Sprintf("LiteLLM prices returned HTTP %d", status)
+		check.Hint = "the price table may have moved; set data.pricing_source to default until it is fixed"
+		return check
+	}
+	check.Status = DoctorPass
+	check.Detail = "LiteLLM prices reachable"
+	return check
+}
+
+// checkClock compares the system clock with the date of a server and the modification times of the usage files
+func (d *Doctor) checkClock(ctx context.Context) DoctorCheck {
+	check := DoctorCheck{Name: "clock"}
+	now := d.now()
+	if future := d.newestFile.Sub(now); future > maxClockSkew {
+		check.Status = DoctorWarn
+		check.Detail = fmt.Sprintf("a usage file was modified %s in the future", future.Round(time.Second))
+		check.Hint = "sync the system clock (NTP) on this machine and on machines whose logs are synced here"
+		return check
+	}
+
+	if d.serverDate.IsZero() && !d.cfg.Data.PricingOfflineMode {
+		d.probe(ctx, d.referenceURL)
+	}
+	if d.serverDate.IsZero() {
+		check.Status = DoctorSkip
+		check.Detail = "no server time available; usage file times look consistent"
+		return check
+	}
+
+	skew := now.Sub(d.serverDate)
+	check.Status = DoctorPass
+	check.Detail = fmt.Sprintf("within %s of server time", maxClockSkew)
+	if skew > maxClockSkew || skew < -maxClockSkew {
+		check.Status = DoctorWarn
+		check.Detail = fmt.Sprintf("system clock is %s off server time", skew.Abs().Round(time.Second))
+		check.Hint = "enable time synchronization (NTP); session windows and daily totals depend on the clock"
+	}
+	return check
+}
+
+// probe sends a HEAD request to url and returns its status, recording the server date
+func (d *Doctor) probe(ctx context.Context, url string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	if date, err := http.ParseTime(resp.Header.Get("Date")); err == nil {
+		d.serverDate = date
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,105 @@
+package internal
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// doctorCheck returns the check named name
+func doctorCheck(t *testing.T, checks []DoctorCheck, name string) DoctorCheck {
+	t.Helper()
+	for _, check := range checks {
+		if check.Name == name {
+			return check
+		}
+	}
+	t.Fatalf("no %s check", name)
+	return DoctorCheck{}
+}
+
+func TestDoctor_Run(t *testing.T) {
+	dataDir := t.TempDir()
+	projectDir := filepath.Join(dataDir, "-Users-dev-webapp")
+	require.NoError(t, os.MkdirAll(projectDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "good.jsonl"), []byte(`{"type":"user"}`+"\n"+`{"type":"assistant"}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "bad.jsonl"), []byte("{\"type\":\n{}\n"), 0644))
+
+	// The server answers with a date ten minutes behind the local clock
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", time.Now().Add(-10*time.Minute).UTC().Format(http.TimeFormat))
+	}))
+	defer server.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.Data.Paths = []string{dataDir, filepath.Join(dataDir, "missing")}
+	cfg.Cache.Dir = t.TempDir()
+	cfg.Data.PricingSource = "litellm"
+	doctor := NewDoctor(cfg)
+	doctor.pricingURL = server.URL
+
+	checks := doctor.Run(context.Background())
+	assert.Equal(t, DoctorPass, doctorCheck(t, checks, "timezone").Status)
+	assert.Equal(t, DoctorPass, checks[1].Status)
+	assert.Equal(t, DoctorFail, checks[2].Status)
+	assert.Contains(t, checks[2].Detail, "does not exist")
+	assert.NotEmpty(t, checks[2].Hint)
+
+	usage := doctorCheck(t, checks, "usage files")
+	assert.Equal(t, DoctorWarn, usage.Status)
+	assert.Contains(t, usage.Detail, "1 with invalid lines (bad.jsonl)")
+	assert.Equal(t, DoctorPass, doctorCheck(t, checks, "cache").Status)
+	assert.Equal(t, DoctorPass, doctorCheck(t, checks, "pricing").Status)
+
+	clock := doctorCheck(t, checks, "clock")
+	assert.Equal(t, DoctorWarn, clock.Status)
+	assert.Contains(t, clock.Detail, "off server time")
+	assert.Contains(t, clock.Detail, "10m")
+}
+
+func TestDoctor_ClockFromUsageFiles(t *testing.T) {
+	dataDir := t.TempDir()
+	file := filepath.Join(dataDir, "session.jsonl")
+	require.NoError(t, os.WriteFile(file, []byte(`{"type":"user"}`+"\n"), 0644))
+	future := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(file, future, future))
+
+	cfg := config.DefaultConfig()
+	cfg.Data.Paths = []string{dataDir}
+	cfg.Cache.Dir = t.TempDir()
+	cfg.Data.PricingOfflineMode = true
+	checks := NewDoctor(cfg).Run(context.Background())
+
+	clock := doctorCheck(t, checks, "clock")
+	assert.Equal(t, DoctorWarn, clock.Status)
+	assert.Contains(t, clock.Detail, "in the future")
+}
+
+func TestDoctor_Failures(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.App.Timezone = "Mars/Olympus"
+	cfg.Data.Paths = nil
+	cfg.Cache.Dir = t.TempDir()
+	cfg.Data.PricingSource = "litellm"
+	cfg.Data.PricingOfflineMode = true
+	checks := NewDoctor(cfg).Run(context.Background())
+
+	assert.Equal(t, DoctorFail, doctorCheck(t, checks, "timezone").Status)
+	assert.Equal(t, DoctorFail, doctorCheck(t, checks, "data path").Status)
+	pricing := doctorCheck(t, checks, "pricing")
+	assert.Equal(t, DoctorFail, pricing.Status)
+	assert.Contains(t, pricing.Detail, "no pricing has been cached")
+	assert.Equal(t, DoctorSkip, doctorCheck(t, checks, "clock").Status)
+
+	configCheck := ConfigCheck(assert.AnError)
+	assert.Equal(t, DoctorFail, configCheck.Status)
+	assert.NotEmpty(t, configCheck.Hint)
+}
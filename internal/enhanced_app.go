@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"runtime"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -13,10 +16,12 @@ import (
 	"github.com/penwyp/claudecat/calculations"
 	"github.com/penwyp/claudecat/config"
 	"github.com/penwyp/claudecat/errors"
+	"github.com/penwyp/claudecat/events"
+	"github.com/penwyp/claudecat/fileio"
+	"github.com/penwyp/claudecat/humanize"
 	"github.com/penwyp/claudecat/logging"
 	"github.com/penwyp/claudecat/models"
 	"github.com/penwyp/claudecat/orchestrator"
-	"github.com/penwyp/claudecat/output"
 	"github.com/penwyp/claudecat/sessions"
 )
 
@@ -24,10 +29,20 @@ import (
 type EnhancedApplication struct {
 	config       *config.Config
 	orchestrator *orchestrator.MonitoringOrchestrator
+	dataPath     string
 	metricsCalc  *calculations.EnhancedMetricsCalculator
 	cache        *cache.Store
-	formatter    *output.ConsoleFormatter
+	formatter    *consoleUI
 	errorHandler *errors.EnhancedErrorHandler
+	influx       *InfluxExporter
+	absence      *AbsenceMonitor
+	notifier     *Notifier
+	limits       *LimitWatcher
+	guardrails   *GuardrailWatcher
+	rules        *RuleWatcher
+	alertLog     *AlertLog
+	api          *APIServer
+	snapshots    *SnapshotStore
 
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -37,8 +52,18 @@ type EnhancedApplication struct {
 	logger         logging.LoggerInterface
 	currentData    orchestrator.MonitoringData
 	currentMetrics *calculations.RealtimeMetrics
+	restoredAt     time.Time // Save time of the snapshot shown until the first data update; zero once live
 	dataMutex      sync.RWMutex
 
+	// Low-power mode slows refreshes and pauses cache warming and metrics collection
+	power          *PowerWatcher
+	lowPower       atomic.Bool
+	powerChanged   chan struct{} // Signals the console to change its redraw interval
+	updateInterval time.Duration // Data refresh interval outside low-power mode
+
+	// Samples memory and goroutines in debug mode; nil otherwise
+	watchdog *Watchdog
+
 	// Application state
 	running bool
 	mu      sync.RWMutex
@@ -80,6 +105,14 @@ func (ea *EnhancedApplication) Run() error {
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
 
+	// Show the last snapshot instead of a blank screen while the initial load runs
+	if !ea.config.UI.CompactMode && !LiteBuild {
+		ea.restoreSnapshot()
+	}
+
+	// Enter low-power mode before the first refresh when it applies right away
+	ea.checkPower()
+
 	// Start all components
 	if err := ea.start(); err != nil {
 		return ea.errorHandler.RetryWithBackoff(
@@ -89,10 +122,37 @@ func (ea *EnhancedApplication) Run() error {
 		)
 	}
 
+	// Serve the API once initial data is available
+	if ea.api != nil {
+		if err := ea.api.Start(); err != nil {
+			ea.cancel()
+			ea.orchestrator.Stop()
+			return err
+		}
+	}
+
 	// Handle signals in a separate goroutine
 	ea.wg.Add(1)
 	go ea.handleSignals(sigCh)
 
+	// Follow switches between battery and AC
+	if ea.power.Polls() {
+		ea.wg.Add(1)
+		go ea.watchPower()
+	}
+
+	// Export usage points off the data update path
+	if ea.influx != nil {
+		ea.wg.Add(1)
+		go ea.exportInflux()
+	}
+
+	// Warn about memory and goroutine growth
+	if ea.watchdog != nil {
+		ea.wg.Add(1)
+		go ea.runWatchdog()
+	}
+
 	// Start the UI (this blocks until the UI exits)
 	var err error
 	if ea.config.UI.CompactMode {
@@ -133,6 +193,7 @@ func (ea *EnhancedApplication) bootstrap() error {
 
 	// Initialize orchestrator with data paths
 	dataPath := ea.getDataPath()
+	ea.dataPath = dataPath
 	updateInterval := time.Duration(ea.config.UI.RefreshRate)
 	if updateInterval <= 0 {
 		updateInterval = 10 * time.Second // Default
@@ -143,13 +204,87 @@ func (ea *EnhancedApplication) bootstrap() error {
 		dataPath,
 		ea.config,
 	)
+	ea.updateInterval = updateInterval
+	ea.power = NewPowerWatcher(ea.config.UI.LowPower)
+	ea.powerChanged = make(chan struct{}, 1)
+	if ea.config.Debug.Enabled || ea.config.Debug.Watchdog.Enabled {
+		ea.watchdog = NewWatchdog(ea.config.Debug.Watchdog)
+	}
 
-	// Initialize console formatter
-	ea.formatter = output.NewConsoleFormatter(
-		ea.config.Subscription.Plan,
-		ea.config.UI.Timezone,
-		ea.config.UI.TimeFormat,
-	)
+	// Initialize the interactive console, if this build includes it
+	ea.initConsole()
+
+	// Initialize InfluxDB line protocol exporter if configured
+	if ea.config.Export.InfluxDB.Enabled {
+		ea.influx = NewInfluxExporter(ea.config.Export.InfluxDB)
+	}
+
+	// Apply the retention policy to the stores under cache.dir
+	ApplyRetention(ea.config)
+
+	// Persist the monitored data so the next start can show it immediately
+	if store, err := OpenSnapshotStore(ea.config); err != nil {
+		logging.LogWarnf("Monitor snapshot disabled: %v", err)
+	} else {
+		ea.snapshots = store
+	}
+
+	// Initialize absence alerting if configured
+	if ea.config.Alerts.Absence.Enabled {
+		loc, err := time.LoadLocation(ea.config.App.Timezone)
+		if err != nil {
+			loc = time.Local
+		}
+		monitor, err := NewAbsenceMonitor(ea.config.Alerts.Absence, loc, time.Now())
+		if err != nil {
+			logging.LogWarnf("Absence alert disabled: %v", err)
+		} else {
+			ea.absence = monitor
+			ea.notifier = NewNotifier(ea.config.Limits)
+		}
+	}
+
+	// Subscribe to data updates and session changes
+	bus := ea.orchestrator.Bus()
+	events.Subscribe(bus, ea.onDataUpdate)
+	events.Subscribe(bus, ea.onSessionChange)
+	events.Subscribe(bus, ea.rollDay)
+	if ea.absence != nil {
+		events.Subscribe(bus, func(data orchestrator.MonitoringData) {
+			ea.checkAbsence(data.Data.Blocks)
+		})
+	}
+
+	// Surface limit warnings and other notices in the console, persisting threshold crossings
+	ea.limits = NewLimitWatcher(ea.config.Subscription)
+	ea.guardrails = NewGuardrailWatcher(ea.config.Guardrails)
+	loc, err := time.LoadLocation(ea.config.App.Timezone)
+	if err != nil {
+		loc = time.Local
+	}
+	ea.rules = NewRuleWatcher(ea.config.Alerts, models.GetPlanLimits(ea.config.Subscription.Plan), loc)
+	if ea.rules.Enabled() && ea.notifier == nil {
+		ea.notifier = NewNotifier(ea.config.Limits)
+	}
+	if ea.alertLog, err = OpenAlertLog(ea.config); err != nil {
+		ea.logger.Warnf("Alerts will not be recorded: %v", err)
+	}
+	events.Subscribe(bus, func(data orchestrator.MonitoringData) {
+		for _, notice := range ea.limits.Observe(data.Data.Blocks) {
+			events.Publish(bus, notice)
+		}
+		for _, notice := range ea.guardrails.Observe(data.Data.Blocks) {
+			events.Publish(bus, notice)
+		}
+		for _, notice := range ea.rules.Observe(data.Data.Blocks) {
+			events.Publish(bus, notice)
+			if err := ea.notifier.Notify(ea.ctx, "claudecat alert", notice.Message); err != nil {
+				ea.logger.Warnf("Alert rule: %v", err)
+			}
+		}
+	})
+	events.Subscribe(bus, ea.showNotice)
+	events.Subscribe(bus, ea.recordAlert)
 
 	return nil
 }
@@ -158,12 +293,6 @@ func (ea *EnhancedApplication) bootstrap() error {
 func (ea *EnhancedApplication) start() error {
 	ea.logger.Info("Starting enhanced application components")
 
-	// Register data update callback with orchestrator
-	ea.orchestrator.RegisterUpdateCallback(ea.onDataUpdate)
-
-	// Register session change callback
-	ea.orchestrator.RegisterSessionCallback(ea.onSessionChange)
-
 	// Set command line arguments for token limit calculation
 	// This would be set from the CLI args in a real implementation
 	ea.orchestrator.SetArgs(map[string]interface{}{
@@ -175,6 +304,15 @@ func (ea *EnhancedApplication) start() error {
 		return fmt.Errorf("failed to start orchestrator: %w", err)
 	}
 
+	// A restored snapshot is on screen until the initial data arrives
+	ea.dataMutex.RLock()
+	restored := !ea.restoredAt.IsZero()
+	ea.dataMutex.RUnlock()
+	if restored {
+		ea.logger.Info("Showing the saved snapshot while the initial data loads")
+		return nil
+	}
+
 	// Wait for initial data with timeout
 	ea.logger.Info("Waiting for initial data...")
 	if !ea.orchestrator.WaitForInitialData(10 * time.Second) {
@@ -186,42 +324,6 @@ func (ea *EnhancedApplication) start() error {
 	return nil
 }
 
-// runInteractive starts the console output application
-func (ea *EnhancedApplication) runInteractive() error {
-	ea.logger.Info("Starting interactive console mode")
-
-	// Clear screen initially
-	fmt.Print("\033[H\033[2J")
-
-	// Create ticker for refresh
-	refreshRate := ea.config.UI.RefreshRate
-	if refreshRate <= 0 {
-		refreshRate = time.Second
-	}
-	ticker := time.NewTicker(refreshRate)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ea.ctx.Done():
-			return nil
-		case <-ticker.C:
-			// Clear screen and move cursor to top
-			fmt.Print("\033[H\033[2J")
-
-			// Get current data
-			ea.dataMutex.RLock()
-			metrics := ea.currentMetrics
-			blocks := ea.currentData.Data.Blocks
-			ea.dataMutex.RUnlock()
-
-			// Format and print
-			output := ea.formatter.Format(metrics, blocks)
-			fmt.Print(output)
-		}
-	}
-}
-
 // runBackground runs in background mode without TUI
 func (ea *EnhancedApplication) runBackground() error {
 	ea.logger.Info("Starting background mode")
@@ -262,6 +364,7 @@ func (ea *EnhancedApplication) onDataUpdate(data orchestrator.MonitoringData) {
 	// Store data for console output
 	ea.dataMutex.Lock()
 	ea.currentData = data
+	ea.restoredAt = time.Time{}
 	if metrics != nil {
 		// Convert enhanced metrics to realtime metrics
 		burnRate := float64(0)
@@ -289,16 +392,104 @@ func (ea *EnhancedApplication) onDataUpdate(data orchestrator.MonitoringData) {
 	}
 	ea.dataMutex.Unlock()
 
-	// Update application metrics
-	ea.updateApplicationMetrics(metrics)
+	ea.saveSnapshot(false)
+
+	// Collect application metrics and export usage points on each refresh, unless in low-power mode
+	if !ea.lowPower.Load() {
+		ea.updateApplicationMetrics(metrics)
+		if ea.influx != nil {
+			ea.influx.Queue(data.Data.Blocks)
+		}
+	}
 
 	ea.logger.Debugf("Processed data update with %d blocks", len(data.Data.Blocks))
 	ea.logger.Debug("=== END DATA UPDATE ===")
 }
 
+// restoreSnapshot shows the snapshot saved by the last run until the first data update replaces it
+func (ea *EnhancedApplication) restoreSnapshot() {
+	if ea.snapshots == nil {
+		return
+	}
+	snapshot, err := ea.snapshots.Load(time.Now())
+	if err != nil {
+		ea.logger.Warnf("Ignoring monitor snapshot: %v", err)
+		return
+	}
+	if snapshot == nil {
+		return
+	}
+
+	ea.dataMutex.Lock()
+	defer ea.dataMutex.Unlock()
+	ea.currentData = orchestrator.MonitoringData{Data: orchestrator.AnalysisResult{Blocks: snapshot.Blocks}}
+	ea.currentMetrics = snapshot.Metrics
+	ea.restoredAt = snapshot.SavedAt
+}
+
+// saveSnapshot persists the current data at most every snapshotSaveInterval unless forced.
+// A restored snapshot is not saved again before live data replaces it.
+func (ea *EnhancedApplication) saveSnapshot(force bool) {
+	if ea.snapshots == nil {
+		return
+	}
+	now := time.Now()
+	if !force && !ea.snapshots.Due(now) {
+		return
+	}
+
+	ea.dataMutex.RLock()
+	snapshot := MonitorSnapshot{SavedAt: now, Blocks: ea.currentData.Data.Blocks, Metrics: ea.currentMetrics}
+	live := ea.restoredAt.IsZero()
+	ea.dataMutex.RUnlock()
+	if !live || len(snapshot.Blocks) == 0 {
+		return
+	}
+	if err := ea.snapshots.Save(snapshot); err != nil {
+		ea.logger.Warnf("Failed to save monitor snapshot: %v", err)
+	}
+}
+
+// checkAbsence alerts when no entries have appeared during work hours for the configured time
+func (ea *EnhancedApplication) checkAbsence(blocks []models.SessionBlock) {
+	if ea.absence == nil {
+		return
+	}
+
+	quiet, fire := ea.absence.Observe(latestEntryTime(blocks), time.Now())
+	if !fire {
+		return
+	}
+
+	message := fmt.Sprintf("No Claude usage recorded for %s of work hours; check that the log directory is still correct", humanize.Duration(quiet))
+	ea.logger.Warnf("Absence alert: %s", message)
+	crossing := &events.Crossing{
+		Metric:    AlertMetricAbsence,
+		Value:     quiet.Hours(),
+		Threshold: ea.config.Alerts.Absence.After.Hours(),
+	}
+	if err := ea.notifier.Notify(ea.ctx, "claudecat: no usage detected", message); err != nil {
+		ea.logger.Warnf("Absence alert: %v", err)
+		events.Publish(ea.orchestrator.Bus(), events.Notice{Level: events.NoticeError, Message: fmt.Sprintf("Absence alert delivery failed: %v", err), Crossing: crossing})
+		return
+	}
+	events.Publish(ea.orchestrator.Bus(), events.Notice{Level: events.NoticeWarning, Message: fmt.Sprintf("Absence alert sent: no usage for %s", humanize.Duration(quiet)), Crossing: crossing})
+}
+
+// recordAlert appends notices caused by a threshold crossing to the alert log
+func (ea *EnhancedApplication) recordAlert(notice events.Notice) {
+	record, ok := NewAlertRecord(notice, time.Now())
+	if !ok || ea.alertLog == nil {
+		return
+	}
+	if err := ea.alertLog.Append(record); err != nil {
+		ea.logger.Warnf("Failed to record alert: %v", err)
+	}
+}
+
 // onSessionChange handles session change events
-func (ea *EnhancedApplication) onSessionChange(eventType, sessionID string, sessionData interface{}) {
-	ea.logger.Infof("Session change: %s for session %s", eventType, sessionID)
+func (ea *EnhancedApplication) onSessionChange(change orchestrator.SessionChanged) {
+	ea.logger.Infof("Session change: %s for session %s", change.Type, change.SessionID)
 
 	// Handle session changes if needed
 	// This could be used for notifications, logging, etc.
@@ -345,6 +536,69 @@ func (ea *EnhancedApplication) extractEntriesFromBlocks(blocks []models.SessionB
 	return result
 }
 
+// exportInflux writes the usage points queued by data updates until shutdown
+func (ea *EnhancedApplication) exportInflux() {
+	defer ea.wg.Done()
+	ea.influx.Run(ea.ctx, func(err error) {
+		ea.logger.Warnf("InfluxDB export failed: %v", err)
+	})
+}
+
+// watchPower checks the power source periodically until shutdown
+func (ea *EnhancedApplication) watchPower() {
+	defer ea.wg.Done()
+
+	ticker := time.NewTicker(powerCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ea.ctx.Done():
+			return
+		case <-ticker.C:
+			ea.checkPower()
+		}
+	}
+}
+
+// runWatchdog samples the process until shutdown, logging and showing each threshold it exceeds
+func (ea *EnhancedApplication) runWatchdog() {
+	defer ea.wg.Done()
+
+	ea.watchdog.Run(ea.ctx, func(message string) {
+		ea.logger.Warnf("Watchdog: %s", message)
+		events.Publish(ea.orchestrator.Bus(), events.Notice{Level: events.NoticeWarning, Message: message})
+	})
+}
+
+// checkPower enters or leaves low-power mode when the power source or configured mode calls for it
+func (ea *EnhancedApplication) checkPower() {
+	active, changed := ea.power.Check()
+	if !changed {
+		return
+	}
+	ea.lowPower.Store(active)
+
+	interval := ea.updateInterval
+	if active {
+		interval = lowPowerInterval(interval, ea.config.UI.LowPowerRefreshRate)
+	}
+	ea.orchestrator.SetUpdateInterval(interval)
+	ea.orchestrator.SetCacheWarming(!active)
+	select {
+	case ea.powerChanged <- struct{}{}:
+	default:
+	}
+
+	message := fmt.Sprintf("Low-power mode on, refreshing every %s", interval)
+	if active {
+		ea.logger.Infof("%s; cache warming and metrics collection paused", message)
+	} else {
+		message = "Low-power mode off"
+		ea.logger.Info("Low-power mode off; cache warming and metrics collection resumed")
+	}
+	events.Publish(ea.orchestrator.Bus(), events.Notice{Level: events.NoticeInfo, Message: message})
+}
+
 // updateApplicationMetrics updates application-level metrics
 func (ea *EnhancedApplication) updateApplicationMetrics(metrics *calculations.EnhancedRealtimeMetrics) {
 	if ea.metrics == nil {
@@ -362,29 +616,40 @@ func (ea *EnhancedApplication) updateApplicationMetrics(metrics *calculations.En
 
 // getDataPath determines the data path to monitor
 func (ea *EnhancedApplication) getDataPath() string {
-	if len(ea.config.Data.Paths) > 0 {
-		path := ea.config.Data.Paths[0]
-		ea.logger.Infof("Using configured data path: %s", path)
+	return resolveDataPath(ea.config, ea.logger)
+}
+
+// resolveDataPath returns the first configured data path, else the first discovered one
+func resolveDataPath(cfg *config.Config, logger logging.LoggerInterface) string {
+	if len(cfg.Data.Paths) > 0 {
+		path := cfg.Data.Paths[0]
+		logger.Infof("Using configured data path: %s", path)
 		return path
 	}
 
-	// Try default paths in order of preference
+	// Discover the Claude data directories across the locations used by different versions
 	homeDir, _ := os.UserHomeDir()
-	defaultPaths := []string{
-		fmt.Sprintf("%s/.claude/projects", homeDir),
+	cacheDir := config.ExpandHome(cfg.Cache.Dir)
+	statePath := ""
+	if cacheDir != "" {
+		statePath = filepath.Join(cacheDir, fileio.DataDirsStateFile)
 	}
-
-	for _, path := range defaultPaths {
-		if _, err := os.Stat(path); err == nil {
-			ea.logger.Infof("Using discovered data path: %s", path)
-			return path
+	dirs := fileio.DiscoverDataDirs(fileio.DataDirCandidates(homeDir, runtime.GOOS, os.Getenv), statePath)
+	for _, warning := range dirs.Warnings {
+		logger.Warnf("%s", warning)
+	}
+	if len(dirs.Dirs) > 0 {
+		if len(dirs.Dirs) > 1 {
+			logger.Warnf("Found %d data paths, monitoring %s; pass --paths to choose another", len(dirs.Dirs), dirs.Dirs[0])
 		}
+		logger.Infof("Using discovered data path: %s", dirs.Dirs[0])
+		return dirs.Dirs[0]
 	}
 
-	// Fallback to first default path even if it doesn't exist
-	defaultPath := defaultPaths[0]
-	ea.logger.Warnf("No existing data paths found, using default: %s", defaultPath)
-	ea.logger.Warnf("To specify a custom path, use: claudecat run --paths /path/to/claude/data")
+	// Fallback to the default path even if it doesn't exist
+	defaultPath := filepath.Join(homeDir, ".claude", "projects")
+	logger.Warnf("No existing data paths found, using default: %s", defaultPath)
+	logger.Warnf("To specify a custom path, use: claudecat run --paths /path/to/claude/data")
 	return defaultPath
 }
 
@@ -432,6 +697,15 @@ func (ea *EnhancedApplication) reloadConfig() error {
 func (ea *EnhancedApplication) shutdown() error {
 	ea.logger.Info("Shutting down enhanced application")
 
+	// Let in-flight API requests finish before the data stops refreshing
+	if ea.api != nil {
+		if err := ea.api.Shutdown(); err != nil {
+			ea.logger.Warnf("API server shutdown: %v", err)
+		}
+	}
+
+	ea.saveSnapshot(true)
+
 	// Stop orchestrator
 	if ea.orchestrator != nil {
 		ea.orchestrator.Stop()
@@ -448,6 +722,24 @@ func (ea *EnhancedApplication) shutdown() error {
 	return nil
 }
 
+// EnableAPI serves the monitoring data over HTTP while the application runs, see APIServer
+func (ea *EnhancedApplication) EnableAPI() (*APIServer, error) {
+	server, err := NewAPIServer(ea.config, ea.snapshot)
+	if err != nil {
+		return nil, err
+	}
+	server.dataPath = ea.dataPath
+	ea.api = server
+	return server, nil
+}
+
+// snapshot returns the latest monitoring data
+func (ea *EnhancedApplication) snapshot() orchestrator.MonitoringData {
+	ea.dataMutex.RLock()
+	defer ea.dataMutex.RUnlock()
+	return ea.currentData
+}
+
 // GetOrchestrator returns the monitoring orchestrator (for testing/debugging)
 func (ea *EnhancedApplication) GetOrchestrator() *orchestrator.MonitoringOrchestrator {
 	return ea.orchestrator
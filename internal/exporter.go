@@ -83,6 +83,9 @@ func (e *Exporter) Export(options ExportOptions) (*ExportResult, error) {
 	// Apply time range filtering
 	data = e.filterByTimeRange(data, options)
 
+	// Apply the retention policy before anything is written
+	redactExpiredResults(data, models.RedactionCutoff(e.config.Retention.RedactIDsAfterDays, time.Now()))
+
 	// Apply aggregation if requested
 	if options.Aggregate {
 		data = e.aggregateData(data)
@@ -124,6 +127,19 @@ func (e *Exporter) Export(options ExportOptions) (*ExportResult, error) {
 	return result, nil
 }
 
+// redactExpiredResults removes IDs from results older than cutoff, keeping their numeric values
+func redactExpiredResults(data []models.AnalysisResult, cutoff time.Time) {
+	if cutoff.IsZero() {
+		return
+	}
+	for i := range data {
+		if data[i].Timestamp.Before(cutoff) {
+			data[i].SessionID = models.RedactIDs(data[i].SessionID)
+			data[i].Project = models.RedactIDs(data[i].Project)
+		}
+	}
+}
+
 // filterByTimeRange filters data based on time range options
 func (e *Exporter) filterByTimeRange(data []models.AnalysisResult, options ExportOptions) []models.AnalysisResult {
 	if options.TimeRange == "all" && options.FromTime == "" && options.ToTime == "" {
@@ -0,0 +1,72 @@
+package internal
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/penwyp/claudecat/calculations"
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/events"
+	"github.com/penwyp/claudecat/humanize"
+	"github.com/penwyp/claudecat/models"
+)
+
+// GuardrailWatcher raises a notice for every new message in the active session whose input
+// exceeds the message-size guardrail of its model
+type GuardrailWatcher struct {
+	guardrail *calculations.MessageGuardrail
+	lastSeen  time.Time
+}
+
+// NewGuardrailWatcher creates a watcher using the configured message-size guardrails
+func NewGuardrailWatcher(cfg config.GuardrailsConfig) *GuardrailWatcher {
+	return &GuardrailWatcher{guardrail: calculations.NewMessageGuardrail(cfg)}
+}
+
+// Observe returns the notices caused by messages of the active session in blocks since the last call
+func (w *GuardrailWatcher) Observe(blocks []models.SessionBlock) []events.Notice {
+	if !w.guardrail.Enabled() {
+		return nil
+	}
+	var active *models.SessionBlock
+	for i := range blocks {
+		if blocks[i].IsActive && !blocks[i].IsGap {
+			active = &blocks[i]
+			break
+		}
+	}
+	if active == nil {
+		return nil
+	}
+
+	var notices []events.Notice
+	latest := w.lastSeen
+	for _, entry := range active.Entries {
+		if !entry.Timestamp.After(w.lastSeen) {
+			continue
+		}
+		if entry.Timestamp.After(latest) {
+			latest = entry.Timestamp
+		}
+		hit, ok := w.guardrail.Check(entry)
+		if !ok {
+			continue
+		}
+		message := fmt.Sprintf("Large message: %s tokens in %s", humanize.Compact(hit.InputTokens), hit.Project)
+		if len(hit.SessionID) >= 8 {
+			message += " " + hit.SessionID[:8]
+		}
+		notices = append(notices, events.Notice{
+			Level:   events.NoticeWarning,
+			Message: message,
+			Crossing: &events.Crossing{
+				Metric:    AlertMetricMessageInput,
+				Value:     float64(hit.InputTokens),
+				Threshold: float64(hit.Threshold),
+				SessionID: active.ID,
+			},
+		})
+	}
+	w.lastSeen = latest
+	return notices
+}
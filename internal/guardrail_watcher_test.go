@@ -0,0 +1,41 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/events"
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuardrailWatcher_NewLargeMessages(t *testing.T) {
+	watcher := NewGuardrailWatcher(config.GuardrailsConfig{
+		MessageInputTokens: 150000,
+		Models:             []config.ModelGuardrailConfig{{Model: "*haiku*", MessageInputTokens: 0}},
+	})
+	start := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	block := models.SessionBlock{
+		ID:       "s1",
+		IsActive: true,
+		Entries: []models.UsageEntry{
+			{Timestamp: start, Model: "claude-sonnet-4-20250514", InputTokens: 20, CacheCreationTokens: 2000, CacheReadTokens: 400000},
+			{Timestamp: start.Add(time.Minute), Model: "claude-sonnet-4-20250514", InputTokens: 1000, CacheCreationTokens: 181000, SessionID: "8c1f2a3b-0000", Project: "webapp"},
+			{Timestamp: start.Add(2 * time.Minute), Model: "claude-3-5-haiku-20241022", InputTokens: 300000},
+		},
+	}
+
+	notices := watcher.Observe([]models.SessionBlock{block})
+	require.Len(t, notices, 1)
+	assert.Equal(t, events.NoticeWarning, notices[0].Level)
+	assert.Equal(t, "Large message: 182K tokens in webapp 8c1f2a3b", notices[0].Message)
+	require.NotNil(t, notices[0].Crossing)
+	assert.Equal(t, AlertMetricMessageInput, notices[0].Crossing.Metric)
+	assert.Equal(t, 150000.0, notices[0].Crossing.Threshold)
+	assert.Empty(t, watcher.Observe([]models.SessionBlock{block}))
+
+	block.Entries = append(block.Entries, models.UsageEntry{Timestamp: start.Add(3 * time.Minute), Model: "claude-opus-4-20250514", InputTokens: 160000})
+	assert.Len(t, watcher.Observe([]models.SessionBlock{block}), 1)
+}
@@ -0,0 +1,111 @@
+package internal
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/penwyp/claudecat/cache"
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/models"
+)
+
+const (
+	// maxHistoryFileSize triggers trimming of the history log once exceeded
+	maxHistoryFileSize = 1024 * 1024
+	// historyKeepRecords is the number of most recent records kept when trimming
+	historyKeepRecords = 500
+)
+
+// HistoryRecord describes a single CLI invocation
+type HistoryRecord struct {
+	Time     time.Time         `json:"time"`
+	Command  string            `json:"command"`
+	Args     []string          `json:"args,omitempty"`
+	Flags    map[string]string `json:"flags,omitempty"`
+	Duration time.Duration     `json:"duration"`
+	Success  bool              `json:"success"`
+	Error    string            `json:"error,omitempty"`
+	Results  map[string]int    `json:"results,omitempty"` // Result counts reported by the command, e.g. entries, rows
+}
+
+// HistoryLog is an append-only JSONL audit log of CLI invocations
+type HistoryLog struct {
+	jsonlLog[HistoryRecord]
+}
+
+// NewHistoryLog creates a history log stored at path
+func NewHistoryLog(path string) *HistoryLog {
+	return newHistoryLog(path, nil)
+}
+
+// newHistoryLog creates a history log stored at path whose records are encrypted with enc when set
+func newHistoryLog(path string, enc *cache.Encryptor) *HistoryLog {
+	return &HistoryLog{jsonlLog[HistoryRecord]{path: path, name: "history", maxSize: maxHistoryFileSize, keep: historyKeepRecords, enc: enc}}
+}
+
+// OpenHistoryLog opens the default history log, encrypted according to cache.encryption.
+// Records written before encryption was enabled are encrypted on open.
+func OpenHistoryLog(cfg *config.Config) (*HistoryLog, error) {
+	enc, err := cacheEncryptor(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load history encryption key: %w", err)
+	}
+
+	h := newHistoryLog(DefaultHistoryPath(cfg), enc)
+	if enc != nil {
+		if err := h.sealPlaintext(); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+// DefaultHistoryPath returns the default location of the history log, under cache.dir
+func DefaultHistoryPath(cfg *config.Config) string {
+	return filepath.Join(cacheDirPath(cfg), "history.jsonl")
+}
+
+// cacheDirPath returns the expanded cache.dir of cfg, or the default cache directory when it is unset
+func cacheDirPath(cfg *config.Config) string {
+	dir := cfg.Cache.Dir
+	if dir == "" {
+		dir = config.DefaultConfig().Cache.Dir
+	}
+	return config.ExpandHome(dir)
+}
+
+// cacheEncryptor returns the encryptor of cache.encryption shared by the summaries and the stores
+// kept next to them, or nil when encryption is off
+func cacheEncryptor(cfg *config.Config) (*cache.Encryptor, error) {
+	return cache.LoadEncryptor(cfg.Cache.Encryption, cacheDirPath(cfg))
+}
+
+// RedactBefore removes IDs from the arguments, flags and errors of records older than cutoff.
+// Timings and result counts are kept.
+func (h *HistoryLog) RedactBefore(cutoff time.Time) (int, error) {
+	if cutoff.IsZero() {
+		return 0, nil
+	}
+
+	return h.redactRecords(func(record *HistoryRecord) bool {
+		if !record.Time.Before(cutoff) {
+			return false
+		}
+
+		changed := false
+		redact := func(s string) string {
+			r := models.RedactIDs(s)
+			changed = changed || r != s
+			return r
+		}
+		for j, arg := range record.Args {
+			record.Args[j] = redact(arg)
+		}
+		for name, value := range record.Flags {
+			record.Flags[name] = redact(value)
+		}
+		record.Error = redact(record.Error)
+		return changed
+	})
+}
@@ -0,0 +1,108 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoryLog_AppendAndRead(t *testing.T) {
+	log := NewHistoryLog(filepath.Join(t.TempDir(), "nested", "history.jsonl"))
+
+	records, err := log.Read()
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	require.NoError(t, log.Append(HistoryRecord{
+		Time:     start,
+		Command:  "claudecat analyze",
+		Flags:    map[string]string{"from": "2024-02-01", "group-by": "day"},
+		Duration: 2 * time.Second,
+		Success:  true,
+		Results:  map[string]int{"rows": 29},
+	}))
+	require.NoError(t, log.Append(HistoryRecord{Time: start.Add(time.Hour), Command: "claudecat cache warm", Error: "boom"}))
+
+	// Malformed lines are skipped
+	file, err := os.OpenFile(log.Path(), os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = file.WriteString("not json\n")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	records, err = log.Read()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "day", records[0].Flags["group-by"])
+	assert.Equal(t, 29, records[0].Results["rows"])
+	assert.True(t, records[0].Time.Equal(start))
+	assert.False(t, records[1].Success)
+}
+
+func TestHistoryLog_Trim(t *testing.T) {
+	log := NewHistoryLog(filepath.Join(t.TempDir(), "history.jsonl"))
+	for i := 0; i < 10; i++ {
+		require.NoError(t, log.Append(HistoryRecord{Command: "claudecat analyze", Results: map[string]int{"run": i}}))
+	}
+
+	require.NoError(t, log.trim(3))
+
+	records, err := log.Read()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, 7, records[0].Results["run"])
+}
+
+func TestHistoryLog_RedactBefore(t *testing.T) {
+	log := NewHistoryLog(filepath.Join(t.TempDir(), "history.jsonl"))
+	old := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	sessionFile := "/home/me/.claude/projects/app/0b6c3c1e-9a43-4a8e-b5d2-6f1f0e2d3c4b.jsonl"
+
+	require.NoError(t, log.Append(HistoryRecord{Time: old, Command: "claudecat cache restore", Args: []string{sessionFile}, Results: map[string]int{"files": 1}}))
+	require.NoError(t, log.Append(HistoryRecord{Time: old.AddDate(0, 1, 0), Command: "claudecat cache restore", Args: []string{sessionFile}}))
+
+	redacted, err := log.RedactBefore(old.AddDate(0, 0, 7))
+	require.NoError(t, err)
+	assert.Equal(t, 1, redacted)
+
+	records, err := log.Read()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.NotContains(t, records[0].Args[0], "0b6c3c1e")
+	assert.Contains(t, records[0].Args[0], "/home/me/.claude/projects/app/redacted-")
+	assert.Equal(t, 1, records[0].Results["files"])
+	assert.Equal(t, sessionFile, records[1].Args[0])
+}
+
+func TestHistoryLog_Encrypted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	plain := NewHistoryLog(path)
+	require.NoError(t, plain.Append(HistoryRecord{Command: "claudecat analyze", Success: true}))
+
+	enc, err := cache.NewEncryptor(make([]byte, 32))
+	require.NoError(t, err)
+	log := newHistoryLog(path, enc)
+	require.NoError(t, log.sealPlaintext())
+	require.NoError(t, log.Append(HistoryRecord{Command: "claudecat cache warm", Success: true}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "claudecat")
+
+	records, err := log.Read()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "claudecat analyze", records[0].Command)
+	assert.Equal(t, "claudecat cache warm", records[1].Command)
+
+	// Encrypted records are skipped without the key
+	records, err = plain.Read()
+	require.NoError(t, err)
+	assert.Empty(t, records)
+}
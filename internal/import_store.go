@@ -0,0 +1,193 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/penwyp/claudecat/cache"
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/models"
+)
+
+// ImportBatch is the usage imported from one export of another tool
+type ImportBatch struct {
+	Name       string              `json:"name"`
+	Format     string              `json:"format"`
+	Source     string              `json:"source"` // File the batch was imported from
+	ImportedAt time.Time           `json:"imported_at"`
+	Entries    []models.UsageEntry `json:"entries"`
+}
+
+// ImportStore keeps imported batches as one file each, encrypted like the cache when enabled
+type ImportStore struct {
+	dir string
+	enc *cache.Encryptor
+}
+
+// NewImportStore creates an import store in dir
+func NewImportStore(dir string) *ImportStore {
+	return &ImportStore{dir: dir}
+}
+
+// OpenImportStore opens the default import store, encrypted according to cache.encryption
+func OpenImportStore(cfg *config.Config) (*ImportStore, error) {
+	enc, err := cacheEncryptor(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load import encryption key: %w", err)
+	}
+	return &ImportStore{dir: DefaultImportDir(cfg), enc: enc}, nil
+}
+
+// DefaultImportDir returns the default location of imported batches, under cache.dir
+func DefaultImportDir(cfg *config.Config) string {
+	return filepath.Join(cacheDirPath(cfg), "imports")
+}
+
+// ValidateImportName checks that a batch name can be used as a file name
+func ValidateImportName(name string) error {
+	if name == "" || strings.HasPrefix(name, ".") || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid import name: %q", name)
+	}
+	return nil
+}
+
+// Save writes a batch, replacing any earlier import under the same name
+func (s *ImportStore) Save(batch ImportBatch) error {
+	if err := ValidateImportName(batch.Name); err != nil {
+		return err
+	}
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return fmt.Errorf("failed to encode import: %w", err)
+	}
+	if data, err = s.enc.SealFile(data); err != nil {
+		return fmt.Errorf("failed to encrypt import: %w", err)
+	}
+
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create import directory: %w", err)
+	}
+	path := s.path(batch.Name)
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write import: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace import: %w", err)
+	}
+	return nil
+}
+
+// Load returns every imported batch sorted by name
+func (s *ImportStore) Load() ([]ImportBatch, error) {
+	files, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read import directory: %w", err)
+	}
+
+	var batches []ImportBatch
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+		batch, err := s.load(filepath.Join(s.dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		batches = append(batches, batch)
+	}
+	sort.Slice(batches, func(i, j int) bool {
+		return batches[i].Name < batches[j].Name
+	})
+	return batches, nil
+}
+
+// Entries returns the entries of every imported batch
+func (s *ImportStore) Entries() ([]models.UsageEntry, error) {
+	batches, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+	var entries []models.UsageEntry
+	for _, batch := range batches {
+		entries = append(entries, batch.Entries...)
+	}
+	return entries, nil
+}
+
+// RedactBefore removes IDs from imported entries older than cutoff and returns how many it redacted.
+// Token counts and costs are kept.
+func (s *ImportStore) RedactBefore(cutoff time.Time) (int, error) {
+	if cutoff.IsZero() {
+		return 0, nil
+	}
+
+	batches, err := s.Load()
+	if err != nil {
+		return 0, err
+	}
+	redacted := 0
+	for _, batch := range batches {
+		changed := 0
+		for i, entry := range batch.Entries {
+			if !entry.Timestamp.Before(cutoff) {
+				continue
+			}
+			if batch.Entries[i] = models.RedactEntryIDs(entry); batch.Entries[i] != entry {
+				changed++
+			}
+		}
+		if changed == 0 {
+			continue
+		}
+		if err := s.Save(batch); err != nil {
+			return redacted, err
+		}
+		redacted += changed
+	}
+	return redacted, nil
+}
+
+// Remove deletes the batch imported under name
+func (s *ImportStore) Remove(name string) error {
+	if err := ValidateImportName(name); err != nil {
+		return err
+	}
+	if err := os.Remove(s.path(name)); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no import named %s", name)
+		}
+		return fmt.Errorf("failed to remove import: %w", err)
+	}
+	return nil
+}
+
+// path returns the file of the batch imported under name
+func (s *ImportStore) path(name string) string {
+	return filepath.Join(s.dir, name+".json")
+}
+
+// load reads one batch file
+func (s *ImportStore) load(path string) (ImportBatch, error) {
+	var batch ImportBatch
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return batch, fmt.Errorf("failed to read import: %w", err)
+	}
+	if data, err = s.enc.OpenFile(data); err != nil {
+		return batch, fmt.Errorf("failed to decrypt import %s: %w", filepath.Base(path), err)
+	}
+	if err := json.Unmarshal(data, &batch); err != nil {
+		return batch, fmt.Errorf("failed to parse import %s: %w", filepath.Base(path), err)
+	}
+	return batch, nil
+}
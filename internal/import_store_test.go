@@ -0,0 +1,108 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/cache"
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportStore_SaveLoadRemove(t *testing.T) {
+	store := NewImportStore(filepath.Join(t.TempDir(), "imports"))
+	entries, err := store.Entries()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	day := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	openai := ImportBatch{Name: "openai", Format: "openai-csv", Entries: []models.UsageEntry{{Timestamp: day, Model: "gpt-4o", TotalTokens: 10}}}
+	require.NoError(t, store.Save(ImportBatch{Name: "ccusage", Format: "ccusage", Entries: []models.UsageEntry{{Timestamp: day, TotalTokens: 1}}}))
+	require.NoError(t, store.Save(openai))
+
+	// Importing under the same name replaces the batch
+	openai.Entries = append(openai.Entries, models.UsageEntry{Timestamp: day.Add(time.Hour), Model: "gpt-4o", TotalTokens: 20})
+	require.NoError(t, store.Save(openai))
+
+	batches, err := store.Load()
+	require.NoError(t, err)
+	require.Len(t, batches, 2)
+	assert.Equal(t, "ccusage", batches[0].Name)
+	assert.Len(t, batches[1].Entries, 2)
+	entries, err = store.Entries()
+	require.NoError(t, err)
+	assert.Len(t, entries, 3)
+
+	require.NoError(t, store.Remove("ccusage"))
+	assert.ErrorContains(t, store.Remove("ccusage"), "no import named ccusage")
+	assert.ErrorContains(t, store.Save(ImportBatch{Name: "../escape"}), "invalid import name")
+	batches, err = store.Load()
+	require.NoError(t, err)
+	assert.Len(t, batches, 1)
+}
+
+func TestImportStore_Encrypted(t *testing.T) {
+	dir := t.TempDir()
+	enc, err := cache.NewEncryptor(make([]byte, 32))
+	require.NoError(t, err)
+	store := &ImportStore{dir: dir, enc: enc}
+	require.NoError(t, store.Save(ImportBatch{Name: "openai", Entries: []models.UsageEntry{{Model: "gpt-4o", Project: "secret-project"}}}))
+
+	data, err := os.ReadFile(filepath.Join(dir, "openai.json"))
+	require.NoError(t, err)
+	assert.True(t, cache.IsSealed(data))
+	assert.NotContains(t, string(data), "secret-project")
+
+	batches, err := store.Load()
+	require.NoError(t, err)
+	require.Len(t, batches, 1)
+	assert.Equal(t, "secret-project", batches[0].Entries[0].Project)
+
+	_, err = NewImportStore(dir).Load()
+	assert.ErrorContains(t, err, "encrypted but cache encryption is off")
+}
+
+func TestAnalyzer_ImportedUsage(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	day := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -1)
+	data := t.TempDir()
+	line := fmt.Sprintf(`{"type":"assistant","timestamp":%q,"sessionId":"s1","requestId":"req-1","message":{"id":"msg-1","model":"claude-3-5-sonnet-20241022","role":"assistant","usage":{"input_tokens":1000,"output_tokens":500}}}`,
+		day.Add(10*time.Hour).Format(time.RFC3339))
+	require.NoError(t, os.MkdirAll(filepath.Join(data, "project"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(data, "project", "session.jsonl"), []byte(line+"\n"), 0o644))
+
+	store := NewImportStore(t.TempDir())
+	require.NoError(t, store.Save(ImportBatch{Name: "openai", Entries: []models.UsageEntry{
+		{Timestamp: day, Model: "gpt-4o", TotalTokens: 30, CostUSD: 2, Project: "openai"},
+	}}))
+
+	cfg := config.DefaultConfig()
+	cfg.Cache.Dir = t.TempDir()
+	cfg.Data.PricingSource = "default"
+	cfg.Data.PricingOfflineMode = false
+	analyzer, err := NewAnalyzer(cfg)
+	require.NoError(t, err)
+	analyzer.SetImportStore(store)
+
+	results, err := analyzer.Analyze([]string{data})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "gpt-4o", results[0].Model)
+	assert.Equal(t, "openai", results[0].Project)
+	assert.Empty(t, results[0].SessionID, "imported usage is not part of a session")
+	assert.NotEmpty(t, results[1].SessionID)
+
+	entries, err := analyzer.LoadEntries([]string{data}, day.Add(time.Hour), time.Time{})
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "imported usage is filtered by date")
+
+	analyzer.SetIncludeImports(false)
+	results, err = analyzer.Analyze([]string{data})
+	require.NoError(t, err)
+	assert.Len(t, results, 1)
+}
@@ -0,0 +1,254 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/models"
+)
+
+// InfluxExporter writes session usage as InfluxDB line protocol to a file or write endpoint
+type InfluxExporter struct {
+	config  config.InfluxDBConfig
+	client  *http.Client
+	pending chan []models.SessionBlock // Latest blocks waiting for Run, see Queue
+}
+
+// NewInfluxExporter creates a new InfluxDB line protocol exporter
+func NewInfluxExporter(cfg config.InfluxDBConfig) *InfluxExporter {
+	if cfg.Measurement == "" {
+		cfg.Measurement = "claudecat"
+	}
+
+	return &InfluxExporter{
+		config:  cfg,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		pending: make(chan []models.SessionBlock, 1),
+	}
+}
+
+// Queue hands blocks to Run without waiting for the export. Blocks still waiting are replaced, so a
+// slow write endpoint skips intermediate refreshes instead of holding up the data updates.
+func (ie *InfluxExporter) Queue(blocks []models.SessionBlock) {
+	select {
+	case <-ie.pending:
+	default:
+	}
+	select {
+	case ie.pending <- blocks:
+	default: // Another refresh queued blocks in between; they are as recent
+	}
+}
+
+// Run exports the queued blocks until ctx is done, reporting failures to onError
+func (ie *InfluxExporter) Run(ctx context.Context, onError func(error)) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case blocks := <-ie.pending:
+			if err := ie.Export(ctx, blocks); err != nil && ctx.Err() == nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// Export writes points for the given session blocks to all configured destinations
+func (ie *InfluxExporter) Export(ctx context.Context, blocks []models.SessionBlock) error {
+	payload := ie.FormatBlocks(blocks)
+	if len(payload) == 0 {
+		return nil
+	}
+
+	if ie.config.File != "" {
+		if err := ie.writeFile(payload); err != nil {
+			return err
+		}
+	}
+
+	if ie.config.URL != "" {
+		if err := ie.post(ctx, payload); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// FormatBlocks renders session blocks as line protocol.
+// Each block produces one "<measurement>_session" point and one "<measurement>_usage"
+// point per model, timestamped at the block start so repeated exports overwrite
+// the same series instead of duplicating them.
+func (ie *InfluxExporter) FormatBlocks(blocks []models.SessionBlock) []byte {
+	var buf bytes.Buffer
+
+	for _, block := range blocks {
+		if block.IsGap || len(block.Entries) == 0 {
+			continue
+		}
+
+		timestamp := block.StartTime.UnixNano()
+		active := boolField("active", block.IsActive) // A field, so the series keeps one key once the block ends
+
+		// Per model usage points
+		perModel := make(map[string]*models.UsageEntry)
+		var modelNames []string
+		for _, entry := range block.Entries {
+			stat, exists := perModel[entry.Model]
+			if !exists {
+				stat = &models.UsageEntry{Model: entry.Model}
+				perModel[entry.Model] = stat
+				modelNames = append(modelNames, entry.Model)
+			}
+			stat.InputTokens += entry.InputTokens
+			stat.OutputTokens += entry.OutputTokens
+			stat.CacheCreationTokens += entry.CacheCreationTokens
+			stat.CacheReadTokens += entry.CacheReadTokens
+			stat.TotalTokens += entry.TotalTokens
+			stat.CostUSD += entry.CostUSD
+		}
+		sort.Strings(modelNames)
+
+		for _, model := range modelNames {
+			stat := perModel[model]
+			writePoint(&buf, ie.config.Measurement+"_usage",
+				[][2]string{{"model", model}, {"session_id", block.ID}},
+				[]string{
+					active,
+					intField("input_tokens", stat.InputTokens),
+					intField("output_tokens", stat.OutputTokens),
+					intField("cache_creation_tokens", stat.CacheCreationTokens),
+					intField("cache_read_tokens", stat.CacheReadTokens),
+					intField("total_tokens", stat.TotalTokens),
+					floatField("cost_usd", stat.CostUSD),
+				},
+				timestamp)
+		}
+
+		// Session aggregate point
+		writePoint(&buf, ie.config.Measurement+"_session",
+			[][2]string{{"session_id", block.ID}},
+			[]string{
+				active,
+				intField("input_tokens", block.TokenCounts.InputTokens),
+				intField("output_tokens", block.TokenCounts.OutputTokens),
+				intField("cache_creation_tokens", block.TokenCounts.CacheCreationTokens),
+				intField("cache_read_tokens", block.TokenCounts.CacheReadTokens),
+				intField("total_tokens", block.TokenCounts.TotalTokens()),
+				floatField("cost_usd", block.CostUSD),
+				intField("messages", block.SentMessagesCount),
+				intField("models", len(modelNames)),
+			},
+			timestamp)
+	}
+
+	return buf.Bytes()
+}
+
+// writeFile atomically replaces the export file with the latest snapshot
+func (ie *InfluxExporter) writeFile(payload []byte) error {
+	path := config.ExpandHome(ie.config.File)
+
+	dir := filepath.Dir(path)
+	if dir != "" && dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, payload, 0644); err != nil {
+		return fmt.Errorf("failed to write line protocol file: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to rename line protocol file: %w", err)
+	}
+
+	return nil
+}
+
+// post sends the payload to the configured write endpoint
+func (ie *InfluxExporter) post(ctx context.Context, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ie.config.URL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create write request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if ie.config.Token != "" {
+		req.Header.Set("Authorization", "Token "+ie.config.Token)
+	}
+
+	resp, err := ie.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to write points: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("write endpoint returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	return nil
+}
+
+// writePoint appends a single line protocol point to buf
+func writePoint(buf *bytes.Buffer, measurement string, tags [][2]string, fields []string, timestamp int64) {
+	buf.WriteString(escapeMeasurement(measurement))
+	for _, tag := range tags {
+		if tag[1] == "" {
+			continue
+		}
+		buf.WriteByte(',')
+		buf.WriteString(escapeTag(tag[0]))
+		buf.WriteByte('=')
+		buf.WriteString(escapeTag(tag[1]))
+	}
+	buf.WriteByte(' ')
+	buf.WriteString(strings.Join(fields, ","))
+	buf.WriteByte(' ')
+	buf.WriteString(strconv.FormatInt(timestamp, 10))
+	buf.WriteByte('\n')
+}
+
+// intField formats an integer field
+func intField(key string, value int) string {
+	return escapeTag(key) + "=" + strconv.Itoa(value) + "i"
+}
+
+// boolField formats a boolean field
+func boolField(key string, value bool) string {
+	return escapeTag(key) + "=" + strconv.FormatBool(value)
+}
+
+// floatField formats a float field
+func floatField(key string, value float64) string {
+	return escapeTag(key) + "=" + strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+var (
+	measurementEscaper = strings.NewReplacer(",", `\,`, " ", `\ `)
+	tagEscaper         = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+)
+
+// escapeMeasurement escapes a measurement name
+func escapeMeasurement(s string) string {
+	return measurementEscaper.Replace(s)
+}
+
+// escapeTag escapes a tag key, tag value or field key
+func escapeTag(s string) string {
+	return tagEscaper.Replace(s)
+}
@@ -0,0 +1,137 @@
+package internal
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testInfluxBlocks() []models.SessionBlock {
+	start := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	return []models.SessionBlock{
+		{
+			ID:        "2024-03-15T10:00:00Z",
+			StartTime: start,
+			EndTime:   start.Add(5 * time.Hour),
+			IsActive:  true,
+			Entries: []models.UsageEntry{
+				{Timestamp: start, Model: "claude-3-5-sonnet", InputTokens: 100, OutputTokens: 50, TotalTokens: 150, CostUSD: 0.5},
+				{Timestamp: start.Add(time.Minute), Model: "claude-3-opus", InputTokens: 10, OutputTokens: 5, TotalTokens: 15, CostUSD: 1.25},
+			},
+			TokenCounts:       models.TokenCounts{InputTokens: 110, OutputTokens: 55},
+			CostUSD:           1.75,
+			SentMessagesCount: 2,
+		},
+		{ID: "gap", StartTime: start.Add(-time.Hour), IsGap: true},
+	}
+}
+
+func TestInfluxExporter_FormatBlocks(t *testing.T) {
+	exporter := NewInfluxExporter(config.InfluxDBConfig{})
+
+	lines := strings.Split(strings.TrimSpace(string(exporter.FormatBlocks(testInfluxBlocks()))), "\n")
+	require.Len(t, lines, 3)
+
+	ts := "1710496800000000000"
+	assert.Equal(t, `claudecat_usage,model=claude-3-5-sonnet,session_id=2024-03-15T10:00:00Z active=true,input_tokens=100i,output_tokens=50i,cache_creation_tokens=0i,cache_read_tokens=0i,total_tokens=150i,cost_usd=0.5 `+ts, lines[0])
+	assert.Equal(t, `claudecat_usage,model=claude-3-opus,session_id=2024-03-15T10:00:00Z active=true,input_tokens=10i,output_tokens=5i,cache_creation_tokens=0i,cache_read_tokens=0i,total_tokens=15i,cost_usd=1.25 `+ts, lines[1])
+	assert.Equal(t, `claudecat_session,session_id=2024-03-15T10:00:00Z active=true,input_tokens=110i,output_tokens=55i,cache_creation_tokens=0i,cache_read_tokens=0i,total_tokens=165i,cost_usd=1.75,messages=2i,models=2i `+ts, lines[2])
+}
+
+func TestInfluxExporter_EscapesTags(t *testing.T) {
+	assert.Equal(t, `my\ model\,v1\=2`, escapeTag("my model,v1=2"))
+	assert.Equal(t, `usage\ data\,x=y`, escapeMeasurement("usage data,x=y"))
+}
+
+func TestInfluxExporter_ExportToFileAndEndpoint(t *testing.T) {
+	var received string
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+		auth = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	filePath := filepath.Join(t.TempDir(), "out", "usage.lp")
+	exporter := NewInfluxExporter(config.InfluxDBConfig{
+		File:        filePath,
+		URL:         server.URL,
+		Token:       "secret",
+		Measurement: "cc",
+	})
+
+	require.NoError(t, exporter.Export(context.Background(), testInfluxBlocks()))
+
+	data, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, string(data), received)
+	assert.Contains(t, received, "cc_session,")
+	assert.Equal(t, "Token secret", auth)
+}
+
+func TestInfluxExporter_EndpointError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad token", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	exporter := NewInfluxExporter(config.InfluxDBConfig{URL: server.URL})
+	err := exporter.Export(context.Background(), testInfluxBlocks())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bad token")
+}
+
+func TestInfluxExporter_QueueDoesNotWait(t *testing.T) {
+	release := make(chan struct{})
+	bodies := make(chan string, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies <- string(body)
+		<-release
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	exporter := NewInfluxExporter(config.InfluxDBConfig{URL: server.URL})
+	go exporter.Run(ctx, func(err error) { t.Error(err) })
+
+	blocks := testInfluxBlocks()
+	exporter.Queue(blocks)
+	first := <-bodies // The endpoint now holds the first export
+
+	// Refreshes during the slow write return at once, and only the latest is exported next
+	done := make(chan struct{})
+	go func() {
+		for i := 1; i <= 3; i++ {
+			latest := testInfluxBlocks()
+			latest[0].SentMessagesCount = i
+			exporter.Queue(latest)
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Queue waited for the export")
+	}
+
+	release <- struct{}{}
+	assert.Contains(t, first, "messages=2i")
+	assert.Contains(t, <-bodies, "messages=3i")
+}
@@ -0,0 +1,201 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/penwyp/claudecat/cache"
+)
+
+// sealedRecordPrefix marks a line holding an encrypted, base64-encoded record
+const sealedRecordPrefix = "enc1:"
+
+// jsonlLog is an append-only log of JSON records of type T, one per line. Once the file grows past
+// maxSize it is rewritten with the most recent keep records.
+type jsonlLog[T any] struct {
+	path    string
+	name    string // Names the log in errors, e.g. "history" or "alert"
+	maxSize int64
+	keep    int
+	enc     *cache.Encryptor // Encrypts records at rest when set
+}
+
+// Path returns the location of the log
+func (l *jsonlLog[T]) Path() string {
+	return l.path
+}
+
+// Append writes a record to the log, trimming old records when the log grows too large
+func (l *jsonlLog[T]) Append(record T) error {
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s log directory: %w", l.name, err)
+	}
+
+	data, err := l.encodeRecord(record)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open %s log: %w", l.name, err)
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write %s record: %w", l.name, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close %s log: %w", l.name, err)
+	}
+
+	if info, err := os.Stat(l.path); err == nil && info.Size() > l.maxSize {
+		return l.trim(l.keep)
+	}
+	return nil
+}
+
+// Read returns the records, oldest first. Malformed lines and encrypted lines that cannot be
+// decrypted, because encryption is off or the key changed, are skipped.
+func (l *jsonlLog[T]) Read() ([]T, error) {
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s log: %w", l.name, err)
+	}
+
+	var records []T
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), int(l.maxSize))
+	for scanner.Scan() {
+		record, ok := l.decodeRecord(scanner.Bytes())
+		if !ok {
+			continue
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return records, fmt.Errorf("failed to parse %s log: %w", l.name, err)
+	}
+
+	return records, nil
+}
+
+// trim rewrites the log keeping only the most recent keep records
+func (l *jsonlLog[T]) trim(keep int) error {
+	records, err := l.Read()
+	if err != nil {
+		return err
+	}
+	if len(records) > keep {
+		records = records[len(records)-keep:]
+	}
+	return l.rewrite(records)
+}
+
+// redactRecords applies redact to every record, which reports whether it changed the record, and
+// rewrites the log if any changed. It returns the number of changed records.
+func (l *jsonlLog[T]) redactRecords(redact func(record *T) bool) (int, error) {
+	records, err := l.Read()
+	if err != nil {
+		return 0, err
+	}
+
+	redacted := 0
+	for i := range records {
+		if redact(&records[i]) {
+			redacted++
+		}
+	}
+	if redacted == 0 {
+		return 0, nil
+	}
+	return redacted, l.rewrite(records)
+}
+
+// rewrite atomically replaces the log with records
+func (l *jsonlLog[T]) rewrite(records []T) error {
+	var buf bytes.Buffer
+	for _, record := range records {
+		data, err := l.encodeRecord(record)
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+
+	tmpFile := l.path + ".tmp"
+	if err := os.WriteFile(tmpFile, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write %s log: %w", l.name, err)
+	}
+	if err := os.Rename(tmpFile, l.path); err != nil {
+		os.Remove(tmpFile)
+		return fmt.Errorf("failed to replace %s log: %w", l.name, err)
+	}
+	return nil
+}
+
+// encodeRecord marshals a record into a line, encrypting it when the log has an encryptor
+func (l *jsonlLog[T]) encodeRecord(record T) ([]byte, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s record: %w", l.name, err)
+	}
+	if l.enc == nil {
+		return data, nil
+	}
+	sealed, err := l.enc.Seal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt %s record: %w", l.name, err)
+	}
+	return []byte(sealedRecordPrefix + base64.StdEncoding.EncodeToString(sealed)), nil
+}
+
+// decodeRecord parses a line written by encodeRecord
+func (l *jsonlLog[T]) decodeRecord(line []byte) (T, bool) {
+	var record T
+	if encoded, ok := bytes.CutPrefix(line, []byte(sealedRecordPrefix)); ok {
+		if l.enc == nil {
+			return record, false
+		}
+		sealed, err := base64.StdEncoding.DecodeString(string(encoded))
+		if err != nil {
+			return record, false
+		}
+		if line, err = l.enc.Open(sealed); err != nil {
+			return record, false
+		}
+	}
+	if err := json.Unmarshal(line, &record); err != nil {
+		return record, false
+	}
+	return record, true
+}
+
+// sealPlaintext rewrites the log encrypted if it still contains unencrypted records
+func (l *jsonlLog[T]) sealPlaintext() error {
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read %s log: %w", l.name, err)
+	}
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(line) > 0 && !bytes.HasPrefix(line, []byte(sealedRecordPrefix)) {
+			records, err := l.Read()
+			if err != nil {
+				return err
+			}
+			return l.rewrite(records)
+		}
+	}
+	return nil
+}
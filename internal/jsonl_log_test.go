@@ -0,0 +1,91 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/penwyp/claudecat/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testLogRecord is a record of the logs in these tests
+type testLogRecord struct {
+	N int `json:"n"`
+}
+
+func TestJSONLLog_AppendTrims(t *testing.T) {
+	enc, err := cache.NewEncryptor(make([]byte, 32))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		enc  *cache.Encryptor
+	}{
+		{"plaintext", nil},
+		{"encrypted", enc},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "nested", "test.jsonl")
+			log := &jsonlLog[testLogRecord]{path: path, name: "test", maxSize: 40, keep: 3, enc: tt.enc}
+
+			for i := 1; i <= 10; i++ {
+				require.NoError(t, log.Append(testLogRecord{N: i}))
+			}
+			info, err := os.Stat(path)
+			require.NoError(t, err)
+			assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+			// Trimming keeps the most recent records once the log grows past maxSize
+			records, err := log.Read()
+			require.NoError(t, err)
+			require.GreaterOrEqual(t, len(records), 3)
+			assert.Less(t, len(records), 10)
+			for i, record := range records {
+				assert.Equal(t, 10-len(records)+1+i, record.N)
+			}
+		})
+	}
+}
+
+func TestJSONLLog_ReadSkipsUnreadableLines(t *testing.T) {
+	enc, err := cache.NewEncryptor(make([]byte, 32))
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "test.jsonl")
+	sealed := &jsonlLog[testLogRecord]{path: path, name: "test", maxSize: 1 << 20, keep: 10, enc: enc}
+	require.NoError(t, sealed.Append(testLogRecord{N: 1}))
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = file.WriteString("{not json\n{\"n\":2}\n")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	tests := []struct {
+		name string
+		enc  *cache.Encryptor
+		want []testLogRecord
+	}{
+		{"with the key", enc, []testLogRecord{{N: 1}, {N: 2}}},
+		{"without the key", nil, []testLogRecord{{N: 2}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := &jsonlLog[testLogRecord]{path: path, name: "test", maxSize: 1 << 20, keep: 10, enc: tt.enc}
+			records, err := log.Read()
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, records)
+		})
+	}
+
+	// Sealing rewrites the plaintext records encrypted and drops the malformed line
+	require.NoError(t, sealed.sealPlaintext())
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), `"n"`)
+	records, err := sealed.Read()
+	require.NoError(t, err)
+	assert.Equal(t, []testLogRecord{{N: 1}, {N: 2}}, records)
+}
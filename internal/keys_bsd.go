@@ -0,0 +1,11 @@
+//go:build !lite && (darwin || freebsd || netbsd || openbsd)
+
+package internal
+
+import "golang.org/x/sys/unix"
+
+// Terminal attribute ioctl requests
+const (
+	ioctlGetTermios = unix.TIOCGETA
+	ioctlSetTermios = unix.TIOCSETA
+)
@@ -0,0 +1,11 @@
+//go:build !lite && linux
+
+package internal
+
+import "golang.org/x/sys/unix"
+
+// Terminal attribute ioctl requests
+const (
+	ioctlGetTermios = unix.TCGETS
+	ioctlSetTermios = unix.TCSETS
+)
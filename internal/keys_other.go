@@ -0,0 +1,8 @@
+//go:build !lite && !(darwin || linux || freebsd || netbsd || openbsd)
+
+package internal
+
+// readKeys is not supported on this platform; the returned channel never receives
+func readKeys() (<-chan byte, func()) {
+	return make(chan byte), func() {}
+}
@@ -0,0 +1,41 @@
+//go:build !lite && (darwin || linux || freebsd || netbsd || openbsd)
+
+package internal
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// readKeys switches the terminal on stdin to unbuffered input without echo and sends each key
+// pressed on the returned channel. The restore function returns the terminal to its previous state.
+// If stdin is not a terminal the channel never receives.
+func readKeys() (<-chan byte, func()) {
+	keys := make(chan byte)
+	fd := int(os.Stdin.Fd())
+	previous, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	if err != nil {
+		return keys, func() {}
+	}
+
+	cbreak := *previous
+	cbreak.Lflag &^= unix.ICANON | unix.ECHO
+	cbreak.Cc[unix.VMIN] = 1
+	cbreak.Cc[unix.VTIME] = 0
+	if err := unix.IoctlSetTermios(fd, ioctlSetTermios, &cbreak); err != nil {
+		return keys, func() {}
+	}
+
+	go func() {
+		buf := make([]byte, 1)
+		for {
+			if n, err := os.Stdin.Read(buf); err != nil {
+				return
+			} else if n == 1 {
+				keys <- buf[0]
+			}
+		}
+	}()
+	return keys, func() { _ = unix.IoctlSetTermios(fd, ioctlSetTermios, previous) }
+}
@@ -0,0 +1,99 @@
+package internal
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/events"
+	"github.com/penwyp/claudecat/humanize"
+	"github.com/penwyp/claudecat/models"
+)
+
+// LimitWatcher raises a notice once per active session for each cost threshold crossed and
+// for every new limit message logged by Claude
+type LimitWatcher struct {
+	costLimit      float64
+	warnThreshold  float64
+	alertThreshold float64
+
+	sessionID string
+	notified  int // 0 none, 1 warn threshold, 2 alert threshold
+	lastLimit time.Time
+}
+
+// NewLimitWatcher creates a watcher using the plan's session cost limit and configured thresholds
+func NewLimitWatcher(cfg config.SubscriptionConfig) *LimitWatcher {
+	return &LimitWatcher{
+		costLimit:      models.GetPlanLimits(cfg.Plan).CostLimit,
+		warnThreshold:  cfg.WarnThreshold,
+		alertThreshold: cfg.AlertThreshold,
+	}
+}
+
+// Observe returns the notices caused by the active session in blocks since the last call
+func (w *LimitWatcher) Observe(blocks []models.SessionBlock) []events.Notice {
+	var active *models.SessionBlock
+	for i := range blocks {
+		if blocks[i].IsActive && !blocks[i].IsGap {
+			active = &blocks[i]
+			break
+		}
+	}
+	if active == nil {
+		return nil
+	}
+	if active.ID != w.sessionID {
+		w.sessionID = active.ID
+		w.notified = 0
+	}
+
+	var notices []events.Notice
+	for _, limit := range active.LimitMessages {
+		if !limit.Timestamp.After(w.lastLimit) {
+			continue
+		}
+		w.lastLimit = limit.Timestamp
+		notices = append(notices, events.Notice{
+			Level:   events.NoticeError,
+			Message: fmt.Sprintf("Limit reached at %s: %s", limit.Timestamp.Local().Format("15:04"), limit.Message),
+			Crossing: &events.Crossing{
+				Metric:    AlertMetricLimitMessage,
+				Value:     active.CostUSD,
+				SessionID: active.ID,
+			},
+		})
+	}
+
+	if w.costLimit > 0 {
+		used := active.CostUSD / w.costLimit
+		switch {
+		case w.alertThreshold > 0 && used >= w.alertThreshold && w.notified < 2:
+			w.notified = 2
+			notices = append(notices, events.Notice{
+				Level:   events.NoticeError,
+				Message: fmt.Sprintf("Session at %.0f%% of the %s cost limit", used*100, humanize.Cost(w.costLimit)),
+				Crossing: &events.Crossing{
+					Metric:    AlertMetricSessionCost,
+					Value:     active.CostUSD,
+					Threshold: w.costLimit * w.alertThreshold,
+					SessionID: active.ID,
+				},
+			})
+		case w.warnThreshold > 0 && used >= w.warnThreshold && w.notified < 1:
+			w.notified = 1
+			notices = append(notices, events.Notice{
+				Level:   events.NoticeWarning,
+				Message: fmt.Sprintf("Session at %.0f%% of the %s cost limit", used*100, humanize.Cost(w.costLimit)),
+				Crossing: &events.Crossing{
+					Metric:    AlertMetricSessionCost,
+					Value:     active.CostUSD,
+					Threshold: w.costLimit * w.warnThreshold,
+					SessionID: active.ID,
+				},
+			})
+		}
+	}
+
+	return notices
+}
@@ -0,0 +1,64 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/events"
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimitWatcher_ThresholdsOncePerSession(t *testing.T) {
+	watcher := NewLimitWatcher(config.SubscriptionConfig{Plan: models.PlanPro, WarnThreshold: 0.8, AlertThreshold: 0.95})
+	block := models.SessionBlock{ID: "s1", IsActive: true, CostUSD: 10}
+
+	assert.Empty(t, watcher.Observe([]models.SessionBlock{block}))
+
+	block.CostUSD = 15 // 83% of the $18 Pro limit
+	notices := watcher.Observe([]models.SessionBlock{block})
+	require.Len(t, notices, 1)
+	assert.Equal(t, events.NoticeWarning, notices[0].Level)
+	require.NotNil(t, notices[0].Crossing)
+	assert.Equal(t, AlertMetricSessionCost, notices[0].Crossing.Metric)
+	assert.InDelta(t, 15, notices[0].Crossing.Value, 0.0001)
+	assert.InDelta(t, 18*0.8, notices[0].Crossing.Threshold, 0.0001)
+	assert.Equal(t, "s1", notices[0].Crossing.SessionID)
+	assert.Empty(t, watcher.Observe([]models.SessionBlock{block}))
+
+	block.CostUSD = 18
+	notices = watcher.Observe([]models.SessionBlock{block})
+	require.Len(t, notices, 1)
+	assert.Equal(t, events.NoticeError, notices[0].Level)
+
+	// A new session starts over
+	next := models.SessionBlock{ID: "s2", IsActive: true, CostUSD: 15}
+	assert.Len(t, watcher.Observe([]models.SessionBlock{next}), 1)
+}
+
+func TestLimitWatcher_NewLimitMessages(t *testing.T) {
+	watcher := NewLimitWatcher(config.SubscriptionConfig{Plan: models.PlanPro})
+	first := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	block := models.SessionBlock{
+		ID:            "s1",
+		IsActive:      true,
+		LimitMessages: []models.LimitMessage{{Message: "usage limit reached", Timestamp: first}},
+	}
+
+	notices := watcher.Observe([]models.SessionBlock{block})
+	require.Len(t, notices, 1)
+	require.NotNil(t, notices[0].Crossing)
+	assert.Equal(t, AlertMetricLimitMessage, notices[0].Crossing.Metric)
+	assert.Empty(t, watcher.Observe([]models.SessionBlock{block}))
+
+	block.LimitMessages = append(block.LimitMessages, models.LimitMessage{Message: "again", Timestamp: first.Add(time.Minute)})
+	notices = watcher.Observe([]models.SessionBlock{block})
+	require.Len(t, notices, 1)
+	assert.Contains(t, notices[0].Message, "again")
+
+	// Inactive sessions produce no notices
+	block.IsActive = false
+	assert.Empty(t, watcher.Observe([]models.SessionBlock{block}))
+}
@@ -0,0 +1,184 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/penwyp/claudecat/cache"
+	"github.com/penwyp/claudecat/calculations"
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/models"
+)
+
+const (
+	// snapshotEntryWindow is how far back blocks keep their entries in a snapshot; older blocks keep only totals
+	snapshotEntryWindow = 24 * time.Hour
+	// snapshotSaveInterval is the minimum time between snapshot writes while monitoring
+	snapshotSaveInterval = time.Minute
+	// maxSnapshotAge is the age beyond which a snapshot is too stale to show at startup
+	maxSnapshotAge = 7 * 24 * time.Hour
+)
+
+// MonitorSnapshot is the last data rendered by the monitor, shown at the next start while the
+// usage data is loaded
+type MonitorSnapshot struct {
+	SavedAt time.Time                     `json:"saved_at"`
+	Blocks  []models.SessionBlock         `json:"blocks"`
+	Metrics *calculations.RealtimeMetrics `json:"metrics,omitempty"`
+}
+
+// SnapshotStore persists the monitor snapshot in a single file, encrypted like the cache when enabled
+type SnapshotStore struct {
+	path            string
+	enc             *cache.Encryptor
+	redactAfterDays int // Entries older than this many days are saved with their IDs redacted; zero disables
+	lastSave        time.Time
+}
+
+// NewSnapshotStore creates a snapshot store at path
+func NewSnapshotStore(path string) *SnapshotStore {
+	return &SnapshotStore{path: path}
+}
+
+// OpenSnapshotStore opens the default snapshot store, encrypted according to cache.encryption
+func OpenSnapshotStore(cfg *config.Config) (*SnapshotStore, error) {
+	cacheDir := cacheDirPath(cfg)
+	enc, err := cache.LoadEncryptor(cfg.Cache.Encryption, cacheDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load snapshot encryption key: %w", err)
+	}
+	return &SnapshotStore{path: DefaultSnapshotPath(cfg), enc: enc, redactAfterDays: cfg.Retention.RedactIDsAfterDays}, nil
+}
+
+// DefaultSnapshotPath returns the default location of the monitor snapshot, under cache.dir
+func DefaultSnapshotPath(cfg *config.Config) string {
+	return filepath.Join(cacheDirPath(cfg), "monitor_snapshot.json")
+}
+
+// Load returns the saved snapshot, or nil if there is none or it is older than maxSnapshotAge.
+// Sessions that have ended since the snapshot was saved are marked inactive.
+func (s *SnapshotStore) Load(now time.Time) (*MonitorSnapshot, error) {
+	snapshot, err := s.read()
+	if snapshot == nil || err != nil {
+		return nil, err
+	}
+	if now.Sub(snapshot.SavedAt) > maxSnapshotAge {
+		return nil, nil
+	}
+	for i := range snapshot.Blocks {
+		if snapshot.Blocks[i].IsActive && !snapshot.Blocks[i].EndTime.After(now) {
+			snapshot.Blocks[i].IsActive = false
+		}
+	}
+	return snapshot, nil
+}
+
+// read returns the saved snapshot whatever its age, or nil if there is none
+func (s *SnapshotStore) read() (*MonitorSnapshot, error) {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	if cache.IsSealed(data) {
+		if s.enc == nil {
+			return nil, fmt.Errorf("snapshot is encrypted but cache encryption is off")
+		}
+		if data, err = s.enc.Open(data); err != nil {
+			return nil, fmt.Errorf("failed to decrypt snapshot: %w", err)
+		}
+	}
+
+	var snapshot MonitorSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot: %w", err)
+	}
+	return &snapshot, nil
+}
+
+// RedactBefore removes IDs from the entries in the saved snapshot that are older than cutoff and
+// returns how many it redacted
+func (s *SnapshotStore) RedactBefore(cutoff time.Time) (int, error) {
+	if cutoff.IsZero() {
+		return 0, nil
+	}
+
+	snapshot, err := s.read()
+	if snapshot == nil || err != nil {
+		return 0, err
+	}
+	redacted := 0
+	for _, block := range snapshot.Blocks {
+		for i, entry := range block.Entries {
+			if !entry.Timestamp.Before(cutoff) {
+				continue
+			}
+			if block.Entries[i] = models.RedactEntryIDs(entry); block.Entries[i] != entry {
+				redacted++
+			}
+		}
+	}
+	if redacted == 0 {
+		return 0, nil
+	}
+	return redacted, s.Save(*snapshot)
+}
+
+// Save writes the snapshot, dropping the entries of blocks that ended more than snapshotEntryWindow
+// before it was taken as well as message and request IDs, which the monitor does not show. The
+// other IDs of entries older than the retention period are redacted.
+func (s *SnapshotStore) Save(snapshot MonitorSnapshot) error {
+	cutoff := models.RedactionCutoff(s.redactAfterDays, snapshot.SavedAt)
+	blocks := make([]models.SessionBlock, len(snapshot.Blocks))
+	for i, block := range snapshot.Blocks {
+		if snapshot.SavedAt.Sub(block.EndTime) > snapshotEntryWindow {
+			block.Entries = nil
+		} else {
+			entries := make([]models.UsageEntry, len(block.Entries))
+			for j, entry := range block.Entries {
+				entry.MessageID, entry.RequestID = "", ""
+				if entry.Timestamp.Before(cutoff) {
+					entry = models.RedactEntryIDs(entry)
+				}
+				entries[j] = entry
+			}
+			block.Entries = entries
+		}
+		blocks[i] = block
+	}
+	snapshot.Blocks = blocks
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	if s.enc != nil {
+		if data, err = s.enc.Seal(data); err != nil {
+			return fmt.Errorf("failed to encrypt snapshot: %w", err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace snapshot: %w", err)
+	}
+	s.lastSave = snapshot.SavedAt
+	return nil
+}
+
+// Due reports whether snapshotSaveInterval has passed since the last save
+func (s *SnapshotStore) Due(now time.Time) bool {
+	return now.Sub(s.lastSave) >= snapshotSaveInterval
+}
@@ -0,0 +1,74 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/cache"
+	"github.com/penwyp/claudecat/calculations"
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotStore_SaveAndLoad(t *testing.T) {
+	store := NewSnapshotStore(filepath.Join(t.TempDir(), "nested", "monitor_snapshot.json"))
+	saved := time.Date(2025, 6, 2, 12, 0, 0, 0, time.UTC)
+
+	snapshot, err := store.Load(saved)
+	require.NoError(t, err)
+	assert.Nil(t, snapshot)
+	assert.True(t, store.Due(saved))
+
+	entry := models.UsageEntry{Timestamp: saved.Add(-time.Hour), Model: "claude-sonnet-4-20250514", TotalTokens: 100, MessageID: "msg_1", RequestID: "req_1"}
+	old := models.SessionBlock{ID: "old", StartTime: saved.Add(-72 * time.Hour), EndTime: saved.Add(-67 * time.Hour), Entries: []models.UsageEntry{entry}, CostUSD: 3}
+	active := models.SessionBlock{ID: "active", StartTime: saved.Add(-2 * time.Hour), EndTime: saved.Add(3 * time.Hour), IsActive: true, Entries: []models.UsageEntry{entry}}
+	require.NoError(t, store.Save(MonitorSnapshot{
+		SavedAt: saved,
+		Blocks:  []models.SessionBlock{old, active},
+		Metrics: &calculations.RealtimeMetrics{CurrentTokens: 100},
+	}))
+	assert.False(t, store.Due(saved.Add(time.Second)))
+	assert.Equal(t, "msg_1", active.Entries[0].MessageID, "the live blocks are left untouched")
+
+	snapshot, err = store.Load(saved.Add(time.Minute))
+	require.NoError(t, err)
+	require.NotNil(t, snapshot)
+	require.Len(t, snapshot.Blocks, 2)
+	assert.Empty(t, snapshot.Blocks[0].Entries)
+	assert.Equal(t, 3.0, snapshot.Blocks[0].CostUSD)
+	require.Len(t, snapshot.Blocks[1].Entries, 1)
+	assert.Empty(t, snapshot.Blocks[1].Entries[0].MessageID)
+	assert.True(t, snapshot.Blocks[1].IsActive)
+	assert.Equal(t, 100, snapshot.Metrics.CurrentTokens)
+
+	// Sessions that ended since the snapshot are shown as inactive, and stale snapshots are ignored
+	snapshot, err = store.Load(saved.Add(4 * time.Hour))
+	require.NoError(t, err)
+	assert.False(t, snapshot.Blocks[1].IsActive)
+	snapshot, err = store.Load(saved.Add(maxSnapshotAge + time.Hour))
+	require.NoError(t, err)
+	assert.Nil(t, snapshot)
+}
+
+func TestSnapshotStore_Encrypted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "monitor_snapshot.json")
+	enc, err := cache.NewEncryptor(make([]byte, 32))
+	require.NoError(t, err)
+	store := &SnapshotStore{path: path, enc: enc}
+	saved := time.Now()
+	require.NoError(t, store.Save(MonitorSnapshot{SavedAt: saved, Blocks: []models.SessionBlock{{ID: "block-1"}}}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "block-1")
+
+	snapshot, err := store.Load(saved)
+	require.NoError(t, err)
+	require.Len(t, snapshot.Blocks, 1)
+
+	_, err = NewSnapshotStore(path).Load(saved)
+	assert.Error(t, err)
+}
@@ -0,0 +1,126 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/penwyp/claudecat/config"
+)
+
+// Notifier delivers alerts through the channels configured in limits.notifications
+type Notifier struct {
+	channels   []config.NotificationType
+	webhookURL string
+	client     *http.Client
+}
+
+// NewNotifier creates a notifier from the limits configuration
+func NewNotifier(cfg config.LimitsConfig) *Notifier {
+	return &Notifier{
+		channels:   cfg.Notifications,
+		webhookURL: cfg.WebhookURL,
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Notify sends the alert to every configured channel, returning the combined failures
+func (n *Notifier) Notify(ctx context.Context, title, message string) error {
+	var failures []string
+	for _, channel := range n.channels {
+		var err error
+		switch channel {
+		case config.NotifyDesktop:
+			err = notifyDesktop(ctx, title, message)
+		case config.NotifySound:
+			_, err = fmt.Fprint(os.Stderr, "\a")
+		case config.NotifyWebhook:
+			err = n.postWebhook(ctx, title, message)
+		default:
+			err = fmt.Errorf("unsupported notification channel")
+		}
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", channel, err))
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("notification failed: %s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// postWebhook posts the alert as JSON to the configured webhook URL
+func (n *Notifier) postWebhook(ctx context.Context, title, message string) error {
+	if n.webhookURL == "" {
+		return fmt.Errorf("no webhook_url configured")
+	}
+
+	body, err := sonic.Marshal(map[string]string{
+		"title":   title,
+		"message": message,
+		"time":    time.Now().Format(time.RFC3339),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// PostWebhook posts a JSON payload to url, failing on a status code of 300 or more
+func PostWebhook(ctx context.Context, url string, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := (&http.Client{Timeout: 10 * time.Second}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// notifyDesktop shows a desktop notification using the platform's notification tool
+func notifyDesktop(ctx context.Context, title, message string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %q with title %q", message, title)
+		cmd = exec.CommandContext(ctx, "osascript", "-e", script)
+	case "linux", "freebsd", "openbsd", "netbsd":
+		cmd = exec.CommandContext(ctx, "notify-send", title, message)
+	default:
+		return fmt.Errorf("desktop notifications are not supported on %s", runtime.GOOS)
+	}
+	return cmd.Run()
+}
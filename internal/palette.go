@@ -0,0 +1,170 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/penwyp/claudecat/calculations"
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/models"
+)
+
+// paletteUsage is the usage line of each command palette command
+var paletteUsage = map[string]string{
+	"range":  "range <7d|24h|2w|today|all|YYYY-MM-DD>",
+	"group":  "group <" + strings.Join(calculations.GroupByKeys, "|") + ">",
+	"export": "export <csv|json> <file>",
+	"theme":  "theme <dark|light|high-contrast|auto>",
+	"about":  "about",
+	"close":  "close",
+	"help":   "help",
+}
+
+// PaletteCommand is a command entered in the command palette of the monitor, such as ":range 7d"
+type PaletteCommand struct {
+	Name string
+	Args []string
+}
+
+// ParsePaletteCommand splits a palette line into its command and arguments and checks them
+func ParsePaletteCommand(line string) (PaletteCommand, error) {
+	fields := strings.Fields(strings.TrimPrefix(strings.TrimSpace(line), ":"))
+	if len(fields) == 0 {
+		return PaletteCommand{}, fmt.Errorf("empty command")
+	}
+	command := PaletteCommand{Name: strings.ToLower(fields[0]), Args: fields[1:]}
+	usage, ok := paletteUsage[command.Name]
+	if !ok {
+		return command, fmt.Errorf("unknown command %q; try :help", command.Name)
+	}
+
+	switch command.Name {
+	case "export":
+		if len(command.Args) < 2 {
+			return command, fmt.Errorf("usage: :%s", usage)
+		}
+		format := strings.ToLower(command.Args[0])
+		if format != "csv" && format != "json" {
+			return command, fmt.Errorf("invalid export format %q (valid: csv, json)", command.Args[0])
+		}
+		// The file name may contain spaces
+		command.Args = []string{format, strings.Join(command.Args[1:], " ")}
+	case "about", "close", "help":
+		if len(command.Args) != 0 {
+			return command, fmt.Errorf("usage: :%s", usage)
+		}
+	default:
+		if len(command.Args) != 1 {
+			return command, fmt.Errorf("usage: :%s", usage)
+		}
+	}
+
+	switch command.Name {
+	case "group":
+		command.Args[0] = strings.ToLower(command.Args[0])
+		if !isGroupByKey(command.Args[0]) {
+			return command, fmt.Errorf("invalid group %q (valid: %s)", command.Args[0], strings.Join(calculations.GroupByKeys, ", "))
+		}
+	case "theme":
+		command.Args[0] = strings.ToLower(command.Args[0])
+		if err := config.ValidateTheme(command.Args[0]); err != nil {
+			return command, err
+		}
+	case "range":
+		if _, err := parsePaletteRange(command.Args[0], time.Now(), time.Local); err != nil {
+			return command, err
+		}
+	}
+	return command, nil
+}
+
+// PaletteHelp lists the commands of the command palette
+func PaletteHelp() string {
+	names := make([]string, 0, len(paletteUsage))
+	for name := range paletteUsage {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	usages := make([]string, len(names))
+	for i, name := range names {
+		usages[i] = ":" + paletteUsage[name]
+	}
+	return strings.Join(usages, "  ")
+}
+
+// PaletteView is the usage report the command palette shows below the monitor
+type PaletteView struct {
+	Range   string // As entered, e.g. "7d", "today" or "all"
+	GroupBy string
+}
+
+// Since returns the start of the view's range at now, or the zero time for all usage
+func (v PaletteView) Since(now time.Time, loc *time.Location) (time.Time, error) {
+	return parsePaletteRange(v.Range, now, loc)
+}
+
+// Report groups the results within the view's range, largest cost first; time periods stay chronological
+func (v PaletteView) Report(results []models.AnalysisResult, now time.Time, loc *time.Location) ([]calculations.GroupResult, error) {
+	since, err := v.Since(now, loc)
+	if err != nil {
+		return nil, err
+	}
+	groups := make(map[string][]models.AnalysisResult)
+	for _, result := range results {
+		if result.Timestamp.Before(since) {
+			continue
+		}
+		// Time periods are local days and hours
+		result.Timestamp = result.Timestamp.In(loc)
+		key := calculations.GroupKey(result, v.GroupBy)
+		groups[key] = append(groups[key], result)
+	}
+
+	report := calculations.NewGroupAggregator(0).Aggregate(groups)
+	if !calculations.IsTimeGrouping(v.GroupBy) {
+		sort.SliceStable(report, func(i, j int) bool {
+			return report[i].Result.CostUSD > report[j].Result.CostUSD
+		})
+	}
+	return report, nil
+}
+
+// parsePaletteRange parses the start of a range given as a length before now (7d, 2w, 24h, 90m), today,
+// all, or a date in loc
+func parsePaletteRange(value string, now time.Time, loc *time.Location) (time.Time, error) {
+	value = strings.ToLower(value)
+	switch value {
+	case "all":
+		return time.Time{}, nil
+	case "", "today":
+		return calculations.StartOfDay(now, loc), nil
+	}
+	if unit := value[len(value)-1]; unit == 'd' || unit == 'w' {
+		if n, err := strconv.Atoi(value[:len(value)-1]); err == nil && n > 0 {
+			if unit == 'w' {
+				n *= 7
+			}
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return now.Add(-d), nil
+	}
+	if day, err := time.ParseInLocation("2006-01-02", value, loc); err == nil {
+		return day, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid range %q (e.g. 7d, 24h, 2w, today, all or YYYY-MM-DD)", value)
+}
+
+// isGroupByKey reports whether key is a field the palette groups by
+func isGroupByKey(key string) bool {
+	for _, k := range calculations.GroupByKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePaletteCommand(t *testing.T) {
+	command, err := ParsePaletteCommand(":range 7d")
+	require.NoError(t, err)
+	assert.Equal(t, PaletteCommand{Name: "range", Args: []string{"7d"}}, command)
+
+	command, err = ParsePaletteCommand("GROUP Model")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"model"}, command.Args)
+
+	command, err = ParsePaletteCommand(":export CSV ~/usage report.csv")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"csv", "~/usage report.csv"}, command.Args)
+
+	for _, line := range []string{
+		":",
+		":quit",
+		":range",
+		":range soon",
+		":group color",
+		":export xml out.xml",
+		":export csv",
+		":theme solarized",
+		":close now",
+		":about me",
+	} {
+		_, err := ParsePaletteCommand(line)
+		assert.Error(t, err, line)
+	}
+}
+
+func TestParsePaletteRange(t *testing.T) {
+	loc := time.UTC
+	now := time.Date(2025, 6, 10, 14, 30, 0, 0, loc)
+	for value, want := range map[string]time.Time{
+		"7d":         time.Date(2025, 6, 3, 14, 30, 0, 0, loc),
+		"2w":         time.Date(2025, 5, 27, 14, 30, 0, 0, loc),
+		"24h":        time.Date(2025, 6, 9, 14, 30, 0, 0, loc),
+		"today":      time.Date(2025, 6, 10, 0, 0, 0, 0, loc),
+		"2025-06-01": time.Date(2025, 6, 1, 0, 0, 0, 0, loc),
+		"all":        {},
+	} {
+		since, err := parsePaletteRange(value, now, loc)
+		require.NoError(t, err, value)
+		assert.Equal(t, want, since, value)
+	}
+	_, err := parsePaletteRange("0d", now, loc)
+	assert.Error(t, err)
+}
+
+func TestPaletteView_Report(t *testing.T) {
+	now := time.Date(2025, 6, 10, 14, 0, 0, 0, time.UTC)
+	results := []models.AnalysisResult{
+		{Timestamp: now.AddDate(0, 0, -10), Model: "claude-opus-4", TotalTokens: 9000, CostUSD: 90, Count: 1},
+		{Timestamp: now.Add(-48 * time.Hour), Model: "claude-sonnet-4", TotalTokens: 1000, CostUSD: 1, Count: 1},
+		{Timestamp: now.Add(-2 * time.Hour), Model: "claude-opus-4", TotalTokens: 2000, CostUSD: 5, Count: 1},
+		{Timestamp: now.Add(-time.Hour), Model: "claude-sonnet-4", TotalTokens: 500, CostUSD: 0.5, Count: 1},
+	}
+
+	report, err := PaletteView{Range: "7d", GroupBy: "model"}.Report(results, now, time.UTC)
+	require.NoError(t, err)
+	require.Len(t, report, 2)
+	assert.Equal(t, "claude-opus-4", report[0].Result.GroupKey, "largest cost first")
+	assert.InDelta(t, 5, report[0].Result.CostUSD, 0.001)
+	assert.Equal(t, 1, report[0].Result.Count)
+	assert.Equal(t, "claude-sonnet-4", report[1].Result.GroupKey)
+	assert.Equal(t, 1500, report[1].Result.TotalTokens)
+
+	report, err = PaletteView{Range: "all", GroupBy: "day"}.Report(results, now, time.UTC)
+	require.NoError(t, err)
+	require.Len(t, report, 3)
+	assert.Equal(t, "2025-05-31", report[0].Result.GroupKey, "days stay chronological")
+	assert.Equal(t, "2025-06-10", report[2].Result.GroupKey)
+}
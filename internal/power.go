@@ -0,0 +1,119 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// PowerSource is what the machine is currently running on
+type PowerSource int
+
+const (
+	PowerUnknown PowerSource = iota // Not detectable on this platform or machine
+	PowerAC
+	PowerBattery
+)
+
+// Low-power modes
+const (
+	LowPowerAuto = "auto" // Low power while running on battery
+	LowPowerOn   = "on"
+	LowPowerOff  = "off"
+)
+
+// powerCheckInterval is how often the power source is checked in auto mode
+const powerCheckInterval = 30 * time.Second
+
+// defaultLowPowerRefreshRate is the low-power refresh interval when none is configured
+const defaultLowPowerRefreshRate = 30 * time.Second
+
+// PowerWatcher decides whether low-power mode applies from the configured mode and the power source
+type PowerWatcher struct {
+	mode   string
+	detect func() PowerSource
+	active bool
+}
+
+// NewPowerWatcher creates a watcher for a low-power mode; an empty mode means auto
+func NewPowerWatcher(mode string) *PowerWatcher {
+	if mode == "" {
+		mode = LowPowerAuto
+	}
+	return &PowerWatcher{mode: mode, detect: detectPowerSource}
+}
+
+// Polls reports whether the power source has to be checked periodically
+func (w *PowerWatcher) Polls() bool {
+	return w.mode == LowPowerAuto
+}
+
+// Check reports whether low-power mode applies and whether that changed since the last check
+func (w *PowerWatcher) Check() (active, changed bool) {
+	switch w.mode {
+	case LowPowerOn:
+		active = true
+	case LowPowerAuto:
+		active = w.detect() == PowerBattery
+	}
+	changed = active != w.active
+	w.active = active
+	return active, changed
+}
+
+// lowPowerInterval returns the refresh interval in low-power mode, never faster than normal
+func lowPowerInterval(normal, configured time.Duration) time.Duration {
+	if configured <= 0 {
+		configured = defaultLowPowerRefreshRate
+	}
+	if normal > configured {
+		return normal
+	}
+	return configured
+}
+
+// powerSupplySource reads the power source from a Linux power_supply class directory: AC when a
+// mains or USB supply is online, battery when only batteries are present
+func powerSupplySource(dir string) PowerSource {
+	supplies, err := os.ReadDir(dir)
+	if err != nil {
+		return PowerUnknown
+	}
+	read := func(supply, name string) string {
+		data, err := os.ReadFile(filepath.Join(dir, supply, name))
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(data))
+	}
+
+	source := PowerUnknown
+	for _, supply := range supplies {
+		name := supply.Name()
+		switch read(name, "type") {
+		case "Mains", "USB":
+			if read(name, "online") == "1" {
+				return PowerAC
+			}
+		case "Battery":
+			// Batteries of peripherals such as mice are not the machine's power source
+			if read(name, "scope") != "Device" {
+				source = PowerBattery
+			}
+		}
+	}
+	return source
+}
+
+// pmsetSource parses the output of `pmset -g batt` on macOS
+func pmsetSource(output string) PowerSource {
+	switch {
+	case strings.Contains(output, "'Battery Power'"):
+		return PowerBattery
+	case strings.Contains(output, "'AC Power'"):
+		return PowerAC
+	default:
+		return PowerUnknown
+	}
+}
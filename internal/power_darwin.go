@@ -0,0 +1,14 @@
+//go:build darwin
+
+package internal
+
+import "os/exec"
+
+// detectPowerSource asks pmset which power source is drawn from
+func detectPowerSource() PowerSource {
+	output, err := exec.Command("pmset", "-g", "batt").Output()
+	if err != nil {
+		return PowerUnknown
+	}
+	return pmsetSource(string(output))
+}
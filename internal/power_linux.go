@@ -0,0 +1,8 @@
+//go:build linux
+
+package internal
+
+// detectPowerSource reads the power source from sysfs
+func detectPowerSource() PowerSource {
+	return powerSupplySource("/sys/class/power_supply")
+}
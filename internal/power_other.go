@@ -0,0 +1,8 @@
+//go:build !linux && !darwin
+
+package internal
+
+// detectPowerSource is not supported on this platform, so auto low-power mode never applies
+func detectPowerSource() PowerSource {
+	return PowerUnknown
+}
@@ -0,0 +1,85 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writePowerSupply writes the sysfs attributes of one power supply
+func writePowerSupply(t *testing.T, dir, name string, attrs map[string]string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, name), 0755))
+	for attr, value := range attrs {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name, attr), []byte(value+"\n"), 0644))
+	}
+}
+
+func TestPowerSupplySource(t *testing.T) {
+	dir := t.TempDir()
+	assert.Equal(t, PowerUnknown, powerSupplySource(filepath.Join(dir, "missing")))
+	assert.Equal(t, PowerUnknown, powerSupplySource(dir), "desktops have no power supplies listed")
+
+	writePowerSupply(t, dir, "hid-mouse-battery", map[string]string{"type": "Battery", "scope": "Device"})
+	assert.Equal(t, PowerUnknown, powerSupplySource(dir), "peripheral batteries are ignored")
+
+	writePowerSupply(t, dir, "BAT0", map[string]string{"type": "Battery", "status": "Discharging"})
+	writePowerSupply(t, dir, "AC", map[string]string{"type": "Mains", "online": "0"})
+	assert.Equal(t, PowerBattery, powerSupplySource(dir))
+
+	writePowerSupply(t, dir, "AC", map[string]string{"type": "Mains", "online": "1"})
+	assert.Equal(t, PowerAC, powerSupplySource(dir))
+}
+
+func TestPmsetSource(t *testing.T) {
+	battery := "Now drawing from 'Battery Power'\n -InternalBattery-0 (id=1234)\t84%; discharging; 5:12 remaining present: true\n"
+	ac := "Now drawing from 'AC Power'\n -InternalBattery-0 (id=1234)\t100%; charged; 0:00 remaining present: true\n"
+	assert.Equal(t, PowerBattery, pmsetSource(battery))
+	assert.Equal(t, PowerAC, pmsetSource(ac))
+	assert.Equal(t, PowerUnknown, pmsetSource(""))
+}
+
+func TestPowerWatcher_Check(t *testing.T) {
+	source := PowerAC
+	watcher := NewPowerWatcher("")
+	watcher.detect = func() PowerSource { return source }
+	assert.True(t, watcher.Polls())
+
+	active, changed := watcher.Check()
+	assert.False(t, active)
+	assert.False(t, changed)
+
+	source = PowerBattery
+	active, changed = watcher.Check()
+	assert.True(t, active)
+	assert.True(t, changed)
+	_, changed = watcher.Check()
+	assert.False(t, changed, "a change is reported once")
+
+	source = PowerUnknown
+	active, changed = watcher.Check()
+	assert.False(t, active)
+	assert.True(t, changed)
+
+	forced := NewPowerWatcher(LowPowerOn)
+	forced.detect = func() PowerSource { return PowerAC }
+	assert.False(t, forced.Polls())
+	active, changed = forced.Check()
+	assert.True(t, active)
+	assert.True(t, changed)
+
+	off := NewPowerWatcher(LowPowerOff)
+	off.detect = func() PowerSource { return PowerBattery }
+	active, _ = off.Check()
+	assert.False(t, active)
+}
+
+func TestLowPowerInterval(t *testing.T) {
+	assert.Equal(t, time.Minute, lowPowerInterval(time.Second, time.Minute))
+	assert.Equal(t, defaultLowPowerRefreshRate, lowPowerInterval(time.Second, 0))
+	assert.Equal(t, 2*time.Minute, lowPowerInterval(2*time.Minute, time.Minute), "never refreshes faster than normal")
+}
@@ -0,0 +1,68 @@
+package internal
+
+import (
+	"time"
+
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/logging"
+	"github.com/penwyp/claudecat/models"
+)
+
+// ApplyRetention redacts IDs older than the configured retention period from the stores kept under
+// cache.dir: the command history, alert log, imported usage, session tag notes and monitor snapshot.
+// The summary cache and its eviction ledger are redacted when the cache is opened.
+func ApplyRetention(cfg *config.Config) {
+	cutoff := models.RedactionCutoff(cfg.Retention.RedactIDsAfterDays, time.Now())
+	if cutoff.IsZero() {
+		return
+	}
+
+	stores := []struct {
+		name   string
+		redact func() (int, error)
+	}{
+		{"command history", func() (int, error) {
+			history, err := OpenHistoryLog(cfg)
+			if err != nil {
+				return 0, err
+			}
+			return history.RedactBefore(cutoff)
+		}},
+		{"alert log", func() (int, error) {
+			alerts, err := OpenAlertLog(cfg)
+			if err != nil {
+				return 0, err
+			}
+			return alerts.RedactBefore(cutoff)
+		}},
+		{"imported usage", func() (int, error) {
+			imports, err := OpenImportStore(cfg)
+			if err != nil {
+				return 0, err
+			}
+			return imports.RedactBefore(cutoff)
+		}},
+		{"session tags", func() (int, error) {
+			tags, err := OpenTagStore(cfg)
+			if err != nil {
+				return 0, err
+			}
+			return tags.RedactBefore(cutoff)
+		}},
+		{"monitor snapshot", func() (int, error) {
+			snapshots, err := OpenSnapshotStore(cfg)
+			if err != nil {
+				return 0, err
+			}
+			return snapshots.RedactBefore(cutoff)
+		}},
+	}
+	for _, store := range stores {
+		redacted, err := store.redact()
+		if err != nil {
+			logging.LogWarnf("Failed to redact %s: %v", store.name, err)
+		} else if redacted > 0 {
+			logging.LogInfof("Redacted IDs from %d records of the %s", redacted, store.name)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyRetention(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Cache.Dir = t.TempDir()
+	cfg.Retention.RedactIDsAfterDays = 30
+	now := time.Now().UTC().Truncate(time.Second)
+	old, recent := now.AddDate(0, 0, -60), now.AddDate(0, 0, -1)
+	oldID, recentID := "0b6c3c1e-9a43-4a8e-b5d2-6f1f0e2d3c4b", "7f1e2d3c-4b5a-4c6d-8e9f-0a1b2c3d4e5f"
+
+	require.NoError(t, NewHistoryLog(DefaultHistoryPath(cfg)).Append(HistoryRecord{Time: old, Args: []string{oldID}}))
+	require.NoError(t, NewHistoryLog(DefaultHistoryPath(cfg)).Append(HistoryRecord{Time: recent, Args: []string{recentID}}))
+	alerts := NewAlertLog(DefaultAlertLogPath(cfg))
+	require.NoError(t, alerts.Append(AlertRecord{Time: old, SessionID: oldID, Message: "Session " + oldID}))
+	require.NoError(t, alerts.Append(AlertRecord{Time: recent, SessionID: recentID}))
+	require.NoError(t, NewImportStore(DefaultImportDir(cfg)).Save(ImportBatch{Name: "openai", Entries: []models.UsageEntry{
+		{Timestamp: old, SessionID: oldID, MessageID: "msg_old", CostUSD: 2},
+		{Timestamp: recent, SessionID: recentID},
+	}}))
+	tags := make(SessionTagSet)
+	oldNote, recentNote := "see "+oldID, "see "+recentID
+	tags.Tag(old, []string{"spike"}, &oldNote)
+	tags.Tag(recent, nil, &recentNote)
+	require.NoError(t, (&TagStore{path: DefaultTagPath(cfg)}).Save(tags))
+	require.NoError(t, NewSnapshotStore(DefaultSnapshotPath(cfg)).Save(MonitorSnapshot{SavedAt: now, Blocks: []models.SessionBlock{
+		{EndTime: now, Entries: []models.UsageEntry{{Timestamp: old, SessionID: oldID}, {Timestamp: recent, SessionID: recentID}}},
+	}}))
+
+	ApplyRetention(cfg)
+
+	paths := []string{
+		DefaultHistoryPath(cfg),
+		DefaultAlertLogPath(cfg),
+		filepath.Join(DefaultImportDir(cfg), "openai.json"),
+		DefaultTagPath(cfg),
+		DefaultSnapshotPath(cfg),
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), oldID, filepath.Base(path))
+		assert.Contains(t, string(data), recentID, "%s keeps recent IDs", filepath.Base(path))
+	}
+
+	entries, err := NewImportStore(DefaultImportDir(cfg)).Entries()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, models.RedactIDs(oldID), entries[0].SessionID, "redacted IDs still group together")
+	assert.Equal(t, 2.0, entries[0].CostUSD)
+	stored, err := (&TagStore{path: DefaultTagPath(cfg)}).Load()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"spike"}, stored.Labels(old))
+}
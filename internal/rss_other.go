@@ -0,0 +1,8 @@
+//go:build !linux
+
+package internal
+
+// residentMemory is not supported on this platform, so the watchdog only checks the heap and goroutines
+func residentMemory() (uint64, bool) {
+	return 0, false
+}
@@ -0,0 +1,102 @@
+package internal
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/penwyp/claudecat/calculations"
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/events"
+	"github.com/penwyp/claudecat/humanize"
+	"github.com/penwyp/claudecat/logging"
+	"github.com/penwyp/claudecat/models"
+)
+
+// RuleWatcher raises a notice when an alert rule starts firing; a rule fires again only after its
+// condition stopped holding, e.g. on the next day or in the next session
+type RuleWatcher struct {
+	rules  []config.AlertRule
+	limits models.PlanLimits
+	loc    *time.Location
+	now    func() time.Time
+	firing map[int]bool // Indexes of the rules firing at the last evaluation
+}
+
+// NewRuleWatcher creates a watcher for the configured alert rules, evaluating days in loc and session
+// percentages of limits
+func NewRuleWatcher(cfg config.AlertsConfig, limits models.PlanLimits, loc *time.Location) *RuleWatcher {
+	rules, err := config.ParseAlertRules(cfg.Rules)
+	if err != nil {
+		logging.LogWarnf("Alert rules disabled: %v", err)
+		rules = nil
+	}
+	return &RuleWatcher{rules: rules, limits: limits, loc: loc, now: time.Now, firing: make(map[int]bool)}
+}
+
+// Enabled reports whether any rule is configured
+func (w *RuleWatcher) Enabled() bool {
+	return len(w.rules) > 0
+}
+
+// Observe returns the notices of the rules that started firing since the last call
+func (w *RuleWatcher) Observe(blocks []models.SessionBlock) []events.Notice {
+	var notices []events.Notice
+	for _, result := range w.Evaluate(blocks, w.now()) {
+		notices = append(notices, RuleNotice(result))
+	}
+	return notices
+}
+
+// Evaluate returns the results of the rules that started firing at now since the last call
+func (w *RuleWatcher) Evaluate(blocks []models.SessionBlock, now time.Time) []calculations.AlertRuleResult {
+	if !w.Enabled() {
+		return nil
+	}
+	var started []calculations.AlertRuleResult
+	for i, result := range calculations.EvaluateAlertRules(w.rules, blocks, w.limits, now, w.loc) {
+		if !result.Firing {
+			delete(w.firing, i)
+			continue
+		}
+		if !w.firing[i] {
+			w.firing[i] = true
+			started = append(started, result)
+		}
+	}
+	return started
+}
+
+// RuleNotice builds the notice of a firing alert rule
+func RuleNotice(result calculations.AlertRuleResult) events.Notice {
+	level := events.NoticeWarning
+	if result.Rule.Level == "error" {
+		level = events.NoticeError
+	}
+	return events.Notice{
+		Level: level,
+		Message: fmt.Sprintf("Alert %s: %s is %s (%s %s)", result.Rule.Name, result.Rule.Metric,
+			FormatRuleValue(result.Rule.Metric, result.Value), result.Rule.Op, FormatRuleValue(result.Rule.Metric, result.Threshold)),
+		Crossing: &events.Crossing{
+			Metric:    result.Rule.Metric,
+			Value:     result.Value,
+			Threshold: result.Threshold,
+			SessionID: result.SessionID,
+		},
+	}
+}
+
+// FormatRuleValue formats a value of an alert rule metric in its unit
+func FormatRuleValue(metric string, value float64) string {
+	switch metric {
+	case config.RuleDailyCost, config.RuleWeeklyCost, config.RuleMonthlyCost, config.RuleSessionCost:
+		return humanize.Cost(value)
+	case config.RuleSessionCostPct, config.RuleSessionTokensPct:
+		return fmt.Sprintf("%.0f%%", value)
+	case config.RuleCostRate:
+		return humanize.Cost(value) + "/h"
+	case config.RuleBurnRate:
+		return humanize.Count(int(value)) + " tok/min"
+	default:
+		return humanize.Count(int(value))
+	}
+}
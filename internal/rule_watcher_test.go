@@ -0,0 +1,41 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/events"
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleWatcher(t *testing.T) {
+	now := time.Date(2025, 6, 4, 15, 0, 0, 0, time.UTC)
+	watcher := NewRuleWatcher(config.AlertsConfig{Rules: []config.AlertRuleConfig{
+		{Name: "spend", When: "session_cost > 5", Level: "error"},
+	}}, models.PlanLimits{}, time.UTC)
+	watcher.now = func() time.Time { return now }
+	require.True(t, watcher.Enabled())
+
+	block := models.SessionBlock{ID: "s1", StartTime: now.Add(-time.Hour), IsActive: true, CostUSD: 6.5}
+	notices := watcher.Observe([]models.SessionBlock{block})
+	require.Len(t, notices, 1)
+	assert.Equal(t, events.NoticeError, notices[0].Level)
+	assert.Equal(t, "Alert spend: session_cost is $6.50 (> $5.00)", notices[0].Message)
+	assert.Equal(t, &events.Crossing{Metric: config.RuleSessionCost, Value: 6.5, Threshold: 5, SessionID: "s1"}, notices[0].Crossing)
+
+	assert.Empty(t, watcher.Observe([]models.SessionBlock{block}), "a firing rule is raised once")
+
+	block.CostUSD = 1
+	assert.Empty(t, watcher.Observe([]models.SessionBlock{block}))
+	block.CostUSD = 7
+	assert.Len(t, watcher.Observe([]models.SessionBlock{block}), 1, "the rule fires again after it stopped")
+}
+
+func TestRuleWatcher_InvalidRules(t *testing.T) {
+	watcher := NewRuleWatcher(config.AlertsConfig{Rules: []config.AlertRuleConfig{{When: "bogus"}}}, models.PlanLimits{}, time.UTC)
+	assert.False(t, watcher.Enabled())
+	assert.Empty(t, watcher.Observe(nil))
+}
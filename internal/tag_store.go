@@ -0,0 +1,232 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/penwyp/claudecat/cache"
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/models"
+	"github.com/penwyp/claudecat/sessions"
+)
+
+// SessionTags are the labels and note attached to a session
+type SessionTags struct {
+	Start  time.Time `json:"start"` // Session start, which identifies the session
+	Labels []string  `json:"labels"`
+	Note   string    `json:"note,omitempty"`
+}
+
+// ID returns the analyze session ID of the tagged session
+func (s SessionTags) ID() string {
+	return sessionIDFor(s.Start)
+}
+
+// SessionTagSet holds the tags of every tagged session, keyed by session start
+type SessionTagSet map[string]SessionTags
+
+// TagStore keeps session tags in a sidecar file next to the cache, encrypted like the cache when enabled
+type TagStore struct {
+	path string
+	enc  *cache.Encryptor
+}
+
+// NewTagStore creates a tag store backed by the file at path
+func NewTagStore(path string) *TagStore {
+	return &TagStore{path: path}
+}
+
+// OpenTagStore opens the default tag store, encrypted according to cache.encryption
+func OpenTagStore(cfg *config.Config) (*TagStore, error) {
+	enc, err := cacheEncryptor(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tag encryption key: %w", err)
+	}
+	return &TagStore{path: DefaultTagPath(cfg), enc: enc}, nil
+}
+
+// DefaultTagPath returns the default location of session tags, under cache.dir
+func DefaultTagPath(cfg *config.Config) string {
+	return filepath.Join(cacheDirPath(cfg), "session_tags.json")
+}
+
+// ValidateTagLabel checks that a label can be listed and grouped by, which rules out commas and spaces
+func ValidateTagLabel(label string) error {
+	if label == "" || strings.ContainsAny(label, ", \t\n") {
+		return fmt.Errorf("invalid tag: %q (tags cannot be empty or contain commas or spaces)", label)
+	}
+	return nil
+}
+
+// Load returns the stored tags; a missing file holds none
+func (s *TagStore) Load() (SessionTagSet, error) {
+	tags := make(SessionTagSet)
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return tags, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session tags: %w", err)
+	}
+	if data, err = s.enc.OpenFile(data); err != nil {
+		return nil, fmt.Errorf("failed to decrypt session tags: %w", err)
+	}
+	var list []SessionTags
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse session tags: %w", err)
+	}
+	for _, session := range list {
+		tags[tagKey(session.Start)] = session
+	}
+	return tags, nil
+}
+
+// Save replaces the stored tags
+func (s *TagStore) Save(tags SessionTagSet) error {
+	data, err := json.Marshal(tags.List())
+	if err != nil {
+		return fmt.Errorf("failed to encode session tags: %w", err)
+	}
+	if data, err = s.enc.SealFile(data); err != nil {
+		return fmt.Errorf("failed to encrypt session tags: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create tag directory: %w", err)
+	}
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write session tags: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace session tags: %w", err)
+	}
+	return nil
+}
+
+// RedactBefore removes IDs from the notes of sessions that started before cutoff and returns how
+// many notes it redacted. Labels are kept, as they are names chosen for grouping.
+func (s *TagStore) RedactBefore(cutoff time.Time) (int, error) {
+	if cutoff.IsZero() {
+		return 0, nil
+	}
+
+	tags, err := s.Load()
+	if err != nil {
+		return 0, err
+	}
+	redacted := 0
+	for key, session := range tags {
+		if !session.Start.Before(cutoff) {
+			continue
+		}
+		if note := models.RedactIDs(session.Note); note != session.Note {
+			session.Note = note
+			tags[key] = session
+			redacted++
+		}
+	}
+	if redacted == 0 {
+		return 0, nil
+	}
+	return redacted, s.Save(tags)
+}
+
+// Get returns the tags of the session starting at start
+func (t SessionTagSet) Get(start time.Time) (SessionTags, bool) {
+	session, ok := t[tagKey(start)]
+	return session, ok
+}
+
+// Labels returns the labels of the session starting at start, or nil
+func (t SessionTagSet) Labels(start time.Time) []string {
+	return t[tagKey(start)].Labels
+}
+
+// Tag adds labels to the session starting at start and replaces its note when note is non-nil
+func (t SessionTagSet) Tag(start time.Time, labels []string, note *string) {
+	key := tagKey(start)
+	session, ok := t[key]
+	if !ok {
+		session = SessionTags{Start: start.UTC()}
+	}
+	for _, label := range labels {
+		if !containsLabel(session.Labels, label) {
+			session.Labels = append(session.Labels, label)
+		}
+	}
+	sort.Strings(session.Labels)
+	if note != nil {
+		session.Note = *note
+	}
+	t[key] = session
+}
+
+// Untag removes labels from the session starting at start, or all of its tags and note when none
+// are given, and reports whether anything was removed
+func (t SessionTagSet) Untag(start time.Time, labels []string) bool {
+	key := tagKey(start)
+	session, ok := t[key]
+	if !ok {
+		return false
+	}
+	if len(labels) == 0 {
+		delete(t, key)
+		return true
+	}
+
+	kept := session.Labels[:0]
+	for _, label := range session.Labels {
+		if !containsLabel(labels, label) {
+			kept = append(kept, label)
+		}
+	}
+	removed := len(kept) < len(session.Labels)
+	session.Labels = kept
+	if len(session.Labels) == 0 && session.Note == "" {
+		delete(t, key)
+	} else {
+		t[key] = session
+	}
+	return removed
+}
+
+// List returns the tagged sessions by start
+func (t SessionTagSet) List() []SessionTags {
+	list := make([]SessionTags, 0, len(t))
+	for _, session := range t {
+		list = append(list, session)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Start.Before(list[j].Start)
+	})
+	return list
+}
+
+// apply copies the tags of the summarized session into the summary; gaps are never tagged
+func (t SessionTagSet) apply(summary *sessions.SessionSummary) {
+	if session, ok := t.Get(summary.Start); ok && !summary.Gap {
+		summary.Tags, summary.Note = session.Labels, session.Note
+	}
+}
+
+// tagKey identifies a session by its start
+func tagKey(start time.Time) string {
+	return start.UTC().Format(time.RFC3339)
+}
+
+// containsLabel reports whether labels contains label
+func containsLabel(labels []string, label string) bool {
+	for _, l := range labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
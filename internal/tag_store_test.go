@@ -0,0 +1,125 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/cache"
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/sessions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTagStore(t *testing.T) {
+	store := NewTagStore(filepath.Join(t.TempDir(), "session_tags.json"))
+	tags, err := store.Load()
+	require.NoError(t, err)
+	assert.Empty(t, tags)
+
+	start := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	note := "Login page"
+	tags.Tag(start, []string{"feature-x", "bug-12"}, &note)
+	tags.Tag(start.In(time.FixedZone("CEST", 2*3600)), []string{"feature-x"}, nil)
+	require.NoError(t, store.Save(tags))
+
+	loaded, err := store.Load()
+	require.NoError(t, err)
+	session, ok := loaded.Get(start)
+	require.True(t, ok)
+	assert.Equal(t, []string{"bug-12", "feature-x"}, session.Labels)
+	assert.Equal(t, "Login page", session.Note)
+	assert.Equal(t, "session_2025-06-01_10", session.ID())
+
+	assert.True(t, loaded.Untag(start, []string{"bug-12"}))
+	assert.False(t, loaded.Untag(start, []string{"bug-12"}))
+	assert.Equal(t, []string{"feature-x"}, loaded.Labels(start))
+	assert.True(t, loaded.Untag(start, nil))
+	assert.Empty(t, loaded.List())
+
+	assert.NoError(t, ValidateTagLabel("feature-x"))
+	assert.Error(t, ValidateTagLabel("feature x"))
+	assert.Error(t, ValidateTagLabel("a,b"))
+}
+
+func TestTagStore_Encrypted(t *testing.T) {
+	dir := t.TempDir()
+	enc, err := cache.NewEncryptor(make([]byte, 32))
+	require.NoError(t, err)
+	path := filepath.Join(dir, "session_tags.json")
+	store := &TagStore{path: path, enc: enc}
+
+	tags := make(SessionTagSet)
+	tags.Tag(time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC), []string{"feature-x"}, nil)
+	require.NoError(t, store.Save(tags))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, cache.IsSealed(data))
+
+	loaded, err := store.Load()
+	require.NoError(t, err)
+	assert.Len(t, loaded, 1)
+	_, err = NewTagStore(path).Load()
+	assert.Error(t, err)
+}
+
+func TestParseSessionID(t *testing.T) {
+	start, ok := parseSessionID("session_2025-06-01_10")
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC), start)
+	start, ok = parseSessionID(sessionIDFor(time.Date(2025, 6, 1, 10, 30, 0, 0, time.UTC)))
+	require.True(t, ok)
+	assert.Equal(t, 30, start.Minute())
+	_, ok = parseSessionID("5166fc87c482")
+	assert.False(t, ok)
+}
+
+func TestAnalyzer_SessionTags(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	day := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -2)
+	data := t.TempDir()
+	var lines string
+	for i, offset := range []time.Duration{10 * time.Hour, 30 * time.Hour} {
+		lines += fmt.Sprintf(`{"type":"assistant","timestamp":%q,"sessionId":"s1","requestId":"req-%d","message":{"id":"msg-%d","model":"claude-3-5-sonnet-20241022","role":"assistant","usage":{"input_tokens":1000,"output_tokens":500}}}`+"\n",
+			day.Add(offset).Format(time.RFC3339), i, i)
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(data, "project"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(data, "project", "session.jsonl"), []byte(lines), 0o644))
+
+	cfg := config.DefaultConfig()
+	cfg.Cache.Dir = t.TempDir()
+	cfg.Data.PricingSource = "default"
+	cfg.Data.PricingOfflineMode = false
+	analyzer, err := NewAnalyzer(cfg)
+	require.NoError(t, err)
+	analyzer.SetIncludeImports(false)
+	store := NewTagStore(filepath.Join(t.TempDir(), "session_tags.json"))
+	analyzer.SetTagStore(store)
+
+	summaries, err := analyzer.Sessions([]string{data}, sessions.ListFilter{HideGaps: true}, time.Now())
+	require.NoError(t, err)
+	require.Len(t, summaries, 2)
+	start, err := analyzer.ResolveSessionStart([]string{data}, summaries[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, day.Add(10*time.Hour), start)
+	_, err = analyzer.ResolveSessionStart([]string{data}, "unknown")
+	assert.Error(t, err)
+
+	tags := make(SessionTagSet)
+	tags.Tag(start, []string{"feature-x"}, nil)
+	require.NoError(t, store.Save(tags))
+
+	results, err := analyzer.Analyze([]string{data})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, []string{"feature-x"}, results[0].Tags)
+	assert.Empty(t, results[1].Tags)
+
+	summaries, err = analyzer.Sessions([]string{data}, sessions.ListFilter{HideGaps: true}, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"feature-x"}, summaries[0].Tags)
+	assert.Empty(t, summaries[1].Tags)
+}
@@ -0,0 +1,191 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/penwyp/claudecat/calculations"
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/events"
+	"github.com/penwyp/claudecat/humanize"
+	"github.com/penwyp/claudecat/logging"
+	"github.com/penwyp/claudecat/models"
+	"github.com/penwyp/claudecat/orchestrator"
+)
+
+// TopStatus is the summary of the active session printed by `claudecat top`
+type TopStatus struct {
+	Active          bool
+	Tokens          int
+	CostUSD         float64
+	CostLimit       float64 // Session cost limit of the plan, 0 if unknown
+	TokensPerMinute float64
+	CostPerHour     float64
+	ResetsAt        time.Time
+	TimeToReset     time.Duration
+	Models          []string
+	TodayTokens     int
+	TodayCost       float64
+	UpdatedAt       time.Time
+}
+
+// NewTopStatus summarizes the active session of blocks and the usage of the local day containing now
+func NewTopStatus(blocks []models.SessionBlock, costLimit float64, now time.Time, loc *time.Location) TopStatus {
+	status := TopStatus{CostLimit: costLimit, UpdatedAt: now.In(loc)}
+	calculator := calculations.NewBurnRateCalculator()
+	for _, block := range blocks {
+		if !block.IsActive || block.IsGap {
+			continue
+		}
+		status.Active = true
+		status.Tokens = block.TokenCounts.TotalTokens()
+		status.CostUSD = block.CostUSD
+		status.ResetsAt = block.EndTime.In(loc)
+		status.TimeToReset = max(block.EndTime.Sub(now), 0)
+		status.Models = block.Models
+		if rate := calculator.CalculateBurnRate(block); rate != nil {
+			status.TokensPerMinute = rate.TokensPerMinute
+			status.CostPerHour = rate.CostPerHour
+		}
+		break
+	}
+
+	local := now.In(loc)
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	for _, block := range blocks {
+		if block.IsGap || block.EndTime.Before(today) {
+			continue
+		}
+		for _, entry := range block.Entries {
+			if !entry.Timestamp.Before(today) {
+				status.TodayTokens += entry.TotalTokens
+				status.TodayCost += entry.CostUSD
+			}
+		}
+	}
+	return status
+}
+
+// Lines renders the status as two lines, or as a single line for status bars
+func (s TopStatus) Lines(oneLine bool) []string {
+	var session string
+	if s.Active {
+		cost := humanize.Cost(s.CostUSD)
+		if s.CostLimit > 0 {
+			cost += fmt.Sprintf(" (%.0f%%)", s.CostUSD/s.CostLimit*100)
+		}
+		session = fmt.Sprintf("%s tok  %s  %s tok/min  %s/h  reset %s (%s)",
+			humanize.Compact(s.Tokens), cost, humanize.Compact(int(s.TokensPerMinute)), humanize.Cost(s.CostPerHour),
+			humanize.Duration(s.TimeToReset.Truncate(time.Minute)), s.ResetsAt.Format("15:04"))
+	} else {
+		session = "no active session"
+	}
+	today := fmt.Sprintf("today %s tok  %s", humanize.Compact(s.TodayTokens), humanize.Cost(s.TodayCost))
+	if oneLine {
+		return []string{session + "  |  " + today}
+	}
+
+	details := today
+	if len(s.Models) > 0 {
+		details += "  " + strings.Join(s.Models, ", ")
+	}
+	details += "  updated " + s.UpdatedAt.Format("15:04:05")
+	return []string{session, details}
+}
+
+// TopOptions configures the top ticker
+type TopOptions struct {
+	Interval time.Duration // Time between data refreshes
+	OneLine  bool          // Print a single line instead of two
+	Once     bool          // Print the status once the data is loaded and return
+	Rewrite  bool          // Redraw the status in place; otherwise each refresh is appended
+}
+
+// topPrinter writes status lines, redrawing the previously written lines in place when rewrite is set
+type topPrinter struct {
+	w       io.Writer
+	rewrite bool
+	drawn   int
+}
+
+// print writes lines, replacing the lines printed by the previous call
+func (p *topPrinter) print(lines []string) {
+	var b strings.Builder
+	if p.rewrite && p.drawn > 0 {
+		// Back to the start of the first line written last time
+		b.WriteString("\r")
+		if p.drawn > 1 {
+			fmt.Fprintf(&b, "\033[%dA", p.drawn-1)
+		}
+	}
+	for i, line := range lines {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		if p.rewrite {
+			b.WriteString("\033[K")
+		}
+		b.WriteString(line)
+	}
+	if !p.rewrite {
+		b.WriteString("\n")
+	}
+	io.WriteString(p.w, b.String())
+	p.drawn = len(lines)
+}
+
+// finish ends the redrawn lines so that the shell prompt starts on a new line
+func (p *topPrinter) finish() {
+	if p.rewrite && p.drawn > 0 {
+		io.WriteString(p.w, "\n")
+	}
+}
+
+// RunTop monitors usage with the orchestrator and prints the status to w after every data refresh
+// until ctx is done
+func RunTop(ctx context.Context, cfg *config.Config, opts TopOptions, w io.Writer) error {
+	loc, err := time.LoadLocation(cfg.App.Timezone)
+	if err != nil {
+		loc = time.Local
+	}
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	costLimit := models.GetPlanLimits(cfg.Subscription.Plan).CostLimit
+
+	monitor := orchestrator.NewMonitoringOrchestrator(interval, resolveDataPath(cfg, logging.NewLogger(cfg.App.LogLevel, cfg.App.LogFile)), cfg)
+	updates := make(chan orchestrator.MonitoringData, 1)
+	unsubscribe := events.Subscribe(monitor.Bus(), func(data orchestrator.MonitoringData) {
+		// Keep only the latest update if the printer falls behind
+		select {
+		case <-updates:
+		default:
+		}
+		updates <- data
+	})
+	defer unsubscribe()
+
+	if err := monitor.Start(); err != nil {
+		return fmt.Errorf("failed to start monitoring: %w", err)
+	}
+	defer monitor.Stop()
+
+	printer := &topPrinter{w: w, rewrite: opts.Rewrite && !opts.Once}
+	defer printer.finish()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case data := <-updates:
+			status := NewTopStatus(data.Data.Blocks, costLimit, time.Now(), loc)
+			printer.print(status.Lines(opts.OneLine))
+			if opts.Once {
+				return nil
+			}
+		}
+	}
+}
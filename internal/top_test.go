@@ -0,0 +1,74 @@
+package internal
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTopStatus(t *testing.T) {
+	now := time.Date(2025, 6, 10, 14, 0, 0, 0, time.UTC)
+	entry := func(at time.Time, tokens int, cost float64) models.UsageEntry {
+		return models.UsageEntry{Timestamp: at, TotalTokens: tokens, CostUSD: cost}
+	}
+	blocks := []models.SessionBlock{
+		{
+			StartTime: time.Date(2025, 6, 9, 22, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2025, 6, 10, 3, 0, 0, 0, time.UTC),
+			Entries: []models.UsageEntry{
+				entry(time.Date(2025, 6, 9, 23, 0, 0, 0, time.UTC), 500, 5),
+				entry(time.Date(2025, 6, 10, 1, 0, 0, 0, time.UTC), 200, 2),
+			},
+		},
+		{IsGap: true, StartTime: time.Date(2025, 6, 10, 3, 0, 0, 0, time.UTC), EndTime: time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)},
+		{
+			StartTime:     time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC),
+			EndTime:       time.Date(2025, 6, 10, 17, 0, 0, 0, time.UTC),
+			IsActive:      true,
+			ActualEndTime: &now,
+			TokenCounts:   models.TokenCounts{InputTokens: 100_000, OutputTokens: 20_000},
+			CostUSD:       3,
+			Models:        []string{"claude-sonnet-4"},
+			Entries:       []models.UsageEntry{entry(time.Date(2025, 6, 10, 13, 0, 0, 0, time.UTC), 120_000, 3)},
+		},
+	}
+
+	status := NewTopStatus(blocks, 10, now, time.UTC)
+	require.True(t, status.Active)
+	assert.Equal(t, 120_000, status.Tokens)
+	assert.Equal(t, 3*time.Hour, status.TimeToReset)
+	assert.InDelta(t, 1000, status.TokensPerMinute, 0.001, "120k tokens over 2 hours")
+	assert.InDelta(t, 1.5, status.CostPerHour, 0.001)
+	assert.Equal(t, 120_200, status.TodayTokens, "entries before local midnight are left out")
+	assert.InDelta(t, 5, status.TodayCost, 0.001)
+
+	lines := status.Lines(false)
+	require.Len(t, lines, 2)
+	assert.Equal(t, "120K tok  $3.00 (30%)  1K tok/min  $1.50/h  reset 3h 0m (17:00)", lines[0])
+	assert.Equal(t, "today 120K tok  $5.00  claude-sonnet-4  updated 14:00:00", lines[1])
+	assert.Equal(t, []string{lines[0] + "  |  today 120K tok  $5.00"}, status.Lines(true))
+
+	idle := NewTopStatus(blocks[:2], 0, now, time.UTC)
+	assert.False(t, idle.Active)
+	assert.Equal(t, []string{"no active session  |  today 200 tok  $2.00"}, idle.Lines(true))
+}
+
+func TestTopPrinter(t *testing.T) {
+	var out bytes.Buffer
+	printer := &topPrinter{w: &out}
+	printer.print([]string{"a", "b"})
+	printer.print([]string{"c", "d"})
+	printer.finish()
+	assert.Equal(t, "a\nb\nc\nd\n", out.String(), "appends lines when not redrawing")
+
+	out.Reset()
+	printer = &topPrinter{w: &out, rewrite: true}
+	printer.print([]string{"a", "b"})
+	printer.print([]string{"c", "d"})
+	printer.finish()
+	assert.Equal(t, "\033[Ka\n\033[Kb\r\033[1A\033[Kc\n\033[Kd\n", out.String())
+}
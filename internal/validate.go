@@ -0,0 +1,220 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/penwyp/claudecat/cache"
+	"github.com/penwyp/claudecat/calculations"
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/fileio"
+	"github.com/penwyp/claudecat/humanize"
+	"github.com/penwyp/claudecat/logging"
+	"github.com/penwyp/claudecat/models"
+	"github.com/penwyp/claudecat/models/pricing"
+	"github.com/penwyp/claudecat/sessions"
+)
+
+// Consistency checks of the validator
+const (
+	ValidateCache    = "cache"    // Cached file summaries agree with a fresh parse of the usage files
+	ValidateSessions = "sessions" // Session blocks add up to the usage entries
+	ValidateDaily    = "daily"    // The daily grouping adds up to the usage entries
+)
+
+// ValidateChecks lists the consistency checks in the order they run
+var ValidateChecks = []string{ValidateCache, ValidateSessions, ValidateDaily}
+
+// Validator checks that the ways claudecat computes the same usage agree with each other
+type Validator struct {
+	cfg      *config.Config
+	cacheDir string
+	pricing  models.PricingProvider
+}
+
+// NewValidator creates a validator for cfg, whose data paths must already be resolved
+func NewValidator(cfg *config.Config) *Validator {
+	cacheDir := config.ExpandHome(cfg.Cache.Dir)
+	provider, err := pricing.CreatePricingProvider(&cfg.Data, cacheDir)
+	if err != nil {
+		logging.LogErrorf("Failed to create pricing provider: %v", err)
+		provider = pricing.NewDefaultProvider()
+	}
+	return &Validator{cfg: cfg, cacheDir: cacheDir, pricing: provider}
+}
+
+// Run performs the named checks, all of them when names is empty, in the order of ValidateChecks
+func (v *Validator) Run(names []string) ([]DoctorCheck, error) {
+	for _, name := range names {
+		if !slices.Contains(ValidateChecks, name) {
+			return nil, fmt.Errorf("unknown check: %s (valid: %s)", name, strings.Join(ValidateChecks, ", "))
+		}
+	}
+	if len(v.cfg.Data.Paths) == 0 {
+		return nil, fmt.Errorf("no data paths found - please specify paths as arguments")
+	}
+
+	// Every check compares against the entries of a fresh parse
+	entries, err := v.load(nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no usage entries found in %s", strings.Join(v.cfg.Data.Paths, ", "))
+	}
+	var checks []DoctorCheck
+	for _, name := range ValidateChecks {
+		if len(names) > 0 && !slices.Contains(names, name) {
+			continue
+		}
+		switch name {
+		case ValidateCache:
+			checks = append(checks, v.checkCache(entries))
+		case ValidateSessions:
+			checks = append(checks, v.checkSessions(entries))
+		case ValidateDaily:
+			checks = append(checks, v.checkDaily(entries))
+		}
+	}
+	return checks, nil
+}
+
+// load reads the usage entries of every data path, through store if it is not nil
+func (v *Validator) load(store fileio.CacheStore) ([]models.UsageEntry, error) {
+	var entries []models.UsageEntry
+	seenFiles := make(map[string]bool)
+	_, skipFiles := scanDuplicates(v.cfg, v.cfg.Data.Paths)
+	for _, path := range v.cfg.Data.Paths {
+		result, err := fileio.LoadUsageEntries(fileio.LoadUsageEntriesOptions{
+			DataPath:            path,
+			Mode:                models.CostModeCalculated,
+			CacheStore:          store,
+			EnableDeduplication: v.cfg.Data.Deduplication,
+			PricingProvider:     v.pricing,
+			MaxLineSize:         v.cfg.Data.MaxLineSize,
+			SeenFiles:           seenFiles,
+			Exclude:             v.cfg.Data.Exclude,
+			SkipFiles:           skipFiles,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to load usage entries from %s: %w", path, err)
+		}
+		entries = append(entries, result.Entries...)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+	return entries, nil
+}
+
+// consistencyTotals sums usage computed one way, for comparison with another
+type consistencyTotals struct {
+	Entries int
+	Tokens  int
+	Cost    float64
+}
+
+// entryTotals sums entries
+func entryTotals(entries []models.UsageEntry) consistencyTotals {
+	var totals consistencyTotals
+	for _, entry := range entries {
+		totals.Entries++
+		totals.Tokens += entry.TotalTokens
+		totals.Cost += entry.CostUSD
+	}
+	return totals
+}
+
+// matches reports whether t and other agree; costs may differ by the rounding of summing in
+// another order
+func (t consistencyTotals) matches(other consistencyTotals) bool {
+	return t.Entries == other.Entries && t.Tokens == other.Tokens &&
+		math.Abs(t.Cost-other.Cost) <= 1e-6*math.Max(1, math.Abs(t.Cost))
+}
+
+func (t consistencyTotals) String() string {
+	return fmt.Sprintf("%s entries, %s tokens, %s", humanize.Count(t.Entries), humanize.Count(t.Tokens), humanize.CostDecimals(t.Cost, 4))
+}
+
+// compareTotals builds the outcome of a check comparing got, computed as what, with the fresh parse
+func compareTotals(name, what string, got, fresh consistencyTotals, hint string) DoctorCheck {
+	if got.matches(fresh) {
+		return DoctorCheck{Name: name, Status: DoctorPass, Detail: fmt.Sprintf("%s agree with a fresh parse: %s", what, fresh)}
+	}
+	return DoctorCheck{
+		Name:   name,
+		Status: DoctorFail,
+		Detail: fmt.Sprintf("%s: %s; fresh parse: %s", what, got, fresh),
+		Hint:   hint,
+	}
+}
+
+// checkCache compares the entries read through the summary cache with a fresh parse
+func (v *Validator) checkCache(fresh []models.UsageEntry) DoctorCheck {
+	store, err := cache.OpenFileBasedSummaryCache(v.cacheDir, v.cfg.Cache.Encryption)
+	if err != nil {
+		return DoctorCheck{
+			Name:   ValidateCache,
+			Status: DoctorSkip,
+			Detail: fmt.Sprintf("cache unavailable: %v", err),
+			Hint:   "run `claudecat doctor` to diagnose the cache",
+		}
+	}
+	cached, err := v.load(store)
+	if err != nil {
+		return DoctorCheck{Name: ValidateCache, Status: DoctorFail, Detail: err.Error()}
+	}
+	return compareTotals(ValidateCache, "cached summaries", entryTotals(cached), entryTotals(fresh),
+		"clear the stale summaries with `claudecat cache clear`; they are rebuilt on the next run")
+}
+
+// checkSessions compares the totals of the session blocks with the entries they were built from
+func (v *Validator) checkSessions(fresh []models.UsageEntry) DoctorCheck {
+	blocks := sessions.NewSessionAnalyzer(int(models.SessionDuration / time.Hour)).TransformToBlocks(fresh)
+	var totals consistencyTotals
+	count := 0
+	for _, block := range blocks {
+		if block.IsGap {
+			continue
+		}
+		count++
+		totals.Entries += len(block.Entries)
+		totals.Tokens += block.TokenCounts.TotalTokens()
+		totals.Cost += block.CostUSD
+	}
+	return compareTotals(ValidateSessions, fmt.Sprintf("%d session blocks", count), totals, entryTotals(fresh),
+		"session blocks lost or double-counted entries; please report it with the output of `claudecat validate -o json`")
+}
+
+// checkDaily compares the daily grouping of analyze, in the configured timezone, with the entries
+func (v *Validator) checkDaily(fresh []models.UsageEntry) DoctorCheck {
+	loc, err := time.LoadLocation(v.cfg.App.Timezone)
+	if err != nil {
+		loc = time.Local
+	}
+	groups := make(map[string][]models.AnalysisResult)
+	for _, entry := range fresh {
+		result := models.AnalysisResult{
+			Timestamp:   entry.Timestamp.In(loc),
+			Model:       entry.Model,
+			TotalTokens: entry.TotalTokens,
+			CostUSD:     entry.CostUSD,
+		}
+		key := calculations.GroupKey(result, "day")
+		groups[key] = append(groups[key], result)
+	}
+
+	var totals consistencyTotals
+	days := calculations.NewGroupAggregator(0).Aggregate(groups)
+	for _, day := range days {
+		totals.Entries += day.Result.Count
+		totals.Tokens += day.Result.TotalTokens
+		totals.Cost += day.Result.CostUSD
+	}
+	return compareTotals(ValidateDaily, fmt.Sprintf("%d days", len(days)), totals, entryTotals(fresh),
+		"daily totals lost or double-counted entries; please report it with the output of `claudecat validate -o json`")
+}
@@ -0,0 +1,51 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidator_Run(t *testing.T) {
+	day := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -2)
+	data := t.TempDir()
+	var lines string
+	for i, offset := range []time.Duration{10 * time.Hour, 11 * time.Hour, 30 * time.Hour} {
+		lines += fmt.Sprintf(`{"type":"assistant","timestamp":%q,"sessionId":"s1","requestId":"req-%d","message":{"id":"msg-%d","model":"claude-3-5-sonnet-20241022","role":"assistant","usage":{"input_tokens":1000,"output_tokens":500}}}`+"\n",
+			day.Add(offset).Format(time.RFC3339), i, i)
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(data, "project"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(data, "project", "session.jsonl"), []byte(lines), 0o644))
+
+	cfg := config.DefaultConfig()
+	cfg.Data.Paths = []string{data}
+	cfg.Cache.Dir = t.TempDir()
+	cfg.Data.PricingSource = "default"
+	validator := NewValidator(cfg)
+
+	// The second run reads the summaries the first one cached
+	for range 2 {
+		checks, err := validator.Run(nil)
+		require.NoError(t, err)
+		require.Len(t, checks, len(ValidateChecks))
+		for _, check := range checks {
+			assert.Equal(t, DoctorPass, check.Status, check.Name+": "+check.Detail)
+		}
+		assert.Contains(t, checks[0].Detail, "3 entries, 4,500 tokens")
+		assert.Contains(t, checks[1].Detail, "2 session blocks")
+	}
+
+	checks, err := validator.Run([]string{ValidateDaily})
+	require.NoError(t, err)
+	require.Len(t, checks, 1)
+	assert.Equal(t, ValidateDaily, checks[0].Name)
+
+	_, err = validator.Run([]string{"pricing"})
+	assert.ErrorContains(t, err, "unknown check: pricing")
+}
@@ -0,0 +1,352 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/events"
+	"github.com/penwyp/claudecat/logging"
+	"github.com/penwyp/claudecat/models"
+	"github.com/penwyp/claudecat/orchestrator"
+)
+
+// Watch event types
+const (
+	WatchUpdate       = "update"        // Every data refresh
+	WatchLimit        = "limit"         // A session cost threshold was crossed or Claude logged a limit message
+	WatchSessionStart = "session_start" // A session became active, including the one active when watching starts
+	WatchSessionEnd   = "session_end"   // The active session ended
+	WatchAlert        = "alert"         // An alert rule of alerts.rules started firing
+)
+
+// WatchEventTypes lists every watch event type
+var WatchEventTypes = []string{WatchUpdate, WatchLimit, WatchSessionStart, WatchSessionEnd, WatchAlert}
+
+const (
+	// watchQueueSize is the number of events waiting for delivery before new ones are dropped
+	watchQueueSize = 64
+	// watchDeliveryTimeout bounds one webhook request or command run
+	watchDeliveryTimeout = 30 * time.Second
+)
+
+// WatchEvent is delivered as JSON for every monitoring event
+type WatchEvent struct {
+	Type      string        `json:"type"`
+	Time      time.Time     `json:"time"`
+	SessionID string        `json:"session_id,omitempty"`
+	Level     string        `json:"level,omitempty"`   // Limit and alert events: warning or error
+	Message   string        `json:"message,omitempty"` // Limit and alert events
+	Metric    string        `json:"metric,omitempty"`  // Limit and alert events: the alert metric crossed
+	Rule      string        `json:"rule,omitempty"`    // Alert events: the name of the rule
+	Value     float64       `json:"value,omitempty"`   // Alert events: the value of the metric
+	Threshold float64       `json:"threshold,omitempty"`
+	Session   *WatchSession `json:"session,omitempty"` // The session the event is about, or the active one
+}
+
+// WatchSession summarizes a session block in a watch event
+type WatchSession struct {
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Active    bool      `json:"active"`
+	Tokens    int       `json:"tokens"`
+	CostUSD   float64   `json:"cost_usd"`
+	CostLimit float64   `json:"cost_limit,omitempty"` // Session cost limit of the plan
+	Messages  int       `json:"messages"`
+	Models    []string  `json:"models"`
+}
+
+// WatchOptions configures headless watching
+type WatchOptions struct {
+	Interval time.Duration // Time between data refreshes
+	Exec     string        // Shell command run for every event with the event JSON on stdin
+	Webhook  string        // URL the event JSON is posted to
+	Events   []string      // Event types delivered; empty delivers all
+}
+
+// ValidateWatchEvents checks that every name is a watch event type
+func ValidateWatchEvents(names []string) error {
+	for _, name := range names {
+		valid := false
+		for _, eventType := range WatchEventTypes {
+			if name == eventType {
+				valid = true
+			}
+		}
+		if !valid {
+			return fmt.Errorf("invalid watch event: %s (valid: %s)", name, strings.Join(WatchEventTypes, ", "))
+		}
+	}
+	return nil
+}
+
+// watchDispatcher turns the orchestrator's events into watch events and delivers them
+type watchDispatcher struct {
+	opts      WatchOptions
+	types     map[string]bool
+	costLimit float64
+	limits    *LimitWatcher
+	rules     *RuleWatcher
+	client    *http.Client
+	w         io.Writer
+	now       func() time.Time
+
+	mu      sync.Mutex
+	pending []orchestrator.SessionChanged // Session changes of the refresh being published
+}
+
+// newWatchDispatcher creates a dispatcher writing every delivered event to w as a JSON line
+func newWatchDispatcher(cfg *config.Config, opts WatchOptions, w io.Writer) (*watchDispatcher, error) {
+	if err := ValidateWatchEvents(opts.Events); err != nil {
+		return nil, err
+	}
+	types := make(map[string]bool)
+	for _, eventType := range opts.Events {
+		types[eventType] = true
+	}
+	loc, err := time.LoadLocation(cfg.App.Timezone)
+	if err != nil {
+		loc = time.Local
+	}
+	return &watchDispatcher{
+		opts:      opts,
+		types:     types,
+		costLimit: models.GetPlanLimits(cfg.Subscription.Plan).CostLimit,
+		limits:    NewLimitWatcher(cfg.Subscription),
+		rules:     NewRuleWatcher(cfg.Alerts, models.GetPlanLimits(cfg.Subscription.Plan), loc),
+		client:    &http.Client{Timeout: watchDeliveryTimeout},
+		w:         w,
+		now:       time.Now,
+	}, nil
+}
+
+// wants reports whether events of eventType are delivered
+func (d *watchDispatcher) wants(eventType string) bool {
+	return len(d.types) == 0 || d.types[eventType]
+}
+
+// sessionChanged records a session change until the refresh it belongs to is published
+func (d *watchDispatcher) sessionChanged(change orchestrator.SessionChanged) {
+	if change.Type == orchestrator.SessionUpdate {
+		return
+	}
+	d.mu.Lock()
+	d.pending = append(d.pending, change)
+	d.mu.Unlock()
+}
+
+// updated returns the events of a refresh: session changes first, then limit crossings and alerts,
+// then the update
+func (d *watchDispatcher) updated(data orchestrator.MonitoringData) []WatchEvent {
+	d.mu.Lock()
+	changes := d.pending
+	d.pending = nil
+	d.mu.Unlock()
+
+	now := d.now()
+	blocks := data.Data.Blocks
+	var watchEvents []WatchEvent
+	for _, change := range changes {
+		eventType := WatchSessionStart
+		if change.Type == orchestrator.SessionEnd {
+			eventType = WatchSessionEnd
+		}
+		watchEvents = append(watchEvents, WatchEvent{
+			Type:      eventType,
+			Time:      now,
+			SessionID: change.SessionID,
+			Session:   d.session(blocks, func(block models.SessionBlock) bool { return block.ID == change.SessionID }),
+		})
+	}
+
+	active := d.session(blocks, func(block models.SessionBlock) bool { return block.IsActive && !block.IsGap })
+	for _, notice := range d.limits.Observe(blocks) {
+		event := WatchEvent{Type: WatchLimit, Time: now, Level: notice.Level.String(), Message: notice.Message, Session: active}
+		if notice.Crossing != nil {
+			event.SessionID = notice.Crossing.SessionID
+			event.Metric = notice.Crossing.Metric
+			event.Threshold = notice.Crossing.Threshold
+		}
+		watchEvents = append(watchEvents, event)
+	}
+	for _, result := range d.rules.Evaluate(blocks, now) {
+		notice := RuleNotice(result)
+		watchEvents = append(watchEvents, WatchEvent{
+			Type:      WatchAlert,
+			Time:      now,
+			SessionID: result.SessionID,
+			Level:     notice.Level.String(),
+			Message:   notice.Message,
+			Metric:    result.Rule.Metric,
+			Rule:      result.Rule.Name,
+			Value:     result.Value,
+			Threshold: result.Threshold,
+			Session:   active,
+		})
+	}
+
+	watchEvents = append(watchEvents, WatchEvent{Type: WatchUpdate, Time: now, SessionID: data.SessionID, Session: active})
+
+	wanted := watchEvents[:0]
+	for _, event := range watchEvents {
+		if d.wants(event.Type) {
+			wanted = append(wanted, event)
+		}
+	}
+	return wanted
+}
+
+// session summarizes the first block matching match, or returns nil
+func (d *watchDispatcher) session(blocks []models.SessionBlock, match func(models.SessionBlock) bool) *WatchSession {
+	for _, block := range blocks {
+		if !match(block) {
+			continue
+		}
+		return &WatchSession{
+			Start:     block.StartTime,
+			End:       block.EndTime,
+			Active:    block.IsActive,
+			Tokens:    block.TokenCounts.TotalTokens(),
+			CostUSD:   block.CostUSD,
+			CostLimit: d.costLimit,
+			Messages:  block.SentMessagesCount,
+			Models:    block.Models,
+		}
+	}
+	return nil
+}
+
+// deliver writes the event to the output, posts it to the webhook and runs the command, returning
+// the combined failures
+func (d *watchDispatcher) deliver(ctx context.Context, event WatchEvent) error {
+	payload, err := sonic.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode watch event: %w", err)
+	}
+	if _, err := d.w.Write(append(payload, '\n')); err != nil {
+		return err
+	}
+
+	var failures []string
+	if d.opts.Webhook != "" {
+		if err := d.postWebhook(ctx, payload); err != nil {
+			failures = append(failures, fmt.Sprintf("webhook: %v", err))
+		}
+	}
+	if d.opts.Exec != "" {
+		if err := d.runCommand(ctx, event, payload); err != nil {
+			failures = append(failures, fmt.Sprintf("exec: %v", err))
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("%s event delivery failed: %s", event.Type, strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// postWebhook posts the event JSON to the webhook URL
+func (d *watchDispatcher) postWebhook(ctx context.Context, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.opts.Webhook, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// runCommand runs the command through the shell with the event JSON on stdin and its main fields
+// in CLAUDECAT_* environment variables
+func (d *watchDispatcher) runCommand(ctx context.Context, event WatchEvent, payload []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, watchDeliveryTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", d.opts.Exec)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", d.opts.Exec)
+	}
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout, cmd.Stderr = os.Stderr, os.Stderr
+	cmd.Env = append(os.Environ(),
+		"CLAUDECAT_EVENT="+event.Type,
+		"CLAUDECAT_SESSION_ID="+event.SessionID,
+		"CLAUDECAT_MESSAGE="+event.Message,
+	)
+	if event.Session != nil {
+		cmd.Env = append(cmd.Env,
+			fmt.Sprintf("CLAUDECAT_TOKENS=%d", event.Session.Tokens),
+			fmt.Sprintf("CLAUDECAT_COST_USD=%.4f", event.Session.CostUSD),
+		)
+	}
+	return cmd.Run()
+}
+
+// RunWatch monitors usage with the orchestrator, without a display, and delivers a watch event for
+// every refresh, limit crossing and session change until ctx is done. Events are written to w as JSON
+// lines and delivered one at a time; when delivery falls behind, new events are dropped.
+func RunWatch(ctx context.Context, cfg *config.Config, opts WatchOptions, w io.Writer) error {
+	dispatcher, err := newWatchDispatcher(cfg, opts, w)
+	if err != nil {
+		return err
+	}
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+
+	monitor := orchestrator.NewMonitoringOrchestrator(interval, resolveDataPath(cfg, logging.NewLogger(cfg.App.LogLevel, cfg.App.LogFile)), cfg)
+	queue := make(chan WatchEvent, watchQueueSize)
+	unsubscribeSessions := events.Subscribe(monitor.Bus(), dispatcher.sessionChanged)
+	defer unsubscribeSessions()
+	unsubscribeUpdates := events.Subscribe(monitor.Bus(), func(data orchestrator.MonitoringData) {
+		for _, event := range dispatcher.updated(data) {
+			select {
+			case queue <- event:
+			default:
+				logging.LogWarnf("Watch delivery is falling behind; dropped %s event", event.Type)
+			}
+		}
+	})
+	defer unsubscribeUpdates()
+
+	if err := monitor.Start(); err != nil {
+		return fmt.Errorf("failed to start monitoring: %w", err)
+	}
+	defer monitor.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event := <-queue:
+			if err := dispatcher.deliver(ctx, event); err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				logging.LogWarnf("%v", err)
+				fmt.Fprintf(os.Stderr, "claudecat watch: %v\n", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/models"
+	"github.com/penwyp/claudecat/orchestrator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestWatchDispatcher(t *testing.T, opts WatchOptions, w io.Writer) *watchDispatcher {
+	t.Helper()
+	cfg := config.DefaultConfig()
+	cfg.Subscription = config.SubscriptionConfig{Plan: models.PlanPro, WarnThreshold: 0.8, AlertThreshold: 0.95}
+	d, err := newWatchDispatcher(cfg, opts, w)
+	require.NoError(t, err)
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	d.now = func() time.Time { return now }
+	return d
+}
+
+func TestWatchDispatcher_Events(t *testing.T) {
+	d := newTestWatchDispatcher(t, WatchOptions{}, io.Discard)
+	start := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	ended := models.SessionBlock{ID: "s1", StartTime: start.Add(-5 * time.Hour), EndTime: start, CostUSD: 3}
+	active := models.SessionBlock{ID: "s2", StartTime: start, EndTime: start.Add(5 * time.Hour), IsActive: true,
+		CostUSD: 15, TokenCounts: models.TokenCounts{InputTokens: 100}, SentMessagesCount: 2, Models: []string{"claude-sonnet-4-20250514"}}
+	data := orchestrator.MonitoringData{SessionID: "s2", Data: orchestrator.AnalysisResult{Blocks: []models.SessionBlock{ended, active}}}
+
+	d.sessionChanged(orchestrator.SessionChanged{Type: orchestrator.SessionEnd, SessionID: "s1"})
+	d.sessionChanged(orchestrator.SessionChanged{Type: orchestrator.SessionStart, SessionID: "s2"})
+	d.sessionChanged(orchestrator.SessionChanged{Type: orchestrator.SessionUpdate, SessionID: "s2"})
+
+	watchEvents := d.updated(data)
+	var types []string
+	for _, event := range watchEvents {
+		types = append(types, event.Type)
+	}
+	assert.Equal(t, []string{WatchSessionEnd, WatchSessionStart, WatchLimit, WatchUpdate}, types)
+
+	assert.InDelta(t, 3, watchEvents[0].Session.CostUSD, 1e-9)
+	assert.False(t, watchEvents[0].Session.Active)
+	limit := watchEvents[2]
+	assert.Equal(t, "s2", limit.SessionID)
+	assert.Equal(t, "warning", limit.Level)
+	assert.Equal(t, AlertMetricSessionCost, limit.Metric)
+	assert.InDelta(t, 18*0.8, limit.Threshold, 1e-9)
+	update := watchEvents[3]
+	assert.Equal(t, WatchSession{Start: start, End: start.Add(5 * time.Hour), Active: true, Tokens: 100, CostUSD: 15,
+		CostLimit: 18, Messages: 2, Models: []string{"claude-sonnet-4-20250514"}}, *update.Session)
+
+	// Session changes and crossings are reported once
+	assert.Len(t, d.updated(data), 1)
+}
+
+func TestWatchDispatcher_FiltersEvents(t *testing.T) {
+	_, err := newWatchDispatcher(config.DefaultConfig(), WatchOptions{Events: []string{"batch"}}, io.Discard)
+	assert.ErrorContains(t, err, "invalid watch event: batch")
+
+	d := newTestWatchDispatcher(t, WatchOptions{Events: []string{WatchSessionStart}}, io.Discard)
+	assert.Empty(t, d.updated(orchestrator.MonitoringData{}))
+	d.sessionChanged(orchestrator.SessionChanged{Type: orchestrator.SessionStart, SessionID: "s1"})
+	watchEvents := d.updated(orchestrator.MonitoringData{})
+	require.Len(t, watchEvents, 1)
+	assert.Nil(t, watchEvents[0].Session, "the session is not in the data")
+}
+
+func TestWatchDispatcher_Deliver(t *testing.T) {
+	var posted []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		posted, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	opts := WatchOptions{Webhook: server.URL}
+	envFile := filepath.Join(t.TempDir(), "env")
+	if runtime.GOOS != "windows" {
+		opts.Exec = fmt.Sprintf(`cat > %q && echo "$CLAUDECAT_EVENT $CLAUDECAT_SESSION_ID $CLAUDECAT_COST_USD" > %q`, envFile+".json", envFile)
+	}
+	d := newTestWatchDispatcher(t, opts, &out)
+
+	event := WatchEvent{Type: WatchUpdate, Time: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC), SessionID: "s1", Session: &WatchSession{CostUSD: 1.5}}
+	require.NoError(t, d.deliver(context.Background(), event))
+
+	var printed WatchEvent
+	require.NoError(t, json.Unmarshal(out.Bytes(), &printed))
+	assert.Equal(t, event.SessionID, printed.SessionID)
+	assert.JSONEq(t, strings.TrimSpace(out.String()), string(posted))
+
+	if runtime.GOOS != "windows" {
+		env, err := os.ReadFile(envFile)
+		require.NoError(t, err)
+		assert.Equal(t, "update s1 1.5000\n", string(env))
+		stdin, err := os.ReadFile(envFile + ".json")
+		require.NoError(t, err)
+		assert.JSONEq(t, string(posted), string(stdin))
+	}
+
+	server.Close()
+	d.opts.Exec = "exit 3"
+	err := d.deliver(context.Background(), event)
+	assert.ErrorContains(t, err, "webhook:")
+	assert.ErrorContains(t, err, "exec: exit status 3")
+}
@@ -0,0 +1,190 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"sync"
+	"time"
+
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/humanize"
+)
+
+// Watchdog defaults, used for zero settings
+const (
+	defaultWatchdogInterval      = 30 * time.Second
+	defaultWatchdogMaxRSSMB      = 1024
+	defaultWatchdogMaxHeapMB     = 512
+	defaultWatchdogMaxGoroutines = 1000
+)
+
+// watchdogMaxProfiles bounds the heap profiles one process writes, so a leak does not fill the disk
+const watchdogMaxProfiles = 3
+
+// WatchdogSample is one reading of the memory and goroutines of the process
+type WatchdogSample struct {
+	Time       time.Time
+	RSS        uint64 // Resident set size; 0 where the platform does not report it
+	HeapAlloc  uint64
+	Goroutines int
+}
+
+// SampleProcess reads the current memory and goroutine counts of the process
+func SampleProcess(now time.Time) WatchdogSample {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	rss, _ := residentMemory()
+	return WatchdogSample{Time: now, RSS: rss, HeapAlloc: mem.HeapAlloc, Goroutines: runtime.NumGoroutine()}
+}
+
+// Watchdog samples the memory and goroutines of the process and warns when they exceed their
+// thresholds, so slow leaks in long-running monitors and servers do not go unnoticed
+type Watchdog struct {
+	interval      time.Duration
+	maxRSS        uint64
+	maxHeap       uint64
+	maxGoroutines int
+	profileDir    string // Empty disables heap profiles
+
+	mu       sync.Mutex
+	last     WatchdogSample
+	over     map[string]bool // Thresholds exceeded by the last sample
+	warnings []string        // Thresholds exceeded by the last sample, for the debug panel
+	profiles []string
+}
+
+// NewWatchdog creates a watchdog from the debug configuration
+func NewWatchdog(cfg config.WatchdogConfig) *Watchdog {
+	w := &Watchdog{
+		interval:      cfg.Interval,
+		maxRSS:        uint64(cfg.MaxRSSMB) << 20,
+		maxHeap:       uint64(cfg.MaxHeapMB) << 20,
+		maxGoroutines: cfg.MaxGoroutines,
+		profileDir:    cfg.ProfileDir,
+		over:          make(map[string]bool),
+	}
+	if w.interval <= 0 {
+		w.interval = defaultWatchdogInterval
+	}
+	if w.maxRSS == 0 {
+		w.maxRSS = defaultWatchdogMaxRSSMB << 20
+	}
+	if w.maxHeap == 0 {
+		w.maxHeap = defaultWatchdogMaxHeapMB << 20
+	}
+	if w.maxGoroutines <= 0 {
+		w.maxGoroutines = defaultWatchdogMaxGoroutines
+	}
+	w.profileDir = config.ExpandHome(w.profileDir)
+	return w
+}
+
+// Run samples the process every interval until ctx is done, passing each new warning to warn
+func (w *Watchdog) Run(ctx context.Context, warn func(message string)) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		for _, message := range w.Observe(SampleProcess(time.Now())) {
+			warn(message)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Observe records a sample and returns a warning for each threshold it exceeds that the previous
+// sample did not. When a profile directory is set, a heap profile is written along with the warnings.
+func (w *Watchdog) Observe(sample WatchdogSample) []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.last = sample
+	checks := []struct {
+		name    string
+		over    bool
+		message string
+	}{
+		{"rss", sample.RSS > w.maxRSS, fmt.Sprintf("Resident memory %s above %s",
+			humanize.Bytes(int64(sample.RSS)), humanize.Bytes(int64(w.maxRSS)))},
+		{"heap", sample.HeapAlloc > w.maxHeap, fmt.Sprintf("Heap %s above %s",
+			humanize.Bytes(int64(sample.HeapAlloc)), humanize.Bytes(int64(w.maxHeap)))},
+		{"goroutines", sample.Goroutines > w.maxGoroutines, fmt.Sprintf("%d goroutines above %d",
+			sample.Goroutines, w.maxGoroutines)},
+	}
+
+	var fired []string
+	w.warnings = nil
+	for _, check := range checks {
+		if !check.over {
+			delete(w.over, check.name)
+			continue
+		}
+		w.warnings = append(w.warnings, check.message)
+		if !w.over[check.name] {
+			w.over[check.name] = true
+			fired = append(fired, check.message)
+		}
+	}
+
+	if len(fired) > 0 && w.profileDir != "" && len(w.profiles) < watchdogMaxProfiles {
+		if path, err := w.writeHeapProfile(sample.Time); err != nil {
+			fired = append(fired, fmt.Sprintf("Heap profile failed: %v", err))
+		} else {
+			w.profiles = append(w.profiles, path)
+			fired = append(fired, "Heap profile written to "+path)
+		}
+	}
+	return fired
+}
+
+// writeHeapProfile writes a heap profile named after now to the profile directory
+func (w *Watchdog) writeHeapProfile(now time.Time) (string, error) {
+	if err := os.MkdirAll(w.profileDir, 0700); err != nil {
+		return "", err
+	}
+	path := filepath.Join(w.profileDir, fmt.Sprintf("heap-%s.pprof", now.Format("20060102-150405")))
+	file, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	// The profile reflects the heap as of the last collection
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(file); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// PanelLines renders the last sample, the exceeded thresholds and the written profiles for the
+// debug panel of the monitor
+func (w *Watchdog) PanelLines() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.last.Time.IsZero() {
+		return []string{"🐞 Watchdog: waiting for the first sample", ""}
+	}
+	rss := "n/a"
+	if w.last.RSS > 0 {
+		rss = humanize.Bytes(int64(w.last.RSS))
+	}
+	lines := []string{fmt.Sprintf("🐞 Watchdog: RSS %s / %s · heap %s / %s · %d / %d goroutines",
+		rss, humanize.Bytes(int64(w.maxRSS)), humanize.Bytes(int64(w.last.HeapAlloc)), humanize.Bytes(int64(w.maxHeap)),
+		w.last.Goroutines, w.maxGoroutines)}
+	for _, warning := range w.warnings {
+		lines = append(lines, "   ⚠️ "+warning)
+	}
+	for _, path := range w.profiles {
+		lines = append(lines, "   Heap profile: "+path)
+	}
+	return append(lines, "")
+}
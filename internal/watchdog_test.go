@@ -0,0 +1,56 @@
+package internal
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchdog_WarnsOncePerExcess(t *testing.T) {
+	dir := t.TempDir()
+	watchdog := NewWatchdog(config.WatchdogConfig{MaxHeapMB: 100, MaxGoroutines: 50, ProfileDir: dir})
+	now := time.Date(2025, 3, 11, 10, 0, 0, 0, time.UTC)
+
+	assert.Empty(t, watchdog.Observe(WatchdogSample{Time: now, HeapAlloc: 10 << 20, Goroutines: 20}))
+
+	warnings := watchdog.Observe(WatchdogSample{Time: now.Add(time.Minute), HeapAlloc: 150 << 20, Goroutines: 20})
+	require.Len(t, warnings, 2, "the heap warning and the profile")
+	assert.Contains(t, warnings[0], "Heap 150.0 MiB above 100.0 MiB")
+	assert.Contains(t, warnings[1], "Heap profile written to ")
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "heap-20250311-100100.pprof", entries[0].Name())
+
+	// A threshold that stays exceeded does not warn again, a newly exceeded one does
+	assert.Empty(t, watchdog.Observe(WatchdogSample{Time: now.Add(2 * time.Minute), HeapAlloc: 160 << 20, Goroutines: 20}))
+	warnings = watchdog.Observe(WatchdogSample{Time: now.Add(3 * time.Minute), HeapAlloc: 160 << 20, Goroutines: 80})
+	require.Len(t, warnings, 2)
+	assert.Equal(t, "80 goroutines above 50", warnings[0])
+
+	lines := watchdog.PanelLines()
+	assert.Contains(t, lines[0], "heap 160.0 MiB / 100.0 MiB")
+	assert.Contains(t, lines[0], "80 / 50 goroutines")
+	assert.Contains(t, lines[0], "RSS n/a", "the samples carry no RSS")
+	assert.Contains(t, lines, "   ⚠️ Heap 160.0 MiB above 100.0 MiB")
+
+	// Falling back below re-arms the warning
+	assert.Empty(t, watchdog.Observe(WatchdogSample{Time: now.Add(4 * time.Minute), HeapAlloc: 10 << 20, Goroutines: 20}))
+	warnings = watchdog.Observe(WatchdogSample{Time: now.Add(5 * time.Minute), HeapAlloc: 150 << 20, Goroutines: 20})
+	require.Len(t, warnings, 2)
+	assert.Contains(t, warnings[1], "Heap profile written to ")
+
+	// Profiles stop at the cap
+	assert.Empty(t, watchdog.Observe(WatchdogSample{Time: now.Add(6 * time.Minute), Goroutines: 20}))
+	assert.Len(t, watchdog.Observe(WatchdogSample{Time: now.Add(7 * time.Minute), HeapAlloc: 150 << 20, Goroutines: 20}), 1)
+}
+
+func TestSampleProcess(t *testing.T) {
+	sample := SampleProcess(time.Now())
+	assert.Positive(t, sample.HeapAlloc)
+	assert.Positive(t, sample.Goroutines)
+}
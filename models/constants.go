@@ -10,9 +10,10 @@ const (
 
 // Model identifiers
 const (
-	ModelOpus   = "claude-3-opus-20240229"
-	ModelSonnet = "claude-3-5-sonnet-20241022"
-	ModelHaiku  = "claude-3-5-haiku-20241022"
+	ModelOpus    = "claude-3-opus-20240229"
+	ModelSonnet  = "claude-3-5-sonnet-20241022"
+	ModelSonnet4 = "claude-sonnet-4-20250514"
+	ModelHaiku   = "claude-3-5-haiku-20241022"
 )
 
 // Plan identifiers
@@ -56,3 +57,6 @@ const (
 	MinTerminalWidth      = 60
 	MinTerminalHeight     = 10
 )
+
+// BuiltinToolServer is the tool server of tools built into Claude Code, such as Bash and Read
+const BuiltinToolServer = "built-in"
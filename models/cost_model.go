@@ -0,0 +1,36 @@
+package models
+
+// CostModel prices usage entries under one billing arrangement, so that the same entries can be
+// costed as a subscription, the Anthropic API or a cloud marketplace would bill them
+type CostModel interface {
+	// Cost returns the cost of entry in USD, given the list pricing of its model
+	Cost(entry UsageEntry, list ModelPricing) float64
+
+	// Name returns the name the cost model is selected by
+	Name() string
+}
+
+// RateTier replaces the rates of requests whose prompt exceeds Above tokens, as long-context pricing does
+type RateTier struct {
+	Above   int // Prompt tokens: input, cache writes and cache reads
+	Pricing ModelPricing
+}
+
+// RateCard holds the rates a cost model bills the requests to one model at
+type RateCard struct {
+	Pricing    ModelPricing
+	Tiers      []RateTier // By ascending Above; the last tier a request exceeds applies
+	RequestFee float64    // USD per request on top of the tokens
+}
+
+// Cost returns the cost of entry under the card
+func (c RateCard) Cost(entry UsageEntry) float64 {
+	pricing := c.Pricing
+	prompt := entry.InputTokens + entry.CacheCreationTokens + entry.CacheReadTokens
+	for _, tier := range c.Tiers {
+		if prompt > tier.Above {
+			pricing = tier.Pricing
+		}
+	}
+	return entry.CalculateCost(pricing) + c.RequestFee
+}
@@ -2,10 +2,36 @@ package models
 
 // ModelPricing defines token pricing for different Claude models
 type ModelPricing struct {
-	Input         float64 // Per million tokens
-	Output        float64 // Per million tokens
-	CacheCreation float64 // Per million tokens
-	CacheRead     float64 // Per million tokens
+	Input           float64 // Per million tokens
+	Output          float64 // Per million tokens
+	CacheCreation   float64 // Per million tokens, 5-minute cache writes
+	CacheCreation1h float64 // Per million tokens, 1-hour cache writes; 0 means 2x the input price
+	CacheRead       float64 // Per million tokens
+
+	// Long-context pricing of the API: requests whose prompt, input and cache tokens, exceeds
+	// LongContextAbove bill their prompt rates times LongContextPrompt and their output rate times
+	// LongContextOutput. 0 means the model has no long-context rates.
+	LongContextAbove  int
+	LongContextPrompt float64
+	LongContextOutput float64
+}
+
+// CacheCreation1hPrice returns the 1-hour cache write price, defaulting to twice the input price
+func (p ModelPricing) CacheCreation1hPrice() float64 {
+	if p.CacheCreation1h > 0 {
+		return p.CacheCreation1h
+	}
+	return p.Input * 2
+}
+
+// Scaled returns the rates multiplied by prompt for input and cache tokens and by output for output tokens
+func (p ModelPricing) Scaled(prompt, output float64) ModelPricing {
+	p.CacheCreation1h = p.CacheCreation1hPrice() * prompt
+	p.Input *= prompt
+	p.Output *= output
+	p.CacheCreation *= prompt
+	p.CacheRead *= prompt
+	return p
 }
 
 // Plan represents a subscription plan with token and cost limits
@@ -15,25 +41,45 @@ type Plan struct {
 	CostLimit  float64 `json:"cost_limit"`
 }
 
+// PlanLimits contains the per-session limits used by the monitor for a subscription plan
+type PlanLimits struct {
+	TokenLimit   int     `json:"token_limit"`
+	CostLimit    float64 `json:"cost_limit"`
+	MessageLimit int     `json:"message_limit"`
+}
+
 // modelPricingMap stores pricing for all Claude models
 var modelPricingMap = map[string]ModelPricing{
 	ModelOpus: {
-		Input:         15.00, // $15 per million tokens
-		Output:        75.00, // $75 per million tokens
-		CacheCreation: 18.75, // $18.75 per million tokens
-		CacheRead:     1.875, // $1.875 per million tokens
+		Input:           15.00, // $15 per million tokens
+		Output:          75.00, // $75 per million tokens
+		CacheCreation:   18.75, // $18.75 per million tokens
+		CacheCreation1h: 30.00, // $30 per million tokens
+		CacheRead:       1.875, // $1.875 per million tokens
 	},
 	ModelSonnet: {
-		Input:         3.00,  // $3 per million tokens
-		Output:        15.00, // $15 per million tokens
-		CacheCreation: 3.75,  // $3.75 per million tokens
-		CacheRead:     0.30,  // $0.30 per million tokens
+		Input:           3.00,  // $3 per million tokens
+		Output:          15.00, // $15 per million tokens
+		CacheCreation:   3.75,  // $3.75 per million tokens
+		CacheCreation1h: 6.00,  // $6 per million tokens
+		CacheRead:       0.30,  // $0.30 per million tokens
+	},
+	ModelSonnet4: {
+		Input:             3.00,  // $3 per million tokens
+		Output:            15.00, // $15 per million tokens
+		CacheCreation:     3.75,  // $3.75 per million tokens
+		CacheCreation1h:   6.00,  // $6 per million tokens
+		CacheRead:         0.30,  // $0.30 per million tokens
+		LongContextAbove:  200_000,
+		LongContextPrompt: 2,   // $6 per million input tokens above 200K
+		LongContextOutput: 1.5, // $22.50 per million output tokens above 200K
 	},
 	ModelHaiku: {
-		Input:         0.80, // $0.80 per million tokens
-		Output:        4.00, // $4 per million tokens
-		CacheCreation: 1.00, // $1 per million tokens
-		CacheRead:     0.08, // $0.08 per million tokens
+		Input:           0.80, // $0.80 per million tokens
+		Output:          4.00, // $4 per million tokens
+		CacheCreation:   1.00, // $1 per million tokens
+		CacheCreation1h: 1.60, // $1.60 per million tokens
+		CacheRead:       0.08, // $0.08 per million tokens
 	},
 }
 
@@ -56,6 +102,20 @@ var planMap = map[string]Plan{
 	},
 }
 
+// defaultPlanLimits stores the built-in per-session limits for each plan
+var defaultPlanLimits = map[string]PlanLimits{
+	PlanPro:   {TokenLimit: 1000000, CostLimit: 18.0, MessageLimit: 1500},
+	PlanMax5:  {TokenLimit: 88000, CostLimit: 35.0, MessageLimit: 1000},
+	PlanMax20: {TokenLimit: 8000000, CostLimit: 140.0, MessageLimit: 12000},
+}
+
+// planMonthlyPrices stores the monthly subscription price of each plan in USD
+var planMonthlyPrices = map[string]float64{
+	PlanPro:   20.00,
+	PlanMax5:  100.00,
+	PlanMax20: 200.00,
+}
+
 // GetPricing returns the pricing for a specific model
 func GetPricing(model string) ModelPricing {
 	if pricing, ok := modelPricingMap[model]; ok {
@@ -93,3 +153,16 @@ func GetAllPricings() map[string]ModelPricing {
 	}
 	return result
 }
+
+// GetPlanLimits returns the built-in per-session limits for a plan, defaulting to Pro
+func GetPlanLimits(planName string) PlanLimits {
+	if limits, ok := defaultPlanLimits[planName]; ok {
+		return limits
+	}
+	return defaultPlanLimits[PlanPro]
+}
+
+// GetPlanMonthlyPrice returns the monthly subscription price of a plan, or 0 if unknown
+func GetPlanMonthlyPrice(planName string) float64 {
+	return planMonthlyPrices[planName]
+}
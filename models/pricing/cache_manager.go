@@ -9,6 +9,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/penwyp/claudecat/config"
 	"github.com/penwyp/claudecat/models"
 )
 
@@ -29,13 +30,7 @@ type PricingCache struct {
 // NewCacheManager creates a new pricing cache manager
 func NewCacheManager(cacheDir string) (*CacheManager, error) {
 	// Expand ~ to home directory
-	if cacheDir[:2] == "~/" {
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get home directory: %w", err)
-		}
-		cacheDir = filepath.Join(homeDir, cacheDir[2:])
-	}
+	cacheDir = config.ExpandHome(cacheDir)
 
 	// Ensure cache directory exists
 	if err := os.MkdirAll(cacheDir, 0755); err != nil {
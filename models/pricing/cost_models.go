@@ -0,0 +1,108 @@
+package pricing
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/models"
+)
+
+// Cost models selectable with data.cost_model
+const (
+	CostModelSubscription = "subscription" // List prices per token: what a subscription's usage would cost on the API
+	CostModelAPI          = "api"          // Anthropic API billing, with long-context rates
+	CostModelBedrock      = "bedrock"      // Amazon Bedrock
+	CostModelVertex       = "vertex"       // Google Cloud Vertex AI
+)
+
+// costModelRate describes how a cost model bills relative to list prices
+type costModelRate struct {
+	longContext     bool    // Bills the long-context rates of the pricing table
+	regionalPremium float64 // Markup of regional endpoints over global ones, selected with -regional; 0 when there are none
+}
+
+// costModelRates holds the billing of each cost model: Bedrock and Vertex AI bill at the API's rates
+// on global endpoints and 10% more on regional ones
+var costModelRates = map[string]costModelRate{
+	CostModelSubscription: {},
+	CostModelAPI:          {longContext: true},
+	CostModelBedrock:      {longContext: true, regionalPremium: 1.1},
+	CostModelVertex:       {longContext: true, regionalPremium: 1.1},
+}
+
+// RateCostModel is a CostModel billing each model at a rate card derived from its list pricing
+type RateCostModel struct {
+	name       string
+	rate       costModelRate
+	premium    float64
+	requestFee float64
+}
+
+// Name returns the name of the cost model
+func (m *RateCostModel) Name() string {
+	return m.name
+}
+
+// Cost returns the cost of entry at the rate card of its model
+func (m *RateCostModel) Cost(entry models.UsageEntry, list models.ModelPricing) float64 {
+	return m.card(list).Cost(entry)
+}
+
+// card returns the rate card a model with the list pricing is billed at
+func (m *RateCostModel) card(list models.ModelPricing) models.RateCard {
+	card := models.RateCard{Pricing: list.Scaled(m.premium, m.premium), RequestFee: m.requestFee}
+	if m.rate.longContext && list.LongContextAbove > 0 {
+		card.Tiers = []models.RateTier{{
+			Above:   list.LongContextAbove,
+			Pricing: list.Scaled(list.LongContextPrompt*m.premium, list.LongContextOutput*m.premium),
+		}}
+	}
+	return card
+}
+
+// NewCostModel creates the cost model called name, adding requestFee USD to every request. The
+// -regional suffix selects the regional endpoints of cost models that have them.
+func NewCostModel(name string, requestFee float64) (models.CostModel, error) {
+	base, regional := strings.CutSuffix(name, "-regional")
+	rate, ok := costModelRates[base]
+	if !ok || (regional && rate.regionalPremium == 0) {
+		return nil, fmt.Errorf("unknown cost model: %s", name)
+	}
+	premium := 1.0
+	if regional {
+		premium = rate.regionalPremium
+	}
+	return &RateCostModel{name: name, rate: rate, premium: premium, requestFee: requestFee}, nil
+}
+
+// CostModelForPath returns the cost model of the data path, from data.cost_models or else
+// data.cost_model, or nil when it bills list prices, the costs cached summaries hold
+func CostModelForPath(cfg *config.DataConfig, path string) (models.CostModel, error) {
+	name := cfg.CostModel
+	for _, entry := range cfg.CostModels {
+		if sameDataPath(entry.Path, path) {
+			name = entry.Model
+			break
+		}
+	}
+	if (name == "" || name == CostModelSubscription) && cfg.RequestFee == 0 {
+		return nil, nil
+	}
+	if name == "" {
+		name = CostModelSubscription
+	}
+	return NewCostModel(name, cfg.RequestFee)
+}
+
+// sameDataPath reports whether the configured data path source, which may start with ~/, is path
+func sameDataPath(source, path string) bool {
+	source = config.ExpandHome(source)
+	a, errA := filepath.Abs(source)
+	b, errB := filepath.Abs(path)
+	if errA != nil || errB != nil {
+		return filepath.Clean(source) == filepath.Clean(path)
+	}
+	return a == b
+}
@@ -0,0 +1,71 @@
+package pricing
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCostModels(t *testing.T) {
+	sonnet := models.GetPricing(models.ModelSonnet4)
+	short := models.UsageEntry{Model: "claude-sonnet-4-20250514", InputTokens: 1000, OutputTokens: 1000}
+	long := models.UsageEntry{Model: "claude-sonnet-4-20250514", InputTokens: 150_000, CacheReadTokens: 100_000, OutputTokens: 1000}
+
+	costs := map[string][2]float64{
+		"subscription":     {0.018, 0.495},
+		"api":              {0.018, 0.9825},
+		"vertex":           {0.018, 0.9825},
+		"bedrock-regional": {0.0198, 1.08075},
+	}
+	for name, want := range costs {
+		model, err := NewCostModel(name, 0)
+		require.NoError(t, err)
+		assert.Equal(t, name, model.Name())
+		assert.InDelta(t, want[0], model.Cost(short, sonnet), 1e-9, name)
+		assert.InDelta(t, want[1], model.Cost(long, sonnet), 1e-9, "%s above the long-context threshold", name)
+	}
+
+	api, err := NewCostModel(CostModelAPI, 0.01)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.028, api.Cost(short, sonnet), 1e-9, "request fee")
+	haiku := models.UsageEntry{Model: models.ModelHaiku, InputTokens: 250_000}
+	assert.InDelta(t, 0.21, api.Cost(haiku, models.GetPricing(models.ModelHaiku)), 1e-9, "no long-context rates")
+
+	// Long-context rates come from the pricing table, as the default provider resolves models
+	resolved, err := NewDefaultProvider().GetPricing(context.Background(), "claude-sonnet-4-5-20250929")
+	require.NoError(t, err)
+	assert.InDelta(t, 0.9825, api.Cost(long, resolved)-0.01, 1e-9)
+	opus, err := NewDefaultProvider().GetPricing(context.Background(), "claude-opus-4-20250514")
+	require.NoError(t, err)
+	assert.Zero(t, opus.LongContextAbove)
+
+	for _, name := range []string{"azure", "api-regional", "subscription-regional"} {
+		_, err := NewCostModel(name, 0)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestCostModelForPath(t *testing.T) {
+	work := filepath.Join(t.TempDir(), "work")
+	cfg := &config.DataConfig{CostModel: CostModelSubscription, CostModels: []config.CostModelPath{{Path: work, Model: CostModelBedrock}}}
+
+	model, err := CostModelForPath(cfg, "personal")
+	require.NoError(t, err)
+	assert.Nil(t, model, "list prices need no cost model")
+
+	model, err = CostModelForPath(cfg, work+string(filepath.Separator))
+	require.NoError(t, err)
+	require.NotNil(t, model)
+	assert.Equal(t, CostModelBedrock, model.Name())
+
+	cfg.RequestFee = 0.01
+	model, err = CostModelForPath(cfg, "personal")
+	require.NoError(t, err)
+	require.NotNil(t, model)
+	assert.Equal(t, CostModelSubscription, model.Name())
+}
@@ -0,0 +1,300 @@
+package pricing
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+)
+
+const (
+	// DefaultDataBundleURL is the release asset containing the latest pricing and limit tables
+	DefaultDataBundleURL = "https://github.com/penwyp/claudecat/releases/latest/download/claudecat-data.json"
+
+	// dataBundleFile and dataSignatureFile are the installed bundle and its detached signature
+	dataBundleFile    = "data_bundle.json"
+	dataSignatureFile = "data_bundle.json.sig"
+
+	// maxDataBundleSize guards against oversized downloads
+	maxDataBundleSize = 4 * 1024 * 1024
+)
+
+// DataPublicKey is the base64-encoded ed25519 key used to sign release data bundles.
+// It is set at build time via -ldflags "-X github.com/penwyp/claudecat/models/pricing.DataPublicKey=...".
+var DataPublicKey = ""
+
+// DataBundle is a versioned set of pricing and plan limit tables shipped as a release asset
+type DataBundle struct {
+	Version   int                          `json:"version"`
+	Published time.Time                    `json:"published"`
+	Pricing   map[string]BundlePricing     `json:"pricing"`
+	Limits    map[string]models.PlanLimits `json:"limits"`
+}
+
+// BundlePricing is the serialized per-model pricing in a data bundle (USD per million tokens)
+type BundlePricing struct {
+	Input           float64 `json:"input"`
+	Output          float64 `json:"output"`
+	CacheCreation   float64 `json:"cache_creation"`
+	CacheCreation1h float64 `json:"cache_creation_1h,omitempty"`
+	CacheRead       float64 `json:"cache_read"`
+
+	LongContextAbove  int     `json:"long_context_above,omitempty"`
+	LongContextPrompt float64 `json:"long_context_prompt,omitempty"`
+	LongContextOutput float64 `json:"long_context_output,omitempty"`
+}
+
+// ModelPricing converts the bundle pricing to the internal representation
+func (p BundlePricing) ModelPricing() models.ModelPricing {
+	return models.ModelPricing{
+		Input:           p.Input,
+		Output:          p.Output,
+		CacheCreation:   p.CacheCreation,
+		CacheCreation1h: p.CacheCreation1h,
+		CacheRead:       p.CacheRead,
+
+		LongContextAbove:  p.LongContextAbove,
+		LongContextPrompt: p.LongContextPrompt,
+		LongContextOutput: p.LongContextOutput,
+	}
+}
+
+// ParsePublicKey decodes a base64-encoded ed25519 public key
+func ParsePublicKey(encoded string) (ed25519.PublicKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode public key: %w", err)
+	}
+	if len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key size: %d bytes", len(raw))
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// VerifyDataBundle checks the base64 signature of data and parses the bundle
+func VerifyDataBundle(data, signature []byte, publicKey ed25519.PublicKey) (*DataBundle, error) {
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode signature: %w", err)
+	}
+	if !ed25519.Verify(publicKey, data, sig) {
+		return nil, fmt.Errorf("data bundle signature verification failed")
+	}
+
+	var bundle DataBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("failed to parse data bundle: %w", err)
+	}
+	if bundle.Version <= 0 {
+		return nil, fmt.Errorf("data bundle has invalid version %d", bundle.Version)
+	}
+	if len(bundle.Pricing) == 0 {
+		return nil, fmt.Errorf("data bundle contains no pricing")
+	}
+	for plan, limits := range bundle.Limits {
+		if limits.TokenLimit <= 0 || limits.CostLimit <= 0 || limits.MessageLimit <= 0 {
+			return nil, fmt.Errorf("data bundle has invalid limits for plan %s", plan)
+		}
+	}
+	return &bundle, nil
+}
+
+// DataUpdater downloads, verifies and installs signed data bundles
+type DataUpdater struct {
+	url        string
+	publicKey  ed25519.PublicKey
+	dir        string
+	httpClient *http.Client
+}
+
+// NewDataUpdater creates an updater that installs bundles into cacheDir
+func NewDataUpdater(url string, publicKey ed25519.PublicKey, cacheDir string) *DataUpdater {
+	if url == "" {
+		url = DefaultDataBundleURL
+	}
+	return &DataUpdater{
+		url:       url,
+		publicKey: publicKey,
+		dir:       cacheDir,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// Fetch downloads the bundle and its signature and verifies them without installing
+func (u *DataUpdater) Fetch(ctx context.Context) (*DataBundle, []byte, []byte, error) {
+	data, err := u.download(ctx, u.url)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to download data bundle: %w", err)
+	}
+	signature, err := u.download(ctx, u.url+".sig")
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to download data bundle signature: %w", err)
+	}
+
+	bundle, err := VerifyDataBundle(data, signature, u.publicKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return bundle, data, signature, nil
+}
+
+// Install writes a verified bundle and its signature to the cache directory.
+// Older versions are rejected unless force is set, preventing rollback to stale tables.
+func (u *DataUpdater) Install(bundle *DataBundle, data, signature []byte, force bool) error {
+	if current, err := LoadDataBundle(u.dir, u.publicKey); err == nil && !force && bundle.Version <= current.Version {
+		return fmt.Errorf("data bundle version %d is not newer than installed version %d", bundle.Version, current.Version)
+	}
+
+	if err := os.MkdirAll(u.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	// Stage both files before replacing either, so a failed install keeps the old verified pair
+	bundlePath := filepath.Join(u.dir, dataBundleFile)
+	signaturePath := filepath.Join(u.dir, dataSignatureFile)
+	if err := os.WriteFile(bundlePath+".tmp", data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", dataBundleFile, err)
+	}
+	defer os.Remove(bundlePath + ".tmp")
+	if err := os.WriteFile(signaturePath+".tmp", signature, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", dataSignatureFile, err)
+	}
+	defer os.Remove(signaturePath + ".tmp")
+
+	previous, previousErr := os.ReadFile(bundlePath)
+	if err := os.Rename(bundlePath+".tmp", bundlePath); err != nil {
+		return fmt.Errorf("failed to rename %s: %w", dataBundleFile, err)
+	}
+	if err := os.Rename(signaturePath+".tmp", signaturePath); err != nil {
+		// Put the previous bundle back so it still matches the installed signature
+		if previousErr == nil {
+			writeFileAtomic(bundlePath, previous)
+		} else {
+			os.Remove(bundlePath)
+		}
+		return fmt.Errorf("failed to rename %s: %w", dataSignatureFile, err)
+	}
+	return nil
+}
+
+// download fetches url with a size limit
+func (u *DataUpdater) download(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := u.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxDataBundleSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if len(data) > maxDataBundleSize {
+		return nil, fmt.Errorf("response exceeds %d bytes", maxDataBundleSize)
+	}
+	return data, nil
+}
+
+// LoadDataBundle loads the installed bundle from cacheDir, re-verifying its signature
+func LoadDataBundle(cacheDir string, publicKey ed25519.PublicKey) (*DataBundle, error) {
+	data, err := os.ReadFile(filepath.Join(cacheDir, dataBundleFile))
+	if err != nil {
+		return nil, err
+	}
+	signature, err := os.ReadFile(filepath.Join(cacheDir, dataSignatureFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data bundle signature: %w", err)
+	}
+	return VerifyDataBundle(data, signature, publicKey)
+}
+
+// ResolveDataPublicKey returns the configured key, falling back to the build-time key
+func ResolveDataPublicKey(configured string) (ed25519.PublicKey, error) {
+	if configured == "" {
+		configured = DataPublicKey
+	}
+	if configured == "" {
+		return nil, fmt.Errorf("no data signing key configured (set data.update_public_key)")
+	}
+	return ParsePublicKey(configured)
+}
+
+// writeFileAtomic writes data to a temporary file and renames it into place
+func writeFileAtomic(path string, data []byte) error {
+	tmpFile := path + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	if err := os.Rename(tmpFile, path); err != nil {
+		os.Remove(tmpFile)
+		return fmt.Errorf("failed to rename %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// BundleProvider implements PricingProvider using a verified data bundle, falling back to defaults
+type BundleProvider struct {
+	bundle   *DataBundle
+	fallback *DefaultProvider
+}
+
+// NewBundleProvider creates a pricing provider backed by bundle
+func NewBundleProvider(bundle *DataBundle) *BundleProvider {
+	return &BundleProvider{
+		bundle:   bundle,
+		fallback: NewDefaultProvider(),
+	}
+}
+
+// GetPricing returns the pricing for a specific model
+func (p *BundleProvider) GetPricing(ctx context.Context, modelName string) (models.ModelPricing, error) {
+	if pricing, ok := p.bundle.Pricing[modelName]; ok {
+		return pricing.ModelPricing(), nil
+	}
+	if pricing, ok := p.bundle.Pricing[models.NormalizeModelName(modelName)]; ok {
+		return pricing.ModelPricing(), nil
+	}
+	return p.fallback.GetPricing(ctx, modelName)
+}
+
+// GetAllPricings returns all available model pricings
+func (p *BundleProvider) GetAllPricings(ctx context.Context) (map[string]models.ModelPricing, error) {
+	result, err := p.fallback.GetAllPricings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for name, pricing := range p.bundle.Pricing {
+		result[name] = pricing.ModelPricing()
+	}
+	return result, nil
+}
+
+// RefreshPricing is a no-op; bundles are refreshed with `claudecat update-data`
+func (p *BundleProvider) RefreshPricing(ctx context.Context) error {
+	return nil
+}
+
+// GetProviderName returns the provider name including the bundle version
+func (p *BundleProvider) GetProviderName() string {
+	return fmt.Sprintf("bundle-v%d", p.bundle.Version)
+}
@@ -0,0 +1,151 @@
+package pricing
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signedBundle(t *testing.T, priv ed25519.PrivateKey, version int) ([]byte, []byte) {
+	data, err := json.Marshal(DataBundle{
+		Version:   version,
+		Published: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Pricing: map[string]BundlePricing{
+			"claude-sonnet-4": {Input: 3, Output: 15, CacheCreation: 3.75, CacheRead: 0.3},
+		},
+		Limits: map[string]models.PlanLimits{
+			"pro": {TokenLimit: 19000, CostLimit: 18, MessageLimit: 250},
+		},
+	})
+	require.NoError(t, err)
+	sig := base64.StdEncoding.EncodeToString(ed25519.Sign(priv, data))
+	return data, []byte(sig)
+}
+
+func TestVerifyDataBundle(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	data, sig := signedBundle(t, priv, 3)
+
+	bundle, err := VerifyDataBundle(data, sig, pub)
+	require.NoError(t, err)
+	assert.Equal(t, 3, bundle.Version)
+	assert.Equal(t, 250, bundle.Limits["pro"].MessageLimit)
+
+	// Tampered data is rejected
+	tampered := append([]byte(nil), data...)
+	tampered[len(tampered)-2] = ' '
+	_, err = VerifyDataBundle(tampered, sig, pub)
+	assert.Error(t, err)
+
+	// A different key is rejected
+	otherPub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	_, err = VerifyDataBundle(data, sig, otherPub)
+	assert.Error(t, err)
+}
+
+func TestDataUpdater_FetchAndInstall(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	version := 2
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, sig := signedBundle(t, priv, version)
+		if r.URL.Path == "/data.json.sig" {
+			w.Write(sig)
+			return
+		}
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	updater := NewDataUpdater(server.URL+"/data.json", pub, dir)
+
+	bundle, data, sig, err := updater.Fetch(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, updater.Install(bundle, data, sig, false))
+
+	installed, err := LoadDataBundle(dir, pub)
+	require.NoError(t, err)
+	assert.Equal(t, 2, installed.Version)
+
+	// Same or older versions are not installed again unless forced
+	bundle, data, sig, err = updater.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Error(t, updater.Install(bundle, data, sig, false))
+	assert.NoError(t, updater.Install(bundle, data, sig, true))
+
+	provider := NewBundleProvider(installed)
+	pricing, err := provider.GetPricing(context.Background(), "claude-sonnet-4")
+	require.NoError(t, err)
+	assert.Equal(t, 3.0, pricing.Input)
+	assert.Equal(t, "bundle-v2", provider.GetProviderName())
+}
+
+func TestDataUpdater_InstallKeepsVerifiedBundleOnFailure(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	dir := t.TempDir()
+	updater := NewDataUpdater("", pub, dir)
+
+	install := func(version int) error {
+		data, sig := signedBundle(t, priv, version)
+		bundle, err := VerifyDataBundle(data, sig, pub)
+		require.NoError(t, err)
+		return updater.Install(bundle, data, sig, false)
+	}
+	require.NoError(t, install(1))
+
+	tests := []struct {
+		name    string
+		blocked string // Made a non-empty directory so writing or renaming there fails
+	}{
+		{"signature not staged", dataSignatureFile + ".tmp"},
+		{"bundle not staged", dataBundleFile + ".tmp"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocked := filepath.Join(dir, tt.blocked)
+			require.NoError(t, os.MkdirAll(filepath.Join(blocked, "x"), 0755))
+			defer os.RemoveAll(blocked)
+
+			assert.Error(t, install(2))
+			installed, err := LoadDataBundle(dir, pub)
+			require.NoError(t, err, "the previous bundle still verifies")
+			assert.Equal(t, 1, installed.Version)
+		})
+	}
+
+	require.NoError(t, install(2))
+	installed, err := LoadDataBundle(dir, pub)
+	require.NoError(t, err)
+	assert.Equal(t, 2, installed.Version)
+}
+
+func TestResolveDataPublicKey(t *testing.T) {
+	_, err := ResolveDataPublicKey("")
+	assert.Error(t, err)
+
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	parsed, err := ResolveDataPublicKey(base64.StdEncoding.EncodeToString(pub))
+	require.NoError(t, err)
+	assert.Equal(t, pub, parsed)
+
+	_, err = ResolveDataPublicKey("bm90LWEta2V5")
+	assert.Error(t, err)
+}
@@ -17,22 +17,35 @@ func NewDefaultProvider() *DefaultProvider {
 	return &DefaultProvider{
 		pricing: map[string]models.ModelPricing{
 			models.ModelOpus: {
-				Input:         15.00, // $15 per million tokens
-				Output:        75.00, // $75 per million tokens
-				CacheCreation: 18.75, // $18.75 per million tokens
-				CacheRead:     1.875, // $1.875 per million tokens
+				Input:           15.00, // $15 per million tokens
+				Output:          75.00, // $75 per million tokens
+				CacheCreation:   18.75, // $18.75 per million tokens
+				CacheCreation1h: 30.00, // $30 per million tokens
+				CacheRead:       1.875, // $1.875 per million tokens
 			},
 			models.ModelSonnet: {
-				Input:         3.00,  // $3 per million tokens
-				Output:        15.00, // $15 per million tokens
-				CacheCreation: 3.75,  // $3.75 per million tokens
-				CacheRead:     0.30,  // $0.30 per million tokens
+				Input:           3.00,  // $3 per million tokens
+				Output:          15.00, // $15 per million tokens
+				CacheCreation:   3.75,  // $3.75 per million tokens
+				CacheCreation1h: 6.00,  // $6 per million tokens
+				CacheRead:       0.30,  // $0.30 per million tokens
+			},
+			models.ModelSonnet4: {
+				Input:             3.00,  // $3 per million tokens
+				Output:            15.00, // $15 per million tokens
+				CacheCreation:     3.75,  // $3.75 per million tokens
+				CacheCreation1h:   6.00,  // $6 per million tokens
+				CacheRead:         0.30,  // $0.30 per million tokens
+				LongContextAbove:  200_000,
+				LongContextPrompt: 2,   // $6 per million input tokens above 200K
+				LongContextOutput: 1.5, // $22.50 per million output tokens above 200K
 			},
 			models.ModelHaiku: {
-				Input:         0.80, // $0.80 per million tokens
-				Output:        4.00, // $4 per million tokens
-				CacheCreation: 1.00, // $1 per million tokens
-				CacheRead:     0.08, // $0.08 per million tokens
+				Input:           0.80, // $0.80 per million tokens
+				Output:          4.00, // $4 per million tokens
+				CacheCreation:   1.00, // $1 per million tokens
+				CacheCreation1h: 1.60, // $1.60 per million tokens
+				CacheRead:       0.08, // $0.08 per million tokens
 			},
 		},
 	}
@@ -56,6 +69,9 @@ func (p *DefaultProvider) GetPricing(ctx context.Context, modelName string) (mod
 	if strings.Contains(modelLower, "opus") {
 		return p.pricing[models.ModelOpus], nil
 	}
+	if strings.Contains(modelLower, "sonnet-4") {
+		return p.pricing[models.ModelSonnet4], nil
+	}
 	if strings.Contains(modelLower, "sonnet") {
 		return p.pricing[models.ModelSonnet], nil
 	}
@@ -2,8 +2,10 @@ package pricing
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/logging"
 	"github.com/penwyp/claudecat/models"
 )
 
@@ -15,6 +17,10 @@ func CreatePricingProvider(cfg *config.DataConfig, cacheDir string) (models.Pric
 	switch cfg.PricingSource {
 	case "default", "":
 		baseProvider = NewDefaultProvider()
+		// Prefer pricing from a verified bundle installed by `claudecat update-data`
+		if bundle := LoadInstalledDataBundle(cfg, cacheDir); bundle != nil {
+			baseProvider = NewBundleProvider(bundle)
+		}
 	case "litellm":
 		baseProvider = NewLiteLLMProvider()
 	default:
@@ -33,3 +39,20 @@ func CreatePricingProvider(cfg *config.DataConfig, cacheDir string) (models.Pric
 
 	return baseProvider, nil
 }
+
+// LoadInstalledDataBundle returns the installed data bundle, or nil if none is installed or it fails verification
+func LoadInstalledDataBundle(cfg *config.DataConfig, cacheDir string) *DataBundle {
+	publicKey, err := ResolveDataPublicKey(cfg.UpdatePublicKey)
+	if err != nil {
+		return nil
+	}
+
+	bundle, err := LoadDataBundle(cacheDir, publicKey)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logging.LogWarnf("Ignoring installed data bundle: %v", err)
+		}
+		return nil
+	}
+	return bundle
+}
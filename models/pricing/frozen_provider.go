@@ -0,0 +1,116 @@
+package pricing
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+)
+
+// PricingSnapshot is a saved copy of the pricing tables of a provider
+type PricingSnapshot struct {
+	Provider string                   `json:"provider"`
+	TakenAt  time.Time                `json:"taken_at"`
+	Pricing  map[string]BundlePricing `json:"pricing"`
+}
+
+// Hash returns a short fingerprint of the pricing tables
+func (s *PricingSnapshot) Hash() string {
+	// Maps are marshaled with sorted keys
+	data, _ := json.Marshal(s.Pricing)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:6])
+}
+
+// FrozenProvider implements PricingProvider with the tables of a snapshot, so that costs do not
+// change when pricing is updated
+type FrozenProvider struct {
+	snapshot *PricingSnapshot
+	tables   *DefaultProvider
+}
+
+// NewFrozenProvider creates a pricing provider backed by snapshot
+func NewFrozenProvider(snapshot *PricingSnapshot) *FrozenProvider {
+	tables := &DefaultProvider{pricing: make(map[string]models.ModelPricing, len(snapshot.Pricing))}
+	for name, pricing := range snapshot.Pricing {
+		tables.pricing[name] = pricing.ModelPricing()
+	}
+	return &FrozenProvider{snapshot: snapshot, tables: tables}
+}
+
+// FreezePricing returns a provider with the pricing tables saved in path, first saving those of
+// provider there when path does not exist yet
+func FreezePricing(ctx context.Context, provider models.PricingProvider, path string, now time.Time) (*FrozenProvider, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		var snapshot PricingSnapshot
+		if err := json.Unmarshal(data, &snapshot); err != nil {
+			return nil, fmt.Errorf("failed to parse pricing snapshot %s: %w", filepath.Base(path), err)
+		}
+		return NewFrozenProvider(&snapshot), nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read pricing snapshot: %w", err)
+	}
+
+	all, err := provider.GetAllPricings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pricing: %w", err)
+	}
+	snapshot := &PricingSnapshot{Provider: provider.GetProviderName(), TakenAt: now, Pricing: make(map[string]BundlePricing, len(all))}
+	for name, pricing := range all {
+		snapshot.Pricing[name] = BundlePricing{
+			Input:           pricing.Input,
+			Output:          pricing.Output,
+			CacheCreation:   pricing.CacheCreation,
+			CacheCreation1h: pricing.CacheCreation1h,
+			CacheRead:       pricing.CacheRead,
+
+			LongContextAbove:  pricing.LongContextAbove,
+			LongContextPrompt: pricing.LongContextPrompt,
+			LongContextOutput: pricing.LongContextOutput,
+		}
+	}
+	data, err = json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create pricing snapshot directory: %w", err)
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		return nil, err
+	}
+	return NewFrozenProvider(snapshot), nil
+}
+
+// Snapshot returns the pricing tables of the provider
+func (p *FrozenProvider) Snapshot() *PricingSnapshot {
+	return p.snapshot
+}
+
+// GetPricing returns the pricing for a specific model
+func (p *FrozenProvider) GetPricing(ctx context.Context, modelName string) (models.ModelPricing, error) {
+	return p.tables.GetPricing(ctx, modelName)
+}
+
+// GetAllPricings returns all available model pricings
+func (p *FrozenProvider) GetAllPricings(ctx context.Context) (map[string]models.ModelPricing, error) {
+	return p.tables.GetAllPricings(ctx)
+}
+
+// RefreshPricing is a no-op; frozen tables never change
+func (p *FrozenProvider) RefreshPricing(ctx context.Context) error {
+	return nil
+}
+
+// GetProviderName returns the name of the provider the tables were taken from
+func (p *FrozenProvider) GetProviderName() string {
+	return p.snapshot.Provider + " (frozen)"
+}
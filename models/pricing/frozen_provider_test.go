@@ -0,0 +1,40 @@
+package pricing
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFreezePricing(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "pricing", "as-of.json")
+	takenAt := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	bundle := &DataBundle{Version: 3, Pricing: map[string]BundlePricing{
+		"claude-sonnet-4": {Input: 3, Output: 15, CacheCreation: 3.75, CacheRead: 0.3},
+	}}
+
+	frozen, err := FreezePricing(ctx, NewBundleProvider(bundle), path, takenAt)
+	require.NoError(t, err)
+	assert.Equal(t, "bundle-v3 (frozen)", frozen.GetProviderName())
+	hash := frozen.Snapshot().Hash()
+	assert.Len(t, hash, 12)
+
+	// Pricing updated later does not change the saved tables
+	bundle.Pricing["claude-sonnet-4"] = BundlePricing{Input: 6, Output: 30}
+	frozen, err = FreezePricing(ctx, NewBundleProvider(bundle), path, takenAt.AddDate(0, 1, 0))
+	require.NoError(t, err)
+	assert.Equal(t, hash, frozen.Snapshot().Hash())
+	assert.Equal(t, takenAt, frozen.Snapshot().TakenAt)
+	pricing, err := frozen.GetPricing(ctx, "claude-sonnet-4-20250514")
+	require.NoError(t, err)
+	assert.Equal(t, 3.0, pricing.Input)
+	pricing, err = frozen.GetPricing(ctx, models.ModelOpus)
+	require.NoError(t, err)
+	assert.Equal(t, 15.0, pricing.Input, "models missing from the bundle keep the default tables")
+}
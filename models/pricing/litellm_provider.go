@@ -14,7 +14,8 @@ import (
 )
 
 const (
-	liteLLMPricingURL = "https://raw.githubusercontent.com/BerriAI/litellm/main/model_prices_and_context_window.json"
+	// LiteLLMPricingURL is the model price table fetched by the litellm pricing source
+	LiteLLMPricingURL = "https://raw.githubusercontent.com/BerriAI/litellm/main/model_prices_and_context_window.json"
 	cacheExpiration   = 24 * time.Hour // Cache pricing data for 24 hours
 )
 
@@ -31,7 +32,10 @@ type liteLLMModel struct {
 	InputCostPerToken           *float64 `json:"input_cost_per_token"`
 	OutputCostPerToken          *float64 `json:"output_cost_per_token"`
 	CacheCreationInputTokenCost *float64 `json:"cache_creation_input_token_cost"`
+	CacheCreation1hTokenCost    *float64 `json:"cache_creation_input_token_cost_above_1hr"`
 	CacheReadInputTokenCost     *float64 `json:"cache_read_input_token_cost"`
+	InputCostAbove200k          *float64 `json:"input_cost_per_token_above_200k_tokens"`
+	OutputCostAbove200k         *float64 `json:"output_cost_per_token_above_200k_tokens"`
 }
 
 // NewLiteLLMProvider creates a new LiteLLM pricing provider
@@ -128,7 +132,7 @@ func (p *LiteLLMProvider) ensurePricingLoaded(ctx context.Context) error {
 
 // fetchPricing fetches the latest pricing data from LiteLLM
 func (p *LiteLLMProvider) fetchPricing(ctx context.Context) error {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, liteLLMPricingURL, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, LiteLLMPricingURL, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
@@ -181,6 +185,9 @@ func (p *LiteLLMProvider) fetchPricing(ctx context.Context) error {
 			// Default to 1.25x input cost if not specified
 			pricing.CacheCreation = pricing.Input * 1.25
 		}
+		if model.CacheCreation1hTokenCost != nil {
+			pricing.CacheCreation1h = *model.CacheCreation1hTokenCost * 1_000_000
+		}
 
 		if model.CacheReadInputTokenCost != nil {
 			pricing.CacheRead = *model.CacheReadInputTokenCost * 1_000_000
@@ -189,6 +196,13 @@ func (p *LiteLLMProvider) fetchPricing(ctx context.Context) error {
 			pricing.CacheRead = pricing.Input * 0.1
 		}
 
+		// Long-context rates, as multipliers of the base rates
+		if model.InputCostAbove200k != nil && model.OutputCostAbove200k != nil && pricing.Input > 0 && pricing.Output > 0 {
+			pricing.LongContextAbove = 200_000
+			pricing.LongContextPrompt = *model.InputCostAbove200k * 1_000_000 / pricing.Input
+			pricing.LongContextOutput = *model.OutputCostAbove200k * 1_000_000 / pricing.Output
+		}
+
 		newPricing[modelName] = pricing
 	}
 
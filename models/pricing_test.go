@@ -16,40 +16,44 @@ func TestGetPricing(t *testing.T) {
 			name:  "opus pricing",
 			model: ModelOpus,
 			want: ModelPricing{
-				Input:         15.00,
-				Output:        75.00,
-				CacheCreation: 18.75,
-				CacheRead:     1.875,
+				Input:           15.00,
+				Output:          75.00,
+				CacheCreation:   18.75,
+				CacheCreation1h: 30.00,
+				CacheRead:       1.875,
 			},
 		},
 		{
 			name:  "sonnet pricing",
 			model: ModelSonnet,
 			want: ModelPricing{
-				Input:         3.00,
-				Output:        15.00,
-				CacheCreation: 3.75,
-				CacheRead:     0.30,
+				Input:           3.00,
+				Output:          15.00,
+				CacheCreation:   3.75,
+				CacheCreation1h: 6.00,
+				CacheRead:       0.30,
 			},
 		},
 		{
 			name:  "haiku pricing",
 			model: ModelHaiku,
 			want: ModelPricing{
-				Input:         0.80,
-				Output:        4.00,
-				CacheCreation: 1.00,
-				CacheRead:     0.08,
+				Input:           0.80,
+				Output:          4.00,
+				CacheCreation:   1.00,
+				CacheCreation1h: 1.60,
+				CacheRead:       0.08,
 			},
 		},
 		{
 			name:  "unknown model defaults to sonnet",
 			model: "unknown-model",
 			want: ModelPricing{
-				Input:         3.00,
-				Output:        15.00,
-				CacheCreation: 3.75,
-				CacheRead:     0.30,
+				Input:           3.00,
+				Output:          15.00,
+				CacheCreation:   3.75,
+				CacheCreation1h: 6.00,
+				CacheRead:       0.30,
 			},
 		},
 	}
@@ -135,9 +139,10 @@ func TestGetAllPricings(t *testing.T) {
 	pricings := GetAllPricings()
 
 	// Check we have all expected models
-	assert.Len(t, pricings, 3)
+	assert.Len(t, pricings, 4)
 	assert.Contains(t, pricings, ModelOpus)
 	assert.Contains(t, pricings, ModelSonnet)
+	assert.Contains(t, pricings, ModelSonnet4)
 	assert.Contains(t, pricings, ModelHaiku)
 
 	// Verify it's a copy by modifying the returned map
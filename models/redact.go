@@ -0,0 +1,48 @@
+package models
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// redactedPrefix marks identifiers that have been replaced by a redaction token
+const redactedPrefix = "redacted-"
+
+// identifierPattern matches Claude message, request and tool IDs and session UUIDs
+var identifierPattern = regexp.MustCompile(`\b(?:msg|req|toolu)_[A-Za-z0-9]+\b|\b[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\b`)
+
+// RedactIDs replaces message, request and session IDs in s with stable redaction tokens.
+// The same ID always maps to the same token, so grouping by session still works after redaction.
+func RedactIDs(s string) string {
+	return identifierPattern.ReplaceAllStringFunc(s, func(id string) string {
+		sum := sha256.Sum256([]byte(id))
+		return fmt.Sprintf("%s%x", redactedPrefix, sum[:6])
+	})
+}
+
+// IsRedacted reports whether s contains a redaction token
+func IsRedacted(s string) bool {
+	return strings.Contains(s, redactedPrefix)
+}
+
+// RedactionCutoff returns the time before which IDs must be redacted, or zero if redaction is disabled
+func RedactionCutoff(afterDays int, now time.Time) time.Time {
+	if afterDays <= 0 {
+		return time.Time{}
+	}
+	return now.AddDate(0, 0, -afterDays)
+}
+
+// RedactEntryIDs returns entry with its message, request and session IDs, and the IDs in its project
+// and source file, replaced by redaction tokens. Token counts and costs are kept.
+func RedactEntryIDs(entry UsageEntry) UsageEntry {
+	entry.MessageID = RedactIDs(entry.MessageID)
+	entry.RequestID = RedactIDs(entry.RequestID)
+	entry.SessionID = RedactIDs(entry.SessionID)
+	entry.Project = RedactIDs(entry.Project)
+	entry.SourceFile = RedactIDs(entry.SourceFile)
+	return entry
+}
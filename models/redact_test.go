@@ -0,0 +1,50 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactIDs(t *testing.T) {
+	input := "msg_01ABCdef req_9XyZ session 0b6c3c1e-9a43-4a8e-b5d2-6f1f0e2d3c4b"
+	redacted := RedactIDs(input)
+
+	assert.NotContains(t, redacted, "msg_01ABCdef")
+	assert.NotContains(t, redacted, "req_9XyZ")
+	assert.NotContains(t, redacted, "0b6c3c1e")
+	assert.True(t, IsRedacted(redacted))
+
+	// Redaction is stable so grouping by ID still works
+	assert.Equal(t, redacted, RedactIDs(input))
+	assert.Equal(t, "claude-sonnet-4", RedactIDs("claude-sonnet-4"))
+}
+
+func TestRedactionCutoff(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	assert.True(t, RedactionCutoff(0, now).IsZero())
+	assert.Equal(t, time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC), RedactionCutoff(30, now))
+}
+
+func TestRedactEntryIDs(t *testing.T) {
+	session := "0b6c3c1e-9a43-4a8e-b5d2-6f1f0e2d3c4b"
+	entry := UsageEntry{
+		MessageID:  "msg_01ABCdef",
+		RequestID:  "req_9XyZ",
+		SessionID:  session,
+		Project:    "claudecat",
+		SourceFile: "/home/me/.claude/projects/claudecat/" + session + ".jsonl",
+		Model:      "claude-sonnet-4",
+		CostUSD:    1.5,
+	}
+	redacted := RedactEntryIDs(entry)
+
+	assert.Equal(t, RedactIDs(entry.MessageID), redacted.MessageID)
+	assert.Equal(t, RedactIDs(session), redacted.SessionID)
+	assert.True(t, IsRedacted(redacted.RequestID))
+	assert.NotContains(t, redacted.SourceFile, session)
+	assert.Equal(t, "claudecat", redacted.Project)
+	assert.Equal(t, entry.CostUSD, redacted.CostUSD)
+	assert.Equal(t, redacted, RedactEntryIDs(redacted), "redaction is idempotent")
+}
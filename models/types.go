@@ -18,18 +18,23 @@ const (
 
 // UsageEntry represents a single token usage event from Claude API
 type UsageEntry struct {
-	Timestamp           time.Time `json:"timestamp"`
-	Model               string    `json:"model"`
-	InputTokens         int       `json:"input_tokens"`
-	OutputTokens        int       `json:"output_tokens"`
-	CacheCreationTokens int       `json:"cache_creation_tokens"`
-	CacheReadTokens     int       `json:"cache_read_tokens"`
-	TotalTokens         int       `json:"total_tokens"` // Calculated field
-	CostUSD             float64   `json:"cost_usd"`     // Calculated field
-	MessageID           string    `json:"message_id"`
-	RequestID           string    `json:"request_id"`
-	SessionID           string    `json:"session_id"` // Claude Code session ID
-	Project             string    `json:"project"`     // Project name extracted from file path
+	Timestamp             time.Time `json:"timestamp"`
+	Model                 string    `json:"model"`
+	InputTokens           int       `json:"input_tokens"`
+	OutputTokens          int       `json:"output_tokens"`
+	CacheCreationTokens   int       `json:"cache_creation_tokens"`              // All cache writes, both tiers
+	CacheCreation1hTokens int       `json:"cache_creation_1h_tokens,omitempty"` // 1-hour tier share of CacheCreationTokens
+	CacheReadTokens       int       `json:"cache_read_tokens"`
+	TotalTokens           int       `json:"total_tokens"`              // Calculated field
+	CostUSD               float64   `json:"cost_usd"`                  // Calculated field
+	CachedCostUSD         float64   `json:"cached_cost_usd,omitempty"` // costUSD recorded in the log, if any
+	MessageID             string    `json:"message_id"`
+	RequestID             string    `json:"request_id"`
+	SessionID             string    `json:"session_id"`            // Claude Code session ID
+	Project               string    `json:"project"`               // Project name extracted from file path
+	ToolServer            string    `json:"tool_server,omitempty"` // Server of the first tool the message called: the MCP server name, or "built-in"
+	SourceFile            string    `json:"source_file,omitempty"` // Log file the entry was read from, when provenance is requested
+	SourceLine            int       `json:"source_line,omitempty"` // 1-based line number within SourceFile
 }
 
 // TokenCounts aggregates token counts with computed totals
@@ -109,12 +114,18 @@ func (u *UsageEntry) CalculateTotalTokens() int {
 func (u *UsageEntry) CalculateCost(pricing ModelPricing) float64 {
 	inputCost := float64(u.InputTokens) / 1_000_000 * pricing.Input
 	outputCost := float64(u.OutputTokens) / 1_000_000 * pricing.Output
-	cacheCreationCost := float64(u.CacheCreationTokens) / 1_000_000 * pricing.CacheCreation
+	cacheCreationCost := float64(u.CacheCreation5mTokens())/1_000_000*pricing.CacheCreation +
+		float64(u.CacheCreation1hTokens)/1_000_000*pricing.CacheCreation1hPrice()
 	cacheReadCost := float64(u.CacheReadTokens) / 1_000_000 * pricing.CacheRead
 
 	return inputCost + outputCost + cacheCreationCost + cacheReadCost
 }
 
+// CacheCreation5mTokens returns the cache writes billed at the 5-minute tier
+func (u *UsageEntry) CacheCreation5mTokens() int {
+	return u.CacheCreationTokens - u.CacheCreation1hTokens
+}
+
 // NormalizeModel normalizes the model name for the entry
 func (u *UsageEntry) NormalizeModel() {
 	u.Model = NormalizeModelName(u.Model)
@@ -243,18 +254,26 @@ func NormalizeModelName(model string) string {
 
 // AnalysisResult represents the result of data analysis operations
 type AnalysisResult struct {
-	Timestamp           time.Time `json:"timestamp"`
-	Model               string    `json:"model"`
-	SessionID           string    `json:"session_id"`
-	InputTokens         int       `json:"input_tokens"`
-	OutputTokens        int       `json:"output_tokens"`
-	CacheCreationTokens int       `json:"cache_creation_tokens"`
-	CacheReadTokens     int       `json:"cache_read_tokens"`
-	TotalTokens         int       `json:"total_tokens"`
-	CostUSD             float64   `json:"cost_usd"`
-	Count               int       `json:"count"`               // For grouped results
-	GroupKey            string    `json:"group_key,omitempty"` // For grouped results
-	Project             string    `json:"project"`              // Project name
+	Timestamp             time.Time  `json:"timestamp"`
+	Model                 string     `json:"model"`
+	SessionID             string     `json:"session_id"`
+	InputTokens           int        `json:"input_tokens"`
+	OutputTokens          int        `json:"output_tokens"`
+	CacheCreationTokens   int        `json:"cache_creation_tokens"`
+	CacheCreation1hTokens int        `json:"cache_creation_1h_tokens,omitempty"` // 1-hour tier share of CacheCreationTokens
+	CacheReadTokens       int        `json:"cache_read_tokens"`
+	TotalTokens           int        `json:"total_tokens"`
+	CostUSD               float64    `json:"cost_usd"`
+	Count                 int        `json:"count"`                        // For grouped results
+	GroupKey              string     `json:"group_key,omitempty"`          // For grouped results
+	Project               string     `json:"project"`                      // Project name
+	ToolServer            string     `json:"tool_server,omitempty"`        // Tool server the message called, if any
+	SessionConfidence     float64    `json:"session_confidence,omitempty"` // Session detection confidence (1.0 for pinned sessions)
+	Tags                  []string   `json:"tags,omitempty"`               // Labels of the session, from claudecat tag
+	SourceFile            string     `json:"source_file,omitempty"`        // Log file of the entry, when provenance is requested
+	SourceLine            int        `json:"source_line,omitempty"`        // 1-based line number within SourceFile
+	BlockEnd              *time.Time `json:"block_end,omitempty"`          // End of the session block, for results grouped by block
+	BlockActive           bool       `json:"block_active,omitempty"`       // Whether the session block is still in progress
 }
 
 // SummaryStats represents summary statistics for analysis results
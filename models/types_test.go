@@ -105,6 +105,26 @@ func TestUsageEntry_CalculateCost(t *testing.T) {
 			pricing: GetPricing(ModelHaiku),
 			want:    0.00008 + 0.0002, // Very small costs
 		},
+		{
+			name: "sonnet pricing with 5m and 1h cache writes",
+			entry: UsageEntry{
+				Model:                 ModelSonnet,
+				CacheCreationTokens:   1_000_000,
+				CacheCreation1hTokens: 400_000,
+			},
+			pricing: GetPricing(ModelSonnet),
+			want:    2.25 + 2.4, // 600k at $3.75 + 400k at $6
+		},
+		{
+			name: "1h cache writes default to twice the input price",
+			entry: UsageEntry{
+				Model:                 ModelSonnet,
+				CacheCreationTokens:   1_000_000,
+				CacheCreation1hTokens: 1_000_000,
+			},
+			pricing: ModelPricing{Input: 3.0, CacheCreation: 3.75},
+			want:    6.0,
+		},
 	}
 
 	for _, tt := range tests {
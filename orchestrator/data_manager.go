@@ -6,8 +6,10 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/penwyp/claudecat/clock"
 	"github.com/penwyp/claudecat/config"
 	"github.com/penwyp/claudecat/fileio"
 	"github.com/penwyp/claudecat/logging"
@@ -43,16 +45,36 @@ type DataManager struct {
 
 	// Initial load tracking
 	initialLoadCompleted bool
+	loadProgress         fileio.ProgressSnapshot
+	loadProgressDecile   int
+	loadProgressMu       sync.RWMutex
 
 	// Pricing and deduplication
 	pricingProvider     models.PricingProvider
+	costModel           models.CostModel // Nil bills list prices
 	enableDeduplication bool
+	maxLineSize         int
+
+	// data.exclude patterns, and whether conversations logged in several project directories are merged
+	exclude         []string
+	mergeDuplicates bool
+
+	// Known session starts from the session overrides file
+	pinnedStarts []time.Time
 
 	// Session window tracking
 	activeSessionFiles map[string]*FileTracker
 	fileTrackerMutex   sync.RWMutex
-	cacheUpdateTicker  *time.Ticker
+	cacheUpdateTicker  clock.Ticker
 	cacheUpdateStop    chan struct{}
+	cacheWarmingPaused atomic.Bool // Skip the periodic session window cache updates
+
+	// Files written within the last minute, refreshed on every load
+	activity    *fileio.ActivityTracker
+	activeFiles []fileio.FileActivity
+
+	// Clock of cache timestamps, retry backoff, session windows and the cache updater
+	clock clock.Clock
 }
 
 // NewDataManager creates a new data manager with cache and fetch settings
@@ -61,9 +83,23 @@ func NewDataManager(hoursBack int, dataPath string) *DataManager {
 		hoursBack:          hoursBack,
 		dataPath:           dataPath,
 		activeSessionFiles: make(map[string]*FileTracker),
+		activity:           fileio.NewActivityTracker(fileio.ActivityWindow),
+		clock:              clock.Real,
 	}
 }
 
+// SetClock sets the clock of the data manager; call it before Start
+func (dm *DataManager) SetClock(c clock.Clock) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	dm.clock = clock.OrReal(c)
+}
+
+// SetCacheWarming enables or pauses the periodic cache updates of files in the session window
+func (dm *DataManager) SetCacheWarming(enabled bool) {
+	dm.cacheWarmingPaused.Store(!enabled)
+}
+
 // SetCacheStore sets the cache store for file summaries
 func (dm *DataManager) SetCacheStore(cacheStore fileio.CacheStore, config config.SummaryCacheConfig) {
 	dm.mu.Lock()
@@ -79,6 +115,13 @@ func (dm *DataManager) SetPricingProvider(provider models.PricingProvider) {
 	dm.pricingProvider = provider
 }
 
+// SetCostModel sets the billing arrangement the entries are costed under; nil bills list prices
+func (dm *DataManager) SetCostModel(model models.CostModel) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	dm.costModel = model
+}
+
 // SetDeduplication sets whether to enable deduplication
 func (dm *DataManager) SetDeduplication(enabled bool) {
 	dm.mu.Lock()
@@ -86,6 +129,37 @@ func (dm *DataManager) SetDeduplication(enabled bool) {
 	dm.enableDeduplication = enabled
 }
 
+// SetMaxLineSize sets the maximum line size buffered when parsing usage files
+func (dm *DataManager) SetMaxLineSize(size int) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	dm.maxLineSize = size
+}
+
+// SetFileFilters sets the directories skipped and whether only the largest copy of a conversation logged
+// in several project directories is loaded
+func (dm *DataManager) SetFileFilters(exclude []string, mergeDuplicates bool) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	dm.exclude = exclude
+	dm.mergeDuplicates = mergeDuplicates
+}
+
+// SetPinnedStarts sets known session starts that begin a session block, see data.session_overrides
+func (dm *DataManager) SetPinnedStarts(starts []time.Time) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	dm.pinnedStarts = starts
+}
+
+// skippedFiles returns the redundant copies of conversations to skip when merging duplicates
+func (dm *DataManager) skippedFiles() map[string]bool {
+	if !dm.mergeDuplicates {
+		return nil
+	}
+	return fileio.ScanDuplicateProjects([]string{dm.dataPath}, dm.exclude).Redundant
+}
+
 // Start starts the DataManager background tasks
 func (dm *DataManager) Start(ctx context.Context) {
 	dm.startCacheUpdater(ctx)
@@ -131,7 +205,7 @@ func (dm *DataManager) GetData(forceRefresh bool) (*AnalysisResult, error) {
 			if attempt < maxRetries-1 {
 				// Exponential backoff
 				backoff := time.Duration(100*(1<<attempt)) * time.Millisecond
-				time.Sleep(backoff)
+				dm.clock.Sleep(backoff)
 				continue
 			}
 
@@ -151,8 +225,8 @@ func (dm *DataManager) GetData(forceRefresh bool) (*AnalysisResult, error) {
 		// Success - update cache
 		dm.mu.Lock()
 		dm.cache = data
-		dm.cacheTimestamp = time.Now()
-		dm.lastSuccessfulFetch = time.Now()
+		dm.cacheTimestamp = dm.clock.Now()
+		dm.lastSuccessfulFetch = dm.cacheTimestamp
 		dm.lastError = nil
 		dm.mu.Unlock()
 
@@ -162,6 +236,34 @@ func (dm *DataManager) GetData(forceRefresh bool) (*AnalysisResult, error) {
 	return nil, fmt.Errorf("unexpected error in data fetching loop")
 }
 
+// recordLoadProgress stores initial load progress and logs every 10% of files warmed
+func (dm *DataManager) recordLoadProgress(progress fileio.ProgressSnapshot) {
+	dm.loadProgressMu.Lock()
+	dm.loadProgress = progress
+	decile := int(progress.Percent()) / 10
+	shouldLog := decile > dm.loadProgressDecile || progress.ProcessedFiles == 1
+	if decile > dm.loadProgressDecile {
+		dm.loadProgressDecile = decile
+	}
+	if progress.Done() {
+		dm.loadProgressDecile = 0
+	}
+	dm.loadProgressMu.Unlock()
+
+	if shouldLog {
+		logging.LogInfof("Cache warm-up: %d/%d files (%.0f%%), %d remaining, ETA %s",
+			progress.ProcessedFiles, progress.TotalFiles, progress.Percent(),
+			progress.Remaining(), progress.ETA.Round(time.Second))
+	}
+}
+
+// GetLoadProgress returns the progress of the most recent initial load
+func (dm *DataManager) GetLoadProgress() fileio.ProgressSnapshot {
+	dm.loadProgressMu.RLock()
+	defer dm.loadProgressMu.RUnlock()
+	return dm.loadProgress
+}
+
 // InvalidateCache invalidates the cache
 func (dm *DataManager) InvalidateCache() {
 	dm.mu.Lock()
@@ -179,7 +281,7 @@ func (dm *DataManager) GetCacheAge() float64 {
 		return -1 // No cache
 	}
 
-	return time.Since(dm.cacheTimestamp).Seconds()
+	return dm.clock.Since(dm.cacheTimestamp).Seconds()
 }
 
 // GetLastError returns the last error encountered
@@ -213,6 +315,11 @@ func (dm *DataManager) performInitialLoad() (*AnalysisResult, error) {
 			CacheStore:          dm.cacheStore,
 			EnableDeduplication: dm.enableDeduplication,
 			PricingProvider:     dm.pricingProvider,
+			CostModel:           dm.costModel,
+			MaxLineSize:         dm.maxLineSize,
+			Progress:            dm.recordLoadProgress,
+			Exclude:             dm.exclude,
+			SkipFiles:           dm.skippedFiles(),
 		}
 
 		resultCache, err := fileio.LoadUsageEntries(optsCache)
@@ -237,8 +344,8 @@ func (dm *DataManager) performInitialLoad() (*AnalysisResult, error) {
 				dm.mu.Lock()
 				dm.initialLoadCompleted = true
 				dm.cache = data
-				dm.cacheTimestamp = time.Now()
-				dm.lastSuccessfulFetch = time.Now()
+				dm.cacheTimestamp = dm.clock.Now()
+				dm.lastSuccessfulFetch = dm.cacheTimestamp
 				dm.lastError = nil
 				dm.mu.Unlock()
 
@@ -260,6 +367,11 @@ func (dm *DataManager) performInitialLoad() (*AnalysisResult, error) {
 		IncludeRaw:          true,
 		EnableDeduplication: dm.enableDeduplication,
 		PricingProvider:     dm.pricingProvider,
+		CostModel:           dm.costModel,
+		MaxLineSize:         dm.maxLineSize,
+		Progress:            dm.recordLoadProgress,
+		Exclude:             dm.exclude,
+		SkipFiles:           dm.skippedFiles(),
 	}
 
 	// Set cache store if available
@@ -282,8 +394,8 @@ func (dm *DataManager) performInitialLoad() (*AnalysisResult, error) {
 	dm.mu.Lock()
 	dm.initialLoadCompleted = true
 	dm.cache = data
-	dm.cacheTimestamp = time.Now()
-	dm.lastSuccessfulFetch = time.Now()
+	dm.cacheTimestamp = dm.clock.Now()
+	dm.lastSuccessfulFetch = dm.cacheTimestamp
 	dm.lastError = nil
 	dm.mu.Unlock()
 
@@ -301,6 +413,10 @@ func (dm *DataManager) analyzeUsageWatchMode() (*AnalysisResult, error) {
 		IncludeRaw:          true,
 		EnableDeduplication: dm.enableDeduplication,
 		PricingProvider:     dm.pricingProvider,
+		CostModel:           dm.costModel,
+		MaxLineSize:         dm.maxLineSize,
+		Exclude:             dm.exclude,
+		SkipFiles:           dm.skippedFiles(),
 	}
 
 	// Set cache store if available
@@ -331,6 +447,8 @@ func (dm *DataManager) processUsageData(result *fileio.LoadUsageEntriesResult, m
 	// Transform entries to blocks using SessionAnalyzer
 	transformStart := time.Now()
 	analyzer := sessions.NewSessionAnalyzer(5) // 5-hour sessions
+	analyzer.SetClock(dm.clock)
+	analyzer.SetPinnedStarts(dm.pinnedStarts)
 	blocks := analyzer.TransformToBlocks(result.Entries)
 	transformTime := time.Since(transformStart)
 	logging.LogInfof("Created %d blocks in %.3fs (%s mode)", len(blocks), transformTime.Seconds(), mode)
@@ -363,7 +481,7 @@ func (dm *DataManager) processUsageData(result *fileio.LoadUsageEntriesResult, m
 
 	// Create metadata
 	metadata := AnalysisMetadata{
-		GeneratedAt:          time.Now(),
+		GeneratedAt:          dm.clock.Now(),
 		HoursAnalyzed:        fmt.Sprintf("%d", dm.hoursBack),
 		EntriesProcessed:     len(result.Entries),
 		BlocksCreated:        len(blocks),
@@ -379,8 +497,14 @@ func (dm *DataManager) processUsageData(result *fileio.LoadUsageEntriesResult, m
 		Metadata: metadata,
 	}
 
-	// Update session window files based on the blocks
-	dm.updateSessionWindowFiles(blocks)
+	// Update session window files and recent file activity
+	files, err := fileio.DiscoverFiles(dm.dataPath)
+	if err != nil {
+		logging.LogErrorf("Failed to discover files: %v", err)
+	} else {
+		dm.updateSessionWindowFiles(blocks, files)
+		dm.recordFileActivity(files, result.Entries)
+	}
 
 	logging.LogInfof("Analysis completed, returning %d blocks (%s mode)", len(blocks), mode)
 	return analysisResult, nil
@@ -441,10 +565,10 @@ func (dm *DataManager) isLimitInBlockTimerange(limit models.LimitMessage, block
 }
 
 // updateSessionWindowFiles updates the list of files that are in the active session window
-func (dm *DataManager) updateSessionWindowFiles(blocks []models.SessionBlock) {
+func (dm *DataManager) updateSessionWindowFiles(blocks []models.SessionBlock, files []string) {
 	// Find active session blocks
 	var activeBlocks []models.SessionBlock
-	now := time.Now()
+	now := dm.clock.Now()
 
 	for _, block := range blocks {
 		// Consider blocks active if they are marked as active or ended within the last 5 hours
@@ -462,12 +586,6 @@ func (dm *DataManager) updateSessionWindowFiles(blocks []models.SessionBlock) {
 	}
 
 	// Scan all JSONL files
-	files, err := fileio.DiscoverFiles(dm.dataPath)
-	if err != nil {
-		logging.LogErrorf("Failed to discover files: %v", err)
-		return
-	}
-
 	for _, file := range files {
 		info, err := os.Stat(file)
 		if err != nil {
@@ -496,6 +614,22 @@ func (dm *DataManager) updateSessionWindowFiles(blocks []models.SessionBlock) {
 	logging.LogDebugf("Session window files updated: %d files in active window", dm.countActiveWindowFiles())
 }
 
+// recordFileActivity updates the list of files that received writes recently
+func (dm *DataManager) recordFileActivity(files []string, entries []models.UsageEntry) {
+	active := dm.activity.Observe(files, entries, dm.clock.Now())
+
+	dm.fileTrackerMutex.Lock()
+	dm.activeFiles = active
+	dm.fileTrackerMutex.Unlock()
+}
+
+// GetActiveFiles returns the files written within the last minute, most recent first
+func (dm *DataManager) GetActiveFiles() []fileio.FileActivity {
+	dm.fileTrackerMutex.RLock()
+	defer dm.fileTrackerMutex.RUnlock()
+	return dm.activeFiles
+}
+
 // countActiveWindowFiles returns the number of files in the active session window
 func (dm *DataManager) countActiveWindowFiles() int {
 	count := 0
@@ -513,9 +647,10 @@ func (dm *DataManager) startCacheUpdater(ctx context.Context) {
 		return // Already running
 	}
 
-	dm.cacheUpdateTicker = time.NewTicker(1 * time.Minute)
+	dm.cacheUpdateTicker = dm.clock.NewTicker(1 * time.Minute)
 	dm.cacheUpdateStop = make(chan struct{})
 
+	ticks, stop := dm.cacheUpdateTicker.C(), dm.cacheUpdateStop
 	go func() {
 		logging.LogInfo("Cache updater started")
 		for {
@@ -523,10 +658,10 @@ func (dm *DataManager) startCacheUpdater(ctx context.Context) {
 			case <-ctx.Done():
 				logging.LogInfo("Cache updater stopped (context cancelled)")
 				return
-			case <-dm.cacheUpdateStop:
+			case <-stop:
 				logging.LogInfo("Cache updater stopped")
 				return
-			case <-dm.cacheUpdateTicker.C:
+			case <-ticks:
 				dm.updateSessionWindowCaches()
 			}
 		}
@@ -547,12 +682,16 @@ func (dm *DataManager) stopCacheUpdater() {
 
 // updateSessionWindowCaches updates caches for files in the session window
 func (dm *DataManager) updateSessionWindowCaches() {
+	if dm.cacheWarmingPaused.Load() {
+		return
+	}
+
 	dm.fileTrackerMutex.RLock()
 	filesToUpdate := make([]string, 0)
 
 	for path, tracker := range dm.activeSessionFiles {
 		// Update cache if file is in session window and hasn't been updated recently
-		if tracker.InSessionWindow && time.Since(tracker.LastCacheUpdate) > 1*time.Minute {
+		if tracker.InSessionWindow && dm.clock.Since(tracker.LastCacheUpdate) > 1*time.Minute {
 			filesToUpdate = append(filesToUpdate, path)
 		}
 	}
@@ -586,6 +725,7 @@ func (dm *DataManager) updateFileCache(filePath string) error {
 		CacheStore:          dm.cacheStore,
 		EnableDeduplication: dm.enableDeduplication,
 		PricingProvider:     dm.pricingProvider,
+		MaxLineSize:         dm.maxLineSize,
 	}
 
 	// This will automatically update the cache since we removed IsWatchMode
@@ -597,7 +737,7 @@ func (dm *DataManager) updateFileCache(filePath string) error {
 	// Update tracker
 	dm.fileTrackerMutex.Lock()
 	if tracker, exists := dm.activeSessionFiles[filePath]; exists {
-		tracker.LastCacheUpdate = time.Now()
+		tracker.LastCacheUpdate = dm.clock.Now()
 		tracker.LastModTime = info.ModTime()
 	}
 	dm.fileTrackerMutex.Unlock()
@@ -3,25 +3,38 @@ package orchestrator
 import (
 	"context"
 	"fmt"
-	"os"
-	"path/filepath"
 	"sync"
 	"time"
 
 	"github.com/penwyp/claudecat/cache"
+	"github.com/penwyp/claudecat/calculations"
+	"github.com/penwyp/claudecat/clock"
 	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/events"
+	"github.com/penwyp/claudecat/fileio"
 	"github.com/penwyp/claudecat/logging"
 	"github.com/penwyp/claudecat/models"
 	"github.com/penwyp/claudecat/models/pricing"
+	"github.com/penwyp/claudecat/sessions"
 )
 
-// MonitoringData represents the data structure passed to callbacks
+// MonitoringData is published on the event bus after every successful data refresh
 type MonitoringData struct {
 	Data         AnalysisResult `json:"data"`
 	TokenLimit   int            `json:"token_limit"`
 	Args         interface{}    `json:"args,omitempty"`
 	SessionID    string         `json:"session_id"`
 	SessionCount int            `json:"session_count"`
+
+	// Realtime metrics of the active session, nil when no session is active
+	Metrics *calculations.RealtimeMetrics `json:"metrics,omitempty"`
+}
+
+// DayRollover is published on the event bus when local midnight passes, before the data is refreshed
+// so that daily counters and budget windows can be reset for the new day
+type DayRollover struct {
+	Previous time.Time // Midnight of the day that ended
+	Day      time.Time // Midnight of the new day
 }
 
 // AnalysisResult represents the processed analysis data
@@ -43,12 +56,6 @@ type AnalysisMetadata struct {
 	QuickStart           bool      `json:"quick_start"`
 }
 
-// DataUpdateCallback represents a callback function for data updates
-type DataUpdateCallback func(MonitoringData)
-
-// SessionChangeCallback represents a callback function for session changes
-type SessionChangeCallback func(eventType, sessionID string, sessionData interface{})
-
 // MonitoringOrchestrator orchestrates monitoring components following SRP
 type MonitoringOrchestrator struct {
 	updateInterval time.Duration
@@ -65,14 +72,33 @@ type MonitoringOrchestrator struct {
 	stopEvent     context.Context
 	stopCancel    context.CancelFunc
 
-	// Callbacks
-	updateCallbacks  []DataUpdateCallback
-	sessionCallbacks []SessionChangeCallback
+	// Event bus carrying MonitoringData, SessionChanged and Notice events
+	bus *events.Bus
+
+	// Whether the last fetch failed, so offline/online notices are raised once per transition
+	sourceOffline bool
+
+	// Local day, used to publish DayRollover at midnight
+	dayClock *calculations.DayClock
+	location *time.Location
+
+	// Clock driving the refresh ticker, the midnight timer and session windows
+	clock clock.Clock
+
+	// Refresh ticker of the running monitoring loop, reset when the update interval changes
+	ticker clock.Ticker
 
 	// Data tracking
 	lastValidData  *MonitoringData
 	firstDataEvent chan struct{}
 
+	// Realtime metrics of the active session, fed the entries added to it since the last refresh
+	metrics      *calculations.MetricsCalculator
+	metricsMu    sync.Mutex // Serializes feeding, as a forced refresh can overlap the monitoring loop
+	metricsStart time.Time  // Start of the block fed to metrics
+	metricsFed   int        // Entries of that block fed so far
+	metricsLast  time.Time  // Timestamp of the last entry fed
+
 	// Args from CLI
 	args interface{}
 
@@ -87,18 +113,17 @@ func NewMonitoringOrchestrator(updateInterval time.Duration, dataPath string, cf
 	dataManager := NewDataManager(192, dataPath) // 192 hours back
 
 	// Expand cache directory path for use in both cache and pricing
-	cacheDir := cfg.Cache.Dir
-	if cacheDir != "" && cacheDir[:2] == "~/" {
-		homeDir, _ := os.UserHomeDir()
-		cacheDir = filepath.Join(homeDir, cacheDir[2:])
-	}
+	cacheDir := config.ExpandHome(cfg.Cache.Dir)
 
 	// Set up cache if enabled
-	fileCache, err := cache.NewFileBasedSummaryCache(cacheDir)
+	fileCache, err := cache.OpenFileBasedSummaryCache(cacheDir, cfg.Cache.Encryption)
 	if err != nil {
 		logging.LogErrorf("Failed to create file-based cache: %v", err)
 		// Cache is disabled on error
 	} else {
+		fileCache.SetTrashTTL(cfg.Cache.TrashTTL)
+		fileCache.SetRedactAfterDays(cfg.Retention.RedactIDsAfterDays)
+		fileCache.SetMaxDiskSize(cfg.Cache.MaxDiskSize)
 		dataManager.SetCacheStore(fileCache, cfg.Data.SummaryCache)
 	}
 
@@ -110,22 +135,45 @@ func NewMonitoringOrchestrator(updateInterval time.Duration, dataPath string, cf
 		pricingProvider = pricing.NewDefaultProvider()
 	}
 	dataManager.SetPricingProvider(pricingProvider)
+	costModel, err := pricing.CostModelForPath(&cfg.Data, dataPath)
+	if err != nil {
+		logging.LogWarnf("Costing %s at list prices: %v", dataPath, err)
+	}
+	dataManager.SetCostModel(costModel)
 
-	// Set deduplication flag
+	// Set deduplication flag and line size limit
 	dataManager.SetDeduplication(cfg.Data.Deduplication)
+	dataManager.SetMaxLineSize(cfg.Data.MaxLineSize)
+	dataManager.SetFileFilters(cfg.Data.Exclude, cfg.Data.DuplicateProjects == "merge")
+	if path := cfg.Data.SessionOverrides; path != "" {
+		pinned, err := sessions.LoadPinnedStarts(config.ExpandHome(path))
+		if err != nil {
+			logging.LogWarnf("Ignoring session overrides: %v", err)
+		}
+		dataManager.SetPinnedStarts(pinned)
+	}
+
+	loc, err := time.LoadLocation(cfg.App.Timezone)
+	if err != nil {
+		loc = time.Local
+	}
 
+	bus := events.NewBus()
 	return &MonitoringOrchestrator{
-		updateInterval:   updateInterval,
-		dataPath:         dataPath,
-		config:           cfg,
-		dataManager:      dataManager,
-		sessionMonitor:   NewSessionMonitor(),
-		monitoring:       false,
-		stopEvent:        ctx,
-		stopCancel:       cancel,
-		updateCallbacks:  make([]DataUpdateCallback, 0),
-		sessionCallbacks: make([]SessionChangeCallback, 0),
-		firstDataEvent:   make(chan struct{}, 1),
+		dayClock:       calculations.NewDayClock(loc, clock.Real.Now()),
+		location:       loc,
+		clock:          clock.Real,
+		updateInterval: updateInterval,
+		dataPath:       dataPath,
+		config:         cfg,
+		dataManager:    dataManager,
+		sessionMonitor: NewSessionMonitor(bus),
+		monitoring:     false,
+		stopEvent:      ctx,
+		stopCancel:     cancel,
+		bus:            bus,
+		firstDataEvent: make(chan struct{}, 1),
+		metrics:        calculations.NewMetricsCalculator(time.Time{}, cfg),
 	}
 }
 
@@ -161,9 +209,8 @@ func (mo *MonitoringOrchestrator) Start() error {
 // Stop stops monitoring
 func (mo *MonitoringOrchestrator) Stop() {
 	mo.mu.Lock()
-	defer mo.mu.Unlock()
-
 	if !mo.monitoring {
+		mo.mu.Unlock()
 		return
 	}
 
@@ -173,15 +220,18 @@ func (mo *MonitoringOrchestrator) Stop() {
 	// Stop DataManager background tasks
 	mo.dataManager.Stop()
 
-	// Wait for goroutine to finish with timeout
-	if mo.monitorThread != nil {
+	monitorThread := mo.monitorThread
+	mo.monitorThread = nil
+	mo.mu.Unlock()
+
+	// Wait for goroutine to finish with timeout; unlocked since a fetch in progress takes the lock
+	if monitorThread != nil {
 		select {
-		case <-mo.monitorThread.Done():
+		case <-monitorThread.Done():
 			// Goroutine finished
 		case <-time.After(5 * time.Second):
 			// Timeout waiting
 		}
-		mo.monitorThread = nil
 	}
 
 	// Clear first data event
@@ -191,25 +241,45 @@ func (mo *MonitoringOrchestrator) Stop() {
 	}
 }
 
-// SetArgs sets command line arguments for token limit calculation
-func (mo *MonitoringOrchestrator) SetArgs(args interface{}) {
+// SetClock sets the clock of the monitoring loop and its components; call it before Start
+func (mo *MonitoringOrchestrator) SetClock(c clock.Clock) {
+	c = clock.OrReal(c)
 	mo.mu.Lock()
-	defer mo.mu.Unlock()
-	mo.args = args
+	mo.clock = c
+	mo.dayClock = calculations.NewDayClock(mo.location, c.Now())
+	mo.mu.Unlock()
+	mo.dataManager.SetClock(c)
+	mo.sessionMonitor.SetClock(c)
 }
 
-// RegisterUpdateCallback registers a callback for data updates
-func (mo *MonitoringOrchestrator) RegisterUpdateCallback(callback DataUpdateCallback) {
+// SetUpdateInterval changes how often data is refreshed, taking effect right away when monitoring
+func (mo *MonitoringOrchestrator) SetUpdateInterval(interval time.Duration) {
 	mo.mu.Lock()
 	defer mo.mu.Unlock()
-	mo.updateCallbacks = append(mo.updateCallbacks, callback)
+	if interval <= 0 || interval == mo.updateInterval {
+		return
+	}
+	mo.updateInterval = interval
+	if mo.ticker != nil {
+		mo.ticker.Reset(interval)
+	}
+}
+
+// SetCacheWarming enables or pauses the background cache updates of files in the session window
+func (mo *MonitoringOrchestrator) SetCacheWarming(enabled bool) {
+	mo.dataManager.SetCacheWarming(enabled)
 }
 
-// RegisterSessionCallback registers a callback for session changes
-func (mo *MonitoringOrchestrator) RegisterSessionCallback(callback SessionChangeCallback) {
+// SetArgs sets command line arguments for token limit calculation
+func (mo *MonitoringOrchestrator) SetArgs(args interface{}) {
 	mo.mu.Lock()
 	defer mo.mu.Unlock()
-	mo.sessionCallbacks = append(mo.sessionCallbacks, callback)
+	mo.args = args
+}
+
+// Bus returns the event bus on which data updates and session changes are published
+func (mo *MonitoringOrchestrator) Bus() *events.Bus {
+	return mo.bus
 }
 
 // ForceRefresh forces immediate data refresh
@@ -217,6 +287,30 @@ func (mo *MonitoringOrchestrator) ForceRefresh() (*MonitoringData, error) {
 	return mo.fetchAndProcessData(true)
 }
 
+// GetLoadProgress returns cache warm-up progress of the initial data load
+func (mo *MonitoringOrchestrator) GetLoadProgress() fileio.ProgressSnapshot {
+	return mo.dataManager.GetLoadProgress()
+}
+
+// GetActiveFiles returns the usage files written within the last minute
+func (mo *MonitoringOrchestrator) GetActiveFiles() []fileio.FileActivity {
+	return mo.dataManager.GetActiveFiles()
+}
+
+// Today returns midnight of the current local day
+func (mo *MonitoringOrchestrator) Today() time.Time {
+	mo.mu.RLock()
+	defer mo.mu.RUnlock()
+	return mo.dayClock.Day()
+}
+
+// nextRollover returns the next local midnight
+func (mo *MonitoringOrchestrator) nextRollover() time.Time {
+	mo.mu.RLock()
+	defer mo.mu.RUnlock()
+	return mo.dayClock.NextRollover()
+}
+
 // WaitForInitialData waits for initial data to be fetched
 func (mo *MonitoringOrchestrator) WaitForInitialData(timeout time.Duration) bool {
 	select {
@@ -234,14 +328,33 @@ func (mo *MonitoringOrchestrator) monitoringLoop() {
 		logging.LogErrorf("Initial data fetch failed: %v", err)
 	}
 
-	ticker := time.NewTicker(mo.updateInterval)
-	defer ticker.Stop()
+	mo.mu.Lock()
+	clk := mo.clock
+	ticker := clk.NewTicker(mo.updateInterval)
+	mo.ticker = ticker
+	mo.mu.Unlock()
+	defer func() {
+		mo.mu.Lock()
+		ticker.Stop()
+		mo.ticker = nil
+		mo.mu.Unlock()
+	}()
+
+	// Fire at midnight; the ticker also checks the day since timers do not advance while the system is suspended
+	midnight := clk.NewTimer(clk.Until(mo.nextRollover()))
+	defer midnight.Stop()
 
 	for {
 		select {
 		case <-mo.stopEvent.Done():
 			return
-		case <-ticker.C:
+		case <-midnight.C():
+			mo.checkDayRollover(clk.Now())
+			midnight.Reset(clk.Until(mo.nextRollover()))
+		case <-ticker.C():
+			if mo.checkDayRollover(clk.Now()) {
+				continue
+			}
 			if _, err := mo.fetchAndProcessData(false); err != nil {
 				logging.LogErrorf("Periodic data fetch failed: %v", err)
 			}
@@ -249,13 +362,36 @@ func (mo *MonitoringOrchestrator) monitoringLoop() {
 	}
 }
 
-// fetchAndProcessData fetches data and notifies callbacks
+// checkDayRollover publishes DayRollover and refreshes the data when now is on a new local day
+func (mo *MonitoringOrchestrator) checkDayRollover(now time.Time) bool {
+	mo.mu.Lock()
+	previous, rolled := mo.dayClock.Advance(now)
+	day := mo.dayClock.Day()
+	mo.mu.Unlock()
+	if !rolled {
+		return false
+	}
+
+	logging.LogInfof("Day rolled over from %s to %s", previous.Format("2006-01-02"), day.Format("2006-01-02"))
+	events.Publish(mo.bus, DayRollover{Previous: previous, Day: day})
+	events.Publish(mo.bus, events.Notice{Level: events.NoticeInfo, Message: fmt.Sprintf("New day: %s, daily totals reset", day.Format("Mon Jan 2"))})
+	if _, err := mo.fetchAndProcessData(true); err != nil {
+		logging.LogErrorf("Data refresh after day rollover failed: %v", err)
+	}
+	return true
+}
+
+// fetchAndProcessData fetches data and publishes it to subscribers
 func (mo *MonitoringOrchestrator) fetchAndProcessData(forceRefresh bool) (*MonitoringData, error) {
 	startTime := time.Now()
 
-	// Fetch data using DataManager
+	// Fetch data using DataManager; it falls back to cached data when a refresh fails
 	data, err := mo.dataManager.GetData(forceRefresh)
-	if err != nil {
+	if err == nil {
+		err = mo.dataManager.GetLastError()
+	}
+	mo.updateSourceStatus(err)
+	if err != nil && data == nil {
 		return nil, fmt.Errorf("failed to fetch data: %w", err)
 	}
 
@@ -279,6 +415,7 @@ func (mo *MonitoringOrchestrator) fetchAndProcessData(forceRefresh bool) (*Monit
 		Args:         mo.args,
 		SessionID:    mo.sessionMonitor.GetCurrentSessionID(),
 		SessionCount: mo.sessionMonitor.GetSessionCount(),
+		Metrics:      mo.updateMetrics(data.Blocks),
 	}
 
 	// Store last valid data
@@ -293,8 +430,8 @@ func (mo *MonitoringOrchestrator) fetchAndProcessData(forceRefresh bool) (*Monit
 		// Channel already has data
 	}
 
-	// Notify callbacks
-	mo.notifyCallbacks(*monitoringData)
+	// Notify subscribers
+	events.Publish(mo.bus, *monitoringData)
 
 	elapsed := time.Since(startTime)
 	logging.LogInfof("Data processing completed in %.3fs", elapsed.Seconds())
@@ -302,6 +439,62 @@ func (mo *MonitoringOrchestrator) fetchAndProcessData(forceRefresh bool) (*Monit
 	return monitoringData, nil
 }
 
+// updateMetrics feeds the realtime metrics the entries appended to the active block since the last
+// refresh and returns the metrics. They start over when another block becomes active or the entries
+// of the block changed other than by appending.
+func (mo *MonitoringOrchestrator) updateMetrics(blocks []models.SessionBlock) *calculations.RealtimeMetrics {
+	mo.metricsMu.Lock()
+	defer mo.metricsMu.Unlock()
+
+	var active *models.SessionBlock
+	for i := range blocks {
+		if blocks[i].IsActive && !blocks[i].IsGap {
+			active = &blocks[i]
+		}
+	}
+	if active == nil {
+		mo.metricsStart, mo.metricsFed = time.Time{}, 0
+		return nil
+	}
+
+	entries := active.Entries
+	appended := active.StartTime.Equal(mo.metricsStart) && len(entries) >= mo.metricsFed &&
+		(mo.metricsFed == 0 || entries[mo.metricsFed-1].Timestamp.Equal(mo.metricsLast))
+	if !appended {
+		mo.metrics.Reset(active.StartTime)
+		mo.metricsStart, mo.metricsFed = active.StartTime, 0
+	}
+	for _, entry := range entries[mo.metricsFed:] {
+		mo.metrics.UpdateWithNewEntry(entry)
+	}
+	mo.metricsFed = len(entries)
+	if len(entries) > 0 {
+		mo.metricsLast = entries[len(entries)-1].Timestamp
+	}
+	return mo.metrics.Calculate()
+}
+
+// updateSourceStatus publishes a notice when the data source goes offline or comes back
+func (mo *MonitoringOrchestrator) updateSourceStatus(err error) {
+	mo.mu.Lock()
+	wasOffline := mo.sourceOffline
+	mo.sourceOffline = err != nil
+	mo.mu.Unlock()
+
+	switch {
+	case err != nil && !wasOffline:
+		events.Publish(mo.bus, events.Notice{Level: events.NoticeWarning, Message: fmt.Sprintf("Data source offline: %v", err)})
+	case err == nil && wasOffline:
+		events.Publish(mo.bus, events.Notice{Level: events.NoticeInfo, Message: "Data source back online"})
+	}
+}
+
+// InvalidateCache drops cached analysis data so the next refresh reloads usage files
+func (mo *MonitoringOrchestrator) InvalidateCache() {
+	mo.dataManager.InvalidateCache()
+	events.Publish(mo.bus, events.Notice{Level: events.NoticeInfo, Message: "Cache cleared; reloading usage data"})
+}
+
 // calculateTokenLimit calculates token limit based on plan and data
 func (mo *MonitoringOrchestrator) calculateTokenLimit(data *AnalysisResult) int {
 	// This would implement the same logic as Claude Monitor's token limit calculation
@@ -310,25 +503,6 @@ func (mo *MonitoringOrchestrator) calculateTokenLimit(data *AnalysisResult) int
 	return 500000 // Default token limit
 }
 
-// notifyCallbacks notifies all registered callbacks
-func (mo *MonitoringOrchestrator) notifyCallbacks(data MonitoringData) {
-	mo.mu.RLock()
-	updateCallbacks := make([]DataUpdateCallback, len(mo.updateCallbacks))
-	copy(updateCallbacks, mo.updateCallbacks)
-	mo.mu.RUnlock()
-
-	for _, callback := range updateCallbacks {
-		func() {
-			defer func() {
-				if r := recover(); r != nil {
-					logging.LogErrorf("Callback panic: %v", r)
-				}
-			}()
-			callback(data)
-		}()
-	}
-}
-
 // Goroutine represents a managed goroutine
 type Goroutine struct {
 	name string
@@ -0,0 +1,256 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/clock"
+	"github.com/penwyp/claudecat/config"
+	"github.com/penwyp/claudecat/events"
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// waitTimeout bounds how long a test waits for the monitoring goroutine; the fake clock never waits on it
+const waitTimeout = 5 * time.Second
+
+// loopWaiters is the number of fake timers of a started orchestrator: the refresh ticker, the midnight
+// timer and the cache updater ticker
+const loopWaiters = 3
+
+// yesterday returns midnight UTC of the day before today, recent enough for the loader's lookback
+func yesterday() time.Time {
+	now := time.Now().UTC()
+	return time.Date(now.Year(), now.Month(), now.Day()-1, 0, 0, 0, 0, time.UTC)
+}
+
+// writeUsage writes a usage file with one assistant message at each timestamp
+func writeUsage(t *testing.T, timestamps ...time.Time) string {
+	t.Helper()
+	dir := t.TempDir()
+	var lines []string
+	for i, ts := range timestamps {
+		lines = append(lines, fmt.Sprintf(`{"type":"assistant","timestamp":%q,"sessionId":"s1","requestId":"req-%d","message":{"id":"msg-%d","model":"claude-3-5-sonnet-20241022","role":"assistant","usage":{"input_tokens":1000,"output_tokens":500}}}`,
+			ts.Format(time.RFC3339), i, i))
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "project"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "project", "session.jsonl"), []byte(strings.Join(lines, "\n")+"\n"), 0o644))
+	return dir
+}
+
+// newTestOrchestrator creates an orchestrator over dataPath driven by a fake clock starting at now;
+// configure adjusts the test configuration before the orchestrator is created
+func newTestOrchestrator(t *testing.T, dataPath string, interval time.Duration, now time.Time, configure ...func(*config.Config)) (*MonitoringOrchestrator, *clock.Fake) {
+	t.Helper()
+	cfg := config.DefaultConfig()
+	cfg.App.Timezone = "UTC"
+	cfg.Cache.Dir = t.TempDir()
+	cfg.Data.PricingSource = "default"
+	cfg.Data.PricingOfflineMode = false
+	cfg.Data.SessionOverrides = ""
+	for _, apply := range configure {
+		apply(cfg)
+	}
+
+	fake := clock.NewFake(now)
+	mo := NewMonitoringOrchestrator(interval, dataPath, cfg)
+	mo.SetClock(fake)
+	return mo, fake
+}
+
+// subscribe collects events of type T published on the orchestrator's bus
+func subscribe[T any](t *testing.T, mo *MonitoringOrchestrator) <-chan T {
+	t.Helper()
+	received := make(chan T, 64)
+	unsubscribe := events.Subscribe(mo.Bus(), func(event T) {
+		select {
+		case received <- event:
+		default:
+		}
+	})
+	t.Cleanup(unsubscribe)
+	return received
+}
+
+// next returns the next event received, failing the test when none arrives
+func next[T any](t *testing.T, received <-chan T) T {
+	t.Helper()
+	select {
+	case event := <-received:
+		return event
+	case <-time.After(waitTimeout):
+		var zero T
+		t.Fatalf("no %T event received", zero)
+		return zero
+	}
+}
+
+// start starts the orchestrator and waits until the initial fetch is done and the loop is waiting
+func start(t *testing.T, mo *MonitoringOrchestrator, fake *clock.Fake) {
+	t.Helper()
+	require.NoError(t, mo.Start())
+	t.Cleanup(mo.Stop)
+	require.True(t, mo.WaitForInitialData(waitTimeout), "initial data not fetched")
+	fake.BlockUntil(loopWaiters)
+}
+
+func TestMonitoringOrchestrator_RefreshTicker(t *testing.T) {
+	base := yesterday().Add(10 * time.Hour)
+	mo, fake := newTestOrchestrator(t, writeUsage(t, base.Add(5*time.Minute)), 10*time.Second, base.Add(time.Hour))
+	updates := subscribe[MonitoringData](t, mo)
+	start(t, mo, fake)
+
+	initial := next(t, updates)
+	assert.Equal(t, base.Add(time.Hour), initial.Data.Metadata.GeneratedAt)
+
+	fake.Advance(9 * time.Second)
+	select {
+	case <-updates:
+		t.Fatal("refreshed before the update interval elapsed")
+	default:
+	}
+
+	for i := 0; i < 3; i++ {
+		fake.Advance(time.Second)
+		update := next(t, updates)
+		assert.Equal(t, initial.SessionID, update.SessionID)
+		fake.Advance(9 * time.Second)
+	}
+}
+
+func TestMonitoringOrchestrator_SetUpdateInterval(t *testing.T) {
+	base := yesterday().Add(10 * time.Hour)
+	mo, fake := newTestOrchestrator(t, writeUsage(t, base.Add(5*time.Minute)), 10*time.Second, base.Add(time.Hour))
+	updates := subscribe[MonitoringData](t, mo)
+	start(t, mo, fake)
+	next(t, updates)
+
+	// The running ticker restarts with the new interval
+	mo.SetUpdateInterval(time.Minute)
+	fake.Advance(59 * time.Second)
+	select {
+	case <-updates:
+		t.Fatal("refreshed before the new update interval elapsed")
+	default:
+	}
+	fake.Advance(time.Second)
+	next(t, updates)
+
+	mo.SetUpdateInterval(10 * time.Second)
+	fake.Advance(10 * time.Second)
+	next(t, updates)
+}
+
+func TestMonitoringOrchestrator_DayRollover(t *testing.T) {
+	day := yesterday()
+	mo, fake := newTestOrchestrator(t, writeUsage(t, day.Add(23*time.Hour+50*time.Minute)), 10*time.Minute, day.Add(23*time.Hour+59*time.Minute+30*time.Second))
+	rollovers := subscribe[DayRollover](t, mo)
+	start(t, mo, fake)
+	assert.Equal(t, day, mo.Today())
+
+	fake.Advance(29 * time.Second)
+	select {
+	case rollover := <-rollovers:
+		t.Fatalf("rolled over before midnight: %+v", rollover)
+	default:
+	}
+
+	fake.Advance(time.Second)
+	rollover := next(t, rollovers)
+	assert.Equal(t, day, rollover.Previous)
+	assert.Equal(t, day.AddDate(0, 0, 1), rollover.Day)
+	assert.Equal(t, day.AddDate(0, 0, 1), mo.Today())
+}
+
+func TestMonitoringOrchestrator_SessionWindowExpiry(t *testing.T) {
+	base := yesterday().Add(10 * time.Hour)
+	mo, fake := newTestOrchestrator(t, writeUsage(t, base.Add(10*time.Minute)), 12*time.Hour, base.Add(time.Hour))
+	changes := subscribe[SessionChanged](t, mo)
+	start(t, mo, fake)
+
+	started := next(t, changes)
+	assert.Equal(t, SessionStart, started.Type)
+	require.NotEmpty(t, started.SessionID)
+
+	// The window runs from the start of the hour of the first message for five hours
+	fake.Advance(4*time.Hour - time.Second)
+	data, err := mo.ForceRefresh()
+	require.NoError(t, err)
+	assert.Equal(t, started.SessionID, data.SessionID)
+	assert.Equal(t, SessionUpdate, next(t, changes).Type)
+
+	fake.Advance(time.Second)
+	data, err = mo.ForceRefresh()
+	require.NoError(t, err)
+	assert.Empty(t, data.SessionID)
+	for _, block := range data.Data.Blocks {
+		assert.False(t, block.IsActive, "block %s still active", block.ID)
+	}
+
+	var ended SessionChanged
+	for ended.Type != SessionEnd {
+		ended = next(t, changes)
+	}
+	assert.Equal(t, started.SessionID, ended.SessionID)
+}
+
+func TestMonitoringOrchestrator_PinnedSessionStart(t *testing.T) {
+	base := yesterday().Add(10 * time.Hour)
+	pin := base.Add(2 * time.Hour) // On the hour, since summary-cached entries are bucketed by hour
+	overrides := filepath.Join(t.TempDir(), "session_overrides.txt")
+	require.NoError(t, os.WriteFile(overrides, []byte(pin.Format(time.RFC3339)+"\n"), 0o644))
+
+	dataPath := writeUsage(t, base.Add(10*time.Minute), pin.Add(5*time.Minute))
+	mo, fake := newTestOrchestrator(t, dataPath, 12*time.Hour, pin.Add(time.Hour), func(cfg *config.Config) {
+		cfg.Data.SessionOverrides = overrides
+	})
+	start(t, mo, fake)
+
+	data, err := mo.ForceRefresh()
+	require.NoError(t, err)
+	require.Len(t, data.Data.Blocks, 2)
+	assert.Equal(t, base, data.Data.Blocks[0].StartTime)
+	assert.Equal(t, pin, data.Data.Blocks[0].EndTime, "the block before the pin ends where the pinned one starts")
+	assert.Equal(t, pin, data.Data.Blocks[1].StartTime)
+	assert.True(t, data.Data.Blocks[1].IsActive)
+	assert.Equal(t, data.Data.Blocks[1].ID, data.SessionID)
+}
+
+func TestMonitoringOrchestrator_UpdateMetrics(t *testing.T) {
+	mo, _ := newTestOrchestrator(t, t.TempDir(), time.Minute, time.Now())
+	start := time.Now().Add(-time.Hour).Truncate(time.Minute)
+	entry := func(minutes, tokens int) models.UsageEntry {
+		return models.UsageEntry{Timestamp: start.Add(time.Duration(minutes) * time.Minute), Model: "claude-sonnet-4-20250514", TotalTokens: tokens}
+	}
+	block := func(start time.Time, entries ...models.UsageEntry) []models.SessionBlock {
+		return []models.SessionBlock{{StartTime: start, EndTime: start.Add(5 * time.Hour), IsActive: true, Entries: entries}}
+	}
+
+	tests := []struct {
+		name    string
+		blocks  []models.SessionBlock
+		tokens  int
+		entries int
+	}{
+		{"first refresh feeds the active block", block(start, entry(1, 100), entry(2, 200)), 300, 2},
+		{"appended entries are fed once", block(start, entry(1, 100), entry(2, 200), entry(3, 50)), 350, 3},
+		{"rewritten entries start over", block(start, entry(1, 100), entry(4, 10)), 110, 2},
+		{"a new active block starts over", block(start.Add(time.Minute), entry(5, 70)), 70, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics := mo.updateMetrics(tt.blocks)
+			require.NotNil(t, metrics)
+			assert.Equal(t, tt.tokens, metrics.CurrentTokens)
+			assert.Equal(t, tt.blocks[0].StartTime, metrics.SessionStart)
+			assert.Equal(t, tt.entries, mo.metrics.GetEntryCount())
+		})
+	}
+
+	assert.Nil(t, mo.updateMetrics([]models.SessionBlock{{StartTime: start}}), "no session is active")
+}
@@ -5,7 +5,8 @@ import (
 	"sync"
 	"time"
 
-	"github.com/penwyp/claudecat/logging"
+	"github.com/penwyp/claudecat/clock"
+	"github.com/penwyp/claudecat/events"
 	"github.com/penwyp/claudecat/models"
 )
 
@@ -18,22 +19,38 @@ const (
 	SessionUpdate SessionChangeType = "session_update"
 )
 
+// SessionChanged is published on the event bus when the active session starts, ends or updates
+type SessionChanged struct {
+	Type      SessionChangeType
+	SessionID string
+	Data      interface{}
+}
+
 // SessionMonitor monitors session changes and validates data
 type SessionMonitor struct {
 	currentSessionID string
 	sessionCount     int
 	lastUpdateTime   time.Time
-	callbacks        []SessionChangeCallback
+	bus              *events.Bus
+	clock            clock.Clock
 	mu               sync.RWMutex
 }
 
-// NewSessionMonitor creates a new session monitor
-func NewSessionMonitor() *SessionMonitor {
+// NewSessionMonitor creates a new session monitor publishing session changes on bus
+func NewSessionMonitor(bus *events.Bus) *SessionMonitor {
 	return &SessionMonitor{
-		callbacks: make([]SessionChangeCallback, 0),
+		bus:   bus,
+		clock: clock.Real,
 	}
 }
 
+// SetClock sets the clock recording the time of the last update
+func (sm *SessionMonitor) SetClock(c clock.Clock) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.clock = clock.OrReal(c)
+}
+
 // Update validates data and updates session tracking
 func (sm *SessionMonitor) Update(data *AnalysisResult) (bool, []string) {
 	sm.mu.Lock()
@@ -89,7 +106,7 @@ func (sm *SessionMonitor) Update(data *AnalysisResult) (bool, []string) {
 		}
 	}
 
-	sm.lastUpdateTime = time.Now()
+	sm.lastUpdateTime = sm.clock.Now()
 
 	// Additional validation
 	if err := sm.validateBlockStructure(data.Blocks); err != nil {
@@ -99,13 +116,6 @@ func (sm *SessionMonitor) Update(data *AnalysisResult) (bool, []string) {
 	return len(errors) == 0, errors
 }
 
-// RegisterCallback registers a callback for session changes
-func (sm *SessionMonitor) RegisterCallback(callback SessionChangeCallback) {
-	sm.mu.Lock()
-	defer sm.mu.Unlock()
-	sm.callbacks = append(sm.callbacks, callback)
-}
-
 // GetCurrentSessionID returns the current session ID
 func (sm *SessionMonitor) GetCurrentSessionID() string {
 	sm.mu.RLock()
@@ -127,18 +137,9 @@ func (sm *SessionMonitor) GetLastUpdateTime() time.Time {
 	return sm.lastUpdateTime
 }
 
-// notifySessionChange notifies all registered callbacks of session changes
+// notifySessionChange publishes a session change to subscribers
 func (sm *SessionMonitor) notifySessionChange(eventType SessionChangeType, sessionID string, sessionData interface{}) {
-	for _, callback := range sm.callbacks {
-		func() {
-			defer func() {
-				if r := recover(); r != nil {
-					logging.LogErrorf("Session callback panic: %v", r)
-				}
-			}()
-			callback(string(eventType), sessionID, sessionData)
-		}()
-	}
+	events.Publish(sm.bus, SessionChanged{Type: eventType, SessionID: sessionID, Data: sessionData})
 }
 
 // validateBlockStructure validates the structure of session blocks
@@ -181,6 +182,6 @@ func (sm *SessionMonitor) GetStatistics() map[string]interface{} {
 		"current_session_id": sm.currentSessionID,
 		"session_count":      sm.sessionCount,
 		"last_update_time":   sm.lastUpdateTime,
-		"callbacks_count":    len(sm.callbacks),
+		"callbacks_count":    events.Subscribers[SessionChanged](sm.bus),
 	}
 }
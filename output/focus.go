@@ -0,0 +1,98 @@
+package output
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/penwyp/claudecat/calculations"
+	"github.com/penwyp/claudecat/humanize"
+	"github.com/penwyp/claudecat/models"
+)
+
+// bigGlyphHeight is the number of lines of a big glyph
+const bigGlyphHeight = 5
+
+// bigGlyphs is a block font for the focus display
+var bigGlyphs = map[rune][bigGlyphHeight]string{
+	'0': {"███", "█ █", "█ █", "█ █", "███"},
+	'1': {" █ ", "██ ", " █ ", " █ ", "███"},
+	'2': {"███", "  █", "███", "█  ", "███"},
+	'3': {"███", "  █", "███", "  █", "███"},
+	'4': {"█ █", "█ █", "███", "  █", "  █"},
+	'5': {"███", "█  ", "███", "  █", "███"},
+	'6': {"███", "█  ", "███", "█ █", "███"},
+	'7': {"███", "  █", " █ ", " █ ", " █ "},
+	'8': {"███", "█ █", "███", "█ █", "███"},
+	'9': {"███", "█ █", "███", "  █", "███"},
+	'%': {"█ █", "  █", " █ ", "█  ", "█ █"},
+	'$': {" ██", "█  ", " █ ", "  █", "██ "},
+	'.': {" ", " ", " ", " ", "█"},
+	':': {" ", "█", " ", "█", " "},
+	'-': {"   ", "   ", "███", "   ", "   "},
+}
+
+// renderBigText renders text in the big block font, one space between glyphs; unknown runes are skipped
+func renderBigText(text string) []string {
+	lines := make([]string, bigGlyphHeight)
+	for _, r := range text {
+		glyph, ok := bigGlyphs[r]
+		if !ok {
+			continue
+		}
+		for i := range lines {
+			if lines[i] != "" {
+				lines[i] += " "
+			}
+			lines[i] += glyph[i]
+		}
+	}
+	return lines
+}
+
+// SetFocusMode switches between the full monitor and the minimal focus display
+func (f *ConsoleFormatter) SetFocusMode(focus bool) {
+	f.focus = focus
+}
+
+// FocusMode reports whether the minimal focus display is shown
+func (f *ConsoleFormatter) FocusMode() bool {
+	return f.focus
+}
+
+// renderFocus renders the session cost usage, cost and time to reset in big digits
+func (f *ConsoleFormatter) renderFocus(metrics *calculations.RealtimeMetrics, blocks []models.SessionBlock) []string {
+	usage, cost, reset := "--%", "$0.00", "-:--"
+	var active *models.SessionBlock
+	for i := range blocks {
+		if blocks[i].IsActive {
+			active = &blocks[i]
+			break
+		}
+	}
+	if active != nil && metrics != nil {
+		if f.costLimitP90 > 0 {
+			usage = fmt.Sprintf("%.0f%%", metrics.CurrentCost/f.costLimitP90*100)
+		}
+		cost = humanize.Cost(metrics.CurrentCost)
+
+		start := metrics.SessionStart
+		if start.IsZero() {
+			start = active.StartTime
+		}
+		remaining := max(time.Until(start.Add(models.SessionDuration)), 0).Truncate(time.Minute)
+		reset = fmt.Sprintf("%d:%02d", int(remaining.Hours()), int(remaining.Minutes())%60)
+	}
+
+	var lines []string
+	for _, section := range []struct{ label, value string }{
+		{"SESSION", usage},
+		{"COST", cost},
+		{"RESET IN", reset},
+	} {
+		lines = append(lines, section.label)
+		lines = append(lines, renderBigText(section.value)...)
+		lines = append(lines, "")
+	}
+	lines = append(lines, "[f] full view  [:] commands")
+	return lines
+}
@@ -0,0 +1,78 @@
+package output
+
+import (
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/calculations"
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderBigText(t *testing.T) {
+	tests := []struct {
+		text  string
+		width int // Width of every line
+	}{
+		{"", 0},
+		{"1", 3},
+		{"$1.5", 3 + 1 + 3 + 1 + 1 + 1 + 3},
+		{"9x9", 3 + 1 + 3}, // Unknown runes are skipped
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			lines := renderBigText(tt.text)
+			require.Len(t, lines, bigGlyphHeight)
+			for _, line := range lines {
+				assert.Equal(t, tt.width, len([]rune(line)), line)
+			}
+		})
+	}
+	assert.Equal(t, bigGlyphs['7'][0]+" "+bigGlyphs['0'][0], renderBigText("70")[0])
+}
+
+func TestConsoleFormatter_RenderFocus(t *testing.T) {
+	start := time.Now().Add(-2*time.Hour - 30*time.Second)
+	active := []models.SessionBlock{{StartTime: start, EndTime: start.Add(models.SessionDuration), IsActive: true}}
+	metrics := &calculations.RealtimeMetrics{CurrentCost: 9, SessionStart: start}
+
+	tests := []struct {
+		name      string
+		costLimit float64
+		blocks    []models.SessionBlock
+		metrics   *calculations.RealtimeMetrics
+		values    []string // Session usage, cost and time to reset
+	}{
+		{"no active session", 18, nil, metrics, []string{"--%", "$0.00", "-:--"}},
+		{"no metrics yet", 18, active, nil, []string{"--%", "$0.00", "-:--"}},
+		{"active session", 18, active, metrics, []string{"50%", "$9.00", "2:59"}},
+		{"unknown cost limit", 0, active, metrics, []string{"--%", "$9.00", "2:59"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewConsoleFormatter("pro", "UTC", "24h")
+			f.costLimitP90 = tt.costLimit
+			lines := f.renderFocus(tt.metrics, tt.blocks)
+
+			// Each section is a label, the value in big digits and a blank line
+			section := bigGlyphHeight + 2
+			require.Len(t, lines, 3*section+1)
+			for i, label := range []string{"SESSION", "COST", "RESET IN"} {
+				assert.Equal(t, label, lines[i*section])
+				assert.Equal(t, renderBigText(tt.values[i]), lines[i*section+1:i*section+1+bigGlyphHeight], label)
+			}
+			assert.Equal(t, "[f] full view  [:] commands", lines[len(lines)-1])
+		})
+	}
+}
+
+func TestConsoleFormatter_FocusModeToggle(t *testing.T) {
+	f := NewConsoleFormatter("pro", "UTC", "24h")
+	assert.False(t, f.FocusMode())
+	assert.NotContains(t, f.Format(nil, nil), "[f] full view")
+
+	f.SetFocusMode(true)
+	assert.True(t, f.FocusMode())
+	assert.Contains(t, f.Format(nil, nil), "[f] full view")
+}
@@ -2,11 +2,17 @@ package output
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/penwyp/claudecat/calculations"
+	"github.com/penwyp/claudecat/events"
+	"github.com/penwyp/claudecat/fileio"
+	"github.com/penwyp/claudecat/humanize"
 	"github.com/penwyp/claudecat/models"
+	"github.com/penwyp/claudecat/terminal"
 )
 
 // ConsoleFormatter formats data for console output
@@ -17,7 +23,47 @@ type ConsoleFormatter struct {
 	tokenLimit       int
 	costLimitP90     float64
 	messagesLimitP90 int
-	p90Calculator    *calculations.P90Calculator
+	limitEstimator   *calculations.CustomLimitEstimator
+	statsAggregator  *calculations.StatsAggregator
+	smoothing        calculations.SmoothingMode
+	planLimits       map[string]models.PlanLimits // Overrides from a signed data bundle
+
+	// Cache warm-up progress shown in the footer
+	warmupProcessed int
+	warmupTotal     int
+	warmupETA       time.Duration
+
+	// Files written within the last minute
+	activeFiles []fileio.FileActivity
+
+	// Time of the saved snapshot shown until the initial load completes; zero once data is live
+	refreshingSince time.Time
+
+	// Whether low-power mode is slowing refreshes, shown in the footer
+	lowPower bool
+
+	// Debug panel lines shown below the active files; empty when debugging is off
+	debugPanel []string
+
+	// Notifications shown in the top-right corner
+	toasts *ToastQueue
+
+	// Alarm for a sustained high burn rate; alarmPending is set when it fires until taken
+	burnAlarm    *calculations.BurnRateAlarm
+	alarmPending bool
+	alarmRate    float64
+
+	// Minimal display with big digits, toggled from the keyboard
+	focus bool
+
+	// Command palette, the report it shows below the monitor and the UI theme
+	palette Palette
+	panel   *ReportPanel
+	theme   string
+
+	// Midnight of the day whose totals are shown, advanced by RollDay from the monitoring goroutine
+	today   time.Time
+	todayMu sync.Mutex
 }
 
 // NewConsoleFormatter creates a new console formatter
@@ -29,22 +75,95 @@ func NewConsoleFormatter(plan, timezone, timeFormat string) *ConsoleFormatter {
 		timeFormat = "24h"
 	}
 
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
 	return &ConsoleFormatter{
-		plan:          strings.ToLower(plan),
-		timezone:      timezone,
-		timeFormat:    timeFormat,
-		p90Calculator: calculations.NewP90Calculator(),
+		plan:            strings.ToLower(plan),
+		timezone:        timezone,
+		timeFormat:      timeFormat,
+		limitEstimator:  calculations.NewCustomLimitEstimator(),
+		statsAggregator: calculations.NewStatsAggregator(loc),
+		smoothing:       calculations.SmoothingHourly,
+		toasts:          NewToastQueue(DefaultToastTTL),
+	}
+}
+
+// RollDay starts showing daily totals for the local day beginning at day
+func (f *ConsoleFormatter) RollDay(day time.Time) {
+	f.todayMu.Lock()
+	defer f.todayMu.Unlock()
+	f.today = day
+}
+
+// renderToday renders the usage recorded so far on the current day, or "" before the day is known
+func (f *ConsoleFormatter) renderToday(blocks []models.SessionBlock) string {
+	f.todayMu.Lock()
+	today := f.today
+	f.todayMu.Unlock()
+	if today.IsZero() {
+		return ""
+	}
+	totals := calculations.SumDay(blocks, today)
+	return fmt.Sprintf("%s · %s tokens · %s entries",
+		humanize.Cost(totals.Cost), humanize.Count(totals.Tokens), humanize.Count(totals.Entries))
+}
+
+// Notify shows a toast that dismisses itself after DefaultToastTTL
+func (f *ConsoleFormatter) Notify(level events.NoticeLevel, message string) {
+	f.toasts.Push(level, message, time.Now())
+}
+
+// SetPlanLimits overrides the built-in per-plan limits
+func (f *ConsoleFormatter) SetPlanLimits(limits map[string]models.PlanLimits) {
+	f.planLimits = limits
+}
+
+// SetSmoothing sets the window used for burn rate, cost rate and predictions
+func (f *ConsoleFormatter) SetSmoothing(mode calculations.SmoothingMode) {
+	if mode == "" {
+		mode = calculations.SmoothingHourly
+	}
+	f.smoothing = mode
+}
+
+// SetBurnAlarm sets the alarm checked against the burn rate on every refresh; nil disables it
+func (f *ConsoleFormatter) SetBurnAlarm(alarm *calculations.BurnRateAlarm) {
+	f.burnAlarm = alarm
+}
+
+// TakeBurnAlarm reports whether the burn rate alarm fired since the last call and the rate that fired it
+func (f *ConsoleFormatter) TakeBurnAlarm() (float64, bool) {
+	pending := f.alarmPending
+	f.alarmPending = false
+	return f.alarmRate, pending
+}
+
+// BurnAlarmThreshold returns the tokens per minute threshold of the burn rate alarm, 0 when disabled
+func (f *ConsoleFormatter) BurnAlarmThreshold() float64 {
+	if f.burnAlarm == nil {
+		return 0
+	}
+	return f.burnAlarm.Threshold()
+}
+
+// observeBurnRate feeds the burn rate to the alarm and raises a toast when it fires
+func (f *ConsoleFormatter) observeBurnRate(tokensPerMinute float64) {
+	if f.burnAlarm == nil || !f.burnAlarm.Observe(tokensPerMinute, time.Now()) {
+		return
 	}
+	f.alarmPending = true
+	f.alarmRate = tokensPerMinute
+	f.Notify(events.NoticeWarning, fmt.Sprintf("Burn rate %.0f tokens/min above %.0f for %s",
+		tokensPerMinute, f.burnAlarm.Threshold(), f.burnAlarm.Duration()))
 }
 
 // Format formats the monitoring data for console output
 func (f *ConsoleFormatter) Format(metrics *calculations.RealtimeMetrics, blocks []models.SessionBlock) string {
 	f.updateLimits(blocks)
 
-	var lines []string
-	lines = append(lines, f.renderHeader()...)
-	lines = append(lines, "")
-
 	// Check if there's an active session
 	hasActiveSession := false
 	if blocks != nil {
@@ -56,13 +175,44 @@ func (f *ConsoleFormatter) Format(metrics *calculations.RealtimeMetrics, blocks
 		}
 	}
 
+	if f.focus {
+		if hasActiveSession && metrics != nil {
+			f.observeBurnRate(f.calculateRates(blocks).TokensPerMinute)
+		} else {
+			f.observeBurnRate(0)
+		}
+		lines := f.renderFocus(metrics, blocks)
+		if f.palette.IsOpen() {
+			lines = append(lines, f.palette.render())
+		}
+		return terminal.Text(strings.Join(lines, "\n"))
+	}
+
+	var lines []string
+	lines = append(lines, f.renderHeader()...)
+	lines = append(lines, "")
+
 	if hasActiveSession && metrics != nil {
 		lines = append(lines, f.renderActiveSession(metrics, blocks)...)
 	} else {
+		f.observeBurnRate(0)
 		lines = append(lines, f.renderNoActiveSession(metrics, blocks)...)
 	}
 
+	lines = append(lines, f.renderActiveFiles()...)
+	lines = append(lines, f.debugPanel...)
+	if f.panel != nil {
+		lines = append(lines, f.panel.render(f.theme)...)
+	}
 	lines = append(lines, f.renderFooter(hasActiveSession))
+	if f.palette.IsOpen() {
+		lines = append(lines, f.palette.render())
+	}
+	// Fall back to ASCII before toasts are aligned to the widths of the lines
+	for i, line := range lines {
+		lines[i] = terminal.Text(line)
+	}
+	lines = overlayToasts(lines, f.toasts.Active(time.Now()))
 
 	return strings.Join(lines, "\n")
 }
@@ -79,8 +229,8 @@ func (f *ConsoleFormatter) renderHeader() []string {
 	}
 
 	return []string{
-		fmt.Sprintf("%s %s %s", sparkles, title, sparkles),
-		separator,
+		themeAccent(f.theme, fmt.Sprintf("%s %s %s", sparkles, title, sparkles)),
+		themeAccent(f.theme, separator),
 		fmt.Sprintf("[ %s | %s ]", plan, strings.ToLower(f.timezone)),
 	}
 }
@@ -93,6 +243,7 @@ func (f *ConsoleFormatter) renderNoActiveSession(metrics *calculations.RealtimeM
 	tokensUsed := 0
 	costUsed := 0.0
 	messagesUsed := 0
+	var tokenCounts models.TokenCounts
 
 	if metrics != nil {
 		tokensUsed = metrics.CurrentTokens
@@ -103,6 +254,7 @@ func (f *ConsoleFormatter) renderNoActiveSession(metrics *calculations.RealtimeM
 			for i := len(blocks) - 1; i >= 0; i-- {
 				if !blocks[i].IsGap {
 					messagesUsed = blocks[i].SentMessagesCount
+					tokenCounts = blocks[i].TokenCounts
 					break
 				}
 			}
@@ -116,26 +268,32 @@ func (f *ConsoleFormatter) renderNoActiveSession(metrics *calculations.RealtimeM
 	}
 
 	// Progress bar
-	progressBar := f.renderWideProgressBar(tokenUsage, "🟨")
+	progressBar := "🟨 " + f.renderTokenBar(tokenUsage, tokenCounts)
 	lines = append(lines, fmt.Sprintf("📊 Token Usage:    %s", progressBar))
+	if tokensUsed > 0 && tokenCounts.TotalTokens() > 0 {
+		lines = append(lines, "                      "+f.renderTokenLegend(tokenCounts))
+	}
 	lines = append(lines, "")
 
 	// Stats - show actual values if any tokens were used
 	if tokensUsed > 0 {
 		lines = append(lines, fmt.Sprintf("🎯 Tokens:         %s / ~%s (%s left)",
-			f.formatNumber(tokensUsed),
-			f.formatNumber(f.tokenLimit),
-			f.formatNumber(f.tokenLimit-tokensUsed)))
-		lines = append(lines, fmt.Sprintf("💲 Session Cost:   $%.2f", costUsed))
+			humanize.Compact(tokensUsed),
+			humanize.Compact(f.tokenLimit),
+			humanize.Compact(f.tokenLimit-tokensUsed)))
+		lines = append(lines, "💲 Session Cost:   "+humanize.Cost(costUsed))
 		lines = append(lines, fmt.Sprintf("📨 Sent Messages:  %d messages", messagesUsed))
 	} else {
-		lines = append(lines, fmt.Sprintf("🎯 Tokens:         0 / ~%s (0 left)", f.formatNumber(f.tokenLimit)))
-		lines = append(lines, "💲 Session Cost:   $0.00")
+		lines = append(lines, fmt.Sprintf("🎯 Tokens:         0 / ~%s (0 left)", humanize.Compact(f.tokenLimit)))
+		lines = append(lines, "💲 Session Cost:   "+humanize.Cost(0))
 		lines = append(lines, "📨 Sent Messages:  0 messages")
 	}
 
 	lines = append(lines, "🔥 Burn Rate:      0.0 tokens/min")
 	lines = append(lines, "💵 Cost Rate:      $0.00 $/min")
+	if today := f.renderToday(blocks); today != "" {
+		lines = append(lines, "📅 Today:          "+today)
+	}
 	lines = append(lines, "")
 
 	return lines
@@ -145,8 +303,10 @@ func (f *ConsoleFormatter) renderNoActiveSession(metrics *calculations.RealtimeM
 func (f *ConsoleFormatter) renderActiveSession(metrics *calculations.RealtimeMetrics, blocks []models.SessionBlock) []string {
 	var lines []string
 
-	// Calculate burn rate
-	burnRate := f.calculateBurnRate(blocks)
+	// Calculate burn and cost rates with the configured smoothing
+	rates := f.calculateRates(blocks)
+	burnRate := rates.TokensPerMinute
+	f.observeBurnRate(burnRate)
 
 	// Calculate percentages
 	tokenUsage := float64(metrics.CurrentTokens) / float64(f.tokenLimit) * 100
@@ -186,17 +346,27 @@ func (f *ConsoleFormatter) renderActiveSession(metrics *calculations.RealtimeMet
 	// Cost Usage
 	costIndicator := f.getColorIndicator(costUsage)
 	costBar := f.renderWideProgressBar(costUsage, "")
-	lines = append(lines, fmt.Sprintf("💰 Cost Usage:           %s %s %5.1f%%    $%.2f / $%.2f",
-		costIndicator, costBar, costUsage, metrics.CurrentCost, f.costLimitP90))
+	lines = append(lines, fmt.Sprintf("💰 Cost Usage:           %s %s %5.1f%%    %s / %s",
+		costIndicator, costBar, costUsage, humanize.Cost(metrics.CurrentCost), humanize.Cost(f.costLimitP90)))
 	lines = append(lines, "")
 
-	// Token Usage
+	// Token Usage, split into fresh and cached tokens
+	var tokenCounts models.TokenCounts
+	for _, block := range blocks {
+		if block.IsActive {
+			tokenCounts = block.TokenCounts
+			break
+		}
+	}
 	tokenIndicator := f.getColorIndicator(tokenUsage)
-	tokenBar := f.renderWideProgressBar(tokenUsage, "")
+	tokenBar := f.renderTokenBar(tokenUsage, tokenCounts)
 	lines = append(lines, fmt.Sprintf("📊 Token Usage:          %s %s %5.1f%%    %s / %s",
 		tokenIndicator, tokenBar, tokenUsage,
-		f.formatNumberWithCommas(metrics.CurrentTokens),
-		f.formatNumberWithCommas(f.tokenLimit)))
+		humanize.Count(metrics.CurrentTokens),
+		humanize.Count(f.tokenLimit)))
+	if tokenCounts.TotalTokens() > 0 {
+		lines = append(lines, "                           "+f.renderTokenLegend(tokenCounts))
+	}
 	lines = append(lines, "")
 
 	// Messages Usage
@@ -204,16 +374,14 @@ func (f *ConsoleFormatter) renderActiveSession(metrics *calculations.RealtimeMet
 	messagesBar := f.renderWideProgressBar(messagesUsage, "")
 	lines = append(lines, fmt.Sprintf("📨 Messages Usage:       %s %s %5.1f%%    %d / %s",
 		messagesIndicator, messagesBar, messagesUsage, messageCount,
-		f.formatNumberWithCommas(f.messagesLimitP90)))
+		humanize.Count(f.messagesLimitP90)))
 	lines = append(lines, strings.Repeat("─", 60))
 
 	// Time to Reset
 	timeIndicator := f.getColorIndicator(timePercentage)
 	timeBar := f.renderWideProgressBar(timePercentage, "")
-	hours := int(timeRemaining / 60)
-	mins := int(timeRemaining) % 60
-	lines = append(lines, fmt.Sprintf("⏱️  Time to Reset:       %s %s %dh %dm",
-		timeIndicator, timeBar, hours, mins))
+	lines = append(lines, fmt.Sprintf("⏱️  Time to Reset:       %s %s %s",
+		timeIndicator, timeBar, humanize.Duration(time.Duration(timeRemaining*float64(time.Minute)))))
 	lines = append(lines, "")
 
 	// Model Distribution
@@ -228,11 +396,19 @@ func (f *ConsoleFormatter) renderActiveSession(metrics *calculations.RealtimeMet
 	} else if burnRate > 50 {
 		emoji = "🏃"
 	}
-	lines = append(lines, fmt.Sprintf("🔥 Burn Rate:              %.1f tokens/min %s", burnRate, emoji))
+	lines = append(lines, fmt.Sprintf("🔥 Burn Rate:              %.1f tokens/min %s  [%s]", burnRate, emoji, f.smoothing.Label()))
 
 	// Cost Rate
-	costRate := f.calculateCostRate(metrics)
-	lines = append(lines, fmt.Sprintf("💲 Cost Rate:              $%.4f $/min", costRate))
+	lines = append(lines, fmt.Sprintf("💲 Cost Rate:              %s $/min  [%s]", humanize.CostDecimals(rates.CostPerMinute, 4), f.smoothing.Label()))
+
+	if today := f.renderToday(blocks); today != "" {
+		lines = append(lines, "📅 Today:                  "+today)
+	}
+
+	// Historical comparison for the same weekday and time of day
+	if baselineText := f.renderBaselineComparison(metrics, blocks); baselineText != "" {
+		lines = append(lines, fmt.Sprintf("📈 vs Typical:             %s", baselineText))
+	}
 
 	lines = append(lines, "")
 	lines = append(lines, "🔮 Predictions:")
@@ -254,6 +430,93 @@ func (f *ConsoleFormatter) renderActiveSession(metrics *calculations.RealtimeMet
 	return lines
 }
 
+// renderBaselineComparison describes how the active session compares to its historical baseline
+func (f *ConsoleFormatter) renderBaselineComparison(metrics *calculations.RealtimeMetrics, blocks []models.SessionBlock) string {
+	if f.statsAggregator == nil {
+		return ""
+	}
+
+	var active *models.SessionBlock
+	for i := range blocks {
+		if blocks[i].IsActive {
+			active = &blocks[i]
+			break
+		}
+	}
+	if active == nil {
+		return ""
+	}
+
+	baseline := f.statsAggregator.CalculateSessionBaseline(blocks, *active, time.Now())
+	diff, ok := baseline.CompareToBaseline(metrics.CurrentCost)
+	if !ok {
+		return ""
+	}
+
+	typical := fmt.Sprintf("your typical %s %s", baseline.Weekday, baseline.DayPeriod)
+	switch {
+	case diff >= 1:
+		return fmt.Sprintf("%.0f%% above %s", diff, typical)
+	case diff <= -1:
+		return fmt.Sprintf("%.0f%% below %s", -diff, typical)
+	default:
+		return fmt.Sprintf("in line with %s", typical)
+	}
+}
+
+// SetWarmupProgress sets the cache warm-up progress shown in the footer; total 0 hides it
+func (f *ConsoleFormatter) SetWarmupProgress(processed, total int, eta time.Duration) {
+	f.warmupProcessed = processed
+	f.warmupTotal = total
+	f.warmupETA = eta
+}
+
+// SetRefreshing marks the display as showing a snapshot saved at since while live data loads; zero clears it
+func (f *ConsoleFormatter) SetRefreshing(since time.Time) {
+	f.refreshingSince = since
+}
+
+// SetLowPower sets whether the footer shows the low-power indicator
+func (f *ConsoleFormatter) SetLowPower(enabled bool) {
+	f.lowPower = enabled
+}
+
+// SetDebugPanel sets the lines of the debug panel; nil hides it
+func (f *ConsoleFormatter) SetDebugPanel(lines []string) {
+	f.debugPanel = lines
+}
+
+// SetActiveFiles sets the files shown as currently receiving writes
+func (f *ConsoleFormatter) SetActiveFiles(files []fileio.FileActivity) {
+	f.activeFiles = files
+}
+
+// renderActiveFiles lists the conversation files written within the last minute, linked to the files
+// and their project directories when the terminal supports hyperlinks
+func (f *ConsoleFormatter) renderActiveFiles() []string {
+	if len(f.activeFiles) == 0 {
+		return nil
+	}
+
+	const maxShown = 3
+	lines := []string{"📂 Active files (last minute):"}
+	for i, file := range f.activeFiles {
+		if i == maxShown {
+			lines = append(lines, fmt.Sprintf("   … and %d more", len(f.activeFiles)-maxShown))
+			break
+		}
+		name := filepath.Base(file.Path)
+		if len(name) > 20 {
+			name = name[:8] + "…" + name[len(name)-10:]
+		}
+		lines = append(lines, fmt.Sprintf("   %s (%s) +%s, %d entries",
+			terminal.Link(terminal.FileURL(filepath.Dir(file.Path)), terminal.TruncateColumn("project", file.Project)), terminal.Link(terminal.FileURL(file.Path), name),
+			humanize.Bytes(file.BytesAppended), file.EntriesParsed))
+	}
+	lines = append(lines, "")
+	return lines
+}
+
 // renderFooter renders the footer
 func (f *ConsoleFormatter) renderFooter(hasActiveSession bool) string {
 	currentTime := f.formatTime(time.Now())
@@ -263,7 +526,26 @@ func (f *ConsoleFormatter) renderFooter(hasActiveSession bool) string {
 		statusText = "Active session"
 	}
 
-	return fmt.Sprintf("⏰ %s 📝 %s", currentTime, statusText)
+	footer := fmt.Sprintf("⏰ %s 📝 %s", currentTime, statusText)
+	if !f.palette.IsOpen() {
+		footer += " ⌨️  [:] commands"
+	}
+	if !f.refreshingSince.IsZero() {
+		since := f.formatTimeShort(f.refreshingSince)
+		if time.Since(f.refreshingSince) >= 24*time.Hour {
+			since = f.refreshingSince.Format("Jan 2 ") + since
+		}
+		footer += fmt.Sprintf(" ⟳ Refreshing… showing data from %s", since)
+	}
+	if f.lowPower {
+		footer += " 🔋 Low power"
+	}
+	if f.warmupTotal > 0 && f.warmupProcessed < f.warmupTotal {
+		footer += fmt.Sprintf(" 🔥 Warming cache: %d/%d files, %d remaining, ETA %s",
+			f.warmupProcessed, f.warmupTotal, f.warmupTotal-f.warmupProcessed, f.warmupETA.Round(time.Second))
+	}
+
+	return footer
 }
 
 // renderWideProgressBar renders a 50-character wide progress bar
@@ -345,57 +627,14 @@ func (f *ConsoleFormatter) getColorIndicator(percentage float64) string {
 	}
 }
 
-// calculateBurnRate calculates the current burn rate in tokens/min
-func (f *ConsoleFormatter) calculateBurnRate(blocks []models.SessionBlock) float64 {
-	if blocks == nil || len(blocks) == 0 {
-		return 0.0
+// calculateRates calculates the current burn rate in tokens/min and cost rate in $/min
+func (f *ConsoleFormatter) calculateRates(blocks []models.SessionBlock) calculations.UsageRates {
+	if len(blocks) == 0 {
+		return calculations.UsageRates{}
 	}
 
-	// Use the burn rate calculator to get hourly burn rate
 	calculator := calculations.NewBurnRateCalculator()
-	return calculator.CalculateHourlyBurnRate(blocks, time.Now())
-}
-
-// calculateCostRate calculates the cost rate in $/min
-func (f *ConsoleFormatter) calculateCostRate(metrics *calculations.RealtimeMetrics) float64 {
-	if metrics == nil || metrics.SessionStart.IsZero() {
-		return 0.0
-	}
-
-	elapsed := time.Since(metrics.SessionStart).Minutes()
-	if elapsed <= 0 {
-		return 0.0
-	}
-
-	return metrics.CurrentCost / elapsed
-}
-
-// formatNumber formats large numbers with K/M suffixes
-func (f *ConsoleFormatter) formatNumber(n int) string {
-	if n >= 1000000 {
-		return fmt.Sprintf("%.0fM", float64(n)/1000000)
-	} else if n >= 1000 {
-		return fmt.Sprintf("%.0fK", float64(n)/1000)
-	}
-	return fmt.Sprintf("%d", n)
-}
-
-// formatNumberWithCommas formats numbers with commas for thousands
-func (f *ConsoleFormatter) formatNumberWithCommas(n int) string {
-	if n < 1000 {
-		return fmt.Sprintf("%d", n)
-	}
-
-	// Convert to string and add commas
-	str := fmt.Sprintf("%d", n)
-	result := ""
-	for i, digit := range str {
-		if i > 0 && (len(str)-i)%3 == 0 {
-			result += ","
-		}
-		result += string(digit)
-	}
-	return result
+	return calculator.CalculateSmoothedRates(blocks, time.Now(), f.smoothing)
 }
 
 // formatTime formats time according to the configured format
@@ -428,32 +667,23 @@ func (f *ConsoleFormatter) formatTimeShort(t time.Time) string {
 	return t.Format("3:04 PM")
 }
 
-// updateLimits updates the limits based on plan or P90 calculations
+// updateLimits updates the limits based on plan or session percentiles
 func (f *ConsoleFormatter) updateLimits(blocks []models.SessionBlock) {
-	// Calculate P90 limits if on custom plan
-	if f.plan == "custom" && f.p90Calculator != nil {
-		f.tokenLimit = f.p90Calculator.CalculateP90Limit(blocks, true)
-		f.costLimitP90 = f.p90Calculator.GetCostP90(blocks)
-		f.messagesLimitP90 = f.p90Calculator.GetMessagesP90(blocks)
+	// Estimate limits from session history if on custom plan
+	if f.plan == "custom" && f.limitEstimator != nil {
+		limits := f.limitEstimator.Estimate(blocks, time.Now())
+		f.tokenLimit = limits.TokenLimit
+		f.costLimitP90 = limits.CostLimit
+		f.messagesLimitP90 = limits.MessageLimit
+	} else if limits, ok := f.planLimits[f.plan]; ok {
+		f.tokenLimit = limits.TokenLimit
+		f.costLimitP90 = limits.CostLimit
+		f.messagesLimitP90 = limits.MessageLimit
 	} else {
 		// Set fixed limits based on plan
-		switch f.plan {
-		case "pro":
-			f.tokenLimit = 1000000
-			f.costLimitP90 = 18.0
-			f.messagesLimitP90 = 1500
-		case "max5":
-			f.tokenLimit = 88000
-			f.costLimitP90 = 35.0
-			f.messagesLimitP90 = 1000
-		case "max20":
-			f.tokenLimit = 8000000
-			f.costLimitP90 = 140.0
-			f.messagesLimitP90 = 12000
-		default:
-			f.tokenLimit = 1000000
-			f.costLimitP90 = 18.0
-			f.messagesLimitP90 = 1500
-		}
+		limits := models.GetPlanLimits(f.plan)
+		f.tokenLimit = limits.TokenLimit
+		f.costLimitP90 = limits.CostLimit
+		f.messagesLimitP90 = limits.MessageLimit
 	}
 }
\ No newline at end of file
@@ -0,0 +1,134 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/penwyp/claudecat/terminal"
+)
+
+// Palette is the vim-style command line of the monitor, opened with ':'
+type Palette struct {
+	open  bool
+	input []byte
+}
+
+// Open shows an empty command line
+func (p *Palette) Open() {
+	p.open = true
+	p.input = p.input[:0]
+}
+
+// IsOpen reports whether the command line is shown and receives keys
+func (p *Palette) IsOpen() bool {
+	return p.open
+}
+
+// Type edits the command line with a key. Enter closes it and returns the entered line with
+// submitted set; Escape, or Backspace on an empty line, closes it without a command.
+func (p *Palette) Type(key byte) (line string, submitted bool) {
+	switch {
+	case key == '\r' || key == '\n':
+		p.open = false
+		return strings.TrimSpace(string(p.input)), true
+	case key == 0x1b:
+		p.open = false
+	case key == 0x7f || key == 0x08:
+		if len(p.input) == 0 {
+			p.open = false
+		} else {
+			p.input = p.input[:len(p.input)-1]
+		}
+	case key == 0x15: // Ctrl-U clears the line
+		p.input = p.input[:0]
+	case key >= 0x20 && key < 0x7f:
+		p.input = append(p.input, key)
+	}
+	return "", false
+}
+
+// render renders the command line with a cursor
+func (p *Palette) render() string {
+	return ":" + string(p.input) + "█"
+}
+
+// ReportPanel is a table shown below the monitor, filled from palette commands
+type ReportPanel struct {
+	Title   string
+	Headers []string
+	Rows    [][]string
+}
+
+// render renders the panel as columns padded to their widest cell
+func (r *ReportPanel) render(theme string) []string {
+	rows := make([][]string, len(r.Rows))
+	for i, row := range r.Rows {
+		rows[i] = make([]string, len(row))
+		for j, cell := range row {
+			if j < len(r.Headers) {
+				cell = terminal.TruncateColumn(r.Headers[j], cell)
+			}
+			rows[i][j] = cell
+		}
+	}
+
+	widths := make([]int, len(r.Headers))
+	for _, row := range append([][]string{r.Headers}, rows...) {
+		for i, cell := range row {
+			if i < len(widths) && terminal.VisibleWidth(cell) > widths[i] {
+				widths[i] = terminal.VisibleWidth(cell)
+			}
+		}
+	}
+	formatRow := func(row []string) string {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			if i < len(widths) {
+				cell += strings.Repeat(" ", widths[i]-terminal.VisibleWidth(cell))
+			}
+			cells[i] = cell
+		}
+		return strings.TrimRight(strings.Join(cells, "  "), " ")
+	}
+
+	lines := []string{themeAccent(theme, r.Title), formatRow(r.Headers)}
+	if len(rows) == 0 {
+		return append(lines, "No usage in range", "")
+	}
+	for _, row := range rows {
+		lines = append(lines, formatRow(row))
+	}
+	return append(lines, "")
+}
+
+// themeAccents are the ANSI styles of the header and panel titles per UI theme; auto leaves them unstyled
+var themeAccents = map[string]string{
+	"dark":          "\033[1;36m",
+	"light":         "\033[1;34m",
+	"high-contrast": "\033[1;97m",
+}
+
+// themeAccent styles text with the accent of theme unless NO_COLOR is set
+func themeAccent(theme, text string) string {
+	accent, ok := themeAccents[theme]
+	if !ok || text == "" || os.Getenv("NO_COLOR") != "" {
+		return text
+	}
+	return fmt.Sprintf("%s%s\033[0m", accent, text)
+}
+
+// SetTheme sets the UI theme used to style the monitor (dark, light, high-contrast, auto)
+func (f *ConsoleFormatter) SetTheme(theme string) {
+	f.theme = theme
+}
+
+// Palette returns the command line of the monitor
+func (f *ConsoleFormatter) Palette() *Palette {
+	return &f.palette
+}
+
+// SetPanel shows a report below the monitor; nil hides it
+func (f *ConsoleFormatter) SetPanel(panel *ReportPanel) {
+	f.panel = panel
+}
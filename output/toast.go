@@ -0,0 +1,120 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/penwyp/claudecat/events"
+	"github.com/penwyp/claudecat/terminal"
+)
+
+// DefaultToastTTL is how long a toast stays on screen
+const DefaultToastTTL = 8 * time.Second
+
+// maxToasts is the number of toasts shown at once; older ones are dropped first
+const maxToasts = 4
+
+// toastColumn is the column at which toasts are drawn to the right of the monitor
+const toastColumn = 64
+
+// maxToastWidth is the maximum length of a toast message before it is truncated
+const maxToastWidth = 48
+
+// Toast is a short-lived notification shown in the top-right corner of the monitor
+type Toast struct {
+	Level     events.NoticeLevel
+	Message   string
+	ExpiresAt time.Time
+}
+
+// ToastQueue holds active toasts; it is safe for concurrent use
+type ToastQueue struct {
+	mu     sync.Mutex
+	toasts []Toast
+	ttl    time.Duration
+}
+
+// NewToastQueue creates a queue whose toasts expire after ttl; 0 uses DefaultToastTTL
+func NewToastQueue(ttl time.Duration) *ToastQueue {
+	if ttl <= 0 {
+		ttl = DefaultToastTTL
+	}
+	return &ToastQueue{ttl: ttl}
+}
+
+// Push adds a toast, replacing an identical one that is still visible
+func (q *ToastQueue) Push(level events.NoticeLevel, message string, now time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i, toast := range q.toasts {
+		if toast.Message == message {
+			q.toasts = append(q.toasts[:i], q.toasts[i+1:]...)
+			break
+		}
+	}
+	q.toasts = append(q.toasts, Toast{Level: level, Message: message, ExpiresAt: now.Add(q.ttl)})
+	if len(q.toasts) > maxToasts {
+		q.toasts = q.toasts[len(q.toasts)-maxToasts:]
+	}
+}
+
+// Active drops expired toasts and returns the remaining ones, newest first
+func (q *ToastQueue) Active(now time.Time) []Toast {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	live := q.toasts[:0]
+	for _, toast := range q.toasts {
+		if now.Before(toast.ExpiresAt) {
+			live = append(live, toast)
+		}
+	}
+	q.toasts = live
+
+	active := make([]Toast, len(live))
+	for i, toast := range live {
+		active[len(live)-1-i] = toast
+	}
+	return active
+}
+
+// overlayToasts draws toasts to the right of the first lines of the screen
+func overlayToasts(lines []string, toasts []Toast) []string {
+	for i, toast := range toasts {
+		if i >= len(lines) {
+			lines = append(lines, "")
+		}
+		line := lines[i]
+		if pad := toastColumn - terminal.VisibleWidth(line); pad > 0 {
+			line += strings.Repeat(" ", pad)
+		} else {
+			line += "  "
+		}
+		lines[i] = line + formatToast(toast)
+	}
+	return lines
+}
+
+// formatToast renders a toast with an icon for its level
+func formatToast(toast Toast) string {
+	icon := "ℹ️"
+	switch toast.Level {
+	case events.NoticeWarning:
+		icon = "⚠️"
+	case events.NoticeError:
+		icon = "❌"
+	}
+	if !terminal.Unicode() {
+		icon = "[" + toast.Level.String() + "]"
+	}
+
+	message := toast.Message
+	if utf8.RuneCountInString(message) > maxToastWidth {
+		message = string([]rune(message)[:maxToastWidth-1]) + "…"
+	}
+	return terminal.Text(fmt.Sprintf("┃ %s %s", icon, message))
+}
@@ -0,0 +1,90 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/penwyp/claudecat/humanize"
+	"github.com/penwyp/claudecat/models"
+)
+
+// tokenSegment is one kind of token in the Token Usage gauge
+type tokenSegment struct {
+	label string
+	color string // ANSI foreground of the segment
+	glyph rune   // Fill of the segment when NO_COLOR is set
+	count int
+}
+
+// tokenSegments splits counts into fresh input, output, cache writes and cache reads, the last
+// being far cheaper than the rest
+func tokenSegments(counts models.TokenCounts) []tokenSegment {
+	return []tokenSegment{
+		{"Input", "\033[34m", '█', counts.InputTokens},
+		{"Output", "\033[32m", '▆', counts.OutputTokens},
+		{"Cache write", "\033[33m", '▄', counts.CacheCreationTokens},
+		{"Cache read", "\033[90m", '▂', counts.CacheReadTokens},
+	}
+}
+
+// paint fills n cells of the segment, in its color unless NO_COLOR is set
+func (s tokenSegment) paint(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return strings.Repeat(string(s.glyph), n)
+	}
+	return s.color + strings.Repeat("█", n) + "\033[0m"
+}
+
+// renderTokenBar renders a 50-character progress bar filled to percentage and split between the
+// kinds of tokens of counts in proportion to their share
+func (f *ConsoleFormatter) renderTokenBar(percentage float64, counts models.TokenCounts) string {
+	total := counts.TotalTokens()
+	if total <= 0 {
+		return f.renderWideProgressBar(percentage, "")
+	}
+	width := 50
+	filled := int(percentage * float64(width) / 100)
+	filled = max(0, min(filled, width))
+
+	// Largest remainders, so the segments add up to the filled cells
+	segments := tokenSegments(counts)
+	cells := make([]int, len(segments))
+	remainders := make([]float64, len(segments))
+	assigned := 0
+	for i, segment := range segments {
+		exact := float64(segment.count) / float64(total) * float64(filled)
+		cells[i] = int(exact)
+		remainders[i] = exact - float64(cells[i])
+		assigned += cells[i]
+	}
+	for ; assigned < filled; assigned++ {
+		largest := 0
+		for i := range remainders {
+			if remainders[i] > remainders[largest] {
+				largest = i
+			}
+		}
+		cells[largest]++
+		remainders[largest] = -1
+	}
+
+	var bar strings.Builder
+	for i, segment := range segments {
+		bar.WriteString(segment.paint(cells[i]))
+	}
+	bar.WriteString(strings.Repeat("░", width-filled))
+	return fmt.Sprintf("[%s]", bar.String())
+}
+
+// renderTokenLegend names the segments of the token bar with their token counts
+func (f *ConsoleFormatter) renderTokenLegend(counts models.TokenCounts) string {
+	var parts []string
+	for _, segment := range tokenSegments(counts) {
+		parts = append(parts, fmt.Sprintf("%s %s %s", segment.paint(1), segment.label, humanize.Compact(segment.count)))
+	}
+	return strings.Join(parts, "  ")
+}
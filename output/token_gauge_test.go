@@ -0,0 +1,50 @@
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsoleFormatter_RenderTokenBar(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	f := NewConsoleFormatter("pro", "UTC", "24h")
+
+	tests := []struct {
+		name       string
+		percentage float64
+		counts     models.TokenCounts
+		cells      map[string]int // Cells of each segment glyph, plus ░ for the empty part
+	}{
+		{"proportional split", 100, models.TokenCounts{InputTokens: 10, OutputTokens: 10, CacheCreationTokens: 10, CacheReadTokens: 20},
+			map[string]int{"█": 10, "▆": 10, "▄": 10, "▂": 20, "░": 0}},
+		{"half full", 50, models.TokenCounts{InputTokens: 1, CacheReadTokens: 1},
+			map[string]int{"█": 13, "▆": 0, "▄": 0, "▂": 12, "░": 25}},
+		{"largest remainders fill every cell", 100, models.TokenCounts{InputTokens: 1, OutputTokens: 1, CacheReadTokens: 1},
+			map[string]int{"█": 17, "▆": 17, "▄": 0, "▂": 16, "░": 0}},
+		{"clamped above the limit", 140, models.TokenCounts{OutputTokens: 5},
+			map[string]int{"▆": 50, "░": 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bar := f.renderTokenBar(tt.percentage, tt.counts)
+			assert.True(t, strings.HasPrefix(bar, "[") && strings.HasSuffix(bar, "]"), bar)
+			assert.Equal(t, 52, len([]rune(bar)), "the bar is always 50 cells wide")
+			for glyph, want := range tt.cells {
+				assert.Equal(t, want, strings.Count(bar, glyph), "cells of %s in %s", glyph, bar)
+			}
+		})
+	}
+}
+
+func TestConsoleFormatter_RenderTokenLegend(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	f := NewConsoleFormatter("pro", "UTC", "24h")
+
+	legend := f.renderTokenLegend(models.TokenCounts{InputTokens: 1200, OutputTokens: 300, CacheCreationTokens: 0, CacheReadTokens: 45000})
+	for _, part := range []string{"█ Input 1.2K", "▆ Output 300", "▄ Cache write 0", "▂ Cache read 45K"} {
+		assert.Contains(t, legend, part)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/penwyp/claudecat/clock"
 	"github.com/penwyp/claudecat/models"
 )
 
@@ -13,6 +14,8 @@ import (
 type SessionAnalyzer struct {
 	sessionDurationHours int
 	sessionDuration      time.Duration
+	clock                clock.Clock
+	pins                 *Detector // Holds the pinned session starts, see SetPinnedStarts
 }
 
 // NewSessionAnalyzer creates a new session analyzer with the specified duration
@@ -24,9 +27,22 @@ func NewSessionAnalyzer(sessionDurationHours int) *SessionAnalyzer {
 	return &SessionAnalyzer{
 		sessionDurationHours: sessionDurationHours,
 		sessionDuration:      time.Duration(sessionDurationHours) * time.Hour,
+		clock:                clock.Real,
+		pins:                 NewDetectorWithOptions(GapThreshold, time.Duration(sessionDurationHours)*time.Hour, 24*time.Hour),
 	}
 }
 
+// SetPinnedStarts sets known session starts that begin a block where the entries alone would not,
+// as the Detector does for the session IDs of analyze
+func (sa *SessionAnalyzer) SetPinnedStarts(starts []time.Time) {
+	sa.pins.SetPinnedStarts(starts)
+}
+
+// SetClock sets the clock deciding which blocks are still active
+func (sa *SessionAnalyzer) SetClock(c clock.Clock) {
+	sa.clock = clock.OrReal(c)
+}
+
 // TransformToBlocks processes entries and creates session blocks
 func (sa *SessionAnalyzer) TransformToBlocks(entries []models.UsageEntry) []models.SessionBlock {
 	if len(entries) == 0 {
@@ -40,12 +56,27 @@ func (sa *SessionAnalyzer) TransformToBlocks(entries []models.UsageEntry) []mode
 
 	var blocks []models.SessionBlock
 	var currentBlock *models.SessionBlock
+	var lastEntry time.Time
 
 	for _, entry := range entries {
-		// Check if we need a new block
-		if currentBlock == nil || sa.shouldCreateNewBlock(currentBlock, entry) {
-			// Close current block
+		// Check if we need a new block; a pinned start since the last entry always begins one
+		pin, pinned := sa.pins.pinnedStartFor(entry.Timestamp, lastEntry)
+		if currentBlock == nil || pinned || sa.shouldCreateNewBlock(currentBlock, entry) {
+			start := sa.roundToHour(entry.Timestamp)
+			if pinned {
+				start = pin
+			}
+
+			// Close current block, ending it at the next start so that unaligned windows do not overlap
 			if currentBlock != nil {
+				if currentBlock.EndTime.After(start) {
+					if pinned {
+						currentBlock.EndTime = start
+						currentBlock.GenerateID()
+					} else {
+						start = currentBlock.EndTime
+					}
+				}
 				sa.finalizeBlock(currentBlock)
 				blocks = append(blocks, *currentBlock)
 
@@ -56,11 +87,12 @@ func (sa *SessionAnalyzer) TransformToBlocks(entries []models.UsageEntry) []mode
 			}
 
 			// Create new block
-			currentBlock = sa.createNewBlock(entry)
+			currentBlock = sa.createNewBlock(start)
 		}
 
 		// Add entry to current block
 		sa.addEntryToBlock(currentBlock, entry)
+		lastEntry = entry.Timestamp
 	}
 
 	// Finalize last block
@@ -110,9 +142,8 @@ func (sa *SessionAnalyzer) roundToHour(timestamp time.Time) time.Time {
 	return time.Date(utc.Year(), utc.Month(), utc.Day(), utc.Hour(), 0, 0, 0, time.UTC)
 }
 
-// createNewBlock creates a new session block
-func (sa *SessionAnalyzer) createNewBlock(entry models.UsageEntry) *models.SessionBlock {
-	startTime := sa.roundToHour(entry.Timestamp)
+// createNewBlock creates a new session block starting at startTime
+func (sa *SessionAnalyzer) createNewBlock(startTime time.Time) *models.SessionBlock {
 	endTime := startTime.Add(sa.sessionDuration)
 
 	block := &models.SessionBlock{
@@ -254,7 +285,7 @@ func (sa *SessionAnalyzer) checkForGap(lastBlock *models.SessionBlock, nextEntry
 
 // markActiveBlocks marks blocks as active if they're still ongoing
 func (sa *SessionAnalyzer) markActiveBlocks(blocks []models.SessionBlock) {
-	currentTime := time.Now().UTC()
+	currentTime := clock.OrReal(sa.clock).Now().UTC()
 
 	for i := range blocks {
 		if !blocks[i].IsGap && blocks[i].EndTime.After(currentTime) {
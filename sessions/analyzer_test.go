@@ -0,0 +1,109 @@
+package sessions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/clock"
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionAnalyzer_SetClock(t *testing.T) {
+	start := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	entries := func() []models.UsageEntry {
+		return []models.UsageEntry{{
+			Timestamp:    start.Add(20 * time.Minute),
+			Model:        "claude-3-sonnet-20240229",
+			InputTokens:  100,
+			OutputTokens: 50,
+			TotalTokens:  150,
+			CostUSD:      0.0045,
+		}}
+	}
+
+	fake := clock.NewFake(start.Add(time.Hour))
+	analyzer := NewSessionAnalyzer(5)
+	analyzer.SetClock(fake)
+
+	blocks := analyzer.TransformToBlocks(entries())
+	require.Len(t, blocks, 1)
+	assert.Equal(t, start.Add(5*time.Hour), blocks[0].EndTime)
+	assert.True(t, blocks[0].IsActive)
+
+	fake.Advance(4*time.Hour - time.Second)
+	assert.True(t, analyzer.TransformToBlocks(entries())[0].IsActive)
+
+	fake.Advance(time.Second)
+	assert.False(t, analyzer.TransformToBlocks(entries())[0].IsActive, "the block ends after exactly five hours")
+}
+
+func TestSessionAnalyzer_PinnedStarts(t *testing.T) {
+	base := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	pin := base.Add(150 * time.Minute)
+
+	tests := []struct {
+		name    string
+		pins    []time.Time
+		entries []time.Duration // Offsets from base
+		starts  []time.Time
+		ends    []time.Time
+	}{
+		{
+			name:    "no pins",
+			entries: []time.Duration{10 * time.Minute, 3 * time.Hour},
+			starts:  []time.Time{base},
+			ends:    []time.Time{base.Add(5 * time.Hour)},
+		},
+		{
+			name:    "pin splits a block",
+			pins:    []time.Time{pin},
+			entries: []time.Duration{10 * time.Minute, 3 * time.Hour},
+			starts:  []time.Time{base, pin},
+			ends:    []time.Time{pin, pin.Add(5 * time.Hour)},
+		},
+		{
+			name:    "pin covers the first entry",
+			pins:    []time.Time{pin},
+			entries: []time.Duration{3 * time.Hour, 4 * time.Hour},
+			starts:  []time.Time{pin},
+			ends:    []time.Time{pin.Add(5 * time.Hour)},
+		},
+		{
+			name:    "block after a pinned window starts where it ends",
+			pins:    []time.Time{pin},
+			entries: []time.Duration{3 * time.Hour, 7*time.Hour + 40*time.Minute},
+			starts:  []time.Time{pin, pin.Add(5 * time.Hour)},
+			ends:    []time.Time{pin.Add(5 * time.Hour), pin.Add(10 * time.Hour)},
+		},
+		{
+			name:    "pin without entries in its window",
+			pins:    []time.Time{base.Add(-6 * time.Hour)},
+			entries: []time.Duration{10 * time.Minute},
+			starts:  []time.Time{base},
+			ends:    []time.Time{base.Add(5 * time.Hour)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer := NewSessionAnalyzer(5)
+			analyzer.SetClock(clock.NewFake(base.Add(24 * time.Hour)))
+			analyzer.SetPinnedStarts(tt.pins)
+
+			var entries []models.UsageEntry
+			for _, offset := range tt.entries {
+				entries = append(entries, models.UsageEntry{Timestamp: base.Add(offset), Model: "claude-3-5-sonnet", InputTokens: 10})
+			}
+			var starts, ends []time.Time
+			for _, block := range analyzer.TransformToBlocks(entries) {
+				if !block.IsGap {
+					starts = append(starts, block.StartTime)
+					ends = append(ends, block.EndTime)
+				}
+			}
+			assert.Equal(t, tt.starts, starts)
+			assert.Equal(t, tt.ends, ends)
+		})
+	}
+}
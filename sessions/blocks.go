@@ -0,0 +1,129 @@
+package sessions
+
+import (
+	"time"
+
+	"github.com/penwyp/claudecat/calculations"
+	"github.com/penwyp/claudecat/models"
+)
+
+// BlockOptions configures a billing block report
+type BlockOptions struct {
+	Filter        ListFilter
+	Limits        models.PlanLimits // Session limits of the plan
+	TokenLimit    int               // Overrides Limits.TokenLimit when positive
+	MaxTokenLimit bool              // Use the largest completed block as the token limit, without counting reaching it as a hit
+}
+
+// BillingBlock is one 5-hour billing window, or the idle gap between two, with its usage against the limits
+type BillingBlock struct {
+	SessionSummary
+	LimitHit     bool    `json:"limit_hit"`               // Claude logged a limit message or a limit was reached
+	TokenPercent float64 `json:"token_percent,omitempty"` // Share of the token limit used
+	CostPercent  float64 `json:"cost_percent,omitempty"`  // Share of the cost limit used
+}
+
+// ActiveBlock describes the billing window in progress
+type ActiveBlock struct {
+	ID               string        `json:"id"`
+	Elapsed          time.Duration `json:"elapsed"`
+	Remaining        time.Duration `json:"remaining"`
+	TokensPerMinute  float64       `json:"tokens_per_minute"`
+	CostPerHour      float64       `json:"cost_per_hour"`
+	ProjectedTokens  int           `json:"projected_tokens,omitempty"`   // Tokens at the window end if the rate continues
+	ProjectedCostUSD float64       `json:"projected_cost_usd,omitempty"` // Cost at the window end if the rate continues
+}
+
+// BlockReport lists billing windows with the limits they are measured against
+type BlockReport struct {
+	TokenLimit  int            `json:"token_limit,omitempty"`
+	CostLimit   float64        `json:"cost_limit,omitempty"`
+	Blocks      []BillingBlock `json:"blocks"`
+	Active      *ActiveBlock   `json:"active,omitempty"`
+	TotalTokens int            `json:"total_tokens"`
+	CostUSD     float64        `json:"cost_usd"`
+	LimitHits   int            `json:"limit_hits"` // Windows that hit a limit
+}
+
+// Blocks reports the blocks selected by opts.Filter in chronological order, with the window in
+// progress at now when it is listed
+func Blocks(blocks []models.SessionBlock, opts BlockOptions, now time.Time) BlockReport {
+	report := BlockReport{TokenLimit: opts.Limits.TokenLimit, CostLimit: opts.Limits.CostLimit}
+	switch {
+	case opts.TokenLimit > 0:
+		report.TokenLimit = opts.TokenLimit
+	case opts.MaxTokenLimit:
+		report.TokenLimit = largestBlockTokens(blocks)
+	}
+
+	summaries := List(blocks, opts.Filter)
+	report.Blocks = make([]BillingBlock, 0, len(summaries))
+	for _, summary := range summaries {
+		billing := BillingBlock{SessionSummary: summary}
+		if !summary.Gap {
+			if report.TokenLimit > 0 {
+				billing.TokenPercent = float64(summary.TotalTokens) / float64(report.TokenLimit) * 100
+			}
+			if report.CostLimit > 0 {
+				billing.CostPercent = summary.CostUSD / report.CostLimit * 100
+			}
+			// The largest block always reaches a limit taken from it, so that is not a hit
+			tokenHit := billing.TokenPercent >= 100 && !(opts.MaxTokenLimit && opts.TokenLimit <= 0)
+			billing.LimitHit = summary.LimitHits > 0 || tokenHit || billing.CostPercent >= 100
+			report.TotalTokens += summary.TotalTokens
+			report.CostUSD += summary.CostUSD
+			if billing.LimitHit {
+				report.LimitHits++
+			}
+		}
+		report.Blocks = append(report.Blocks, billing)
+	}
+
+	for _, block := range blocks {
+		if block.IsActive && !block.IsGap && listed(report.Blocks, block.ID) {
+			report.Active = activeBlock(block, now)
+			break
+		}
+	}
+	return report
+}
+
+// activeBlock measures the window in progress at now
+func activeBlock(block models.SessionBlock, now time.Time) *ActiveBlock {
+	active := &ActiveBlock{
+		ID:        block.ID,
+		Elapsed:   max(now.Sub(block.StartTime), 0),
+		Remaining: max(block.EndTime.Sub(now), 0),
+	}
+	calculator := calculations.NewBurnRateCalculator()
+	if rate := calculator.CalculateBurnRate(block); rate != nil {
+		active.TokensPerMinute = rate.TokensPerMinute
+		active.CostPerHour = rate.CostPerHour
+	}
+	if projection := calculator.ProjectBlockUsageAt(block, now); projection != nil {
+		active.ProjectedTokens = projection.ProjectedTotalTokens
+		active.ProjectedCostUSD = projection.ProjectedTotalCost
+	}
+	return active
+}
+
+// largestBlockTokens returns the most tokens used in a completed block
+func largestBlockTokens(blocks []models.SessionBlock) int {
+	largest := 0
+	for _, block := range blocks {
+		if !block.IsGap && !block.IsActive {
+			largest = max(largest, block.TokenCounts.TotalTokens())
+		}
+	}
+	return largest
+}
+
+// listed reports whether the block with id is in the report
+func listed(blocks []BillingBlock, id string) bool {
+	for _, block := range blocks {
+		if block.ID == id {
+			return true
+		}
+	}
+	return false
+}
@@ -13,6 +13,7 @@ type Detector struct {
 	gapThreshold    time.Duration
 	sessionDuration time.Duration
 	lookbackWindow  time.Duration
+	pinnedStarts    []time.Time // Known session starts that override detection
 }
 
 // DetectionResult contains the results of session boundary detection
@@ -63,6 +64,30 @@ func NewDetectorWithOptions(gapThreshold, sessionDuration, lookbackWindow time.D
 	}
 }
 
+// SetPinnedStarts sets known session start times that the detector treats as ground truth
+func (d *Detector) SetPinnedStarts(starts []time.Time) {
+	d.pinnedStarts = make([]time.Time, len(starts))
+	copy(d.pinnedStarts, starts)
+	sort.Slice(d.pinnedStarts, func(i, j int) bool {
+		return d.pinnedStarts[i].Before(d.pinnedStarts[j])
+	})
+}
+
+// pinnedStartFor returns the latest pinned start in (after, t] whose session window still covers t
+func (d *Detector) pinnedStartFor(t, after time.Time) (time.Time, bool) {
+	for i := len(d.pinnedStarts) - 1; i >= 0; i-- {
+		pin := d.pinnedStarts[i]
+		if pin.After(t) {
+			continue
+		}
+		if !pin.After(after) || !t.Before(pin.Add(d.sessionDuration)) {
+			return time.Time{}, false
+		}
+		return pin, true
+	}
+	return time.Time{}, false
+}
+
 // DetectSessions analyzes usage entries and detects session boundaries
 func (d *Detector) DetectSessions(entries []models.UsageEntry) DetectionResult {
 	if len(entries) == 0 {
@@ -117,34 +142,53 @@ func (d *Detector) detectSessionBoundaries(entries []models.UsageEntry) []Sessio
 	}
 
 	sessions := []SessionBoundary{}
-	var currentSessionStart time.Time
 	lastEntryTime := entries[0].Timestamp
 
-	// Initialize first session
-	currentSessionStart = RoundToSessionStart(entries[0].Timestamp)
+	// Initialize first session, preferring a pinned start that covers the first entry
+	currentSessionStart, explicit := d.pinnedStartFor(entries[0].Timestamp, time.Time{})
+	if !explicit {
+		currentSessionStart = RoundToSessionStart(entries[0].Timestamp)
+	}
+
+	endSession := func(lastIndex int, sessionEnd time.Time) {
+		boundary := SessionBoundary{
+			StartTime:  currentSessionStart,
+			EndTime:    sessionEnd,
+			Confidence: d.calculateConfidence(entries, lastIndex, currentSessionStart, sessionEnd),
+			Source:     "detected",
+		}
+		if explicit {
+			boundary.Confidence = 1.0
+			boundary.Source = "explicit"
+		}
+		sessions = append(sessions, boundary)
+	}
 
 	for i, entry := range entries {
 		timeSinceLastEntry := entry.Timestamp.Sub(lastEntryTime)
+		pin, pinned := d.pinnedStartFor(entry.Timestamp, lastEntryTime)
 
 		// Check if this entry indicates a new session
-		if timeSinceLastEntry >= d.gapThreshold ||
+		if (i > 0 && pinned) || timeSinceLastEntry >= d.gapThreshold ||
 			entry.Timestamp.Sub(currentSessionStart) >= d.sessionDuration {
 
 			// End current session
 			sessionEnd := lastEntryTime
-			if currentSessionStart.Add(d.sessionDuration).Before(sessionEnd) {
-				sessionEnd = currentSessionStart.Add(d.sessionDuration)
+			windowEnd := currentSessionStart.Add(d.sessionDuration)
+			if windowEnd.Before(sessionEnd) {
+				sessionEnd = windowEnd
+			}
+			endSession(i-1, sessionEnd)
+
+			// Start new session; an unaligned pinned window must not overlap the next one
+			if pinned {
+				currentSessionStart, explicit = pin, true
+			} else {
+				currentSessionStart, explicit = RoundToSessionStart(entry.Timestamp), false
+				if currentSessionStart.Before(windowEnd) && !entry.Timestamp.Before(windowEnd) {
+					currentSessionStart = windowEnd
+				}
 			}
-
-			sessions = append(sessions, SessionBoundary{
-				StartTime:  currentSessionStart,
-				EndTime:    sessionEnd,
-				Confidence: d.calculateConfidence(entries, i-1, currentSessionStart, sessionEnd),
-				Source:     "detected",
-			})
-
-			// Start new session
-			currentSessionStart = RoundToSessionStart(entry.Timestamp)
 		}
 
 		lastEntryTime = entry.Timestamp
@@ -155,13 +199,7 @@ func (d *Detector) detectSessionBoundaries(entries []models.UsageEntry) []Sessio
 	if currentSessionStart.Add(d.sessionDuration).Before(sessionEnd) {
 		sessionEnd = currentSessionStart.Add(d.sessionDuration)
 	}
-
-	sessions = append(sessions, SessionBoundary{
-		StartTime:  currentSessionStart,
-		EndTime:    sessionEnd,
-		Confidence: d.calculateConfidence(entries, len(entries)-1, currentSessionStart, sessionEnd),
-		Source:     "detected",
-	})
+	endSession(len(entries)-1, sessionEnd)
 
 	return sessions
 }
@@ -461,3 +461,33 @@ func TestDetector_GenerateSessionID(t *testing.T) {
 	assert.Contains(t, sessionID, "session_")
 	assert.Contains(t, sessionID, "20240115") // Should contain date
 }
+
+func TestDetector_DetectSessions_PinnedStarts(t *testing.T) {
+	detector := NewDetector()
+	baseTime := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	pin := time.Date(2024, 1, 15, 11, 30, 0, 0, time.UTC)
+	detector.SetPinnedStarts([]time.Time{pin})
+
+	entries := []models.UsageEntry{
+		{Timestamp: baseTime.Add(10 * time.Minute)},
+		{Timestamp: baseTime.Add(time.Hour)},
+		{Timestamp: pin.Add(5 * time.Minute)},
+		{Timestamp: pin.Add(4 * time.Hour)},
+		{Timestamp: pin.Add(5*time.Hour + 10*time.Minute)},
+	}
+
+	result := detector.DetectSessions(entries)
+	assert.Len(t, result.Sessions, 3)
+	assert.Empty(t, result.Overlaps)
+
+	assert.Equal(t, baseTime, result.Sessions[0].StartTime)
+	assert.Equal(t, "detected", result.Sessions[0].Source)
+
+	// The pinned session starts exactly at the pin with full confidence
+	assert.Equal(t, pin, result.Sessions[1].StartTime)
+	assert.Equal(t, "explicit", result.Sessions[1].Source)
+	assert.Equal(t, 1.0, result.Sessions[1].Confidence)
+
+	// The following session starts where the pinned window ends rather than overlapping it
+	assert.Equal(t, pin.Add(5*time.Hour), result.Sessions[2].StartTime)
+}
@@ -0,0 +1,92 @@
+package sessions
+
+import (
+	"sort"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+)
+
+// LimitEvent is a limit message found in the logs with the session block it was hit in
+type LimitEvent struct {
+	Time       time.Time     `json:"time"`
+	Type       string        `json:"type"` // system_limit, opus_limit or tool_result_limit
+	Message    string        `json:"message"`
+	BlockID    string        `json:"block_id"`
+	BlockStart time.Time     `json:"block_start"`
+	BlockEnd   time.Time     `json:"block_end"`
+	ResumedAt  *time.Time    `json:"resumed_at,omitempty"` // First usage after the hit within the block
+	Lost       time.Duration `json:"lost"`                 // Until usage resumed, the next hit or the block ended
+}
+
+// LimitReport lists the limit hits within a time range
+type LimitReport struct {
+	Events []LimitEvent   `json:"events"`
+	Blocks int            `json:"blocks"` // Session blocks with at least one hit
+	Lost   time.Duration  `json:"lost"`
+	ByType map[string]int `json:"by_type"`
+}
+
+// Limits reports the limit messages attached to the blocks overlapping [filter.From, filter.To]
+// whose time is in that range, in chronological order. The time lost to a hit runs until the next
+// usage in its block, or the next hit, or the end of the block when usage did not resume, and
+// stops at now for the active block. Hits in idle gaps lose no time.
+func Limits(blocks []models.SessionBlock, filter ListFilter, now time.Time) LimitReport {
+	report := LimitReport{Events: []LimitEvent{}, ByType: make(map[string]int)}
+	for _, block := range blocks {
+		if len(block.LimitMessages) == 0 {
+			continue
+		}
+		hits := append([]models.LimitMessage(nil), block.LimitMessages...)
+		sort.SliceStable(hits, func(i, j int) bool { return hits[i].Timestamp.Before(hits[j].Timestamp) })
+
+		counted := false
+		for i, hit := range hits {
+			if (!filter.From.IsZero() && hit.Timestamp.Before(filter.From)) || (!filter.To.IsZero() && hit.Timestamp.After(filter.To)) {
+				continue
+			}
+			event := LimitEvent{
+				Time:       hit.Timestamp,
+				Type:       hit.Type,
+				Message:    hit.Message,
+				BlockID:    block.ID,
+				BlockStart: block.StartTime,
+				BlockEnd:   block.EndTime,
+			}
+			if !block.IsGap {
+				end := block.EndTime
+				if now.Before(end) {
+					end = now
+				}
+				for _, entry := range block.Entries {
+					if entry.Timestamp.After(hit.Timestamp) {
+						resumed := entry.Timestamp
+						event.ResumedAt = &resumed
+						if resumed.Before(end) {
+							end = resumed
+						}
+						break
+					}
+				}
+				if i+1 < len(hits) && hits[i+1].Timestamp.Before(end) {
+					end = hits[i+1].Timestamp
+				}
+				if end.After(hit.Timestamp) {
+					event.Lost = end.Sub(hit.Timestamp)
+				}
+			}
+
+			report.Events = append(report.Events, event)
+			report.ByType[hit.Type]++
+			report.Lost += event.Lost
+			if !counted && !block.IsGap {
+				report.Blocks++
+				counted = true
+			}
+		}
+	}
+	sort.SliceStable(report.Events, func(i, j int) bool {
+		return report.Events[i].Time.Before(report.Events[j].Time)
+	})
+	return report
+}
@@ -0,0 +1,147 @@
+package sessions
+
+import (
+	"sort"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+)
+
+// ListFilter selects the session blocks returned by List; zero bounds are open
+type ListFilter struct {
+	From       time.Time
+	To         time.Time
+	ActiveOnly bool
+	HideGaps   bool
+}
+
+// SessionSummary is one session block or idle gap as listed by claudecat sessions
+type SessionSummary struct {
+	ID           string             `json:"id"`
+	Start        time.Time          `json:"start"`
+	End          time.Time          `json:"end"`
+	LastActivity *time.Time         `json:"last_activity,omitempty"`
+	Duration     time.Duration      `json:"duration"` // Start to last activity for sessions, the idle time for gaps
+	Active       bool               `json:"active"`
+	Gap          bool               `json:"gap"`
+	Entries      int                `json:"entries"`
+	Tokens       models.TokenCounts `json:"tokens"`
+	TotalTokens  int                `json:"total_tokens"`
+	CostUSD      float64            `json:"cost_usd"`
+	Models       []string           `json:"models"`
+	LimitHits    int                `json:"limit_hits"`
+	Tags         []string           `json:"tags,omitempty"` // Labels of the session, from claudecat tag
+	Note         string             `json:"note,omitempty"`
+}
+
+// List summarizes the blocks overlapping [filter.From, filter.To] in chronological order
+func List(blocks []models.SessionBlock, filter ListFilter) []SessionSummary {
+	summaries := make([]SessionSummary, 0, len(blocks))
+	for _, block := range blocks {
+		if filter.ActiveOnly && !block.IsActive {
+			continue
+		}
+		if filter.HideGaps && block.IsGap {
+			continue
+		}
+		if !filter.From.IsZero() && block.EndTime.Before(filter.From) {
+			continue
+		}
+		if !filter.To.IsZero() && block.StartTime.After(filter.To) {
+			continue
+		}
+		summaries = append(summaries, summarizeBlock(block))
+	}
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return summaries[i].Start.Before(summaries[j].Start)
+	})
+	return summaries
+}
+
+// summarizeBlock converts a block into its listed form
+func summarizeBlock(block models.SessionBlock) SessionSummary {
+	summary := SessionSummary{
+		ID:           block.ID,
+		Start:        block.StartTime,
+		End:          block.EndTime,
+		LastActivity: block.ActualEndTime,
+		Active:       block.IsActive,
+		Gap:          block.IsGap,
+		Entries:      len(block.Entries),
+		Tokens:       block.TokenCounts,
+		TotalTokens:  block.TokenCounts.TotalTokens(),
+		CostUSD:      block.CostUSD,
+		Models:       block.Models,
+		LimitHits:    len(block.LimitMessages),
+	}
+	if summary.Models == nil {
+		summary.Models = []string{}
+	}
+	switch {
+	case block.IsGap:
+		summary.Duration = block.EndTime.Sub(block.StartTime)
+	case block.ActualEndTime != nil:
+		summary.Duration = block.ActualEndTime.Sub(block.StartTime)
+	}
+	return summary
+}
+
+// SessionModelUsage is the usage of one model within a session block, as exported by claudecat sessions --per-model
+type SessionModelUsage struct {
+	SessionID   string             `json:"session_id"`
+	Start       time.Time          `json:"start"`
+	End         time.Time          `json:"end"`
+	Active      bool               `json:"active"`
+	Model       string             `json:"model"`
+	Entries     int                `json:"entries"`
+	Tokens      models.TokenCounts `json:"tokens"`
+	TotalTokens int                `json:"total_tokens"`
+	CostUSD     float64            `json:"cost_usd"`
+	Tags        []string           `json:"tags,omitempty"` // Labels of the session, from claudecat tag
+}
+
+// ListPerModel breaks the session blocks selected by filter down by model, in chronological order
+// and by model name within a session. Gaps have no usage and are left out.
+func ListPerModel(blocks []models.SessionBlock, filter ListFilter) []SessionModelUsage {
+	filter.HideGaps = true
+	var rows []SessionModelUsage
+	for _, summary := range List(blocks, filter) {
+		block := findBlock(blocks, summary.ID)
+		if block == nil {
+			continue
+		}
+		byModel := make(map[string]*SessionModelUsage)
+		var names []string
+		for _, entry := range block.Entries {
+			row, ok := byModel[entry.Model]
+			if !ok {
+				row = &SessionModelUsage{SessionID: block.ID, Start: block.StartTime, End: block.EndTime, Active: block.IsActive, Model: entry.Model}
+				byModel[entry.Model] = row
+				names = append(names, entry.Model)
+			}
+			row.Entries++
+			row.Tokens.InputTokens += entry.InputTokens
+			row.Tokens.OutputTokens += entry.OutputTokens
+			row.Tokens.CacheCreationTokens += entry.CacheCreationTokens
+			row.Tokens.CacheReadTokens += entry.CacheReadTokens
+			row.CostUSD += entry.CostUSD
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			row := byModel[name]
+			row.TotalTokens = row.Tokens.TotalTokens()
+			rows = append(rows, *row)
+		}
+	}
+	return rows
+}
+
+// findBlock returns the block with id, or nil
+func findBlock(blocks []models.SessionBlock, id string) *models.SessionBlock {
+	for i := range blocks {
+		if blocks[i].ID == id {
+			return &blocks[i]
+		}
+	}
+	return nil
+}
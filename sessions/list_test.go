@@ -0,0 +1,163 @@
+package sessions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestList(t *testing.T) {
+	base := time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC)
+	entry := func(offset time.Duration, model string) models.UsageEntry {
+		return models.UsageEntry{Timestamp: base.Add(offset), Model: model, InputTokens: 100, OutputTokens: 50, TotalTokens: 150, CostUSD: 0.5}
+	}
+	blocks := NewSessionAnalyzer(5).TransformToBlocks([]models.UsageEntry{
+		entry(10*time.Minute, "claude-sonnet-4-20250514"),
+		entry(90*time.Minute, "claude-opus-4-20250514"),
+		entry(24*time.Hour, "claude-sonnet-4-20250514"),
+	})
+
+	summaries := List(blocks, ListFilter{})
+	require.Len(t, summaries, 3)
+	first := summaries[0]
+	assert.Equal(t, base, first.Start)
+	assert.Equal(t, base.Add(5*time.Hour), first.End)
+	assert.Equal(t, 90*time.Minute, first.Duration)
+	assert.Equal(t, 2, first.Entries)
+	assert.Equal(t, 300, first.TotalTokens)
+	assert.InDelta(t, 1.0, first.CostUSD, 1e-9)
+	assert.ElementsMatch(t, []string{"claude-sonnet-4-20250514", "claude-opus-4-20250514"}, first.Models)
+
+	gap := summaries[1]
+	assert.True(t, gap.Gap)
+	assert.Equal(t, 24*time.Hour-90*time.Minute, gap.Duration)
+	assert.NotNil(t, gap.Models)
+
+	assert.Len(t, List(blocks, ListFilter{HideGaps: true}), 2)
+	assert.Empty(t, List(blocks, ListFilter{ActiveOnly: true}))
+
+	// Blocks overlapping the range are kept
+	inRange := List(blocks, ListFilter{From: base.Add(4 * time.Hour), To: base.Add(6 * time.Hour)})
+	require.Len(t, inRange, 2)
+	assert.Equal(t, base, inRange[0].Start)
+	assert.True(t, inRange[1].Gap)
+}
+
+func TestBlocks(t *testing.T) {
+	base := time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC)
+	entry := func(offset time.Duration, tokens int, cost float64) models.UsageEntry {
+		return models.UsageEntry{Timestamp: base.Add(offset), Model: "claude-sonnet-4-20250514", InputTokens: tokens, TotalTokens: tokens, CostUSD: cost}
+	}
+	blocks := NewSessionAnalyzer(5).TransformToBlocks([]models.UsageEntry{
+		entry(10*time.Minute, 1000, 2),
+		entry(24*time.Hour, 3000, 12),
+		entry(24*time.Hour+60*time.Minute, 1000, 2),
+	})
+	require.Len(t, blocks, 3)
+	blocks[2].IsActive = true
+	now := base.Add(24*time.Hour + 2*time.Hour)
+
+	report := Blocks(blocks, BlockOptions{Limits: models.PlanLimits{TokenLimit: 8000, CostLimit: 10}}, now)
+	require.Len(t, report.Blocks, 3)
+	assert.Equal(t, 8000, report.TokenLimit)
+	assert.Equal(t, 5000, report.TotalTokens)
+	assert.InDelta(t, 16.0, report.CostUSD, 1e-9)
+
+	first := report.Blocks[0]
+	assert.False(t, first.LimitHit)
+	assert.InDelta(t, 12.5, first.TokenPercent, 1e-9)
+	assert.InDelta(t, 20.0, first.CostPercent, 1e-9)
+	assert.True(t, report.Blocks[1].Gap)
+	assert.Zero(t, report.Blocks[1].CostPercent)
+
+	// The cost limit was reached in the active block
+	assert.True(t, report.Blocks[2].LimitHit)
+	assert.Equal(t, 1, report.LimitHits)
+	require.NotNil(t, report.Active)
+	assert.Equal(t, blocks[2].ID, report.Active.ID)
+	assert.Equal(t, 2*time.Hour, report.Active.Elapsed)
+	assert.Equal(t, 3*time.Hour, report.Active.Remaining)
+	assert.Greater(t, report.Active.ProjectedTokens, 4000)
+
+	// The token limit can come from the largest completed block
+	maxReport := Blocks(blocks, BlockOptions{MaxTokenLimit: true, Filter: ListFilter{HideGaps: true}}, now)
+	assert.Equal(t, 1000, maxReport.TokenLimit)
+	require.Len(t, maxReport.Blocks, 2)
+	assert.InDelta(t, 400.0, maxReport.Blocks[1].TokenPercent, 1e-9)
+	assert.False(t, maxReport.Blocks[1].LimitHit)
+
+	// The active block is only described when it is listed
+	past := Blocks(blocks, BlockOptions{Filter: ListFilter{To: base.Add(time.Hour), HideGaps: true}}, now)
+	require.Len(t, past.Blocks, 1)
+	assert.Nil(t, past.Active)
+	assert.Zero(t, past.Blocks[0].TokenPercent)
+}
+
+func TestListPerModel(t *testing.T) {
+	base := time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC)
+	entry := func(offset time.Duration, model string, cost float64) models.UsageEntry {
+		return models.UsageEntry{Timestamp: base.Add(offset), Model: model, InputTokens: 100, OutputTokens: 50,
+			CacheCreationTokens: 10, CacheReadTokens: 1000, TotalTokens: 1160, CostUSD: cost}
+	}
+	blocks := NewSessionAnalyzer(5).TransformToBlocks([]models.UsageEntry{
+		entry(10*time.Minute, "claude-sonnet-4-20250514", 0.5),
+		entry(20*time.Minute, "claude-opus-4-20250514", 2),
+		entry(30*time.Minute, "claude-sonnet-4-20250514", 0.5),
+		entry(24*time.Hour, "claude-sonnet-4-20250514", 0.5),
+	})
+
+	rows := ListPerModel(blocks, ListFilter{})
+	require.Len(t, rows, 3)
+	assert.Equal(t, "claude-opus-4-20250514", rows[0].Model)
+	assert.Equal(t, 1, rows[0].Entries)
+	sonnet := rows[1]
+	assert.Equal(t, "claude-sonnet-4-20250514", sonnet.Model)
+	assert.Equal(t, rows[0].SessionID, sonnet.SessionID)
+	assert.Equal(t, base, sonnet.Start)
+	assert.Equal(t, 2, sonnet.Entries)
+	assert.Equal(t, models.TokenCounts{InputTokens: 200, OutputTokens: 100, CacheCreationTokens: 20, CacheReadTokens: 2000}, sonnet.Tokens)
+	assert.Equal(t, 2320, sonnet.TotalTokens)
+	assert.InDelta(t, 1.0, sonnet.CostUSD, 1e-9)
+	assert.Equal(t, base.Add(24*time.Hour), rows[2].Start)
+
+	assert.Len(t, ListPerModel(blocks, ListFilter{From: base.Add(12 * time.Hour)}), 1)
+}
+
+func TestLimits(t *testing.T) {
+	base := time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC)
+	entry := func(offset time.Duration) models.UsageEntry {
+		return models.UsageEntry{Timestamp: base.Add(offset), Model: "claude-opus-4-20250514", InputTokens: 100, TotalTokens: 100, CostUSD: 1}
+	}
+	blocks := NewSessionAnalyzer(5).TransformToBlocks([]models.UsageEntry{
+		entry(10 * time.Minute), entry(2 * time.Hour), // Resumed 1h30 after the first hit
+		entry(24 * time.Hour), // Never resumed after the hit: lost until the block ends
+	})
+	require.Len(t, blocks, 3)
+	hit := func(offset time.Duration, kind string) models.LimitMessage {
+		return models.LimitMessage{Timestamp: base.Add(offset), Type: kind, Message: "Claude usage limit reached"}
+	}
+	blocks[0].LimitMessages = []models.LimitMessage{hit(30*time.Minute, "system_limit"), hit(20*time.Minute, "system_limit")}
+	blocks[2].LimitMessages = []models.LimitMessage{hit(27*time.Hour, "opus_limit")}
+
+	report := Limits(blocks, ListFilter{}, base.Add(48*time.Hour))
+	require.Len(t, report.Events, 3)
+	assert.Equal(t, base.Add(20*time.Minute), report.Events[0].Time)
+	assert.Equal(t, 10*time.Minute, report.Events[0].Lost, "until the next hit")
+	assert.Equal(t, 90*time.Minute, report.Events[1].Lost)
+	require.NotNil(t, report.Events[1].ResumedAt)
+	assert.Equal(t, base.Add(2*time.Hour), *report.Events[1].ResumedAt)
+	assert.Equal(t, blocks[0].ID, report.Events[1].BlockID)
+	assert.Nil(t, report.Events[2].ResumedAt)
+	assert.Equal(t, 2*time.Hour, report.Events[2].Lost, "the block started at 09:00 the next day")
+	assert.Equal(t, 2, report.Blocks)
+	assert.Equal(t, 4*time.Hour-20*time.Minute, report.Lost)
+	assert.Equal(t, map[string]int{"system_limit": 2, "opus_limit": 1}, report.ByType)
+
+	// The active block loses time only up to now
+	report = Limits(blocks, ListFilter{From: base.Add(12 * time.Hour)}, base.Add(27*time.Hour+15*time.Minute))
+	require.Len(t, report.Events, 1)
+	assert.Equal(t, 15*time.Minute, report.Events[0].Lost)
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/penwyp/claudecat/calculations"
+	"github.com/penwyp/claudecat/clock"
 	"github.com/penwyp/claudecat/models"
 )
 
@@ -16,6 +17,7 @@ type Manager struct {
 	mu             sync.RWMutex                 // Protects concurrent access
 	detector       *Detector                    // Session boundary detection
 	costCalc       *calculations.CostCalculator // Cost calculations
+	clock          clock.Clock                  // Decides which sessions are active and their countdowns
 }
 
 // Session represents a 5-hour usage session with statistics
@@ -50,9 +52,17 @@ func NewManager() *Manager {
 		activeSessions: make([]*Session, 0),
 		detector:       NewDetector(),
 		costCalc:       calculations.NewCostCalculator(),
+		clock:          clock.Real,
 	}
 }
 
+// SetClock sets the clock deciding which sessions are active and how much of them remains
+func (m *Manager) SetClock(c clock.Clock) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.clock = clock.OrReal(c)
+}
+
 // AddEntry adds a usage entry to the appropriate session(s)
 func (m *Manager) AddEntry(entry models.UsageEntry) error {
 	if err := entry.Validate(); err != nil {
@@ -70,7 +80,7 @@ func (m *Manager) AddEntry(entry models.UsageEntry) error {
 	}
 
 	// Update session statistics
-	if err := session.UpdateStats(m.costCalc); err != nil {
+	if err := session.updateStatsAt(m.costCalc, m.clock.Now()); err != nil {
 		return fmt.Errorf("failed to update session stats: %w", err)
 	}
 
@@ -130,7 +140,7 @@ func (m *Manager) RefreshStats() error {
 	defer m.mu.Unlock()
 
 	for _, session := range m.sessions {
-		if err := session.UpdateStats(m.costCalc); err != nil {
+		if err := session.updateStatsAt(m.costCalc, m.clock.Now()); err != nil {
 			return fmt.Errorf("failed to refresh stats for session %s: %w", session.ID, err)
 		}
 	}
@@ -165,8 +175,9 @@ func (m *Manager) findOrCreateSession(timestamp time.Time) *Session {
 func (m *Manager) updateActiveSessions() {
 	m.activeSessions = m.activeSessions[:0] // Clear without reallocating
 
+	now := m.clock.Now()
 	for _, session := range m.sessions {
-		if !session.IsExpired() {
+		if !session.IsExpiredAt(now) {
 			session.IsActive = true
 			m.activeSessions = append(m.activeSessions, session)
 		} else {
@@ -205,6 +216,11 @@ func (s *Session) AddEntry(entry models.UsageEntry) error {
 
 // UpdateStats recalculates session statistics
 func (s *Session) UpdateStats(calc *calculations.CostCalculator) error {
+	return s.updateStatsAt(calc, time.Now())
+}
+
+// updateStatsAt recalculates session statistics with the time-based metrics taken at now
+func (s *Session) updateStatsAt(calc *calculations.CostCalculator, now time.Time) error {
 	if len(s.Entries) == 0 {
 		return nil
 	}
@@ -232,8 +248,8 @@ func (s *Session) UpdateStats(calc *calculations.CostCalculator) error {
 	}
 
 	// Calculate time-based metrics
-	s.Stats.TimeRemaining = s.TimeRemaining()
-	s.Stats.PercentageUsed = s.PercentageComplete()
+	s.Stats.TimeRemaining = s.TimeRemainingAt(now)
+	s.Stats.PercentageUsed = s.PercentageCompleteAt(now)
 
 	return nil
 }
@@ -245,12 +261,22 @@ func (s *Session) IsExpiring() bool {
 
 // IsExpired returns true if session has passed its end time
 func (s *Session) IsExpired() bool {
-	return time.Now().UTC().After(s.EndTime)
+	return s.IsExpiredAt(time.Now())
+}
+
+// IsExpiredAt returns true if the session has passed its end time at now
+func (s *Session) IsExpiredAt(now time.Time) bool {
+	return now.After(s.EndTime)
 }
 
 // TimeRemaining returns remaining time in the session
 func (s *Session) TimeRemaining() time.Duration {
-	remaining := s.EndTime.Sub(time.Now().UTC())
+	return s.TimeRemainingAt(time.Now())
+}
+
+// TimeRemainingAt returns the time remaining in the session at now
+func (s *Session) TimeRemainingAt(now time.Time) time.Duration {
+	remaining := s.EndTime.Sub(now)
 	if remaining < 0 {
 		return 0
 	}
@@ -259,7 +285,12 @@ func (s *Session) TimeRemaining() time.Duration {
 
 // PercentageComplete returns how much of the session has elapsed (0-100)
 func (s *Session) PercentageComplete() float64 {
-	elapsed := time.Now().UTC().Sub(s.StartTime)
+	return s.PercentageCompleteAt(time.Now())
+}
+
+// PercentageCompleteAt returns how much of the session has elapsed at now (0-100)
+func (s *Session) PercentageCompleteAt(now time.Time) float64 {
+	elapsed := now.Sub(s.StartTime)
 	if elapsed < 0 {
 		return 0
 	}
@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/penwyp/claudecat/clock"
 	"github.com/penwyp/claudecat/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -337,6 +338,53 @@ func TestSession_TimeCalculations(t *testing.T) {
 	assert.False(t, session.IsExpired())
 }
 
+func TestSession_TimeCalculationsAt(t *testing.T) {
+	start := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	session := &Session{ID: "test", StartTime: start, EndTime: start.Add(SessionDuration)}
+
+	now := start.Add(2 * time.Hour)
+	assert.InDelta(t, 40.0, session.PercentageCompleteAt(now), 1e-9)
+	assert.Equal(t, 3*time.Hour, session.TimeRemainingAt(now))
+	assert.False(t, session.IsExpiredAt(now))
+
+	end := start.Add(SessionDuration)
+	assert.False(t, session.IsExpiredAt(end))
+	assert.True(t, session.IsExpiredAt(end.Add(time.Nanosecond)))
+	assert.Equal(t, time.Duration(0), session.TimeRemainingAt(end.Add(time.Hour)))
+	assert.Equal(t, 100.0, session.PercentageCompleteAt(end.Add(time.Hour)))
+}
+
+func TestManager_SetClock(t *testing.T) {
+	start := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start.Add(30 * time.Minute))
+	manager := NewManager()
+	manager.SetClock(fake)
+
+	require.NoError(t, manager.AddEntry(models.UsageEntry{
+		Timestamp:    start.Add(15 * time.Minute),
+		Model:        "claude-3-sonnet-20240229",
+		InputTokens:  100,
+		OutputTokens: 50,
+		TotalTokens:  150,
+		CostUSD:      0.0045,
+	}))
+
+	active := manager.GetActiveSession()
+	require.NotNil(t, active)
+	assert.Equal(t, 4*time.Hour+30*time.Minute, active.Stats.TimeRemaining)
+	assert.InDelta(t, 10.0, active.Stats.PercentageUsed, 1e-9)
+
+	fake.Advance(4*time.Hour + 30*time.Minute)
+	require.NoError(t, manager.RefreshStats())
+	assert.NotNil(t, manager.GetActiveSession(), "the window includes its end time")
+	assert.Equal(t, time.Duration(0), active.Stats.TimeRemaining)
+
+	fake.Advance(time.Second)
+	require.NoError(t, manager.RefreshStats())
+	assert.Nil(t, manager.GetActiveSession())
+	assert.False(t, active.IsActive)
+}
+
 func TestSession_ExpirationStatus(t *testing.T) {
 	// Create session that started 4.5 hours ago (expiring)
 	expiringStart := time.Now().Add(-4*time.Hour - 45*time.Minute)
@@ -0,0 +1,70 @@
+package sessions
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// pinnedStartLayouts are the accepted timestamp formats in a session overrides file
+var pinnedStartLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
+
+// LoadPinnedStarts reads known session start times from an overrides file.
+// Each line holds one timestamp (RFC3339 or "YYYY-MM-DD HH:MM" in local time); '#' starts a comment.
+// A missing file is not an error and yields no pins.
+func LoadPinnedStarts(path string) ([]time.Time, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open session overrides: %w", err)
+	}
+	defer file.Close()
+
+	var starts []time.Time
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = line[:idx]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		start, err := parsePinnedStart(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, lineNum, err)
+		}
+		starts = append(starts, start)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read session overrides: %w", err)
+	}
+
+	sort.Slice(starts, func(i, j int) bool {
+		return starts[i].Before(starts[j])
+	})
+	return starts, nil
+}
+
+// parsePinnedStart parses a single pinned start timestamp
+func parsePinnedStart(s string) (time.Time, error) {
+	for _, layout := range pinnedStartLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid session start %q (expected RFC3339 or YYYY-MM-DD HH:MM)", s)
+}
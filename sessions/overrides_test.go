@@ -0,0 +1,32 @@
+package sessions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadPinnedStarts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session_overrides.txt")
+	content := "# Session starts from usage emails\n2024-01-15T11:30:00Z\n\n2024-01-14 08:00  # local time\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	starts, err := LoadPinnedStarts(path)
+	require.NoError(t, err)
+	require.Len(t, starts, 2)
+	assert.Equal(t, time.Date(2024, 1, 14, 8, 0, 0, 0, time.Local), starts[0])
+	assert.True(t, starts[1].Equal(time.Date(2024, 1, 15, 11, 30, 0, 0, time.UTC)))
+
+	// A missing file yields no pins
+	starts, err = LoadPinnedStarts(filepath.Join(t.TempDir(), "missing.txt"))
+	require.NoError(t, err)
+	assert.Empty(t, starts)
+
+	require.NoError(t, os.WriteFile(path, []byte("yesterday\n"), 0644))
+	_, err = LoadPinnedStarts(path)
+	assert.ErrorContains(t, err, ":1:")
+}
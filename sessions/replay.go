@@ -0,0 +1,72 @@
+package sessions
+
+import (
+	"time"
+
+	"github.com/penwyp/claudecat/clock"
+	"github.com/penwyp/claudecat/models"
+)
+
+// Moment is what the monitor showed at a past time, rebuilt from the usage logged by then
+type Moment struct {
+	At             time.Time             `json:"at"`
+	TokenLimit     int                   `json:"token_limit,omitempty"`
+	CostLimit      float64               `json:"cost_limit,omitempty"`
+	MessageLimit   int                   `json:"message_limit,omitempty"`
+	Block          *BillingBlock         `json:"block,omitempty"`  // The block in progress at At, else the last one before it
+	Active         *ActiveBlock          `json:"active,omitempty"` // Set when Block was in progress at At
+	Messages       int                   `json:"messages"`         // Messages sent in Block by At
+	MessagePercent float64               `json:"message_percent,omitempty"`
+	TokensRunOut   *time.Time            `json:"tokens_run_out,omitempty"` // When the burn rate would have reached the token limit, if before the reset
+	LimitMessages  []models.LimitMessage `json:"limit_messages,omitempty"` // Limit messages logged in Block by At
+}
+
+// Replay rebuilds the monitor at at from the entries and limit messages of the logs, ignoring
+// everything logged after it, and measures the block of that moment against limits
+func Replay(entries []models.UsageEntry, limitMessages []models.LimitMessage, limits models.PlanLimits, at time.Time) Moment {
+	moment := Moment{At: at, TokenLimit: limits.TokenLimit, CostLimit: limits.CostLimit, MessageLimit: limits.MessageLimit}
+
+	var past []models.UsageEntry
+	for _, entry := range entries {
+		if !entry.Timestamp.After(at) {
+			past = append(past, entry)
+		}
+	}
+	analyzer := NewSessionAnalyzer(int(models.SessionDuration / time.Hour))
+	analyzer.SetClock(clock.NewFake(at))
+	blocks := analyzer.TransformToBlocks(past)
+
+	var block *models.SessionBlock
+	for i := len(blocks) - 1; i >= 0; i-- {
+		if !blocks[i].IsGap {
+			block = &blocks[i]
+			break
+		}
+	}
+	if block == nil {
+		return moment
+	}
+	for _, limit := range limitMessages {
+		if !limit.Timestamp.Before(block.StartTime) && !limit.Timestamp.After(block.EndTime) && !limit.Timestamp.After(at) {
+			block.LimitMessages = append(block.LimitMessages, limit)
+		}
+	}
+
+	report := Blocks([]models.SessionBlock{*block}, BlockOptions{Limits: limits}, at)
+	moment.Block = &report.Blocks[0]
+	moment.Active = report.Active
+	moment.Messages = block.SentMessagesCount
+	moment.LimitMessages = block.LimitMessages
+	if limits.MessageLimit > 0 {
+		moment.MessagePercent = float64(block.SentMessagesCount) / float64(limits.MessageLimit) * 100
+	}
+
+	if moment.Active != nil && moment.Active.TokensPerMinute > 0 && limits.TokenLimit > moment.Block.TotalTokens {
+		left := float64(limits.TokenLimit - moment.Block.TotalTokens)
+		runOut := at.Add(time.Duration(left / moment.Active.TokensPerMinute * float64(time.Minute)))
+		if runOut.Before(block.EndTime) {
+			moment.TokensRunOut = &runOut
+		}
+	}
+	return moment
+}
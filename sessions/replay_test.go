@@ -0,0 +1,56 @@
+package sessions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/penwyp/claudecat/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplay(t *testing.T) {
+	base := time.Date(2025, 6, 3, 9, 0, 0, 0, time.UTC)
+	entry := func(offset time.Duration, tokens int, cost float64) models.UsageEntry {
+		return models.UsageEntry{Timestamp: base.Add(offset), Model: "claude-sonnet-4-20250514", InputTokens: tokens, TotalTokens: tokens, CostUSD: cost}
+	}
+	entries := []models.UsageEntry{
+		entry(10*time.Minute, 1000, 2),
+		entry(70*time.Minute, 1000, 2),
+		entry(3*time.Hour, 2000, 4),
+		entry(30*time.Hour, 500, 1),
+	}
+	limitMessages := []models.LimitMessage{
+		{Message: "Claude usage limit reached", Timestamp: base.Add(2 * time.Hour), Type: "system_limit"},
+		{Message: "Claude usage limit reached", Timestamp: base.Add(4 * time.Hour), Type: "system_limit"},
+	}
+	limits := models.PlanLimits{TokenLimit: 4000, CostLimit: 10, MessageLimit: 10}
+
+	moment := Replay(entries, limitMessages, limits, base.Add(2*time.Hour))
+	require.NotNil(t, moment.Block)
+	assert.Equal(t, base, moment.Block.Start)
+	assert.Equal(t, 2000, moment.Block.TotalTokens, "later entries are ignored")
+	assert.InDelta(t, 50.0, moment.Block.TokenPercent, 1e-9)
+	assert.InDelta(t, 40.0, moment.Block.CostPercent, 1e-9)
+	assert.Equal(t, 2, moment.Messages)
+	assert.InDelta(t, 20.0, moment.MessagePercent, 1e-9)
+	require.Len(t, moment.LimitMessages, 1, "limit messages logged later are ignored")
+	assert.True(t, moment.Block.LimitHit)
+
+	require.NotNil(t, moment.Active)
+	assert.Equal(t, 2*time.Hour, moment.Active.Elapsed)
+	assert.Equal(t, 3*time.Hour, moment.Active.Remaining)
+	assert.InDelta(t, 2000.0/70, moment.Active.TokensPerMinute, 1e-6, "from the block start to its last entry")
+	require.NotNil(t, moment.TokensRunOut)
+	assert.Equal(t, base.Add(3*time.Hour+10*time.Minute), *moment.TokensRunOut)
+
+	// After the block ended, the monitor showed it as the last session
+	ended := Replay(entries, limitMessages, limits, base.Add(6*time.Hour))
+	require.NotNil(t, ended.Block)
+	assert.Equal(t, 4000, ended.Block.TotalTokens)
+	assert.Nil(t, ended.Active)
+	assert.Nil(t, ended.TokensRunOut)
+	assert.Len(t, ended.LimitMessages, 2)
+
+	assert.Nil(t, Replay(entries, limitMessages, limits, base).Block, "nothing was logged yet")
+}
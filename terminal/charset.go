@@ -0,0 +1,117 @@
+// Package terminal holds the charset, hyperlink and column width settings of the terminal output.
+// It depends only on the standard library, so the lite build can use it without the monitor's
+// output package.
+package terminal
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+// Charsets selectable with SetCharset
+const (
+	CharsetAuto    = "auto" // Detected from the terminal and locale
+	CharsetUnicode = "unicode"
+	CharsetASCII   = "ascii"
+)
+
+// asciiOnly is set when output must stay within ASCII
+var asciiOnly atomic.Bool
+
+func init() {
+	asciiOnly.Store(!DetectUnicode(os.Getenv, runtime.GOOS))
+}
+
+// asciiReplacements maps the box drawing, block and punctuation characters used in output to ASCII
+var asciiReplacements = map[rune]string{
+	'─': "-", '━': "-", '═': "-",
+	'│': "|", '┃': "|", '║': "|",
+	'┌': "+", '┐': "+", '└': "+", '┘': "+", '├': "+", '┤': "+", '┬': "+", '┴': "+", '┼': "+",
+	'╭': "+", '╮': "+", '╰': "+", '╯': "+",
+	'█': "#", '▓': "#", '▒': "#", '░': "-",
+	'▁': "_", '▂': ".", '▃': ":", '▄': "=", '▅': "+", '▆': "*", '▇': "%",
+	'·': "-", '•': "*", '…': "...", '→': "->", '←': "<-", '↑': "^", '↓': "v",
+	'–': "-", '—': "-", '±': "+/-", '×': "x", '✦': "*", '✧': "*", '⟳': "~",
+}
+
+// DetectUnicode reports whether the terminal described by the environment displays UTF-8 and emojis.
+// Serial and kernel consoles do not; otherwise the locale decides, and without one only Windows and
+// macOS terminals are assumed to be UTF-8.
+func DetectUnicode(getenv func(string) string, goos string) bool {
+	switch strings.ToLower(getenv("TERM")) {
+	case "dumb", "linux", "vt100", "vt102", "vt220", "ansi":
+		return false
+	}
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if locale := strings.ToLower(getenv(name)); locale != "" {
+			return strings.Contains(locale, "utf-8") || strings.Contains(locale, "utf8")
+		}
+	}
+	return goos == "windows" || goos == "darwin"
+}
+
+// SetCharset overrides the detected charset; auto detects it again
+func SetCharset(charset string) {
+	switch charset {
+	case CharsetUnicode:
+		asciiOnly.Store(false)
+	case CharsetASCII:
+		asciiOnly.Store(true)
+	default:
+		asciiOnly.Store(!DetectUnicode(os.Getenv, runtime.GOOS))
+	}
+}
+
+// Unicode reports whether output may use box drawing, block characters and emojis
+func Unicode() bool {
+	return !asciiOnly.Load()
+}
+
+// Text returns s unchanged when the terminal displays Unicode, else its ASCII fallback, see ToASCII
+func Text(s string) string {
+	if !asciiOnly.Load() {
+		return s
+	}
+	return ToASCII(s)
+}
+
+// ToASCII replaces box drawing with +, - and |, bars with # and -, drops emojis along with the space
+// after them and turns any other non-ASCII character into ?
+func ToASCII(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	skipSpace := false
+	for _, r := range s {
+		if r == 0xFE0F || r == 0x200D {
+			continue // Emoji presentation selector and joiner
+		}
+		if skipSpace {
+			skipSpace = false
+			if r == ' ' {
+				continue
+			}
+		}
+		switch {
+		case r < 0x80:
+			b.WriteRune(r)
+		case asciiReplacements[r] != "":
+			b.WriteString(asciiReplacements[r])
+		case isEmoji(r):
+			skipSpace = true
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// isEmoji reports whether r is in one of the emoji and pictograph blocks
+func isEmoji(r rune) bool {
+	return r >= 0x1F000 ||
+		(r >= 0x2300 && r <= 0x23FF) || // Miscellaneous technical: ⏰ ⌨ ⏱
+		(r >= 0x2600 && r <= 0x27BF) || // Miscellaneous symbols and dingbats: ⚠ ❌
+		(r >= 0x2B00 && r <= 0x2BFF) ||
+		r == 0x2139 // ℹ
+}
@@ -0,0 +1,73 @@
+package terminal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectUnicode(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		goos string
+		want bool
+	}{
+		{"UTF-8 locale", map[string]string{"LANG": "en_US.UTF-8"}, "linux", true},
+		{"utf8 spelling", map[string]string{"LC_CTYPE": "C.utf8"}, "linux", true},
+		{"LC_ALL takes precedence", map[string]string{"LC_ALL": "C", "LANG": "en_US.UTF-8"}, "linux", false},
+		{"kernel console", map[string]string{"TERM": "linux", "LANG": "en_US.UTF-8"}, "linux", false},
+		{"dumb terminal", map[string]string{"TERM": "dumb"}, "darwin", false},
+		{"no locale on linux", map[string]string{"TERM": "xterm-256color"}, "linux", false},
+		{"no locale on macOS", map[string]string{"TERM": "xterm-256color"}, "darwin", true},
+		{"no locale on Windows", nil, "windows", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(name string) string { return tt.env[name] }
+			assert.Equal(t, tt.want, DetectUnicode(getenv, tt.goos))
+		})
+	}
+}
+
+func TestToASCII(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"plain text", "plain text"},
+		{"┌─┬─┐", "+-+-+"},
+		{"│ a │", "| a |"},
+		{"███░░", "###--"},
+		{"▁▃▅▇", "_:+%"},
+		{"a → b…", "a -> b..."},
+		{"⚠️ Limit reached", "Limit reached"},
+		{"🔥 Hot ✅", "Hot "},
+		{"café", "caf?"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			assert.Equal(t, tt.want, ToASCII(tt.in))
+		})
+	}
+}
+
+func TestSetCharset(t *testing.T) {
+	defer SetCharset(CharsetAuto)
+
+	tests := []struct {
+		charset string
+		unicode bool
+		text    string
+	}{
+		{CharsetASCII, false, "+--+ ##"},
+		{CharsetUnicode, true, "┌──┐ ██"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.charset, func(t *testing.T) {
+			SetCharset(tt.charset)
+			assert.Equal(t, tt.unicode, Unicode())
+			assert.Equal(t, tt.text, Text("┌──┐ ██"))
+		})
+	}
+}
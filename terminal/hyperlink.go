@@ -0,0 +1,122 @@
+package terminal
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Hyperlink modes selectable with SetHyperlinks
+const (
+	HyperlinksAuto   = "auto" // Detected from the terminal
+	HyperlinksAlways = "always"
+	HyperlinksNever  = "never"
+)
+
+// hyperlinksOn is set when project names and session IDs are rendered as OSC 8 hyperlinks
+var hyperlinksOn atomic.Bool
+
+// hyperlinkPrograms are the TERM_PROGRAM values of terminals known to open OSC 8 hyperlinks
+var hyperlinkPrograms = map[string]bool{
+	"iterm.app": true, "wezterm": true, "vscode": true, "ghostty": true, "hyper": true, "tabby": true,
+}
+
+// sessionLink is the URL template session IDs link to, see SetSessionLink
+var sessionLink atomic.Value
+
+// osc8 matches the OSC 8 sequences opening and closing a hyperlink
+var osc8 = regexp.MustCompile("\033\\]8;[^\033\a]*(?:\033\\\\|\a)")
+
+// DetectHyperlinks reports whether the terminal described by the environment opens OSC 8 hyperlinks.
+// FORCE_HYPERLINK=1 or 0 overrides the detection; CI logs and terminal multiplexers, which drop or
+// garble the sequences unless configured to pass them on, are assumed not to.
+func DetectHyperlinks(getenv func(string) string) bool {
+	if force := getenv("FORCE_HYPERLINK"); force != "" {
+		return force != "0"
+	}
+	if getenv("CI") != "" || getenv("TMUX") != "" || getenv("STY") != "" {
+		return false
+	}
+	if getenv("WT_SESSION") != "" || getenv("KITTY_WINDOW_ID") != "" || getenv("KONSOLE_VERSION") != "" || getenv("DOMTERM") != "" {
+		return true
+	}
+	if hyperlinkPrograms[strings.ToLower(getenv("TERM_PROGRAM"))] {
+		return true
+	}
+	// GNOME Terminal, Tilix and the other VTE terminals support them since VTE 0.50
+	if vte, err := strconv.Atoi(getenv("VTE_VERSION")); err == nil && vte >= 5000 {
+		return true
+	}
+	term := strings.ToLower(getenv("TERM"))
+	return term == "xterm-kitty" || term == "xterm-ghostty" || term == "wezterm" ||
+		strings.HasPrefix(term, "alacritty") || strings.HasPrefix(term, "foot")
+}
+
+// SetHyperlinks turns hyperlinks on or off; auto turns them on when terminal is set and the
+// environment describes a terminal that supports them
+func SetHyperlinks(mode string, terminal bool) {
+	switch mode {
+	case HyperlinksAlways:
+		hyperlinksOn.Store(true)
+	case HyperlinksNever:
+		hyperlinksOn.Store(false)
+	default:
+		hyperlinksOn.Store(terminal && DetectHyperlinks(os.Getenv))
+	}
+}
+
+// Hyperlinks reports whether output renders hyperlinks
+func Hyperlinks() bool {
+	return hyperlinksOn.Load()
+}
+
+// Link returns text as a hyperlink to target when hyperlinks are on and target is set, else text
+func Link(target, text string) string {
+	if target == "" || text == "" || !hyperlinksOn.Load() {
+		return text
+	}
+	return "\033]8;;" + target + "\033\\" + text + "\033]8;;\033\\"
+}
+
+// SetSessionLink sets the URL template session IDs link to; {id} is replaced by the escaped session ID
+func SetSessionLink(template string) {
+	sessionLink.Store(template)
+}
+
+// SessionURL returns the URL sessionID links to, or "" without a session link template
+func SessionURL(sessionID string) string {
+	template, _ := sessionLink.Load().(string)
+	if template == "" || sessionID == "" {
+		return ""
+	}
+	return strings.ReplaceAll(template, "{id}", url.PathEscape(sessionID))
+}
+
+// FileURL returns the file:// URL of path, with the host name terminals check before opening it,
+// or "" for an empty path
+func FileURL(path string) string {
+	if path == "" {
+		return ""
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path // Windows drive letters
+	}
+	host, _ := os.Hostname()
+	return (&url.URL{Scheme: "file", Host: host, Path: path}).String()
+}
+
+// StripHyperlinks removes the hyperlink sequences from s, leaving the text shown on screen
+func StripHyperlinks(s string) string {
+	if !strings.Contains(s, "\033]8;") {
+		return s
+	}
+	return osc8.ReplaceAllString(s, "")
+}
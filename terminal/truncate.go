@@ -0,0 +1,83 @@
+package terminal
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// columnWidths caps table columns by lowercase header, see SetColumnWidths
+var (
+	columnWidthsMu sync.RWMutex
+	columnWidths   map[string]int
+)
+
+// ansiEscape matches ANSI styling sequences such as the monitor's theme accents
+var ansiEscape = regexp.MustCompile("\033\\[[0-9;]*m")
+
+// fullNames is set when --full-names turns the column caps off
+var fullNames atomic.Bool
+
+// SetColumnWidths sets the widest a table column may grow, by lowercase header; 0 leaves it unlimited
+func SetColumnWidths(widths map[string]int) {
+	caps := make(map[string]int, len(widths))
+	for header, width := range widths {
+		caps[strings.ToLower(header)] = width
+	}
+	columnWidthsMu.Lock()
+	columnWidths = caps
+	columnWidthsMu.Unlock()
+}
+
+// SetFullNames turns the column caps of SetColumnWidths off, showing cells in full
+func SetFullNames(on bool) {
+	fullNames.Store(on)
+}
+
+// ColumnWidth returns the widest the column named header may grow, or 0 when unlimited
+func ColumnWidth(header string) int {
+	if fullNames.Load() {
+		return 0
+	}
+	columnWidthsMu.RLock()
+	defer columnWidthsMu.RUnlock()
+	return columnWidths[strings.ToLower(strings.TrimSpace(header))]
+}
+
+// TruncateColumn shortens cell to the width of the column named header, see TruncateMiddle
+func TruncateColumn(header, cell string) string {
+	return TruncateMiddle(cell, ColumnWidth(header))
+}
+
+// TruncateMiddle shortens s to width characters by replacing its middle with an ellipsis, keeping
+// both the start and the distinguishing end of names such as mangled project paths. A cell that
+// is a single hyperlink stays linked; 0 leaves s as it is.
+func TruncateMiddle(s string, width int) string {
+	if width <= 0 || VisibleWidth(s) <= width {
+		return s
+	}
+
+	opening, text, closing := "", StripHyperlinks(s), ""
+	if links := osc8.FindAllStringIndex(s, -1); len(links) == 2 && links[0][0] == 0 && links[1][1] == len(s) {
+		opening, text, closing = s[:links[0][1]], s[links[0][1]:links[1][0]], s[links[1][0]:]
+	}
+
+	ellipsis := "..."
+	if Unicode() {
+		ellipsis = "…"
+	}
+	runes := []rune(ansiEscape.ReplaceAllString(text, ""))
+	keep := width - len([]rune(ellipsis))
+	if keep <= 0 {
+		return opening + string(runes[:width]) + closing
+	}
+	head := (keep + 1) / 2
+	return opening + string(runes[:head]) + ellipsis + string(runes[len(runes)-(keep-head):]) + closing
+}
+
+// VisibleWidth returns the number of runes of line shown on screen, ignoring ANSI styling and hyperlinks
+func VisibleWidth(line string) int {
+	return utf8.RuneCountInString(ansiEscape.ReplaceAllString(StripHyperlinks(line), ""))
+}